@@ -4,6 +4,7 @@ import (
 	"auth-service/handler"
 	"auth-service/model"
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -22,22 +23,22 @@ type MockAssignmentsService struct {
 	mock.Mock
 }
 
-func (m *MockAssignmentsService) Create(a *model.DriverAssignment) error {
+func (m *MockAssignmentsService) Create(ctx context.Context, a *model.DriverAssignment) error {
 	args := m.Called(a)
 	return args.Error(0)
 }
 
-func (m *MockAssignmentsService) FindByVehicle(vehicleID uint) ([]model.DriverAssignment, error) {
+func (m *MockAssignmentsService) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.DriverAssignment, error) {
 	args := m.Called(vehicleID)
 	return args.Get(0).([]model.DriverAssignment), args.Error(1)
 }
 
-func (m *MockAssignmentsService) Update(a *model.DriverAssignment) error {
+func (m *MockAssignmentsService) Update(ctx context.Context, a *model.DriverAssignment) error {
 	args := m.Called(a)
 	return args.Error(0)
 }
 
-func (m *MockAssignmentsService) Delete(id uint) error {
+func (m *MockAssignmentsService) Delete(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -23,7 +23,7 @@ func (h *AssignmentHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.service.Create(&a); err != nil {
+	if err := h.service.Create(c.Request.Context(), &a); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -39,7 +39,7 @@ func (h *AssignmentHandler) FindByVehicle(c *gin.Context) {
 		return
 	}
 
-	data, err := h.service.FindByVehicle(uint(vehicleID))
+	data, err := h.service.FindByVehicle(c.Request.Context(), uint(vehicleID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -54,7 +54,7 @@ func (h *AssignmentHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.service.Update(&a); err != nil {
+	if err := h.service.Update(c.Request.Context(), &a); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -63,7 +63,7 @@ func (h *AssignmentHandler) Update(c *gin.Context) {
 
 func (h *AssignmentHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.service.Delete(uint(id)); err != nil {
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
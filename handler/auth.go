@@ -77,7 +77,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	err := h.AuthService.Register(req.Username, req.Password, req.Role)
+	err := h.AuthService.Register(c.Request.Context(), req.Username, req.Password, req.Role)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": err.Error(),
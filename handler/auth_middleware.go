@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const claimsContextKey = "claims"
+
+func AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		tokenString := strings.TrimPrefix(header, "Bearer ")
+		if header == "" || tokenString == header {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		claims, err := utils.ParseAccessToken(tokenString)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+
+		c.Set(claimsContextKey, claims)
+		c.Request = c.Request.WithContext(utils.WithTenant(c.Request.Context(), claims.TenantID))
+		c.Next()
+	}
+}
+
+func CurrentUser(c *gin.Context) *model.User {
+	value, ok := c.Get(claimsContextKey)
+	if !ok {
+		return nil
+	}
+	claims, ok := value.(*utils.JWTclaims)
+	if !ok {
+		return nil
+	}
+	return &model.User{ID: claims.UserID, TenantID: claims.TenantID, Role: claims.Role, DriverID: claims.DriverID}
+}
+
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil || !user.IsAdmin() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func RequireSuperAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if user == nil || !user.IsSuperAdmin() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "super admin role required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,84 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAdminRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin", handler.AuthMiddleware(), handler.RequireAdmin(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": handler.CurrentUser(c).ID})
+	})
+	return router
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	adminToken, _ := utils.GenerateAccessToken(1, 1, "admin", nil)
+	userToken, _ := utils.GenerateAccessToken(2, 1, "user", nil)
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"not bearer", "Basic abc", http.StatusUnauthorized},
+		{"invalid token", "Bearer invalid", http.StatusUnauthorized},
+		{"non admin", "Bearer " + userToken, http.StatusForbidden},
+		{"admin", "Bearer " + adminToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			setupAdminRouter().ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestRequireSuperAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/tenants", handler.AuthMiddleware(), handler.RequireSuperAdmin(), func(c *gin.Context) {
+		tenantID, _ := utils.TenantFromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{"tenant_id": tenantID})
+	})
+
+	adminToken, _ := utils.GenerateAccessToken(1, 1, model.RoleAdmin, nil)
+	superToken, _ := utils.GenerateAccessToken(2, 1, model.RoleSuperAdmin, nil)
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"admin", adminToken, http.StatusForbidden},
+		{"super admin", superToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/tenants", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
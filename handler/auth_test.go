@@ -3,6 +3,7 @@ package handler_test
 import (
 	"auth-service/handler"
 	"auth-service/service/mock_auth_service"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -186,7 +187,7 @@ func TestAuthHandler_Register_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockSvc := &mock_auth_service.MockAuthService{
-		RegisterFn: func(username, password, role string) error {
+		RegisterFn: func(ctx context.Context, username, password, role string) error {
 			return nil
 		},
 	}
@@ -251,7 +252,7 @@ func TestAuthHandler_Register_ServiceError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockSvc := &mock_auth_service.MockAuthService{
-		RegisterFn: func(username, password, role string) error {
+		RegisterFn: func(ctx context.Context, username, password, role string) error {
 			return errors.New("service error")
 		},
 	}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultAvailabilityRange = 7 * 24 * time.Hour
+
+type AvailabilityHandler struct {
+	Service service.AvailabilityServiceInterface
+}
+
+func NewAvailabilityHandler(s service.AvailabilityServiceInterface) *AvailabilityHandler {
+	return &AvailabilityHandler{Service: s}
+}
+
+// parseTimeQuery accepts RFC 3339 timestamps or plain dates, falling back to def
+// when the parameter is absent.
+func parseTimeQuery(c *gin.Context, name string, def time.Time) (time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return def, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", raw, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s, expected RFC 3339 time or YYYY-MM-DD", name)
+}
+
+func (h *AvailabilityHandler) GetDriverAvailability(c *gin.Context) {
+	driverID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+
+	now := time.Now()
+	from, err := parseTimeQuery(c, "from", time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := parseTimeQuery(c, "to", from.Add(defaultAvailabilityRange))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	availability, err := h.Service.GetDriverAvailability(c.Request.Context(), driverID, from, to)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, availability)
+}
+
+func (h *AvailabilityHandler) AddLeave(c *gin.Context) {
+	driverID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+
+	var leave model.DriverLeave
+	if err := c.ShouldBindJSON(&leave); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leave.DriverID = driverID
+	if err := h.Service.AddLeave(c.Request.Context(), &leave); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, leave)
+}
+
+func (h *AvailabilityHandler) DeleteLeave(c *gin.Context) {
+	driverID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+	leaveID, err := strconv.Atoi(c.Param("leave_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid leave id"})
+		return
+	}
+
+	if err := h.Service.DeleteLeave(c.Request.Context(), driverID, leaveID); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Leave deleted successfully"})
+}
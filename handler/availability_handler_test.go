@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockAvailabilityService struct {
+	from, to time.Time
+}
+
+func (m *MockAvailabilityService) GetDriverAvailability(ctx context.Context, driverID int, from, to time.Time) (*model.DriverAvailability, error) {
+	if driverID != 3 {
+		return nil, service.ErrNotFound
+	}
+	m.from, m.to = from, to
+	return &model.DriverAvailability{DriverID: driverID, From: from, To: to, Free: []model.TimeSlot{{StartAt: from, EndAt: to}}}, nil
+}
+
+func (m *MockAvailabilityService) AddLeave(ctx context.Context, l *model.DriverLeave) error {
+	if l.DriverID != 3 {
+		return service.ErrDriverUnavailable
+	}
+	l.ID = 1
+	return nil
+}
+
+func (m *MockAvailabilityService) DeleteLeave(ctx context.Context, driverID, id int) error {
+	if id != 1 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func TestAvailabilityHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &MockAvailabilityService{}
+	h := handler.NewAvailabilityHandler(svc)
+	router := gin.New()
+	router.GET("/drivers/:id/availability", h.GetDriverAvailability)
+	router.POST("/drivers/:id/leave", h.AddLeave)
+	router.DELETE("/drivers/:id/leave/:leave_id", h.DeleteLeave)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/drivers/3/availability?from=2024-05-01T08:00:00Z&to=2024-05-01T18:00:00Z", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"free":[{"start_at":"2024-05-01T08:00:00Z","end_at":"2024-05-01T18:00:00Z"}]`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/drivers/3/availability?from=2024-05-01", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 7*24*time.Hour, svc.to.Sub(svc.from))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/drivers/3/availability?from=tomorrow", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/drivers/4/availability", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/drivers/3/leave",
+		bytes.NewBufferString(`{"start_at":"2024-05-02T00:00:00Z","end_at":"2024-05-03T00:00:00Z","reason":"sick"}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"driver_id":3`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/drivers/5/leave",
+		bytes.NewBufferString(`{"start_at":"2024-05-02T00:00:00Z","end_at":"2024-05-03T00:00:00Z"}`)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/drivers/3/leave/2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -3,16 +3,20 @@ package handler
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type BookingRepoInterface interface {
-	Create(*model.Booking) error
-	GetAll() ([]model.Booking, error)
-	Update(*model.Booking) error
-	Delete(id string) error
+	Create(context.Context, *model.Booking) error
+	GetAll(context.Context) ([]model.Booking, error)
+	GetByID(ctx context.Context, id string) (*model.Booking, error)
+	Update(context.Context, *model.Booking) error
+	Delete(ctx context.Context, id string, version int) error
 }
 
 type BookingHandler struct {
@@ -29,15 +33,20 @@ func (h *BookingHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.BookingService.Create(&booking); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	booking.CreatedBy = nil
+	if user := CurrentUser(c); user != nil {
+		booking.CreatedBy = &user.ID
+	}
+	if err := h.BookingService.Create(c.Request.Context(), &booking); err != nil {
+		respondWriteError(c, err)
 		return
 	}
+	setETag(c, booking.Version)
 	c.JSON(http.StatusCreated, booking)
 }
 
 func (h *BookingHandler) GetAll(c *gin.Context) {
-	bookings, err := h.BookingService.GetAll()
+	bookings, err := h.BookingService.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -45,6 +54,32 @@ func (h *BookingHandler) GetAll(c *gin.Context) {
 	c.JSON(http.StatusOK, bookings)
 }
 
+func (h *BookingHandler) GetByID(c *gin.Context) {
+	booking, err := h.BookingService.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if booking == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+
+	setETag(c, booking.Version)
+	c.JSON(http.StatusOK, booking)
+}
+
+func (h *BookingHandler) GetByCode(c *gin.Context) {
+	booking, err := h.BookingService.GetByCode(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, booking.Version)
+	c.JSON(http.StatusOK, booking)
+}
+
 func (h *BookingHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 
@@ -54,22 +89,191 @@ func (h *BookingHandler) Update(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	booking.ID = id
-	if err := h.BookingService.Update(&booking); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	booking.Version = version
+	if err := h.BookingService.Update(c.Request.Context(), &booking); err != nil {
+		respondWriteError(c, err)
 		return
 	}
 
+	setETag(c, booking.Version)
 	c.JSON(http.StatusOK, booking)
 }
 
 func (h *BookingHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.BookingService.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.BookingService.Delete(c.Request.Context(), id, version); err != nil {
+		respondWriteError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Booking deleted successfully"})
 }
+
+func (h *BookingHandler) Transition(c *gin.Context) {
+	var req model.BookingTransition
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := h.BookingService.Transition(c.Request.Context(), c.Param("id"), CurrentUser(c), req)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, booking.Version)
+	c.JSON(http.StatusOK, booking)
+}
+
+// UpdateStop records the driver arriving at, leaving or skipping one stop.
+func (h *BookingHandler) UpdateStop(c *gin.Context) {
+	position, err := strconv.Atoi(c.Param("position"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid stop position"})
+		return
+	}
+
+	var req model.StopUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := h.BookingService.UpdateStop(c.Request.Context(), c.Param("id"), position, CurrentUser(c), req)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, booking.Version)
+	c.JSON(http.StatusOK, booking)
+}
+
+func (h *BookingHandler) GetStatusHistory(c *gin.Context) {
+	history, err := h.BookingService.GetStatusHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// Cancel cancels a booking under the tenant's cancellation policy, keeping the
+// booking and settling its fee and refund.
+func (h *BookingHandler) Cancel(c *gin.Context) {
+	var req model.BookingCancel
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	booking, cancellation, err := h.BookingService.Cancel(c.Request.Context(), c.Param("id"), CurrentUser(c), req.Reason)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, booking.Version)
+	c.JSON(http.StatusOK, gin.H{"booking": booking, "cancellation": cancellation})
+}
+
+// parseFloatQuery reads a required decimal query parameter, or def when one
+// is given and the parameter is absent.
+func parseFloatQuery(c *gin.Context, name string, def *float64) (float64, error) {
+	raw := c.Query(name)
+	if raw == "" && def != nil {
+		return *def, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s, expected a decimal number", name)
+	}
+	return v, nil
+}
+
+const defaultNearbyRadiusKM = 3.0
+
+// Nearby lists bookings picking up within radius_km (3 km by default) of
+// lat,lng, nearest first.
+func (h *BookingHandler) Nearby(c *gin.Context) {
+	radius := defaultNearbyRadiusKM
+	var point [3]float64
+	for i, name := range []string{"lat", "lng", "radius_km"} {
+		var def *float64
+		if name == "radius_km" {
+			def = &radius
+		}
+		v, err := parseFloatQuery(c, name, def)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		point[i] = v
+	}
+
+	bookings, err := h.BookingService.Nearby(c.Request.Context(), point[0], point[1], point[2])
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, bookings)
+}
+
+// Within lists bookings picking up inside the box min_lat,min_lng..max_lat,max_lng.
+func (h *BookingHandler) Within(c *gin.Context) {
+	var box model.GeoBox
+	for _, f := range []struct {
+		name string
+		dst  *float64
+	}{{"min_lat", &box.MinLat}, {"min_lng", &box.MinLng}, {"max_lat", &box.MaxLat}, {"max_lng", &box.MaxLng}} {
+		v, err := parseFloatQuery(c, f.name, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		*f.dst = v
+	}
+
+	bookings, err := h.BookingService.Within(c.Request.Context(), box)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, bookings)
+}
+
+func (h *BookingHandler) GetTrip(c *gin.Context) {
+	trip, err := h.BookingService.GetTrip(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, trip)
+}
+
+func (h *BookingHandler) GetCancellation(c *gin.Context) {
+	cancellation, err := h.BookingService.GetCancellation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cancellation)
+}
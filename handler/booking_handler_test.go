@@ -3,7 +3,10 @@ package handler_test
 import (
 	"auth-service/handler"
 	"auth-service/model"
+	"auth-service/service"
+	"auth-service/utils"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -16,9 +19,10 @@ import (
 
 type MockBookingService struct {
 	ReturnError bool
+	Conflict    bool
 }
 
-func (m *MockBookingService) Create(b *model.Booking) error {
+func (m *MockBookingService) Create(ctx context.Context, b *model.Booking) error {
 	if m.ReturnError || b.ID == "" {
 		return errors.New("user id required")
 	}
@@ -26,7 +30,7 @@ func (m *MockBookingService) Create(b *model.Booking) error {
 	return nil
 }
 
-func (m *MockBookingService) GetAll() ([]model.Booking, error) {
+func (m *MockBookingService) GetAll(ctx context.Context) ([]model.Booking, error) {
 	if m.ReturnError {
 		return nil, errors.New("get all error")
 	}
@@ -35,23 +39,115 @@ func (m *MockBookingService) GetAll() ([]model.Booking, error) {
 	}, nil
 }
 
-func (m *MockBookingService) Update(b *model.Booking) error {
+func (m *MockBookingService) Update(ctx context.Context, b *model.Booking) error {
 	if m.ReturnError {
 		return errors.New("service error")
 	}
+	if m.Conflict {
+		return service.ErrVersionConflict
+	}
 	if b.ID == "" {
 		return errors.New("id required")
 	}
 	return nil
 }
 
-func (m *MockBookingService) Delete(id string) error {
+func (m *MockBookingService) GetByID(ctx context.Context, id string) (*model.Booking, error) {
+	if m.ReturnError {
+		return nil, errors.New("service error")
+	}
+	if id != "1" {
+		return nil, nil
+	}
+	return &model.Booking{ID: "1", Customer: "123", Version: 2}, nil
+}
+
+func (m *MockBookingService) GetByCode(ctx context.Context, code string) (*model.Booking, error) {
+	switch code {
+	case "BK00001A":
+		return &model.Booking{ID: "BK00001A", Customer: "123", Version: 2}, nil
+	case "BK00001B":
+		return nil, service.ErrNotFound
+	}
+	return nil, service.ErrInvalidBookingCode
+}
+
+func (m *MockBookingService) Delete(ctx context.Context, id string, version int) error {
 	if m.ReturnError || id == "" {
 		return errors.New("id required")
 	}
 	return nil
 }
 
+func (m *MockBookingService) Transition(ctx context.Context, id string, user *model.User, t model.BookingTransition) (*model.Booking, error) {
+	if id != "1" {
+		return nil, service.ErrNotFound
+	}
+	if user == nil || !user.IsAdmin() {
+		return nil, service.ErrTransitionForbidden
+	}
+	if t.To == "Pending" {
+		return nil, service.ErrInvalidTransition
+	}
+	return &model.Booking{ID: id, Status: t.To, Version: 3}, nil
+}
+
+func (m *MockBookingService) GetStatusHistory(ctx context.Context, id string) ([]model.BookingStatusChange, error) {
+	if id != "1" {
+		return nil, service.ErrNotFound
+	}
+	return []model.BookingStatusChange{{ID: 1, BookingID: id, FromStatus: "Pending", ToStatus: "Confirmed"}}, nil
+}
+
+func (m *MockBookingService) Cancel(ctx context.Context, id string, user *model.User, reason string) (*model.Booking, *model.BookingCancellation, error) {
+	if id != "1" {
+		return nil, nil, service.ErrNotFound
+	}
+	return &model.Booking{ID: id, Status: model.BookingCancelled, Version: 3},
+		&model.BookingCancellation{ID: 1, BookingID: id, Status: model.BookingCancelled, Fee: 50000, Reason: reason}, nil
+}
+
+func (m *MockBookingService) GetCancellation(ctx context.Context, id string) (*model.BookingCancellation, error) {
+	if id != "1" {
+		return nil, service.ErrNotFound
+	}
+	return &model.BookingCancellation{ID: 1, BookingID: id, Status: model.BookingCancelled, Fee: 50000}, nil
+}
+
+func (m *MockBookingService) GetTrip(ctx context.Context, id string) (*model.VehicleTrip, error) {
+	if id != "1" {
+		return nil, service.ErrNotFound
+	}
+	return &model.VehicleTrip{ID: 4, BookingID: &id, DistanceKM: 12, Status: model.TripCompleted}, nil
+}
+
+func (m *MockBookingService) Nearby(ctx context.Context, lat, lng, radiusKM float64) ([]model.NearbyBooking, error) {
+	if radiusKM <= 0 {
+		return nil, service.ErrInvalidRadius
+	}
+	return []model.NearbyBooking{{Booking: model.Booking{ID: "1", PickupLat: &lat, PickupLng: &lng}, AwayKM: radiusKM / 2}}, nil
+}
+
+func (m *MockBookingService) UpdateStop(ctx context.Context, id string, position int, user *model.User, u model.StopUpdate) (*model.Booking, error) {
+	if id != "1" || position != 1 {
+		return nil, service.ErrNotFound
+	}
+	if user == nil || !user.ActsFor(3) {
+		return nil, service.ErrTransitionForbidden
+	}
+	if u.Status == model.StopDeparted {
+		return nil, service.ErrInvalidStop
+	}
+	return &model.Booking{ID: id, Version: 2, Stops: []model.BookingStop{{Position: 1, Address: "Jl. Braga 10", Status: u.Status}}}, nil
+}
+
+func (m *MockBookingService) Within(ctx context.Context, box model.GeoBox) ([]model.Booking, error) {
+	if box.MinLat > box.MaxLat {
+		return nil, service.ErrInvalidLocation
+	}
+	return []model.Booking{{ID: "1"}}, nil
+}
+
 func TestDeleteBooking_Final(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -63,6 +159,7 @@ func TestDeleteBooking_Final(t *testing.T) {
 		router.DELETE("/bookings/:id", h.Delete)
 
 		req, _ := http.NewRequest("DELETE", "/bookings/1", nil)
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 
@@ -78,6 +175,7 @@ func TestDeleteBooking_Final(t *testing.T) {
 		router.DELETE("/bookings/:id", h.Delete)
 
 		req, _ := http.NewRequest("DELETE", "/bookings/1", nil)
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 
@@ -87,7 +185,7 @@ func TestDeleteBooking_Final(t *testing.T) {
 
 	t.Run("Empty ID (direct service call)", func(t *testing.T) {
 		mockSvc := &MockBookingService{}
-		err := mockSvc.Delete("")
+		err := mockSvc.Delete(context.Background(), "", 1)
 		assert.Error(t, err)
 		assert.Equal(t, "id required", err.Error())
 	})
@@ -189,6 +287,7 @@ func TestUpdateBooking_Success(t *testing.T) {
 	payload := model.Booking{Customer: "Updated Booking"}
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequest("PUT", "/bookings/1", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -206,6 +305,7 @@ func TestUpdateBooking_BadRequest_BindError(t *testing.T) {
 
 	body := []byte(`{"customer":`)
 	req, _ := http.NewRequest("PUT", "/bookings/1", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -224,9 +324,295 @@ func TestUpdateBooking_ServiceError(t *testing.T) {
 	payload := model.Booking{Customer: "Updated Booking"}
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequest("PUT", "/bookings/1", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestGetBookingByID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewBookingHandler(&MockBookingService{})
+
+	router := gin.New()
+	router.GET("/bookings/:id", h.GetByID)
+
+	req, _ := http.NewRequest("GET", "/bookings/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	req, _ = http.NewRequest("GET", "/bookings/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateBooking_Preconditions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		svc     *MockBookingService
+		status  int
+		etag    string
+	}{
+		{"missing If-Match", "", &MockBookingService{}, http.StatusPreconditionRequired, ""},
+		{"malformed If-Match", "abc", &MockBookingService{}, http.StatusBadRequest, ""},
+		{"stale version", `"1"`, &MockBookingService{Conflict: true}, http.StatusPreconditionFailed, ""},
+		{"current version", `W/"3"`, &MockBookingService{}, http.StatusOK, `"3"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewBookingHandler(tt.svc)
+
+			router := gin.New()
+			router.PUT("/bookings/:id", h.Update)
+
+			req, _ := http.NewRequest("PUT", "/bookings/1", bytes.NewBufferString(`{"customer":"A"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.etag, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestDeleteBooking_RequiresIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewBookingHandler(&MockBookingService{})
+
+	router := gin.New()
+	router.DELETE("/bookings/:id", h.Delete)
+
+	req, _ := http.NewRequest("DELETE", "/bookings/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+}
+
+func TestBookingTransition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		id     string
+		role   string
+		body   string
+		status int
+	}{
+		{"allowed", "1", model.RoleAdmin, `{"to":"Confirmed"}`, http.StatusOK},
+		{"missing target", "1", model.RoleAdmin, `{}`, http.StatusBadRequest},
+		{"forbidden role", "1", "user", `{"to":"Confirmed"}`, http.StatusForbidden},
+		{"invalid move", "1", model.RoleAdmin, `{"to":"Pending"}`, http.StatusConflict},
+		{"unknown booking", "2", model.RoleAdmin, `{"to":"Confirmed"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewBookingHandler(&MockBookingService{})
+
+			token, _ := utils.GenerateAccessToken(7, 1, tt.role, nil)
+
+			router := gin.New()
+			router.Use(handler.AuthMiddleware())
+			router.POST("/booking/:id/transitions", h.Transition)
+
+			req, _ := http.NewRequest("POST", "/booking/"+tt.id+"/transitions", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+				assert.Contains(t, w.Body.String(), `"status":"Confirmed"`)
+			}
+		})
+	}
+}
+
+func TestBookingStatusHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewBookingHandler(&MockBookingService{})
+
+	router := gin.New()
+	router.GET("/booking/:id/history", h.GetStatusHistory)
+
+	req, _ := http.NewRequest("GET", "/booking/1/history", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"to_status":"Confirmed"`)
+
+	req, _ = http.NewRequest("GET", "/booking/2/history", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateBookingStop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewBookingHandler(&MockBookingService{})
+
+	router := gin.New()
+	router.Use(handler.AuthMiddleware())
+	router.PUT("/booking/:id/stops/:position", h.UpdateStop)
+
+	assigned, other := 3, 4
+	driver, _ := utils.GenerateAccessToken(7, 1, model.RoleDriver, &assigned)
+	otherDriver, _ := utils.GenerateAccessToken(8, 1, model.RoleDriver, &other)
+
+	tests := []struct {
+		name   string
+		url    string
+		token  string
+		body   string
+		status int
+	}{
+		{"arrived", "/booking/1/stops/1", driver, `{"status":"arrived"}`, http.StatusOK},
+		{"not yet arrived", "/booking/1/stops/1", driver, `{"status":"departed"}`, http.StatusConflict},
+		{"unknown status", "/booking/1/stops/1", driver, `{"status":"lost"}`, http.StatusBadRequest},
+		{"bad position", "/booking/1/stops/first", driver, `{"status":"arrived"}`, http.StatusBadRequest},
+		{"unknown stop", "/booking/1/stops/2", driver, `{"status":"arrived"}`, http.StatusNotFound},
+		{"another driver", "/booking/1/stops/1", otherDriver, `{"status":"arrived"}`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", tt.url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"status":"arrived"`)
+			}
+		})
+	}
+}
+
+func TestGetBookingByCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewBookingHandler(&MockBookingService{})
+
+	router := gin.New()
+	router.GET("/booking/:id", h.GetByID)
+	router.GET("/booking/by-code/:code", h.GetByCode)
+
+	tests := []struct {
+		name   string
+		code   string
+		status int
+		want   string
+	}{
+		{"found", "BK00001A", http.StatusOK, `"id":"BK00001A"`},
+		{"unknown", "BK00001B", http.StatusNotFound, "not found"},
+		{"bad checksum", "BK00001C", http.StatusBadRequest, "invalid booking code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/booking/by-code/"+tt.code, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestBookingCancel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewBookingHandler(&MockBookingService{})
+
+	router := gin.New()
+	router.POST("/booking/:id/cancel", h.Cancel)
+	router.GET("/booking/:id/cancellation", h.GetCancellation)
+	router.GET("/booking/:id/trip", h.GetTrip)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"cancel", "POST", "/booking/1/cancel", `{"reason":"plans changed"}`, http.StatusOK, `"reason":"plans changed"`},
+		{"cancel without body", "POST", "/booking/1/cancel", "", http.StatusOK, `"status":"Cancelled"`},
+		{"bad body", "POST", "/booking/1/cancel", `{"reason":`, http.StatusBadRequest, "error"},
+		{"unknown booking", "POST", "/booking/2/cancel", "", http.StatusNotFound, "not found"},
+		{"cancellation", "GET", "/booking/1/cancellation", "", http.StatusOK, `"fee":50000`},
+		{"not cancelled", "GET", "/booking/2/cancellation", "", http.StatusNotFound, "not found"},
+		{"trip", "GET", "/booking/1/trip", "", http.StatusOK, `"DistanceKM":12`},
+		{"no trip", "GET", "/booking/2/trip", "", http.StatusNotFound, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestBookingGeoSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewBookingHandler(&MockBookingService{})
+
+	router := gin.New()
+	router.GET("/booking/nearby", h.Nearby)
+	router.GET("/booking/within", h.Within)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		want   string
+	}{
+		{"nearby", "/booking/nearby?lat=-6.2&lng=106.8&radius_km=4", http.StatusOK, `"away_km":2`},
+		{"default radius", "/booking/nearby?lat=-6.2&lng=106.8", http.StatusOK, `"away_km":1.5`},
+		{"missing lat", "/booking/nearby?lng=106.8", http.StatusBadRequest, "invalid lat"},
+		{"bad radius", "/booking/nearby?lat=-6.2&lng=106.8&radius_km=x", http.StatusBadRequest, "invalid radius_km"},
+		{"zero radius", "/booking/nearby?lat=-6.2&lng=106.8&radius_km=0", http.StatusBadRequest, "radius"},
+		{"within", "/booking/within?min_lat=-6.3&min_lng=106.7&max_lat=-6.1&max_lng=106.9", http.StatusOK, `"id":"1"`},
+		{"missing corner", "/booking/within?min_lat=-6.3&min_lng=106.7&max_lat=-6.1", http.StatusBadRequest, "invalid max_lng"},
+		{"inverted box", "/booking/within?min_lat=-6.1&min_lng=106.7&max_lat=-6.3&max_lng=106.9", http.StatusBadRequest, "latitude"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
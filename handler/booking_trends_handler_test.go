@@ -3,6 +3,7 @@ package handler_test
 import (
 	"auth-service/handler"
 	"auth-service/model"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -16,7 +17,7 @@ type MockBookingTrendService struct {
 	ReturnError bool
 }
 
-func (m *MockBookingTrendService) GetTrends(year int) ([]model.BookingTrend, error) {
+func (m *MockBookingTrendService) GetTrends(ctx context.Context, year int) ([]model.BookingTrend, error) {
 	if m.ReturnError {
 		return nil, errors.New("no trip found")
 	}
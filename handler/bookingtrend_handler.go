@@ -2,6 +2,7 @@ package handler
 
 import (
 	"auth-service/model"
+	"context"
 	"net/http"
 	"strconv"
 
@@ -9,7 +10,7 @@ import (
 )
 
 type BookingTrendsServiceInterface interface {
-	GetTrends(year int) ([]model.BookingTrend, error)
+	GetTrends(ctx context.Context, year int) ([]model.BookingTrend, error)
 }
 
 type BookingTrendsHandler struct {
@@ -24,7 +25,7 @@ func (h *BookingTrendsHandler) GetTrends(c *gin.Context) {
 	yearStr := c.DefaultQuery("year", "2024")
 	year, _ := strconv.Atoi(yearStr)
 
-	data, err := h.Service.GetTrends(year)
+	data, err := h.Service.GetTrends(c.Request.Context(), year)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	Service service.CalendarServiceInterface
+}
+
+func NewCalendarHandler(s service.CalendarServiceInterface) *CalendarHandler {
+	return &CalendarHandler{Service: s}
+}
+
+func calendarSubject(c *gin.Context, kind string) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + kind + " id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// feedURL is where calendar apps subscribe to the feed, on the host the
+// request came in on.
+func feedURL(c *gin.Context, f *model.CalendarFeed) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + "/calendar/" + f.Token + ".ics"
+}
+
+func (h *CalendarHandler) GetFeed(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := calendarSubject(c, kind)
+		if !ok {
+			return
+		}
+
+		f, err := h.Service.GetFeed(c.Request.Context(), kind, id)
+		if err != nil {
+			respondWriteError(c, err)
+			return
+		}
+		f.URL = feedURL(c, f)
+		c.JSON(http.StatusOK, f)
+	}
+}
+
+// IssueFeed creates the feed, or rotates its token when it already exists.
+func (h *CalendarHandler) IssueFeed(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := calendarSubject(c, kind)
+		if !ok {
+			return
+		}
+
+		f, err := h.Service.IssueFeed(c.Request.Context(), kind, id)
+		if err != nil {
+			respondWriteError(c, err)
+			return
+		}
+		f.URL = feedURL(c, f)
+		c.JSON(http.StatusCreated, f)
+	}
+}
+
+func (h *CalendarHandler) RevokeFeed(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := calendarSubject(c, kind)
+		if !ok {
+			return
+		}
+
+		if err := h.Service.RevokeFeed(c.Request.Context(), kind, id); err != nil {
+			respondWriteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "calendar feed revoked"})
+	}
+}
+
+// Serve is fetched by calendar apps without logging in; the token in the URL
+// is the credential.
+func (h *CalendarHandler) Serve(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	body, err := h.Service.Render(c.Request.Context(), token)
+	if errors.Is(err, service.ErrNotFound) {
+		c.String(http.StatusNotFound, "calendar not found")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Disposition", "inline; filename=schedule.ics")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
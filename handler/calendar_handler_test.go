@@ -0,0 +1,93 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockCalendarService struct{}
+
+func (m *MockCalendarService) GetFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error) {
+	if subjectID != 1 {
+		return nil, service.ErrNotFound
+	}
+	return &model.CalendarFeed{Token: "abc", Kind: kind, SubjectID: subjectID}, nil
+}
+
+func (m *MockCalendarService) IssueFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error) {
+	if subjectID != 1 {
+		return nil, service.ErrNotFound
+	}
+	return &model.CalendarFeed{Token: "def", Kind: kind, SubjectID: subjectID}, nil
+}
+
+func (m *MockCalendarService) RevokeFeed(ctx context.Context, kind string, subjectID int) error {
+	if subjectID != 1 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (m *MockCalendarService) Render(ctx context.Context, token string) ([]byte, error) {
+	if token != "abc" {
+		return nil, service.ErrNotFound
+	}
+	return []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), nil
+}
+
+func TestCalendarHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewCalendarHandler(&MockCalendarService{})
+	router := gin.New()
+	router.GET("/calendar/:token", h.Serve)
+	router.GET("/drivers/:id/calendar-feed", h.GetFeed(model.CalendarDriver))
+	router.POST("/drivers/:id/calendar-feed", h.IssueFeed(model.CalendarDriver))
+	router.DELETE("/drivers/:id/calendar-feed", h.RevokeFeed(model.CalendarDriver))
+	router.POST("/car/:id/calendar-feed", h.IssueFeed(model.CalendarVehicle))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		want   string
+	}{
+		{"get feed", "GET", "/drivers/1/calendar-feed", http.StatusOK, `"url":"http://example.com/calendar/abc.ics"`},
+		{"no feed", "GET", "/drivers/2/calendar-feed", http.StatusNotFound, "not found"},
+		{"bad id", "GET", "/drivers/x/calendar-feed", http.StatusBadRequest, "invalid driver id"},
+		{"issue", "POST", "/drivers/1/calendar-feed", http.StatusCreated, `"token":"def"`},
+		{"issue vehicle", "POST", "/car/1/calendar-feed", http.StatusCreated, `"kind":"vehicle"`},
+		{"bad vehicle id", "POST", "/car/x/calendar-feed", http.StatusBadRequest, "invalid vehicle id"},
+		{"issue for unknown driver", "POST", "/drivers/2/calendar-feed", http.StatusNotFound, "not found"},
+		{"revoke", "DELETE", "/drivers/1/calendar-feed", http.StatusOK, "revoked"},
+		{"revoke missing", "DELETE", "/drivers/2/calendar-feed", http.StatusNotFound, "not found"},
+		{"serve", "GET", "/calendar/abc.ics", http.StatusOK, "BEGIN:VCALENDAR"},
+		{"serve without suffix", "GET", "/calendar/abc", http.StatusOK, "BEGIN:VCALENDAR"},
+		{"unknown token", "GET", "/calendar/zzz.ics", http.StatusNotFound, "calendar not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "http://example.com"+tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+
+	req, _ := http.NewRequest("GET", "/calendar/abc.ics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CancellationHandler struct {
+	Service service.CancellationServiceInterface
+}
+
+func NewCancellationHandler(s service.CancellationServiceInterface) *CancellationHandler {
+	return &CancellationHandler{Service: s}
+}
+
+func (h *CancellationHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.Service.GetPolicy(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *CancellationHandler) SavePolicy(c *gin.Context) {
+	var p model.CancellationPolicy
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Service.SavePolicy(c.Request.Context(), &p); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
@@ -0,0 +1,64 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockCancellationService struct {
+	Saved *model.CancellationPolicy
+}
+
+func (m *MockCancellationService) GetPolicy(ctx context.Context) (*model.CancellationPolicy, error) {
+	p := model.DefaultCancellationPolicy()
+	return &p, nil
+}
+
+func (m *MockCancellationService) SavePolicy(ctx context.Context, p *model.CancellationPolicy) error {
+	m.Saved = p
+	return nil
+}
+
+func TestCancellationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &MockCancellationService{}
+	h := handler.NewCancellationHandler(svc)
+	router := gin.New()
+	router.GET("/cancellation-policy", h.GetPolicy)
+	router.PUT("/cancellation-policy", h.SavePolicy)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		want   string
+	}{
+		{"get", "GET", "", http.StatusOK, `"free_hours":24`},
+		{"save", "PUT", `{"free_hours":6,"late_fee_percent":25,"minimum_late_fee":20000,"no_show_fee_percent":100}`, http.StatusOK, `"late_fee_percent":25`},
+		{"fee above fare", "PUT", `{"free_hours":6,"late_fee_percent":150}`, http.StatusBadRequest, "LateFeePercent"},
+		{"negative hours", "PUT", `{"free_hours":-1}`, http.StatusBadRequest, "FreeHours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "/cancellation-policy", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+	assert.Equal(t, 20000.0, svc.Saved.MinimumLateFee)
+}
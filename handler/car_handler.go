@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
@@ -10,11 +11,11 @@ import (
 )
 
 type CarServiceInterface interface {
-	GetAll() ([]model.Car, error)
-	GetByID(int) (*model.Car, error)
-	Create(model.Car) error
-	Update(int, model.Car) error
-	Delete(int) error
+	GetAll(context.Context) ([]model.Car, error)
+	GetByID(context.Context, int) (*model.Car, error)
+	Create(context.Context, model.Car) error
+	Update(context.Context, int, model.Car) error
+	Delete(context.Context, int, int) error
 }
 
 type CarHandler struct {
@@ -26,7 +27,7 @@ func NewCarHandler(service CarServiceInterface) *CarHandler {
 }
 
 func (h *CarHandler) GetAll(c *gin.Context) {
-	data, err := h.Service.GetAll()
+	data, err := h.Service.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -36,7 +37,7 @@ func (h *CarHandler) GetAll(c *gin.Context) {
 
 func (h *CarHandler) GetByID(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	v, err := h.Service.GetByID(id)
+	v, err := h.Service.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -45,6 +46,7 @@ func (h *CarHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
 		return
 	}
+	setETag(c, v.Version)
 	c.JSON(http.StatusOK, v)
 }
 
@@ -55,7 +57,7 @@ func (h *CarHandler) Create(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.Create(v); err != nil {
+	if err := h.Service.Create(c.Request.Context(), v); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -72,19 +74,31 @@ func (h *CarHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.Update(id, v); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	v.Version = version
+	if err := h.Service.Update(c.Request.Context(), id, v); err != nil {
+		respondWriteError(c, err)
 		return
 	}
 
+	setETag(c, version+1)
 	c.JSON(http.StatusOK, gin.H{"message": "Car updated"})
 }
 
 func (h *CarHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	if err := h.Service.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Delete(c.Request.Context(), id, version); err != nil {
+		respondWriteError(c, err)
 		return
 	}
 
@@ -3,6 +3,7 @@ package handler_test
 import (
 	"auth-service/handler"
 	"auth-service/model"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -17,7 +18,7 @@ type MockCarService struct {
 	ReturnError bool
 }
 
-func (m *MockCarService) GetAll() ([]model.Car, error) {
+func (m *MockCarService) GetAll(ctx context.Context) ([]model.Car, error) {
 	if m.ReturnError {
 		return nil, errors.New("failed to fetch cars")
 	}
@@ -27,7 +28,7 @@ func (m *MockCarService) GetAll() ([]model.Car, error) {
 	}, nil
 }
 
-func (m *MockCarService) GetByID(id int) (*model.Car, error) {
+func (m *MockCarService) GetByID(ctx context.Context, id int) (*model.Car, error) {
 	if m.ReturnError {
 		return nil, errors.New("service error")
 	}
@@ -37,14 +38,14 @@ func (m *MockCarService) GetByID(id int) (*model.Car, error) {
 	return &model.Car{ID: 1, Brand: "Car A"}, nil
 }
 
-func (m *MockCarService) Create(car model.Car) error {
+func (m *MockCarService) Create(ctx context.Context, car model.Car) error {
 	if m.ReturnError {
 		return errors.New("failed to create car")
 	}
 	return nil
 }
 
-func (m *MockCarService) Update(id int, car model.Car) error {
+func (m *MockCarService) Update(ctx context.Context, id int, car model.Car) error {
 	if m.ReturnError {
 		return errors.New("failed to update car")
 	}
@@ -54,7 +55,7 @@ func (m *MockCarService) Update(id int, car model.Car) error {
 	return nil
 }
 
-func (m *MockCarService) Delete(id int) error {
+func (m *MockCarService) Delete(ctx context.Context, id int, version int) error {
 	if m.ReturnError {
 		return errors.New("failed to delete car")
 	}
@@ -171,6 +172,7 @@ func TestCarHandler_Update(t *testing.T) {
 
 	payload := `{"name":"Car Updated"}`
 	req, _ := http.NewRequest("PUT", "/cars/1", strings.NewReader(payload))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -186,6 +188,7 @@ func TestCarHandler_Delete(t *testing.T) {
 	router.DELETE("/cars/:id", h.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/cars/1", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -248,6 +251,7 @@ func TestCarHandler_Update_Error(t *testing.T) {
 
 	payload := `{"name":"Car Updated"}`
 	req, _ := http.NewRequest("PUT", "/cars/1", strings.NewReader(payload))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -264,6 +268,7 @@ func TestCarHandler_Update_NotFound(t *testing.T) {
 
 	payload := `{"name":"Car Updated"}`
 	req, _ := http.NewRequest("PUT", "/cars/2", strings.NewReader(payload))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -279,6 +284,7 @@ func TestCarHandler_Delete_Error(t *testing.T) {
 	router.DELETE("/cars/:id", h.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/cars/1", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -308,6 +314,7 @@ func TestCarHandler_Update_InvalidID(t *testing.T) {
 
 	payload := `{"name":"Car Updated"}`
 	req, _ := http.NewRequest("PUT", "/cars/abc", strings.NewReader(payload))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -323,6 +330,7 @@ func TestCarHandler_Delete_InvalidID(t *testing.T) {
 	router.DELETE("/cars/:id", h.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/cars/abc", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
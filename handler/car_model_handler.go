@@ -2,6 +2,7 @@ package handler
 
 import (
 	"auth-service/model"
+	"context"
 	"net/http"
 	"strconv"
 
@@ -9,9 +10,9 @@ import (
 )
 
 type CarModelServiceInterface interface {
-	GetAll() ([]model.CarModel, error)
-	GetByID(int) (*model.CarModel, error)
-	Create(model.CarModel) error
+	GetAll(context.Context) ([]model.CarModel, error)
+	GetByID(context.Context, int) (*model.CarModel, error)
+	Create(context.Context, model.CarModel) error
 }
 
 type CarModelHandler struct {
@@ -23,7 +24,7 @@ func NewCarModelRepositoryHandler(service CarModelServiceInterface) *CarModelHan
 }
 
 func (h *CarModelHandler) GetAll(c *gin.Context) {
-	data, err := h.Service.GetAll()
+	data, err := h.Service.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -39,7 +40,7 @@ func (h *CarModelHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	carModel, err := h.Service.GetByID(id)
+	carModel, err := h.Service.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -59,7 +60,7 @@ func (h *CarModelHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.Service.Create(body); err != nil {
+	if err := h.Service.Create(c.Request.Context(), body); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -3,6 +3,7 @@ package handler_test
 import (
 	"auth-service/handler"
 	"auth-service/model"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -23,7 +24,7 @@ func TestNewCarModelRepositoryHandler(t *testing.T) {
 	assert.NotNil(t, h)
 }
 
-func (m *MockCarModelService) GetAll() ([]model.CarModel, error) {
+func (m *MockCarModelService) GetAll(ctx context.Context) ([]model.CarModel, error) {
 	if m.ReturnError {
 		return nil, errors.New("failed to fetch car models")
 	}
@@ -33,7 +34,7 @@ func (m *MockCarModelService) GetAll() ([]model.CarModel, error) {
 	}, nil
 }
 
-func (m *MockCarModelService) GetByID(id int) (*model.CarModel, error) {
+func (m *MockCarModelService) GetByID(ctx context.Context, id int) (*model.CarModel, error) {
 	if m.ReturnError {
 		return nil, errors.New("car model not found")
 	}
@@ -43,7 +44,7 @@ func (m *MockCarModelService) GetByID(id int) (*model.CarModel, error) {
 	return &model.CarModel{ID: 1, ModelName: "Model A"}, nil
 }
 
-func (m *MockCarModelService) Create(cm model.CarModel) error {
+func (m *MockCarModelService) Create(ctx context.Context, cm model.CarModel) error {
 	if m.ReturnError {
 		return errors.New("failed to create car model")
 	}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"auth-service/model"
+	"context"
 	"net/http"
 	"strconv"
 
@@ -9,9 +10,9 @@ import (
 )
 
 type CarTypeServiceInterface interface {
-	GetAll() ([]model.CarType, error)
-	GetByID(id int) (*model.CarType, error)
-	Create(model.CarType) error
+	GetAll(context.Context) ([]model.CarType, error)
+	GetByID(ctx context.Context, id int) (*model.CarType, error)
+	Create(context.Context, model.CarType) error
 }
 type CarTypeHandler struct {
 	Service CarTypeServiceInterface
@@ -21,7 +22,7 @@ func NewCarTypeHandler(s CarTypeServiceInterface) *CarTypeHandler {
 	return &CarTypeHandler{Service: s}
 }
 func (h *CarTypeHandler) GetAll(c *gin.Context) {
-	data, err := h.Service.GetAll()
+	data, err := h.Service.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -37,7 +38,7 @@ func (h *CarTypeHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	carModel, err := h.Service.GetByID(id)
+	carModel, err := h.Service.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -59,7 +60,7 @@ func (h *CarTypeHandler) Create(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.Create(body); err != nil {
+	if err := h.Service.Create(c.Request.Context(), body); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -3,6 +3,7 @@ package handler_test
 import (
 	"auth-service/handler"
 	"auth-service/model"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -18,12 +19,12 @@ type MockCarTypeService struct {
 	mock.Mock
 }
 
-func (m *MockCarTypeService) GetAll() ([]model.CarType, error) {
+func (m *MockCarTypeService) GetAll(ctx context.Context) ([]model.CarType, error) {
 	args := m.Called()
 	return args.Get(0).([]model.CarType), args.Error(1)
 }
 
-func (m *MockCarTypeService) GetByID(id int) (*model.CarType, error) {
+func (m *MockCarTypeService) GetByID(ctx context.Context, id int) (*model.CarType, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -31,7 +32,7 @@ func (m *MockCarTypeService) GetByID(id int) (*model.CarType, error) {
 	return args.Get(0).(*model.CarType), args.Error(1)
 }
 
-func (m *MockCarTypeService) Create(ct model.CarType) error {
+func (m *MockCarTypeService) Create(ctx context.Context, ct model.CarType) error {
 	args := m.Called(ct)
 	return args.Error(0)
 }
@@ -3,6 +3,7 @@ package handler_test
 import (
 	"auth-service/handler"
 	"auth-service/model"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -14,7 +15,7 @@ import (
 
 type MockPopularDestinationService struct{}
 
-func (m *MockPopularDestinationService) GetAll() ([]model.PopularDestination, error) {
+func (m *MockPopularDestinationService) GetAll(ctx context.Context) ([]model.PopularDestination, error) {
 	return []model.PopularDestination{
 		{Destination: "Bali", Bookings: 100},
 		{Destination: "Jakarta", Bookings: 50},
@@ -23,7 +24,7 @@ func (m *MockPopularDestinationService) GetAll() ([]model.PopularDestination, er
 
 type MockErrorService struct{}
 
-func (m *MockErrorService) GetAll() ([]model.PopularDestination, error) {
+func (m *MockErrorService) GetAll(ctx context.Context) ([]model.PopularDestination, error) {
 	return nil, errors.New("service error")
 }
 
@@ -11,7 +11,7 @@ type PopularDestinationHandler struct {
 }
 
 func (h *PopularDestinationHandler) GetAll(c *gin.Context) {
-	data, err := h.Service.GetAll()
+	data, err := h.Service.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
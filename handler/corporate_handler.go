@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CorporateHandler struct {
+	Service service.CorporateServiceInterface
+}
+
+func NewCorporateHandler(s service.CorporateServiceInterface) *CorporateHandler {
+	return &CorporateHandler{Service: s}
+}
+
+func (h *CorporateHandler) GetAccounts(c *gin.Context) {
+	accounts, err := h.Service.GetAccounts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (h *CorporateHandler) GetAccount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	account, err := h.Service.GetAccount(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	if account == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "corporate account not found"})
+		return
+	}
+
+	setETag(c, account.Version)
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *CorporateHandler) CreateAccount(c *gin.Context) {
+	account := model.CorporateAccount{Active: true}
+	if err := c.ShouldBindJSON(&account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Service.CreateAccount(c.Request.Context(), &account); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, account.Version)
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *CorporateHandler) UpdateAccount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var account model.CorporateAccount
+	if err := c.ShouldBindJSON(&account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	account.ID = id
+	account.Version = version
+	if err := h.Service.UpdateAccount(c.Request.Context(), &account); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, account.Version)
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *CorporateHandler) GetBalance(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	balance, err := h.Service.GetBalance(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+func (h *CorporateHandler) GetInvoices(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	invoices, err := h.Service.GetInvoices(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invoices)
+}
+
+// CreateInvoice bills the account for the month given as ?month=YYYY-MM,
+// the previous calendar month by default.
+func (h *CorporateHandler) CreateInvoice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	if raw := c.Query("month"); raw != "" {
+		month, err = time.ParseInLocation("2006-01", raw, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "month must be YYYY-MM"})
+			return
+		}
+	}
+
+	invoice, err := h.Service.Invoice(c.Request.Context(), id, month)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, invoice)
+}
+
+func (h *CorporateHandler) GetInvoice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice id"})
+		return
+	}
+
+	invoice, err := h.Service.GetInvoice(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	if invoice == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+		return
+	}
+	c.JSON(http.StatusOK, invoice)
+}
+
+func (h *CorporateHandler) RecordPayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice id"})
+		return
+	}
+
+	var payment model.InvoicePayment
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if user := CurrentUser(c); user != nil {
+		recordedBy := int(user.ID)
+		payment.RecordedBy = &recordedBy
+	}
+
+	invoice, err := h.Service.RecordPayment(c.Request.Context(), id, &payment)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, invoice)
+}
+
+func (h *CorporateHandler) InvoicePDF(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invoice id"})
+		return
+	}
+
+	pdfBytes, filename, err := h.Service.InvoicePDF(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
@@ -0,0 +1,152 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockCorporateService struct{}
+
+func (m *MockCorporateService) GetAccounts(ctx context.Context) ([]model.CorporateAccount, error) {
+	return []model.CorporateAccount{{ID: 1, Name: "PT Maju"}}, nil
+}
+
+func (m *MockCorporateService) GetAccount(ctx context.Context, id int) (*model.CorporateAccount, error) {
+	if id != 1 {
+		return nil, nil
+	}
+	return &model.CorporateAccount{ID: 1, Name: "PT Maju", Version: 2}, nil
+}
+
+func (m *MockCorporateService) CreateAccount(ctx context.Context, a *model.CorporateAccount) error {
+	if a.Name == "Taken" {
+		return service.ErrAccountNameTaken
+	}
+	a.ID, a.Version = 2, 1
+	return nil
+}
+
+func (m *MockCorporateService) UpdateAccount(ctx context.Context, a *model.CorporateAccount) error {
+	if a.Version != 2 {
+		return service.ErrVersionConflict
+	}
+	a.Version++
+	return nil
+}
+
+func (m *MockCorporateService) GetBalance(ctx context.Context, id int) (*model.AccountBalance, error) {
+	if id != 1 {
+		return nil, service.ErrNotFound
+	}
+	return &model.AccountBalance{AccountID: 1, Unbilled: 300000}, nil
+}
+
+func (m *MockCorporateService) GetInvoices(ctx context.Context, accountID int) ([]model.CorporateInvoice, error) {
+	return []model.CorporateInvoice{{ID: 5, AccountID: accountID, Number: "INV-202609-0001"}}, nil
+}
+
+func (m *MockCorporateService) GetInvoice(ctx context.Context, id int) (*model.CorporateInvoice, error) {
+	if id != 5 {
+		return nil, nil
+	}
+	return &model.CorporateInvoice{ID: 5, Number: "INV-202609-0001", Total: 300000}, nil
+}
+
+func (m *MockCorporateService) Invoice(ctx context.Context, accountID int, month time.Time) (*model.CorporateInvoice, error) {
+	if month.Month() == time.January {
+		return nil, service.ErrInvoiceExists
+	}
+	return &model.CorporateInvoice{ID: 6, AccountID: accountID, Number: "INV-" + month.Format("200601") + "-0001"}, nil
+}
+
+func (m *MockCorporateService) RecordPayment(ctx context.Context, invoiceID int, p *model.InvoicePayment) (*model.CorporateInvoice, error) {
+	if p.Amount > 300000 {
+		return nil, service.ErrInvoiceOverpaid
+	}
+	return &model.CorporateInvoice{ID: invoiceID, AmountPaid: p.Amount, Status: model.InvoicePartiallyPaid}, nil
+}
+
+func (m *MockCorporateService) InvoicePDF(ctx context.Context, id int) ([]byte, string, error) {
+	if id != 5 {
+		return nil, "", service.ErrNotFound
+	}
+	return []byte("%PDF"), "invoice_INV-202609-0001.pdf", nil
+}
+
+func TestCorporateHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewCorporateHandler(&MockCorporateService{})
+	router := gin.New()
+	router.GET("/corporate-accounts", h.GetAccounts)
+	router.POST("/corporate-accounts", h.CreateAccount)
+	router.GET("/corporate-accounts/:id", h.GetAccount)
+	router.PUT("/corporate-accounts/:id", h.UpdateAccount)
+	router.GET("/corporate-accounts/:id/balance", h.GetBalance)
+	router.GET("/corporate-accounts/:id/invoices", h.GetInvoices)
+	router.POST("/corporate-accounts/:id/invoices", h.CreateInvoice)
+	router.GET("/invoices/:id", h.GetInvoice)
+	router.GET("/invoices/:id/pdf", h.InvoicePDF)
+	router.POST("/invoices/:id/payments", h.RecordPayment)
+
+	account := `{"name":"PT Maju","booker_ids":[4],"cost_centres":["SALES"],"credit_limit":5000000}`
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		ifMatch string
+		status  int
+		want    string
+	}{
+		{"list", "GET", "/corporate-accounts", "", "", http.StatusOK, `"name":"PT Maju"`},
+		{"create", "POST", "/corporate-accounts", account, "", http.StatusCreated, `"active":true`},
+		{"missing name", "POST", "/corporate-accounts", `{"credit_limit":100}`, "", http.StatusBadRequest, "Name"},
+		{"negative limit", "POST", "/corporate-accounts", `{"name":"X","credit_limit":-1}`, "", http.StatusBadRequest, "CreditLimit"},
+		{"name taken", "POST", "/corporate-accounts", `{"name":"Taken"}`, "", http.StatusConflict, "already exists"},
+		{"get", "GET", "/corporate-accounts/1", "", "", http.StatusOK, `"version":2`},
+		{"unknown", "GET", "/corporate-accounts/9", "", "", http.StatusNotFound, "not found"},
+		{"bad id", "GET", "/corporate-accounts/x", "", "", http.StatusBadRequest, "invalid account id"},
+		{"update", "PUT", "/corporate-accounts/1", account, `"2"`, http.StatusOK, `"version":3`},
+		{"stale update", "PUT", "/corporate-accounts/1", account, `"1"`, http.StatusPreconditionFailed, "modified"},
+		{"balance", "GET", "/corporate-accounts/1/balance", "", "", http.StatusOK, `"unbilled":300000`},
+		{"balance of unknown account", "GET", "/corporate-accounts/9/balance", "", "", http.StatusNotFound, "not found"},
+		{"invoices", "GET", "/corporate-accounts/1/invoices", "", "", http.StatusOK, `"number":"INV-202609-0001"`},
+		{"invoice month", "POST", "/corporate-accounts/1/invoices?month=2026-08", "", "", http.StatusCreated, `"number":"INV-202608-0001"`},
+		{"bad month", "POST", "/corporate-accounts/1/invoices?month=August", "", "", http.StatusBadRequest, "YYYY-MM"},
+		{"already invoiced", "POST", "/corporate-accounts/1/invoices?month=2026-01", "", "", http.StatusConflict, "already been invoiced"},
+		{"invoice", "GET", "/invoices/5", "", "", http.StatusOK, `"total":300000`},
+		{"unknown invoice", "GET", "/invoices/6", "", "", http.StatusNotFound, "invoice not found"},
+		{"payment", "POST", "/invoices/5/payments", `{"amount":100000,"reference":"TRF-1"}`, "", http.StatusCreated, `"status":"partially_paid"`},
+		{"zero payment", "POST", "/invoices/5/payments", `{"amount":0}`, "", http.StatusBadRequest, "Amount"},
+		{"overpayment", "POST", "/invoices/5/payments", `{"amount":300001}`, "", http.StatusConflict, "exceeds"},
+		{"pdf", "GET", "/invoices/5/pdf", "", "", http.StatusOK, "%PDF"},
+		{"pdf of unknown invoice", "GET", "/invoices/6/pdf", "", "", http.StatusNotFound, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomerHandler struct {
+	Service service.CustomerServiceInterface
+}
+
+func NewCustomerHandler(s service.CustomerServiceInterface) *CustomerHandler {
+	return &CustomerHandler{Service: s}
+}
+
+func (h *CustomerHandler) Search(c *gin.Context) {
+	customers, err := h.Service.Search(c.Request.Context(), c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, customers)
+}
+
+func (h *CustomerHandler) GetDetail(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	detail, err := h.Service.GetDetail(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, detail.Version)
+	c.JSON(http.StatusOK, detail)
+}
+
+func (h *CustomerHandler) Create(c *gin.Context) {
+	var customer model.Customer
+	if err := c.ShouldBindJSON(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Service.Create(c.Request.Context(), &customer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, customer.Version)
+	c.JSON(http.StatusCreated, customer)
+}
+
+func (h *CustomerHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	var customer model.Customer
+	if err := c.ShouldBindJSON(&customer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	customer.ID = id
+	customer.Version = version
+	if err := h.Service.Update(c.Request.Context(), &customer); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, customer.Version)
+	c.JSON(http.StatusOK, customer)
+}
+
+func (h *CustomerHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Delete(c.Request.Context(), id, version); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Customer deleted successfully"})
+}
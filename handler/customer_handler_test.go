@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockCustomerService struct{}
+
+func (m *MockCustomerService) Search(ctx context.Context, query string) ([]model.Customer, error) {
+	return []model.Customer{{ID: 1, Name: "Sari " + query}}, nil
+}
+
+func (m *MockCustomerService) GetByID(ctx context.Context, id int) (*model.Customer, error) {
+	return &model.Customer{ID: id, Name: "Sari"}, nil
+}
+
+func (m *MockCustomerService) GetDetail(ctx context.Context, id int) (*model.CustomerDetail, error) {
+	if id != 1 {
+		return nil, service.ErrNotFound
+	}
+	return &model.CustomerDetail{
+		Customer: model.Customer{ID: 1, Name: "Sari", Version: 2},
+		Bookings: []model.Booking{{ID: "BK1"}},
+	}, nil
+}
+
+func (m *MockCustomerService) Create(ctx context.Context, c *model.Customer) error {
+	c.ID = 1
+	c.Version = 1
+	return nil
+}
+
+func (m *MockCustomerService) Update(ctx context.Context, c *model.Customer) error {
+	if c.Version != 2 {
+		return service.ErrVersionConflict
+	}
+	c.Version++
+	return nil
+}
+
+func (m *MockCustomerService) Delete(ctx context.Context, id int, version int) error {
+	return nil
+}
+
+func TestCustomerHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewCustomerHandler(&MockCustomerService{})
+	router := gin.New()
+	router.GET("/customers", h.Search)
+	router.POST("/customers", h.Create)
+	router.GET("/customers/:id", h.GetDetail)
+	router.PUT("/customers/:id", h.Update)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers?q=0812", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Sari 0812")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/customers", bytes.NewBufferString(`{"phones":["0812"]}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/customers", bytes.NewBufferString(`{"name":"Sari","phones":["0812"]}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers/1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"bookings":[{"id":"BK1"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers/2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/customers/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req := httptest.NewRequest(http.MethodPut, "/customers/1", bytes.NewBufferString(`{"name":"Sari","blacklisted":true}`))
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/customers/1", bytes.NewBufferString(`{"name":"Sari","blacklisted":true}`))
+	req.Header.Set("If-Match", `"2"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
@@ -16,7 +16,7 @@ func NewDashboardHandler(s service.DashboardServiceInterface) *DashboardHandler
 }
 
 func (h *DashboardHandler) GetDashboard(c *gin.Context) {
-	data, err := h.service.GetDashboardData()
+	data, err := h.service.GetDashboardData(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
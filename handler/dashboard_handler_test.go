@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"auth-service/handler"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -17,7 +18,7 @@ type DashboardService interface {
 
 type MockDashboardService struct{}
 
-func (s *MockDashboardService) GetDashboardData() (map[string]interface{}, error) {
+func (s *MockDashboardService) GetDashboardData(ctx context.Context) (map[string]interface{}, error) {
 	data := map[string]interface{}{
 		"totalUsers": 10,
 		"totalSales": 5000,
@@ -27,7 +28,7 @@ func (s *MockDashboardService) GetDashboardData() (map[string]interface{}, error
 
 type MockDashboardServiceError struct{}
 
-func (m *MockDashboardServiceError) GetDashboardData() (map[string]interface{}, error) {
+func (m *MockDashboardServiceError) GetDashboardData(ctx context.Context) (map[string]interface{}, error) {
 	return nil, errors.New("failed to fetch dashboard data")
 }
 
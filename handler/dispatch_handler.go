@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DispatchHandler struct {
+	Service service.DispatchServiceInterface
+}
+
+func NewDispatchHandler(s service.DispatchServiceInterface) *DispatchHandler {
+	return &DispatchHandler{Service: s}
+}
+
+func (h *DispatchHandler) Candidates(c *gin.Context) {
+	candidates, err := h.Service.Candidates(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, candidates)
+}
+
+func (h *DispatchHandler) Dispatch(c *gin.Context) {
+	var req model.DispatchRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.Service.Dispatch(c.Request.Context(), c.Param("id"), CurrentUser(c), req.Auto)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	if result.Booking != nil {
+		setETag(c, result.Booking.Version)
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *DispatchHandler) GetOffers(c *gin.Context) {
+	offers, err := h.Service.GetOffers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, offers)
+}
+
+func (h *DispatchHandler) Accept(c *gin.Context) {
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer id"})
+		return
+	}
+
+	booking, err := h.Service.Accept(c.Request.Context(), offerID, CurrentUser(c))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, booking.Version)
+	c.JSON(http.StatusOK, booking)
+}
+
+func (h *DispatchHandler) Decline(c *gin.Context) {
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer id"})
+		return
+	}
+
+	next, err := h.Service.Decline(c.Request.Context(), offerID, CurrentUser(c))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Offer declined", "next_offer": next})
+}
+
+func (h *DispatchHandler) SetLocation(c *gin.Context) {
+	driverID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+
+	var req struct {
+		Latitude  *float64 `json:"latitude" binding:"required"`
+		Longitude *float64 `json:"longitude" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	location := model.DriverLocation{DriverID: driverID, Latitude: *req.Latitude, Longitude: *req.Longitude}
+	if err := h.Service.SetLocation(c.Request.Context(), &location, CurrentUser(c)); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, location)
+}
@@ -0,0 +1,116 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"auth-service/utils"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockDispatchService struct{}
+
+func (m *MockDispatchService) Candidates(ctx context.Context, bookingID string) ([]model.DispatchCandidate, error) {
+	if bookingID != "BK1" {
+		return nil, service.ErrNotDispatchable
+	}
+	return []model.DispatchCandidate{{DriverID: 2, Name: "Budi", Score: 12}}, nil
+}
+
+func (m *MockDispatchService) Dispatch(ctx context.Context, bookingID string, user *model.User, auto bool) (*model.DispatchResult, error) {
+	if auto {
+		driverID := 2
+		return &model.DispatchResult{Booking: &model.Booking{ID: bookingID, DriverID: &driverID, Status: model.BookingDriverAssigned, Version: 4}}, nil
+	}
+	return &model.DispatchResult{Offer: &model.DispatchOffer{ID: 1, BookingID: bookingID, DriverID: 2, Status: model.OfferPending}}, nil
+}
+
+func (m *MockDispatchService) GetOffers(ctx context.Context, bookingID string) ([]model.DispatchOffer, error) {
+	return []model.DispatchOffer{{ID: 1, BookingID: bookingID}}, nil
+}
+
+func (m *MockDispatchService) Accept(ctx context.Context, offerID int, user *model.User) (*model.Booking, error) {
+	if user == nil || !user.IsDriver() {
+		return nil, service.ErrTransitionForbidden
+	}
+	if offerID != 1 {
+		return nil, service.ErrOfferExpired
+	}
+	if !user.ActsFor(2) {
+		return nil, service.ErrTransitionForbidden
+	}
+	return &model.Booking{ID: "BK1", Status: model.BookingDriverAssigned, Version: 4}, nil
+}
+
+func (m *MockDispatchService) Decline(ctx context.Context, offerID int, user *model.User) (*model.DispatchOffer, error) {
+	return nil, nil
+}
+
+func (m *MockDispatchService) SetLocation(ctx context.Context, l *model.DriverLocation, user *model.User) error {
+	if user == nil || !user.ActsFor(l.DriverID) {
+		return service.ErrTransitionForbidden
+	}
+	return nil
+}
+
+func TestDispatchHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewDispatchHandler(&MockDispatchService{})
+	router := gin.New()
+	router.Use(handler.AuthMiddleware())
+	router.GET("/booking/:id/dispatch/candidates", h.Candidates)
+	router.POST("/booking/:id/dispatch", h.Dispatch)
+	router.POST("/dispatch/offers/:id/accept", h.Accept)
+	router.POST("/dispatch/offers/:id/decline", h.Decline)
+	router.PUT("/drivers/:id/location", h.SetLocation)
+
+	admin, _ := utils.GenerateAccessToken(1, 1, model.RoleAdmin, nil)
+	driverID, otherID := 2, 5
+	driver, _ := utils.GenerateAccessToken(7, 1, model.RoleDriver, &driverID)
+	other, _ := utils.GenerateAccessToken(8, 1, model.RoleDriver, &otherID)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+		want   string
+	}{
+		{"candidates", "GET", "/booking/BK1/dispatch/candidates", admin, "", http.StatusOK, `"name":"Budi"`},
+		{"not dispatchable", "GET", "/booking/BK2/dispatch/candidates", admin, "", http.StatusConflict, "confirmed"},
+		{"offer", "POST", "/booking/BK1/dispatch", admin, "", http.StatusCreated, `"offer":{"id":1`},
+		{"auto assign", "POST", "/booking/BK1/dispatch", admin, `{"auto":true}`, http.StatusOK, `"status":"DriverAssigned"`},
+		{"accept", "POST", "/dispatch/offers/1/accept", driver, "", http.StatusOK, `"id":"BK1"`},
+		{"accept as admin user", "POST", "/dispatch/offers/1/accept", admin, "", http.StatusForbidden, "role"},
+		{"accept other driver's offer", "POST", "/dispatch/offers/1/accept", other, "", http.StatusForbidden, "role"},
+		{"accept expired", "POST", "/dispatch/offers/2/accept", driver, "", http.StatusConflict, "expired"},
+		{"decline", "POST", "/dispatch/offers/1/decline", driver, "", http.StatusOK, `"next_offer":null`},
+		{"location", "PUT", "/drivers/2/location", driver, `{"latitude":0,"longitude":106.8}`, http.StatusOK, `"latitude":0`},
+		{"location missing", "PUT", "/drivers/2/location", driver, `{"latitude":-6.2}`, http.StatusBadRequest, "Longitude"},
+		{"location as admin", "PUT", "/drivers/2/location", admin, `{"latitude":-6.2,"longitude":106.8}`, http.StatusOK, `"driver_id":2`},
+		{"other driver's location", "PUT", "/drivers/2/location", other, `{"latitude":-6.2,"longitude":106.8}`, http.StatusForbidden, "role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
@@ -2,17 +2,18 @@ package handler
 
 import (
 	"auth-service/model"
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type DriverServiceInterface interface {
-	GetAll() ([]model.Driver, error)
-	Create(*model.Driver) error
-	GetByID(string) (*model.Driver, error)
-	Update(string, *model.Driver) error
-	Delete(string) error
+	GetAll(context.Context) ([]model.Driver, error)
+	Create(context.Context, *model.Driver) error
+	GetByID(context.Context, string) (*model.Driver, error)
+	Update(context.Context, string, *model.Driver) error
+	Delete(context.Context, string, int) error
 }
 
 type DriverHandler struct {
@@ -24,7 +25,7 @@ func NewDriverHandler(service DriverServiceInterface) *DriverHandler {
 }
 
 func (h *DriverHandler) GetAll(c *gin.Context) {
-	drivers, err := h.Service.GetAll()
+	drivers, err := h.Service.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -62,7 +63,7 @@ func (h *DriverHandler) Create(c *gin.Context) {
 		Status:              req.Status,
 	}
 
-	if err := h.Service.Create(&driver); err != nil {
+	if err := h.Service.Create(c.Request.Context(), &driver); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -72,11 +73,14 @@ func (h *DriverHandler) Create(c *gin.Context) {
 
 func (h *DriverHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
-	driver, err := h.Service.GetByID(id)
+	driver, err := h.Service.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if driver != nil {
+		setETag(c, driver.Version)
+	}
 	c.JSON(http.StatusOK, driver)
 }
 
@@ -112,19 +116,31 @@ func (h *DriverHandler) Update(c *gin.Context) {
 		Status:              req.Status,
 	}
 
-	if err := h.Service.Update(id, &driver); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	driver.Version = version
+	if err := h.Service.Update(c.Request.Context(), id, &driver); err != nil {
+		respondWriteError(c, err)
 		return
 	}
 
+	setETag(c, driver.Version)
 	c.JSON(http.StatusOK, gin.H{"message": "Driver updated successfully"})
 }
 
 func (h *DriverHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.Service.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Delete(c.Request.Context(), id, version); err != nil {
+		respondWriteError(c, err)
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"auth-service/handler"
 	"auth-service/model"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -19,7 +20,7 @@ type MockDriverService struct {
 	ReturnError bool
 }
 
-func (m *MockDriverService) GetAll() ([]model.Driver, error) {
+func (m *MockDriverService) GetAll(ctx context.Context) ([]model.Driver, error) {
 	if m.ReturnError {
 		return nil, errors.New("failed to fetch drivers")
 	}
@@ -28,7 +29,7 @@ func (m *MockDriverService) GetAll() ([]model.Driver, error) {
 	}, nil
 }
 
-func (m *MockDriverService) Create(driver *model.Driver) error {
+func (m *MockDriverService) Create(ctx context.Context, driver *model.Driver) error {
 	if m.ReturnError {
 		return errors.New("insert failed")
 	}
@@ -39,21 +40,21 @@ func (m *MockDriverService) Create(driver *model.Driver) error {
 	return nil
 }
 
-func (m *MockDriverService) GetByID(id string) (*model.Driver, error) {
+func (m *MockDriverService) GetByID(ctx context.Context, id string) (*model.Driver, error) {
 	if id == "1" {
 		return &model.Driver{ID: 1, Name: "John Doe", Email: "john@example.com", Phone: "1234567890", Address: "123 Main St", DriverLicenseNumber: "DL123", CarModelID: "1", CarTypeID: "1", PlateNumber: "ABC123", Status: "active"}, nil
 	}
 	return nil, errors.New("driver not found")
 }
 
-func (m *MockDriverService) Update(id string, driver *model.Driver) error {
+func (m *MockDriverService) Update(ctx context.Context, id string, driver *model.Driver) error {
 	if id == "1" {
 		return nil
 	}
 	return errors.New("driver not found")
 }
 
-func (m *MockDriverService) Delete(id string) error {
+func (m *MockDriverService) Delete(ctx context.Context, id string, version int) error {
 	if id == "1" {
 		return nil
 	}
@@ -303,6 +304,7 @@ func TestUpdateDriver(t *testing.T) {
 	}
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequest("PUT", "/drivers/1", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -319,6 +321,7 @@ func TestUpdateDriverInvalidJSON(t *testing.T) {
 	router := setupDriverRouter(h)
 
 	req, _ := http.NewRequest("PUT", "/drivers/1", bytes.NewBufferString("invalid json"))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -346,6 +349,7 @@ func TestUpdateDriverNotFound(t *testing.T) {
 	}
 	body, _ := json.Marshal(payload)
 	req, _ := http.NewRequest("PUT", "/drivers/2", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
@@ -361,6 +365,7 @@ func TestDeleteDriver(t *testing.T) {
 	router := setupDriverRouter(h)
 
 	req, _ := http.NewRequest("DELETE", "/drivers/1", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -376,6 +381,7 @@ func TestDeleteDriverNotFound(t *testing.T) {
 	router := setupDriverRouter(h)
 
 	req, _ := http.NewRequest("DELETE", "/drivers/2", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
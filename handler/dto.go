@@ -19,3 +19,7 @@ type LoginResponse struct {
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
+
+type SwitchTenantRequest struct {
+	TenantID int64 `json:"tenant_id" binding:"required"`
+}
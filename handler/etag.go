@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"auth-service/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid If-Match header"})
+		return 0, false
+	}
+
+	return version, true
+}
+
+func respondWriteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrVersionConflict):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrUnknownCustomer),
+		errors.Is(err, service.ErrMissingWindow), errors.Is(err, service.ErrInvalidWindow),
+		errors.Is(err, service.ErrAvailabilityRange), errors.Is(err, service.ErrUnknownDriver),
+		errors.Is(err, service.ErrInvalidLocation), errors.Is(err, service.ErrNoTariff),
+		errors.Is(err, service.ErrUnknownQuote), errors.Is(err, service.ErrUnknownPromo),
+		errors.Is(err, service.ErrPromoNotApplicable), errors.Is(err, service.ErrPromoNeedsCustomer),
+		errors.Is(err, service.ErrPromoOnQuote), errors.Is(err, service.ErrInvalidPromo),
+		errors.Is(err, service.ErrInvalidRecurrence), errors.Is(err, service.ErrNoOccurrence),
+		errors.Is(err, service.ErrInvalidBookingCode), errors.Is(err, service.ErrInvalidChannel),
+		errors.Is(err, service.ErrInvalidLocale), errors.Is(err, service.ErrInvalidRadius),
+		errors.Is(err, service.ErrNoDistance), errors.Is(err, service.ErrNoRoute), errors.Is(err, service.ErrTripTooShort),
+		errors.Is(err, service.ErrInvalidTag), errors.Is(err, service.ErrUnknownAccount),
+		errors.Is(err, service.ErrInvalidCostCentre), errors.Is(err, service.ErrInvalidCalendar),
+		errors.Is(err, service.ErrUnknownBooking), errors.Is(err, service.ErrInvalidPaymentStatus),
+		errors.Is(err, service.ErrInvalidRefund), errors.Is(err, service.ErrInvalidCallback),
+		errors.Is(err, service.ErrInvalidPaymentAmount),
+		errors.Is(err, service.ErrRefundReason):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTransitionForbidden), errors.Is(err, service.ErrCustomerBlacklisted),
+		errors.Is(err, service.ErrBookerNotAuthorised):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrStatusChange),
+		errors.Is(err, service.ErrDriverUnavailable), errors.Is(err, service.ErrVehicleUnavailable),
+		errors.Is(err, service.ErrScheduleConflict), errors.Is(err, service.ErrNotDispatchable),
+		errors.Is(err, service.ErrNoCandidates), errors.Is(err, service.ErrOfferPending),
+		errors.Is(err, service.ErrOfferClosed), errors.Is(err, service.ErrOfferExpired),
+		errors.Is(err, service.ErrQuoteExpired), errors.Is(err, service.ErrQuoteRedeemed),
+		errors.Is(err, service.ErrPriceLocked), errors.Is(err, service.ErrPromoInactive),
+		errors.Is(err, service.ErrPromoExhausted), errors.Is(err, service.ErrPromoCodeTaken),
+		errors.Is(err, service.ErrTripNotCompleted), errors.Is(err, service.ErrFeedbackExists),
+		errors.Is(err, service.ErrInvalidStop), errors.Is(err, service.ErrAccountInactive),
+		errors.Is(err, service.ErrCreditLimitExceeded), errors.Is(err, service.ErrAccountNameTaken),
+		errors.Is(err, service.ErrInvoiceExists), errors.Is(err, service.ErrNothingToInvoice),
+		errors.Is(err, service.ErrInvoiceOverpaid), errors.Is(err, service.ErrPaymentTransition),
+		errors.Is(err, service.ErrNoPaymentProvider), errors.Is(err, service.ErrPaymentNotCaptured),
+		errors.Is(err, service.ErrRefundExceedsCaptured), errors.Is(err, service.ErrRefundRequired),
+		errors.Is(err, service.ErrCapturedAmount):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPaymentGateway):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
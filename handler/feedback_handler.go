@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedbackHandler struct {
+	Service service.FeedbackServiceInterface
+}
+
+func NewFeedbackHandler(s service.FeedbackServiceInterface) *FeedbackHandler {
+	return &FeedbackHandler{Service: s}
+}
+
+// Submit records feedback a customer gave staff for a booking's trip.
+func (h *FeedbackHandler) Submit(c *gin.Context) {
+	var req model.FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	f, err := h.Service.Submit(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, f)
+}
+
+func (h *FeedbackHandler) GetByBooking(c *gin.Context) {
+	f, err := h.Service.GetByBooking(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
+
+// GetForm and SubmitForm serve the feedback link sent to customers and are
+// reached without logging in; the token in the link is the credential.
+func (h *FeedbackHandler) GetForm(c *gin.Context) {
+	form, err := h.Service.GetForm(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondFeedbackLinkError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, form)
+}
+
+func (h *FeedbackHandler) SubmitForm(c *gin.Context) {
+	var req model.FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	f, err := h.Service.SubmitWithToken(c.Request.Context(), c.Param("token"), req)
+	if err != nil {
+		respondFeedbackLinkError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, f)
+}
+
+func respondFeedbackLinkError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidLink) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	respondWriteError(c, err)
+}
+
+func (h *FeedbackHandler) GetByDriver(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+
+	feedback, err := h.Service.GetByDriver(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, feedback)
+}
+
+// GetAlerts lists driver rating alerts; ?open=true leaves out acknowledged ones.
+func (h *FeedbackHandler) GetAlerts(c *gin.Context) {
+	open, _ := strconv.ParseBool(c.Query("open"))
+	alerts, err := h.Service.GetAlerts(c.Request.Context(), open)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, alerts)
+}
+
+func (h *FeedbackHandler) AcknowledgeAlert(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alert id"})
+		return
+	}
+
+	user := CurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	if err := h.Service.AcknowledgeAlert(c.Request.Context(), id, user.ID); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Alert acknowledged"})
+}
@@ -0,0 +1,145 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"auth-service/utils"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockFeedbackService struct {
+	AckedBy int64
+	Open    bool
+}
+
+func (m *MockFeedbackService) Submit(ctx context.Context, bookingID string, req model.FeedbackRequest) (*model.TripFeedback, error) {
+	switch bookingID {
+	case "BK2":
+		return nil, service.ErrTripNotCompleted
+	case "BK3":
+		return nil, service.ErrFeedbackExists
+	}
+	return &model.TripFeedback{ID: 1, BookingID: &bookingID, Rating: req.Rating, Tags: req.Tags, Source: model.FeedbackFromStaff}, nil
+}
+
+func (m *MockFeedbackService) GetByBooking(ctx context.Context, bookingID string) (*model.TripFeedback, error) {
+	if bookingID != "BK1" {
+		return nil, service.ErrNotFound
+	}
+	return &model.TripFeedback{ID: 1, BookingID: &bookingID, Rating: 5}, nil
+}
+
+func (m *MockFeedbackService) GetForm(ctx context.Context, token string) (*model.FeedbackForm, error) {
+	if token != "good" {
+		return nil, service.ErrInvalidLink
+	}
+	return &model.FeedbackForm{BookingID: "BK1", Driver: "Budi", Tags: model.FeedbackTags}, nil
+}
+
+func (m *MockFeedbackService) SubmitWithToken(ctx context.Context, token string, req model.FeedbackRequest) (*model.TripFeedback, error) {
+	if token != "good" {
+		return nil, service.ErrInvalidLink
+	}
+	for _, tag := range req.Tags {
+		if !model.ValidFeedbackTag(tag) {
+			return nil, service.ErrInvalidTag
+		}
+	}
+	return &model.TripFeedback{ID: 2, Rating: req.Rating, Source: model.FeedbackFromLink}, nil
+}
+
+func (m *MockFeedbackService) GetByDriver(ctx context.Context, driverID int) ([]model.TripFeedback, error) {
+	if driverID != 4 {
+		return nil, service.ErrNotFound
+	}
+	return []model.TripFeedback{{ID: 1, DriverID: &driverID, Rating: 3}}, nil
+}
+
+func (m *MockFeedbackService) GetAlerts(ctx context.Context, open bool) ([]model.DriverRatingAlert, error) {
+	m.Open = open
+	return []model.DriverRatingAlert{{ID: 1, DriverID: 4, DriverName: "Budi", RollingAverage: 3.1, Threshold: 3.5}}, nil
+}
+
+func (m *MockFeedbackService) AcknowledgeAlert(ctx context.Context, id int, by int64) error {
+	if id != 1 {
+		return service.ErrNotFound
+	}
+	m.AckedBy = by
+	return nil
+}
+
+func TestFeedbackHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &MockFeedbackService{}
+	h := handler.NewFeedbackHandler(svc)
+	router := gin.New()
+	router.GET("/feedback/:token", h.GetForm)
+	router.POST("/feedback/:token", h.SubmitForm)
+	api := router.Group("", handler.AuthMiddleware())
+	api.POST("/booking/:id/feedback", handler.RequireAdmin(), h.Submit)
+	api.GET("/booking/:id/feedback", h.GetByBooking)
+	api.GET("/drivers/:id/feedback", h.GetByDriver)
+	api.GET("/driver-alerts", h.GetAlerts)
+	api.POST("/driver-alerts/:id/ack", h.AcknowledgeAlert)
+
+	admin, _ := utils.GenerateAccessToken(9, 1, model.RoleAdmin, nil)
+	driverID := 4
+	driver, _ := utils.GenerateAccessToken(10, 1, model.RoleDriver, &driverID)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+		want   string
+	}{
+		{"submit", "POST", "/booking/BK1/feedback", admin, `{"rating":5,"tags":["punctual"]}`, http.StatusCreated, `"source":"staff"`},
+		{"rating out of range", "POST", "/booking/BK1/feedback", admin, `{"rating":6}`, http.StatusBadRequest, "Rating"},
+		{"rating missing", "POST", "/booking/BK1/feedback", admin, `{"comment":"ok"}`, http.StatusBadRequest, "Rating"},
+		{"trip not completed", "POST", "/booking/BK2/feedback", admin, `{"rating":4}`, http.StatusConflict, "completed trip"},
+		{"already rated", "POST", "/booking/BK3/feedback", admin, `{"rating":4}`, http.StatusConflict, "already"},
+		{"driver cannot submit", "POST", "/booking/BK1/feedback", driver, `{"rating":5}`, http.StatusForbidden, "admin role required"},
+		{"get", "GET", "/booking/BK1/feedback", admin, "", http.StatusOK, `"rating":5`},
+		{"get missing", "GET", "/booking/BK9/feedback", admin, "", http.StatusNotFound, "not found"},
+		{"needs login", "GET", "/booking/BK1/feedback", "", "", http.StatusUnauthorized, "bearer"},
+		{"form", "GET", "/feedback/good", "", "", http.StatusOK, `"driver":"Budi"`},
+		{"form bad link", "GET", "/feedback/bad", "", "", http.StatusUnauthorized, "expired"},
+		{"submit form", "POST", "/feedback/good", "", `{"rating":3}`, http.StatusCreated, `"source":"link"`},
+		{"submit form unknown tag", "POST", "/feedback/good", "", `{"rating":3,"tags":["fast"]}`, http.StatusBadRequest, "tag"},
+		{"submit form bad link", "POST", "/feedback/bad", "", `{"rating":3}`, http.StatusUnauthorized, "expired"},
+		{"by driver", "GET", "/drivers/4/feedback", admin, "", http.StatusOK, `"rating":3`},
+		{"by unknown driver", "GET", "/drivers/5/feedback", admin, "", http.StatusNotFound, "not found"},
+		{"by invalid driver", "GET", "/drivers/x/feedback", admin, "", http.StatusBadRequest, "invalid driver id"},
+		{"alerts", "GET", "/driver-alerts?open=true", admin, "", http.StatusOK, `"driver_name":"Budi"`},
+		{"acknowledge", "POST", "/driver-alerts/1/ack", admin, "", http.StatusOK, "acknowledged"},
+		{"acknowledge missing", "POST", "/driver-alerts/2/ack", admin, "", http.StatusNotFound, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+	assert.True(t, svc.Open)
+	assert.Equal(t, int64(9), svc.AckedBy)
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"auth-service/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GeocodeHandler struct {
+	Geocoder service.Geocoder
+}
+
+func NewGeocodeHandler(g service.Geocoder) *GeocodeHandler {
+	return &GeocodeHandler{Geocoder: g}
+}
+
+func (h *GeocodeHandler) Geocode(c *gin.Context) {
+	point, err := h.Geocoder.Geocode(c.Request.Context(), c.Query("q"))
+	if errors.Is(err, service.ErrAddressNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, point)
+}
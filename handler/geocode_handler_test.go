@@ -0,0 +1,43 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeocode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewGeocodeHandler(service.DefaultGazetteer())
+
+	router := gin.New()
+	router.GET("/geocode", h.Geocode)
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   string
+	}{
+		{"known place", "?q=Stasiun+Gambir", http.StatusOK, `"name":"Stasiun Gambir"`},
+		{"place in address", "?q=Jl.+Asia+Afrika+8,+Bandung", http.StatusOK, `"lat":-6.9025`},
+		{"unknown place", "?q=Atlantis", http.StatusNotFound, "could not be geocoded"},
+		{"empty query", "", http.StatusNotFound, "could not be geocoded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/geocode"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockIdempotencyService struct {
+	Stored    map[string]*model.IdempotencyKey
+	BeginErr  error
+	Completed map[string]int
+	Released  []string
+}
+
+func NewMockIdempotencyService() *MockIdempotencyService {
+	return &MockIdempotencyService{
+		Stored:    map[string]*model.IdempotencyKey{},
+		Completed: map[string]int{},
+	}
+}
+
+func (m *MockIdempotencyService) Begin(ctx context.Context, key, method, path string, body []byte) (*model.IdempotencyKey, error) {
+	if m.BeginErr != nil {
+		return nil, m.BeginErr
+	}
+	return m.Stored[key], nil
+}
+
+func (m *MockIdempotencyService) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	m.Completed[key] = statusCode
+	m.Stored[key] = &model.IdempotencyKey{Key: key, StatusCode: statusCode, ResponseBody: body}
+	return nil
+}
+
+func (m *MockIdempotencyService) Release(ctx context.Context, key string) error {
+	m.Released = append(m.Released, key)
+	return nil
+}
+
+func (m *MockIdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func setupIdempotentRouter(svc service.IdempotencyServiceInterface, status int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/payments", handler.IdempotencyMiddleware(svc), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func TestIdempotencyMiddleware_StoresAndReplays(t *testing.T) {
+	svc := NewMockIdempotencyService()
+	calls := 0
+	router := setupIdempotentRouter(svc, http.StatusCreated, &calls)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", "/payments", strings.NewReader(`{"amount":1}`))
+		req.Header.Set("Idempotency-Key", "abc")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"call":1}`, w.Body.String())
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, svc.Completed["abc"])
+}
+
+func TestIdempotencyMiddleware_ReplayHeader(t *testing.T) {
+	svc := NewMockIdempotencyService()
+	svc.Stored["abc"] = &model.IdempotencyKey{Key: "abc", StatusCode: http.StatusCreated, ResponseBody: []byte(`{"id":7}`)}
+	calls := 0
+	router := setupIdempotentRouter(svc, http.StatusCreated, &calls)
+
+	req, _ := http.NewRequest("POST", "/payments", strings.NewReader(`{}`))
+	req.Header.Set("Idempotency-Key", "abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	assert.JSONEq(t, `{"id":7}`, w.Body.String())
+}
+
+func TestIdempotencyMiddleware_WithoutKey(t *testing.T) {
+	svc := NewMockIdempotencyService()
+	calls := 0
+	router := setupIdempotentRouter(svc, http.StatusCreated, &calls)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", "/payments", strings.NewReader(`{}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, svc.Completed)
+}
+
+func TestIdempotencyMiddleware_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"mismatch", service.ErrIdempotencyMismatch, http.StatusUnprocessableEntity},
+		{"in progress", service.ErrIdempotencyInProgress, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockIdempotencyService()
+			svc.BeginErr = tt.err
+			calls := 0
+			router := setupIdempotentRouter(svc, http.StatusCreated, &calls)
+
+			req, _ := http.NewRequest("POST", "/payments", strings.NewReader(`{}`))
+			req.Header.Set("Idempotency-Key", "abc")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, 0, calls)
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_ReleasesOnServerError(t *testing.T) {
+	svc := NewMockIdempotencyService()
+	calls := 0
+	router := setupIdempotentRouter(svc, http.StatusInternalServerError, &calls)
+
+	req, _ := http.NewRequest("POST", "/payments", strings.NewReader(`{}`))
+	req.Header.Set("Idempotency-Key", "abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, []string{"abc"}, svc.Released)
+	assert.Empty(t, svc.Completed)
+}
@@ -25,7 +25,7 @@ func (h *MaintenanceHandler) Create(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.Create(&m); err != nil {
+	if err := h.Service.Create(c.Request.Context(), &m); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -41,7 +41,7 @@ func (h *MaintenanceHandler) FindByVehicle(c *gin.Context) {
 		return
 	}
 
-	data, err := h.Service.FindByVehicle(vehicleID)
+	data, err := h.Service.FindByVehicle(c.Request.Context(), vehicleID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -66,7 +66,7 @@ func (h *MaintenanceHandler) Update(c *gin.Context) {
 
 	m.ID = uint(id)
 
-	if err := h.Service.Update(&m); err != nil {
+	if err := h.Service.Update(c.Request.Context(), &m); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -82,7 +82,7 @@ func (h *MaintenanceHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.Delete(uint(id)); err != nil {
+	if err := h.Service.Delete(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
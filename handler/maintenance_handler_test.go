@@ -4,6 +4,7 @@ import (
 	"auth-service/handler"
 	"auth-service/model"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -17,7 +18,7 @@ import (
 
 type MockMaintenanceService struct{}
 
-func (m *MockMaintenanceService) Create(maintenance *model.VehicleMaintenance) error {
+func (m *MockMaintenanceService) Create(ctx context.Context, maintenance *model.VehicleMaintenance) error {
 	if maintenance.VehicleID == 0 {
 		return errors.New("vehicle id required")
 	}
@@ -25,7 +26,7 @@ func (m *MockMaintenanceService) Create(maintenance *model.VehicleMaintenance) e
 	return nil
 }
 
-func (m *MockMaintenanceService) FindByVehicle(vehicleID int) ([]model.VehicleMaintenance, error) {
+func (m *MockMaintenanceService) FindByVehicle(ctx context.Context, vehicleID int) ([]model.VehicleMaintenance, error) {
 	if vehicleID == 0 {
 		return nil, errors.New("invalid vehicle id")
 	}
@@ -42,14 +43,14 @@ func (m *MockMaintenanceService) FindByVehicle(vehicleID int) ([]model.VehicleMa
 	}, nil
 }
 
-func (m *MockMaintenanceService) Update(maintenance *model.VehicleMaintenance) error {
+func (m *MockMaintenanceService) Update(ctx context.Context, maintenance *model.VehicleMaintenance) error {
 	if maintenance.ID == 0 {
 		return errors.New("id required")
 	}
 	return nil
 }
 
-func (m *MockMaintenanceService) Delete(id uint) error {
+func (m *MockMaintenanceService) Delete(ctx context.Context, id uint) error {
 	if id == 0 {
 		return errors.New("id required")
 	}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	Service service.NotificationServiceInterface
+}
+
+func NewNotificationHandler(s service.NotificationServiceInterface) *NotificationHandler {
+	return &NotificationHandler{Service: s}
+}
+
+func (h *NotificationHandler) GetOutbox(c *gin.Context) {
+	notifications, err := h.Service.GetOutbox(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) GetPreference(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	p, err := h.Service.GetPreference(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *NotificationHandler) SavePreference(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer id"})
+		return
+	}
+
+	var p model.NotificationPreference
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p.CustomerID = id
+
+	if err := h.Service.SavePreference(c.Request.Context(), &p); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
@@ -0,0 +1,82 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockNotificationService struct {
+	Status string
+	Saved  *model.NotificationPreference
+}
+
+func (m *MockNotificationService) GetOutbox(ctx context.Context, status string) ([]model.Notification, error) {
+	m.Status = status
+	return []model.Notification{{ID: 1, Event: model.NotifyBookingConfirmed, Channel: model.ChannelEmail, Status: status}}, nil
+}
+
+func (m *MockNotificationService) GetPreference(ctx context.Context, customerID int) (*model.NotificationPreference, error) {
+	if customerID != 4 {
+		return nil, service.ErrNotFound
+	}
+	return &model.NotificationPreference{CustomerID: 4, Locale: model.LocaleID, Channels: []string{}}, nil
+}
+
+func (m *MockNotificationService) SavePreference(ctx context.Context, p *model.NotificationPreference) error {
+	if p.CustomerID != 4 {
+		return service.ErrNotFound
+	}
+	m.Saved = p
+	return nil
+}
+
+func TestNotificationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &MockNotificationService{}
+	h := handler.NewNotificationHandler(svc)
+	router := gin.New()
+	router.GET("/notifications", h.GetOutbox)
+	router.GET("/customers/:id/notification-preferences", h.GetPreference)
+	router.PUT("/customers/:id/notification-preferences", h.SavePreference)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"outbox", "GET", "/notifications?status=failed", "", http.StatusOK, `"status":"failed"`},
+		{"preference", "GET", "/customers/4/notification-preferences", "", http.StatusOK, `"locale":"id"`},
+		{"unknown customer", "GET", "/customers/5/notification-preferences", "", http.StatusNotFound, "not found"},
+		{"invalid id", "GET", "/customers/x/notification-preferences", "", http.StatusBadRequest, "invalid customer id"},
+		{"save", "PUT", "/customers/4/notification-preferences", `{"locale":"en","channels":["whatsapp"]}`, http.StatusOK, `"customer_id":4`},
+		{"unknown channel", "PUT", "/customers/4/notification-preferences", `{"channels":["fax"]}`, http.StatusBadRequest, "Channels"},
+		{"unknown locale", "PUT", "/customers/4/notification-preferences", `{"locale":"fr"}`, http.StatusBadRequest, "Locale"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+	assert.Equal(t, "failed", svc.Status)
+	assert.Equal(t, []string{"whatsapp"}, svc.Saved.Channels)
+}
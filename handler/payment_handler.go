@@ -3,6 +3,8 @@ package handler
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -27,7 +29,7 @@ func (h *PaymentHandler) GetPayments(c *gin.Context) {
 		return
 	}
 
-	payments, err := h.Service.GetPayments(page, pageSize)
+	payments, err := h.Service.GetPayments(c.Request.Context(), page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -54,9 +56,21 @@ func (h *PaymentHandler) GetPaymentByID(c *gin.Context) {
 		return
 	}
 
+	setETag(c, payment.Version)
 	c.JSON(http.StatusOK, payment)
 }
 
+// GetByBooking returns the payments of a booking and what is still owed on it.
+func (h *PaymentHandler) GetByBooking(c *gin.Context) {
+	summary, err := h.Service.GetBookingPayments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
 func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 	var p model.Payment
 
@@ -67,7 +81,7 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 
 	id, err := h.Service.CreatePayment(c.Request.Context(), &p)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWriteError(c, err)
 		return
 	}
 
@@ -93,20 +107,109 @@ func (h *PaymentHandler) UpdatePayment(c *gin.Context) {
 		return
 	}
 
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	p.PaymentID = id
+	p.Version = version
 
 	err = h.Service.UpdatePayment(c.Request.Context(), &p)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWriteError(c, err)
 		return
 	}
 
+	setETag(c, p.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "payment updated successfully",
 		"data":    p,
 	})
 }
 
+// RefreshPayment asks the payment gateway how a payment stands and applies it.
+func (h *PaymentHandler) RefreshPayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment id"})
+		return
+	}
+
+	payment, err := h.Service.RefreshPayment(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, payment.Version)
+	c.JSON(http.StatusOK, payment)
+}
+
+// Callback receives the signed status callbacks of the payment gateway. A
+// replayed callback is acknowledged without being applied again.
+func (h *PaymentHandler) Callback(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.Service.HandleCallback(c.Request.Context(), body,
+		c.GetHeader(service.CallbackTimestampHeader), c.GetHeader(service.CallbackSignatureHeader))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "callback processed"})
+	case errors.Is(err, service.ErrCallbackReplayed):
+		c.JSON(http.StatusOK, gin.H{"message": err.Error()})
+	case errors.Is(err, service.ErrCallbackSignature), errors.Is(err, service.ErrCallbackExpired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	default:
+		respondWriteError(c, err)
+	}
+}
+
+// RefundPayment records a refund of a payment, approved by the current user.
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment id"})
+		return
+	}
+
+	var req model.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var approvedBy *int64
+	if user := CurrentUser(c); user != nil {
+		approvedBy = &user.ID
+	}
+
+	refund, err := h.Service.RefundPayment(c.Request.Context(), id, req, approvedBy)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, refund)
+}
+
+func (h *PaymentHandler) GetRefunds(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment id"})
+		return
+	}
+
+	refunds, err := h.Service.GetRefunds(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, refunds)
+}
+
 func (h *PaymentHandler) GetPaymentStats(c *gin.Context) {
 	stats, err := h.Service.GetPaymentStats(c.Request.Context())
 	if err != nil {
@@ -116,6 +219,8 @@ func (h *PaymentHandler) GetPaymentStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"total_payment":      stats.TotalPayment,
+		"total_refunded":     stats.TotalRefunded,
+		"net_payment":        stats.NetPayment,
 		"pending_payment":    stats.PendingPayment,
 		"total_transactions": stats.TotalTransactions,
 	})
@@ -128,9 +233,14 @@ func (h *PaymentHandler) DeletePayment(c *gin.Context) {
 		return
 	}
 
-	err = h.Service.DeletePayment(c.Request.Context(), id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err = h.Service.DeletePayment(c.Request.Context(), id, version)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWriteError(c, err)
 		return
 	}
 
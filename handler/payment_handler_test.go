@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -12,18 +13,21 @@ import (
 
 	"auth-service/handler"
 	"auth-service/model"
+	"auth-service/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+var paymentBooking = "BK1"
+
 type MockPaymentService struct {
 	mock.Mock
 }
 
-func (m *MockPaymentService) GetPayments(page, pageSize int) ([]model.Payment, error) {
-	args := m.Called(page, pageSize)
+func (m *MockPaymentService) GetPayments(ctx context.Context, page, pageSize int) ([]model.Payment, error) {
+	args := m.Called(ctx, page, pageSize)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -48,8 +52,8 @@ func (m *MockPaymentService) UpdatePayment(ctx context.Context, p *model.Payment
 	return args.Error(0)
 }
 
-func (m *MockPaymentService) DeletePayment(ctx context.Context, id int) error {
-	args := m.Called(ctx, id)
+func (m *MockPaymentService) DeletePayment(ctx context.Context, id int, version int) error {
+	args := m.Called(ctx, id, version)
 	return args.Error(0)
 }
 
@@ -61,6 +65,43 @@ func (m *MockPaymentService) GetPaymentStats(ctx context.Context) (*model.Paymen
 	return args.Get(0).(*model.PaymentStats), args.Error(1)
 }
 
+func (m *MockPaymentService) GetBookingPayments(ctx context.Context, bookingID string) (*model.BookingPayments, error) {
+	args := m.Called(ctx, bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BookingPayments), args.Error(1)
+}
+
+func (m *MockPaymentService) RefreshPayment(ctx context.Context, id int) (*model.Payment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *MockPaymentService) HandleCallback(ctx context.Context, body []byte, timestamp, signature string) error {
+	args := m.Called(ctx, string(body), timestamp, signature)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) RefundPayment(ctx context.Context, paymentID int, req model.RefundRequest, approvedBy *int64) (*model.PaymentRefund, error) {
+	args := m.Called(ctx, paymentID, req, approvedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PaymentRefund), args.Error(1)
+}
+
+func (m *MockPaymentService) GetRefunds(ctx context.Context, paymentID int) ([]model.PaymentRefund, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PaymentRefund), args.Error(1)
+}
+
 func setupPaymentRouter(h *handler.PaymentHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
@@ -71,6 +112,11 @@ func setupPaymentRouter(h *handler.PaymentHandler) *gin.Engine {
 	r.PUT("/payments/:id", h.UpdatePayment)
 	r.DELETE("/payments/:id", h.DeletePayment)
 	r.GET("/payments/stats", h.GetPaymentStats)
+	r.GET("/booking/:id/payments", h.GetByBooking)
+	r.POST("/payments/:id/refresh", h.RefreshPayment)
+	r.POST("/payments/callback", h.Callback)
+	r.POST("/payments/:id/refunds", h.RefundPayment)
+	r.GET("/payments/:id/refunds", h.GetRefunds)
 
 	return r
 }
@@ -134,9 +180,9 @@ func TestGetPayments(t *testing.T) {
 	h := &handler.PaymentHandler{Service: mockService}
 
 	payments := []model.Payment{
-		{PaymentID: 1, BookingID: 1, Customer: "John", Amount: 100.0},
+		{PaymentID: 1, BookingID: &paymentBooking, Customer: "John", Amount: 100.0},
 	}
-	mockService.On("GetPayments", 1, 5).Return(payments, nil)
+	mockService.On("GetPayments", mock.Anything, 1, 5).Return(payments, nil)
 
 	router := setupPaymentRouter(h)
 
@@ -186,7 +232,7 @@ func TestGetPaymentByID_Success(t *testing.T) {
 	mockService := new(MockPaymentService)
 	h := &handler.PaymentHandler{Service: mockService}
 
-	payment := &model.Payment{PaymentID: 1, BookingID: 1, Customer: "John", Amount: 100.0}
+	payment := &model.Payment{PaymentID: 1, BookingID: &paymentBooking, Customer: "John", Amount: 100.0}
 	mockService.On("GetPaymentByID", mock.Anything, 1).Return(payment, nil)
 
 	router := setupPaymentRouter(h)
@@ -240,7 +286,7 @@ func TestCreatePayment_Success(t *testing.T) {
 	mockService := new(MockPaymentService)
 	h := &handler.PaymentHandler{Service: mockService}
 
-	payment := model.Payment{BookingID: 1, Customer: "John", Amount: 100.0}
+	payment := model.Payment{BookingID: &paymentBooking, Customer: "John", Amount: 100.0}
 	mockService.On("CreatePayment", mock.Anything, &payment).Return(1, nil)
 
 	router := setupPaymentRouter(h)
@@ -276,13 +322,14 @@ func TestUpdatePayment_Success(t *testing.T) {
 	mockService := new(MockPaymentService)
 	h := &handler.PaymentHandler{Service: mockService}
 
-	payment := model.Payment{PaymentID: 1, BookingID: 1, Customer: "John", Amount: 100.0}
+	payment := model.Payment{PaymentID: 1, BookingID: &paymentBooking, Customer: "John", Amount: 100.0, Version: 1}
 	mockService.On("UpdatePayment", mock.Anything, &payment).Return(nil)
 
 	router := setupPaymentRouter(h)
 
 	body, _ := json.Marshal(payment)
 	req, _ := http.NewRequest("PUT", "/payments/1", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -300,6 +347,7 @@ func TestUpdatePayment_InvalidID(t *testing.T) {
 	router := setupPaymentRouter(h)
 
 	req, _ := http.NewRequest("PUT", "/payments/abc", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -312,7 +360,7 @@ func TestGetPaymentStats_Success(t *testing.T) {
 	mockService := new(MockPaymentService)
 	h := &handler.PaymentHandler{Service: mockService}
 
-	stats := &model.PaymentStats{TotalPayment: 1000, PendingPayment: 100, TotalTransactions: 10}
+	stats := &model.PaymentStats{TotalPayment: 1000, TotalRefunded: 250, NetPayment: 750, PendingPayment: 100, TotalTransactions: 10}
 	mockService.On("GetPaymentStats", mock.Anything).Return(stats, nil)
 
 	router := setupPaymentRouter(h)
@@ -326,6 +374,8 @@ func TestGetPaymentStats_Success(t *testing.T) {
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Equal(t, float64(1000), response["total_payment"])
+	assert.Equal(t, float64(250), response["total_refunded"])
+	assert.Equal(t, float64(750), response["net_payment"])
 	mockService.AssertExpectations(t)
 }
 
@@ -333,11 +383,12 @@ func TestDeletePayment_Success(t *testing.T) {
 	mockService := new(MockPaymentService)
 	h := &handler.PaymentHandler{Service: mockService}
 
-	mockService.On("DeletePayment", mock.Anything, 1).Return(nil)
+	mockService.On("DeletePayment", mock.Anything, 1, 1).Return(nil)
 
 	router := setupPaymentRouter(h)
 
 	req, _ := http.NewRequest("DELETE", "/payments/1", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -354,6 +405,7 @@ func TestDeletePayment_InvalidID(t *testing.T) {
 	router := setupPaymentRouter(h)
 
 	req, _ := http.NewRequest("DELETE", "/payments/abc", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -366,7 +418,7 @@ func TestGetPayments_Error(t *testing.T) {
 	mockService := new(MockPaymentService)
 	h := &handler.PaymentHandler{Service: mockService}
 
-	mockService.On("GetPayments", 1, 5).Return(nil, errors.New("get payments error"))
+	mockService.On("GetPayments", mock.Anything, 1, 5).Return(nil, errors.New("get payments error"))
 
 	router := setupPaymentRouter(h)
 
@@ -383,13 +435,14 @@ func TestUpdatePayment_Error(t *testing.T) {
 	mockService := new(MockPaymentService)
 	h := &handler.PaymentHandler{Service: mockService}
 
-	payment := model.Payment{PaymentID: 1, BookingID: 1, Customer: "John", Amount: 100.0}
+	payment := model.Payment{PaymentID: 1, BookingID: &paymentBooking, Customer: "John", Amount: 100.0, Version: 1}
 	mockService.On("UpdatePayment", mock.Anything, &payment).Return(errors.New("update error"))
 
 	router := setupPaymentRouter(h)
 
 	body, _ := json.Marshal(payment)
 	req, _ := http.NewRequest("PUT", "/payments/1", bytes.NewBuffer(body))
+	req.Header.Set("If-Match", `"1"`)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -505,11 +558,12 @@ func TestDeletePayment_Error(t *testing.T) {
 	mockService := new(MockPaymentService)
 	h := &handler.PaymentHandler{Service: mockService}
 
-	mockService.On("DeletePayment", mock.Anything, 1).Return(errors.New("delete error"))
+	mockService.On("DeletePayment", mock.Anything, 1, 1).Return(errors.New("delete error"))
 
 	router := setupPaymentRouter(h)
 
 	req, _ := http.NewRequest("DELETE", "/payments/1", nil)
+	req.Header.Set("If-Match", `"1"`)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -517,3 +571,159 @@ func TestDeletePayment_Error(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestPaymentHandler_GetByBooking(t *testing.T) {
+	mockService := new(MockPaymentService)
+	h := &handler.PaymentHandler{Service: mockService}
+
+	summary := &model.BookingPayments{BookingID: "BK1", PaymentStatus: model.BookingPartiallyPaid, AmountDue: 100000, Paid: 60000, Outstanding: 40000}
+	mockService.On("GetBookingPayments", mock.Anything, "BK1").Return(summary, nil)
+	mockService.On("GetBookingPayments", mock.Anything, "BK2").Return(nil, service.ErrNotFound)
+
+	router := setupPaymentRouter(h)
+
+	req, _ := http.NewRequest("GET", "/booking/BK1/payments", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"outstanding":40000`)
+	assert.Contains(t, w.Body.String(), `"payment_status":"Partially Paid"`)
+
+	req, _ = http.NewRequest("GET", "/booking/BK2/payments", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPaymentHandler_RefreshPayment(t *testing.T) {
+	mockService := new(MockPaymentService)
+	h := &handler.PaymentHandler{Service: mockService}
+
+	mockService.On("RefreshPayment", mock.Anything, 1).Return(&model.Payment{PaymentID: 1, Status: model.PaymentPaid, Version: 3}, nil)
+	mockService.On("RefreshPayment", mock.Anything, 2).Return(nil, service.ErrNoPaymentProvider)
+	mockService.On("RefreshPayment", mock.Anything, 3).Return(nil, fmt.Errorf("%w: timeout", service.ErrPaymentGateway))
+
+	router := setupPaymentRouter(h)
+
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/payments/1/refresh", http.StatusOK, `"status":"paid"`},
+		{"/payments/2/refresh", http.StatusConflict, "not made through a payment gateway"},
+		{"/payments/3/refresh", http.StatusBadGateway, "timeout"},
+		{"/payments/x/refresh", http.StatusBadRequest, "invalid payment id"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.status, w.Code, tt.path)
+		assert.Contains(t, w.Body.String(), tt.want, tt.path)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestPaymentHandler_Callback(t *testing.T) {
+	mockService := new(MockPaymentService)
+	h := &handler.PaymentHandler{Service: mockService}
+
+	mockService.On("HandleCallback", mock.Anything, `{"event_id":"1"}`, "100", "good").Return(nil)
+	mockService.On("HandleCallback", mock.Anything, `{"event_id":"2"}`, "100", "good").Return(service.ErrCallbackReplayed)
+	mockService.On("HandleCallback", mock.Anything, `{"event_id":"3"}`, "100", "bad").Return(service.ErrCallbackSignature)
+	mockService.On("HandleCallback", mock.Anything, `{"event_id":"4"}`, "1", "good").Return(service.ErrCallbackExpired)
+	mockService.On("HandleCallback", mock.Anything, `{"event_id":"5"}`, "100", "good").Return(service.ErrNotFound)
+
+	router := setupPaymentRouter(h)
+
+	tests := []struct {
+		name      string
+		body      string
+		timestamp string
+		signature string
+		status    int
+		want      string
+	}{
+		{"applied", `{"event_id":"1"}`, "100", "good", http.StatusOK, "callback processed"},
+		{"replayed", `{"event_id":"2"}`, "100", "good", http.StatusOK, "already been processed"},
+		{"bad signature", `{"event_id":"3"}`, "100", "bad", http.StatusUnauthorized, "signature is invalid"},
+		{"stale", `{"event_id":"4"}`, "1", "good", http.StatusUnauthorized, "outside the allowed window"},
+		{"unknown payment", `{"event_id":"5"}`, "100", "good", http.StatusNotFound, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/payments/callback", strings.NewReader(tt.body))
+			req.Header.Set(service.CallbackTimestampHeader, tt.timestamp)
+			req.Header.Set(service.CallbackSignatureHeader, tt.signature)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestPaymentHandler_RefundPayment(t *testing.T) {
+	mockService := new(MockPaymentService)
+	h := &handler.PaymentHandler{Service: mockService}
+
+	refund := &model.PaymentRefund{ID: 4, PaymentID: 1, Amount: 25000, Reason: "double charge", Status: model.RefundSucceeded}
+	mockService.On("RefundPayment", mock.Anything, 1, model.RefundRequest{Amount: 25000, Reason: "double charge"}, (*int64)(nil)).Return(refund, nil)
+	mockService.On("RefundPayment", mock.Anything, 2, model.RefundRequest{Amount: 25000, Reason: "double charge"}, (*int64)(nil)).Return(nil, service.ErrRefundExceedsCaptured)
+	mockService.On("RefundPayment", mock.Anything, 3, model.RefundRequest{Amount: 25000}, (*int64)(nil)).Return(nil, service.ErrRefundReason)
+	mockService.On("RefundPayment", mock.Anything, 4, model.RefundRequest{Amount: 25000, Reason: "double charge"}, (*int64)(nil)).Return(nil, fmt.Errorf("%w: refused", service.ErrPaymentGateway))
+
+	router := setupPaymentRouter(h)
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"/payments/1/refunds", `{"amount":25000,"reason":"double charge"}`, http.StatusCreated, `"status":"succeeded"`},
+		{"/payments/2/refunds", `{"amount":25000,"reason":"double charge"}`, http.StatusConflict, "refund exceeds"},
+		{"/payments/3/refunds", `{"amount":25000}`, http.StatusBadRequest, "reason is required"},
+		{"/payments/4/refunds", `{"amount":25000,"reason":"double charge"}`, http.StatusBadGateway, "refused"},
+		{"/payments/x/refunds", `{}`, http.StatusBadRequest, "invalid payment id"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.status, w.Code, tt.path)
+		assert.Contains(t, w.Body.String(), tt.want, tt.path)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestPaymentHandler_GetRefunds(t *testing.T) {
+	mockService := new(MockPaymentService)
+	h := &handler.PaymentHandler{Service: mockService}
+
+	mockService.On("GetRefunds", mock.Anything, 1).Return([]model.PaymentRefund{{ID: 4, PaymentID: 1, Amount: 25000}}, nil)
+	mockService.On("GetRefunds", mock.Anything, 2).Return(nil, service.ErrNotFound)
+
+	router := setupPaymentRouter(h)
+
+	req, _ := http.NewRequest("GET", "/payments/1/refunds", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"amount":25000`)
+
+	req, _ = http.NewRequest("GET", "/payments/2/refunds", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
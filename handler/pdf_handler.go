@@ -19,7 +19,7 @@ func NewPDFHandler(s service.PDFServiceInterface) *PDFHandler {
 func (h *PDFHandler) HandlePDFReceipt(c *gin.Context) {
 	tripID := c.Param("trip_id")
 
-	pdfBytes, filename, err := h.PDFService.GenerateTripReceiptPDF(tripID)
+	pdfBytes, filename, err := h.PDFService.GenerateTripReceiptPDF(c.Request.Context(), tripID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
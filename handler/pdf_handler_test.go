@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"auth-service/handler"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -15,7 +16,7 @@ type MockPdfService struct {
 	ReturnError bool
 }
 
-func (m *MockPdfService) GenerateTripReceiptPDF(tripID string) ([]byte, string, error) {
+func (m *MockPdfService) GenerateTripReceiptPDF(ctx context.Context, tripID string) ([]byte, string, error) {
 	if tripID == "error" {
 		return nil, "", errors.New("failed to generate PDF")
 	}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingHandler struct {
+	Service service.PricingServiceInterface
+}
+
+func NewPricingHandler(s service.PricingServiceInterface) *PricingHandler {
+	return &PricingHandler{Service: s}
+}
+
+func (h *PricingHandler) GetTariffs(c *gin.Context) {
+	tariffs, err := h.Service.GetTariffs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tariffs)
+}
+
+func (h *PricingHandler) SaveTariff(c *gin.Context) {
+	var t model.Tariff
+	if err := c.ShouldBindJSON(&t); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t.CarTypeID = c.Param("car_type_id")
+
+	if err := h.Service.SaveTariff(c.Request.Context(), &t); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *PricingHandler) CreateQuote(c *gin.Context) {
+	var req model.QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.Service.Quote(c.Request.Context(), req)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, quote)
+}
+
+func (h *PricingHandler) GetQuote(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quote id"})
+		return
+	}
+
+	quote, err := h.Service.GetQuote(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
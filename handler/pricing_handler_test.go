@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockPricingService struct{}
+
+func (m *MockPricingService) GetTariffs(ctx context.Context) ([]model.Tariff, error) {
+	return []model.Tariff{{CarTypeID: "mpv", BaseFare: 20000}}, nil
+}
+
+func (m *MockPricingService) SaveTariff(ctx context.Context, t *model.Tariff) error {
+	return nil
+}
+
+func (m *MockPricingService) Quote(ctx context.Context, req model.QuoteRequest) (*model.Quote, error) {
+	if req.CarTypeID != "mpv" {
+		return nil, service.ErrNoTariff
+	}
+	return &model.Quote{ID: 1, CarTypeID: "mpv", Total: 85000, Items: []model.QuoteItem{{Code: model.QuoteItemBaseFare, Amount: 85000}}}, nil
+}
+
+func (m *MockPricingService) GetQuote(ctx context.Context, id int) (*model.Quote, error) {
+	if id != 1 {
+		return nil, service.ErrNotFound
+	}
+	return &model.Quote{ID: 1, Total: 85000}, nil
+}
+
+func TestPricingHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewPricingHandler(&MockPricingService{})
+	router := gin.New()
+	router.GET("/tariffs", h.GetTariffs)
+	router.PUT("/tariffs/:car_type_id", h.SaveTariff)
+	router.POST("/quotes", h.CreateQuote)
+	router.GET("/quotes/:id", h.GetQuote)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"tariffs", "GET", "/tariffs", "", http.StatusOK, `"base_fare":20000`},
+		{"save tariff", "PUT", "/tariffs/sedan", `{"base_fare":15000,"per_km":3500,"night_start_hour":22,"night_end_hour":5}`, http.StatusOK, `"car_type_id":"sedan"`},
+		{"bad night hour", "PUT", "/tariffs/sedan", `{"night_start_hour":24}`, http.StatusBadRequest, "NightStartHour"},
+		{"quote", "POST", "/quotes", `{"car_type_id":"mpv","distance_km":12.5,"pickup_at":"2024-05-01T09:00:00Z"}`, http.StatusCreated, `"total":85000`},
+		{"quote without distance", "POST", "/quotes", `{"car_type_id":"mpv","pickup_at":"2024-05-01T09:00:00Z"}`, http.StatusBadRequest, "DistanceKM"},
+		{"quote without tariff", "POST", "/quotes", `{"car_type_id":"bus","distance_km":3,"pickup_at":"2024-05-01T09:00:00Z"}`, http.StatusBadRequest, "no tariff"},
+		{"get quote", "GET", "/quotes/1", "", http.StatusOK, `"id":1`},
+		{"unknown quote", "GET", "/quotes/2", "", http.StatusNotFound, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
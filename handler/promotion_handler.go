@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPromotionReportRange = 30 * 24 * time.Hour
+
+type PromotionHandler struct {
+	Service service.PromotionServiceInterface
+}
+
+func NewPromotionHandler(s service.PromotionServiceInterface) *PromotionHandler {
+	return &PromotionHandler{Service: s}
+}
+
+func (h *PromotionHandler) GetAll(c *gin.Context) {
+	promotions, err := h.Service.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, promotions)
+}
+
+func (h *PromotionHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+
+	promotion, err := h.Service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, promotion.Version)
+	c.JSON(http.StatusOK, promotion)
+}
+
+func (h *PromotionHandler) Create(c *gin.Context) {
+	var promotion model.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Service.Create(c.Request.Context(), &promotion); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, promotion.Version)
+	c.JSON(http.StatusCreated, promotion)
+}
+
+func (h *PromotionHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+
+	var promotion model.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	promotion.ID = id
+	promotion.Version = version
+	if err := h.Service.Update(c.Request.Context(), &promotion); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, promotion.Version)
+	c.JSON(http.StatusOK, promotion)
+}
+
+func (h *PromotionHandler) GetRedemptions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+
+	redemptions, err := h.Service.GetRedemptions(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, redemptions)
+}
+
+// Report covers the last 30 days unless from and to are given.
+func (h *PromotionHandler) Report(c *gin.Context) {
+	now := time.Now()
+	to, err := parseTimeQuery(c, "to", now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, err := parseTimeQuery(c, "from", to.Add(-defaultPromotionReportRange))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.Service.Report(c.Request.Context(), from, to)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockPromotionService struct{}
+
+func (m *MockPromotionService) GetAll(ctx context.Context) ([]model.Promotion, error) {
+	return []model.Promotion{{ID: 1, Code: "HEMAT10"}}, nil
+}
+
+func (m *MockPromotionService) GetByID(ctx context.Context, id int) (*model.Promotion, error) {
+	if id != 1 {
+		return nil, service.ErrNotFound
+	}
+	return &model.Promotion{ID: 1, Code: "HEMAT10", Version: 2}, nil
+}
+
+func (m *MockPromotionService) Create(ctx context.Context, p *model.Promotion) error {
+	if p.Code == "TAKEN" {
+		return service.ErrPromoCodeTaken
+	}
+	p.ID, p.Version = 2, 1
+	return nil
+}
+
+func (m *MockPromotionService) Update(ctx context.Context, p *model.Promotion) error {
+	if p.Version != 2 {
+		return service.ErrVersionConflict
+	}
+	p.Version++
+	return nil
+}
+
+func (m *MockPromotionService) GetRedemptions(ctx context.Context, id int) ([]model.PromotionRedemption, error) {
+	return []model.PromotionRedemption{{ID: 1, PromotionID: id, BookingID: "BK1", Discount: 5000}}, nil
+}
+
+func (m *MockPromotionService) Report(ctx context.Context, from, to time.Time) ([]model.PromotionReport, error) {
+	if !to.After(from) {
+		return nil, service.ErrInvalidWindow
+	}
+	return []model.PromotionReport{{PromotionID: 1, Code: "HEMAT10", Redemptions: 3, TotalDiscount: 15000}}, nil
+}
+
+func TestPromotionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewPromotionHandler(&MockPromotionService{})
+	router := gin.New()
+	router.GET("/promotions", h.GetAll)
+	router.POST("/promotions", h.Create)
+	router.GET("/promotions/:id", h.GetByID)
+	router.PUT("/promotions/:id", h.Update)
+	router.GET("/promotions/:id/redemptions", h.GetRedemptions)
+	router.GET("/promotion-report", h.Report)
+
+	promotion := `{"code":"HEMAT10","discount_type":"percent","discount_value":10}`
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		ifMatch string
+		status  int
+		want    string
+	}{
+		{"list", "GET", "/promotions", "", "", http.StatusOK, `"code":"HEMAT10"`},
+		{"create", "POST", "/promotions", promotion, "", http.StatusCreated, `"id":2`},
+		{"bad discount type", "POST", "/promotions", `{"code":"X","discount_type":"free","discount_value":10}`, "", http.StatusBadRequest, "DiscountType"},
+		{"code taken", "POST", "/promotions", `{"code":"TAKEN","discount_type":"fixed","discount_value":5000}`, "", http.StatusConflict, "already exists"},
+		{"get", "GET", "/promotions/1", "", "", http.StatusOK, `"version":2`},
+		{"unknown", "GET", "/promotions/9", "", "", http.StatusNotFound, "not found"},
+		{"update", "PUT", "/promotions/1", promotion, `"2"`, http.StatusOK, `"version":3`},
+		{"update without If-Match", "PUT", "/promotions/1", promotion, "", http.StatusPreconditionRequired, "If-Match"},
+		{"stale update", "PUT", "/promotions/1", promotion, `"1"`, http.StatusPreconditionFailed, "modified"},
+		{"redemptions", "GET", "/promotions/1/redemptions", "", "", http.StatusOK, `"booking_id":"BK1"`},
+		{"report", "GET", "/promotion-report", "", "", http.StatusOK, `"total_discount":15000`},
+		{"inverted report window", "GET", "/promotion-report?from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z", "", "", http.StatusBadRequest, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
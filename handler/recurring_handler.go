@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecurringBookingHandler struct {
+	Service service.RecurringServiceInterface
+}
+
+func NewRecurringBookingHandler(s service.RecurringServiceInterface) *RecurringBookingHandler {
+	return &RecurringBookingHandler{Service: s}
+}
+
+func (h *RecurringBookingHandler) GetAll(c *gin.Context) {
+	series, err := h.Service.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *RecurringBookingHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring booking id"})
+		return
+	}
+
+	detail, err := h.Service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, detail.Version)
+	c.JSON(http.StatusOK, detail)
+}
+
+func (h *RecurringBookingHandler) Create(c *gin.Context) {
+	var series model.RecurringBooking
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Service.Create(c.Request.Context(), &series); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, series.Version)
+	c.JSON(http.StatusCreated, series)
+}
+
+// Update edits the whole series; see UpdateOccurrence for a single day.
+func (h *RecurringBookingHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring booking id"})
+		return
+	}
+
+	var series model.RecurringBooking
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	series.ID = id
+	series.Version = version
+	if err := h.Service.Update(c.Request.Context(), &series, CurrentUser(c)); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, series.Version)
+	c.JSON(http.StatusOK, series)
+}
+
+// UpdateOccurrence edits the booking of one day of the series. If-Match
+// carries the version of that booking.
+func (h *RecurringBookingHandler) UpdateOccurrence(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring booking id"})
+		return
+	}
+
+	var booking model.Booking
+	if err := c.ShouldBindJSON(&booking); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	booking.Version = version
+	if err := h.Service.UpdateOccurrence(c.Request.Context(), id, c.Param("date"), &booking); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, booking.Version)
+	c.JSON(http.StatusOK, booking)
+}
+
+func (h *RecurringBookingHandler) SkipOccurrence(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring booking id"})
+		return
+	}
+
+	if err := h.Service.SkipOccurrence(c.Request.Context(), id, c.Param("date"), CurrentUser(c)); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Occurrence skipped"})
+}
+
+func (h *RecurringBookingHandler) Cancel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recurring booking id"})
+		return
+	}
+
+	var req model.RecurringCancel
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	series, err := h.Service.Cancel(c.Request.Context(), id, CurrentUser(c), req)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	setETag(c, series.Version)
+	c.JSON(http.StatusOK, series)
+}
@@ -0,0 +1,132 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockRecurringService struct{}
+
+func (m *MockRecurringService) GetAll(ctx context.Context) ([]model.RecurringBooking, error) {
+	return []model.RecurringBooking{{ID: 1, Customer: "PT Maju", Frequency: model.RecurWeekly}}, nil
+}
+
+func (m *MockRecurringService) GetByID(ctx context.Context, id int) (*model.RecurringBookingDetail, error) {
+	if id != 1 {
+		return nil, service.ErrNotFound
+	}
+	bookingID := "BKR1-20240506"
+	return &model.RecurringBookingDetail{
+		RecurringBooking: model.RecurringBooking{ID: 1, Customer: "PT Maju", Version: 2},
+		Occurrences:      []model.RecurringOccurrence{{ID: 1, RecurringID: 1, Date: "2024-05-06", BookingID: &bookingID}},
+	}, nil
+}
+
+func (m *MockRecurringService) Create(ctx context.Context, r *model.RecurringBooking) error {
+	r.ID, r.Version = 2, 1
+	return nil
+}
+
+func (m *MockRecurringService) Update(ctx context.Context, r *model.RecurringBooking, user *model.User) error {
+	if r.Version != 2 {
+		return service.ErrVersionConflict
+	}
+	if r.Until != nil && *r.Until < r.StartAt.Format(model.DateLayout) {
+		return service.ErrInvalidRecurrence
+	}
+	r.Version++
+	return nil
+}
+
+func (m *MockRecurringService) UpdateOccurrence(ctx context.Context, id int, date string, b *model.Booking) error {
+	if date != "2024-05-06" {
+		return service.ErrNoOccurrence
+	}
+	b.ID = "BKR1-20240506"
+	b.Version++
+	return nil
+}
+
+func (m *MockRecurringService) SkipOccurrence(ctx context.Context, id int, date string, user *model.User) error {
+	if date != "2024-05-06" {
+		return service.ErrNoOccurrence
+	}
+	return nil
+}
+
+func (m *MockRecurringService) Cancel(ctx context.Context, id int, user *model.User, c model.RecurringCancel) (*model.RecurringBooking, error) {
+	until := "2024-05-31"
+	if c.From != "" {
+		until = c.From
+	}
+	return &model.RecurringBooking{ID: id, Until: &until, Version: 3}, nil
+}
+
+func TestRecurringBookingHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewRecurringBookingHandler(&MockRecurringService{})
+	router := gin.New()
+	router.GET("/recurring-bookings", h.GetAll)
+	router.POST("/recurring-bookings", h.Create)
+	router.GET("/recurring-bookings/:id", h.GetByID)
+	router.PUT("/recurring-bookings/:id", h.Update)
+	router.POST("/recurring-bookings/:id/cancel", h.Cancel)
+	router.PUT("/recurring-bookings/:id/occurrences/:date", h.UpdateOccurrence)
+	router.DELETE("/recurring-bookings/:id/occurrences/:date", h.SkipOccurrence)
+
+	series := `{"customer":"PT Maju","frequency":"weekly","weekdays":["MO","WE"],"start_at":"2024-05-06T07:00:00+07:00","duration_minutes":60}`
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		ifMatch string
+		status  int
+		want    string
+	}{
+		{"list", "GET", "/recurring-bookings", "", "", http.StatusOK, `"customer":"PT Maju"`},
+		{"create", "POST", "/recurring-bookings", series, "", http.StatusCreated, `"id":2`},
+		{"bad frequency", "POST", "/recurring-bookings", `{"frequency":"hourly","start_at":"2024-05-06T07:00:00Z","duration_minutes":60}`, "", http.StatusBadRequest, "Frequency"},
+		{"bad weekday", "POST", "/recurring-bookings", `{"frequency":"weekly","weekdays":["XX"],"start_at":"2024-05-06T07:00:00Z","duration_minutes":60}`, "", http.StatusBadRequest, "Weekdays"},
+		{"get", "GET", "/recurring-bookings/1", "", "", http.StatusOK, `"booking_id":"BKR1-20240506"`},
+		{"unknown", "GET", "/recurring-bookings/9", "", "", http.StatusNotFound, "not found"},
+		{"invalid id", "GET", "/recurring-bookings/abc", "", "", http.StatusBadRequest, "invalid recurring booking id"},
+		{"update series", "PUT", "/recurring-bookings/1", series, `"2"`, http.StatusOK, `"version":3`},
+		{"update without If-Match", "PUT", "/recurring-bookings/1", series, "", http.StatusPreconditionRequired, "If-Match"},
+		{"stale update", "PUT", "/recurring-bookings/1", series, `"1"`, http.StatusPreconditionFailed, "modified"},
+		{"ends before start", "PUT", "/recurring-bookings/1", `{"frequency":"daily","start_at":"2024-05-06T07:00:00Z","duration_minutes":60,"until":"2024-05-01"}`, `"2"`, http.StatusBadRequest, "end on or after"},
+		{"update occurrence", "PUT", "/recurring-bookings/1/occurrences/2024-05-06", `{"customer":"PT Maju"}`, `"1"`, http.StatusOK, `"version":2`},
+		{"update missing occurrence", "PUT", "/recurring-bookings/1/occurrences/2024-05-07", `{"customer":"PT Maju"}`, `"1"`, http.StatusBadRequest, "no occurrence"},
+		{"skip occurrence", "DELETE", "/recurring-bookings/1/occurrences/2024-05-06", "", "", http.StatusOK, "Occurrence skipped"},
+		{"skip missing occurrence", "DELETE", "/recurring-bookings/1/occurrences/2024-05-07", "", "", http.StatusBadRequest, "no occurrence"},
+		{"cancel", "POST", "/recurring-bookings/1/cancel", "", "", http.StatusOK, `"until":"2024-05-31"`},
+		{"cancel from", "POST", "/recurring-bookings/1/cancel", `{"from":"2024-05-20"}`, "", http.StatusOK, `"until":"2024-05-20"`},
+		{"cancel bad date", "POST", "/recurring-bookings/1/cancel", `{"from":"20 May"}`, "", http.StatusBadRequest, "From"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
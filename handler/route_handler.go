@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RouteHandler struct {
+	Router service.Router
+}
+
+func NewRouteHandler(r service.Router) *RouteHandler {
+	return &RouteHandler{Router: r}
+}
+
+// Route estimates the road distance and ETA from from_lat,from_lng to
+// to_lat,to_lng, leaving at depart_at or now.
+func (h *RouteHandler) Route(c *gin.Context) {
+	if h.Router == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "routing is not configured"})
+		return
+	}
+
+	var coords [4]float64
+	for i, name := range []string{"from_lat", "from_lng", "to_lat", "to_lng"} {
+		v, err := parseFloatQuery(c, name, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		coords[i] = v
+	}
+	departAt, err := parseTimeQuery(c, "depart_at", time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := h.Router.Route(c.Request.Context(), model.GeoPoint{Lat: coords[0], Lng: coords[1]},
+		model.GeoPoint{Lat: coords[2], Lng: coords[3]}, departAt)
+	if errors.Is(err, service.ErrNoRoute) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, route)
+}
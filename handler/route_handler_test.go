@@ -0,0 +1,60 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubRouter struct{}
+
+func (stubRouter) Route(ctx context.Context, from, to model.GeoPoint, departAt time.Time) (*model.Route, error) {
+	if to.Lat < -90 {
+		return nil, service.ErrInvalidLocation
+	}
+	if from == to {
+		return nil, service.ErrNoRoute
+	}
+	return &model.Route{DistanceKM: 4.4, DurationMinutes: float64(departAt.Hour())}, nil
+}
+
+func TestRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/route", handler.NewRouteHandler(stubRouter{}).Route)
+	router.GET("/unrouted", handler.NewRouteHandler(nil).Route)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		want   string
+	}{
+		{"route", "/route?from_lat=-6.2&from_lng=106.8&to_lat=-6.2&to_lng=106.82&depart_at=2024-05-01T08:00:00Z", http.StatusOK, `"duration_minutes":8`},
+		{"bad depart_at", "/route?from_lat=-6.2&from_lng=106.8&to_lat=-6.2&to_lng=106.82&depart_at=soon", http.StatusBadRequest, "invalid depart_at"},
+		{"missing point", "/route?from_lat=-6.2&from_lng=106.8&to_lat=-6.2", http.StatusBadRequest, "invalid to_lng"},
+		{"invalid point", "/route?from_lat=-6.2&from_lng=106.8&to_lat=-96.2&to_lng=106.82", http.StatusBadRequest, "latitude"},
+		{"no route", "/route?from_lat=-6.2&from_lng=106.8&to_lat=-6.2&to_lng=106.8", http.StatusNotFound, "no road route"},
+		{"not configured", "/unrouted?from_lat=-6.2&from_lng=106.8&to_lat=-6.2&to_lng=106.82", http.StatusNotImplemented, "not configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	Service service.TenantServiceInterface
+}
+
+func NewTenantHandler(s service.TenantServiceInterface) *TenantHandler {
+	return &TenantHandler{Service: s}
+}
+
+func (h *TenantHandler) GetAll(c *gin.Context) {
+	tenants, err := h.Service.GetAll(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tenants)
+}
+
+func (h *TenantHandler) Create(c *gin.Context) {
+	var tenant model.Tenant
+	if err := c.ShouldBindJSON(&tenant); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Service.Create(c.Request.Context(), &tenant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tenant)
+}
+
+func (h *TenantHandler) Switch(c *gin.Context) {
+	var req SwitchTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessToken, err := h.Service.SwitchTenant(c.Request.Context(), CurrentUser(c), req.TenantID)
+	switch {
+	case errors.Is(err, service.ErrSuperAdminRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrTenantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": accessToken})
+}
@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"auth-service/utils"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockTenantService struct{}
+
+func (m *MockTenantService) GetAll(ctx context.Context) ([]model.Tenant, error) {
+	return []model.Tenant{{ID: 1, Name: "Default", Slug: "default"}}, nil
+}
+
+func (m *MockTenantService) Create(ctx context.Context, t *model.Tenant) error {
+	t.ID = 2
+	return nil
+}
+
+func (m *MockTenantService) SwitchTenant(ctx context.Context, user *model.User, tenantID int64) (string, error) {
+	if !user.IsSuperAdmin() {
+		return "", service.ErrSuperAdminRequired
+	}
+	if tenantID != 2 {
+		return "", service.ErrTenantNotFound
+	}
+	return "TOKEN", nil
+}
+
+func TestTenantHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewTenantHandler(&MockTenantService{})
+	router := gin.New()
+	router.POST("/tenants", h.Create)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tenants", bytes.NewBufferString(`{"name":"Acme","slug":"acme"}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tenants", bytes.NewBufferString(`{"name":"Acme"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTenantHandler_Switch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewTenantHandler(&MockTenantService{})
+	router := gin.New()
+	router.POST("/tenants/switch", handler.AuthMiddleware(), h.Switch)
+
+	adminToken, _ := utils.GenerateAccessToken(1, 1, model.RoleAdmin, nil)
+	superToken, _ := utils.GenerateAccessToken(2, 1, model.RoleSuperAdmin, nil)
+
+	tests := []struct {
+		name   string
+		token  string
+		body   string
+		status int
+	}{
+		{"not super admin", adminToken, `{"tenant_id":2}`, http.StatusForbidden},
+		{"unknown tenant", superToken, `{"tenant_id":9}`, http.StatusNotFound},
+		{"missing tenant", superToken, `{}`, http.StatusBadRequest},
+		{"success", superToken, `{"tenant_id":2}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/tenants/switch", bytes.NewBufferString(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
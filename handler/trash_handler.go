@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"auth-service/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	Service service.TrashServiceInterface
+}
+
+func NewTrashHandler(s service.TrashServiceInterface) *TrashHandler {
+	return &TrashHandler{Service: s}
+}
+
+func (h *TrashHandler) List(c *gin.Context) {
+	items, err := h.Service.List(c.Request.Context(), c.Param("entity"))
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownEntity) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *TrashHandler) Restore(entity string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := h.Service.Restore(c.Request.Context(), entity, c.Param("id"))
+		switch {
+		case errors.Is(err, service.ErrInvalidID):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrNotFound), errors.Is(err, service.ErrUnknownEntity):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrScheduleConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "restored successfully"})
+	}
+}
@@ -0,0 +1,96 @@
+package handler_test
+
+import (
+	"auth-service/handler"
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockTrashService struct {
+	ReturnError bool
+}
+
+func (m *MockTrashService) List(ctx context.Context, entity string) (interface{}, error) {
+	if m.ReturnError {
+		return nil, errors.New("failed to fetch trash")
+	}
+	if entity != service.TrashCars {
+		return nil, service.ErrUnknownEntity
+	}
+	return []model.Car{{ID: 1, Brand: "Car A"}}, nil
+}
+
+func (m *MockTrashService) Restore(ctx context.Context, entity, id string) error {
+	if m.ReturnError {
+		return errors.New("failed to restore")
+	}
+	switch id {
+	case "1":
+		return nil
+	case "abc":
+		return service.ErrInvalidID
+	case "2":
+		return service.ErrScheduleConflict
+	}
+	return service.ErrNotFound
+}
+
+func (m *MockTrashService) Purge(ctx context.Context) (map[string]int64, error) {
+	return nil, nil
+}
+
+func TestTrashHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewTrashHandler(&MockTrashService{})
+	router := gin.New()
+	router.GET("/trash/:entity", h.List)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/trash/car", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/trash/users", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTrashHandler_List_Error(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewTrashHandler(&MockTrashService{ReturnError: true})
+	router := gin.New()
+	router.GET("/trash/:entity", h.List)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/trash/car", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestTrashHandler_Restore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := handler.NewTrashHandler(&MockTrashService{})
+	router := gin.New()
+	router.POST("/car/:id/restore", h.Restore(service.TrashCars))
+
+	cases := map[string]int{
+		"/car/1/restore":   http.StatusOK,
+		"/car/abc/restore": http.StatusBadRequest,
+		"/car/9/restore":   http.StatusNotFound,
+		"/car/2/restore":   http.StatusConflict,
+	}
+	for path, status := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		assert.Equal(t, status, w.Code, path)
+	}
+}
@@ -23,8 +23,8 @@ func (h *TripHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.service.Create(&t); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Create(c.Request.Context(), &t); err != nil {
+		respondWriteError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, t)
@@ -37,7 +37,7 @@ func (h *TripHandler) FindByVehicle(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vehicle_id"})
 		return
 	}
-	data, err := h.service.FindByVehicle(uint(vehicleID))
+	data, err := h.service.FindByVehicle(c.Request.Context(), uint(vehicleID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -58,8 +58,8 @@ func (h *TripHandler) Update(c *gin.Context) {
 		return
 	}
 	t.ID = uint(id)
-	if err := h.service.Update(&t); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Update(c.Request.Context(), &t); err != nil {
+		respondWriteError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, t)
@@ -72,7 +72,7 @@ func (h *TripHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
-	if err := h.service.Delete(uint(id)); err != nil {
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
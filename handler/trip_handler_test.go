@@ -20,7 +20,7 @@ type MockTripService struct {
 	ReturnError bool
 }
 
-func (m *MockTripService) Create(t *model.VehicleTrip) error {
+func (m *MockTripService) Create(ctx context.Context, t *model.VehicleTrip) error {
 	if m.ReturnError {
 		return errors.New("mock error")
 	}
@@ -31,7 +31,7 @@ func (m *MockTripService) Create(t *model.VehicleTrip) error {
 	return nil
 }
 
-func (m *MockTripService) FindByVehicle(vehicleID uint) ([]model.VehicleTrip, error) {
+func (m *MockTripService) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.VehicleTrip, error) {
 	if m.ReturnError {
 		return nil, errors.New("mock error")
 	}
@@ -40,7 +40,7 @@ func (m *MockTripService) FindByVehicle(vehicleID uint) ([]model.VehicleTrip, er
 	}, nil
 }
 
-func (m *MockTripService) Update(t *model.VehicleTrip) error {
+func (m *MockTripService) Update(ctx context.Context, t *model.VehicleTrip) error {
 	if m.ReturnError {
 		return errors.New("mock error")
 	}
@@ -50,7 +50,7 @@ func (m *MockTripService) Update(t *model.VehicleTrip) error {
 	return nil
 }
 
-func (m *MockTripService) Delete(id uint) error {
+func (m *MockTripService) Delete(ctx context.Context, id uint) error {
 	if m.ReturnError {
 		return errors.New("mock error")
 	}
@@ -2,13 +2,14 @@ package handler
 
 import (
 	"auth-service/model"
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type TripHistoryServiceInterface interface {
-	GetTripHistory() ([]model.TripHistory, error)
+	GetTripHistory(ctx context.Context) ([]model.TripHistory, error)
 }
 
 type TripHistoryHandler struct {
@@ -20,7 +21,7 @@ func NewTripHistoryHandler(s TripHistoryServiceInterface) *TripHistoryHandler {
 }
 
 func (h *TripHistoryHandler) GetTripHistory(c *gin.Context) {
-	result, err := h.Service.GetTripHistory()
+	result, err := h.Service.GetTripHistory(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Trip not found"})
 		return
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"auth-service/model"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -15,7 +16,7 @@ type MockTripHistoryService struct {
 	ReturnError bool
 }
 
-func (m *MockTripHistoryService) GetTripHistory() ([]model.TripHistory, error) {
+func (m *MockTripHistoryService) GetTripHistory(ctx context.Context) ([]model.TripHistory, error) {
 	if m.ReturnError {
 		return nil, errors.New("no trip found")
 	}
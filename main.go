@@ -1,9 +1,17 @@
 package main
 
 import (
+	"auth-service/model"
+	"auth-service/service"
+	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
@@ -73,22 +81,329 @@ func ResetAllPasswords(db *sql.DB, password string) error {
 	return nil
 }
 
+func startIdempotencyPurge(repos *repositories, interval time.Duration) {
+	idempotencyService := service.NewIdempotencyService(repos.Idempotency, idempotencyRetention)
+
+	go func() {
+		for range time.Tick(interval) {
+			n, err := idempotencyService.PurgeExpired(context.Background())
+			if err != nil {
+				log.Printf("Gagal purge idempotency key: %v\n", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("%d idempotency key kedaluwarsa dihapus\n", n)
+			}
+		}
+	}()
+}
+
+func startTrashPurge(repos *repositories, interval time.Duration) {
+	trashService := service.NewTrashService(repos.Drivers, repos.Cars, repos.Bookings, repos.Payments, trashRetention)
+
+	go func() {
+		for range time.Tick(interval) {
+			purged, err := trashService.Purge(context.Background())
+			if err != nil {
+				log.Printf("Gagal purge data terhapus: %v\n", err)
+				continue
+			}
+			for entity, n := range purged {
+				if n > 0 {
+					log.Printf("%d data %s dihapus permanen\n", n, entity)
+				}
+			}
+		}
+	}()
+}
+
+func startDispatchSweep(repos *repositories, interval time.Duration) {
+	dispatchService := newDispatchService(repos)
+
+	go func() {
+		for range time.Tick(interval) {
+			n, err := dispatchService.ExpireOffers(context.Background())
+			if err != nil {
+				log.Printf("Gagal memproses tawaran dispatch kedaluwarsa: %v\n", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("%d tawaran dispatch kedaluwarsa, diteruskan ke driver berikutnya\n", n)
+			}
+		}
+	}()
+}
+
+func startRecurringScheduler(repos *repositories, interval time.Duration) {
+	recurringService := service.NewRecurringService(repos.Recurring, newBookingService(repos), recurringDaysAhead)
+
+	go func() {
+		for range time.Tick(interval) {
+			n, err := recurringService.Materialise(context.Background())
+			if err != nil {
+				log.Printf("Gagal menjadwalkan booking berulang: %v\n", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("%d booking berulang dijadwalkan\n", n)
+			}
+		}
+	}()
+}
+
+// startInvoiceScheduler checks every interval for corporate accounts still to
+// be invoiced for the previous month.
+func startInvoiceScheduler(repos *repositories, interval time.Duration) {
+	corporateService := service.NewCorporateService(repos.Corporate)
+
+	go func() {
+		for range time.Tick(interval) {
+			n, err := corporateService.RunMonthly(context.Background(), time.Now())
+			if err != nil {
+				log.Printf("Gagal membuat invoice bulanan: %v\n", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("%d invoice bulanan diterbitkan\n", n)
+			}
+		}
+	}()
+}
+
+// startPaymentExpiry expires pending and authorised payments that were not
+// captured in time.
+func startPaymentExpiry(repos *repositories, interval time.Duration) {
+	paymentService := newPaymentService(repos)
+
+	go func() {
+		for range time.Tick(interval) {
+			n, err := paymentService.ExpirePending(context.Background())
+			if err != nil {
+				log.Printf("Gagal memproses pembayaran kedaluwarsa: %v\n", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("%d pembayaran kedaluwarsa\n", n)
+			}
+		}
+	}()
+}
+
+func startNotificationDispatcher(repos *repositories, senders map[string]service.NotificationSender, interval time.Duration) {
+	notificationService := service.NewNotificationService(repos.Notifications, repos.Customers, repos.Drivers)
+	notificationService.Senders = senders
+
+	go func() {
+		for range time.Tick(interval) {
+			n, err := notificationService.Deliver(context.Background())
+			if err != nil {
+				log.Printf("Gagal mengirim notifikasi: %v\n", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("%d notifikasi terkirim\n", n)
+			}
+		}
+	}()
+}
+
+// notificationSenders uses the real provider for each channel that has been
+// configured and writes the rest to the notification log.
+func notificationSenders(cfg notificationConfig) (map[string]service.NotificationSender, error) {
+	local, err := service.NewFileSender(cfg.LogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	senders := map[string]service.NotificationSender{
+		model.ChannelEmail:    local,
+		model.ChannelSMS:      local,
+		model.ChannelWhatsApp: local,
+	}
+	if cfg.SMTPAddr != "" {
+		senders[model.ChannelEmail] = service.NewSMTPSender(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUser, cfg.SMTPPassword)
+	}
+	if cfg.SMSURL != "" {
+		senders[model.ChannelSMS] = &service.SMSSender{URL: cfg.SMSURL, Token: cfg.SMSToken, From: cfg.SMSFrom}
+	}
+	if cfg.WhatsAppURL != "" {
+		senders[model.ChannelWhatsApp] = &service.WhatsAppSender{URL: cfg.WhatsAppURL, Token: cfg.WhatsAppToken}
+	}
+	return senders, nil
+}
+
+// geocoder looks addresses up on a Nominatim server when one is configured,
+// otherwise in the gazetteer file, falling back to the built-in places.
+func geocoder(url, gazetteerPath string) (service.Geocoder, error) {
+	if url != "" {
+		return &service.NominatimGeocoder{URL: url, UserAgent: "auth-service", Client: &http.Client{Timeout: 10 * time.Second}}, nil
+	}
+	if gazetteerPath == "" {
+		return service.DefaultGazetteer(), nil
+	}
+
+	f, err := os.Open(gazetteerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return service.LoadGazetteer(f)
+}
+
+func loadRoadGraph(path string) (*service.RoadGraph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	graph, err := service.LoadRoadGraph(f)
+	if err != nil {
+		return nil, err
+	}
+	graph.Location = time.FixedZone("WIB", 7*60*60)
+	return graph, nil
+}
+
+// paymentProviders collects virtual account, QRIS and e-wallet payments
+// through the configured gateway. Without one, the local mock gateway is
+// started when it has an address, and payments are only recorded otherwise.
+func paymentProviders(cfg paymentConfig) map[string]service.PaymentProvider {
+	url := cfg.GatewayURL
+	if url == "" {
+		if cfg.MockAddr == "" {
+			return nil
+		}
+		mock := service.NewMockGateway(cfg.ServerKey, cfg.CallbackURL, cfg.CallbackSecret)
+		go func() {
+			if err := http.ListenAndServe(cfg.MockAddr, mock); err != nil {
+				log.Printf("Mock payment gateway berhenti: %v\n", err)
+			}
+		}()
+		url = "http://" + cfg.MockAddr
+		if strings.HasPrefix(cfg.MockAddr, ":") {
+			url = "http://localhost" + cfg.MockAddr
+		}
+		fmt.Println("✅ Mock payment gateway aktif di", url)
+	}
+
+	gateway := &service.Gateway{URL: url, ServerKey: cfg.ServerKey, Client: &http.Client{Timeout: 15 * time.Second}}
+	return map[string]service.PaymentProvider{
+		model.PaymentMethodVirtualAccount: &service.VirtualAccountProvider{Gateway: gateway, Bank: cfg.Bank},
+		model.PaymentMethodQRIS:           &service.QRISProvider{Gateway: gateway},
+		model.PaymentMethodEWallet:        &service.EWalletProvider{Gateway: gateway, Wallet: cfg.Wallet},
+	}
+}
+
+type paymentConfig struct {
+	GatewayURL     string
+	ServerKey      string
+	CallbackSecret string
+	CallbackURL    string
+	MockAddr       string
+	Bank           string
+	Wallet         string
+}
+
+type notificationConfig struct {
+	LogPath       string
+	SMTPAddr      string
+	SMTPFrom      string
+	SMTPUser      string
+	SMTPPassword  string
+	SMSURL        string
+	SMSToken      string
+	SMSFrom       string
+	WhatsAppURL   string
+	WhatsAppToken string
+}
+
 func main() {
-	db, err := sql.Open("postgres",
+	storage := flag.String("storage", storagePostgres, "storage backend: postgres or memory")
+	var notify notificationConfig
+	flag.StringVar(&notify.LogPath, "notify-log", "notifications.log", "file that receives notifications for channels without a provider")
+	flag.StringVar(&notify.SMTPAddr, "smtp-addr", "", "SMTP relay host:port for email notifications")
+	flag.StringVar(&notify.SMTPFrom, "smtp-from", "", "sender address for email notifications")
+	flag.StringVar(&notify.SMTPUser, "smtp-user", "", "SMTP username")
+	flag.StringVar(&notify.SMTPPassword, "smtp-password", "", "SMTP password")
+	flag.StringVar(&notify.SMSURL, "sms-url", "", "SMS gateway endpoint")
+	flag.StringVar(&notify.SMSToken, "sms-token", "", "SMS gateway bearer token")
+	flag.StringVar(&notify.SMSFrom, "sms-from", "", "SMS sender ID")
+	flag.StringVar(&notify.WhatsAppURL, "whatsapp-url", "", "WhatsApp Cloud API messages endpoint")
+	flag.StringVar(&notify.WhatsAppToken, "whatsapp-token", "", "WhatsApp Cloud API access token")
+	geocoderURL := flag.String("geocoder-url", "", "Nominatim server used to geocode addresses")
+	gazetteer := flag.String("gazetteer", "", "CSV of name,lat,lng places used to geocode addresses offline")
+	roadGraph := flag.String("road-graph", "", "road network file used to estimate route distance and ETA")
+	var payments paymentConfig
+	flag.StringVar(&payments.GatewayURL, "payment-gateway-url", "", "base URL of the payment gateway API")
+	flag.StringVar(&payments.ServerKey, "payment-server-key", "", "payment gateway server key")
+	flag.StringVar(&payments.CallbackSecret, "payment-callback-secret", "", "secret the payment gateway signs its callbacks with")
+	flag.StringVar(&payments.CallbackURL, "payment-callback-url", "http://localhost:8080/payments/callback", "where the mock payment gateway sends its callbacks")
+	flag.StringVar(&payments.MockAddr, "mock-gateway", "", "address to run the local mock payment gateway on, e.g. :8090, when no gateway URL is set")
+	flag.StringVar(&payments.Bank, "payment-va-bank", "bca", "bank that issues virtual accounts")
+	flag.StringVar(&payments.Wallet, "payment-ewallet", "gopay", "e-wallet that e-wallet payments are made with")
+	feedbackURL := flag.String("feedback-url", "", "page customers are sent to rate their trip; the link token is appended")
+	ratingAlertEmail := flag.String("rating-alert-email", "", "address that receives low driver rating alerts")
+	flag.Parse()
+
+	repos, db, err := openStorage(*storage,
 		"host=localhost port=5432 user=postgres password=1234567 dbname=authdb sslmode=disable")
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
-	defer db.Close()
 
 	defaultPassword := "12345"
-	if err := ResetAllPasswords(db, defaultPassword); err != nil {
+	if db != nil {
+		defer db.Close()
+
+		if err := ResetAllPasswords(db, defaultPassword); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("✅ Semua password user berhasil di-reset ke default:", defaultPassword)
+	} else {
+		if err := seedMemoryAdmin(repos, "admin", defaultPassword); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("✅ Storage memory aktif, login dengan admin /", defaultPassword)
+	}
+
+	repos.Geocoder, err = geocoder(*geocoderURL, *gazetteer)
+	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println("✅ Semua password user berhasil di-reset ke default:", defaultPassword)
+	if *roadGraph != "" {
+		graph, err := loadRoadGraph(*roadGraph)
+		if err != nil {
+			log.Fatal(err)
+		}
+		repos.Router = graph
+	}
+
+	repos.FeedbackURL = *feedbackURL
+	repos.RatingAlertEmail = *ratingAlertEmail
+	if payments.GatewayURL == "" && payments.MockAddr != "" && payments.CallbackSecret == "" {
+		// The mock gateway and its callbacks only need to agree locally.
+		payments.CallbackSecret = "mock-callback-secret"
+	}
+	repos.PaymentProviders = paymentProviders(payments)
+	repos.PaymentCallbackSecret = payments.CallbackSecret
+
+	startIdempotencyPurge(repos, time.Hour)
+	startTrashPurge(repos, 24*time.Hour)
+	startDispatchSweep(repos, 15*time.Second)
+	startRecurringScheduler(repos, time.Hour)
+	startInvoiceScheduler(repos, time.Hour)
+	startPaymentExpiry(repos, 5*time.Minute)
+
+	senders, err := notificationSenders(notify)
+	if err != nil {
+		log.Fatal(err)
+	}
+	startNotificationDispatcher(repos, senders, 30*time.Second)
 
-	r := SetupRouter(db)
+	r := SetupRouter(repos)
 
 	fmt.Println("Server running at http://localhost:8080")
 	if err := r.Run(":8080"); err != nil {
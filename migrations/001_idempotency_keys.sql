@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key           VARCHAR(255) PRIMARY KEY,
+    method        VARCHAR(10)  NOT NULL,
+    path          TEXT         NOT NULL,
+    fingerprint   CHAR(64)     NOT NULL,
+    status_code   INT          NOT NULL DEFAULT 0,
+    response_body BYTEA,
+    created_at    TIMESTAMP    NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMP    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
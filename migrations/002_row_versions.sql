@@ -0,0 +1,4 @@
+ALTER TABLE booking  ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE drivers  ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE payment  ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,9 @@
+ALTER TABLE drivers  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE booking  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE payment  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_drivers_deleted_at  ON drivers (deleted_at)  WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_vehicles_deleted_at ON vehicles (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_booking_deleted_at  ON booking (deleted_at)  WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payment_deleted_at  ON payment (deleted_at)  WHERE deleted_at IS NOT NULL;
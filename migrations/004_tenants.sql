@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id         BIGSERIAL    PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    slug       VARCHAR(64)  NOT NULL UNIQUE,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+-- Existing rows belong to the operator that ran this deployment before tenants existed.
+INSERT INTO tenants (id, name, slug) VALUES (1, 'Default', 'default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('tenants', 'id'), GREATEST((SELECT MAX(id) FROM tenants), 1));
+
+ALTER TABLE users                ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE drivers              ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE vehicles             ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE booking              ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE payment              ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE vehicle_trips        ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE trips                ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE driver_assignments   ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE vehicle_maintenance  ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE car_type             ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE car_model            ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE popular_destinations ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE booking_trends       ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE idempotency_keys     ADD COLUMN IF NOT EXISTS tenant_id BIGINT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+
+ALTER TABLE users                ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE drivers              ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE vehicles             ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE booking              ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE payment              ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE vehicle_trips        ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE trips                ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE driver_assignments   ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE vehicle_maintenance  ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE car_type             ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE car_model            ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE popular_destinations ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE booking_trends       ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_keys     ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant                ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_drivers_tenant              ON drivers (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_vehicles_tenant             ON vehicles (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_booking_tenant              ON booking (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_payment_tenant              ON payment (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_vehicle_trips_tenant        ON vehicle_trips (tenant_id, vehicle_id);
+CREATE INDEX IF NOT EXISTS idx_trips_tenant                ON trips (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_driver_assignments_tenant   ON driver_assignments (tenant_id, vehicle_id);
+CREATE INDEX IF NOT EXISTS idx_vehicle_maintenance_tenant  ON vehicle_maintenance (tenant_id, vehicle_id);
+CREATE INDEX IF NOT EXISTS idx_car_type_tenant             ON car_type (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_car_model_tenant            ON car_model (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_popular_destinations_tenant ON popular_destinations (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_booking_trends_tenant       ON booking_trends (tenant_id, year);
+
+-- Idempotency keys are only unique within a tenant.
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key);
@@ -0,0 +1,30 @@
+-- Fold free-text statuses into the lifecycle; anything unrecognised restarts at Pending.
+UPDATE booking SET status = CASE lower(regexp_replace(status, '[\s_-]', '', 'g'))
+    WHEN 'pending'        THEN 'Pending'
+    WHEN 'confirmed'      THEN 'Confirmed'
+    WHEN 'driverassigned' THEN 'DriverAssigned'
+    WHEN 'ontrip'         THEN 'OnTrip'
+    WHEN 'completed'      THEN 'Completed'
+    WHEN 'cancelled'      THEN 'Cancelled'
+    WHEN 'canceled'       THEN 'Cancelled'
+    WHEN 'noshow'         THEN 'NoShow'
+    ELSE 'Pending'
+END;
+
+ALTER TABLE booking ALTER COLUMN status SET DEFAULT 'Pending';
+ALTER TABLE booking DROP CONSTRAINT IF EXISTS booking_status_check;
+ALTER TABLE booking ADD CONSTRAINT booking_status_check
+    CHECK (status IN ('Pending', 'Confirmed', 'DriverAssigned', 'OnTrip', 'Completed', 'Cancelled', 'NoShow'));
+
+CREATE TABLE IF NOT EXISTS booking_status_history (
+    id          BIGSERIAL    PRIMARY KEY,
+    tenant_id   BIGINT       NOT NULL REFERENCES tenants (id),
+    booking_id  VARCHAR(32)  NOT NULL REFERENCES booking (id) ON DELETE CASCADE,
+    from_status VARCHAR(20)  NOT NULL,
+    to_status   VARCHAR(20)  NOT NULL,
+    changed_by  BIGINT       NOT NULL,
+    reason      TEXT         NOT NULL DEFAULT '',
+    changed_at  TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_status_history_booking ON booking_status_history (tenant_id, booking_id, changed_at);
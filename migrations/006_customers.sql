@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS customers (
+    id          SERIAL       PRIMARY KEY,
+    tenant_id   BIGINT       NOT NULL REFERENCES tenants (id),
+    name        VARCHAR(255) NOT NULL,
+    phones      TEXT[]       NOT NULL DEFAULT '{}',
+    emails      TEXT[]       NOT NULL DEFAULT '{}',
+    addresses   TEXT[]       NOT NULL DEFAULT '{}',
+    notes       TEXT         NOT NULL DEFAULT '',
+    blacklisted BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    version     INT          NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_tenant ON customers (tenant_id, name);
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS customer_id INT REFERENCES customers (id) ON DELETE SET NULL;
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS customer_id INT REFERENCES customers (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_booking_customer ON booking (tenant_id, customer_id);
+CREATE INDEX IF NOT EXISTS idx_payment_customer ON payment (tenant_id, customer_id);
@@ -0,0 +1,34 @@
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS driver_id  INT REFERENCES drivers (id) ON DELETE SET NULL;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS vehicle_id INT REFERENCES vehicles (id) ON DELETE SET NULL;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS start_at   TIMESTAMP;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS end_at     TIMESTAMP;
+
+ALTER TABLE booking DROP CONSTRAINT IF EXISTS booking_window_check;
+ALTER TABLE booking ADD CONSTRAINT booking_window_check
+    CHECK ((start_at IS NULL AND end_at IS NULL) OR end_at > start_at);
+
+CREATE TABLE IF NOT EXISTS driver_leave (
+    id         SERIAL     PRIMARY KEY,
+    tenant_id  BIGINT     NOT NULL REFERENCES tenants (id),
+    driver_id  INT        NOT NULL REFERENCES drivers (id) ON DELETE CASCADE,
+    start_at   TIMESTAMP  NOT NULL,
+    end_at     TIMESTAMP  NOT NULL CHECK (end_at > start_at),
+    reason     TEXT       NOT NULL DEFAULT '',
+    created_at TIMESTAMP  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_leave_driver ON driver_leave (tenant_id, driver_id, start_at);
+
+-- The service checks availability before writing; these constraints close the
+-- race between two concurrent bookings for the same driver or vehicle.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE booking DROP CONSTRAINT IF EXISTS booking_driver_no_overlap;
+ALTER TABLE booking ADD CONSTRAINT booking_driver_no_overlap
+    EXCLUDE USING gist (tenant_id WITH =, driver_id WITH =, tsrange(start_at, end_at) WITH &&)
+    WHERE (driver_id IS NOT NULL AND deleted_at IS NULL AND status NOT IN ('Cancelled', 'NoShow'));
+
+ALTER TABLE booking DROP CONSTRAINT IF EXISTS booking_vehicle_no_overlap;
+ALTER TABLE booking ADD CONSTRAINT booking_vehicle_no_overlap
+    EXCLUDE USING gist (tenant_id WITH =, vehicle_id WITH =, tsrange(start_at, end_at) WITH &&)
+    WHERE (vehicle_id IS NOT NULL AND deleted_at IS NULL AND status NOT IN ('Cancelled', 'NoShow'));
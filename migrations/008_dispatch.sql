@@ -0,0 +1,30 @@
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS pickup_lat  DOUBLE PRECISION;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS pickup_lng  DOUBLE PRECISION;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS car_type_id VARCHAR(50);
+
+CREATE TABLE IF NOT EXISTS driver_locations (
+    driver_id  INT              PRIMARY KEY REFERENCES drivers (id) ON DELETE CASCADE,
+    tenant_id  BIGINT           NOT NULL REFERENCES tenants (id),
+    latitude   DOUBLE PRECISION NOT NULL,
+    longitude  DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP        NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS dispatch_offers (
+    id           SERIAL           PRIMARY KEY,
+    tenant_id    BIGINT           NOT NULL REFERENCES tenants (id),
+    booking_id   VARCHAR(32)      NOT NULL REFERENCES booking (id) ON DELETE CASCADE,
+    driver_id    INT              NOT NULL REFERENCES drivers (id) ON DELETE CASCADE,
+    score        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    status       VARCHAR(20)      NOT NULL DEFAULT 'Pending'
+                 CHECK (status IN ('Pending', 'Accepted', 'Declined', 'Expired')),
+    offered_at   TIMESTAMP        NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMP        NOT NULL,
+    responded_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispatch_offers_booking ON dispatch_offers (tenant_id, booking_id, offered_at);
+CREATE INDEX IF NOT EXISTS idx_dispatch_offers_pending ON dispatch_offers (expires_at) WHERE status = 'Pending';
+
+-- At most one open offer per booking.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dispatch_offers_one_pending ON dispatch_offers (booking_id) WHERE status = 'Pending';
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS tariffs (
+    tenant_id           BIGINT        NOT NULL REFERENCES tenants (id),
+    car_type_id         VARCHAR(50)   NOT NULL,
+    base_fare           NUMERIC(12,2) NOT NULL DEFAULT 0,
+    per_km              NUMERIC(12,2) NOT NULL DEFAULT 0,
+    per_minute          NUMERIC(12,2) NOT NULL DEFAULT 0,
+    minimum_fare        NUMERIC(12,2) NOT NULL DEFAULT 0,
+    night_surcharge_pct NUMERIC(5,2)  NOT NULL DEFAULT 0,
+    night_start_hour    SMALLINT      NOT NULL DEFAULT 22 CHECK (night_start_hour BETWEEN 0 AND 23),
+    night_end_hour      SMALLINT      NOT NULL DEFAULT 5  CHECK (night_end_hour BETWEEN 0 AND 23),
+    airport_surcharge   NUMERIC(12,2) NOT NULL DEFAULT 0,
+    updated_at          TIMESTAMP     NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, car_type_id)
+);
+
+CREATE TABLE IF NOT EXISTS quotes (
+    id               SERIAL           PRIMARY KEY,
+    tenant_id        BIGINT           NOT NULL REFERENCES tenants (id),
+    car_type_id      VARCHAR(50)      NOT NULL,
+    distance_km      DOUBLE PRECISION NOT NULL,
+    duration_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    pickup_at        TIMESTAMPTZ      NOT NULL,
+    airport          BOOLEAN          NOT NULL DEFAULT FALSE,
+    items            JSONB            NOT NULL,
+    total            NUMERIC(12,2)    NOT NULL,
+    expires_at       TIMESTAMP        NOT NULL,
+    created_at       TIMESTAMP        NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS quote_id INT REFERENCES quotes (id) ON DELETE SET NULL;
+
+-- A quote locks its price for exactly one booking.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_booking_quote ON booking (quote_id) WHERE quote_id IS NOT NULL;
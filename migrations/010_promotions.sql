@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS promotions (
+    id               SERIAL        PRIMARY KEY,
+    tenant_id        BIGINT        NOT NULL REFERENCES tenants (id),
+    code             VARCHAR(40)   NOT NULL,
+    description      TEXT          NOT NULL DEFAULT '',
+    discount_type    VARCHAR(10)   NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value   NUMERIC(12,2) NOT NULL CHECK (discount_value > 0),
+    max_discount     NUMERIC(12,2),
+    minimum_fare     NUMERIC(12,2) NOT NULL DEFAULT 0,
+    car_type_ids     TEXT[]        NOT NULL DEFAULT '{}',
+    valid_from       TIMESTAMPTZ,
+    valid_until      TIMESTAMPTZ,
+    max_redemptions  INT,
+    max_per_customer INT,
+    active           BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at       TIMESTAMP     NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMP     NOT NULL DEFAULT NOW(),
+    version          INT           NOT NULL DEFAULT 1,
+    UNIQUE (tenant_id, code)
+);
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS promo_code VARCHAR(40);
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS discount   NUMERIC(12,2);
+
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS promo_code VARCHAR(40);
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS discount   NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id           SERIAL        PRIMARY KEY,
+    tenant_id    BIGINT        NOT NULL REFERENCES tenants (id),
+    promotion_id INT           NOT NULL REFERENCES promotions (id),
+    booking_id   VARCHAR(32)   NOT NULL UNIQUE REFERENCES booking (id) ON DELETE CASCADE,
+    customer_id  INT           REFERENCES customers (id) ON DELETE SET NULL,
+    discount     NUMERIC(12,2) NOT NULL,
+    redeemed_at  TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_promotion ON promotion_redemptions (promotion_id, customer_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_tenant    ON promotion_redemptions (tenant_id, redeemed_at);
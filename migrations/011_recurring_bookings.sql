@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS recurring_bookings (
+    id               SERIAL           PRIMARY KEY,
+    tenant_id        BIGINT           NOT NULL REFERENCES tenants (id),
+    customer_id      INT              REFERENCES customers (id) ON DELETE SET NULL,
+    customer         VARCHAR(255)     NOT NULL DEFAULT '',
+    phone_number     VARCHAR(50),
+    place            VARCHAR(255)     NOT NULL DEFAULT '',
+    pickup_location  TEXT,
+    pickup_lat       DOUBLE PRECISION,
+    pickup_lng       DOUBLE PRECISION,
+    drop_location    TEXT,
+    car_type_id      VARCHAR(50),
+    driver_id        INT              REFERENCES drivers (id) ON DELETE SET NULL,
+    vehicle_id       INT              REFERENCES vehicles (id) ON DELETE SET NULL,
+    amount           NUMERIC(12,2),
+    payment          VARCHAR(50)      NOT NULL DEFAULT '',
+    notes            TEXT,
+    frequency        VARCHAR(10)      NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    repeat_interval  INT              NOT NULL DEFAULT 1 CHECK (repeat_interval > 0),
+    weekdays         TEXT[]           NOT NULL DEFAULT '{}',
+    start_at         TIMESTAMP        NOT NULL,
+    duration_minutes INT              NOT NULL CHECK (duration_minutes > 0),
+    until            DATE,
+    exceptions       DATE[]           NOT NULL DEFAULT '{}',
+    days_ahead       INT              NOT NULL DEFAULT 0,
+    created_at       TIMESTAMP        NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMP        NOT NULL DEFAULT NOW(),
+    version          INT              NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_bookings_tenant ON recurring_bookings (tenant_id, id);
+CREATE INDEX IF NOT EXISTS idx_recurring_bookings_until  ON recurring_bookings (until);
+
+-- One row per scheduled day; the unique key stops two schedulers from booking
+-- the same day twice.
+CREATE TABLE IF NOT EXISTS recurring_occurrences (
+    id              SERIAL      PRIMARY KEY,
+    tenant_id       BIGINT      NOT NULL REFERENCES tenants (id),
+    recurring_id    INT         NOT NULL REFERENCES recurring_bookings (id) ON DELETE CASCADE,
+    occurrence_date DATE        NOT NULL,
+    booking_id      VARCHAR(32) REFERENCES booking (id) ON DELETE SET NULL,
+    detached        BOOLEAN     NOT NULL DEFAULT FALSE,
+    error           TEXT,
+    created_at      TIMESTAMP   NOT NULL DEFAULT NOW(),
+    UNIQUE (recurring_id, occurrence_date)
+);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS cancellation_policies (
+    tenant_id           BIGINT        PRIMARY KEY REFERENCES tenants (id),
+    free_hours          INT           NOT NULL DEFAULT 24 CHECK (free_hours >= 0),
+    late_fee_percent    NUMERIC(5,2)  NOT NULL DEFAULT 50 CHECK (late_fee_percent BETWEEN 0 AND 100),
+    minimum_late_fee    NUMERIC(12,2) NOT NULL DEFAULT 0,
+    no_show_fee_percent NUMERIC(5,2)  NOT NULL DEFAULT 100 CHECK (no_show_fee_percent BETWEEN 0 AND 100),
+    updated_at          TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS booking_cancellations (
+    id                SERIAL        PRIMARY KEY,
+    tenant_id         BIGINT        NOT NULL REFERENCES tenants (id),
+    booking_id        VARCHAR(32)   NOT NULL UNIQUE REFERENCES booking (id) ON DELETE CASCADE,
+    status            VARCHAR(20)   NOT NULL,
+    hours_before      DOUBLE PRECISION,
+    fare              NUMERIC(12,2) NOT NULL DEFAULT 0,
+    paid              NUMERIC(12,2) NOT NULL DEFAULT 0,
+    fee               NUMERIC(12,2) NOT NULL DEFAULT 0,
+    refund            NUMERIC(12,2) NOT NULL DEFAULT 0,
+    fee_payment_id    INT           REFERENCES payment (payment_id) ON DELETE SET NULL,
+    refund_payment_id INT           REFERENCES payment (payment_id) ON DELETE SET NULL,
+    reason            TEXT          NOT NULL DEFAULT '',
+    cancelled_by      BIGINT        NOT NULL,
+    created_at        TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_cancellations_tenant ON booking_cancellations (tenant_id, created_at);
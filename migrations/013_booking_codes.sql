@@ -0,0 +1,9 @@
+-- Booking IDs are booking codes drawn from this sequence, see utils.BookingCode.
+CREATE SEQUENCE IF NOT EXISTS booking_code_seq;
+
+-- Completed trips point at their booking by code. Older rows are left
+-- unchecked; new and updated rows must name an existing booking.
+ALTER TABLE trips ALTER COLUMN booking_code DROP NOT NULL;
+ALTER TABLE trips DROP CONSTRAINT IF EXISTS trips_booking_code_fkey;
+ALTER TABLE trips ADD CONSTRAINT trips_booking_code_fkey
+    FOREIGN KEY (booking_code) REFERENCES booking (id) ON DELETE SET NULL NOT VALID;
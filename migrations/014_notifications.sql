@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    customer_id INT         PRIMARY KEY REFERENCES customers (id) ON DELETE CASCADE,
+    tenant_id   BIGINT      NOT NULL REFERENCES tenants (id),
+    locale      VARCHAR(5)  NOT NULL DEFAULT 'id' CHECK (locale IN ('id', 'en')),
+    channels    TEXT[]      NOT NULL DEFAULT '{}',
+    updated_at  TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id              SERIAL       PRIMARY KEY,
+    tenant_id       BIGINT       NOT NULL REFERENCES tenants (id),
+    event           VARCHAR(40)  NOT NULL,
+    channel         VARCHAR(20)  NOT NULL CHECK (channel IN ('email', 'sms', 'whatsapp')),
+    recipient       VARCHAR(255) NOT NULL,
+    locale          VARCHAR(5)   NOT NULL DEFAULT 'id',
+    subject         TEXT         NOT NULL DEFAULT '',
+    body            TEXT         NOT NULL,
+    booking_id      VARCHAR(32)  REFERENCES booking (id) ON DELETE SET NULL,
+    customer_id     INT          REFERENCES customers (id) ON DELETE SET NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    attempts        INT          NOT NULL DEFAULT 0,
+    last_error      TEXT         NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMP,
+    created_at      TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_due ON notifications (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant ON notifications (tenant_id, created_at);
@@ -0,0 +1,55 @@
+-- Trips live in one table. A trip is opened when its booking goes on trip and
+-- closed with the actual distance, duration and fare when it completes.
+ALTER TABLE trips DROP CONSTRAINT IF EXISTS trips_booking_code_fkey;
+
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS vehicle_id INT;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS driver_id  INT;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS status     VARCHAR(20) NOT NULL DEFAULT 'completed';
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS started_at TIMESTAMP;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS ended_at   TIMESTAMP;
+
+ALTER TABLE trips DROP CONSTRAINT IF EXISTS trips_vehicle_id_fkey;
+ALTER TABLE trips ADD CONSTRAINT trips_vehicle_id_fkey
+    FOREIGN KEY (vehicle_id) REFERENCES vehicles (id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE trips DROP CONSTRAINT IF EXISTS trips_driver_id_fkey;
+ALTER TABLE trips ADD CONSTRAINT trips_driver_id_fkey
+    FOREIGN KEY (driver_id) REFERENCES drivers (id) ON DELETE SET NULL NOT VALID;
+
+-- Fares may carry cents, and a trip is unrated until the customer rates it.
+ALTER TABLE trips ALTER COLUMN amount TYPE NUMERIC(12, 2);
+ALTER TABLE trips ALTER COLUMN rating DROP NOT NULL;
+ALTER TABLE trips ALTER COLUMN feedback DROP NOT NULL;
+
+-- Trips name their booking by booking_id, and the old vehicle_trips rows are
+-- folded in once.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'trips' AND column_name = 'booking_code') THEN
+        ALTER TABLE trips RENAME COLUMN booking_code TO booking_id;
+    END IF;
+
+    IF to_regclass('vehicle_trips') IS NOT NULL THEN
+        INSERT INTO trips (vehicle_id, driver_id, booking_date, pickup_location, destination, distance_km,
+                           duration_minutes, rating, amount, customer_name, driver_name, vehicle_name,
+                           status, started_at, ended_at, tenant_id)
+        SELECT NULLIF(vt.vehicle_id, 0), NULLIF(vt.driver_id, 0), vt.trip_date, vt.origin, vt.destination, vt.distance_km,
+               COALESCE(vt.duration, 0), NULLIF(vt.rating, 0), vt.price, vt.passenger_name, COALESCE(d.name, ''),
+               COALESCE(v.brand || ' ' || v.model, ''), 'completed', vt.trip_date, vt.trip_date, vt.tenant_id
+        FROM vehicle_trips vt
+        LEFT JOIN drivers d ON d.id = vt.driver_id AND d.tenant_id = vt.tenant_id
+        LEFT JOIN vehicles v ON v.id = vt.vehicle_id AND v.tenant_id = vt.tenant_id;
+
+        DROP TABLE vehicle_trips;
+    END IF;
+END $$;
+
+ALTER TABLE trips DROP CONSTRAINT IF EXISTS trips_booking_id_fkey;
+ALTER TABLE trips ADD CONSTRAINT trips_booking_id_fkey
+    FOREIGN KEY (booking_id) REFERENCES booking (id) ON DELETE SET NULL NOT VALID;
+
+UPDATE trips SET started_at = booking_date, ended_at = booking_date WHERE started_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_trips_booking ON trips (tenant_id, booking_id);
+CREATE INDEX IF NOT EXISTS idx_trips_vehicle ON trips (tenant_id, vehicle_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trips_open_booking ON trips (booking_id) WHERE status = 'ongoing';
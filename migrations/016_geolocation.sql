@@ -0,0 +1,13 @@
+-- Drop-off coordinates sit next to the pickup ones from 008, and the booking
+-- keeps the great-circle distance between them.
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS drop_lat    DOUBLE PRECISION;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS drop_lng    DOUBLE PRECISION;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS distance_km DOUBLE PRECISION;
+
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS pickup_lat      DOUBLE PRECISION;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS pickup_lng      DOUBLE PRECISION;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS destination_lat DOUBLE PRECISION;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS destination_lng DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_booking_pickup_point ON booking (tenant_id, pickup_lat, pickup_lng)
+    WHERE deleted_at IS NULL AND pickup_lat IS NOT NULL;
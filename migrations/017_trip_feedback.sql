@@ -0,0 +1,36 @@
+-- A completed trip is rated once. The driver's averages are kept on the driver
+-- row and refreshed with every rating; the rolling one covers the most recent
+-- ratings only and raises an alert when it drops below the threshold.
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS rating_average NUMERIC(3, 2) NOT NULL DEFAULT 0;
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS rating_count   INT           NOT NULL DEFAULT 0;
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS rolling_rating NUMERIC(3, 2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS trip_feedback (
+    id          SERIAL      PRIMARY KEY,
+    tenant_id   BIGINT      NOT NULL REFERENCES tenants (id),
+    trip_id     INT         NOT NULL REFERENCES trips (id) ON DELETE CASCADE,
+    booking_id  VARCHAR(32) REFERENCES booking (id) ON DELETE SET NULL,
+    driver_id   INT         REFERENCES drivers (id) ON DELETE SET NULL,
+    customer_id INT         REFERENCES customers (id) ON DELETE SET NULL,
+    rating      SMALLINT    NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    tags        TEXT[]      NOT NULL DEFAULT '{}',
+    comment     TEXT        NOT NULL DEFAULT '',
+    source      VARCHAR(10) NOT NULL CHECK (source IN ('staff', 'link')),
+    created_at  TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trip_feedback_trip ON trip_feedback (trip_id);
+CREATE INDEX IF NOT EXISTS idx_trip_feedback_driver ON trip_feedback (tenant_id, driver_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS driver_rating_alerts (
+    id              SERIAL        PRIMARY KEY,
+    tenant_id       BIGINT        NOT NULL REFERENCES tenants (id),
+    driver_id       INT           NOT NULL REFERENCES drivers (id) ON DELETE CASCADE,
+    rolling_rating  NUMERIC(3, 2) NOT NULL,
+    threshold       NUMERIC(3, 2) NOT NULL,
+    created_at      TIMESTAMP     NOT NULL DEFAULT NOW(),
+    acknowledged_at TIMESTAMP,
+    acknowledged_by INT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_driver_rating_alerts_open ON driver_rating_alerts (driver_id) WHERE acknowledged_at IS NULL;
@@ -0,0 +1,25 @@
+-- Ordered stops a booking makes between its pickup and drop-off. Each stop is
+-- priced as its own leg and, for a scheduled wait, as waiting time.
+CREATE TABLE IF NOT EXISTS booking_stops (
+    booking_id    VARCHAR(32)  NOT NULL REFERENCES booking (id) ON DELETE CASCADE,
+    position      SMALLINT     NOT NULL CHECK (position > 0),
+    kind          VARCHAR(10)  NOT NULL DEFAULT 'waypoint' CHECK (kind IN ('pickup', 'drop_off', 'waypoint')),
+    address       TEXT         NOT NULL,
+    lat           DOUBLE PRECISION,
+    lng           DOUBLE PRECISION,
+    contact_name  VARCHAR(100) NOT NULL DEFAULT '',
+    contact_phone VARCHAR(30)  NOT NULL DEFAULT '',
+    wait_minutes  INT          NOT NULL DEFAULT 0 CHECK (wait_minutes >= 0),
+    leg_km        NUMERIC(8, 1),
+    status        VARCHAR(10)  NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'arrived', 'departed', 'skipped')),
+    arrived_at    TIMESTAMP,
+    departed_at   TIMESTAMP,
+    tenant_id     BIGINT       NOT NULL REFERENCES tenants (id),
+    PRIMARY KEY (booking_id, position)
+);
+
+ALTER TABLE tariffs ADD COLUMN IF NOT EXISTS per_stop NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS stops        INT   NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS wait_minutes INT   NOT NULL DEFAULT 0;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS legs         JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,68 @@
+-- Business customers book on account. Bookings charged to an account are
+-- collected into a monthly invoice instead of being paid per trip.
+CREATE TABLE IF NOT EXISTS corporate_accounts (
+    id                 SERIAL        PRIMARY KEY,
+    tenant_id          BIGINT        NOT NULL REFERENCES tenants (id),
+    name               VARCHAR(150)  NOT NULL,
+    billing_email      VARCHAR(150)  NOT NULL DEFAULT '',
+    billing_address    TEXT          NOT NULL DEFAULT '',
+    tax_id             VARCHAR(40)   NOT NULL DEFAULT '',
+    booker_ids         INT[]         NOT NULL DEFAULT '{}',
+    cost_centres       TEXT[]        NOT NULL DEFAULT '{}',
+    credit_limit       NUMERIC(14,2) CHECK (credit_limit >= 0),
+    payment_terms_days INT           NOT NULL DEFAULT 30 CHECK (payment_terms_days >= 0),
+    active             BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at         TIMESTAMP     NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMP     NOT NULL DEFAULT NOW(),
+    version            INT           NOT NULL DEFAULT 1,
+    UNIQUE (tenant_id, name)
+);
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS corporate_account_id INT REFERENCES corporate_accounts (id);
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS cost_centre          VARCHAR(60);
+
+CREATE INDEX IF NOT EXISTS idx_booking_corporate_account ON booking (corporate_account_id) WHERE corporate_account_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS corporate_invoices (
+    id           SERIAL        PRIMARY KEY,
+    tenant_id    BIGINT        NOT NULL REFERENCES tenants (id),
+    account_id   INT           NOT NULL REFERENCES corporate_accounts (id),
+    number       VARCHAR(40)   NOT NULL,
+    period_start DATE          NOT NULL,
+    period_end   DATE          NOT NULL,
+    total        NUMERIC(14,2) NOT NULL DEFAULT 0,
+    amount_paid  NUMERIC(14,2) NOT NULL DEFAULT 0,
+    status       VARCHAR(20)   NOT NULL DEFAULT 'issued' CHECK (status IN ('issued', 'partially_paid', 'paid', 'void')),
+    issued_at    TIMESTAMP     NOT NULL DEFAULT NOW(),
+    due_at       TIMESTAMP     NOT NULL,
+    UNIQUE (account_id, period_start),
+    UNIQUE (tenant_id, number)
+);
+
+-- One line per billed booking; the unique booking_id keeps a booking from
+-- being invoiced twice.
+CREATE TABLE IF NOT EXISTS corporate_invoice_lines (
+    id          SERIAL        PRIMARY KEY,
+    invoice_id  INT           NOT NULL REFERENCES corporate_invoices (id) ON DELETE CASCADE,
+    booking_id  VARCHAR(32)   UNIQUE REFERENCES booking (id) ON DELETE SET NULL,
+    trip_date   TIMESTAMP     NOT NULL,
+    description TEXT          NOT NULL DEFAULT '',
+    booker      VARCHAR(100)  NOT NULL DEFAULT '',
+    cost_centre VARCHAR(60)   NOT NULL DEFAULT '',
+    distance_km NUMERIC(8,1),
+    amount      NUMERIC(14,2) NOT NULL,
+    tenant_id   BIGINT        NOT NULL REFERENCES tenants (id)
+);
+
+CREATE TABLE IF NOT EXISTS corporate_invoice_payments (
+    id          SERIAL        PRIMARY KEY,
+    invoice_id  INT           NOT NULL REFERENCES corporate_invoices (id) ON DELETE CASCADE,
+    amount      NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+    method      VARCHAR(30)   NOT NULL DEFAULT 'transfer',
+    reference   VARCHAR(100)  NOT NULL DEFAULT '',
+    paid_at     TIMESTAMP     NOT NULL DEFAULT NOW(),
+    recorded_by INT,
+    tenant_id   BIGINT        NOT NULL REFERENCES tenants (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_corporate_invoices_tenant ON corporate_invoices (tenant_id, issued_at);
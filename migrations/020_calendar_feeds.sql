@@ -0,0 +1,13 @@
+-- A calendar feed publishes a driver's or vehicle's schedule at a secret URL
+-- that calendar apps subscribe to. Each subject has at most one feed; issuing
+-- a new one replaces the token, so leaked URLs are revoked by rotating.
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    token      VARCHAR(64) PRIMARY KEY,
+    tenant_id  BIGINT      NOT NULL REFERENCES tenants (id),
+    kind       VARCHAR(10) NOT NULL CHECK (kind IN ('driver', 'vehicle')),
+    subject_id INT         NOT NULL,
+    created_at TIMESTAMP   NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, kind, subject_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_assignments_driver ON driver_assignments (tenant_id, driver_name);
@@ -0,0 +1,41 @@
+-- Payments point at their booking by code. The old integer booking_id never
+-- matched a booking, so values that do not name an existing booking are
+-- cleared rather than guessed.
+ALTER TABLE payment ALTER COLUMN booking_id DROP NOT NULL;
+ALTER TABLE payment ALTER COLUMN booking_id TYPE VARCHAR(32) USING booking_id::TEXT;
+UPDATE payment p SET booking_id = NULL
+WHERE booking_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM booking b WHERE b.id = p.booking_id AND b.tenant_id = p.tenant_id);
+ALTER TABLE payment DROP CONSTRAINT IF EXISTS payment_booking_id_fkey;
+ALTER TABLE payment ADD CONSTRAINT payment_booking_id_fkey
+    FOREIGN KEY (booking_id) REFERENCES booking (id) ON DELETE SET NULL;
+
+-- Payment status follows a fixed lifecycle. Free-text statuses are mapped
+-- onto it; anything unrecognised is treated as still pending.
+UPDATE payment SET status = CASE LOWER(TRIM(status))
+    WHEN 'pending'            THEN 'pending'
+    WHEN 'unpaid'             THEN 'pending'
+    WHEN 'authorised'         THEN 'authorised'
+    WHEN 'authorized'         THEN 'authorised'
+    WHEN 'paid'               THEN 'paid'
+    WHEN 'lunas'              THEN 'paid'
+    WHEN 'success'            THEN 'paid'
+    WHEN 'settled'            THEN 'paid'
+    WHEN 'partially_refunded' THEN 'partially_refunded'
+    WHEN 'refunded'           THEN 'refunded'
+    WHEN 'failed'             THEN 'failed'
+    WHEN 'expired'            THEN 'expired'
+    ELSE 'pending'
+END;
+
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS refunded_amount NUMERIC(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS expires_at      TIMESTAMP;
+
+UPDATE payment SET refunded_amount = amount WHERE status = 'refunded' AND method <> 'refund';
+
+ALTER TABLE payment DROP CONSTRAINT IF EXISTS payment_status_check;
+ALTER TABLE payment ADD CONSTRAINT payment_status_check
+    CHECK (status IN ('pending', 'authorised', 'paid', 'partially_refunded', 'refunded', 'failed', 'expired'));
+
+CREATE INDEX IF NOT EXISTS idx_payment_booking ON payment (tenant_id, booking_id) WHERE booking_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payment_expiry  ON payment (expires_at) WHERE status IN ('pending', 'authorised');
@@ -0,0 +1,17 @@
+-- Payments collected through a gateway carry the order id the gateway knows
+-- them by and what the customer pays with: a virtual account number, a QRIS
+-- string or an e-wallet link.
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS provider_ref VARCHAR(64);
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS pay_code     TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_provider_ref ON payment (provider_ref) WHERE provider_ref IS NOT NULL;
+
+-- Every gateway callback that was accepted, so a captured callback replayed
+-- within the signature window is not applied twice.
+CREATE TABLE IF NOT EXISTS payment_callbacks (
+    event_id     VARCHAR(128) PRIMARY KEY,
+    provider_ref VARCHAR(64)  NOT NULL,
+    received_at  TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_callbacks_received ON payment_callbacks (received_at);
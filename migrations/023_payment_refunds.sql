@@ -0,0 +1,23 @@
+-- Refunds are recorded against the payment they give money back from. The
+-- refunded amount of a payment is the sum of its refunds that did not fail.
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    id          SERIAL        PRIMARY KEY,
+    payment_id  INT           NOT NULL REFERENCES payment (payment_id) ON DELETE CASCADE,
+    amount      NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    reason      TEXT          NOT NULL,
+    status      VARCHAR(20)   NOT NULL DEFAULT 'succeeded' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    gateway     BOOLEAN       NOT NULL DEFAULT FALSE,
+    approved_by INT,
+    created_at  TIMESTAMP     NOT NULL DEFAULT NOW(),
+    tenant_id   BIGINT        NOT NULL REFERENCES tenants (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_payment ON payment_refunds (tenant_id, payment_id);
+
+-- Payments refunded before refunds were recorded get one refund covering
+-- what they gave back.
+INSERT INTO payment_refunds (payment_id, amount, reason, tenant_id)
+SELECT payment_id, refunded_amount, 'refunded before refunds were recorded', tenant_id
+FROM payment
+WHERE refunded_amount > 0
+  AND NOT EXISTS (SELECT 1 FROM payment_refunds r WHERE r.payment_id = payment.payment_id);
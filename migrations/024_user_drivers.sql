@@ -0,0 +1,3 @@
+-- A driver account acts for the driver it is linked to: it answers that
+-- driver's dispatch offers and reports that driver's progress on bookings.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS driver_id INT REFERENCES drivers (id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+-- A quote keeps the stops it was priced for, so a booking made from it cannot
+-- add, drop or move them at the quoted price.
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS quoted_stops JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,61 @@
+-- A cancelled booking is refunded through refunds of the payments the
+-- customer made rather than a refund payment of its own.
+ALTER TABLE payment_refunds ADD COLUMN IF NOT EXISTS cancellation_id INT REFERENCES booking_cancellations (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_cancellation ON payment_refunds (cancellation_id) WHERE cancellation_id IS NOT NULL;
+
+-- Refund payments recorded by earlier cancellations are spread over the
+-- captured payments of their booking, oldest first, and then removed. A
+-- refund payment that cannot be spread in full stops the migration rather
+-- than losing what was refunded.
+DO $$
+DECLARE
+    r         RECORD;
+    p         RECORD;
+    remaining NUMERIC(12,2);
+    part      NUMERIC(12,2);
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_schema = current_schema() AND table_name = 'booking_cancellations' AND column_name = 'refund_payment_id') THEN
+        RETURN;
+    END IF;
+
+    FOR r IN
+        SELECT rp.payment_id, rp.booking_id, rp.amount, rp.tenant_id, c.id AS cancellation_id, c.cancelled_by
+        FROM payment rp
+        LEFT JOIN booking_cancellations c ON c.refund_payment_id = rp.payment_id
+        WHERE rp.method = 'refund' AND rp.status = 'refunded' AND rp.deleted_at IS NULL
+        ORDER BY rp.payment_id
+    LOOP
+        remaining := r.amount;
+        FOR p IN
+            SELECT payment_id, amount - refunded_amount AS available
+            FROM payment
+            WHERE booking_id = r.booking_id AND tenant_id = r.tenant_id AND deleted_at IS NULL
+              AND method <> 'refund' AND status IN ('paid', 'partially_refunded')
+            ORDER BY payment_id
+        LOOP
+            EXIT WHEN remaining <= 0;
+            part := LEAST(remaining, p.available);
+            CONTINUE WHEN part <= 0;
+
+            UPDATE payment SET refunded_amount = refunded_amount + part,
+                status = CASE WHEN refunded_amount + part >= amount THEN 'refunded' ELSE 'partially_refunded' END,
+                version = version + 1
+            WHERE payment_id = p.payment_id;
+            INSERT INTO payment_refunds (payment_id, amount, reason, status, approved_by, cancellation_id, tenant_id)
+            VALUES (p.payment_id, part, 'cancellation of booking ' || r.booking_id, 'succeeded', r.cancelled_by, r.cancellation_id, r.tenant_id);
+            remaining := remaining - part;
+        END LOOP;
+
+        IF remaining > 0 THEN
+            RAISE EXCEPTION 'refund payment % of booking % exceeds its captured payments by %',
+                r.payment_id, r.booking_id, remaining;
+        END IF;
+        DELETE FROM payment WHERE payment_id = r.payment_id;
+    END LOOP;
+
+    -- Deleted or unsettled refund payments were never counted as refunds and
+    -- are left in place.
+    ALTER TABLE booking_cancellations DROP COLUMN refund_payment_id;
+END $$;
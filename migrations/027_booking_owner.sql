@@ -0,0 +1,2 @@
+-- The user who made a booking may cancel it without being an admin.
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS created_by BIGINT;
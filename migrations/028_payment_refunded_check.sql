@@ -0,0 +1,3 @@
+-- Refunds never give back more than a payment captured.
+ALTER TABLE payment DROP CONSTRAINT IF EXISTS payment_refunded_amount_check;
+ALTER TABLE payment ADD CONSTRAINT payment_refunded_amount_check CHECK (refunded_amount <= amount);
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+const (
+	BlockBooking     = "booking"
+	BlockLeave       = "leave"
+	BlockMaintenance = "maintenance"
+)
+
+type DriverLeave struct {
+	ID        int       `json:"id"`
+	DriverID  int       `json:"driver_id"`
+	StartAt   time.Time `json:"start_at" binding:"required"`
+	EndAt     time.Time `json:"end_at" binding:"required"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BusyBlock is a window in which a driver or vehicle cannot take a booking.
+// Ref identifies the booking, leave or maintenance record behind it.
+type BusyBlock struct {
+	Kind    string    `json:"kind"`
+	Ref     string    `json:"ref"`
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+type TimeSlot struct {
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+}
+
+type DriverAvailability struct {
+	DriverID int         `json:"driver_id"`
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	Free     []TimeSlot  `json:"free"`
+	Busy     []BusyBlock `json:"busy"`
+}
@@ -5,20 +5,44 @@ import (
 )
 
 type Booking struct {
-	ID             string    `json:"id"`
-	Customer       string    `json:"customer"`
-	Driver         string    `json:"driver"`
-	Place          string    `json:"place"`
-	Date           string    `json:"date"`
-	Price          string    `json:"price"`
-	Status         string    `json:"status"`
-	Payment        string    `json:"payment"`
-	PhoneNumber    *string   `json:"phone_number"`
-	PickupLocation *string   `json:"pickup_location"`
-	DropLocation   *string   `json:"drop_location"`
-	PickupTime     *string   `json:"pickup_time"`
-	Amount         *float64  `json:"amount"`
-	Notes          *string   `json:"notes"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             string        `json:"id"`
+	Customer       string        `json:"customer"`
+	CustomerID     *int          `json:"customer_id"`
+	Driver         string        `json:"driver"`
+	DriverID       *int          `json:"driver_id"`
+	VehicleID      *int          `json:"vehicle_id"`
+	Place          string        `json:"place"`
+	Date           string        `json:"date"`
+	StartAt        *time.Time    `json:"start_at"`
+	EndAt          *time.Time    `json:"end_at"`
+	Price          string        `json:"price"`
+	Status         string        `json:"status"`
+	Payment        string        `json:"payment"`
+	PhoneNumber    *string       `json:"phone_number"`
+	PickupLocation *string       `json:"pickup_location"`
+	PickupLat      *float64      `json:"pickup_lat"`
+	PickupLng      *float64      `json:"pickup_lng"`
+	DropLocation   *string       `json:"drop_location"`
+	DropLat        *float64      `json:"drop_lat"`
+	DropLng        *float64      `json:"drop_lng"`
+	DistanceKM     *float64      `json:"distance_km"`
+	Stops          []BookingStop `json:"stops,omitempty" binding:"max=10,dive"`
+	PickupTime     *string       `json:"pickup_time"`
+	CarTypeID      *string       `json:"car_type_id"`
+	QuoteID        *int          `json:"quote_id"`
+	PromoCode      *string       `json:"promo_code"`
+	Discount       *float64      `json:"discount"`
+	Amount         *float64      `json:"amount"`
+	// CorporateAccountID charges the booking to a corporate account instead
+	// of collecting payment per trip.
+	CorporateAccountID *int       `json:"corporate_account_id"`
+	CostCentre         *string    `json:"cost_centre"`
+	Notes              *string    `json:"notes"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	Version            int        `json:"version"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
+	// CreatedBy is the user who made the booking; besides admins, only they
+	// may cancel it.
+	CreatedBy *int64 `json:"created_by"`
 }
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	BookingPending        = "Pending"
+	BookingConfirmed      = "Confirmed"
+	BookingDriverAssigned = "DriverAssigned"
+	BookingOnTrip         = "OnTrip"
+	BookingCompleted      = "Completed"
+	BookingCancelled      = "Cancelled"
+	BookingNoShow         = "NoShow"
+)
+
+var BookingStatuses = []string{
+	BookingPending,
+	BookingConfirmed,
+	BookingDriverAssigned,
+	BookingOnTrip,
+	BookingCompleted,
+	BookingCancelled,
+	BookingNoShow,
+}
+
+type BookingStatusChange struct {
+	ID         int64     `json:"id"`
+	BookingID  string    `json:"booking_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  int64     `json:"changed_by"`
+	Reason     string    `json:"reason,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+
+	// Cancellation, when set, is settled in the same transaction as the
+	// status change.
+	Cancellation *BookingCancellation `json:"-"`
+	// Notifications are queued in the outbox with the status change.
+	Notifications []Notification `json:"-"`
+	// Trip is opened (when new) or closed with the status change.
+	Trip *VehicleTrip `json:"-"`
+	// Offer, when set, is answered with the status change, which fails if
+	// the offer is no longer pending.
+	Offer *DispatchOffer `json:"-"`
+}
+
+type BookingTransition struct {
+	To     string `json:"to" binding:"required"`
+	Reason string `json:"reason"`
+
+	// Actuals recorded on the trip when the booking completes. Duration and
+	// fare default to the time on trip and the booking amount.
+	DistanceKM      *int     `json:"distance_km" binding:"omitempty,min=0"`
+	DurationMinutes *int     `json:"duration_minutes" binding:"omitempty,min=0"`
+	Fare            *float64 `json:"fare" binding:"omitempty,min=0"`
+}
+
+// ParseBookingStatus accepts any casing and spacing of a known status, so
+// "driver assigned", "DRIVER_ASSIGNED" and "DriverAssigned" are the same.
+func ParseBookingStatus(s string) (string, bool) {
+	key := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+	for _, status := range BookingStatuses {
+		if strings.ToLower(status) == key {
+			return status, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,19 @@
+package model_test
+
+import (
+	"auth-service/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBookingStatus(t *testing.T) {
+	for _, in := range []string{"DriverAssigned", "driver assigned", "DRIVER_ASSIGNED", " driver-assigned "} {
+		status, ok := model.ParseBookingStatus(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, model.BookingDriverAssigned, status)
+	}
+
+	_, ok := model.ParseBookingStatus("lost")
+	assert.False(t, ok)
+}
@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Stop kinds. A pickup collects a passenger, a drop-off sets one down and a
+// waypoint is any other stop on the way.
+const (
+	StopPickup   = "pickup"
+	StopDropOff  = "drop_off"
+	StopWaypoint = "waypoint"
+)
+
+const (
+	StopPending  = "pending"
+	StopArrived  = "arrived"
+	StopDeparted = "departed"
+	StopSkipped  = "skipped"
+)
+
+const MaxBookingStops = 10
+
+// BookingStop is one of the ordered stops a booking makes between its pickup
+// and drop-off locations. LegKM is the distance from the point before it.
+type BookingStop struct {
+	BookingID    string     `json:"-"`
+	Position     int        `json:"position"`
+	Kind         string     `json:"kind" binding:"omitempty,oneof=pickup drop_off waypoint"`
+	Address      string     `json:"address" binding:"required"`
+	Lat          *float64   `json:"lat"`
+	Lng          *float64   `json:"lng"`
+	ContactName  string     `json:"contact_name"`
+	ContactPhone string     `json:"contact_phone"`
+	WaitMinutes  int        `json:"wait_minutes" binding:"gte=0,lte=240"`
+	LegKM        *float64   `json:"leg_km"`
+	Status       string     `json:"status"`
+	ArrivedAt    *time.Time `json:"arrived_at"`
+	DepartedAt   *time.Time `json:"departed_at"`
+}
+
+// StopUpdate records the driver reaching, leaving or skipping a stop.
+type StopUpdate struct {
+	Status string `json:"status" binding:"required,oneof=arrived departed skipped"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Calendar feed subjects.
+const (
+	CalendarDriver  = "driver"
+	CalendarVehicle = "vehicle"
+)
+
+// CalendarFeed is the secret subscription URL of a driver's or vehicle's
+// schedule. URL is filled in by the handler from the request.
+type CalendarFeed struct {
+	Token     string    `json:"token"`
+	Kind      string    `json:"kind"`
+	SubjectID int       `json:"subject_id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	TenantID  int64     `json:"-"`
+}
+
+// CalendarSchedule is what a feed publishes: the subject's upcoming bookings,
+// its driver assignments and the maintenance days of its vehicles.
+type CalendarSchedule struct {
+	Name        string
+	Bookings    []Booking
+	Assignments []DriverAssignment
+	Maintenance []VehicleMaintenance
+}
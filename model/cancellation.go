@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+const PaymentMethodCancellationFee = "cancellation_fee"
+
+// CancellationPolicy prices cancelling a booking. Cancelling at least
+// FreeHours before pickup is free; later cancellations pay LateFeePercent of
+// the fare, but never less than MinimumLateFee or more than the fare. A no-show
+// pays NoShowFeePercent of the fare.
+type CancellationPolicy struct {
+	FreeHours        int       `json:"free_hours" binding:"gte=0"`
+	LateFeePercent   float64   `json:"late_fee_percent" binding:"gte=0,lte=100"`
+	MinimumLateFee   float64   `json:"minimum_late_fee" binding:"gte=0"`
+	NoShowFeePercent float64   `json:"no_show_fee_percent" binding:"gte=0,lte=100"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// DefaultCancellationPolicy applies to tenants that have not saved their own.
+func DefaultCancellationPolicy() CancellationPolicy {
+	return CancellationPolicy{FreeHours: 24, LateFeePercent: 50, NoShowFeePercent: 100}
+}
+
+// BookingCancellation settles a cancelled or no-show booking. A fee the
+// customer has already paid for is kept from the refund, which is given back
+// through Refunds of the payments they made; a fee on an unpaid booking is
+// charged as a pending payment.
+type BookingCancellation struct {
+	ID           int             `json:"id"`
+	BookingID    string          `json:"booking_id"`
+	Status       string          `json:"status"`
+	HoursBefore  *float64        `json:"hours_before"`
+	Fare         float64         `json:"fare"`
+	Paid         float64         `json:"paid"`
+	Fee          float64         `json:"fee"`
+	Refund       float64         `json:"refund"`
+	FeePaymentID *int            `json:"fee_payment_id"`
+	Refunds      []PaymentRefund `json:"refunds"`
+	Reason       string          `json:"reason,omitempty"`
+	CancelledBy  int64           `json:"cancelled_by"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type BookingCancel struct {
+	Reason string `json:"reason"`
+}
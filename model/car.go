@@ -18,6 +18,8 @@ type Car struct {
 	LastMaintenanceDate *time.Time `json:"last_maintenance_date"`
 	CurrentKM           int        `json:"current_km"`
 	Status              string     `json:"status"`
+	Version             int        `json:"version"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"`
 
 	Maintenance []VehicleMaintenance `gorm:"foreignKey:VehicleID"`
 	Assignments []DriverAssignment   `gorm:"foreignKey:VehicleID"`
@@ -0,0 +1,80 @@
+package model
+
+import "time"
+
+const (
+	InvoiceIssued        = "issued"
+	InvoicePartiallyPaid = "partially_paid"
+	InvoicePaid          = "paid"
+	InvoiceVoid          = "void"
+)
+
+// CorporateAccount lets a business book on account. Only the customers listed
+// in BookerIDs may charge bookings to it, each against one of its
+// CostCentres when any are set. A nil CreditLimit means unlimited credit.
+type CorporateAccount struct {
+	ID               int       `json:"id"`
+	Name             string    `json:"name" binding:"required"`
+	BillingEmail     string    `json:"billing_email" binding:"omitempty,email"`
+	BillingAddress   string    `json:"billing_address"`
+	TaxID            string    `json:"tax_id"`
+	BookerIDs        []int     `json:"booker_ids"`
+	CostCentres      []string  `json:"cost_centres"`
+	CreditLimit      *float64  `json:"credit_limit" binding:"omitempty,gte=0"`
+	PaymentTermsDays int       `json:"payment_terms_days" binding:"gte=0"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Version          int       `json:"version"`
+	TenantID         int64     `json:"-"`
+}
+
+// AccountBalance is what an account owes: Unbilled covers bookings not yet
+// invoiced and Outstanding the unpaid part of its invoices. Available is nil
+// for accounts without a credit limit.
+type AccountBalance struct {
+	AccountID   int      `json:"account_id"`
+	Unbilled    float64  `json:"unbilled"`
+	Outstanding float64  `json:"outstanding"`
+	CreditLimit *float64 `json:"credit_limit"`
+	Available   *float64 `json:"available"`
+}
+
+type CorporateInvoice struct {
+	ID          int              `json:"id"`
+	AccountID   int              `json:"account_id"`
+	AccountName string           `json:"account_name"`
+	Number      string           `json:"number"`
+	PeriodStart time.Time        `json:"period_start"`
+	PeriodEnd   time.Time        `json:"period_end"`
+	Total       float64          `json:"total"`
+	AmountPaid  float64          `json:"amount_paid"`
+	Status      string           `json:"status"`
+	IssuedAt    time.Time        `json:"issued_at"`
+	DueAt       time.Time        `json:"due_at"`
+	Lines       []InvoiceLine    `json:"lines,omitempty"`
+	Payments    []InvoicePayment `json:"payments,omitempty"`
+}
+
+// InvoiceLine bills one completed booking, priced at its trip's fare.
+type InvoiceLine struct {
+	ID          int       `json:"id"`
+	InvoiceID   int       `json:"-"`
+	BookingID   *string   `json:"booking_id"`
+	TripDate    time.Time `json:"trip_date"`
+	Description string    `json:"description"`
+	Booker      string    `json:"booker"`
+	CostCentre  string    `json:"cost_centre"`
+	DistanceKM  *float64  `json:"distance_km"`
+	Amount      float64   `json:"amount"`
+}
+
+type InvoicePayment struct {
+	ID         int       `json:"id"`
+	InvoiceID  int       `json:"invoice_id"`
+	Amount     float64   `json:"amount" binding:"gt=0"`
+	Method     string    `json:"method"`
+	Reference  string    `json:"reference"`
+	PaidAt     time.Time `json:"paid_at"`
+	RecordedBy *int      `json:"recorded_by"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type Customer struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name" binding:"required"`
+	Phones      []string  `json:"phones"`
+	Emails      []string  `json:"emails"`
+	Addresses   []string  `json:"addresses"`
+	Notes       string    `json:"notes"`
+	Blacklisted bool      `json:"blacklisted"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int       `json:"version"`
+}
+
+type CustomerDetail struct {
+	Customer
+	Bookings []Booking     `json:"bookings"`
+	Payments []Payment     `json:"payments"`
+	Trips    []TripHistory `json:"trips"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+const (
+	OfferPending  = "Pending"
+	OfferAccepted = "Accepted"
+	OfferDeclined = "Declined"
+	OfferExpired  = "Expired"
+)
+
+type DriverLocation struct {
+	DriverID  int       `json:"driver_id"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DispatchCandidate is an active driver considered for a booking. Latitude and
+// Longitude are nil when the driver has never reported a location. DistanceKM
+// is by road when the route to the pickup is known, ETAMinutes only then.
+type DispatchCandidate struct {
+	DriverID   int      `json:"driver_id"`
+	Name       string   `json:"name"`
+	CarTypeID  string   `json:"car_type_id"`
+	Latitude   *float64 `json:"latitude,omitempty"`
+	Longitude  *float64 `json:"longitude,omitempty"`
+	DistanceKM *float64 `json:"distance_km"`
+	ETAMinutes *float64 `json:"eta_minutes,omitempty"`
+	Workload   int      `json:"workload"`
+	Rating     float64  `json:"rating"`
+	Score      float64  `json:"score"`
+}
+
+type DispatchOffer struct {
+	ID          int        `json:"id"`
+	BookingID   string     `json:"booking_id"`
+	DriverID    int        `json:"driver_id"`
+	Score       float64    `json:"score"`
+	Status      string     `json:"status"`
+	OfferedAt   time.Time  `json:"offered_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	TenantID    int64      `json:"-"`
+}
+
+type DispatchRequest struct {
+	Auto bool `json:"auto"`
+}
+
+// DispatchResult carries the assigned booking when dispatch was automatic, or
+// the offer sent to the best candidate otherwise.
+type DispatchResult struct {
+	Booking *Booking       `json:"booking,omitempty"`
+	Offer   *DispatchOffer `json:"offer,omitempty"`
+}
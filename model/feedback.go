@@ -0,0 +1,92 @@
+package model
+
+import "time"
+
+// Feedback sources: staff record feedback taken over the phone, customers
+// submit it through the signed link sent when their trip completes.
+const (
+	FeedbackFromStaff = "staff"
+	FeedbackFromLink  = "link"
+)
+
+// Rating tags a customer can attach to their feedback.
+const (
+	TagPunctual      = "punctual"
+	TagFriendly      = "friendly"
+	TagSafeDriving   = "safe_driving"
+	TagCleanCar      = "clean_car"
+	TagKnowsRoute    = "knows_route"
+	TagLate          = "late"
+	TagRude          = "rude"
+	TagUnsafeDriving = "unsafe_driving"
+	TagDirtyCar      = "dirty_car"
+	TagWrongRoute    = "wrong_route"
+)
+
+var FeedbackTags = []string{
+	TagPunctual, TagFriendly, TagSafeDriving, TagCleanCar, TagKnowsRoute,
+	TagLate, TagRude, TagUnsafeDriving, TagDirtyCar, TagWrongRoute,
+}
+
+func ValidFeedbackTag(tag string) bool {
+	for _, t := range FeedbackTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+type FeedbackRequest struct {
+	Rating  int      `json:"rating" binding:"required,min=1,max=5"`
+	Tags    []string `json:"tags"`
+	Comment string   `json:"comment" binding:"max=2000"`
+}
+
+// TripFeedback is the one rating a completed trip can receive.
+type TripFeedback struct {
+	ID         int       `json:"id"`
+	TripID     uint      `json:"trip_id"`
+	BookingID  *string   `json:"booking_id"`
+	DriverID   *int      `json:"driver_id"`
+	CustomerID *int      `json:"customer_id"`
+	Rating     int       `json:"rating"`
+	Tags       []string  `json:"tags"`
+	Comment    string    `json:"comment"`
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DriverRating is a driver's average over all their feedback and over the
+// most recent ratings only.
+type DriverRating struct {
+	DriverID       int     `json:"driver_id"`
+	Average        float64 `json:"average"`
+	Count          int     `json:"count"`
+	RollingAverage float64 `json:"rolling_average"`
+}
+
+// DriverRatingAlert is raised when a driver's rolling average drops below the
+// threshold, and stays open until acknowledged; a driver has at most one open.
+type DriverRatingAlert struct {
+	ID             int        `json:"id"`
+	DriverID       int        `json:"driver_id"`
+	DriverName     string     `json:"driver_name"`
+	RollingAverage float64    `json:"rolling_average"`
+	Threshold      float64    `json:"threshold"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	AcknowledgedBy *int64     `json:"acknowledged_by"`
+}
+
+// FeedbackForm is what a customer opening a feedback link sees: the trip to
+// rate, the tags to choose from, and their feedback once submitted.
+type FeedbackForm struct {
+	BookingID   string        `json:"booking_id"`
+	TripDate    time.Time     `json:"trip_date"`
+	Origin      string        `json:"origin"`
+	Destination string        `json:"destination"`
+	Driver      string        `json:"driver"`
+	Tags        []string      `json:"tags"`
+	Feedback    *TripFeedback `json:"feedback"`
+}
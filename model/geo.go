@@ -0,0 +1,27 @@
+package model
+
+// GeoPoint is a WGS84 coordinate, named after the place it was geocoded from.
+type GeoPoint struct {
+	Name string  `json:"name,omitempty"`
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+}
+
+// GeoBox bounds a search by latitude and longitude, edges included.
+type GeoBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+func (b GeoBox) Contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// NearbyBooking is a booking found by a radius search, with how far its
+// pickup point is from the search centre.
+type NearbyBooking struct {
+	Booking
+	AwayKM float64 `json:"away_km"`
+}
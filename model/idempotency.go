@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type IdempotencyKey struct {
+	Key          string    `json:"key"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Fingerprint  string    `json:"fingerprint"`
+	StatusCode   int       `json:"status_code"`
+	ResponseBody []byte    `json:"response_body"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+const (
+	ChannelEmail    = "email"
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+const (
+	LocaleID = "id"
+	LocaleEN = "en"
+)
+
+// Notification events. TripAssigned goes to the driver, DriverRatingLow to the
+// operations team, the rest to the customer.
+const (
+	NotifyBookingConfirmed = "booking_confirmed"
+	NotifyDriverAssigned   = "driver_assigned"
+	NotifyTripAssigned     = "trip_assigned"
+	NotifyPaymentReceived  = "payment_received"
+	NotifyFeedbackRequest  = "feedback_request"
+	NotifyDriverRatingLow  = "driver_rating_low"
+)
+
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+)
+
+// Notification is one rendered message in the outbox. The dispatcher retries
+// it until it is sent or runs out of attempts.
+type Notification struct {
+	ID            int        `json:"id"`
+	Event         string     `json:"event"`
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Locale        string     `json:"locale"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	BookingID     *string    `json:"booking_id"`
+	CustomerID    *int       `json:"customer_id"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	TenantID      int64      `json:"-"`
+}
+
+// NotificationPreference is how a customer wants to be reached. An empty
+// Channels list means email when the customer has one, SMS otherwise.
+type NotificationPreference struct {
+	CustomerID int       `json:"customer_id"`
+	Locale     string    `json:"locale" binding:"omitempty,oneof=id en"`
+	Channels   []string  `json:"channels" binding:"dive,oneof=email sms whatsapp"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
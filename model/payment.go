@@ -1,18 +1,218 @@
 package model
 
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// A payment starts pending, may be authorised before it is captured as paid,
+// and can then be refunded in part or in full. Pending and authorised payments
+// fail or expire instead of being captured.
+const (
+	PaymentPending           = "pending"
+	PaymentAuthorised        = "authorised"
+	PaymentPaid              = "paid"
+	PaymentPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded    = "refunded"
+	PaymentFailed            = "failed"
+	PaymentExpired           = "expired"
+)
+
+var PaymentStatuses = []string{
+	PaymentPending,
+	PaymentAuthorised,
+	PaymentPaid,
+	PaymentPartiallyRefunded,
+	PaymentStatusRefunded,
+	PaymentFailed,
+	PaymentExpired,
+}
+
+// Methods collected through a payment gateway. The customer pays into a
+// virtual account, scans a QRIS code or approves the payment in an e-wallet.
+const (
+	PaymentMethodVirtualAccount = "virtual_account"
+	PaymentMethodQRIS           = "qris"
+	PaymentMethodEWallet        = "ewallet"
+)
+
+// The payment status of a booking, derived from its payments. They are title
+// cased like the free-text values bookings carried before.
+const (
+	BookingUnpaid            = "Unpaid"
+	BookingPaymentPending    = "Pending"
+	BookingAuthorised        = "Authorised"
+	BookingPartiallyPaid     = "Partially Paid"
+	BookingPaid              = "Paid"
+	BookingPartiallyRefunded = "Partially Refunded"
+	BookingRefunded          = "Refunded"
+)
+
 type Payment struct {
-	PaymentID   int     `json:"payment_id"`
-	BookingID   int     `json:"booking_id"`
-	Customer    string  `json:"customer"`
-	Driver      string  `json:"driver"`
-	Amount      float64 `json:"amount"`
-	Method      string  `json:"method"`
-	Status      string  `json:"status"`
-	PaymentDate string  `json:"payment_date"`
+	PaymentID      int        `json:"payment_id"`
+	BookingID      *string    `json:"booking_id"`
+	Customer       string     `json:"customer"`
+	CustomerID     *int       `json:"customer_id"`
+	Driver         string     `json:"driver"`
+	Amount         float64    `json:"amount"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	Method         string     `json:"method"`
+	Status         string     `json:"status"`
+	PaymentDate    string     `json:"payment_date"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ProviderRef    *string    `json:"provider_ref,omitempty"`
+	PayCode        string     `json:"pay_code,omitempty"`
+	Version        int        `json:"version"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	TenantID       int64      `json:"-"`
+}
+
+// Captured reports whether the money of p was collected, whatever has been
+// refunded of it since.
+func (p Payment) Captured() bool {
+	switch p.Status {
+	case PaymentPaid, PaymentPartiallyRefunded, PaymentStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// ChargeRequest asks a gateway to collect Amount under the order id Ref.
+type ChargeRequest struct {
+	Ref       string
+	Amount    float64
+	Customer  string
+	ExpiresAt *time.Time
+}
+
+// Charge is a payment as the gateway sees it, with its status mapped onto the
+// payment lifecycle. PayCode is the virtual account number, QRIS string or
+// e-wallet link the customer pays with.
+type Charge struct {
+	Ref            string
+	Status         string
+	Amount         float64
+	RefundedAmount float64
+	PayCode        string
+	ExpiresAt      *time.Time
+}
+
+// PaymentCallback records a gateway callback that has been accepted.
+type PaymentCallback struct {
+	EventID     string
+	ProviderRef string
+	ReceivedAt  time.Time
 }
 
+// A refund made through a gateway is pending until the gateway has carried
+// it out, and fails when the gateway refuses it. Other refunds succeed as
+// soon as they are recorded.
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+	RefundFailed    = "failed"
+)
+
+// PaymentRefund gives back part or all of a captured payment. ApprovedBy is
+// the user who approved it; refunds reported by the gateway have none. A
+// refund settling a cancelled booking names its CancellationID.
+type PaymentRefund struct {
+	ID             int       `json:"id"`
+	PaymentID      int       `json:"payment_id"`
+	Amount         float64   `json:"amount"`
+	Reason         string    `json:"reason"`
+	Status         string    `json:"status"`
+	Gateway        bool      `json:"gateway"`
+	ApprovedBy     *int64    `json:"approved_by"`
+	CancellationID *int      `json:"cancellation_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RefundRequest asks for Amount of a payment back. Without an amount,
+// whatever has not been refunded yet is.
+type RefundRequest struct {
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+// PaymentStats totals captured payments. TotalRefunded is what has been
+// given back of them, and NetPayment what was kept.
 type PaymentStats struct {
 	TotalPayment      int64 `json:"total_payment"`
+	TotalRefunded     int64 `json:"total_refunded"`
+	NetPayment        int64 `json:"net_payment"`
 	PendingPayment    int64 `json:"pending_payment"`
 	TotalTransactions int64 `json:"total_transactions"`
 }
+
+// BookingPayments is the payment account of one booking. Outstanding is what
+// the customer still owes; it is negative when they are owed money back.
+type BookingPayments struct {
+	BookingID     string    `json:"booking_id"`
+	PaymentStatus string    `json:"payment_status"`
+	AmountDue     float64   `json:"amount_due"`
+	Paid          float64   `json:"paid"`
+	Refunded      float64   `json:"refunded"`
+	Outstanding   float64   `json:"outstanding"`
+	Payments      []Payment `json:"payments"`
+}
+
+// ParsePaymentStatus accepts any casing of a known status, and the American
+// spelling of authorised.
+func ParsePaymentStatus(s string) (string, bool) {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "_")
+	if key == "authorized" {
+		key = PaymentAuthorised
+	}
+	for _, status := range PaymentStatuses {
+		if status == key {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// SummarisePayments totals the payments of a booking that owes due. Failed and
+// expired payments count for nothing.
+func SummarisePayments(due float64, payments []Payment) BookingPayments {
+	s := BookingPayments{AmountDue: due, Payments: payments}
+	var pending, authorised bool
+	for _, p := range payments {
+		switch {
+		case p.Captured():
+			s.Paid += p.Amount
+			s.Refunded += p.RefundedAmount
+		case p.Status == PaymentPending:
+			pending = true
+		case p.Status == PaymentAuthorised:
+			authorised = true
+		}
+	}
+	s.Paid = roundCents(s.Paid)
+	s.Refunded = roundCents(s.Refunded)
+	net := roundCents(s.Paid - s.Refunded)
+	s.Outstanding = roundCents(due - net)
+
+	switch {
+	case s.Refunded > 0 && net <= 0:
+		s.PaymentStatus = BookingRefunded
+	case s.Refunded > 0:
+		s.PaymentStatus = BookingPartiallyRefunded
+	case net > 0 && net >= due:
+		s.PaymentStatus = BookingPaid
+	case net > 0:
+		s.PaymentStatus = BookingPartiallyPaid
+	case authorised:
+		s.PaymentStatus = BookingAuthorised
+	case pending:
+		s.PaymentStatus = BookingPaymentPending
+	default:
+		s.PaymentStatus = BookingUnpaid
+	}
+	return s
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
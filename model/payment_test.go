@@ -0,0 +1,47 @@
+package model_test
+
+import (
+	"auth-service/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePaymentStatus(t *testing.T) {
+	for in, want := range map[string]string{"PAID": model.PaymentPaid, "authorized": model.PaymentAuthorised, "Partially Refunded": model.PaymentPartiallyRefunded} {
+		status, ok := model.ParsePaymentStatus(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, status)
+	}
+
+	_, ok := model.ParsePaymentStatus("lunas")
+	assert.False(t, ok)
+}
+
+func TestSummarisePayments(t *testing.T) {
+	paid := model.Payment{Amount: 60000, Status: model.PaymentPaid}
+	tests := []struct {
+		name        string
+		payments    []model.Payment
+		status      string
+		outstanding float64
+	}{
+		{"nothing yet", nil, model.BookingUnpaid, 100000},
+		{"failed and expired count for nothing", []model.Payment{{Amount: 100000, Status: model.PaymentFailed}, {Amount: 100000, Status: model.PaymentExpired}}, model.BookingUnpaid, 100000},
+		{"pending", []model.Payment{{Amount: 100000, Status: model.PaymentPending}}, model.BookingPaymentPending, 100000},
+		{"authorised", []model.Payment{{Amount: 100000, Status: model.PaymentAuthorised}}, model.BookingAuthorised, 100000},
+		{"deposit", []model.Payment{paid}, model.BookingPartiallyPaid, 40000},
+		{"paid in full", []model.Payment{paid, {Amount: 40000, Status: model.PaymentPaid}}, model.BookingPaid, 0},
+		{"overpaid", []model.Payment{paid, {Amount: 50000, Status: model.PaymentPaid}}, model.BookingPaid, -10000},
+		{"partial refund", []model.Payment{{Amount: 100000, RefundedAmount: 25000, Status: model.PaymentPartiallyRefunded}}, model.BookingPartiallyRefunded, 25000},
+		{"refunded", []model.Payment{{Amount: 100000, RefundedAmount: 100000, Status: model.PaymentStatusRefunded}}, model.BookingRefunded, 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := model.SummarisePayments(100000, tt.payments)
+			assert.Equal(t, tt.status, s.PaymentStatus)
+			assert.Equal(t, tt.outstanding, s.Outstanding)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import "time"
 
 type Pdf struct {
 	ID              string    `json:"trip_id"`
+	BookingCode     string    `json:"booking_code"`
 	CustomerName    string    `json:"customer_name"`
 	BookingDate     time.Time `json:"booking_date"`
 	DurationMinutes int       `json:"duration_minutes"`
@@ -12,12 +13,20 @@ type Pdf struct {
 	Destination     string    `json:"destination"`
 	DriverName      string    `json:"driver_name"`
 	VehicleName     string    `json:"vehicle_name"`
-	Amount          int       `json:"amount"`
+	Amount          float64   `json:"amount"`
 	Rating          float32   `json:"rating"`
 	Feedback        string    `json:"feedback"`
+	Stops           []string  `json:"stops"`
 }
 
 type PDFTemplateData struct {
 	Pdf
 	AmountFormatted string
 }
+
+type InvoiceTemplateData struct {
+	CorporateInvoice
+	BillingAddress string
+	TaxID          string
+	Outstanding    float64
+}
@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+const (
+	QuoteItemBaseFare    = "base_fare"
+	QuoteItemDistance    = "distance"
+	QuoteItemTime        = "time"
+	QuoteItemMinimumFare = "minimum_fare"
+	QuoteItemNight       = "night_surcharge"
+	QuoteItemAirport     = "airport_surcharge"
+	QuoteItemTolls       = "tolls"
+	QuoteItemStops       = "stops"
+	QuoteItemWaiting     = "waiting"
+	QuoteItemDiscount    = "discount"
+)
+
+// Tariff is the price list of one car type. The night surcharge is a percentage
+// of the fare and applies to pickups from NightStartHour until NightEndHour in
+// Western Indonesian Time, wrapping past midnight; equal hours disable it.
+type Tariff struct {
+	CarTypeID         string    `json:"car_type_id"`
+	BaseFare          float64   `json:"base_fare" binding:"gte=0"`
+	PerKM             float64   `json:"per_km" binding:"gte=0"`
+	PerMinute         float64   `json:"per_minute" binding:"gte=0"`
+	MinimumFare       float64   `json:"minimum_fare" binding:"gte=0"`
+	NightSurchargePct float64   `json:"night_surcharge_pct" binding:"gte=0"`
+	NightStartHour    int       `json:"night_start_hour" binding:"gte=0,lte=23"`
+	NightEndHour      int       `json:"night_end_hour" binding:"gte=0,lte=23"`
+	AirportSurcharge  float64   `json:"airport_surcharge" binding:"gte=0"`
+	PerStop           float64   `json:"per_stop" binding:"gte=0"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// QuoteRequest is priced on DistanceKM and DurationMinutes. When Pickup and
+// Drop are given, whichever of the two is missing is estimated from the road
+// route between them, through any Stops in order.
+type QuoteRequest struct {
+	CarTypeID       string      `json:"car_type_id" binding:"required"`
+	DistanceKM      float64     `json:"distance_km" binding:"required_without=Pickup,gte=0"`
+	DurationMinutes float64     `json:"duration_minutes" binding:"gte=0"`
+	Pickup          *GeoPoint   `json:"pickup"`
+	Drop            *GeoPoint   `json:"drop"`
+	Stops           []QuoteStop `json:"stops" binding:"max=10,dive"`
+	PickupAt        time.Time   `json:"pickup_at" binding:"required"`
+	Airport         bool        `json:"airport"`
+	Tolls           float64     `json:"tolls" binding:"gte=0"`
+	PromoCode       string      `json:"promo_code"`
+	CustomerID      *int        `json:"customer_id"`
+}
+
+// QuoteStop is an intermediate stop of a quoted trip. Each stop adds the
+// tariff's per-stop fee and its waiting time at the per-minute rate.
+type QuoteStop struct {
+	Point       *GeoPoint `json:"point"`
+	WaitMinutes int       `json:"wait_minutes" binding:"gte=0,lte=240"`
+}
+
+type QuoteItem struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+type Quote struct {
+	ID              int         `json:"id"`
+	CarTypeID       string      `json:"car_type_id"`
+	DistanceKM      float64     `json:"distance_km"`
+	DurationMinutes float64     `json:"duration_minutes"`
+	PickupAt        time.Time   `json:"pickup_at"`
+	Airport         bool        `json:"airport"`
+	Stops           int         `json:"stops"`
+	WaitMinutes     int         `json:"wait_minutes"`
+	QuotedStops     []QuoteStop `json:"quoted_stops,omitempty"`
+	Legs            []Route     `json:"legs,omitempty"`
+	Items           []QuoteItem `json:"items"`
+	PromoCode       *string     `json:"promo_code,omitempty"`
+	Discount        float64     `json:"discount"`
+	Total           float64     `json:"total"`
+	ExpiresAt       time.Time   `json:"expires_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// Promotion is a promo code. Percentage discounts may be capped by MaxDiscount;
+// nil limits and validity bounds mean unlimited, and an empty CarTypeIDs
+// applies to every car type.
+type Promotion struct {
+	ID             int        `json:"id"`
+	Code           string     `json:"code" binding:"required"`
+	Description    string     `json:"description"`
+	DiscountType   string     `json:"discount_type" binding:"required,oneof=percent fixed"`
+	DiscountValue  float64    `json:"discount_value" binding:"gt=0"`
+	MaxDiscount    *float64   `json:"max_discount" binding:"omitempty,gt=0"`
+	MinimumFare    float64    `json:"minimum_fare" binding:"gte=0"`
+	CarTypeIDs     []string   `json:"car_type_ids"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	MaxRedemptions *int       `json:"max_redemptions" binding:"omitempty,gt=0"`
+	MaxPerCustomer *int       `json:"max_per_customer" binding:"omitempty,gt=0"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Version        int        `json:"version"`
+}
+
+type PromotionRedemption struct {
+	ID          int       `json:"id"`
+	PromotionID int       `json:"promotion_id"`
+	BookingID   string    `json:"booking_id"`
+	CustomerID  *int      `json:"customer_id"`
+	Discount    float64   `json:"discount"`
+	RedeemedAt  time.Time `json:"redeemed_at"`
+}
+
+// PromotionReport sums the redemptions of one promotion; NetRevenue is what the
+// discounted bookings were charged after the discount.
+type PromotionReport struct {
+	PromotionID   int     `json:"promotion_id"`
+	Code          string  `json:"code"`
+	Redemptions   int     `json:"redemptions"`
+	Customers     int     `json:"customers"`
+	TotalDiscount float64 `json:"total_discount"`
+	NetRevenue    float64 `json:"net_revenue"`
+	Remaining     *int    `json:"remaining"`
+}
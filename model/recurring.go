@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+const (
+	RecurDaily  = "daily"
+	RecurWeekly = "weekly"
+
+	// DateLayout is how calendar days of a recurring series are written.
+	DateLayout = "2006-01-02"
+)
+
+// RecurringBooking is a booking template that the scheduler turns into one
+// booking per occurrence, DaysAhead days in advance (zero uses the server
+// default). StartAt is the first pickup; its clock time applies to every
+// occurrence. Weekdays take RRULE BYDAY codes and narrow both frequencies; a
+// weekly series without them repeats on the weekday of StartAt. Until is the
+// last day of the series and Exceptions are days it skips.
+type RecurringBooking struct {
+	ID              int       `json:"id"`
+	CustomerID      *int      `json:"customer_id"`
+	Customer        string    `json:"customer"`
+	PhoneNumber     *string   `json:"phone_number"`
+	Place           string    `json:"place"`
+	PickupLocation  *string   `json:"pickup_location"`
+	PickupLat       *float64  `json:"pickup_lat"`
+	PickupLng       *float64  `json:"pickup_lng"`
+	DropLocation    *string   `json:"drop_location"`
+	CarTypeID       *string   `json:"car_type_id"`
+	DriverID        *int      `json:"driver_id"`
+	VehicleID       *int      `json:"vehicle_id"`
+	Amount          *float64  `json:"amount" binding:"omitempty,gte=0"`
+	Payment         string    `json:"payment"`
+	Notes           *string   `json:"notes"`
+	Frequency       string    `json:"frequency" binding:"required,oneof=daily weekly"`
+	Interval        int       `json:"interval" binding:"gte=0"`
+	Weekdays        []string  `json:"weekdays" binding:"dive,oneof=MO TU WE TH FR SA SU"`
+	StartAt         time.Time `json:"start_at" binding:"required"`
+	DurationMinutes int       `json:"duration_minutes" binding:"gt=0"`
+	Until           *string   `json:"until" binding:"omitempty,datetime=2006-01-02"`
+	Exceptions      []string  `json:"exceptions" binding:"dive,datetime=2006-01-02"`
+	DaysAhead       int       `json:"days_ahead" binding:"gte=0,lte=90"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Version         int       `json:"version"`
+	TenantID        int64     `json:"-"`
+}
+
+// RecurringOccurrence records that one day of a series has been scheduled.
+// BookingID is nil when its booking could not be created, with Error saying
+// why. Detached occurrences were edited on their own and series edits leave
+// them alone.
+type RecurringOccurrence struct {
+	ID          int       `json:"id"`
+	RecurringID int       `json:"recurring_id"`
+	Date        string    `json:"date"`
+	BookingID   *string   `json:"booking_id"`
+	Detached    bool      `json:"detached"`
+	Error       *string   `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RecurringBookingDetail struct {
+	RecurringBooking
+	Occurrences []RecurringOccurrence `json:"occurrences"`
+}
+
+// RecurringCancel ends a series the day before From, today when empty, and
+// cancels the bookings already scheduled from then on.
+type RecurringCancel struct {
+	From   string `json:"from" binding:"omitempty,datetime=2006-01-02"`
+	Reason string `json:"reason"`
+}
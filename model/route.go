@@ -0,0 +1,10 @@
+package model
+
+// Route is a road route between two points as estimated for one departure
+// time. Path runs from the road node nearest the start to the one nearest
+// the end.
+type Route struct {
+	DistanceKM      float64    `json:"distance_km"`
+	DurationMinutes float64    `json:"duration_minutes"`
+	Path            []GeoPoint `json:"path,omitempty"`
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type Tenant struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" binding:"required"`
+	Slug      string    `json:"slug" binding:"required"`
+	CreatedAt time.Time `json:"created_at"`
+}
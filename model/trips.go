@@ -2,17 +2,36 @@ package model
 
 import "time"
 
+const (
+	TripOngoing   = "ongoing"
+	TripCompleted = "completed"
+)
+
+// VehicleTrip is one trip of a vehicle. Trips made for a booking are opened
+// when it goes on trip and closed with the actual figures when it completes.
 type VehicleTrip struct {
-	ID            uint `gorm:"primaryKey"`
-	VehicleID     uint
-	DriverID      uint
-	TripDate      time.Time
-	Origin        string
-	Destination   string
-	DistanceKM    int
-	Rating        int
-	Price         float64
-	PassengerName string
+	ID              uint `gorm:"primaryKey"`
+	BookingID       *string
+	VehicleID       uint
+	DriverID        uint
+	TripDate        time.Time
+	Origin          string
+	OriginLat       *float64
+	OriginLng       *float64
+	Destination     string
+	DestinationLat  *float64
+	DestinationLng  *float64
+	DistanceKM      int
+	DurationMinutes int
+	Rating          float32
+	Price           float64
+	PassengerName   string
+	DriverName      string
+	VehicleName     string
+	Feedback        string
+	Status          string
+	StartedAt       *time.Time
+	EndedAt         *time.Time
 }
 
 type TotalTrips struct {
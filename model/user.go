@@ -1,8 +1,38 @@
 package model
 
+const (
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "superadmin"
+	RoleDriver     = "driver"
+)
+
 type User struct {
 	ID       int64
+	TenantID int64
 	Username string
 	Password string
 	Role     string
+	// DriverID links a driver account to the driver it drives as.
+	DriverID *int
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin || u.IsSuperAdmin()
+}
+
+func (u *User) IsSuperAdmin() bool {
+	return u.Role == RoleSuperAdmin
+}
+
+func (u *User) IsDriver() bool {
+	return u.Role == RoleDriver
+}
+
+// ActsFor reports whether u may act for the driver driverID: admins act for
+// every driver, driver accounts only for the driver they are linked to.
+func (u *User) ActsFor(driverID int) bool {
+	if u.IsAdmin() {
+		return true
+	}
+	return u.IsDriver() && u.DriverID != nil && *u.DriverID == driverID
 }
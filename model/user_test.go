@@ -22,3 +22,12 @@ func TestIsAdmin(t *testing.T) {
 	assert.True(t, userAdmin.IsAdmin(), "User with role admin should return true")
 	assert.False(t, userNonAdmin.IsAdmin(), "User with role user should return false")
 }
+
+func TestIsSuperAdmin(t *testing.T) {
+	superAdmin := &model.User{ID: 1, Role: model.RoleSuperAdmin}
+	admin := &model.User{ID: 2, Role: model.RoleAdmin}
+
+	assert.True(t, superAdmin.IsSuperAdmin())
+	assert.True(t, superAdmin.IsAdmin(), "super admin should pass admin checks")
+	assert.False(t, admin.IsSuperAdmin())
+}
@@ -2,14 +2,15 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
 )
 
 type AssignmentsRepositoryInterface interface {
-	Create(a *model.DriverAssignment) error
-	FindByVehicle(vehicleID uint) ([]model.DriverAssignment, error)
-	Update(a *model.DriverAssignment) error
-	Delete(id uint) error
+	Create(ctx context.Context, a *model.DriverAssignment) error
+	FindByVehicle(ctx context.Context, vehicleID uint) ([]model.DriverAssignment, error)
+	Update(ctx context.Context, a *model.DriverAssignment) error
+	Delete(ctx context.Context, id uint) error
 }
 
 type AssignmentsRepository struct {
@@ -20,30 +21,41 @@ func NewAssignmentsRepository(db *sql.DB) *AssignmentsRepository {
 	return &AssignmentsRepository{DB: db}
 }
 
-func (r *AssignmentsRepository) Create(a *model.DriverAssignment) error {
+func (r *AssignmentsRepository) Create(ctx context.Context, a *model.DriverAssignment) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `INSERT INTO driver_assignments
-			 (vehicle_id, start_date, end_date, total_trips, driver_name, status)
-			 VALUES ($1, $2, $3, $4, $5, $6)
+			 (vehicle_id, start_date, end_date, total_trips, driver_name, status, tenant_id)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)
 			 RETURNING id
 			`
-	err := r.DB.QueryRow(query,
+	err = r.DB.QueryRowContext(ctx, query,
 		a.VehicleID,
 		a.StartDate,
 		a.EndDate,
 		a.TotalTrips,
 		a.DriverName,
 		a.Status,
+		tenantID,
 	).Scan(&a.ID)
 	return err
 }
 
-func (r *AssignmentsRepository) FindByVehicle(vehicleID uint) ([]model.DriverAssignment, error) {
+func (r *AssignmentsRepository) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.DriverAssignment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
     SELECT id, vehicle_id, start_date, end_date, total_trips, driver_name, status
     FROM driver_assignments
-    WHERE vehicle_id = $1`
+    WHERE vehicle_id = $1 AND tenant_id = $2`
 
-	rows, err := r.DB.Query(query, vehicleID)
+	rows, err := r.DB.QueryContext(ctx, query, vehicleID, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +83,12 @@ func (r *AssignmentsRepository) FindByVehicle(vehicleID uint) ([]model.DriverAss
 	return list, nil
 }
 
-func (r *AssignmentsRepository) Update(a *model.DriverAssignment) error {
+func (r *AssignmentsRepository) Update(ctx context.Context, a *model.DriverAssignment) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `
         UPDATE driver_assignments
         SET vehicle_id = $1,
@@ -80,9 +97,9 @@ func (r *AssignmentsRepository) Update(a *model.DriverAssignment) error {
     		total_trips = $4,
     		driver_name = $5,
     		status = $6
-		WHERE id = $7
+		WHERE id = $7 AND tenant_id = $8
     `
-	_, err := r.DB.Exec(query,
+	_, err = r.DB.ExecContext(ctx, query,
 		a.VehicleID,
 		a.StartDate,
 		a.EndDate,
@@ -90,12 +107,18 @@ func (r *AssignmentsRepository) Update(a *model.DriverAssignment) error {
 		a.DriverName,
 		a.Status,
 		a.ID,
+		tenantID,
 	)
 	return err
 }
 
-func (r *AssignmentsRepository) Delete(id uint) error {
-	query := `DELETE FROM driver_assignments WHERE id = $1`
-	_, err := r.DB.Exec(query, id)
+func (r *AssignmentsRepository) Delete(ctx context.Context, id uint) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM driver_assignments WHERE id = $1 AND tenant_id = $2`
+	_, err = r.DB.ExecContext(ctx, query, id, tenantID)
 	return err
 }
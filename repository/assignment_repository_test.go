@@ -33,14 +33,14 @@ func TestAssignmentRepository_Create(t *testing.T) {
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
 		INSERT INTO driver_assignments
-		(vehicle_id, start_date, end_date, total_trips, driver_name, status)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		(vehicle_id, start_date, end_date, total_trips, driver_name, status, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`)).
-		WithArgs(assignment.VehicleID, assignment.StartDate, assignment.EndDate, assignment.TotalTrips, assignment.DriverName, assignment.Status).
+		WithArgs(assignment.VehicleID, assignment.StartDate, assignment.EndDate, assignment.TotalTrips, assignment.DriverName, assignment.Status, int64(1)).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
-	err = repo.Create(assignment)
+	err = repo.Create(tenantCtx(), assignment)
 	assert.NoError(t, err)
 	assert.Equal(t, uint(1), assignment.ID)
 }
@@ -62,9 +62,9 @@ func TestAssignmentRepository_FindByVehicle(t *testing.T) {
 		SELECT id, vehicle_id, start_date, end_date, total_trips, driver_name, status
 		FROM driver_assignments
 		WHERE vehicle_id = $1
-	`)).WithArgs(1).WillReturnRows(rows)
+	`)).WithArgs(1, int64(1)).WillReturnRows(rows)
 
-	result, err := repo.FindByVehicle(1)
+	result, err := repo.FindByVehicle(tenantCtx(), 1)
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
 	assert.Equal(t, "John", result[0].DriverName)
@@ -100,10 +100,10 @@ func TestAssignmentRepository_Update(t *testing.T) {
 			status = $6
 		WHERE id = $7
 	`)).
-		WithArgs(assignment.VehicleID, assignment.StartDate, assignment.EndDate, assignment.TotalTrips, assignment.DriverName, assignment.Status, assignment.ID).
+		WithArgs(assignment.VehicleID, assignment.StartDate, assignment.EndDate, assignment.TotalTrips, assignment.DriverName, assignment.Status, assignment.ID, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Update(assignment)
+	err = repo.Update(tenantCtx(), assignment)
 	assert.NoError(t, err)
 }
 
@@ -114,10 +114,10 @@ func TestFindByVehicle_QueryError1(t *testing.T) {
 	repo := repository.NewAssignmentsRepository(db)
 
 	mock.ExpectQuery("FROM driver_assignments").
-		WithArgs(uint(1)).
+		WithArgs(uint(1), int64(1)).
 		WillReturnError(errors.New("query error"))
 
-	result, err := repo.FindByVehicle(1)
+	result, err := repo.FindByVehicle(tenantCtx(), 1)
 
 	assert.Nil(t, result)
 	assert.Error(t, err)
@@ -136,7 +136,7 @@ func TestFindByVehicle_ScanError2(t *testing.T) {
 	mock.ExpectQuery("FROM driver_assignments").
 		WillReturnRows(rows)
 
-	result, err := repo.FindByVehicle(1)
+	result, err := repo.FindByVehicle(tenantCtx(), 1)
 
 	assert.Nil(t, result)
 	assert.Error(t, err)
@@ -162,10 +162,10 @@ func TestFindByVehicle_Success(t *testing.T) {
 	)
 
 	mock.ExpectQuery("FROM driver_assignments").
-		WithArgs(uint(1)).
+		WithArgs(uint(1), int64(1)).
 		WillReturnRows(rows)
 
-	result, err := repo.FindByVehicle(1)
+	result, err := repo.FindByVehicle(tenantCtx(), 1)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
@@ -179,9 +179,9 @@ func TestAssignmentRepository_Delete(t *testing.T) {
 	repo := repository.NewAssignmentsRepository(db)
 
 	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM driver_assignments WHERE id = $1")).
-		WithArgs(1).
+		WithArgs(1, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Delete(1)
+	err = repo.Delete(tenantCtx(), 1)
 	assert.NoError(t, err)
 }
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type AvailabilityRepositoryInterface interface {
+	DriverBlocks(ctx context.Context, driverID int, from, to time.Time) ([]model.BusyBlock, error)
+	VehicleBlocks(ctx context.Context, vehicleID int, from, to time.Time) ([]model.BusyBlock, error)
+	CreateLeave(ctx context.Context, l *model.DriverLeave) error
+	DeleteLeave(ctx context.Context, driverID, id int) error
+}
+
+type AvailabilityRepository struct {
+	DB *sql.DB
+}
+
+func NewAvailabilityRepository(db *sql.DB) *AvailabilityRepository {
+	return &AvailabilityRepository{DB: db}
+}
+
+// DriverBlocks lists the driver's bookings and leave overlapping [from, to),
+// plus maintenance days of the vehicles assigned to the driver.
+func (r *AvailabilityRepository) DriverBlocks(ctx context.Context, driverID int, from, to time.Time) ([]model.BusyBlock, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT 'booking', id, start_at, end_at
+        FROM booking
+        WHERE tenant_id = $1 AND driver_id = $2 AND deleted_at IS NULL AND status NOT IN ('Cancelled', 'NoShow')
+          AND start_at < $4 AND end_at > $3
+        UNION ALL
+        SELECT 'leave', id::text, start_at, end_at
+        FROM driver_leave
+        WHERE tenant_id = $1 AND driver_id = $2 AND start_at < $4 AND end_at > $3
+        UNION ALL
+        SELECT 'maintenance', m.id::text, date_trunc('day', m.service_date), date_trunc('day', m.service_date) + INTERVAL '1 day'
+        FROM vehicle_maintenance m
+        JOIN vehicles v ON v.id = m.vehicle_id AND v.tenant_id = m.tenant_id
+        WHERE m.tenant_id = $1 AND v.driver_id = $2 AND v.deleted_at IS NULL
+          AND date_trunc('day', m.service_date) < $4 AND date_trunc('day', m.service_date) + INTERVAL '1 day' > $3
+        ORDER BY 3
+    `
+	return r.blocks(ctx, query, tenantID, driverID, from, to)
+}
+
+// VehicleBlocks lists the vehicle's bookings and maintenance days overlapping [from, to).
+func (r *AvailabilityRepository) VehicleBlocks(ctx context.Context, vehicleID int, from, to time.Time) ([]model.BusyBlock, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT 'booking', id, start_at, end_at
+        FROM booking
+        WHERE tenant_id = $1 AND vehicle_id = $2 AND deleted_at IS NULL AND status NOT IN ('Cancelled', 'NoShow')
+          AND start_at < $4 AND end_at > $3
+        UNION ALL
+        SELECT 'maintenance', id::text, date_trunc('day', service_date), date_trunc('day', service_date) + INTERVAL '1 day'
+        FROM vehicle_maintenance
+        WHERE tenant_id = $1 AND vehicle_id = $2
+          AND date_trunc('day', service_date) < $4 AND date_trunc('day', service_date) + INTERVAL '1 day' > $3
+        ORDER BY 3
+    `
+	return r.blocks(ctx, query, tenantID, vehicleID, from, to)
+}
+
+func (r *AvailabilityRepository) blocks(ctx context.Context, query string, tenantID int64, id int, from, to time.Time) ([]model.BusyBlock, error) {
+	rows, err := r.DB.QueryContext(ctx, query, tenantID, id, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := []model.BusyBlock{}
+	for rows.Next() {
+		var b model.BusyBlock
+		if err := rows.Scan(&b.Kind, &b.Ref, &b.StartAt, &b.EndAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+
+	return blocks, rows.Err()
+}
+
+func (r *AvailabilityRepository) CreateLeave(ctx context.Context, l *model.DriverLeave) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.QueryRowContext(ctx,
+		`INSERT INTO driver_leave (driver_id, start_at, end_at, reason, tenant_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, created_at`,
+		l.DriverID, l.StartAt, l.EndAt, l.Reason, tenantID,
+	).Scan(&l.ID, &l.CreatedAt)
+}
+
+func (r *AvailabilityRepository) DeleteLeave(ctx context.Context, driverID, id int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return restoredResult(r.DB.ExecContext(ctx,
+		`DELETE FROM driver_leave WHERE id = $1 AND driver_id = $2 AND tenant_id = $3`,
+		id, driverID, tenantID,
+	))
+}
@@ -0,0 +1,89 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailabilityRepository_DriverBlocks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAvailabilityRepository(db)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"kind", "ref", "start_at", "end_at"}).
+		AddRow("booking", "BK1", from.Add(8*time.Hour), from.Add(10*time.Hour)).
+		AddRow("leave", "2", from.Add(12*time.Hour), from.Add(14*time.Hour))
+
+	mock.ExpectQuery(`FROM booking\s+WHERE tenant_id = \$1 AND driver_id = \$2`).
+		WithArgs(int64(1), 3, from, to).
+		WillReturnRows(rows)
+
+	blocks, err := repo.DriverBlocks(tenantCtx(), 3, from, to)
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 2)
+	assert.Equal(t, model.BlockLeave, blocks[1].Kind)
+
+	mock.ExpectQuery(`FROM vehicle_maintenance\s+WHERE tenant_id = \$1 AND vehicle_id = \$2`).
+		WithArgs(int64(1), 9, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "ref", "start_at", "end_at"}))
+
+	blocks, err = repo.VehicleBlocks(tenantCtx(), 9, from, to)
+	assert.NoError(t, err)
+	assert.Empty(t, blocks)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAvailabilityRepository_Leave(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewAvailabilityRepository(db)
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	leave := &model.DriverLeave{DriverID: 3, StartAt: start, EndAt: start.Add(24 * time.Hour), Reason: "sick"}
+
+	mock.ExpectQuery(`INSERT INTO driver_leave`).
+		WithArgs(3, leave.StartAt, leave.EndAt, "sick", int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, time.Now()))
+
+	assert.NoError(t, repo.CreateLeave(tenantCtx(), leave))
+	assert.Equal(t, 5, leave.ID)
+
+	mock.ExpectExec(`DELETE FROM driver_leave WHERE id = \$1 AND driver_id = \$2 AND tenant_id = \$3`).
+		WithArgs(5, 4, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.DeleteLeave(tenantCtx(), 4, 5), repository.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Create_ScheduleConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT nextval`).WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO booking`).
+		WillReturnError(&pq.Error{Code: "23P01", Constraint: "booking_driver_no_overlap"})
+	mock.ExpectRollback()
+
+	err = repo.Create(tenantCtx(), &model.Booking{Status: "pending"})
+	assert.ErrorIs(t, err, repository.ErrScheduleConflict)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
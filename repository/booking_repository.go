@@ -2,49 +2,75 @@ package repository
 
 import (
 	"auth-service/model"
+	"auth-service/utils"
+	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"strings"
 	"time"
 )
 
 type BookingRepositoryInterface interface {
-	Create(*model.Booking) error
-	GetAll() ([]model.Booking, error)
-	Update(*model.Booking) error
-	Delete(id string) error
+	Create(ctx context.Context, b *model.Booking) error
+	GetAll(ctx context.Context) ([]model.Booking, error)
+	GetByPickupArea(ctx context.Context, box model.GeoBox) ([]model.Booking, error)
+	GetByID(ctx context.Context, id string) (*model.Booking, error)
+	Update(ctx context.Context, b *model.Booking) error
+	Delete(ctx context.Context, id string, version int) error
+	GetDeleted(ctx context.Context) ([]model.Booking, error)
+	Restore(ctx context.Context, id string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	Transition(ctx context.Context, b *model.Booking, change *model.BookingStatusChange) error
+	GetStatusHistory(ctx context.Context, id string) ([]model.BookingStatusChange, error)
+	UpdateStop(ctx context.Context, bookingID string, stop *model.BookingStop, from string) error
 }
 
 type BookingRepository struct {
 	DB *sql.DB
 }
 
-func generateBookingID() string {
-	t := time.Now()
-	return fmt.Sprintf("BK%04d%02d%02d%02d%02d%02d%03d",
-		t.Year(), t.Month(), t.Day(),
-		t.Hour(), t.Minute(), t.Second(),
-		t.Nanosecond()/1e6,
-	)
+func normalizeBooking(b *model.Booking) {
+	b.Payment = strings.Title(strings.ToLower(strings.TrimSpace(b.Payment)))
+	if status, ok := model.ParseBookingStatus(b.Status); ok {
+		b.Status = status
+	} else {
+		b.Status = strings.Title(strings.ToLower(strings.TrimSpace(b.Status)))
+	}
 }
 
-func (r *BookingRepository) Create(b *model.Booking) error {
-	if b.ID == "" {
-		b.ID = generateBookingID()
+func (r *BookingRepository) Create(ctx context.Context, b *model.Booking) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
 	}
 
 	b.CreatedAt = time.Now()
 	b.UpdatedAt = time.Now()
+	b.Version = 1
 
-	b.Payment = strings.Title(strings.ToLower(strings.TrimSpace(b.Payment)))
-	b.Status = strings.Title(strings.ToLower(strings.TrimSpace(b.Status)))
+	normalizeBooking(b)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if b.ID == "" {
+		var seq int64
+		if err := tx.QueryRowContext(ctx, `SELECT nextval('booking_code_seq')`).Scan(&seq); err != nil {
+			return err
+		}
+		b.ID = utils.BookingCode(seq)
+	}
 
-	_, err := r.DB.Exec(
+	_, err = tx.ExecContext(ctx,
 		`INSERT INTO booking
-        (id, customer, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, created_at, updated_at)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`,
+        (id, customer, customer_id, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, driver_id, vehicle_id, start_at, end_at, pickup_lat, pickup_lng, car_type_id, quote_id, promo_code, discount, drop_lat, drop_lng, distance_km, corporate_account_id, cost_centre, created_by, created_at, updated_at, tenant_id)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34)`,
 		b.ID,
 		b.Customer,
+		b.CustomerID,
 		b.Driver,
 		b.Place,
 		b.Date,
@@ -57,17 +83,69 @@ func (r *BookingRepository) Create(b *model.Booking) error {
 		b.PickupTime,
 		b.Amount,
 		b.Notes,
+		b.DriverID,
+		b.VehicleID,
+		b.StartAt,
+		b.EndAt,
+		b.PickupLat,
+		b.PickupLng,
+		b.CarTypeID,
+		b.QuoteID,
+		b.PromoCode,
+		b.Discount,
+		b.DropLat,
+		b.DropLng,
+		b.DistanceKM,
+		b.CorporateAccountID,
+		b.CostCentre,
+		b.CreatedBy,
 		b.CreatedAt,
 		b.UpdatedAt,
+		tenantID,
 	)
+	if err != nil {
+		return bookingError(err)
+	}
+
+	if err := insertStops(ctx, tx, tenantID, b); err != nil {
+		return err
+	}
+
+	if b.PromoCode != nil {
+		if err := redeemPromotion(ctx, tx, tenantID, b); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *BookingRepository) GetAll(ctx context.Context) ([]model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryBookings(ctx, `tenant_id = $1 AND deleted_at IS NULL`, tenantID)
+}
+
+// GetByPickupArea lists live bookings whose pickup point lies in box.
+func (r *BookingRepository) GetByPickupArea(ctx context.Context, box model.GeoBox) ([]model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	return r.queryBookings(ctx,
+		`tenant_id = $1 AND deleted_at IS NULL AND pickup_lat BETWEEN $2 AND $3 AND pickup_lng BETWEEN $4 AND $5`,
+		tenantID, box.MinLat, box.MaxLat, box.MinLng, box.MaxLng,
+	)
 }
 
-func (r *BookingRepository) GetAll() ([]model.Booking, error) {
+func (r *BookingRepository) queryBookings(ctx context.Context, where string, args ...any) ([]model.Booking, error) {
 	var bookings []model.Booking
 
-	rows, err := r.DB.Query(`SELECT id, customer, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, created_at, updated_at FROM booking`)
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, customer, customer_id, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, driver_id, vehicle_id, start_at, end_at, pickup_lat, pickup_lng, car_type_id, quote_id, promo_code, discount, drop_lat, drop_lng, distance_km, corporate_account_id, cost_centre, created_by, created_at, updated_at, version FROM booking WHERE `+where, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +156,7 @@ func (r *BookingRepository) GetAll() ([]model.Booking, error) {
 		if err := rows.Scan(
 			&b.ID,
 			&b.Customer,
+			&b.CustomerID,
 			&b.Driver,
 			&b.Place,
 			&b.Date,
@@ -90,41 +169,141 @@ func (r *BookingRepository) GetAll() ([]model.Booking, error) {
 			&b.PickupTime,
 			&b.Amount,
 			&b.Notes,
+			&b.DriverID,
+			&b.VehicleID,
+			&b.StartAt,
+			&b.EndAt,
+			&b.PickupLat,
+			&b.PickupLng,
+			&b.CarTypeID,
+			&b.QuoteID,
+			&b.PromoCode,
+			&b.Discount,
+			&b.DropLat,
+			&b.DropLng,
+			&b.DistanceKM,
+			&b.CorporateAccountID,
+			&b.CostCentre,
+			&b.CreatedBy,
 			&b.CreatedAt,
 			&b.UpdatedAt,
+			&b.Version,
 		); err != nil {
 			return nil, err
 		}
 		bookings = append(bookings, b)
 	}
 
-	return bookings, nil
+	return bookings, rows.Err()
+}
+
+func (r *BookingRepository) GetByID(ctx context.Context, id string) (*model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var b model.Booking
+	err = r.DB.QueryRowContext(ctx, `SELECT id, customer, customer_id, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, driver_id, vehicle_id, start_at, end_at, pickup_lat, pickup_lng, car_type_id, quote_id, promo_code, discount, drop_lat, drop_lng, distance_km, corporate_account_id, cost_centre, created_by, created_at, updated_at, version FROM booking WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`, id, tenantID).Scan(
+		&b.ID,
+		&b.Customer,
+		&b.CustomerID,
+		&b.Driver,
+		&b.Place,
+		&b.Date,
+		&b.Price,
+		&b.Status,
+		&b.Payment,
+		&b.PhoneNumber,
+		&b.PickupLocation,
+		&b.DropLocation,
+		&b.PickupTime,
+		&b.Amount,
+		&b.Notes,
+		&b.DriverID,
+		&b.VehicleID,
+		&b.StartAt,
+		&b.EndAt,
+		&b.PickupLat,
+		&b.PickupLng,
+		&b.CarTypeID,
+		&b.QuoteID,
+		&b.PromoCode,
+		&b.Discount,
+		&b.DropLat,
+		&b.DropLng,
+		&b.DistanceKM,
+		&b.CorporateAccountID,
+		&b.CostCentre,
+		&b.CreatedBy,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+		&b.Version,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if b.Stops, err = r.getStops(ctx, tenantID, b.ID); err != nil {
+		return nil, err
+	}
+
+	return &b, nil
 }
 
-func (r *BookingRepository) Update(b *model.Booking) error {
+func (r *BookingRepository) Update(ctx context.Context, b *model.Booking) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	b.UpdatedAt = time.Now()
 
-	b.Payment = strings.Title(strings.ToLower(strings.TrimSpace(b.Payment)))
-	b.Status = strings.Title(strings.ToLower(strings.TrimSpace(b.Status)))
+	normalizeBooking(b)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	_, err := r.DB.Exec(
+	res, err := tx.ExecContext(ctx,
 		`UPDATE booking SET
 			customer = $1,
-			driver = $2,
-			place = $3,
-			date = $4,
-			price = $5,
-			status = $6,
-			payment = $7,
-			phone_number = $8,
-			pickup_location = $9,
-			drop_location = $10,
-			pickup_time = $11,
-			amount = $12,
-			notes = $13,
-			updated_at = $14
-		WHERE id = $15`,
+			customer_id = $2,
+			driver = $3,
+			place = $4,
+			date = $5,
+			price = $6,
+			status = $7,
+			payment = $8,
+			phone_number = $9,
+			pickup_location = $10,
+			drop_location = $11,
+			pickup_time = $12,
+			amount = $13,
+			notes = $14,
+			driver_id = $15,
+			vehicle_id = $16,
+			start_at = $17,
+			end_at = $18,
+			pickup_lat = $19,
+			pickup_lng = $20,
+			car_type_id = $21,
+			drop_lat = $22,
+			drop_lng = $23,
+			distance_km = $24,
+			corporate_account_id = $25,
+			cost_centre = $26,
+			updated_at = $27,
+			version = version + 1
+		WHERE id = $28 AND version = $29 AND tenant_id = $30 AND deleted_at IS NULL`,
 		b.Customer,
+		b.CustomerID,
 		b.Driver,
 		b.Place,
 		b.Date,
@@ -137,14 +316,305 @@ func (r *BookingRepository) Update(b *model.Booking) error {
 		b.PickupTime,
 		b.Amount,
 		b.Notes,
+		b.DriverID,
+		b.VehicleID,
+		b.StartAt,
+		b.EndAt,
+		b.PickupLat,
+		b.PickupLng,
+		b.CarTypeID,
+		b.DropLat,
+		b.DropLng,
+		b.DistanceKM,
+		b.CorporateAccountID,
+		b.CostCentre,
 		b.UpdatedAt,
 		b.ID,
+		b.Version,
+		tenantID,
 	)
 
-	return err
+	if err := versionedRowResult(ctx, tx, "booking", "id", b.ID, tenantID, res, bookingError(err)); err != nil {
+		return err
+	}
+
+	// A nil Stops leaves the saved stops alone; an empty one clears them.
+	if b.Stops != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM booking_stops WHERE booking_id = $1 AND tenant_id = $2`, b.ID, tenantID); err != nil {
+			return err
+		}
+		if err := insertStops(ctx, tx, tenantID, b); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	b.Version++
+	return nil
+}
+
+func (r *BookingRepository) Delete(ctx context.Context, id string, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.DB.ExecContext(ctx,
+		`UPDATE booking SET deleted_at = NOW(), version = version + 1 WHERE id = $1 AND version = $2 AND tenant_id = $3 AND deleted_at IS NULL`,
+		id, version, tenantID,
+	)
+	return versionedRowResult(ctx, r.DB, "booking", "id", id, tenantID, res, err)
 }
 
-func (r *BookingRepository) Delete(id string) error {
-	_, err := r.DB.Exec(`DELETE FROM booking WHERE id = $1`, id)
-	return err
+func (r *BookingRepository) GetDeleted(ctx context.Context) ([]model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var bookings []model.Booking
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, customer, customer_id, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, driver_id, vehicle_id, start_at, end_at, pickup_lat, pickup_lng, car_type_id, quote_id, promo_code, discount, drop_lat, drop_lng, distance_km, corporate_account_id, cost_centre, created_by, created_at, updated_at, version, deleted_at FROM booking WHERE tenant_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b model.Booking
+		if err := rows.Scan(
+			&b.ID,
+			&b.Customer,
+			&b.CustomerID,
+			&b.Driver,
+			&b.Place,
+			&b.Date,
+			&b.Price,
+			&b.Status,
+			&b.Payment,
+			&b.PhoneNumber,
+			&b.PickupLocation,
+			&b.DropLocation,
+			&b.PickupTime,
+			&b.Amount,
+			&b.Notes,
+			&b.DriverID,
+			&b.VehicleID,
+			&b.StartAt,
+			&b.EndAt,
+			&b.PickupLat,
+			&b.PickupLng,
+			&b.CarTypeID,
+			&b.QuoteID,
+			&b.PromoCode,
+			&b.Discount,
+			&b.DropLat,
+			&b.DropLng,
+			&b.DistanceKM,
+			&b.CorporateAccountID,
+			&b.CostCentre,
+			&b.CreatedBy,
+			&b.CreatedAt,
+			&b.UpdatedAt,
+			&b.Version,
+			&b.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+
+	return bookings, rows.Err()
+}
+
+func (r *BookingRepository) Restore(ctx context.Context, id string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.DB.ExecContext(ctx,
+		`UPDATE booking SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL`,
+		id, tenantID,
+	)
+	return restoredResult(res, bookingError(err))
+}
+
+// PurgeDeleted is run by the retention job and spans every tenant.
+func (r *BookingRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM booking WHERE deleted_at IS NOT NULL AND deleted_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Transition moves b to b.Status, together with its driver, and records change
+// in the same transaction, so the history never disagrees with the booking row.
+func (r *BookingRepository) Transition(ctx context.Context, b *model.Booking, change *model.BookingStatusChange) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	b.UpdatedAt = time.Now()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE booking SET status = $1, driver = $2, driver_id = $3, updated_at = $4, version = version + 1 WHERE id = $5 AND version = $6 AND tenant_id = $7 AND deleted_at IS NULL`,
+		b.Status, b.Driver, b.DriverID, b.UpdatedAt, b.ID, b.Version, tenantID,
+	)
+	err = versionedRowResult(ctx, tx, "booking", "id", b.ID, tenantID, res, err)
+	if err != nil {
+		return err
+	}
+
+	if change.Offer != nil {
+		if err := respondOffer(ctx, tx, tenantID, change.Offer); err != nil {
+			return err
+		}
+	}
+
+	change.BookingID = b.ID
+	change.ChangedAt = b.UpdatedAt
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO booking_status_history (booking_id, from_status, to_status, changed_by, reason, changed_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		change.BookingID, change.FromStatus, change.ToStatus, change.ChangedBy, change.Reason, change.ChangedAt, tenantID,
+	).Scan(&change.ID)
+	if err != nil {
+		return err
+	}
+
+	if change.Cancellation != nil {
+		if err := settleCancellation(ctx, tx, tenantID, b, change.Cancellation); err != nil {
+			return err
+		}
+	}
+	for i := range change.Notifications {
+		if err := enqueueNotification(ctx, tx, tenantID, &change.Notifications[i]); err != nil {
+			return err
+		}
+	}
+	if change.Trip != nil {
+		if err := saveTrip(ctx, tx, tenantID, change.Trip); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	b.Version++
+	return nil
+}
+
+func (r *BookingRepository) GetStatusHistory(ctx context.Context, id string) ([]model.BookingStatusChange, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT id, booking_id, from_status, to_status, changed_by, reason, changed_at FROM booking_status_history WHERE booking_id = $1 AND tenant_id = $2 ORDER BY changed_at, id`,
+		id, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []model.BookingStatusChange{}
+	for rows.Next() {
+		var h model.BookingStatusChange
+		if err := rows.Scan(&h.ID, &h.BookingID, &h.FromStatus, &h.ToStatus, &h.ChangedBy, &h.Reason, &h.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+func insertStops(ctx context.Context, tx *sql.Tx, tenantID int64, b *model.Booking) error {
+	for i := range b.Stops {
+		st := &b.Stops[i]
+		st.BookingID = b.ID
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO booking_stops
+			(booking_id, position, kind, address, lat, lng, contact_name, contact_phone, wait_minutes, leg_km, status, arrived_at, departed_at, tenant_id)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
+			b.ID, st.Position, st.Kind, st.Address, st.Lat, st.Lng, st.ContactName, st.ContactPhone,
+			st.WaitMinutes, st.LegKM, st.Status, st.ArrivedAt, st.DepartedAt, tenantID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *BookingRepository) getStops(ctx context.Context, tenantID int64, bookingID string) ([]model.BookingStop, error) {
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT position, kind, address, lat, lng, contact_name, contact_phone, wait_minutes, leg_km, status, arrived_at, departed_at
+		FROM booking_stops WHERE booking_id = $1 AND tenant_id = $2 ORDER BY position`,
+		bookingID, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stops []model.BookingStop
+	for rows.Next() {
+		st := model.BookingStop{BookingID: bookingID}
+		if err := rows.Scan(&st.Position, &st.Kind, &st.Address, &st.Lat, &st.Lng, &st.ContactName, &st.ContactPhone,
+			&st.WaitMinutes, &st.LegKM, &st.Status, &st.ArrivedAt, &st.DepartedAt); err != nil {
+			return nil, err
+		}
+		stops = append(stops, st)
+	}
+	return stops, rows.Err()
+}
+
+// UpdateStop saves the progress of one stop: its status and arrival and
+// departure times. The stop must still be in status from, so that two
+// updates racing from the same status cannot both apply.
+func (r *BookingRepository) UpdateStop(ctx context.Context, bookingID string, stop *model.BookingStop, from string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.DB.ExecContext(ctx,
+		`UPDATE booking_stops SET status = $1, arrived_at = $2, departed_at = $3
+		WHERE booking_id = $4 AND position = $5 AND tenant_id = $6 AND status = $7`,
+		stop.Status, stop.ArrivedAt, stop.DepartedAt, bookingID, stop.Position, tenantID, from,
+	)
+	err = versionedResult(res, err)
+	if !errors.Is(err, ErrVersionConflict) {
+		return err
+	}
+
+	var exists bool
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM booking_stops WHERE booking_id = $1 AND position = $2 AND tenant_id = $3)`,
+		bookingID, stop.Position, tenantID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
 }
@@ -3,12 +3,14 @@ package repository_test
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"auth-service/utils"
 	"database/sql"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -35,14 +37,18 @@ func TestBookingRepository_Create_Success(t *testing.T) {
 		Notes:          stringPtr("Test note"),
 	}
 
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT nextval\('booking_code_seq'\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(1000))
 	mock.ExpectExec(`INSERT INTO booking`).
-		WithArgs(sqlmock.AnyArg(), "John Doe", "Driver1", "Location A", "2023-10-01", "100.00", "Pending", "Cash", "1234567890", "Pickup", "Drop", "10:00", 100.0, "Test note", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(utils.BookingCode(1000), "John Doe", nil, "Driver1", "Location A", "2023-10-01", "100.00", "Pending", "Cash", "1234567890", "Pickup", "Drop", "10:00", 100.0, "Test note", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	err = repo.Create(booking)
+	err = repo.Create(tenantCtx(), booking)
 
 	assert.NoError(t, err)
-	assert.NotEmpty(t, booking.ID)
+	assert.Equal(t, utils.BookingCode(1000), booking.ID)
 	assert.True(t, booking.CreatedAt.After(time.Time{}))
 	assert.True(t, booking.UpdatedAt.After(time.Time{}))
 
@@ -63,10 +69,14 @@ func TestBookingRepository_Create_Error(t *testing.T) {
 		Payment:  "cash",
 	}
 
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT nextval`).
+		WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(1001))
 	mock.ExpectExec(`INSERT INTO booking`).
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	err = repo.Create(booking)
+	err = repo.Create(tenantCtx(), booking)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -84,7 +94,7 @@ func TestBookingRepository_GetAll_QueryError(t *testing.T) {
 	mock.ExpectQuery("FROM booking").
 		WillReturnError(errors.New("query error"))
 
-	bookings, err := repo.GetAll()
+	bookings, err := repo.GetAll(tenantCtx())
 
 	assert.Nil(t, bookings)
 	assert.Error(t, err)
@@ -103,7 +113,26 @@ func TestBookingRepository_GetAll_ScanError(t *testing.T) {
 	mock.ExpectQuery("FROM booking").
 		WillReturnRows(rows)
 
-	bookings, err := repo.GetAll()
+	bookings, err := repo.GetAll(tenantCtx())
+
+	assert.Nil(t, bookings)
+	assert.Error(t, err)
+}
+
+func TestBookingRepository_GetAll_RowsError(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow("BK1").
+		RowError(0, errors.New("connection reset"))
+
+	mock.ExpectQuery("FROM booking").
+		WillReturnRows(rows)
+
+	bookings, err := repo.GetAll(tenantCtx())
 
 	assert.Nil(t, bookings)
 	assert.Error(t, err)
@@ -116,13 +145,14 @@ func TestBookingRepository_GetAll_Success(t *testing.T) {
 	repo := repository.BookingRepository{DB: db}
 
 	rows := sqlmock.NewRows([]string{
-		"id", "customer", "driver", "place", "date",
+		"id", "customer", "customer_id", "driver", "place", "date",
 		"price", "status", "payment", "phone_number",
 		"pickup_location", "drop_location", "pickup_time",
-		"amount", "notes", "created_at", "updated_at",
+		"amount", "notes", "driver_id", "vehicle_id", "start_at", "end_at", "pickup_lat", "pickup_lng", "car_type_id", "quote_id", "promo_code", "discount", "drop_lat", "drop_lng", "distance_km", "corporate_account_id", "cost_centre", "created_by", "created_at", "updated_at", "version",
 	}).AddRow(
 		1,
 		"John",
+		nil,
 		"Driver A",
 		"Bandung",
 		time.Now(),
@@ -135,14 +165,31 @@ func TestBookingRepository_GetAll_Success(t *testing.T) {
 		time.Now(),
 		100000,
 		"OK",
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		time.Now(),
 		time.Now(),
+		1,
 	)
 
 	mock.ExpectQuery("FROM booking").
 		WillReturnRows(rows)
 
-	bookings, err := repo.GetAll()
+	bookings, err := repo.GetAll(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, bookings, 1)
@@ -155,10 +202,10 @@ func TestBookingRepository_GetAll_Error(t *testing.T) {
 
 	repo := repository.BookingRepository{DB: db}
 
-	mock.ExpectQuery(`SELECT id, customer, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, created_at, updated_at FROM booking`).
+	mock.ExpectQuery(`SELECT id, customer, customer_id, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, driver_id, vehicle_id, start_at, end_at, pickup_lat, pickup_lng, car_type_id, quote_id, promo_code, discount, drop_lat, drop_lng, distance_km, corporate_account_id, cost_centre, created_by, created_at, updated_at, version FROM booking`).
 		WillReturnError(sql.ErrConnDone)
 
-	bookings, err := repo.GetAll()
+	bookings, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, bookings)
@@ -190,15 +237,19 @@ func TestBookingRepository_Update_Success(t *testing.T) {
 		PickupTime:     stringPtr("11:00"),
 		Amount:         float64Ptr(200.0),
 		Notes:          stringPtr("Updated note"),
+		Version:        4,
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec(`UPDATE booking SET`).
-		WithArgs("Jane Doe", "Driver2", "Location B", "2023-10-02", "200.00", "Confirmed", "Card", "0987654321", "New Pickup", "New Drop", "11:00", 200.0, "Updated note", sqlmock.AnyArg(), "BK123").
+		WithArgs("Jane Doe", nil, "Driver2", "Location B", "2023-10-02", "200.00", "Confirmed", "Card", "0987654321", "New Pickup", "New Drop", "11:00", 200.0, "Updated note", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, sqlmock.AnyArg(), "BK123", 4, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
-	err = repo.Update(booking)
+	err = repo.Update(tenantCtx(), booking)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 5, booking.Version)
 	assert.True(t, booking.UpdatedAt.After(time.Time{}))
 
 	err = mock.ExpectationsWereMet()
@@ -219,10 +270,12 @@ func TestBookingRepository_Update_Error(t *testing.T) {
 		Payment:  "card",
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec(`UPDATE booking SET`).
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	err = repo.Update(booking)
+	err = repo.Update(tenantCtx(), booking)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -240,11 +293,11 @@ func TestBookingRepository_Delete_Success(t *testing.T) {
 
 	id := "BK123"
 
-	mock.ExpectExec(`DELETE FROM booking WHERE id = \$1`).
-		WithArgs(id).
+	mock.ExpectExec(`UPDATE booking SET deleted_at = NOW\(\), version = version \+ 1 WHERE id = \$1 AND version = \$2 AND tenant_id = \$3 AND deleted_at IS NULL`).
+		WithArgs(id, 1, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id, 1)
 
 	assert.NoError(t, err)
 
@@ -261,11 +314,11 @@ func TestBookingRepository_Delete_Error(t *testing.T) {
 
 	id := "BK123"
 
-	mock.ExpectExec(`DELETE FROM booking WHERE id = \$1`).
-		WithArgs(id).
+	mock.ExpectExec(`UPDATE booking SET deleted_at = NOW\(\), version = version \+ 1 WHERE id = \$1 AND version = \$2 AND tenant_id = \$3 AND deleted_at IS NULL`).
+		WithArgs(id, 1, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id, 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -274,6 +327,147 @@ func TestBookingRepository_Delete_Error(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestBookingRepository_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+
+	rows := sqlmock.NewRows([]string{
+		"id", "customer", "customer_id", "driver", "place", "date",
+		"price", "status", "payment", "phone_number",
+		"pickup_location", "drop_location", "pickup_time",
+		"amount", "notes", "driver_id", "vehicle_id", "start_at", "end_at", "pickup_lat", "pickup_lng", "car_type_id", "quote_id", "promo_code", "discount", "drop_lat", "drop_lng", "distance_km", "corporate_account_id", "cost_centre", "created_by", "created_at", "updated_at", "version",
+	}).AddRow("BK1", "John", nil, "Driver A", "Bandung", "2024-01-01", "100000", "Pending", "Cash",
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, time.Now(), time.Now(), 3)
+
+	mock.ExpectQuery(`FROM booking WHERE id = \$1`).WithArgs("BK1", int64(1)).WillReturnRows(rows)
+	mock.ExpectQuery(`FROM booking_stops WHERE booking_id = \$1 AND tenant_id = \$2 ORDER BY position`).
+		WithArgs("BK1", int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"position", "kind", "address", "lat", "lng", "contact_name", "contact_phone", "wait_minutes", "leg_km", "status", "arrived_at", "departed_at"}).
+			AddRow(1, model.StopPickup, "Jl. Braga 10", -6.91, 107.61, "Sari", "0812", 5, 2.4, model.StopPending, nil, nil))
+
+	booking, err := repo.GetByID(tenantCtx(), "BK1")
+	assert.NoError(t, err)
+	assert.Equal(t, "John", booking.Customer)
+	assert.Equal(t, 3, booking.Version)
+	assert.Len(t, booking.Stops, 1)
+	assert.Equal(t, "Jl. Braga 10", booking.Stops[0].Address)
+	assert.Equal(t, "BK1", booking.Stops[0].BookingID)
+
+	mock.ExpectQuery(`FROM booking WHERE id = \$1`).WithArgs("BK2", int64(1)).WillReturnError(sql.ErrNoRows)
+
+	booking, err = repo.GetByID(tenantCtx(), "BK2")
+	assert.NoError(t, err)
+	assert.Nil(t, booking)
+
+	mock.ExpectQuery(`FROM booking WHERE id = \$1`).WithArgs("BK3", int64(1)).WillReturnError(sql.ErrConnDone)
+
+	_, err = repo.GetByID(tenantCtx(), "BK3")
+	assert.Equal(t, sql.ErrConnDone, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Update_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "booking", "id", "BK123", true)
+	mock.ExpectRollback()
+
+	booking := &model.Booking{ID: "BK123", Version: 1}
+	err = repo.Update(tenantCtx(), booking)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	assert.Equal(t, 1, booking.Version)
+
+	mock.ExpectExec(`UPDATE booking SET deleted_at = NOW\(\), version = version \+ 1 WHERE id = \$1 AND version = \$2 AND tenant_id = \$3 AND deleted_at IS NULL`).
+		WithArgs("BK123", 1, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "booking", "id", "BK123", true)
+
+	err = repo.Delete(tenantCtx(), "BK123", 1)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Update_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "booking", "id", "BK404", false)
+	mock.ExpectRollback()
+
+	err = repo.Update(tenantCtx(), &model.Booking{ID: "BK404", Version: 1})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	mock.ExpectExec(`UPDATE booking SET deleted_at`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "booking", "id", "BK404", false)
+
+	err = repo.Delete(tenantCtx(), "BK404", 1)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Stops(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+
+	booking := &model.Booking{ID: "BK123", Customer: "Jane Doe", Status: "confirmed", Payment: "cash", Version: 2,
+		Stops: []model.BookingStop{{Position: 1, Kind: model.StopPickup, Address: "Jl. Braga 10", WaitMinutes: 5, Status: model.StopPending}}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM booking_stops WHERE booking_id = \$1 AND tenant_id = \$2`).
+		WithArgs("BK123", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO booking_stops`).
+		WithArgs("BK123", 1, model.StopPickup, "Jl. Braga 10", nil, nil, "", "", 5, nil, model.StopPending, nil, nil, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.Update(tenantCtx(), booking))
+	assert.Equal(t, 3, booking.Version)
+
+	now := time.Now()
+	stop := &model.BookingStop{Position: 1, Status: model.StopArrived, ArrivedAt: &now}
+	mock.ExpectExec(`UPDATE booking_stops SET status = \$1, arrived_at = \$2, departed_at = \$3`).
+		WithArgs(model.StopArrived, &now, nil, "BK123", 1, int64(1), model.StopPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdateStop(tenantCtx(), "BK123", stop, model.StopPending))
+
+	mock.ExpectExec(`UPDATE booking_stops SET`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM booking_stops`).
+		WithArgs("BK123", 1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	assert.ErrorIs(t, repo.UpdateStop(tenantCtx(), "BK123", stop, model.StopPending), repository.ErrVersionConflict)
+
+	mock.ExpectExec(`UPDATE booking_stops SET`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM booking_stops`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	assert.ErrorIs(t, repo.UpdateStop(tenantCtx(), "BK123", stop, model.StopPending), repository.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // Helper functions for pointers
 func stringPtr(s string) *string {
 	return &s
@@ -282,3 +476,123 @@ func stringPtr(s string) *string {
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+func TestBookingRepository_Trash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+	deletedAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{
+		"id", "customer", "customer_id", "driver", "place", "date",
+		"price", "status", "payment", "phone_number",
+		"pickup_location", "drop_location", "pickup_time",
+		"amount", "notes", "driver_id", "vehicle_id", "start_at", "end_at", "pickup_lat", "pickup_lng", "car_type_id", "quote_id", "promo_code", "discount", "drop_lat", "drop_lng", "distance_km", "corporate_account_id", "cost_centre", "created_by", "created_at", "updated_at", "version", "deleted_at",
+	}).AddRow("BK1", "John", nil, "Driver A", "Bandung", "2024-01-01", "100000", "Pending", "Cash",
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, time.Now(), time.Now(), 2, deletedAt)
+
+	mock.ExpectQuery(`FROM booking WHERE tenant_id = \$1 AND deleted_at IS NOT NULL`).WillReturnRows(rows)
+
+	bookings, err := repo.GetDeleted(tenantCtx())
+	assert.NoError(t, err)
+	assert.Len(t, bookings, 1)
+	assert.Equal(t, deletedAt, *bookings[0].DeletedAt)
+
+	mock.ExpectExec(`UPDATE booking SET deleted_at = NULL`).WithArgs("BK1", int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Restore(tenantCtx(), "BK1"))
+
+	mock.ExpectExec(`UPDATE booking SET deleted_at = NULL`).WithArgs("BK2", int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Restore(tenantCtx(), "BK2"), repository.ErrNotFound)
+
+	mock.ExpectExec(`UPDATE booking SET deleted_at = NULL`).WithArgs("BK3", int64(1)).
+		WillReturnError(&pq.Error{Code: "23P01", Constraint: "booking_driver_no_overlap"})
+	assert.ErrorIs(t, repo.Restore(tenantCtx(), "BK3"), repository.ErrScheduleConflict)
+
+	mock.ExpectExec(`DELETE FROM booking WHERE deleted_at IS NOT NULL AND deleted_at <= \$1`).
+		WithArgs(deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	n, err := repo.PurgeDeleted(tenantCtx(), deletedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Transition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+
+	driverID := 3
+	booking := &model.Booking{ID: "BK1", Status: model.BookingDriverAssigned, Driver: "Budi", DriverID: &driverID, Version: 2}
+	offer := &model.DispatchOffer{ID: 8, Status: model.OfferAccepted}
+	change := &model.BookingStatusChange{FromStatus: model.BookingConfirmed, ToStatus: model.BookingDriverAssigned, ChangedBy: 5, Reason: "dispatch", Offer: offer}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET status = \$1, driver = \$2, driver_id = \$3, updated_at = \$4, version = version \+ 1 WHERE id = \$5 AND version = \$6 AND tenant_id = \$7 AND deleted_at IS NULL`).
+		WithArgs("DriverAssigned", "Budi", 3, sqlmock.AnyArg(), "BK1", 2, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE dispatch_offers SET status = \$1, responded_at = \$2 WHERE id = \$3 AND tenant_id = \$4 AND status = 'Pending'`).
+		WithArgs("Accepted", sqlmock.AnyArg(), 8, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO booking_status_history`).
+		WithArgs("BK1", "Confirmed", "DriverAssigned", int64(5), "dispatch", sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Transition(tenantCtx(), booking, change))
+	assert.Equal(t, 3, booking.Version)
+	assert.Equal(t, int64(11), change.ID)
+	assert.Equal(t, "BK1", change.BookingID)
+	assert.NotNil(t, offer.RespondedAt)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET status`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE dispatch_offers SET status`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.Transition(tenantCtx(), booking, &model.BookingStatusChange{Offer: &model.DispatchOffer{ID: 9, Status: model.OfferAccepted}})
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	assert.Equal(t, 3, booking.Version)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET status`).WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "booking", "id", "BK1", true)
+	mock.ExpectRollback()
+
+	err = repo.Transition(tenantCtx(), booking, &model.BookingStatusChange{})
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	assert.Equal(t, 3, booking.Version)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_GetStatusHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+	changedAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "booking_id", "from_status", "to_status", "changed_by", "reason", "changed_at"}).
+		AddRow(1, "BK1", "Pending", "Confirmed", 5, "", changedAt).
+		AddRow(2, "BK1", "Confirmed", "Cancelled", 9, "customer request", changedAt)
+
+	mock.ExpectQuery(`FROM booking_status_history WHERE booking_id = \$1 AND tenant_id = \$2 ORDER BY changed_at, id`).
+		WithArgs("BK1", int64(1)).
+		WillReturnRows(rows)
+
+	history, err := repo.GetStatusHistory(tenantCtx(), "BK1")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "Cancelled", history[1].ToStatus)
+	assert.Equal(t, "customer request", history[1].Reason)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,11 +2,12 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
 )
 
 type BookingTrendsRepositoryInterface interface {
-	GetTrends(year int) ([]model.BookingTrend, error)
+	GetTrends(ctx context.Context, year int) ([]model.BookingTrend, error)
 }
 
 type BookingTrendsRepository struct {
@@ -17,13 +18,18 @@ func NewBookingTrendsRepository(db *sql.DB) *BookingTrendsRepository {
 	return &BookingTrendsRepository{DB: db}
 }
 
-func (r *BookingTrendsRepository) GetTrends(year int) ([]model.BookingTrend, error) {
-	rows, err := r.DB.Query(`
+func (r *BookingTrendsRepository) GetTrends(ctx context.Context, year int) ([]model.BookingTrend, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
         SELECT month, booking_count, year 
         FROM booking_trends 
-        WHERE year = $1
+        WHERE year = $1 AND tenant_id = $2
         ORDER BY id ASC
-    `, year)
+    `, year, tenantID)
 	if err != nil {
 		return nil, err
 	}
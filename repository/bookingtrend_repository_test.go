@@ -26,11 +26,11 @@ func TestBookingTrendsRepository_GetTrends_Success(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta(`
         SELECT month, booking_count, year
         FROM booking_trends
-        WHERE year = $1
+        WHERE year = $1 AND tenant_id = $2
         ORDER BY id ASC
-    `)).WithArgs(year).WillReturnRows(rows)
+    `)).WithArgs(year, int64(1)).WillReturnRows(rows)
 
-	trends, err := repo.GetTrends(year)
+	trends, err := repo.GetTrends(tenantCtx(), year)
 
 	assert.NoError(t, err)
 	assert.Len(t, trends, 2)
@@ -57,11 +57,11 @@ func TestBookingTrendsRepository_GetTrends_Error(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta(`
         SELECT month, booking_count, year
         FROM booking_trends
-        WHERE year = $1
+        WHERE year = $1 AND tenant_id = $2
         ORDER BY id ASC
-    `)).WithArgs(year).WillReturnError(sql.ErrConnDone)
+    `)).WithArgs(year, int64(1)).WillReturnError(sql.ErrConnDone)
 
-	trends, err := repo.GetTrends(year)
+	trends, err := repo.GetTrends(tenantCtx(), year)
 
 	assert.Error(t, err)
 	assert.Nil(t, trends)
@@ -78,10 +78,10 @@ func TestGetTrends_QueryError(t *testing.T) {
 	repo := repository.NewBookingTrendsRepository(db)
 
 	mock.ExpectQuery("FROM booking_trends").
-		WithArgs(2024).
+		WithArgs(2024, int64(1)).
 		WillReturnError(errors.New("query error"))
 
-	trends, err := repo.GetTrends(2024)
+	trends, err := repo.GetTrends(tenantCtx(), 2024)
 
 	assert.Nil(t, trends)
 	assert.Error(t, err)
@@ -98,10 +98,10 @@ func TestGetTrends_ScanError(t *testing.T) {
 	}).AddRow("Jan", 10)
 
 	mock.ExpectQuery("FROM booking_trends").
-		WithArgs(2024).
+		WithArgs(2024, int64(1)).
 		WillReturnRows(rows)
 
-	trends, err := repo.GetTrends(2024)
+	trends, err := repo.GetTrends(tenantCtx(), 2024)
 
 	assert.Nil(t, trends)
 	assert.Error(t, err)
@@ -120,10 +120,10 @@ func TestGetTrends_Success(t *testing.T) {
 		AddRow("Feb", 20, 2024)
 
 	mock.ExpectQuery("FROM booking_trends").
-		WithArgs(2024).
+		WithArgs(2024, int64(1)).
 		WillReturnRows(rows)
 
-	trends, err := repo.GetTrends(2024)
+	trends, err := repo.GetTrends(tenantCtx(), 2024)
 
 	assert.NoError(t, err)
 	assert.Len(t, trends, 2)
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type CalendarRepositoryInterface interface {
+	GetFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error)
+	SaveFeed(ctx context.Context, f *model.CalendarFeed) error
+	DeleteFeed(ctx context.Context, kind string, subjectID int) error
+	FindFeed(ctx context.Context, token string) (*model.CalendarFeed, error)
+	DriverSchedule(ctx context.Context, driverID int, from time.Time) (*model.CalendarSchedule, error)
+	VehicleSchedule(ctx context.Context, vehicleID int, from time.Time) (*model.CalendarSchedule, error)
+}
+
+type CalendarRepository struct {
+	DB *sql.DB
+}
+
+func NewCalendarRepository(db *sql.DB) *CalendarRepository {
+	return &CalendarRepository{DB: db}
+}
+
+func (r *CalendarRepository) GetFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f := model.CalendarFeed{Kind: kind, SubjectID: subjectID, TenantID: tenantID}
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT token, created_at FROM calendar_feeds WHERE tenant_id = $1 AND kind = $2 AND subject_id = $3`,
+		tenantID, kind, subjectID,
+	).Scan(&f.Token, &f.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// SaveFeed stores the feed, replacing the subject's previous token.
+func (r *CalendarRepository) SaveFeed(ctx context.Context, f *model.CalendarFeed) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	f.TenantID = tenantID
+	return r.DB.QueryRowContext(ctx,
+		`INSERT INTO calendar_feeds (token, tenant_id, kind, subject_id, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (tenant_id, kind, subject_id) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at
+		RETURNING created_at`,
+		f.Token, tenantID, f.Kind, f.SubjectID,
+	).Scan(&f.CreatedAt)
+}
+
+func (r *CalendarRepository) DeleteFeed(ctx context.Context, kind string, subjectID int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return restoredResult(r.DB.ExecContext(ctx,
+		`DELETE FROM calendar_feeds WHERE tenant_id = $1 AND kind = $2 AND subject_id = $3`,
+		tenantID, kind, subjectID,
+	))
+}
+
+// FindFeed looks a token up in every tenant; calendar apps fetch feeds without
+// signing in.
+func (r *CalendarRepository) FindFeed(ctx context.Context, token string) (*model.CalendarFeed, error) {
+	f := model.CalendarFeed{Token: token}
+	err := r.DB.QueryRowContext(ctx,
+		`SELECT tenant_id, kind, subject_id, created_at FROM calendar_feeds WHERE token = $1`,
+		token,
+	).Scan(&f.TenantID, &f.Kind, &f.SubjectID, &f.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// DriverSchedule lists the driver's bookings ending after from, assignments
+// made out to the driver's name and maintenance days of the vehicles the
+// driver is assigned to. It returns nil when the driver does not exist.
+func (r *CalendarRepository) DriverSchedule(ctx context.Context, driverID int, from time.Time) (*model.CalendarSchedule, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var s model.CalendarSchedule
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT name FROM drivers WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`,
+		driverID, tenantID,
+	).Scan(&s.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Bookings, err = r.scheduleBookings(ctx, "driver_id", tenantID, driverID, from); err != nil {
+		return nil, err
+	}
+	if s.Assignments, err = r.scheduleAssignments(ctx, "driver_name = $2", tenantID, s.Name, from); err != nil {
+		return nil, err
+	}
+	s.Maintenance, err = r.scheduleMaintenance(ctx, `
+        SELECT m.id, m.vehicle_id, m.service_date, m.description, m.cost, m.mileage, m.service_type
+        FROM vehicle_maintenance m
+        JOIN vehicles v ON v.id = m.vehicle_id AND v.tenant_id = m.tenant_id
+        WHERE m.tenant_id = $1 AND v.driver_id = $2 AND v.deleted_at IS NULL
+          AND m.service_date >= date_trunc('day', $3::timestamp)
+        ORDER BY m.service_date
+    `, tenantID, driverID, from)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// VehicleSchedule lists the vehicle's bookings ending after from, its driver
+// assignments and its maintenance days. It returns nil when the vehicle does
+// not exist.
+func (r *CalendarRepository) VehicleSchedule(ctx context.Context, vehicleID int, from time.Time) (*model.CalendarSchedule, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var brand, carModel, plate string
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT brand, model, plate_number FROM vehicles WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`,
+		vehicleID, tenantID,
+	).Scan(&brand, &carModel, &plate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := model.CalendarSchedule{Name: vehicleName(brand, carModel, plate)}
+	if s.Bookings, err = r.scheduleBookings(ctx, "vehicle_id", tenantID, vehicleID, from); err != nil {
+		return nil, err
+	}
+	if s.Assignments, err = r.scheduleAssignments(ctx, "vehicle_id = $2", tenantID, vehicleID, from); err != nil {
+		return nil, err
+	}
+	s.Maintenance, err = r.scheduleMaintenance(ctx, `
+        SELECT id, vehicle_id, service_date, description, cost, mileage, service_type
+        FROM vehicle_maintenance
+        WHERE tenant_id = $1 AND vehicle_id = $2 AND service_date >= date_trunc('day', $3::timestamp)
+        ORDER BY service_date
+    `, tenantID, vehicleID, from)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func vehicleName(brand, carModel, plate string) string {
+	return fmt.Sprintf("%s %s (%s)", brand, carModel, plate)
+}
+
+// scheduleBookings lists the scheduled bookings holding the driver or vehicle,
+// matching the predicate of the booking exclusion constraints.
+func (r *CalendarRepository) scheduleBookings(ctx context.Context, column string, tenantID int64, id int, from time.Time) ([]model.Booking, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, customer, place, start_at, end_at, status, phone_number, pickup_location, drop_location, notes, updated_at, version
+        FROM booking
+        WHERE tenant_id = $1 AND `+column+` = $2 AND deleted_at IS NULL AND status NOT IN ('Cancelled', 'NoShow')
+          AND start_at IS NOT NULL AND end_at > $3
+        ORDER BY start_at
+    `, tenantID, id, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []model.Booking{}
+	for rows.Next() {
+		var b model.Booking
+		err := rows.Scan(&b.ID, &b.Customer, &b.Place, &b.StartAt, &b.EndAt, &b.Status, &b.PhoneNumber,
+			&b.PickupLocation, &b.DropLocation, &b.Notes, &b.UpdatedAt, &b.Version)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+func (r *CalendarRepository) scheduleAssignments(ctx context.Context, match string, tenantID int64, subject any, from time.Time) ([]model.DriverAssignment, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT id, vehicle_id, start_date, end_date, total_trips, driver_name, status
+        FROM driver_assignments
+        WHERE tenant_id = $1 AND `+match+` AND end_date >= date_trunc('day', $3::timestamp)
+        ORDER BY start_date
+    `, tenantID, subject, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []model.DriverAssignment{}
+	for rows.Next() {
+		var a model.DriverAssignment
+		if err := rows.Scan(&a.ID, &a.VehicleID, &a.StartDate, &a.EndDate, &a.TotalTrips, &a.DriverName, &a.Status); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+func (r *CalendarRepository) scheduleMaintenance(ctx context.Context, query string, args ...any) ([]model.VehicleMaintenance, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []model.VehicleMaintenance{}
+	for rows.Next() {
+		var m model.VehicleMaintenance
+		if err := rows.Scan(&m.ID, &m.VehicleID, &m.ServiceDate, &m.Description, &m.Cost, &m.Mileage, &m.ServiceType); err != nil {
+			return nil, err
+		}
+		records = append(records, m)
+	}
+	return records, rows.Err()
+}
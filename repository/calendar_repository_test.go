@@ -0,0 +1,98 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarRepository_Feeds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCalendarRepository(db)
+
+	created := time.Now()
+	mock.ExpectQuery(`INSERT INTO calendar_feeds .* ON CONFLICT \(tenant_id, kind, subject_id\) DO UPDATE`).
+		WithArgs("tok", int64(1), model.CalendarDriver, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(created))
+	feed := &model.CalendarFeed{Token: "tok", Kind: model.CalendarDriver, SubjectID: 4}
+	require.NoError(t, repo.SaveFeed(tenantCtx(), feed))
+	assert.Equal(t, created, feed.CreatedAt)
+	assert.Equal(t, int64(1), feed.TenantID)
+
+	// Feeds are looked up by token alone, without a tenant in the context.
+	mock.ExpectQuery(`FROM calendar_feeds WHERE token = \$1`).
+		WithArgs("tok").
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "kind", "subject_id", "created_at"}).AddRow(3, model.CalendarDriver, 4, created))
+	found, err := repo.FindFeed(context.Background(), "tok")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, int64(3), found.TenantID)
+
+	mock.ExpectQuery(`FROM calendar_feeds WHERE token = \$1`).
+		WithArgs("gone").
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "kind", "subject_id", "created_at"}))
+	found, err = repo.FindFeed(context.Background(), "gone")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	mock.ExpectExec(`DELETE FROM calendar_feeds`).
+		WithArgs(int64(1), model.CalendarVehicle, 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteFeed(tenantCtx(), model.CalendarVehicle, 7), repository.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCalendarRepository_VehicleSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCalendarRepository(db)
+
+	from := time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)
+	start, end := from.Add(24*time.Hour), from.Add(26*time.Hour)
+
+	mock.ExpectQuery(`SELECT brand, model, plate_number FROM vehicles`).
+		WithArgs(7, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"brand", "model", "plate_number"}).AddRow("Toyota", "Innova", "D 1234 AB"))
+	mock.ExpectQuery(`FROM booking\s+WHERE tenant_id = \$1 AND vehicle_id = \$2`).
+		WithArgs(int64(1), 7, from).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "customer", "place", "start_at", "end_at", "status", "phone_number", "pickup_location", "drop_location", "notes", "updated_at", "version"}).
+			AddRow("BK1", "Sari", "Bandung", start, end, model.BookingConfirmed, nil, "Jakarta", "Bandung", nil, from, 3))
+	mock.ExpectQuery(`FROM driver_assignments\s+WHERE tenant_id = \$1 AND vehicle_id = \$2`).
+		WithArgs(int64(1), 7, from).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vehicle_id", "start_date", "end_date", "total_trips", "driver_name", "status"}))
+	mock.ExpectQuery(`FROM vehicle_maintenance\s+WHERE tenant_id = \$1 AND vehicle_id = \$2`).
+		WithArgs(int64(1), 7, from).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "vehicle_id", "service_date", "description", "cost", "mileage", "service_type"}).
+			AddRow(2, 7, from.AddDate(0, 0, 3), "", 300000.0, 10000, "oil"))
+
+	schedule, err := repo.VehicleSchedule(tenantCtx(), 7, from)
+	require.NoError(t, err)
+	require.NotNil(t, schedule)
+	assert.Equal(t, "Toyota Innova (D 1234 AB)", schedule.Name)
+	require.Len(t, schedule.Bookings, 1)
+	assert.Equal(t, 3, schedule.Bookings[0].Version)
+	assert.Empty(t, schedule.Assignments)
+	require.Len(t, schedule.Maintenance, 1)
+	assert.Equal(t, "oil", schedule.Maintenance[0].ServiceType)
+
+	mock.ExpectQuery(`SELECT name FROM drivers`).
+		WithArgs(9, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	schedule, err = repo.DriverSchedule(tenantCtx(), 9, from)
+	require.NoError(t, err)
+	assert.Nil(t, schedule)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
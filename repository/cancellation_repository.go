@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type CancellationRepositoryInterface interface {
+	GetPolicy(ctx context.Context) (*model.CancellationPolicy, error)
+	SavePolicy(ctx context.Context, p *model.CancellationPolicy) error
+	GetByBooking(ctx context.Context, bookingID string) (*model.BookingCancellation, error)
+}
+
+type CancellationRepository struct {
+	DB *sql.DB
+}
+
+func NewCancellationRepository(db *sql.DB) *CancellationRepository {
+	return &CancellationRepository{DB: db}
+}
+
+// GetPolicy returns nil when the tenant has not saved a policy.
+func (r *CancellationRepository) GetPolicy(ctx context.Context) (*model.CancellationPolicy, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var p model.CancellationPolicy
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT free_hours, late_fee_percent, minimum_late_fee, no_show_fee_percent, updated_at FROM cancellation_policies WHERE tenant_id = $1`,
+		tenantID,
+	).Scan(&p.FreeHours, &p.LateFeePercent, &p.MinimumLateFee, &p.NoShowFeePercent, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SavePolicy creates the tenant's policy or replaces it.
+func (r *CancellationRepository) SavePolicy(ctx context.Context, p *model.CancellationPolicy) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.UpdatedAt = time.Now()
+
+	_, err = r.DB.ExecContext(ctx, `
+        INSERT INTO cancellation_policies (free_hours, late_fee_percent, minimum_late_fee, no_show_fee_percent, updated_at, tenant_id)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (tenant_id) DO UPDATE SET
+            free_hours = EXCLUDED.free_hours,
+            late_fee_percent = EXCLUDED.late_fee_percent,
+            minimum_late_fee = EXCLUDED.minimum_late_fee,
+            no_show_fee_percent = EXCLUDED.no_show_fee_percent,
+            updated_at = EXCLUDED.updated_at
+    `,
+		p.FreeHours, p.LateFeePercent, p.MinimumLateFee, p.NoShowFeePercent, p.UpdatedAt, tenantID,
+	)
+	return err
+}
+
+func (r *CancellationRepository) GetByBooking(ctx context.Context, bookingID string) (*model.BookingCancellation, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var c model.BookingCancellation
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT id, booking_id, status, hours_before, fare, paid, fee, refund, fee_payment_id, reason, cancelled_by, created_at
+		FROM booking_cancellations WHERE booking_id = $1 AND tenant_id = $2`,
+		bookingID, tenantID,
+	).Scan(&c.ID, &c.BookingID, &c.Status, &c.HoursBefore, &c.Fare, &c.Paid, &c.Fee, &c.Refund,
+		&c.FeePaymentID, &c.Reason, &c.CancelledBy, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.Refunds, err = queryRefunds(ctx, r.DB, `cancellation_id = $1 AND tenant_id = $2`, c.ID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// cancellationFee returns the payment that charges the fee of c beyond what
+// the customer has paid, or nil when there is none.
+func cancellationFee(b *model.Booking, c *model.BookingCancellation) *model.Payment {
+	if c.Fee <= c.Paid {
+		return nil
+	}
+	return &model.Payment{BookingID: &b.ID, Customer: b.Customer, CustomerID: b.CustomerID, Driver: b.Driver, Amount: c.Fee - c.Paid,
+		Method: model.PaymentMethodCancellationFee, Status: model.PaymentPending}
+}
+
+// settleCancellation records c, its fee charge and its refunds inside the
+// transition transaction of b, and brings the payment status of b up to date.
+// A refund that would take more than is left of its payment fails the
+// transition with ErrRefundExceedsCaptured.
+func settleCancellation(ctx context.Context, tx *sql.Tx, tenantID int64, b *model.Booking, c *model.BookingCancellation) error {
+	if fee := cancellationFee(b, c); fee != nil {
+		var id int
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO payment (booking_id, customer, customer_id, driver, amount, method, status, tenant_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING payment_id`,
+			fee.BookingID, fee.Customer, fee.CustomerID, fee.Driver, fee.Amount, fee.Method, fee.Status, tenantID,
+		).Scan(&id)
+		if err != nil {
+			return err
+		}
+		c.FeePaymentID = &id
+	}
+
+	c.BookingID = b.ID
+	c.CreatedAt = b.UpdatedAt
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO booking_cancellations (booking_id, status, hours_before, fare, paid, fee, refund, fee_payment_id, reason, cancelled_by, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		c.BookingID, c.Status, c.HoursBefore, c.Fare, c.Paid, c.Fee, c.Refund, c.FeePaymentID, c.Reason, c.CancelledBy, c.CreatedAt, tenantID,
+	).Scan(&c.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range c.Refunds {
+		c.Refunds[i].CancellationID = &c.ID
+		if _, err := addRefund(ctx, tx, tenantID, &c.Refunds[i]); err != nil {
+			return err
+		}
+	}
+	return syncBookingPayment(ctx, tx, tenantID, &b.ID)
+}
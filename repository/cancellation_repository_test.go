@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancellationRepository_Policy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCancellationRepository(db)
+
+	mock.ExpectQuery(`FROM cancellation_policies WHERE tenant_id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"free_hours", "late_fee_percent", "minimum_late_fee", "no_show_fee_percent", "updated_at"}))
+	missing, err := repo.GetPolicy(tenantCtx())
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	mock.ExpectExec(`INSERT INTO cancellation_policies .* ON CONFLICT \(tenant_id\) DO UPDATE`).
+		WithArgs(6, 25.0, 20000.0, 100.0, sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	policy := &model.CancellationPolicy{FreeHours: 6, LateFeePercent: 25, MinimumLateFee: 20000, NoShowFeePercent: 100}
+	assert.NoError(t, repo.SavePolicy(tenantCtx(), policy))
+	assert.False(t, policy.UpdatedAt.IsZero())
+
+	mock.ExpectQuery(`FROM cancellation_policies WHERE tenant_id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"free_hours", "late_fee_percent", "minimum_late_fee", "no_show_fee_percent", "updated_at"}).
+			AddRow(6, 25.0, 20000.0, 100.0, time.Now()))
+	found, err := repo.GetPolicy(tenantCtx())
+	assert.NoError(t, err)
+	assert.Equal(t, 6, found.FreeHours)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_TransitionSettlesCancellation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+
+	booking := &model.Booking{ID: "BK1", Customer: "Sari", Status: model.BookingCancelled, Version: 2}
+	approver := int64(5)
+	cancellation := &model.BookingCancellation{Status: model.BookingCancelled, Fare: 100000, Paid: 100000, Fee: 50000, Refund: 50000, CancelledBy: 5,
+		Refunds: []model.PaymentRefund{{PaymentID: 20, Amount: 50000, Reason: "cancellation of booking BK1", Status: model.RefundSucceeded, ApprovedBy: &approver}}}
+	change := &model.BookingStatusChange{FromStatus: model.BookingConfirmed, ToStatus: model.BookingCancelled, ChangedBy: 5, Cancellation: cancellation}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET status`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO booking_status_history`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(`INSERT INTO booking_cancellations`).
+		WithArgs("BK1", model.BookingCancelled, nil, 100000.0, 100000.0, 50000.0, 50000.0, nil, "", int64(5), sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`UPDATE payment SET refunded_amount = refunded_amount \+ \$1`).
+		WithArgs(50000.0, 20, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow("BK1"))
+	mock.ExpectQuery(`INSERT INTO payment_refunds`).
+		WithArgs(20, 50000.0, "cancellation of booking BK1", model.RefundSucceeded, false, &approver, 3, sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(31))
+	expectBookingPaymentSync(mock, "BK1", 100000.0, model.BookingPartiallyRefunded,
+		paymentRows().AddRow(20, "BK1", "Sari", nil, "", 100000.0, 50000.0, "cash", model.PaymentPartiallyRefunded, "2023-01-01", nil, nil, "", 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Transition(tenantCtx(), booking, change))
+	assert.Equal(t, 3, cancellation.ID)
+	assert.Nil(t, cancellation.FeePaymentID)
+	assert.Equal(t, 31, cancellation.Refunds[0].ID)
+	assert.Equal(t, 3, *cancellation.Refunds[0].CancellationID)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET status`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO booking_status_history`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectQuery(`INSERT INTO booking_cancellations`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectQuery(`UPDATE payment SET refunded_amount`).
+		WithArgs(50000.0, 20, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}))
+	mock.ExpectRollback()
+
+	twice := &model.BookingStatusChange{Cancellation: &model.BookingCancellation{Status: model.BookingCancelled, Fare: 100000, Paid: 100000, Fee: 50000, Refund: 50000,
+		Refunds: []model.PaymentRefund{{PaymentID: 20, Amount: 50000, Status: model.RefundSucceeded}}}}
+	assert.ErrorIs(t, repo.Transition(tenantCtx(), booking, twice), repository.ErrRefundExceedsCaptured)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE booking SET status`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO booking_status_history`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectQuery(`INSERT INTO payment`).
+		WithArgs("BK1", "Sari", nil, "", 30000.0, model.PaymentMethodCancellationFee, model.PaymentPending, int64(1)).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	unpaid := &model.BookingStatusChange{Cancellation: &model.BookingCancellation{Status: model.BookingCancelled, Fare: 60000, Fee: 30000}}
+	assert.ErrorIs(t, repo.Transition(tenantCtx(), booking, unpaid), assert.AnError)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,13 +2,14 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
 )
 
 type CarModelRepositoryInterface interface {
-	FindAll() ([]model.CarModel, error)
-	GetByID(id int) (*model.CarModel, error)
-	Create(cm *model.CarModel) error
+	FindAll(ctx context.Context) ([]model.CarModel, error)
+	GetByID(ctx context.Context, id int) (*model.CarModel, error)
+	Create(ctx context.Context, cm *model.CarModel) error
 }
 
 type CarModelRepository struct {
@@ -19,8 +20,13 @@ func NewCarModelRepository(db *sql.DB) *CarModelRepository {
 	return &CarModelRepository{DB: db}
 }
 
-func (r *CarModelRepository) FindAll() ([]model.CarModel, error) {
-	rows, err := r.DB.Query("SELECT id, model_name, created_at, updated_at FROM car_model")
+func (r *CarModelRepository) FindAll(ctx context.Context) ([]model.CarModel, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, model_name, created_at, updated_at FROM car_model WHERE tenant_id = $1", tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -37,13 +43,18 @@ func (r *CarModelRepository) FindAll() ([]model.CarModel, error) {
 	return list, nil
 }
 
-func (r *CarModelRepository) GetByID(id int) (*model.CarModel, error) {
+func (r *CarModelRepository) GetByID(ctx context.Context, id int) (*model.CarModel, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var cm model.CarModel
-	err := r.DB.QueryRow(`
+	err = r.DB.QueryRowContext(ctx, `
 		SELECT id, model_name, created_at, updated_at
 		FROM car_model
-		WHERE id=$1
-	`, id).Scan(&cm.ID, &cm.ModelName, &cm.CreatedAt, &cm.UpdatedAt)
+		WHERE id=$1 AND tenant_id=$2
+	`, id, tenantID).Scan(&cm.ID, &cm.ModelName, &cm.CreatedAt, &cm.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -55,8 +66,13 @@ func (r *CarModelRepository) GetByID(id int) (*model.CarModel, error) {
 	return &cm, nil
 }
 
-func (r *CarModelRepository) Create(cm *model.CarModel) error {
-	query := `INSERT INTO car_model (model_name, created_at, updated_at)
-	          VALUES ($1, $2, $3) RETURNING id`
-	return r.DB.QueryRow(query, cm.ModelName, cm.CreatedAt, cm.UpdatedAt).Scan(&cm.ID)
+func (r *CarModelRepository) Create(ctx context.Context, cm *model.CarModel) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO car_model (model_name, created_at, updated_at, tenant_id)
+	          VALUES ($1, $2, $3, $4) RETURNING id`
+	return r.DB.QueryRowContext(ctx, query, cm.ModelName, cm.CreatedAt, cm.UpdatedAt, tenantID).Scan(&cm.ID)
 }
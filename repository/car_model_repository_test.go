@@ -21,7 +21,7 @@ func TestCarModelRepository_FindAll_Error(t *testing.T) {
 	mock.ExpectQuery(`SELECT id, model_name, created_at, updated_at FROM car_model`).
 		WillReturnError(sql.ErrConnDone)
 
-	carModels, err := repo.FindAll()
+	carModels, err := repo.FindAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, carModels)
@@ -51,7 +51,7 @@ func TestCarModelRepository_FindAll_ScanError(t *testing.T) {
 	mock.ExpectQuery("SELECT id, model_name, created_at, updated_at FROM car_model").
 		WillReturnRows(rows)
 
-	result, err := repo.FindAll()
+	result, err := repo.FindAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -80,7 +80,7 @@ func TestCarModelRepository_FindAll_Success(t *testing.T) {
 	mock.ExpectQuery("SELECT id, model_name, created_at, updated_at FROM car_model").
 		WillReturnRows(rows)
 
-	result, err := repo.FindAll()
+	result, err := repo.FindAll(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
@@ -100,10 +100,10 @@ func TestCarModelRepository_GetByID_Success(t *testing.T) {
 		AddRow(1, "Model A", time.Now(), time.Now())
 
 	mock.ExpectQuery(`SELECT id, model_name, created_at, updated_at FROM car_model WHERE id=\$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnRows(rows)
 
-	carModel, err := repo.GetByID(id)
+	carModel, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, carModel)
@@ -124,10 +124,10 @@ func TestCarModelRepository_GetByID_NotFound(t *testing.T) {
 	id := 1
 
 	mock.ExpectQuery(`SELECT id, model_name, created_at, updated_at FROM car_model WHERE id=\$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrNoRows)
 
-	carModel, err := repo.GetByID(id)
+	carModel, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.Nil(t, carModel)
@@ -146,10 +146,10 @@ func TestCarModelRepository_GetByID_Error(t *testing.T) {
 	id := 1
 
 	mock.ExpectQuery(`SELECT id, model_name, created_at, updated_at FROM car_model WHERE id=\$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	carModel, err := repo.GetByID(id)
+	carModel, err := repo.GetByID(tenantCtx(), id)
 
 	assert.Error(t, err)
 	assert.Nil(t, carModel)
@@ -172,11 +172,11 @@ func TestCarModelRepository_Create_Success(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	mock.ExpectQuery(`INSERT INTO car_model \(model_name, created_at, updated_at\) VALUES \(\$1, \$2, \$3\) RETURNING id`).
-		WithArgs(carModel.ModelName, carModel.CreatedAt, carModel.UpdatedAt).
+	mock.ExpectQuery(`INSERT INTO car_model \(model_name, created_at, updated_at, tenant_id\) VALUES \(\$1, \$2, \$3, \$4\) RETURNING id`).
+		WithArgs(carModel.ModelName, carModel.CreatedAt, carModel.UpdatedAt, int64(1)).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
-	err = repo.Create(carModel)
+	err = repo.Create(tenantCtx(), carModel)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, carModel.ID)
@@ -198,11 +198,11 @@ func TestCarModelRepository_Create_Error(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	mock.ExpectQuery(`INSERT INTO car_model \(model_name, created_at, updated_at\) VALUES \(\$1, \$2, \$3\) RETURNING id`).
-		WithArgs(carModel.ModelName, carModel.CreatedAt, carModel.UpdatedAt).
+	mock.ExpectQuery(`INSERT INTO car_model \(model_name, created_at, updated_at, tenant_id\) VALUES \(\$1, \$2, \$3, \$4\) RETURNING id`).
+		WithArgs(carModel.ModelName, carModel.CreatedAt, carModel.UpdatedAt, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Create(carModel)
+	err = repo.Create(tenantCtx(), carModel)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -2,15 +2,20 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
+	"time"
 )
 
 type CarRepositoryInterface interface {
-	GetAll() ([]model.Car, error)
-	GetByID(id int) (*model.Car, error)
-	Create(v model.Car) error
-	Update(id int, v model.Car) error
-	Delete(id int) error
+	GetAll(ctx context.Context) ([]model.Car, error)
+	GetByID(ctx context.Context, id int) (*model.Car, error)
+	Create(ctx context.Context, v model.Car) error
+	Update(ctx context.Context, id int, v model.Car) error
+	Delete(ctx context.Context, id int, version int) error
+	GetDeleted(ctx context.Context) ([]model.Car, error)
+	Restore(ctx context.Context, id int) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 type CarRepository struct {
@@ -21,12 +26,18 @@ func NewCarRepository(db *sql.DB) *CarRepository {
 	return &CarRepository{DB: db}
 }
 
-func (r *CarRepository) GetAll() ([]model.Car, error) {
-	rows, err := r.DB.Query(`
+func (r *CarRepository) GetAll(ctx context.Context) ([]model.Car, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
 		SELECT id, brand, model, year, plate_number, capacity, color,
-		       driver_id, last_maintenance_date, current_km
+		       driver_id, last_maintenance_date, current_km, version
 		FROM vehicles
-	`)
+		WHERE tenant_id=$1 AND deleted_at IS NULL
+	`, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +58,7 @@ func (r *CarRepository) GetAll() ([]model.Car, error) {
 			&v.DriverID,
 			&v.LastMaintenanceDate,
 			&v.CurrentKM,
+			&v.Version,
 		)
 		if err != nil {
 			return nil, err
@@ -57,16 +69,21 @@ func (r *CarRepository) GetAll() ([]model.Car, error) {
 	return cars, nil
 }
 
-func (r *CarRepository) GetByID(id int) (*model.Car, error) {
+func (r *CarRepository) GetByID(ctx context.Context, id int) (*model.Car, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var v model.Car
-	err := r.DB.QueryRow(`
+	err = r.DB.QueryRowContext(ctx, `
 		SELECT id, brand, model, year, plate_number, capacity, color,
-		       driver_id, last_maintenance_date, current_km
-		FROM vehicles WHERE id=$1
-	`, id).Scan(
+		       driver_id, last_maintenance_date, current_km, version
+		FROM vehicles WHERE id=$1 AND tenant_id=$2 AND deleted_at IS NULL
+	`, id, tenantID).Scan(
 		&v.ID, &v.Brand, &v.Model, &v.Year,
 		&v.PlateNumber, &v.Capacity, &v.Color,
-		&v.DriverID, &v.LastMaintenanceDate, &v.CurrentKM,
+		&v.DriverID, &v.LastMaintenanceDate, &v.CurrentKM, &v.Version,
 	)
 
 	if err != nil {
@@ -79,37 +96,113 @@ func (r *CarRepository) GetByID(id int) (*model.Car, error) {
 	return &v, nil
 }
 
-func (r *CarRepository) Create(v model.Car) error {
-	_, err := r.DB.Exec(`
-		INSERT INTO vehicles 
-		(brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+func (r *CarRepository) Create(ctx context.Context, v model.Car) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx, `
+		INSERT INTO vehicles
+		(brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km, tenant_id)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 	`,
 		v.Brand, v.Model, v.Year, v.PlateNumber,
 		v.Capacity, v.Color, v.DriverID,
-		v.LastMaintenanceDate, v.CurrentKM,
+		v.LastMaintenanceDate, v.CurrentKM, tenantID,
 	)
 	return err
 }
 
-func (r *CarRepository) Update(id int, v model.Car) error {
-	_, err := r.DB.Exec(`
-		UPDATE vehicles SET 
+func (r *CarRepository) Update(ctx context.Context, id int, v model.Car) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE vehicles SET
 			brand=$1, model=$2, year=$3, plate_number=$4,
 			capacity=$5, color=$6, driver_id=$7,
-			last_maintenance_date=$8, current_km=$9
-		WHERE id=$10
+			last_maintenance_date=$8, current_km=$9,
+			version=version+1
+		WHERE id=$10 AND version=$11 AND tenant_id=$12 AND deleted_at IS NULL
 	`,
 		v.Brand, v.Model, v.Year, v.PlateNumber,
 		v.Capacity, v.Color, v.DriverID,
 		v.LastMaintenanceDate, v.CurrentKM,
-		id,
+		id, v.Version, tenantID,
 	)
+	return versionedRowResult(ctx, r.DB, "vehicles", "id", id, tenantID, res, err)
+}
 
-	return err
+func (r *CarRepository) Delete(ctx context.Context, id int, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE vehicles SET deleted_at=NOW(), version=version+1
+		WHERE id=$1 AND version=$2 AND tenant_id=$3 AND deleted_at IS NULL
+	`, id, version, tenantID)
+	return versionedRowResult(ctx, r.DB, "vehicles", "id", id, tenantID, res, err)
 }
 
-func (r *CarRepository) Delete(id int) error {
-	_, err := r.DB.Exec(`DELETE FROM vehicles WHERE id=$1`, id)
-	return err
+func (r *CarRepository) GetDeleted(ctx context.Context) ([]model.Car, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, brand, model, year, plate_number, capacity, color,
+		       driver_id, last_maintenance_date, current_km, version, deleted_at
+		FROM vehicles
+		WHERE tenant_id=$1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cars []model.Car
+
+	for rows.Next() {
+		var v model.Car
+		err := rows.Scan(
+			&v.ID, &v.Brand, &v.Model, &v.Year,
+			&v.PlateNumber, &v.Capacity, &v.Color,
+			&v.DriverID, &v.LastMaintenanceDate, &v.CurrentKM,
+			&v.Version, &v.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		cars = append(cars, v)
+	}
+
+	return cars, rows.Err()
+}
+
+func (r *CarRepository) Restore(ctx context.Context, id int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return restoredResult(r.DB.ExecContext(ctx, `
+		UPDATE vehicles SET deleted_at=NULL, version=version+1
+		WHERE id=$1 AND tenant_id=$2 AND deleted_at IS NOT NULL
+	`, id, tenantID))
+}
+
+// PurgeDeleted is run by the retention job and spans every tenant.
+func (r *CarRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM vehicles WHERE deleted_at IS NOT NULL AND deleted_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
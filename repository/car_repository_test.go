@@ -18,10 +18,10 @@ func TestCarRepository_GetAll_Error(t *testing.T) {
 
 	repo := repository.NewCarRepository(db)
 
-	mock.ExpectQuery(`SELECT id, brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km FROM vehicles`).
+	mock.ExpectQuery(`SELECT id, brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km, version FROM vehicles`).
 		WillReturnError(sql.ErrConnDone)
 
-	cars, err := repo.GetAll()
+	cars, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, cars)
@@ -49,6 +49,7 @@ func TestCarRepository_GetAll_ScanError(t *testing.T) {
 		"driver_id",
 		"last_maintenance_date",
 		"current_km",
+		"version",
 	}).AddRow(
 		1,
 		"Toyota",
@@ -60,12 +61,13 @@ func TestCarRepository_GetAll_ScanError(t *testing.T) {
 		1,
 		time.Now(),
 		10000,
+		1,
 	)
 
 	mock.ExpectQuery(`FROM vehicles`).
 		WillReturnRows(rows)
 
-	result, err := repo.GetAll()
+	result, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -81,14 +83,14 @@ func TestCarRepository_GetByID_Success(t *testing.T) {
 
 	id := 1
 	lastMaintenance := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "brand", "model", "year", "plate_number", "capacity", "color", "driver_id", "last_maintenance_date", "current_km"}).
-		AddRow(1, "Toyota", "Camry", 2020, "ABC123", 5, "Blue", 1, lastMaintenance, 10000)
+	rows := sqlmock.NewRows([]string{"id", "brand", "model", "year", "plate_number", "capacity", "color", "driver_id", "last_maintenance_date", "current_km", "version"}).
+		AddRow(1, "Toyota", "Camry", 2020, "ABC123", 5, "Blue", 1, lastMaintenance, 10000, 3)
 
-	mock.ExpectQuery(`SELECT id, brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km FROM vehicles WHERE id=\$1`).
-		WithArgs(id).
+	mock.ExpectQuery(`SELECT id, brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km, version FROM vehicles WHERE id=\$1`).
+		WithArgs(id, int64(1)).
 		WillReturnRows(rows)
 
-	car, err := repo.GetByID(id)
+	car, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, car)
@@ -102,6 +104,7 @@ func TestCarRepository_GetByID_Success(t *testing.T) {
 	assert.Equal(t, 1, car.DriverID)
 	assert.Equal(t, lastMaintenance, *car.LastMaintenanceDate)
 	assert.Equal(t, 10000, car.CurrentKM)
+	assert.Equal(t, 3, car.Version)
 
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
@@ -116,11 +119,11 @@ func TestCarRepository_GetByID_NotFound(t *testing.T) {
 
 	id := 1
 
-	mock.ExpectQuery(`SELECT id, brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km FROM vehicles WHERE id=\$1`).
-		WithArgs(id).
+	mock.ExpectQuery(`SELECT id, brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km, version FROM vehicles WHERE id=\$1`).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrNoRows)
 
-	car, err := repo.GetByID(id)
+	car, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.Nil(t, car)
@@ -138,11 +141,11 @@ func TestCarRepository_GetByID_Error(t *testing.T) {
 
 	id := 1
 
-	mock.ExpectQuery(`SELECT id, brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km FROM vehicles WHERE id=\$1`).
-		WithArgs(id).
+	mock.ExpectQuery(`SELECT id, brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km, version FROM vehicles WHERE id=\$1`).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	car, err := repo.GetByID(id)
+	car, err := repo.GetByID(tenantCtx(), id)
 
 	assert.Error(t, err)
 	assert.Nil(t, car)
@@ -170,13 +173,14 @@ func TestCarRepository_Create_Success(t *testing.T) {
 		DriverID:            1,
 		LastMaintenanceDate: &lastMaintenance,
 		CurrentKM:           10000,
+		Version:             2,
 	}
 
-	mock.ExpectExec(`INSERT INTO vehicles \(brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9\)`).
-		WithArgs(car.Brand, car.Model, car.Year, car.PlateNumber, car.Capacity, car.Color, car.DriverID, car.LastMaintenanceDate, car.CurrentKM).
+	mock.ExpectExec(`INSERT INTO vehicles \(brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km, tenant_id\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10\)`).
+		WithArgs(car.Brand, car.Model, car.Year, car.PlateNumber, car.Capacity, car.Color, car.DriverID, car.LastMaintenanceDate, car.CurrentKM, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Create(car)
+	err = repo.Create(tenantCtx(), car)
 
 	assert.NoError(t, err)
 
@@ -202,13 +206,14 @@ func TestCarRepository_Create_Error(t *testing.T) {
 		DriverID:            1,
 		LastMaintenanceDate: &lastMaintenance,
 		CurrentKM:           10000,
+		Version:             2,
 	}
 
-	mock.ExpectExec(`INSERT INTO vehicles \(brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9\)`).
-		WithArgs(car.Brand, car.Model, car.Year, car.PlateNumber, car.Capacity, car.Color, car.DriverID, car.LastMaintenanceDate, car.CurrentKM).
+	mock.ExpectExec(`INSERT INTO vehicles \(brand, model, year, plate_number, capacity, color, driver_id, last_maintenance_date, current_km, tenant_id\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10\)`).
+		WithArgs(car.Brand, car.Model, car.Year, car.PlateNumber, car.Capacity, car.Color, car.DriverID, car.LastMaintenanceDate, car.CurrentKM, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Create(car)
+	err = repo.Create(tenantCtx(), car)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -236,13 +241,14 @@ func TestCarRepository_Update_Success(t *testing.T) {
 		DriverID:            1,
 		LastMaintenanceDate: &lastMaintenance,
 		CurrentKM:           10000,
+		Version:             2,
 	}
 
-	mock.ExpectExec(`UPDATE vehicles SET brand=\$1, model=\$2, year=\$3, plate_number=\$4, capacity=\$5, color=\$6, driver_id=\$7, last_maintenance_date=\$8, current_km=\$9 WHERE id=\$10`).
-		WithArgs(car.Brand, car.Model, car.Year, car.PlateNumber, car.Capacity, car.Color, car.DriverID, car.LastMaintenanceDate, car.CurrentKM, id).
+	mock.ExpectExec(`UPDATE vehicles SET brand=\$1, model=\$2, year=\$3, plate_number=\$4, capacity=\$5, color=\$6, driver_id=\$7, last_maintenance_date=\$8, current_km=\$9, version=version\+1 WHERE id=\$10 AND version=\$11`).
+		WithArgs(car.Brand, car.Model, car.Year, car.PlateNumber, car.Capacity, car.Color, car.DriverID, car.LastMaintenanceDate, car.CurrentKM, id, car.Version, int64(1)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Update(id, car)
+	err = repo.Update(tenantCtx(), id, car)
 
 	assert.NoError(t, err)
 
@@ -269,13 +275,14 @@ func TestCarRepository_Update_Error(t *testing.T) {
 		DriverID:            1,
 		LastMaintenanceDate: &lastMaintenance,
 		CurrentKM:           10000,
+		Version:             2,
 	}
 
-	mock.ExpectExec(`UPDATE vehicles SET brand=\$1, model=\$2, year=\$3, plate_number=\$4, capacity=\$5, color=\$6, driver_id=\$7, last_maintenance_date=\$8, current_km=\$9 WHERE id=\$10`).
-		WithArgs(car.Brand, car.Model, car.Year, car.PlateNumber, car.Capacity, car.Color, car.DriverID, car.LastMaintenanceDate, car.CurrentKM, id).
+	mock.ExpectExec(`UPDATE vehicles SET brand=\$1, model=\$2, year=\$3, plate_number=\$4, capacity=\$5, color=\$6, driver_id=\$7, last_maintenance_date=\$8, current_km=\$9, version=version\+1 WHERE id=\$10 AND version=\$11`).
+		WithArgs(car.Brand, car.Model, car.Year, car.PlateNumber, car.Capacity, car.Color, car.DriverID, car.LastMaintenanceDate, car.CurrentKM, id, car.Version, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Update(id, car)
+	err = repo.Update(tenantCtx(), id, car)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -293,11 +300,11 @@ func TestCarRepository_Delete_Success(t *testing.T) {
 
 	id := 1
 
-	mock.ExpectExec(`DELETE FROM vehicles WHERE id=\$1`).
-		WithArgs(id).
+	mock.ExpectExec(`UPDATE vehicles SET deleted_at=NOW\(\), version=version\+1 WHERE id=\$1 AND version=\$2 AND tenant_id=\$3 AND deleted_at IS NULL`).
+		WithArgs(id, 2, int64(1)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id, 2)
 
 	assert.NoError(t, err)
 
@@ -314,11 +321,11 @@ func TestCarRepository_Delete_Error(t *testing.T) {
 
 	id := 1
 
-	mock.ExpectExec(`DELETE FROM vehicles WHERE id=\$1`).
-		WithArgs(id).
+	mock.ExpectExec(`UPDATE vehicles SET deleted_at=NOW\(\), version=version\+1 WHERE id=\$1 AND version=\$2 AND tenant_id=\$3 AND deleted_at IS NULL`).
+		WithArgs(id, 2, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id, 2)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -345,6 +352,7 @@ func TestCarRepository_GetAll_Success(t *testing.T) {
 		"driver_id",
 		"last_maintenance_date",
 		"current_km",
+		"version",
 	}).AddRow(
 		1,
 		"Toyota",
@@ -356,15 +364,83 @@ func TestCarRepository_GetAll_Success(t *testing.T) {
 		1,
 		time.Now(),
 		15000,
+		1,
 	)
 
 	mock.ExpectQuery("FROM vehicles").
 		WillReturnRows(rows)
 
-	result, err := repo.GetAll()
+	result, err := repo.GetAll(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
 	assert.Equal(t, "Toyota", result[0].Brand)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestCarRepository_Update_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCarRepository(db)
+
+	mock.ExpectExec(`UPDATE vehicles SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "vehicles", "id", 1, true)
+
+	err = repo.Update(tenantCtx(), 1, model.Car{Brand: "Toyota", Version: 1})
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+	mock.ExpectExec(`UPDATE vehicles SET deleted_at=NOW\(\), version=version\+1 WHERE id=\$1 AND version=\$2 AND tenant_id=\$3 AND deleted_at IS NULL`).
+		WithArgs(1, 1, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "vehicles", "id", 1, true)
+
+	err = repo.Delete(tenantCtx(), 1, 1)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+	mock.ExpectExec(`UPDATE vehicles SET deleted_at`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "vehicles", "id", 404, false)
+
+	err = repo.Delete(tenantCtx(), 404, 1)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCarRepository_Trash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCarRepository(db)
+	deletedAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "brand", "model", "year", "plate_number", "capacity", "color", "driver_id", "last_maintenance_date", "current_km", "version", "deleted_at"}).
+		AddRow(1, "Toyota", "Camry", 2020, "ABC123", 5, "Blue", 1, nil, 10000, 2, deletedAt)
+
+	mock.ExpectQuery(`FROM vehicles WHERE tenant_id=\$1 AND deleted_at IS NOT NULL`).WillReturnRows(rows)
+
+	cars, err := repo.GetDeleted(tenantCtx())
+	assert.NoError(t, err)
+	assert.Len(t, cars, 1)
+	assert.Equal(t, deletedAt, *cars[0].DeletedAt)
+
+	mock.ExpectExec(`UPDATE vehicles SET deleted_at=NULL`).WithArgs(1, int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Restore(tenantCtx(), 1))
+
+	mock.ExpectExec(`UPDATE vehicles SET deleted_at=NULL`).WithArgs(2, int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Restore(tenantCtx(), 2), repository.ErrNotFound)
+
+	mock.ExpectExec(`DELETE FROM vehicles WHERE deleted_at IS NOT NULL AND deleted_at <= \$1`).
+		WithArgs(deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := repo.PurgeDeleted(tenantCtx(), deletedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,13 +2,14 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
 )
 
 type CarTypeRepositoryInterface interface {
-	FindAll() ([]model.CarType, error)
-	GetByID(id int) (*model.CarType, error)
-	Create(ct model.CarType) error
+	FindAll(ctx context.Context) ([]model.CarType, error)
+	GetByID(ctx context.Context, id int) (*model.CarType, error)
+	Create(ctx context.Context, ct model.CarType) error
 }
 
 type CarTypeRepository struct {
@@ -19,8 +20,13 @@ func NewCarTypeRepository(db *sql.DB) *CarTypeRepository {
 	return &CarTypeRepository{DB: db}
 }
 
-func (r *CarTypeRepository) FindAll() ([]model.CarType, error) {
-	rows, err := r.DB.Query("SELECT id, type_name FROM car_type")
+func (r *CarTypeRepository) FindAll(ctx context.Context) ([]model.CarType, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, type_name FROM car_type WHERE tenant_id = $1", tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -37,13 +43,18 @@ func (r *CarTypeRepository) FindAll() ([]model.CarType, error) {
 	return list, nil
 }
 
-func (r *CarTypeRepository) GetByID(id int) (*model.CarType, error) {
+func (r *CarTypeRepository) GetByID(ctx context.Context, id int) (*model.CarType, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var cm model.CarType
-	err := r.DB.QueryRow(`
+	err = r.DB.QueryRowContext(ctx, `
 		SELECT id, type_name
 		FROM car_type
-		WHERE id=$1
-	`, id).Scan(&cm.ID, &cm.TypeName)
+		WHERE id=$1 AND tenant_id=$2
+	`, id, tenantID).Scan(&cm.ID, &cm.TypeName)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -55,10 +66,15 @@ func (r *CarTypeRepository) GetByID(id int) (*model.CarType, error) {
 	return &cm, nil
 }
 
-func (r *CarTypeRepository) Create(ct model.CarType) error {
-	_, err := r.DB.Exec(
-		"INSERT INTO car_type (type_name) VALUES ($1)",
-		ct.TypeName,
+func (r *CarTypeRepository) Create(ctx context.Context, ct model.CarType) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx,
+		"INSERT INTO car_type (type_name, tenant_id) VALUES ($1, $2)",
+		ct.TypeName, tenantID,
 	)
 	return err
 }
@@ -25,7 +25,7 @@ func TestCarTypeRepository_FindAll_Success(t *testing.T) {
 	mock.ExpectQuery(`SELECT id, type_name FROM car_type`).
 		WillReturnRows(rows)
 
-	carTypes, err := repo.FindAll()
+	carTypes, err := repo.FindAll(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, carTypes, 2)
@@ -48,7 +48,7 @@ func TestCarTypeRepository_FindAll_Error(t *testing.T) {
 	mock.ExpectQuery(`SELECT id, type_name FROM car_type`).
 		WillReturnError(sql.ErrConnDone)
 
-	carTypes, err := repo.FindAll()
+	carTypes, err := repo.FindAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, carTypes)
@@ -70,10 +70,10 @@ func TestCarTypeRepository_GetByID_Success(t *testing.T) {
 		AddRow(1, "Sedan")
 
 	mock.ExpectQuery(`SELECT id, type_name FROM car_type WHERE id=\$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnRows(rows)
 
-	carType, err := repo.GetByID(id)
+	carType, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, carType)
@@ -94,10 +94,10 @@ func TestCarTypeRepository_GetByID_NotFound(t *testing.T) {
 	id := 1
 
 	mock.ExpectQuery(`SELECT id, type_name FROM car_type WHERE id=\$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrNoRows)
 
-	carType, err := repo.GetByID(id)
+	carType, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.Nil(t, carType)
@@ -116,10 +116,10 @@ func TestCarTypeRepository_GetByID_Error(t *testing.T) {
 	id := 1
 
 	mock.ExpectQuery(`SELECT id, type_name FROM car_type WHERE id=\$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	carType, err := repo.GetByID(id)
+	carType, err := repo.GetByID(tenantCtx(), id)
 
 	assert.Error(t, err)
 	assert.Nil(t, carType)
@@ -140,11 +140,11 @@ func TestCarTypeRepository_Create_Success(t *testing.T) {
 		TypeName: "Sedan",
 	}
 
-	mock.ExpectExec(`INSERT INTO car_type \(type_name\) VALUES \(\$1\)`).
-		WithArgs(carType.TypeName).
+	mock.ExpectExec(`INSERT INTO car_type \(type_name, tenant_id\) VALUES \(\$1, \$2\)`).
+		WithArgs(carType.TypeName, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Create(carType)
+	err = repo.Create(tenantCtx(), carType)
 
 	assert.NoError(t, err)
 
@@ -163,11 +163,11 @@ func TestCarTypeRepository_Create_Error(t *testing.T) {
 		TypeName: "Sedan",
 	}
 
-	mock.ExpectExec(`INSERT INTO car_type \(type_name\) VALUES \(\$1\)`).
-		WithArgs(carType.TypeName).
+	mock.ExpectExec(`INSERT INTO car_type \(type_name, tenant_id\) VALUES \(\$1, \$2\)`).
+		WithArgs(carType.TypeName, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Create(carType)
+	err = repo.Create(tenantCtx(), carType)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -186,7 +186,7 @@ func TestCarTypeRepository_FindAll_QueryError(t *testing.T) {
 	mock.ExpectQuery("SELECT id, type_name FROM car_type").
 		WillReturnError(errors.New("db error"))
 
-	result, err := repo.FindAll()
+	result, err := repo.FindAll(tenantCtx())
 
 	assert.Nil(t, result)
 	assert.Error(t, err)
@@ -206,7 +206,7 @@ func TestCarTypeRepository_FindAll_ScanError(t *testing.T) {
 	mock.ExpectQuery(`SELECT id, type_name FROM car_type`).
 		WillReturnRows(rows)
 
-	result, err := repo.FindAll()
+	result, err := repo.FindAll(tenantCtx())
 
 	assert.Nil(t, result)
 	assert.Error(t, err)
@@ -1,24 +1,30 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 
 	"auth-service/model"
 )
 
 type PopularDestinationRepositoryInterface interface {
-	GetAll() ([]model.PopularDestination, error)
-	Add(pd model.PopularDestination) (*model.PopularDestination, error)
-	UpdateBookings(id int, bookings int) error
-	Delete(id int) error
+	GetAll(ctx context.Context) ([]model.PopularDestination, error)
+	Add(ctx context.Context, pd model.PopularDestination) (*model.PopularDestination, error)
+	UpdateBookings(ctx context.Context, id int, bookings int) error
+	Delete(ctx context.Context, id int) error
 }
 
 type PopularDestinationRepository struct {
 	DB *sql.DB
 }
 
-func (r *PopularDestinationRepository) GetAll() ([]model.PopularDestination, error) {
-	rows, err := r.DB.Query("SELECT id, destination, bookings, created_at FROM popular_destinations ORDER BY bookings DESC")
+func (r *PopularDestinationRepository) GetAll(ctx context.Context) ([]model.PopularDestination, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, destination, bookings, created_at FROM popular_destinations WHERE tenant_id = $1 ORDER BY bookings DESC", tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -35,10 +41,15 @@ func (r *PopularDestinationRepository) GetAll() ([]model.PopularDestination, err
 	return result, nil
 }
 
-func (r *PopularDestinationRepository) Add(pd model.PopularDestination) (*model.PopularDestination, error) {
-	err := r.DB.QueryRow(
-		"INSERT INTO popular_destinations (destination, bookings) VALUES ($1, $2) RETURNING id, created_at",
-		pd.Destination, pd.Bookings,
+func (r *PopularDestinationRepository) Add(ctx context.Context, pd model.PopularDestination) (*model.PopularDestination, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB.QueryRowContext(ctx,
+		"INSERT INTO popular_destinations (destination, bookings, tenant_id) VALUES ($1, $2, $3) RETURNING id, created_at",
+		pd.Destination, pd.Bookings, tenantID,
 	).Scan(&pd.ID, &pd.CreatedAt)
 	if err != nil {
 		return nil, err
@@ -46,12 +57,22 @@ func (r *PopularDestinationRepository) Add(pd model.PopularDestination) (*model.
 	return &pd, nil
 }
 
-func (r *PopularDestinationRepository) UpdateBookings(id int, bookings int) error {
-	_, err := r.DB.Exec("UPDATE popular_destinations SET bookings = $1 WHERE id = $2", bookings, id)
+func (r *PopularDestinationRepository) UpdateBookings(ctx context.Context, id int, bookings int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx, "UPDATE popular_destinations SET bookings = $1 WHERE id = $2 AND tenant_id = $3", bookings, id, tenantID)
 	return err
 }
 
-func (r *PopularDestinationRepository) Delete(id int) error {
-	_, err := r.DB.Exec("DELETE FROM popular_destinations WHERE id = $1", id)
+func (r *PopularDestinationRepository) Delete(ctx context.Context, id int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx, "DELETE FROM popular_destinations WHERE id = $1 AND tenant_id = $2", id, tenantID)
 	return err
 }
@@ -22,10 +22,10 @@ func TestPopularDestinationRepository_GetAll_Success(t *testing.T) {
 		AddRow(1, "Jakarta", 100, time.Now()).
 		AddRow(2, "Bandung", 80, time.Now())
 
-	mock.ExpectQuery(`SELECT id, destination, bookings, created_at FROM popular_destinations ORDER BY bookings DESC`).
+	mock.ExpectQuery(`SELECT id, destination, bookings, created_at FROM popular_destinations WHERE tenant_id = \$1 ORDER BY bookings DESC`).
 		WillReturnRows(rows)
 
-	destinations, err := repo.GetAll()
+	destinations, err := repo.GetAll(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, destinations, 2)
@@ -47,10 +47,10 @@ func TestPopularDestinationRepository_GetAll_Error(t *testing.T) {
 
 	repo := repository.PopularDestinationRepository{DB: db}
 
-	mock.ExpectQuery(`SELECT id, destination, bookings, created_at FROM popular_destinations ORDER BY bookings DESC`).
+	mock.ExpectQuery(`SELECT id, destination, bookings, created_at FROM popular_destinations WHERE tenant_id = \$1 ORDER BY bookings DESC`).
 		WillReturnError(sql.ErrConnDone)
 
-	destinations, err := repo.GetAll()
+	destinations, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, destinations)
@@ -72,11 +72,11 @@ func TestPopularDestinationRepository_Add_Success(t *testing.T) {
 		Bookings:    100,
 	}
 
-	mock.ExpectQuery(`INSERT INTO popular_destinations \(destination, bookings\) VALUES \(\$1, \$2\) RETURNING id, created_at`).
-		WithArgs(pd.Destination, pd.Bookings).
+	mock.ExpectQuery(`INSERT INTO popular_destinations \(destination, bookings, tenant_id\) VALUES \(\$1, \$2, \$3\) RETURNING id, created_at`).
+		WithArgs(pd.Destination, pd.Bookings, int64(1)).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
 
-	result, err := repo.Add(pd)
+	result, err := repo.Add(tenantCtx(), pd)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -100,11 +100,11 @@ func TestPopularDestinationRepository_Add_Error(t *testing.T) {
 		Bookings:    100,
 	}
 
-	mock.ExpectQuery(`INSERT INTO popular_destinations \(destination, bookings\) VALUES \(\$1, \$2\) RETURNING id, created_at`).
-		WithArgs(pd.Destination, pd.Bookings).
+	mock.ExpectQuery(`INSERT INTO popular_destinations \(destination, bookings, tenant_id\) VALUES \(\$1, \$2, \$3\) RETURNING id, created_at`).
+		WithArgs(pd.Destination, pd.Bookings, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	result, err := repo.Add(pd)
+	result, err := repo.Add(tenantCtx(), pd)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -125,10 +125,10 @@ func TestPopularDestinationRepository_UpdateBookings_Success(t *testing.T) {
 	bookings := 150
 
 	mock.ExpectExec(`UPDATE popular_destinations SET bookings = \$1 WHERE id = \$2`).
-		WithArgs(bookings, id).
+		WithArgs(bookings, id, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.UpdateBookings(id, bookings)
+	err = repo.UpdateBookings(tenantCtx(), id, bookings)
 
 	assert.NoError(t, err)
 
@@ -147,10 +147,10 @@ func TestPopularDestinationRepository_UpdateBookings_Error(t *testing.T) {
 	bookings := 150
 
 	mock.ExpectExec(`UPDATE popular_destinations SET bookings = \$1 WHERE id = \$2`).
-		WithArgs(bookings, id).
+		WithArgs(bookings, id, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.UpdateBookings(id, bookings)
+	err = repo.UpdateBookings(tenantCtx(), id, bookings)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -169,10 +169,10 @@ func TestPopularDestinationRepository_Delete_Success(t *testing.T) {
 	id := 1
 
 	mock.ExpectExec(`DELETE FROM popular_destinations WHERE id = \$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id)
 
 	assert.NoError(t, err)
 
@@ -190,10 +190,10 @@ func TestPopularDestinationRepository_Delete_Error(t *testing.T) {
 	id := 1
 
 	mock.ExpectExec(`DELETE FROM popular_destinations WHERE id = \$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -224,7 +224,7 @@ func TestPopularDestinationRepository_GetAll_ScanError(t *testing.T) {
 	mock.ExpectQuery(`SELECT id, destination, bookings, created_at FROM popular_destinations`).
 		WillReturnRows(rows)
 
-	result, err := repo.GetAll()
+	result, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrInvoiceOverpaid = errors.New("payment exceeds the amount outstanding on the invoice")
+
+type CorporateRepositoryInterface interface {
+	GetAccounts(ctx context.Context) ([]model.CorporateAccount, error)
+	GetAccount(ctx context.Context, id int) (*model.CorporateAccount, error)
+	CreateAccount(ctx context.Context, a *model.CorporateAccount) error
+	UpdateAccount(ctx context.Context, a *model.CorporateAccount) error
+	GetBalance(ctx context.Context, accountID int) (*model.AccountBalance, error)
+	GetBillable(ctx context.Context) ([]model.CorporateAccount, error)
+	GetUninvoiced(ctx context.Context, accountID int, before time.Time) ([]model.InvoiceLine, error)
+	CreateInvoice(ctx context.Context, inv *model.CorporateInvoice) error
+	GetInvoices(ctx context.Context, accountID int) ([]model.CorporateInvoice, error)
+	GetInvoice(ctx context.Context, id int) (*model.CorporateInvoice, error)
+	AddInvoicePayment(ctx context.Context, p *model.InvoicePayment) error
+}
+
+type CorporateRepository struct {
+	DB *sql.DB
+}
+
+func NewCorporateRepository(db *sql.DB) *CorporateRepository {
+	return &CorporateRepository{DB: db}
+}
+
+func normalizeAccount(a *model.CorporateAccount) {
+	a.Name = strings.TrimSpace(a.Name)
+	if a.BookerIDs == nil {
+		a.BookerIDs = []int{}
+	}
+	if a.CostCentres == nil {
+		a.CostCentres = []string{}
+	}
+}
+
+// invoiceDescription names the journey billed on an invoice line.
+func invoiceDescription(place string, pickup, drop *string) string {
+	if pickup != nil && drop != nil && *pickup != "" && *drop != "" {
+		return *pickup + " - " + *drop
+	}
+	if drop != nil && *drop != "" {
+		return *drop
+	}
+	return place
+}
+
+const accountColumns = `id, name, billing_email, billing_address, tax_id, booker_ids, cost_centres, credit_limit, payment_terms_days, active, created_at, updated_at, version, tenant_id`
+
+func (r *CorporateRepository) queryAccounts(ctx context.Context, where string, args ...any) ([]model.CorporateAccount, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+accountColumns+` FROM corporate_accounts WHERE `+where+` ORDER BY name, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []model.CorporateAccount{}
+	for rows.Next() {
+		var a model.CorporateAccount
+		var bookers pq.Int64Array
+		if err := rows.Scan(&a.ID, &a.Name, &a.BillingEmail, &a.BillingAddress, &a.TaxID, &bookers, pq.Array(&a.CostCentres),
+			&a.CreditLimit, &a.PaymentTermsDays, &a.Active, &a.CreatedAt, &a.UpdatedAt, &a.Version, &a.TenantID); err != nil {
+			return nil, err
+		}
+		for _, id := range bookers {
+			a.BookerIDs = append(a.BookerIDs, int(id))
+		}
+		normalizeAccount(&a)
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *CorporateRepository) GetAccounts(ctx context.Context) ([]model.CorporateAccount, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryAccounts(ctx, `tenant_id = $1`, tenantID)
+}
+
+func (r *CorporateRepository) GetAccount(ctx context.Context, id int) (*model.CorporateAccount, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := r.queryAccounts(ctx, `id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil || len(accounts) == 0 {
+		return nil, err
+	}
+	return &accounts[0], nil
+}
+
+func (r *CorporateRepository) CreateAccount(ctx context.Context, a *model.CorporateAccount) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeAccount(a)
+
+	err = r.DB.QueryRowContext(ctx,
+		`INSERT INTO corporate_accounts (name, billing_email, billing_address, tax_id, booker_ids, cost_centres, credit_limit, payment_terms_days, active, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING id, created_at, updated_at`,
+		a.Name, a.BillingEmail, a.BillingAddress, a.TaxID, pq.Array(a.BookerIDs), pq.Array(a.CostCentres), a.CreditLimit, a.PaymentTermsDays, a.Active, tenantID,
+	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicateKey
+	}
+	if err != nil {
+		return err
+	}
+
+	a.Version = 1
+	a.TenantID = tenantID
+	return nil
+}
+
+func (r *CorporateRepository) UpdateAccount(ctx context.Context, a *model.CorporateAccount) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeAccount(a)
+	a.UpdatedAt = time.Now()
+
+	res, err := r.DB.ExecContext(ctx,
+		`UPDATE corporate_accounts SET name = $1, billing_email = $2, billing_address = $3, tax_id = $4, booker_ids = $5, cost_centres = $6,
+			credit_limit = $7, payment_terms_days = $8, active = $9, updated_at = $10, version = version + 1
+		WHERE id = $11 AND version = $12 AND tenant_id = $13`,
+		a.Name, a.BillingEmail, a.BillingAddress, a.TaxID, pq.Array(a.BookerIDs), pq.Array(a.CostCentres),
+		a.CreditLimit, a.PaymentTermsDays, a.Active, a.UpdatedAt, a.ID, a.Version, tenantID,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicateKey
+	}
+	if err := versionedResult(res, err); err != nil {
+		return err
+	}
+
+	a.Version++
+	return nil
+}
+
+// GetBalance adds up the bookings charged to the account that are not yet on
+// an invoice, leaving out cancelled ones, and the unpaid part of its invoices.
+func (r *CorporateRepository) GetBalance(ctx context.Context, accountID int) (*model.AccountBalance, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b := model.AccountBalance{AccountID: accountID}
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT
+			(SELECT COALESCE(SUM(COALESCE(amount, 0)), 0) FROM booking b
+				WHERE b.tenant_id = $1 AND b.corporate_account_id = $2 AND b.deleted_at IS NULL AND b.status NOT IN ($3, $4)
+				AND NOT EXISTS (SELECT 1 FROM corporate_invoice_lines l WHERE l.booking_id = b.id)),
+			(SELECT COALESCE(SUM(total - amount_paid), 0) FROM corporate_invoices
+				WHERE tenant_id = $1 AND account_id = $2 AND status <> $5)`,
+		tenantID, accountID, model.BookingCancelled, model.BookingNoShow, model.InvoiceVoid,
+	).Scan(&b.Unbilled, &b.Outstanding)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetBillable is run by the invoice scheduler and spans every tenant. It
+// returns the active accounts.
+func (r *CorporateRepository) GetBillable(ctx context.Context) ([]model.CorporateAccount, error) {
+	return r.queryAccounts(ctx, `active`)
+}
+
+// GetUninvoiced prices the completed bookings charged to the account that
+// finished before the given time and are not on an invoice yet. A booking is
+// billed at the fare of its last completed trip, falling back to the booked
+// amount.
+func (r *CorporateRepository) GetUninvoiced(ctx context.Context, accountID int, before time.Time) ([]model.InvoiceLine, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT b.id, COALESCE(t.ended_at, b.start_at, b.created_at), b.customer, b.place, b.pickup_location, b.drop_location,
+			COALESCE(b.cost_centre, ''), COALESCE(t.distance_km::numeric, b.distance_km), COALESCE(t.amount, b.amount, 0)
+		FROM booking b
+		LEFT JOIN LATERAL (
+			SELECT ended_at, distance_km, amount FROM trips
+			WHERE booking_id = b.id AND status = $4
+			ORDER BY ended_at DESC NULLS LAST, id DESC LIMIT 1
+		) t ON TRUE
+		WHERE b.tenant_id = $1 AND b.corporate_account_id = $2 AND b.status = $5 AND b.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM corporate_invoice_lines l WHERE l.booking_id = b.id)
+			AND COALESCE(t.ended_at, b.start_at, b.created_at) < $3
+		ORDER BY 2, b.id`,
+		tenantID, accountID, before, model.TripCompleted, model.BookingCompleted,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []model.InvoiceLine{}
+	for rows.Next() {
+		var l model.InvoiceLine
+		var place string
+		var pickup, drop *string
+		if err := rows.Scan(&l.BookingID, &l.TripDate, &l.Booker, &place, &pickup, &drop, &l.CostCentre, &l.DistanceKM, &l.Amount); err != nil {
+			return nil, err
+		}
+		l.Description = invoiceDescription(place, pickup, drop)
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// CreateInvoice saves the invoice with its lines. A second invoice for the
+// same account and period, or a booking already on another invoice, is
+// reported as ErrDuplicateKey.
+func (r *CorporateRepository) CreateInvoice(ctx context.Context, inv *model.CorporateInvoice) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO corporate_invoices (account_id, number, period_start, period_end, total, amount_paid, status, issued_at, due_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $7, $8, $9)
+		RETURNING id`,
+		inv.AccountID, inv.Number, inv.PeriodStart, inv.PeriodEnd, inv.Total, inv.Status, inv.IssuedAt, inv.DueAt, tenantID,
+	).Scan(&inv.ID)
+	if err != nil {
+		return invoiceError(err)
+	}
+
+	for i := range inv.Lines {
+		l := &inv.Lines[i]
+		l.InvoiceID = inv.ID
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO corporate_invoice_lines (invoice_id, booking_id, trip_date, description, booker, cost_centre, distance_km, amount, tenant_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id`,
+			inv.ID, l.BookingID, l.TripDate, l.Description, l.Booker, l.CostCentre, l.DistanceKM, l.Amount, tenantID,
+		).Scan(&l.ID)
+		if err != nil {
+			return invoiceError(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func invoiceError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+const invoiceColumns = `i.id, i.account_id, a.name, i.number, i.period_start, i.period_end, i.total, i.amount_paid, i.status, i.issued_at, i.due_at`
+
+func scanInvoice(scan func(dest ...any) error, inv *model.CorporateInvoice) error {
+	return scan(&inv.ID, &inv.AccountID, &inv.AccountName, &inv.Number, &inv.PeriodStart, &inv.PeriodEnd,
+		&inv.Total, &inv.AmountPaid, &inv.Status, &inv.IssuedAt, &inv.DueAt)
+}
+
+func (r *CorporateRepository) GetInvoices(ctx context.Context, accountID int) ([]model.CorporateInvoice, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT `+invoiceColumns+` FROM corporate_invoices i JOIN corporate_accounts a ON a.id = i.account_id
+		WHERE i.tenant_id = $1 AND i.account_id = $2 ORDER BY i.period_start DESC, i.id DESC`,
+		tenantID, accountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []model.CorporateInvoice{}
+	for rows.Next() {
+		var inv model.CorporateInvoice
+		if err := scanInvoice(rows.Scan, &inv); err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+	return invoices, rows.Err()
+}
+
+// GetInvoice returns the invoice with its lines and the payments recorded
+// against it.
+func (r *CorporateRepository) GetInvoice(ctx context.Context, id int) (*model.CorporateInvoice, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var inv model.CorporateInvoice
+	err = scanInvoice(r.DB.QueryRowContext(ctx,
+		`SELECT `+invoiceColumns+` FROM corporate_invoices i JOIN corporate_accounts a ON a.id = i.account_id
+		WHERE i.id = $1 AND i.tenant_id = $2`,
+		id, tenantID,
+	).Scan, &inv)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := r.DB.QueryContext(ctx,
+		`SELECT id, booking_id, trip_date, description, booker, cost_centre, distance_km, amount
+		FROM corporate_invoice_lines WHERE invoice_id = $1 AND tenant_id = $2 ORDER BY trip_date, id`,
+		id, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer lines.Close()
+
+	inv.Lines = []model.InvoiceLine{}
+	for lines.Next() {
+		l := model.InvoiceLine{InvoiceID: id}
+		if err := lines.Scan(&l.ID, &l.BookingID, &l.TripDate, &l.Description, &l.Booker, &l.CostCentre, &l.DistanceKM, &l.Amount); err != nil {
+			return nil, err
+		}
+		inv.Lines = append(inv.Lines, l)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+
+	payments, err := r.DB.QueryContext(ctx,
+		`SELECT id, invoice_id, amount, method, reference, paid_at, recorded_by
+		FROM corporate_invoice_payments WHERE invoice_id = $1 AND tenant_id = $2 ORDER BY paid_at, id`,
+		id, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer payments.Close()
+
+	inv.Payments = []model.InvoicePayment{}
+	for payments.Next() {
+		var p model.InvoicePayment
+		if err := payments.Scan(&p.ID, &p.InvoiceID, &p.Amount, &p.Method, &p.Reference, &p.PaidAt, &p.RecordedBy); err != nil {
+			return nil, err
+		}
+		inv.Payments = append(inv.Payments, p)
+	}
+	return &inv, payments.Err()
+}
+
+// AddInvoicePayment records a payment and moves the invoice to partially_paid
+// or paid. A payment larger than what is left to pay, or against a void
+// invoice, fails with ErrInvoiceOverpaid.
+func (r *CorporateRepository) AddInvoicePayment(ctx context.Context, p *model.InvoicePayment) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE corporate_invoices SET amount_paid = amount_paid + $1,
+			status = CASE WHEN amount_paid + $1 >= total THEN $2 ELSE $3 END
+		WHERE id = $4 AND tenant_id = $5 AND status <> $6 AND amount_paid + $1 <= total`,
+		p.Amount, model.InvoicePaid, model.InvoicePartiallyPaid, p.InvoiceID, tenantID, model.InvoiceVoid,
+	)
+	if err := versionedResult(res, err); err != nil {
+		if err == ErrVersionConflict {
+			return ErrInvoiceOverpaid
+		}
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO corporate_invoice_payments (invoice_id, amount, method, reference, paid_at, recorded_by, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		p.InvoiceID, p.Amount, p.Method, p.Reference, p.PaidAt, p.RecordedBy, tenantID,
+	).Scan(&p.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,126 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var accountColumns = []string{"id", "name", "billing_email", "billing_address", "tax_id", "booker_ids", "cost_centres", "credit_limit", "payment_terms_days", "active", "created_at", "updated_at", "version", "tenant_id"}
+
+func TestCorporateRepository_Accounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCorporateRepository(db)
+
+	limit := 5000000.0
+	account := &model.CorporateAccount{Name: " PT Maju ", BookerIDs: []int{3, 7}, CreditLimit: &limit, PaymentTermsDays: 30, Active: true}
+	mock.ExpectQuery(`INSERT INTO corporate_accounts`).
+		WithArgs("PT Maju", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg(), &limit, 30, true, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(2, time.Now(), time.Now()))
+	require.NoError(t, repo.CreateAccount(tenantCtx(), account))
+	assert.Equal(t, 2, account.ID)
+	assert.Equal(t, "PT Maju", account.Name)
+	assert.Equal(t, []string{}, account.CostCentres)
+
+	mock.ExpectQuery(`INSERT INTO corporate_accounts`).
+		WillReturnError(&pq.Error{Code: "23505"})
+	assert.ErrorIs(t, repo.CreateAccount(tenantCtx(), &model.CorporateAccount{Name: "PT Maju"}), repository.ErrDuplicateKey)
+
+	mock.ExpectQuery(`FROM corporate_accounts WHERE id = \$1 AND tenant_id = \$2`).
+		WithArgs(2, int64(1)).
+		WillReturnRows(sqlmock.NewRows(accountColumns).
+			AddRow(2, "PT Maju", "", "", "", "{3,7}", "{SALES,OPS}", limit, 30, true, time.Now(), time.Now(), 1, 1))
+	found, err := repo.GetAccount(tenantCtx(), 2)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, []int{3, 7}, found.BookerIDs)
+	assert.Equal(t, []string{"SALES", "OPS"}, found.CostCentres)
+
+	mock.ExpectExec(`UPDATE corporate_accounts SET`).
+		WithArgs("PT Maju", "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg(), &limit, 30, false, sqlmock.AnyArg(), 2, 1, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	found.Active = false
+	assert.ErrorIs(t, repo.UpdateAccount(tenantCtx(), found), repository.ErrVersionConflict)
+
+	mock.ExpectQuery(`SELECT`).
+		WithArgs(int64(1), 2, model.BookingCancelled, model.BookingNoShow, model.InvoiceVoid).
+		WillReturnRows(sqlmock.NewRows([]string{"unbilled", "outstanding"}).AddRow(250000.0, 1000000.0))
+	balance, err := repo.GetBalance(tenantCtx(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 250000.0, balance.Unbilled)
+	assert.Equal(t, 1000000.0, balance.Outstanding)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCorporateRepository_Invoices(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCorporateRepository(db)
+
+	before := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	tripDate := time.Date(2026, 9, 12, 9, 30, 0, 0, time.UTC)
+	pickup, drop := "Jakarta", "Bandung"
+	mock.ExpectQuery(`FROM booking b\s+LEFT JOIN LATERAL`).
+		WithArgs(int64(1), 2, before, model.TripCompleted, model.BookingCompleted).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trip_date", "customer", "place", "pickup_location", "drop_location", "cost_centre", "distance_km", "amount"}).
+			AddRow("BK1", tripDate, "Sari", "Bandung", pickup, drop, "SALES", 150.0, 450000.0))
+	lines, err := repo.GetUninvoiced(tenantCtx(), 2, before)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "Jakarta - Bandung", lines[0].Description)
+	assert.Equal(t, 450000.0, lines[0].Amount)
+
+	inv := &model.CorporateInvoice{AccountID: 2, Number: "INV-202609-0002", PeriodStart: before.AddDate(0, -1, 0), PeriodEnd: before.AddDate(0, 0, -1),
+		Total: 450000, Status: model.InvoiceIssued, IssuedAt: before, DueAt: before.AddDate(0, 0, 30), Lines: lines}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO corporate_invoices`).
+		WithArgs(2, "INV-202609-0002", inv.PeriodStart, inv.PeriodEnd, 450000.0, model.InvoiceIssued, before, inv.DueAt, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectQuery(`INSERT INTO corporate_invoice_lines`).
+		WithArgs(9, lines[0].BookingID, tripDate, "Jakarta - Bandung", "Sari", "SALES", lines[0].DistanceKM, 450000.0, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(31))
+	mock.ExpectCommit()
+	require.NoError(t, repo.CreateInvoice(tenantCtx(), inv))
+	assert.Equal(t, 9, inv.ID)
+	assert.Equal(t, 9, inv.Lines[0].InvoiceID)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO corporate_invoices`).WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.CreateInvoice(tenantCtx(), inv), repository.ErrDuplicateKey)
+
+	paidAt := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE corporate_invoices SET amount_paid = amount_paid \+ \$1`).
+		WithArgs(500000.0, model.InvoicePaid, model.InvoicePartiallyPaid, 9, int64(1), model.InvoiceVoid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	err = repo.AddInvoicePayment(tenantCtx(), &model.InvoicePayment{InvoiceID: 9, Amount: 500000, PaidAt: paidAt})
+	assert.ErrorIs(t, err, repository.ErrInvoiceOverpaid)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE corporate_invoices SET amount_paid = amount_paid \+ \$1`).
+		WithArgs(200000.0, model.InvoicePaid, model.InvoicePartiallyPaid, 9, int64(1), model.InvoiceVoid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO corporate_invoice_payments`).
+		WithArgs(9, 200000.0, "transfer", "TRF-1", paidAt, nil, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectCommit()
+	payment := &model.InvoicePayment{InvoiceID: 9, Amount: 200000, Method: "transfer", Reference: "TRF-1", PaidAt: paidAt}
+	require.NoError(t, repo.AddInvoicePayment(tenantCtx(), payment))
+	assert.Equal(t, 4, payment.ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type CustomerRepositoryInterface interface {
+	Search(ctx context.Context, query string) ([]model.Customer, error)
+	GetByID(ctx context.Context, id int) (*model.Customer, error)
+	Create(ctx context.Context, c *model.Customer) error
+	Update(ctx context.Context, c *model.Customer) error
+	Delete(ctx context.Context, id int, version int) error
+	GetBookings(ctx context.Context, id int) ([]model.Booking, error)
+	GetPayments(ctx context.Context, id int) ([]model.Payment, error)
+	GetTrips(ctx context.Context, id int) ([]model.TripHistory, error)
+}
+
+type CustomerRepository struct {
+	DB *sql.DB
+}
+
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{DB: db}
+}
+
+func normalizeCustomer(c *model.Customer) {
+	if c.Phones == nil {
+		c.Phones = []string{}
+	}
+	if c.Emails == nil {
+		c.Emails = []string{}
+	}
+	if c.Addresses == nil {
+		c.Addresses = []string{}
+	}
+}
+
+// Search matches name, phones and emails case-insensitively; an empty query lists everyone.
+func (r *CustomerRepository) Search(ctx context.Context, query string) ([]model.Customer, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT id, name, phones, emails, addresses, notes, blacklisted, created_at, updated_at, version
+		FROM customers
+		WHERE tenant_id = $1 AND ($2 = '' OR name ILIKE '%' || $2 || '%' OR array_to_string(phones, ' ') ILIKE '%' || $2 || '%' OR array_to_string(emails, ' ') ILIKE '%' || $2 || '%')
+		ORDER BY name`,
+		tenantID, query,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := []model.Customer{}
+	for rows.Next() {
+		var c model.Customer
+		if err := rows.Scan(&c.ID, &c.Name, pq.Array(&c.Phones), pq.Array(&c.Emails), pq.Array(&c.Addresses),
+			&c.Notes, &c.Blacklisted, &c.CreatedAt, &c.UpdatedAt, &c.Version); err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+
+	return customers, rows.Err()
+}
+
+func (r *CustomerRepository) GetByID(ctx context.Context, id int) (*model.Customer, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var c model.Customer
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT id, name, phones, emails, addresses, notes, blacklisted, created_at, updated_at, version FROM customers WHERE id = $1 AND tenant_id = $2`,
+		id, tenantID,
+	).Scan(&c.ID, &c.Name, pq.Array(&c.Phones), pq.Array(&c.Emails), pq.Array(&c.Addresses),
+		&c.Notes, &c.Blacklisted, &c.CreatedAt, &c.UpdatedAt, &c.Version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *CustomerRepository) Create(ctx context.Context, c *model.Customer) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeCustomer(c)
+
+	err = r.DB.QueryRowContext(ctx,
+		`INSERT INTO customers (name, phones, emails, addresses, notes, blacklisted, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at`,
+		c.Name, pq.Array(c.Phones), pq.Array(c.Emails), pq.Array(c.Addresses), c.Notes, c.Blacklisted, tenantID,
+	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	c.Version = 1
+	return nil
+}
+
+func (r *CustomerRepository) Update(ctx context.Context, c *model.Customer) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeCustomer(c)
+	c.UpdatedAt = time.Now()
+
+	err = versionedResult(r.DB.ExecContext(ctx,
+		`UPDATE customers SET name = $1, phones = $2, emails = $3, addresses = $4, notes = $5, blacklisted = $6, updated_at = $7, version = version + 1
+		WHERE id = $8 AND version = $9 AND tenant_id = $10`,
+		c.Name, pq.Array(c.Phones), pq.Array(c.Emails), pq.Array(c.Addresses), c.Notes, c.Blacklisted, c.UpdatedAt,
+		c.ID, c.Version, tenantID,
+	))
+	if err != nil {
+		return err
+	}
+
+	c.Version++
+	return nil
+}
+
+func (r *CustomerRepository) Delete(ctx context.Context, id int, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return versionedResult(r.DB.ExecContext(ctx,
+		`DELETE FROM customers WHERE id = $1 AND version = $2 AND tenant_id = $3`,
+		id, version, tenantID,
+	))
+}
+
+func (r *CustomerRepository) GetBookings(ctx context.Context, id int) ([]model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, customer, customer_id, driver, place, date, price, status, payment, phone_number, pickup_location, drop_location, pickup_time, amount, notes, driver_id, vehicle_id, start_at, end_at, pickup_lat, pickup_lng, car_type_id, quote_id, promo_code, discount, drop_lat, drop_lng, distance_km, created_at, updated_at, version FROM booking WHERE customer_id = $1 AND tenant_id = $2 AND deleted_at IS NULL ORDER BY created_at DESC`, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []model.Booking{}
+	for rows.Next() {
+		var b model.Booking
+		if err := rows.Scan(
+			&b.ID,
+			&b.Customer,
+			&b.CustomerID,
+			&b.Driver,
+			&b.Place,
+			&b.Date,
+			&b.Price,
+			&b.Status,
+			&b.Payment,
+			&b.PhoneNumber,
+			&b.PickupLocation,
+			&b.DropLocation,
+			&b.PickupTime,
+			&b.Amount,
+			&b.Notes,
+			&b.DriverID,
+			&b.VehicleID,
+			&b.StartAt,
+			&b.EndAt,
+			&b.PickupLat,
+			&b.PickupLng,
+			&b.CarTypeID,
+			&b.QuoteID,
+			&b.PromoCode,
+			&b.Discount,
+			&b.DropLat,
+			&b.DropLng,
+			&b.DistanceKM,
+			&b.CreatedAt,
+			&b.UpdatedAt,
+			&b.Version,
+		); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+
+	return bookings, rows.Err()
+}
+
+func (r *CustomerRepository) GetPayments(ctx context.Context, id int) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT `+paymentColumns+`
+		 FROM payment
+		 WHERE customer_id=$1 AND tenant_id=$2 AND deleted_at IS NULL
+		 ORDER BY payment_date DESC`, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := scanPayments(rows)
+	if err != nil {
+		return nil, err
+	}
+	if payments == nil {
+		payments = []model.Payment{}
+	}
+	return payments, nil
+}
+
+// GetTrips follows the trips' bookings, since the trips table predates
+// customers and only carries the customer's name.
+func (r *CustomerRepository) GetTrips(ctx context.Context, id int) ([]model.TripHistory, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT t.id, t.booking_id, t.customer_name, t.booking_date,
+               t.duration_minutes, t.distance_km, t.pickup_location, t.destination,
+               t.driver_name, t.vehicle_name, t.amount, COALESCE(t.rating, 0), COALESCE(t.feedback, '')
+        FROM trips t
+        JOIN booking b ON b.id = t.booking_id AND b.tenant_id = t.tenant_id
+        WHERE b.customer_id = $1 AND t.tenant_id = $2
+        ORDER BY t.booking_date DESC
+    `
+	rows, err := r.DB.QueryContext(ctx, query, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trips := []model.TripHistory{}
+	for rows.Next() {
+		var t model.TripHistory
+		if err := rows.Scan(
+			&t.ID, &t.BookingCode, &t.CustomerName, &t.BookingDate,
+			&t.DurationMinutes, &t.DistanceKM, &t.PickupLocation,
+			&t.Destination, &t.DriverName, &t.VehicleName,
+			&t.Amount, &t.Rating, &t.Feedback,
+		); err != nil {
+			return nil, err
+		}
+		trips = append(trips, t)
+	}
+
+	return trips, rows.Err()
+}
@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var customerColumns = []string{"id", "name", "phones", "emails", "addresses", "notes", "blacklisted", "created_at", "updated_at", "version"}
+
+func TestCustomerRepository_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCustomerRepository(db)
+
+	rows := sqlmock.NewRows(customerColumns).
+		AddRow(1, "Sari", "{0812,0813}", "{sari@example.com}", "{}", "", false, time.Now(), time.Now(), 1)
+
+	mock.ExpectQuery(`FROM customers\s+WHERE tenant_id = \$1 AND \(\$2 = '' OR name ILIKE`).
+		WithArgs(int64(1), "081").
+		WillReturnRows(rows)
+
+	customers, err := repo.Search(tenantCtx(), "081")
+	assert.NoError(t, err)
+	assert.Len(t, customers, 1)
+	assert.Equal(t, []string{"0812", "0813"}, customers[0].Phones)
+	assert.Empty(t, customers[0].Addresses)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_GetByID_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCustomerRepository(db)
+
+	mock.ExpectQuery(`FROM customers WHERE id = \$1 AND tenant_id = \$2`).
+		WithArgs(9, int64(1)).
+		WillReturnRows(sqlmock.NewRows(customerColumns))
+
+	customer, err := repo.GetByID(tenantCtx(), 9)
+	assert.NoError(t, err)
+	assert.Nil(t, customer)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_CreateUpdateDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCustomerRepository(db)
+	now := time.Now()
+
+	customer := &model.Customer{Name: "Sari", Phones: []string{"0812"}}
+
+	mock.ExpectQuery(`INSERT INTO customers`).
+		WithArgs("Sari", pq.Array([]string{"0812"}), pq.Array([]string{}), pq.Array([]string{}), "", false, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(3, now, now))
+
+	assert.NoError(t, repo.Create(tenantCtx(), customer))
+	assert.Equal(t, 3, customer.ID)
+	assert.Equal(t, 1, customer.Version)
+
+	customer.Blacklisted = true
+	mock.ExpectExec(`UPDATE customers SET name = \$1`).
+		WithArgs("Sari", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "", true, sqlmock.AnyArg(), 3, 1, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Update(tenantCtx(), customer))
+	assert.Equal(t, 2, customer.Version)
+
+	mock.ExpectExec(`DELETE FROM customers WHERE id = \$1 AND version = \$2 AND tenant_id = \$3`).
+		WithArgs(3, 1, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Delete(tenantCtx(), 3, 1), repository.ErrVersionConflict)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_GetTrips(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCustomerRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "booking_id", "customer_name", "booking_date", "duration_minutes", "distance_km",
+		"pickup_location", "destination", "driver_name", "vehicle_name", "amount", "rating", "feedback"}).
+		AddRow(1, "BK1", "Sari", "2024-01-01", 30, 12, "A", "B", "Budi", "Avanza", 50000, 4.5, "ok")
+
+	mock.ExpectQuery(`JOIN booking b ON b.id = t.booking_id`).
+		WithArgs(3, int64(1)).
+		WillReturnRows(rows)
+
+	trips, err := repo.GetTrips(tenantCtx(), 3)
+	assert.NoError(t, err)
+	assert.Len(t, trips, 1)
+	assert.Equal(t, "BK1", trips[0].BookingCode)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
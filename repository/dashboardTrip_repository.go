@@ -19,26 +19,30 @@ func NewDashboardTripRepository(db *sql.DB) *DashboardTripRepository {
 }
 
 func (r *DashboardTripRepository) GetDashboardSummary(ctx context.Context) (*model.DashboardSummary, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	summary := &model.DashboardSummary{}
 
-	err := r.DB.QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM vehicle_trips
-	`).Scan(&summary.TotalTrips)
+	err = r.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM trips WHERE tenant_id = $1 AND status = 'completed'
+	`, tenantID).Scan(&summary.TotalTrips)
 	if err != nil {
 		return nil, err
 	}
 
 	err = r.DB.QueryRowContext(ctx, `
-		SELECT COALESCE(SUM(distance_km), 0) FROM vehicle_trips
-	`).Scan(&summary.TotalDistance)
+		SELECT COALESCE(SUM(distance_km), 0) FROM trips WHERE tenant_id = $1 AND status = 'completed'
+	`, tenantID).Scan(&summary.TotalDistance)
 	if err != nil {
 		return nil, err
 	}
 
 	err = r.DB.QueryRowContext(ctx, `
-		SELECT COALESCE(SUM(duration), 0) FROM vehicle_trips
-	`).Scan(&summary.TotalDuration)
+		SELECT COALESCE(SUM(duration_minutes), 0) FROM trips WHERE tenant_id = $1 AND status = 'completed'
+	`, tenantID).Scan(&summary.TotalDuration)
 	if err != nil {
 		return nil, err
 	}
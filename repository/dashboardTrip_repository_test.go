@@ -2,7 +2,6 @@ package repository_test
 
 import (
 	"auth-service/repository"
-	"context"
 	"database/sql"
 	"testing"
 
@@ -17,16 +16,16 @@ func TestDashboardTripRepository_GetDashboardSummary_Success(t *testing.T) {
 
 	repo := repository.NewDashboardTripRepository(db)
 
-	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(50))
 
-	mock.ExpectQuery(`SELECT COALESCE\(SUM\(distance_km\), 0\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(distance_km\), 0\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1000))
 
-	mock.ExpectQuery(`SELECT COALESCE\(SUM\(duration\), 0\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(duration_minutes\), 0\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(200))
 
-	ctx := context.Background()
+	ctx := tenantCtx()
 	summary, err := repo.GetDashboardSummary(ctx)
 
 	assert.NoError(t, err)
@@ -45,10 +44,10 @@ func TestDashboardTripRepository_GetDashboardSummary_ErrorOnTotalTrips(t *testin
 
 	repo := repository.NewDashboardTripRepository(db)
 
-	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnError(sql.ErrConnDone)
 
-	ctx := context.Background()
+	ctx := tenantCtx()
 	summary, err := repo.GetDashboardSummary(ctx)
 
 	assert.Error(t, err)
@@ -66,13 +65,13 @@ func TestDashboardTripRepository_GetDashboardSummary_ErrorOnTotalDistance(t *tes
 
 	repo := repository.NewDashboardTripRepository(db)
 
-	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(50))
 
-	mock.ExpectQuery(`SELECT COALESCE\(SUM\(distance_km\), 0\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(distance_km\), 0\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnError(sql.ErrConnDone)
 
-	ctx := context.Background()
+	ctx := tenantCtx()
 	summary, err := repo.GetDashboardSummary(ctx)
 
 	assert.Error(t, err)
@@ -90,16 +89,16 @@ func TestDashboardTripRepository_GetDashboardSummary_ErrorOnTotalDuration(t *tes
 
 	repo := repository.NewDashboardTripRepository(db)
 
-	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(50))
 
-	mock.ExpectQuery(`SELECT COALESCE\(SUM\(distance_km\), 0\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(distance_km\), 0\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1000))
 
-	mock.ExpectQuery(`SELECT COALESCE\(SUM\(duration\), 0\) FROM vehicle_trips`).
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(duration_minutes\), 0\) FROM trips WHERE tenant_id = \$1 AND status = 'completed'`).
 		WillReturnError(sql.ErrConnDone)
 
-	ctx := context.Background()
+	ctx := tenantCtx()
 	summary, err := repo.GetDashboardSummary(ctx)
 
 	assert.Error(t, err)
@@ -1,11 +1,14 @@
 package repository
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 type DashboardRepositoryInterface interface {
-	GetTotalBookings() (int, error)
-	GetActiveDrivers() (int, error)
-	GetTotalRevenue() (float64, error)
+	GetTotalBookings(ctx context.Context) (int, error)
+	GetActiveDrivers(ctx context.Context) (int, error)
+	GetTotalRevenue(ctx context.Context) (float64, error)
 }
 
 type DashboardRepository struct {
@@ -16,21 +19,39 @@ func NewDashboardRepository(db *sql.DB) *DashboardRepository {
 	return &DashboardRepository{DB: db}
 }
 
-func (r *DashboardRepository) GetTotalBookings() (int, error) {
+func (r *DashboardRepository) GetTotalBookings(ctx context.Context) (int, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	var count int
-	err := r.DB.QueryRow(`SELECT COUNT(*) FROM booking`).Scan(&count)
+	err = r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM booking WHERE tenant_id = $1 AND deleted_at IS NULL`, tenantID).Scan(&count)
 	return count, err
 }
 
-func (r *DashboardRepository) GetActiveDrivers() (int, error) {
+func (r *DashboardRepository) GetActiveDrivers(ctx context.Context) (int, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	var count int
-	err := r.DB.QueryRow(`SELECT COUNT(*) FROM drivers WHERE status = 'active'`).Scan(&count)
+	err = r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM drivers WHERE status = 'active' AND tenant_id = $1 AND deleted_at IS NULL`, tenantID).Scan(&count)
 	return count, err
 }
 
-func (r *DashboardRepository) GetTotalRevenue() (float64, error) {
+// GetTotalRevenue is what captured payments brought in, less what has been
+// refunded of them.
+func (r *DashboardRepository) GetTotalRevenue(ctx context.Context) (float64, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	var total float64
-	err := r.DB.QueryRow(`
-    SELECT COALESCE(SUM(amount), 0)::FLOAT  FROM payment`).Scan(&total)
+	err = r.DB.QueryRowContext(ctx, `
+    SELECT COALESCE(SUM(amount - refunded_amount) FILTER (WHERE status IN ('paid', 'partially_refunded', 'refunded')), 0)::FLOAT
+    FROM payment WHERE tenant_id = $1 AND deleted_at IS NULL`, tenantID).Scan(&total)
 	return total, err
 }
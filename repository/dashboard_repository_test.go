@@ -19,7 +19,7 @@ func TestDashboardRepository_GetTotalBookings_Success(t *testing.T) {
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM booking`).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
 
-	count, err := repo.GetTotalBookings()
+	count, err := repo.GetTotalBookings(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Equal(t, 10, count)
@@ -38,7 +38,7 @@ func TestDashboardRepository_GetTotalBookings_Error(t *testing.T) {
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM booking`).
 		WillReturnError(sql.ErrConnDone)
 
-	count, err := repo.GetTotalBookings()
+	count, err := repo.GetTotalBookings(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, count)
@@ -58,7 +58,7 @@ func TestDashboardRepository_GetActiveDrivers_Success(t *testing.T) {
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM drivers WHERE status = 'active'`).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
 
-	count, err := repo.GetActiveDrivers()
+	count, err := repo.GetActiveDrivers(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Equal(t, 5, count)
@@ -77,7 +77,7 @@ func TestDashboardRepository_GetActiveDrivers_Error(t *testing.T) {
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM drivers WHERE status = 'active'`).
 		WillReturnError(sql.ErrConnDone)
 
-	count, err := repo.GetActiveDrivers()
+	count, err := repo.GetActiveDrivers(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, count)
@@ -94,10 +94,10 @@ func TestDashboardRepository_GetTotalRevenue_Success(t *testing.T) {
 
 	repo := repository.NewDashboardRepository(db)
 
-	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\)::FLOAT FROM payment`).
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount - refunded_amount\) FILTER \(WHERE status IN .+\)::FLOAT FROM payment`).
 		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(1000.0))
 
-	total, err := repo.GetTotalRevenue()
+	total, err := repo.GetTotalRevenue(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1000.0, total)
@@ -113,10 +113,10 @@ func TestDashboardRepository_GetTotalRevenue_Error(t *testing.T) {
 
 	repo := repository.NewDashboardRepository(db)
 
-	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\)::FLOAT FROM payment`).
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount - refunded_amount\) FILTER \(WHERE status IN .+\)::FLOAT FROM payment`).
 		WillReturnError(sql.ErrConnDone)
 
-	total, err := repo.GetTotalRevenue()
+	total, err := repo.GetTotalRevenue(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Equal(t, 0.0, total)
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type DispatchRepositoryInterface interface {
+	ActiveDrivers(ctx context.Context, from, to time.Time) ([]model.DispatchCandidate, error)
+	SetLocation(ctx context.Context, l *model.DriverLocation) error
+	CreateOffer(ctx context.Context, o *model.DispatchOffer) error
+	GetOffer(ctx context.Context, id int) (*model.DispatchOffer, error)
+	GetOffers(ctx context.Context, bookingID string) ([]model.DispatchOffer, error)
+	RespondOffer(ctx context.Context, o *model.DispatchOffer) error
+	ExpireOffers(ctx context.Context, now time.Time) ([]model.DispatchOffer, error)
+}
+
+type DispatchRepository struct {
+	DB *sql.DB
+}
+
+func NewDispatchRepository(db *sql.DB) *DispatchRepository {
+	return &DispatchRepository{DB: db}
+}
+
+// ActiveDrivers lists active drivers with their last location, average trip
+// rating and the number of bookings they hold within [from, to).
+func (r *DispatchRepository) ActiveDrivers(ctx context.Context, from, to time.Time) ([]model.DispatchCandidate, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT d.id, d.name, d.car_type_id, l.latitude, l.longitude,
+               COALESCE((SELECT AVG(t.rating) FROM trips t WHERE t.tenant_id = d.tenant_id AND (t.driver_id = d.id OR (t.driver_id IS NULL AND t.driver_name = d.name))), 0),
+               (SELECT COUNT(*) FROM booking b
+                WHERE b.tenant_id = d.tenant_id AND b.driver_id = d.id AND b.deleted_at IS NULL
+                  AND b.status NOT IN ('Cancelled', 'NoShow') AND b.start_at < $3 AND b.end_at > $2)
+        FROM drivers d
+        LEFT JOIN driver_locations l ON l.driver_id = d.id AND l.tenant_id = d.tenant_id
+        WHERE d.tenant_id = $1 AND d.status = 'active' AND d.deleted_at IS NULL
+        ORDER BY d.id
+    `
+	rows, err := r.DB.QueryContext(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []model.DispatchCandidate{}
+	for rows.Next() {
+		var c model.DispatchCandidate
+		if err := rows.Scan(&c.DriverID, &c.Name, &c.CarTypeID, &c.Latitude, &c.Longitude, &c.Rating, &c.Workload); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+func (r *DispatchRepository) SetLocation(ctx context.Context, l *model.DriverLocation) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	l.UpdatedAt = time.Now()
+
+	_, err = r.DB.ExecContext(ctx,
+		`INSERT INTO driver_locations (driver_id, latitude, longitude, updated_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (driver_id) DO UPDATE SET latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude, updated_at = EXCLUDED.updated_at`,
+		l.DriverID, l.Latitude, l.Longitude, l.UpdatedAt, tenantID,
+	)
+	return err
+}
+
+func (r *DispatchRepository) CreateOffer(ctx context.Context, o *model.DispatchOffer) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	o.TenantID = tenantID
+
+	return r.DB.QueryRowContext(ctx,
+		`INSERT INTO dispatch_offers (booking_id, driver_id, score, status, offered_at, expires_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		o.BookingID, o.DriverID, o.Score, o.Status, o.OfferedAt, o.ExpiresAt, tenantID,
+	).Scan(&o.ID)
+}
+
+func (r *DispatchRepository) GetOffer(ctx context.Context, id int) (*model.DispatchOffer, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var o model.DispatchOffer
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT id, booking_id, driver_id, score, status, offered_at, expires_at, responded_at, tenant_id FROM dispatch_offers WHERE id = $1 AND tenant_id = $2`,
+		id, tenantID,
+	).Scan(&o.ID, &o.BookingID, &o.DriverID, &o.Score, &o.Status, &o.OfferedAt, &o.ExpiresAt, &o.RespondedAt, &o.TenantID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+func (r *DispatchRepository) GetOffers(ctx context.Context, bookingID string) ([]model.DispatchOffer, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT id, booking_id, driver_id, score, status, offered_at, expires_at, responded_at, tenant_id FROM dispatch_offers WHERE booking_id = $1 AND tenant_id = $2 ORDER BY offered_at, id`,
+		bookingID, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOffers(rows)
+}
+
+func scanOffers(rows *sql.Rows) ([]model.DispatchOffer, error) {
+	offers := []model.DispatchOffer{}
+	for rows.Next() {
+		var o model.DispatchOffer
+		if err := rows.Scan(&o.ID, &o.BookingID, &o.DriverID, &o.Score, &o.Status, &o.OfferedAt, &o.ExpiresAt, &o.RespondedAt, &o.TenantID); err != nil {
+			return nil, err
+		}
+		offers = append(offers, o)
+	}
+	return offers, rows.Err()
+}
+
+// RespondOffer closes a pending offer with o.Status. An offer that was already
+// answered or expired reports ErrVersionConflict.
+func (r *DispatchRepository) RespondOffer(ctx context.Context, o *model.DispatchOffer) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return respondOffer(ctx, r.DB, tenantID, o)
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// respondOffer answers o if it is still pending, and returns
+// ErrVersionConflict when it is not.
+func respondOffer(ctx context.Context, e execer, tenantID int64, o *model.DispatchOffer) error {
+	now := time.Now()
+	err := versionedResult(e.ExecContext(ctx,
+		`UPDATE dispatch_offers SET status = $1, responded_at = $2 WHERE id = $3 AND tenant_id = $4 AND status = 'Pending'`,
+		o.Status, now, o.ID, tenantID,
+	))
+	if err != nil {
+		return err
+	}
+
+	o.RespondedAt = &now
+	return nil
+}
+
+// ExpireOffers is run by the dispatch sweep and spans every tenant.
+func (r *DispatchRepository) ExpireOffers(ctx context.Context, now time.Time) ([]model.DispatchOffer, error) {
+	rows, err := r.DB.QueryContext(ctx,
+		`UPDATE dispatch_offers SET status = 'Expired', responded_at = $1
+		WHERE status = 'Pending' AND expires_at <= $1
+		RETURNING id, booking_id, driver_id, score, status, offered_at, expires_at, responded_at, tenant_id`,
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOffers(rows)
+}
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var offerColumns = []string{"id", "booking_id", "driver_id", "score", "status", "offered_at", "expires_at", "responded_at", "tenant_id"}
+
+func TestDispatchRepository_ActiveDrivers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewDispatchRepository(db)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "car_type_id", "latitude", "longitude", "rating", "workload"}).
+		AddRow(1, "Budi", "mpv", -6.2, 106.8, 4.5, 2).
+		AddRow(2, "Andi", "sedan", nil, nil, 0, 0)
+
+	mock.ExpectQuery(`FROM drivers d\s+LEFT JOIN driver_locations l`).
+		WithArgs(int64(1), from, to).
+		WillReturnRows(rows)
+
+	drivers, err := repo.ActiveDrivers(tenantCtx(), from, to)
+	assert.NoError(t, err)
+	assert.Len(t, drivers, 2)
+	assert.Equal(t, 2, drivers[0].Workload)
+	assert.Equal(t, -6.2, *drivers[0].Latitude)
+	assert.Nil(t, drivers[1].Latitude)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatchRepository_Offers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewDispatchRepository(db)
+	now := time.Now()
+	offer := &model.DispatchOffer{BookingID: "BK1", DriverID: 2, Score: 7.5, Status: model.OfferPending, OfferedAt: now, ExpiresAt: now.Add(time.Minute)}
+
+	mock.ExpectQuery(`INSERT INTO dispatch_offers`).
+		WithArgs("BK1", 2, 7.5, "Pending", offer.OfferedAt, offer.ExpiresAt, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	assert.NoError(t, repo.CreateOffer(tenantCtx(), offer))
+	assert.Equal(t, 3, offer.ID)
+
+	mock.ExpectQuery(`FROM dispatch_offers WHERE id = \$1 AND tenant_id = \$2`).
+		WithArgs(4, int64(1)).
+		WillReturnRows(sqlmock.NewRows(offerColumns))
+	missing, err := repo.GetOffer(tenantCtx(), 4)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	offer.Status = model.OfferAccepted
+	mock.ExpectExec(`UPDATE dispatch_offers SET status = \$1, responded_at = \$2 WHERE id = \$3 AND tenant_id = \$4 AND status = 'Pending'`).
+		WithArgs("Accepted", sqlmock.AnyArg(), 3, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.RespondOffer(tenantCtx(), offer), repository.ErrVersionConflict)
+
+	mock.ExpectQuery(`UPDATE dispatch_offers SET status = 'Expired'`).
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows(offerColumns).AddRow(3, "BK1", 2, 7.5, "Expired", now, now, now, 5))
+	expired, err := repo.ExpireOffers(tenantCtx(), now)
+	assert.NoError(t, err)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, int64(5), expired[0].TenantID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
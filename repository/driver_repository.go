@@ -2,23 +2,33 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
+	"time"
 )
 
 type DriverRepositoryInterface interface {
-	GetAll() ([]model.Driver, error)
-	Create(d *model.Driver) error
-	GetByID(id string) (*model.Driver, error)
-	Update(id string, d *model.Driver) error
-	Delete(id string) error
+	GetAll(ctx context.Context) ([]model.Driver, error)
+	Create(ctx context.Context, d *model.Driver) error
+	GetByID(ctx context.Context, id string) (*model.Driver, error)
+	Update(ctx context.Context, id string, d *model.Driver) error
+	Delete(ctx context.Context, id string, version int) error
+	GetDeleted(ctx context.Context) ([]model.Driver, error)
+	Restore(ctx context.Context, id string) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 type DriverRepository struct {
 	DB *sql.DB
 }
 
-func (r *DriverRepository) GetAll() ([]model.Driver, error) {
-	rows, err := r.DB.Query("SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, created_at, updated_at FROM drivers")
+func (r *DriverRepository) GetAll(ctx context.Context) ([]model.Driver, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, rating_average, rating_count, rolling_rating, created_at, updated_at, version FROM drivers WHERE tenant_id = $1 AND deleted_at IS NULL", tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +38,7 @@ func (r *DriverRepository) GetAll() ([]model.Driver, error) {
 	for rows.Next() {
 		var d model.Driver
 		if err := rows.Scan(&d.ID, &d.Name, &d.Email, &d.Phone, &d.Address, &d.DriverLicenseNumber, &d.CarModelID, &d.CarTypeID, &d.PlateNumber,
-			&d.Status, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			&d.Status, &d.RatingAverage, &d.RatingCount, &d.RollingRating, &d.CreatedAt, &d.UpdatedAt, &d.Version); err != nil {
 			return nil, err
 		}
 		drivers = append(drivers, d)
@@ -41,41 +51,117 @@ func (r *DriverRepository) GetAll() ([]model.Driver, error) {
 	return drivers, nil
 }
 
-func (r *DriverRepository) Create(d *model.Driver) error {
+func (r *DriverRepository) Create(ctx context.Context, d *model.Driver) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `
-    INSERT INTO drivers (name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number,status, created_at, updated_at)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+    INSERT INTO drivers (name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number,status, tenant_id, created_at, updated_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
     RETURNING id, created_at, updated_at;
 `
 
-	return r.DB.QueryRow(
+	err = r.DB.QueryRowContext(ctx,
 		query,
-		d.Name, d.Email, d.Phone, d.Address, d.DriverLicenseNumber, d.CarModelID, d.CarTypeID, d.PlateNumber, d.Status,
+		d.Name, d.Email, d.Phone, d.Address, d.DriverLicenseNumber, d.CarModelID, d.CarTypeID, d.PlateNumber, d.Status, tenantID,
 	).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	d.Version = 1
+	return nil
 }
 
-func (r *DriverRepository) GetByID(id string) (*model.Driver, error) {
+func (r *DriverRepository) GetByID(ctx context.Context, id string) (*model.Driver, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var d model.Driver
-	err := r.DB.QueryRow("SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, created_at, updated_at FROM drivers WHERE id = $1", id).Scan(&d.ID, &d.Name, &d.Email, &d.Phone, &d.Address, &d.DriverLicenseNumber, &d.CarModelID, &d.CarTypeID, &d.PlateNumber, &d.Status, &d.CreatedAt, &d.UpdatedAt)
+	err = r.DB.QueryRowContext(ctx, "SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, rating_average, rating_count, rolling_rating, created_at, updated_at, version FROM drivers WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL", id, tenantID).Scan(&d.ID, &d.Name, &d.Email, &d.Phone, &d.Address, &d.DriverLicenseNumber, &d.CarModelID, &d.CarTypeID, &d.PlateNumber, &d.Status, &d.RatingAverage, &d.RatingCount, &d.RollingRating, &d.CreatedAt, &d.UpdatedAt, &d.Version)
 	if err != nil {
 		return nil, err
 	}
 	return &d, nil
 }
 
-func (r *DriverRepository) Update(id string, d *model.Driver) error {
+func (r *DriverRepository) Update(ctx context.Context, id string, d *model.Driver) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `
         UPDATE drivers
         SET name=$1, email=$2, phone=$3, address=$4, driver_license_number=$5, car_model_id=$6, car_type_id=$7, plate_number=$8,
-		status=$9, updated_at=NOW()
-        WHERE id=$10
+		status=$9, updated_at=NOW(), version=version+1
+        WHERE id=$10 AND version=$11 AND tenant_id=$12 AND deleted_at IS NULL
     `
-	_, err := r.DB.Exec(query, d.Name, d.Email, d.Phone, d.Address, d.DriverLicenseNumber, d.CarModelID, d.CarTypeID, d.PlateNumber, d.Status, id)
-	return err
+	res, err := r.DB.ExecContext(ctx, query, d.Name, d.Email, d.Phone, d.Address, d.DriverLicenseNumber, d.CarModelID, d.CarTypeID, d.PlateNumber, d.Status, id, d.Version, tenantID)
+	err = versionedRowResult(ctx, r.DB, "drivers", "id", id, tenantID, res, err)
+	if err != nil {
+		return err
+	}
+
+	d.Version++
+	return nil
+}
+
+func (r *DriverRepository) Delete(ctx context.Context, id string, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE drivers SET deleted_at=NOW(), version=version+1 WHERE id=$1 AND version=$2 AND tenant_id=$3 AND deleted_at IS NULL`
+	res, err := r.DB.ExecContext(ctx, query, id, version, tenantID)
+	return versionedRowResult(ctx, r.DB, "drivers", "id", id, tenantID, res, err)
+}
+
+func (r *DriverRepository) GetDeleted(ctx context.Context) ([]model.Driver, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, rating_average, rating_count, rolling_rating, created_at, updated_at, version, deleted_at FROM drivers WHERE tenant_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC", tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drivers []model.Driver
+	for rows.Next() {
+		var d model.Driver
+		if err := rows.Scan(&d.ID, &d.Name, &d.Email, &d.Phone, &d.Address, &d.DriverLicenseNumber, &d.CarModelID, &d.CarTypeID, &d.PlateNumber,
+			&d.Status, &d.RatingAverage, &d.RatingCount, &d.RollingRating, &d.CreatedAt, &d.UpdatedAt, &d.Version, &d.DeletedAt); err != nil {
+			return nil, err
+		}
+		drivers = append(drivers, d)
+	}
+
+	return drivers, rows.Err()
 }
 
-func (r *DriverRepository) Delete(id string) error {
-	query := `DELETE FROM drivers WHERE id=$1`
-	_, err := r.DB.Exec(query, id)
-	return err
+func (r *DriverRepository) Restore(ctx context.Context, id string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE drivers SET deleted_at=NULL, version=version+1 WHERE id=$1 AND tenant_id=$2 AND deleted_at IS NOT NULL`
+	return restoredResult(r.DB.ExecContext(ctx, query, id, tenantID))
+}
+
+// PurgeDeleted is run by the retention job and spans every tenant.
+func (r *DriverRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM drivers WHERE deleted_at IS NOT NULL AND deleted_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
@@ -33,16 +33,16 @@ func TestDriverRepository_GetAll_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "name", "email", "phone", "address",
 		"driver_license_number", "car_model_id", "car_type_id", "plate_number",
-		"status", "created_at", "updated_at",
+		"status", "rating_average", "rating_count", "rolling_rating", "created_at", "updated_at", "version",
 	}).AddRow(
 		1, "John Doe", "john@example.com", "1234567890", "Address 1",
-		"DL123", "1", "1", "ABC123", "active", time.Now(), time.Now(),
+		"DL123", "1", "1", "ABC123", "active", 4.5, 10, 4.2, time.Now(), time.Now(), 1,
 	)
 
-	mock.ExpectQuery("SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, created_at, updated_at FROM drivers").
+	mock.ExpectQuery("SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, rating_average, rating_count, rolling_rating, created_at, updated_at, version FROM drivers").
 		WillReturnRows(rows)
 
-	drivers, err := repo.GetAll()
+	drivers, err := repo.GetAll(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, drivers, 1)
@@ -62,7 +62,7 @@ func TestDriverRepository_GetAll_QueryError(t *testing.T) {
 	mock.ExpectQuery("(?i)SELECT .* FROM drivers").
 		WillReturnError(fmt.Errorf("query failed"))
 
-	drivers, err := repo.GetAll()
+	drivers, err := repo.GetAll(tenantCtx())
 	assert.Error(t, err)
 	assert.Nil(t, drivers)
 	assert.Contains(t, err.Error(), "query failed")
@@ -85,10 +85,10 @@ func TestDriverRepository_GetAll_Error(t *testing.T) {
 
 	repo := repository.DriverRepository{DB: db}
 
-	mock.ExpectQuery("SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, created_at, updated_at FROM drivers").
+	mock.ExpectQuery("SELECT id, name, email, phone, address, driver_license_number, car_model_id, car_type_id, plate_number, status, rating_average, rating_count, rolling_rating, created_at, updated_at, version FROM drivers").
 		WillReturnError(sql.ErrConnDone)
 
-	drivers, err := repo.GetAll()
+	drivers, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, drivers)
@@ -117,10 +117,10 @@ func TestDriverRepository_Create_Success(t *testing.T) {
 	}
 
 	mock.ExpectQuery(`INSERT INTO drivers .* RETURNING id, created_at, updated_at`).
-		WithArgs(driver.Name, driver.Email, driver.Phone, driver.Address, driver.DriverLicenseNumber, driver.CarModelID, driver.CarTypeID, driver.PlateNumber, driver.Status).
+		WithArgs(driver.Name, driver.Email, driver.Phone, driver.Address, driver.DriverLicenseNumber, driver.CarModelID, driver.CarTypeID, driver.PlateNumber, driver.Status, int64(1)).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
 
-	err = repo.Create(driver)
+	err = repo.Create(tenantCtx(), driver)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, driver.ID)
@@ -140,7 +140,7 @@ func TestDriverRepository_Create_Error(t *testing.T) {
 	mock.ExpectQuery(`INSERT INTO drivers .* RETURNING id, created_at, updated_at`).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Create(driver)
+	err = repo.Create(tenantCtx(), driver)
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
 
@@ -158,17 +158,17 @@ func TestDriverRepository_GetByID_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"id", "name", "email", "phone", "address",
 		"driver_license_number", "car_model_id", "car_type_id", "plate_number",
-		"status", "created_at", "updated_at",
+		"status", "rating_average", "rating_count", "rolling_rating", "created_at", "updated_at", "version",
 	}).AddRow(
 		1, "John Doe", "john@example.com", "1234567890", "Address 1",
-		"DL123", "1", "1", "ABC123", "active", time.Now(), time.Now(),
+		"DL123", "1", "1", "ABC123", "active", 4.5, 10, 4.2, time.Now(), time.Now(), 1,
 	)
 
 	mock.ExpectQuery(`SELECT .* FROM drivers WHERE id = \$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnRows(rows)
 
-	driver, err := repo.GetByID(id)
+	driver, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, driver)
@@ -187,10 +187,10 @@ func TestDriverRepository_GetByID_Error(t *testing.T) {
 	id := "1"
 
 	mock.ExpectQuery(`SELECT .* FROM drivers WHERE id = \$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	driver, err := repo.GetByID(id)
+	driver, err := repo.GetByID(tenantCtx(), id)
 	assert.Error(t, err)
 	assert.Nil(t, driver)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -206,14 +206,15 @@ func TestDriverRepository_Update_Success(t *testing.T) {
 	repo := repository.DriverRepository{DB: db}
 
 	id := "1"
-	driver := &model.Driver{Name: "Jane Doe"}
+	driver := &model.Driver{Name: "Jane Doe", Version: 2}
 
-	mock.ExpectExec(`UPDATE drivers .* WHERE id=\$10`).
-		WithArgs(driver.Name, driver.Email, driver.Phone, driver.Address, driver.DriverLicenseNumber, driver.CarModelID, driver.CarTypeID, driver.PlateNumber, driver.Status, id).
+	mock.ExpectExec(`UPDATE drivers .* WHERE id=\$10 AND version=\$11`).
+		WithArgs(driver.Name, driver.Email, driver.Phone, driver.Address, driver.DriverLicenseNumber, driver.CarModelID, driver.CarTypeID, driver.PlateNumber, driver.Status, id, 2, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Update(id, driver)
+	err = repo.Update(tenantCtx(), id, driver)
 	assert.NoError(t, err)
+	assert.Equal(t, 3, driver.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -228,7 +229,7 @@ func TestDriverRepository_Update_Error(t *testing.T) {
 
 	mock.ExpectExec(`UPDATE drivers .* WHERE id=\$10`).WillReturnError(sql.ErrConnDone)
 
-	err = repo.Update(id, driver)
+	err = repo.Update(tenantCtx(), id, driver)
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -242,11 +243,11 @@ func TestDriverRepository_Delete_Success(t *testing.T) {
 	repo := repository.DriverRepository{DB: db}
 	id := "1"
 
-	mock.ExpectExec(`DELETE FROM drivers WHERE id=\$1`).
-		WithArgs(id).
+	mock.ExpectExec(`UPDATE drivers SET deleted_at=NOW\(\), version=version\+1 WHERE id=\$1 AND version=\$2 AND tenant_id=\$3 AND deleted_at IS NULL`).
+		WithArgs(id, 1, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id, 1)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -259,9 +260,9 @@ func TestDriverRepository_Delete_Error(t *testing.T) {
 	repo := repository.DriverRepository{DB: db}
 	id := "1"
 
-	mock.ExpectExec(`DELETE FROM drivers WHERE id=\$1`).WithArgs(id).WillReturnError(sql.ErrConnDone)
+	mock.ExpectExec(`UPDATE drivers SET deleted_at=NOW\(\), version=version\+1 WHERE id=\$1 AND version=\$2 AND tenant_id=\$3 AND deleted_at IS NULL`).WithArgs(id, 1, int64(1)).WillReturnError(sql.ErrConnDone)
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id, 1)
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -276,15 +277,15 @@ func TestDriverRepository_GetAll_ScanError(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"id", "name", "email", "phone", "address",
-		"driver_license_number", "car_model_id", "car_type_id", "plate_number", "status", "created_at", "updated_at",
+		"driver_license_number", "car_model_id", "car_type_id", "plate_number", "status", "rating_average", "rating_count", "rolling_rating", "created_at", "updated_at", "version",
 	}).AddRow(
 		"invalid_int", "John Doe", "john@example.com", "1234567890", "Address 1",
-		"DL123", 1, 1, "ABC123", "active", time.Now(), time.Now(),
+		"DL123", 1, 1, "ABC123", "active", 4.5, 10, 4.2, time.Now(), time.Now(), 1,
 	)
 
 	mock.ExpectQuery("SELECT .* FROM drivers").WillReturnRows(rows)
 
-	drivers, err := repo.GetAll()
+	drivers, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, drivers)
@@ -301,14 +302,14 @@ func TestDriverRepository_GetAll_RowsErr(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "name", "email", "phone", "address",
 		"driver_license_number", "car_model_id", "car_type_id", "plate_number",
-		"status", "created_at", "updated_at",
+		"status", "rating_average", "rating_count", "rolling_rating", "created_at", "updated_at", "version",
 	}).AddRow(1, "John Doe", "john@example.com", "1234567890", "Address 1",
-		"DL123", 1, 1, "ABC123", "active", time.Now(), time.Now(),
+		"DL123", 1, 1, "ABC123", "active", 4.5, 10, 4.2, time.Now(), time.Now(), 1,
 	).CloseError(fmt.Errorf("rows iteration error"))
 
 	mock.ExpectQuery("SELECT .* FROM drivers").WillReturnRows(rows)
 
-	drivers, err := repo.GetAll()
+	drivers, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, drivers)
@@ -316,3 +317,68 @@ func TestDriverRepository_GetAll_RowsErr(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestDriverRepository_Update_VersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.DriverRepository{DB: db}
+	driver := &model.Driver{Name: "Jane Doe", Version: 1}
+
+	mock.ExpectExec(`UPDATE drivers .* WHERE id=\$10 AND version=\$11`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "drivers", "id", "1", true)
+
+	err = repo.Update(tenantCtx(), "1", driver)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	assert.Equal(t, 1, driver.Version)
+
+	mock.ExpectExec(`UPDATE drivers .* WHERE id=\$10 AND version=\$11`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLiveRow(mock, "drivers", "id", "404", false)
+
+	err = repo.Update(tenantCtx(), "404", driver)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDriverRepository_Trash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.DriverRepository{DB: db}
+	deletedAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{
+		"id", "name", "email", "phone", "address",
+		"driver_license_number", "car_model_id", "car_type_id", "plate_number",
+		"status", "rating_average", "rating_count", "rolling_rating", "created_at", "updated_at", "version", "deleted_at",
+	}).AddRow(
+		1, "John Doe", "john@example.com", "1234567890", "Address 1",
+		"DL123", "1", "1", "ABC123", "active", 4.5, 10, 4.2, time.Now(), time.Now(), 2, deletedAt,
+	)
+
+	mock.ExpectQuery(`FROM drivers WHERE tenant_id = \$1 AND deleted_at IS NOT NULL`).WillReturnRows(rows)
+
+	drivers, err := repo.GetDeleted(tenantCtx())
+	assert.NoError(t, err)
+	assert.Len(t, drivers, 1)
+	assert.Equal(t, deletedAt, *drivers[0].DeletedAt)
+
+	mock.ExpectExec(`UPDATE drivers SET deleted_at=NULL`).WithArgs("1", int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Restore(tenantCtx(), "1"))
+
+	mock.ExpectExec(`UPDATE drivers SET deleted_at=NULL`).WithArgs("2", int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Restore(tenantCtx(), "2"), repository.ErrNotFound)
+
+	mock.ExpectExec(`DELETE FROM drivers WHERE deleted_at IS NOT NULL AND deleted_at <= \$1`).
+		WithArgs(deletedAt).
+		WillReturnError(sql.ErrConnDone)
+
+	_, err = repo.PurgeDeleted(tenantCtx(), deletedAt)
+	assert.Equal(t, sql.ErrConnDone, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
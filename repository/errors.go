@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrVersionConflict  = errors.New("record has been modified by another request")
+	ErrNotFound         = errors.New("record not found")
+	ErrNoTenant         = errors.New("no tenant in request context")
+	ErrDuplicateKey     = errors.New("record already exists")
+	ErrScheduleConflict = errors.New("booking overlaps another booking for the same driver or vehicle")
+	ErrQuoteRedeemed    = errors.New("quote has already been used for a booking")
+)
+
+func versionedResult(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	return nil
+}
+
+// versionedRowResult is versionedResult for a write to the live row of table
+// whose key column is id. A write that matched nothing is ErrNotFound when
+// the row does not exist or has been deleted.
+func versionedRowResult(ctx context.Context, q queryRower, table, key string, id any, tenantID int64, res sql.Result, err error) error {
+	err = versionedResult(res, err)
+	if !errors.Is(err, ErrVersionConflict) {
+		return err
+	}
+	return missingOrConflict(ctx, q, table, key, id, tenantID)
+}
+
+// missingOrConflict explains a versioned write to table that matched no row:
+// ErrNotFound when the live row is gone, ErrVersionConflict when it changed.
+func missingOrConflict(ctx context.Context, q queryRower, table, key string, id any, tenantID int64) error {
+	var exists bool
+	err := q.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+table+` WHERE `+key+` = $1 AND tenant_id = $2 AND deleted_at IS NULL)`,
+		id, tenantID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
+
+func restoredResult(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// bookingError reports a violation of the booking overlap exclusion
+// constraints as ErrScheduleConflict, and a second booking made from the same
+// quote as ErrQuoteRedeemed.
+func bookingError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == "23P01":
+		return ErrScheduleConflict
+	case pqErr.Code == "23505" && pqErr.Constraint == "idx_booking_quote":
+		return ErrQuoteRedeemed
+	}
+	return err
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type FeedbackRepositoryInterface interface {
+	Submit(ctx context.Context, f *model.TripFeedback, window int) (*model.DriverRating, error)
+	GetByTrip(ctx context.Context, tripID uint) (*model.TripFeedback, error)
+	GetByDriver(ctx context.Context, driverID int) ([]model.TripFeedback, error)
+	RaiseAlert(ctx context.Context, a *model.DriverRatingAlert, notifications []model.Notification) (bool, error)
+	GetAlerts(ctx context.Context, open bool) ([]model.DriverRatingAlert, error)
+	AcknowledgeAlert(ctx context.Context, id int, by int64) error
+}
+
+type FeedbackRepository struct {
+	DB *sql.DB
+}
+
+func NewFeedbackRepository(db *sql.DB) *FeedbackRepository {
+	return &FeedbackRepository{DB: db}
+}
+
+const feedbackColumns = `id, trip_id, booking_id, driver_id, customer_id, rating, tags, comment, source, created_at`
+
+// Submit records f, copies it onto its trip and refreshes the driver's
+// averages in one transaction; window is how many of the driver's latest
+// ratings the rolling average covers. A second submission for the trip returns
+// ErrDuplicateKey. The rating is nil when the trip has no driver.
+func (r *FeedbackRepository) Submit(ctx context.Context, f *model.TripFeedback, window int) (*model.DriverRating, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	f.CreatedAt = time.Now()
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO trip_feedback (trip_id, booking_id, driver_id, customer_id, rating, tags, comment, source, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+		f.TripID, f.BookingID, f.DriverID, f.CustomerID, f.Rating, pq.Array(f.Tags), f.Comment, f.Source, f.CreatedAt, tenantID,
+	).Scan(&f.ID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, ErrDuplicateKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE trips SET rating = $1, feedback = $2 WHERE id = $3 AND tenant_id = $4`,
+		f.Rating, f.Comment, f.TripID, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var rating *model.DriverRating
+	if f.DriverID != nil {
+		rating = &model.DriverRating{DriverID: *f.DriverID}
+		err = tx.QueryRowContext(ctx, `
+            UPDATE drivers d SET rating_average = s.average, rating_count = s.count, rolling_rating = s.rolling
+            FROM (
+                SELECT COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count,
+                    (SELECT COALESCE(AVG(rating), 0) FROM (
+                        SELECT rating FROM trip_feedback WHERE driver_id = $1 AND tenant_id = $2
+                        ORDER BY created_at DESC, id DESC LIMIT $3
+                    ) latest) AS rolling
+                FROM trip_feedback WHERE driver_id = $1 AND tenant_id = $2
+            ) s
+            WHERE d.id = $1 AND d.tenant_id = $2
+            RETURNING d.rating_average, d.rating_count, d.rolling_rating`,
+			*f.DriverID, tenantID, window,
+		).Scan(&rating.Average, &rating.Count, &rating.RollingAverage)
+		if errors.Is(err, sql.ErrNoRows) {
+			rating = nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
+
+func (r *FeedbackRepository) GetByTrip(ctx context.Context, tripID uint) (*model.TripFeedback, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := scanFeedback(r.DB.QueryRowContext(ctx,
+		`SELECT `+feedbackColumns+` FROM trip_feedback WHERE trip_id = $1 AND tenant_id = $2`, tripID, tenantID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return f, err
+}
+
+// GetByDriver returns the driver's feedback, newest first.
+func (r *FeedbackRepository) GetByDriver(ctx context.Context, driverID int) ([]model.TripFeedback, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT `+feedbackColumns+` FROM trip_feedback WHERE driver_id = $1 AND tenant_id = $2 ORDER BY created_at DESC, id DESC`,
+		driverID, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feedback := []model.TripFeedback{}
+	for rows.Next() {
+		f, err := scanFeedback(rows)
+		if err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, *f)
+	}
+	return feedback, rows.Err()
+}
+
+func scanFeedback(row rowScanner) (*model.TripFeedback, error) {
+	var f model.TripFeedback
+	err := row.Scan(&f.ID, &f.TripID, &f.BookingID, &f.DriverID, &f.CustomerID, &f.Rating, pq.Array(&f.Tags), &f.Comment, &f.Source, &f.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// RaiseAlert opens a with its notifications unless the driver already has an
+// open alert, and reports whether it did.
+func (r *FeedbackRepository) RaiseAlert(ctx context.Context, a *model.DriverRatingAlert, notifications []model.Notification) (bool, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	a.CreatedAt = time.Now()
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO driver_rating_alerts (driver_id, rolling_rating, threshold, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (driver_id) WHERE acknowledged_at IS NULL DO NOTHING
+		RETURNING id`,
+		a.DriverID, a.RollingAverage, a.Threshold, a.CreatedAt, tenantID,
+	).Scan(&a.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for i := range notifications {
+		if err := enqueueNotification(ctx, tx, tenantID, &notifications[i]); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// GetAlerts returns the tenant's alerts, newest first; open limits them to
+// the unacknowledged ones.
+func (r *FeedbackRepository) GetAlerts(ctx context.Context, open bool) ([]model.DriverRatingAlert, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT a.id, a.driver_id, d.name, a.rolling_rating, a.threshold, a.created_at, a.acknowledged_at, a.acknowledged_by
+		FROM driver_rating_alerts a JOIN drivers d ON d.id = a.driver_id
+		WHERE a.tenant_id = $1 AND (NOT $2 OR a.acknowledged_at IS NULL)
+		ORDER BY a.created_at DESC, a.id DESC`,
+		tenantID, open,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []model.DriverRatingAlert{}
+	for rows.Next() {
+		var a model.DriverRatingAlert
+		if err := rows.Scan(&a.ID, &a.DriverID, &a.DriverName, &a.RollingAverage, &a.Threshold, &a.CreatedAt,
+			&a.AcknowledgedAt, &a.AcknowledgedBy); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// AcknowledgeAlert closes an open alert; ErrNotFound means there is no open
+// alert with that id.
+func (r *FeedbackRepository) AcknowledgeAlert(ctx context.Context, id int, by int64) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return restoredResult(r.DB.ExecContext(ctx,
+		`UPDATE driver_rating_alerts SET acknowledged_at = NOW(), acknowledged_by = $1
+		WHERE id = $2 AND tenant_id = $3 AND acknowledged_at IS NULL`,
+		by, id, tenantID,
+	))
+}
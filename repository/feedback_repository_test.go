@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedbackRepository_Submit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewFeedbackRepository(db)
+	bookingID, driverID := "BK1", 4
+	feedback := &model.TripFeedback{TripID: 9, BookingID: &bookingID, DriverID: &driverID, Rating: 2,
+		Tags: []string{model.TagLate}, Comment: "twenty minutes late", Source: model.FeedbackFromLink}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO trip_feedback`).
+		WithArgs(uint(9), &bookingID, &driverID, nil, 2, sqlmock.AnyArg(), "twenty minutes late", model.FeedbackFromLink, sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectExec(`UPDATE trips SET rating = \$1, feedback = \$2 WHERE id = \$3 AND tenant_id = \$4`).
+		WithArgs(2, "twenty minutes late", uint(9), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE drivers d SET rating_average = s.average, rating_count = s.count, rolling_rating = s.rolling`).
+		WithArgs(4, int64(1), 20).
+		WillReturnRows(sqlmock.NewRows([]string{"rating_average", "rating_count", "rolling_rating"}).AddRow(4.1, 12, 3.25))
+	mock.ExpectCommit()
+
+	rating, err := repo.Submit(tenantCtx(), feedback, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, feedback.ID)
+	assert.Equal(t, &model.DriverRating{DriverID: 4, Average: 4.1, Count: 12, RollingAverage: 3.25}, rating)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO trip_feedback`).WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	_, err = repo.Submit(tenantCtx(), &model.TripFeedback{TripID: 9, Rating: 5, Source: model.FeedbackFromStaff}, 20)
+	assert.ErrorIs(t, err, repository.ErrDuplicateKey)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFeedbackRepository_RaiseAlert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewFeedbackRepository(db)
+	notifications := []model.Notification{{Event: model.NotifyDriverRatingLow, Channel: model.ChannelEmail, Recipient: "ops@example.com"}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO driver_rating_alerts .* ON CONFLICT \(driver_id\) WHERE acknowledged_at IS NULL DO NOTHING`).
+		WithArgs(4, 3.2, 3.5, sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(`INSERT INTO notifications`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(30))
+	mock.ExpectCommit()
+
+	alert := &model.DriverRatingAlert{DriverID: 4, RollingAverage: 3.2, Threshold: 3.5}
+	raised, err := repo.RaiseAlert(tenantCtx(), alert, notifications)
+	assert.NoError(t, err)
+	assert.True(t, raised)
+	assert.Equal(t, 2, alert.ID)
+	assert.Equal(t, 30, notifications[0].ID)
+
+	// The driver already has an open alert, so nothing is inserted or sent.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO driver_rating_alerts`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	raised, err = repo.RaiseAlert(tenantCtx(), &model.DriverRatingAlert{DriverID: 4, RollingAverage: 3.1, Threshold: 3.5}, notifications)
+	assert.NoError(t, err)
+	assert.False(t, raised)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFeedbackRepository_Alerts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewFeedbackRepository(db)
+
+	mock.ExpectQuery(`FROM driver_rating_alerts a JOIN drivers d ON d.id = a.driver_id`).
+		WithArgs(int64(1), true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "driver_id", "name", "rolling_rating", "threshold", "created_at", "acknowledged_at", "acknowledged_by"}).
+			AddRow(2, 4, "Budi", 3.2, 3.5, time.Now(), nil, nil))
+	alerts, err := repo.GetAlerts(tenantCtx(), true)
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "Budi", alerts[0].DriverName)
+
+	mock.ExpectExec(`UPDATE driver_rating_alerts SET acknowledged_at = NOW\(\), acknowledged_by = \$1`).
+		WithArgs(int64(5), 2, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.AcknowledgeAlert(tenantCtx(), 2, 5))
+
+	mock.ExpectExec(`UPDATE driver_rating_alerts`).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.AcknowledgeAlert(tenantCtx(), 2, 5), repository.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type IdempotencyRepositoryInterface interface {
+	Reserve(ctx context.Context, k *model.IdempotencyKey) (bool, error)
+	FindByKey(ctx context.Context, key string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, key string, statusCode int, body []byte) error
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	DB *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{DB: db}
+}
+
+func (r *IdempotencyRepository) Reserve(ctx context.Context, k *model.IdempotencyKey) (bool, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.DB.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (key, method, path, fingerprint, status_code, created_at, expires_at, tenant_id)
+		VALUES ($1, $2, $3, $4, 0, $5, $6, $7)
+		ON CONFLICT (tenant_id, key) DO UPDATE SET
+			method = EXCLUDED.method,
+			path = EXCLUDED.path,
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = 0,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	`, k.Key, k.Method, k.Path, k.Fingerprint, k.CreatedAt, k.ExpiresAt, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepository) FindByKey(ctx context.Context, key string) (*model.IdempotencyKey, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var k model.IdempotencyKey
+	err = r.DB.QueryRowContext(ctx, `
+		SELECT key, method, path, fingerprint, status_code, COALESCE(response_body, ''), created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = $1 AND tenant_id = $2
+	`, key, tenantID).Scan(
+		&k.Key, &k.Method, &k.Path, &k.Fingerprint,
+		&k.StatusCode, &k.ResponseBody, &k.CreatedAt, &k.ExpiresAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_body = $2
+		WHERE key = $3 AND tenant_id = $4
+	`, statusCode, body, key, tenantID)
+	return err
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, key string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND tenant_id = $2 AND status_code = 0`, key, tenantID)
+	return err
+}
+
+// DeleteExpired is run by the retention job and spans every tenant.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
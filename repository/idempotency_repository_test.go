@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyRepository(db)
+	now := time.Now()
+	k := &model.IdempotencyKey{
+		Key:         "abc",
+		Method:      "POST",
+		Path:        "/payments",
+		Fingerprint: "fp",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	mock.ExpectExec(`INSERT INTO idempotency_keys`).
+		WithArgs("abc", "POST", "/payments", "fp", now, now.Add(time.Hour), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	reserved, err := repo.Reserve(tenantCtx(), k)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	mock.ExpectExec(`INSERT INTO idempotency_keys`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reserved, err = repo.Reserve(tenantCtx(), k)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+
+	mock.ExpectExec(`INSERT INTO idempotency_keys`).
+		WillReturnError(sql.ErrConnDone)
+
+	_, err = repo.Reserve(tenantCtx(), k)
+	assert.Equal(t, sql.ErrConnDone, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_FindByKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyRepository(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"key", "method", "path", "fingerprint", "status_code", "response_body", "created_at", "expires_at"}).
+		AddRow("abc", "POST", "/booking", "fp", 201, []byte(`{"id":"1"}`), now, now.Add(time.Hour))
+	mock.ExpectQuery(`SELECT key, method, path, fingerprint`).WithArgs("abc", int64(1)).WillReturnRows(rows)
+
+	k, err := repo.FindByKey(tenantCtx(), "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, 201, k.StatusCode)
+	assert.Equal(t, `{"id":"1"}`, string(k.ResponseBody))
+
+	mock.ExpectQuery(`SELECT key, method, path, fingerprint`).WithArgs("missing", int64(1)).WillReturnError(sql.ErrNoRows)
+
+	k, err = repo.FindByKey(tenantCtx(), "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, k)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_CompleteReleaseAndPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewIdempotencyRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE idempotency_keys`).
+		WithArgs(201, []byte("ok"), "abc", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE key = \$1 AND tenant_id = \$2 AND status_code = 0`).
+		WithArgs("abc", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE expires_at <= \$1`).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	assert.NoError(t, repo.Complete(tenantCtx(), "abc", 201, []byte("ok")))
+	assert.NoError(t, repo.Release(tenantCtx(), "abc"))
+
+	n, err := repo.DeleteExpired(tenantCtx(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,14 +2,15 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
 )
 
 type MaintenanceRepositoryInterface interface {
-	Create(*model.VehicleMaintenance) error
-	FindByVehicle(vehicleID int) ([]model.VehicleMaintenance, error)
-	Update(*model.VehicleMaintenance) error
-	Delete(id uint) error
+	Create(ctx context.Context, m *model.VehicleMaintenance) error
+	FindByVehicle(ctx context.Context, vehicleID int) ([]model.VehicleMaintenance, error)
+	Update(ctx context.Context, m *model.VehicleMaintenance) error
+	Delete(ctx context.Context, id uint) error
 }
 
 type MaintenanceRepository struct {
@@ -20,23 +21,33 @@ func NewMaintenanceRepository(db *sql.DB) *MaintenanceRepository {
 	return &MaintenanceRepository{DB: db}
 }
 
-func (r *MaintenanceRepository) Create(m *model.VehicleMaintenance) error {
+func (r *MaintenanceRepository) Create(ctx context.Context, m *model.VehicleMaintenance) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `
-        INSERT INTO vehicle_maintenance (vehicle_id, service_date, description, cost, mileage, service_type)
-        VALUES ($1, $2, $3, $4, $5, $6)`
+        INSERT INTO vehicle_maintenance (vehicle_id, service_date, description, cost, mileage, service_type, tenant_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
-	_, err := r.DB.Exec(query, m.VehicleID, m.ServiceDate, m.Description, m.Cost, m.Mileage, m.ServiceType)
+	_, err = r.DB.ExecContext(ctx, query, m.VehicleID, m.ServiceDate, m.Description, m.Cost, m.Mileage, m.ServiceType, tenantID)
 	return err
 }
 
-func (r *MaintenanceRepository) FindByVehicle(vehicleID int) ([]model.VehicleMaintenance, error) {
+func (r *MaintenanceRepository) FindByVehicle(ctx context.Context, vehicleID int) ([]model.VehicleMaintenance, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
         SELECT id, vehicle_id, service_date, description, cost , mileage , service_type
         FROM vehicle_maintenance
-        WHERE vehicle_id = $1
+        WHERE vehicle_id = $1 AND tenant_id = $2
         ORDER BY service_date DESC`
 
-	rows, err := r.DB.Query(query, vehicleID)
+	rows, err := r.DB.QueryContext(ctx, query, vehicleID, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +66,12 @@ func (r *MaintenanceRepository) FindByVehicle(vehicleID int) ([]model.VehicleMai
 	return list, nil
 }
 
-func (r *MaintenanceRepository) Update(m *model.VehicleMaintenance) error {
+func (r *MaintenanceRepository) Update(ctx context.Context, m *model.VehicleMaintenance) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	query := `
         UPDATE vehicle_maintenance
         SET vehicle_id = $1,
@@ -64,9 +80,9 @@ func (r *MaintenanceRepository) Update(m *model.VehicleMaintenance) error {
             cost = $4,
             mileage = $5,
             service_type = $6
-        WHERE id = $7`
+        WHERE id = $7 AND tenant_id = $8`
 
-	_, err := r.DB.Exec(query,
+	_, err = r.DB.ExecContext(ctx, query,
 		m.VehicleID,
 		m.ServiceDate,
 		m.Description,
@@ -74,13 +90,19 @@ func (r *MaintenanceRepository) Update(m *model.VehicleMaintenance) error {
 		m.Mileage,
 		m.ServiceType,
 		m.ID,
+		tenantID,
 	)
 
 	return err
 }
 
-func (r *MaintenanceRepository) Delete(id uint) error {
-	query := `DELETE FROM vehicle_maintenance WHERE id = $1`
-	_, err := r.DB.Exec(query, id)
+func (r *MaintenanceRepository) Delete(ctx context.Context, id uint) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM vehicle_maintenance WHERE id = $1 AND tenant_id = $2`
+	_, err = r.DB.ExecContext(ctx, query, id, tenantID)
 	return err
 }
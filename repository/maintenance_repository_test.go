@@ -28,11 +28,11 @@ func TestMaintenanceRepository_Create_Success(t *testing.T) {
 		ServiceType: "Maintenance",
 	}
 
-	mock.ExpectExec(`INSERT INTO vehicle_maintenance \(vehicle_id, service_date, description, cost, mileage, service_type\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)`).
-		WithArgs(maintenance.VehicleID, maintenance.ServiceDate, maintenance.Description, maintenance.Cost, maintenance.Mileage, maintenance.ServiceType).
+	mock.ExpectExec(`INSERT INTO vehicle_maintenance \(vehicle_id, service_date, description, cost, mileage, service_type, tenant_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`).
+		WithArgs(maintenance.VehicleID, maintenance.ServiceDate, maintenance.Description, maintenance.Cost, maintenance.Mileage, maintenance.ServiceType, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Create(maintenance)
+	err = repo.Create(tenantCtx(), maintenance)
 
 	assert.NoError(t, err)
 
@@ -56,11 +56,11 @@ func TestMaintenanceRepository_Create_Error(t *testing.T) {
 		ServiceType: "Maintenance",
 	}
 
-	mock.ExpectExec(`INSERT INTO vehicle_maintenance \(vehicle_id, service_date, description, cost, mileage, service_type\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)`).
-		WithArgs(maintenance.VehicleID, maintenance.ServiceDate, maintenance.Description, maintenance.Cost, maintenance.Mileage, maintenance.ServiceType).
+	mock.ExpectExec(`INSERT INTO vehicle_maintenance \(vehicle_id, service_date, description, cost, mileage, service_type, tenant_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`).
+		WithArgs(maintenance.VehicleID, maintenance.ServiceDate, maintenance.Description, maintenance.Cost, maintenance.Mileage, maintenance.ServiceType, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Create(maintenance)
+	err = repo.Create(tenantCtx(), maintenance)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -80,11 +80,11 @@ func TestMaintenanceRepository_FindByVehicle_Success(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "vehicle_id", "service_date", "description", "cost", "mileage", "service_type"}).
 		AddRow(1, 1, time.Now(), "Oil change", 100.0, 5000, "Maintenance")
 
-	mock.ExpectQuery(`SELECT id, vehicle_id, service_date, description, cost , mileage , service_type FROM vehicle_maintenance WHERE vehicle_id = \$1 ORDER BY service_date DESC`).
-		WithArgs(vehicleID).
+	mock.ExpectQuery(`SELECT id, vehicle_id, service_date, description, cost , mileage , service_type FROM vehicle_maintenance WHERE vehicle_id = \$1 AND tenant_id = \$2 ORDER BY service_date DESC`).
+		WithArgs(vehicleID, int64(1)).
 		WillReturnRows(rows)
 
-	maintenances, err := repo.FindByVehicle(vehicleID)
+	maintenances, err := repo.FindByVehicle(tenantCtx(), vehicleID)
 
 	assert.NoError(t, err)
 	assert.Len(t, maintenances, 1)
@@ -104,11 +104,11 @@ func TestMaintenanceRepository_FindByVehicle_Error(t *testing.T) {
 
 	vehicleID := 1
 
-	mock.ExpectQuery(`SELECT id, vehicle_id, service_date, description, cost , mileage , service_type FROM vehicle_maintenance WHERE vehicle_id = \$1 ORDER BY service_date DESC`).
-		WithArgs(vehicleID).
+	mock.ExpectQuery(`SELECT id, vehicle_id, service_date, description, cost , mileage , service_type FROM vehicle_maintenance WHERE vehicle_id = \$1 AND tenant_id = \$2 ORDER BY service_date DESC`).
+		WithArgs(vehicleID, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	maintenances, err := repo.FindByVehicle(vehicleID)
+	maintenances, err := repo.FindByVehicle(tenantCtx(), vehicleID)
 
 	assert.Error(t, err)
 	assert.Nil(t, maintenances)
@@ -136,10 +136,10 @@ func TestMaintenanceRepository_Update_Success(t *testing.T) {
 	}
 
 	mock.ExpectExec(`UPDATE vehicle_maintenance SET vehicle_id = \$1, service_date = \$2, description = \$3, cost = \$4, mileage = \$5, service_type = \$6 WHERE id = \$7`).
-		WithArgs(maintenance.VehicleID, maintenance.ServiceDate, maintenance.Description, maintenance.Cost, maintenance.Mileage, maintenance.ServiceType, maintenance.ID).
+		WithArgs(maintenance.VehicleID, maintenance.ServiceDate, maintenance.Description, maintenance.Cost, maintenance.Mileage, maintenance.ServiceType, maintenance.ID, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Update(maintenance)
+	err = repo.Update(tenantCtx(), maintenance)
 
 	assert.NoError(t, err)
 
@@ -165,10 +165,10 @@ func TestMaintenanceRepository_Update_Error(t *testing.T) {
 	}
 
 	mock.ExpectExec(`UPDATE vehicle_maintenance SET vehicle_id = \$1, service_date = \$2, description = \$3, cost = \$4, mileage = \$5, service_type = \$6 WHERE id = \$7`).
-		WithArgs(maintenance.VehicleID, maintenance.ServiceDate, maintenance.Description, maintenance.Cost, maintenance.Mileage, maintenance.ServiceType, maintenance.ID).
+		WithArgs(maintenance.VehicleID, maintenance.ServiceDate, maintenance.Description, maintenance.Cost, maintenance.Mileage, maintenance.ServiceType, maintenance.ID, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Update(maintenance)
+	err = repo.Update(tenantCtx(), maintenance)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -187,10 +187,10 @@ func TestMaintenanceRepository_Delete_Success(t *testing.T) {
 	id := uint(1)
 
 	mock.ExpectExec(`DELETE FROM vehicle_maintenance WHERE id = \$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id)
 
 	assert.NoError(t, err)
 
@@ -208,10 +208,10 @@ func TestMaintenanceRepository_Delete_Error(t *testing.T) {
 	id := uint(1)
 
 	mock.ExpectExec(`DELETE FROM vehicle_maintenance WHERE id = \$1`).
-		WithArgs(id).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	err = repo.Delete(id)
+	err = repo.Delete(tenantCtx(), id)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -227,10 +227,10 @@ func TestMaintenanceRepository_FindByVehicle_QueryError(t *testing.T) {
 	repo := repository.MaintenanceRepository{DB: db}
 
 	mock.ExpectQuery("SELECT .* FROM vehicle_maintenance WHERE vehicle_id = .* ORDER BY service_date DESC").
-		WithArgs(1).
+		WithArgs(1, int64(1)).
 		WillReturnError(fmt.Errorf("query failed"))
 
-	list, err := repo.FindByVehicle(1)
+	list, err := repo.FindByVehicle(tenantCtx(), 1)
 
 	assert.Error(t, err)
 	assert.Nil(t, list)
@@ -265,10 +265,10 @@ func TestMaintenanceRepository_FindByVehicle_ScanError(t *testing.T) {
 	)
 
 	mock.ExpectQuery(`FROM vehicle_maintenance`).
-		WithArgs(1).
+		WithArgs(1, int64(1)).
 		WillReturnRows(rows)
 
-	result, err := repo.FindByVehicle(1)
+	result, err := repo.FindByVehicle(tenantCtx(), 1)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+)
+
+type MemoryAssignmentsRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryAssignmentsRepository(s *MemoryStore) *MemoryAssignmentsRepository {
+	return &MemoryAssignmentsRepository{Store: s}
+}
+
+func (r *MemoryAssignmentsRepository) Create(ctx context.Context, a *model.DriverAssignment) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	a.ID = uint(r.Store.nextID("driver_assignments"))
+	r.Store.assignments = append(r.Store.assignments, memRow[model.DriverAssignment]{tenantID: tenantID, value: model.DriverAssignment{
+		ID:         a.ID,
+		VehicleID:  a.VehicleID,
+		StartDate:  a.StartDate,
+		EndDate:    a.EndDate,
+		TotalTrips: a.TotalTrips,
+		DriverName: a.DriverName,
+		Status:     a.Status,
+	}})
+	return nil
+}
+
+func (r *MemoryAssignmentsRepository) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.DriverAssignment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var list []model.DriverAssignment
+	for _, row := range r.Store.assignments {
+		if row.tenantID == tenantID && row.value.VehicleID == vehicleID {
+			list = append(list, row.value)
+		}
+	}
+	return list, nil
+}
+
+func (r *MemoryAssignmentsRepository) Update(ctx context.Context, a *model.DriverAssignment) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.assignments {
+		row := &r.Store.assignments[i]
+		if row.tenantID == tenantID && row.value.ID == a.ID {
+			row.value.VehicleID = a.VehicleID
+			row.value.StartDate = a.StartDate
+			row.value.EndDate = a.EndDate
+			row.value.TotalTrips = a.TotalTrips
+			row.value.DriverName = a.DriverName
+			row.value.Status = a.Status
+		}
+	}
+	return nil
+}
+
+func (r *MemoryAssignmentsRepository) Delete(ctx context.Context, id uint) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	kept := r.Store.assignments[:0]
+	for _, row := range r.Store.assignments {
+		if row.tenantID == tenantID && row.value.ID == id {
+			continue
+		}
+		kept = append(kept, row)
+	}
+	r.Store.assignments = kept
+	return nil
+}
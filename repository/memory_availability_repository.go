@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type MemoryAvailabilityRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryAvailabilityRepository(s *MemoryStore) *MemoryAvailabilityRepository {
+	return &MemoryAvailabilityRepository{Store: s}
+}
+
+// holdsSchedule reports whether b occupies its driver and vehicle, matching the
+// predicate of the booking exclusion constraints.
+func holdsSchedule(b model.Booking) bool {
+	return b.DeletedAt == nil && b.StartAt != nil && b.EndAt != nil &&
+		b.Status != model.BookingCancelled && b.Status != model.BookingNoShow
+}
+
+func sameRef(a, b *int) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+func overlapsWindow(start, end, from, to time.Time) bool {
+	return start.Before(to) && end.After(from)
+}
+
+func maintenanceBlock(m model.VehicleMaintenance) model.BusyBlock {
+	day := time.Date(m.ServiceDate.Year(), m.ServiceDate.Month(), m.ServiceDate.Day(), 0, 0, 0, 0, m.ServiceDate.Location())
+	return model.BusyBlock{Kind: model.BlockMaintenance, Ref: strconv.Itoa(int(m.ID)), StartAt: day, EndAt: day.AddDate(0, 0, 1)}
+}
+
+func (r *MemoryAvailabilityRepository) bookingBlocks(tenantID int64, matches func(model.Booking) bool, from, to time.Time) []model.BusyBlock {
+	var blocks []model.BusyBlock
+	for _, row := range r.Store.bookings {
+		b := row.value
+		if row.tenantID == tenantID && holdsSchedule(b) && matches(b) && overlapsWindow(*b.StartAt, *b.EndAt, from, to) {
+			blocks = append(blocks, model.BusyBlock{Kind: model.BlockBooking, Ref: b.ID, StartAt: *b.StartAt, EndAt: *b.EndAt})
+		}
+	}
+	return blocks
+}
+
+func (r *MemoryAvailabilityRepository) maintenanceBlocks(tenantID int64, vehicles map[int]bool, from, to time.Time) []model.BusyBlock {
+	var blocks []model.BusyBlock
+	for _, row := range r.Store.maintenance {
+		if row.tenantID != tenantID || !vehicles[int(row.value.VehicleID)] {
+			continue
+		}
+		if block := maintenanceBlock(row.value); overlapsWindow(block.StartAt, block.EndAt, from, to) {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func sortBlocks(blocks []model.BusyBlock) []model.BusyBlock {
+	if blocks == nil {
+		return []model.BusyBlock{}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].StartAt.Before(blocks[j].StartAt) })
+	return blocks
+}
+
+func (r *MemoryAvailabilityRepository) DriverBlocks(ctx context.Context, driverID int, from, to time.Time) ([]model.BusyBlock, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	blocks := r.bookingBlocks(tenantID, func(b model.Booking) bool { return sameRef(b.DriverID, &driverID) }, from, to)
+
+	for _, row := range r.Store.leaves {
+		l := row.value
+		if row.tenantID == tenantID && l.DriverID == driverID && overlapsWindow(l.StartAt, l.EndAt, from, to) {
+			blocks = append(blocks, model.BusyBlock{Kind: model.BlockLeave, Ref: strconv.Itoa(l.ID), StartAt: l.StartAt, EndAt: l.EndAt})
+		}
+	}
+
+	vehicles := map[int]bool{}
+	for _, row := range r.Store.cars {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil && row.value.DriverID == driverID {
+			vehicles[row.value.ID] = true
+		}
+	}
+	blocks = append(blocks, r.maintenanceBlocks(tenantID, vehicles, from, to)...)
+
+	return sortBlocks(blocks), nil
+}
+
+func (r *MemoryAvailabilityRepository) VehicleBlocks(ctx context.Context, vehicleID int, from, to time.Time) ([]model.BusyBlock, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	blocks := r.bookingBlocks(tenantID, func(b model.Booking) bool { return sameRef(b.VehicleID, &vehicleID) }, from, to)
+	blocks = append(blocks, r.maintenanceBlocks(tenantID, map[int]bool{vehicleID: true}, from, to)...)
+
+	return sortBlocks(blocks), nil
+}
+
+func (r *MemoryAvailabilityRepository) CreateLeave(ctx context.Context, l *model.DriverLeave) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	l.ID = r.Store.nextID("driver_leave")
+	l.CreatedAt = time.Now()
+	r.Store.leaves = append(r.Store.leaves, memRow[model.DriverLeave]{tenantID: tenantID, value: *l})
+	return nil
+}
+
+func (r *MemoryAvailabilityRepository) DeleteLeave(ctx context.Context, driverID, id int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i, row := range r.Store.leaves {
+		if row.tenantID == tenantID && row.value.ID == id && row.value.DriverID == driverID {
+			r.Store.leaves = append(r.Store.leaves[:i], r.Store.leaves[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
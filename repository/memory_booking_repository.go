@@ -0,0 +1,471 @@
+package repository
+
+import (
+	"auth-service/model"
+	"auth-service/utils"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryBookingRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryBookingRepository(s *MemoryStore) *MemoryBookingRepository {
+	return &MemoryBookingRepository{Store: s}
+}
+
+func (r *MemoryBookingRepository) find(tenantID int64, id string, deleted bool) *model.Booking {
+	for i := range r.Store.bookings {
+		row := &r.Store.bookings[i]
+		if row.tenantID == tenantID && row.value.ID == id && (row.value.DeletedAt != nil) == deleted {
+			return &row.value
+		}
+	}
+	return nil
+}
+
+// overlaps mirrors the exclusion constraints on booking: two scheduled bookings
+// may not share a driver or vehicle over the same window.
+func (r *MemoryBookingRepository) overlaps(tenantID int64, b *model.Booking) bool {
+	if !holdsSchedule(*b) {
+		return false
+	}
+	for _, row := range r.Store.bookings {
+		other := row.value
+		if row.tenantID != tenantID || other.ID == b.ID || !holdsSchedule(other) {
+			continue
+		}
+		if !sameRef(b.DriverID, other.DriverID) && !sameRef(b.VehicleID, other.VehicleID) {
+			continue
+		}
+		if other.StartAt.Before(*b.EndAt) && other.EndAt.After(*b.StartAt) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryBookingRepository) Create(ctx context.Context, b *model.Booking) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.CreatedAt = time.Now()
+	b.UpdatedAt = time.Now()
+	b.Version = 1
+
+	normalizeBooking(b)
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if b.ID == "" {
+		b.ID = utils.BookingCode(int64(r.Store.nextID("booking_code_seq")))
+	}
+
+	for _, row := range r.Store.bookings {
+		if row.value.ID == b.ID {
+			return ErrDuplicateKey
+		}
+		if b.QuoteID != nil && sameRef(row.value.QuoteID, b.QuoteID) {
+			return ErrQuoteRedeemed
+		}
+	}
+	if r.overlaps(tenantID, b) {
+		return ErrScheduleConflict
+	}
+	if b.PromoCode != nil {
+		if err := r.Store.redeemPromotion(tenantID, b); err != nil {
+			return err
+		}
+	}
+
+	stored := *b
+	stored.DeletedAt = nil
+	stored.Stops = nil
+	r.Store.bookings = append(r.Store.bookings, memRow[model.Booking]{tenantID: tenantID, value: stored})
+	r.saveStops(tenantID, b)
+	return nil
+}
+
+// saveStops replaces the stops held for b, mirroring the delete and insert
+// done by the SQL repository.
+func (r *MemoryBookingRepository) saveStops(tenantID int64, b *model.Booking) {
+	stops := r.Store.stops[:0]
+	for _, row := range r.Store.stops {
+		if row.tenantID != tenantID || row.value.BookingID != b.ID {
+			stops = append(stops, row)
+		}
+	}
+	for i := range b.Stops {
+		b.Stops[i].BookingID = b.ID
+		stops = append(stops, memRow[model.BookingStop]{tenantID: tenantID, value: b.Stops[i]})
+	}
+	r.Store.stops = stops
+}
+
+func (r *MemoryBookingRepository) GetAll(ctx context.Context) ([]model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var bookings []model.Booking
+	for _, row := range r.Store.bookings {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil {
+			bookings = append(bookings, row.value)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *MemoryBookingRepository) GetByPickupArea(ctx context.Context, box model.GeoBox) ([]model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var bookings []model.Booking
+	for _, row := range r.Store.bookings {
+		b := row.value
+		if row.tenantID == tenantID && b.DeletedAt == nil && b.PickupLat != nil && b.PickupLng != nil && box.Contains(*b.PickupLat, *b.PickupLng) {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *MemoryBookingRepository) GetByID(ctx context.Context, id string) (*model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	b := r.find(tenantID, id, false)
+	if b == nil {
+		return nil, nil
+	}
+	found := *b
+	for _, row := range r.Store.stops {
+		if row.tenantID == tenantID && row.value.BookingID == id {
+			found.Stops = append(found.Stops, row.value)
+		}
+	}
+	sort.Slice(found.Stops, func(i, j int) bool { return found.Stops[i].Position < found.Stops[j].Position })
+	return &found, nil
+}
+
+func (r *MemoryBookingRepository) UpdateStop(ctx context.Context, bookingID string, stop *model.BookingStop, from string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.stops {
+		row := &r.Store.stops[i]
+		if row.tenantID == tenantID && row.value.BookingID == bookingID && row.value.Position == stop.Position {
+			if row.value.Status != from {
+				return ErrVersionConflict
+			}
+			row.value.Status = stop.Status
+			row.value.ArrivedAt = stop.ArrivedAt
+			row.value.DepartedAt = stop.DepartedAt
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryBookingRepository) Update(ctx context.Context, b *model.Booking) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.UpdatedAt = time.Now()
+
+	normalizeBooking(b)
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, b.ID, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != b.Version {
+		return ErrVersionConflict
+	}
+	if r.overlaps(tenantID, b) {
+		return ErrScheduleConflict
+	}
+
+	stored.Customer = b.Customer
+	stored.CustomerID = b.CustomerID
+	stored.Driver = b.Driver
+	stored.DriverID = b.DriverID
+	stored.VehicleID = b.VehicleID
+	stored.Place = b.Place
+	stored.Date = b.Date
+	stored.StartAt = b.StartAt
+	stored.EndAt = b.EndAt
+	stored.Price = b.Price
+	stored.Status = b.Status
+	stored.Payment = b.Payment
+	stored.PhoneNumber = b.PhoneNumber
+	stored.PickupLocation = b.PickupLocation
+	stored.PickupLat = b.PickupLat
+	stored.PickupLng = b.PickupLng
+	stored.CarTypeID = b.CarTypeID
+	stored.DropLocation = b.DropLocation
+	stored.DropLat = b.DropLat
+	stored.DropLng = b.DropLng
+	stored.DistanceKM = b.DistanceKM
+	stored.PickupTime = b.PickupTime
+	stored.Amount = b.Amount
+	stored.Notes = b.Notes
+	stored.UpdatedAt = b.UpdatedAt
+	stored.Version++
+	if b.Stops != nil {
+		r.saveStops(tenantID, b)
+	}
+
+	b.Version++
+	return nil
+}
+
+func (r *MemoryBookingRepository) Delete(ctx context.Context, id string, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != version {
+		return ErrVersionConflict
+	}
+
+	now := time.Now()
+	stored.DeletedAt = &now
+	stored.Version++
+	return nil
+}
+
+func (r *MemoryBookingRepository) GetDeleted(ctx context.Context) ([]model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var bookings []model.Booking
+	for _, row := range r.Store.bookings {
+		if row.tenantID == tenantID && row.value.DeletedAt != nil {
+			bookings = append(bookings, row.value)
+		}
+	}
+	sortByDeletedAt(bookings, func(b model.Booking) *time.Time { return b.DeletedAt })
+	return bookings, nil
+}
+
+func (r *MemoryBookingRepository) Restore(ctx context.Context, id string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, true)
+	if stored == nil {
+		return ErrNotFound
+	}
+
+	stored.DeletedAt = nil
+	stored.Version++
+	return nil
+}
+
+// PurgeDeleted is run by the retention job and spans every tenant.
+func (r *MemoryBookingRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	var n int64
+	r.Store.bookings, n = purgeRows(r.Store.bookings, func(b model.Booking) *time.Time { return b.DeletedAt }, before)
+
+	// Mirrors ON DELETE CASCADE on booking_status_history, booking_stops,
+	// dispatch_offers, promotion_redemptions and booking_cancellations, and ON DELETE SET NULL on
+	// recurring_occurrences, notifications, trips, trip_feedback,
+	// corporate_invoice_lines and payment.
+	remaining := map[string]bool{}
+	for _, row := range r.Store.bookings {
+		remaining[row.value.ID] = true
+	}
+	statuses := r.Store.statuses[:0]
+	for _, row := range r.Store.statuses {
+		if remaining[row.value.BookingID] {
+			statuses = append(statuses, row)
+		}
+	}
+	r.Store.statuses = statuses
+	stops := r.Store.stops[:0]
+	for _, row := range r.Store.stops {
+		if remaining[row.value.BookingID] {
+			stops = append(stops, row)
+		}
+	}
+	r.Store.stops = stops
+	offers := r.Store.offers[:0]
+	for _, row := range r.Store.offers {
+		if remaining[row.value.BookingID] {
+			offers = append(offers, row)
+		}
+	}
+	r.Store.offers = offers
+	redemptions := r.Store.redemptions[:0]
+	for _, row := range r.Store.redemptions {
+		if remaining[row.value.BookingID] {
+			redemptions = append(redemptions, row)
+		}
+	}
+	r.Store.redemptions = redemptions
+	cancellations := r.Store.cancellations[:0]
+	for _, row := range r.Store.cancellations {
+		if remaining[row.value.BookingID] {
+			cancellations = append(cancellations, row)
+		}
+	}
+	r.Store.cancellations = cancellations
+	for i := range r.Store.occurrences {
+		if o := &r.Store.occurrences[i].value; o.BookingID != nil && !remaining[*o.BookingID] {
+			o.BookingID = nil
+		}
+	}
+	for i := range r.Store.notifications {
+		if n := &r.Store.notifications[i].value; n.BookingID != nil && !remaining[*n.BookingID] {
+			n.BookingID = nil
+		}
+	}
+	for i := range r.Store.trips {
+		if t := &r.Store.trips[i].value; t.BookingID != nil && !remaining[*t.BookingID] {
+			t.BookingID = nil
+		}
+	}
+	for i := range r.Store.feedback {
+		if f := &r.Store.feedback[i].value; f.BookingID != nil && !remaining[*f.BookingID] {
+			f.BookingID = nil
+		}
+	}
+	for i := range r.Store.invoiceLines {
+		if l := &r.Store.invoiceLines[i].value; l.BookingID != nil && !remaining[*l.BookingID] {
+			l.BookingID = nil
+		}
+	}
+	for i := range r.Store.payments {
+		if p := &r.Store.payments[i].value; p.BookingID != nil && !remaining[*p.BookingID] {
+			p.BookingID = nil
+		}
+	}
+	return n, nil
+}
+
+func (r *MemoryBookingRepository) Transition(ctx context.Context, b *model.Booking, change *model.BookingStatusChange) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, b.ID, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != b.Version {
+		return ErrVersionConflict
+	}
+	var offer *model.DispatchOffer
+	if change.Offer != nil {
+		if offer = r.Store.pendingOffer(tenantID, change.Offer.ID); offer == nil {
+			return ErrVersionConflict
+		}
+	}
+	if change.Cancellation != nil {
+		for i := range change.Cancellation.Refunds {
+			if r.Store.refundable(tenantID, &change.Cancellation.Refunds[i]) == nil {
+				return ErrRefundExceedsCaptured
+			}
+		}
+	}
+
+	b.UpdatedAt = time.Now()
+	stored.Status = b.Status
+	stored.Driver = b.Driver
+	stored.DriverID = b.DriverID
+	stored.UpdatedAt = b.UpdatedAt
+	stored.Version++
+
+	change.ID = int64(r.Store.nextID("booking_status_history"))
+	change.BookingID = b.ID
+	change.ChangedAt = b.UpdatedAt
+	if offer != nil {
+		r.Store.respondOffer(offer, change.Offer)
+	}
+	r.Store.statuses = append(r.Store.statuses, memRow[model.BookingStatusChange]{tenantID: tenantID, value: *change})
+	if change.Cancellation != nil {
+		r.Store.settleCancellation(tenantID, b, change.Cancellation)
+	}
+	for i := range change.Notifications {
+		r.Store.enqueueNotification(tenantID, &change.Notifications[i])
+	}
+	if change.Trip != nil {
+		r.Store.saveTrip(tenantID, change.Trip)
+	}
+
+	b.Version++
+	return nil
+}
+
+func (r *MemoryBookingRepository) GetStatusHistory(ctx context.Context, id string) ([]model.BookingStatusChange, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	history := []model.BookingStatusChange{}
+	for _, row := range r.Store.statuses {
+		if row.tenantID == tenantID && row.value.BookingID == id {
+			history = append(history, row.value)
+		}
+	}
+	return history, nil
+}
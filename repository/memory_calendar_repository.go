@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryCalendarRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCalendarRepository(s *MemoryStore) *MemoryCalendarRepository {
+	return &MemoryCalendarRepository{Store: s}
+}
+
+func (r *MemoryCalendarRepository) GetFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, f := range r.Store.calendarFeeds {
+		if f.TenantID == tenantID && f.Kind == kind && f.SubjectID == subjectID {
+			found := f
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryCalendarRepository) SaveFeed(ctx context.Context, f *model.CalendarFeed) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	f.TenantID = tenantID
+	f.CreatedAt = time.Now()
+	for i, existing := range r.Store.calendarFeeds {
+		if existing.TenantID == tenantID && existing.Kind == f.Kind && existing.SubjectID == f.SubjectID {
+			r.Store.calendarFeeds[i] = *f
+			return nil
+		}
+	}
+	r.Store.calendarFeeds = append(r.Store.calendarFeeds, *f)
+	return nil
+}
+
+func (r *MemoryCalendarRepository) DeleteFeed(ctx context.Context, kind string, subjectID int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i, f := range r.Store.calendarFeeds {
+		if f.TenantID == tenantID && f.Kind == kind && f.SubjectID == subjectID {
+			r.Store.calendarFeeds = append(r.Store.calendarFeeds[:i], r.Store.calendarFeeds[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryCalendarRepository) FindFeed(ctx context.Context, token string) (*model.CalendarFeed, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, f := range r.Store.calendarFeeds {
+		if f.Token == token {
+			found := f
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryCalendarRepository) DriverSchedule(ctx context.Context, driverID int, from time.Time) (*model.CalendarSchedule, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var s *model.CalendarSchedule
+	for _, row := range r.Store.drivers {
+		if row.tenantID == tenantID && row.value.ID == driverID && row.value.DeletedAt == nil {
+			s = &model.CalendarSchedule{Name: row.value.Name}
+		}
+	}
+	if s == nil {
+		return nil, nil
+	}
+
+	vehicles := map[int]bool{}
+	for _, row := range r.Store.cars {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil && row.value.DriverID == driverID {
+			vehicles[row.value.ID] = true
+		}
+	}
+
+	s.Bookings = r.scheduleBookings(tenantID, func(b model.Booking) bool { return sameRef(b.DriverID, &driverID) }, from)
+	s.Assignments = r.scheduleAssignments(tenantID, func(a model.DriverAssignment) bool { return a.DriverName == s.Name }, from)
+	s.Maintenance = r.scheduleMaintenance(tenantID, vehicles, from)
+	return s, nil
+}
+
+func (r *MemoryCalendarRepository) VehicleSchedule(ctx context.Context, vehicleID int, from time.Time) (*model.CalendarSchedule, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var s *model.CalendarSchedule
+	for _, row := range r.Store.cars {
+		c := row.value
+		if row.tenantID == tenantID && c.ID == vehicleID && c.DeletedAt == nil {
+			s = &model.CalendarSchedule{Name: vehicleName(c.Brand, c.Model, c.PlateNumber)}
+		}
+	}
+	if s == nil {
+		return nil, nil
+	}
+
+	s.Bookings = r.scheduleBookings(tenantID, func(b model.Booking) bool { return sameRef(b.VehicleID, &vehicleID) }, from)
+	s.Assignments = r.scheduleAssignments(tenantID, func(a model.DriverAssignment) bool { return int(a.VehicleID) == vehicleID }, from)
+	s.Maintenance = r.scheduleMaintenance(tenantID, map[int]bool{vehicleID: true}, from)
+	return s, nil
+}
+
+// startOfDay matches date_trunc('day', t).
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (r *MemoryCalendarRepository) scheduleBookings(tenantID int64, matches func(model.Booking) bool, from time.Time) []model.Booking {
+	bookings := []model.Booking{}
+	for _, row := range r.Store.bookings {
+		b := row.value
+		if row.tenantID == tenantID && holdsSchedule(b) && matches(b) && b.EndAt.After(from) {
+			bookings = append(bookings, b)
+		}
+	}
+	sort.SliceStable(bookings, func(i, j int) bool { return bookings[i].StartAt.Before(*bookings[j].StartAt) })
+	return bookings
+}
+
+func (r *MemoryCalendarRepository) scheduleAssignments(tenantID int64, matches func(model.DriverAssignment) bool, from time.Time) []model.DriverAssignment {
+	assignments := []model.DriverAssignment{}
+	for _, row := range r.Store.assignments {
+		if row.tenantID == tenantID && matches(row.value) && !row.value.EndDate.Before(startOfDay(from)) {
+			assignments = append(assignments, row.value)
+		}
+	}
+	sort.SliceStable(assignments, func(i, j int) bool { return assignments[i].StartDate.Before(assignments[j].StartDate) })
+	return assignments
+}
+
+func (r *MemoryCalendarRepository) scheduleMaintenance(tenantID int64, vehicles map[int]bool, from time.Time) []model.VehicleMaintenance {
+	records := []model.VehicleMaintenance{}
+	for _, row := range r.Store.maintenance {
+		if row.tenantID == tenantID && vehicles[int(row.value.VehicleID)] && !row.value.ServiceDate.Before(startOfDay(from)) {
+			records = append(records, row.value)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].ServiceDate.Before(records[j].ServiceDate) })
+	return records
+}
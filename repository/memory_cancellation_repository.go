@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"time"
+)
+
+type MemoryCancellationRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCancellationRepository(s *MemoryStore) *MemoryCancellationRepository {
+	return &MemoryCancellationRepository{Store: s}
+}
+
+func (r *MemoryCancellationRepository) GetPolicy(ctx context.Context) (*model.CancellationPolicy, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.policies {
+		if row.tenantID == tenantID {
+			p := row.value
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryCancellationRepository) SavePolicy(ctx context.Context, p *model.CancellationPolicy) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.UpdatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.policies {
+		if r.Store.policies[i].tenantID == tenantID {
+			r.Store.policies[i].value = *p
+			return nil
+		}
+	}
+	r.Store.policies = append(r.Store.policies, memRow[model.CancellationPolicy]{tenantID: tenantID, value: *p})
+	return nil
+}
+
+func (r *MemoryCancellationRepository) GetByBooking(ctx context.Context, bookingID string) (*model.BookingCancellation, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.cancellations {
+		if row.tenantID == tenantID && row.value.BookingID == bookingID {
+			c := row.value
+			c.Refunds = []model.PaymentRefund{}
+			for _, rf := range r.Store.refunds {
+				if rf.tenantID == tenantID && rf.value.CancellationID != nil && *rf.value.CancellationID == c.ID {
+					c.Refunds = append(c.Refunds, rf.value)
+				}
+			}
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// settleCancellation mirrors the Postgres transition transaction and must be
+// called with the write lock held, after the refunds of c have been checked
+// with refundable.
+func (s *MemoryStore) settleCancellation(tenantID int64, b *model.Booking, c *model.BookingCancellation) {
+	if fee := cancellationFee(b, c); fee != nil {
+		id := s.nextID("payment")
+		fee.PaymentID = id
+		fee.PaymentDate = time.Now().UTC().Format(time.RFC3339)
+		fee.Version = 1
+		s.payments = append(s.payments, memRow[model.Payment]{tenantID: tenantID, value: *fee})
+		c.FeePaymentID = &id
+	}
+
+	c.ID = s.nextID("booking_cancellations")
+	c.BookingID = b.ID
+	c.CreatedAt = b.UpdatedAt
+	for i := range c.Refunds {
+		c.Refunds[i].CancellationID = &c.ID
+		s.addRefund(tenantID, &c.Refunds[i])
+	}
+	s.cancellations = append(s.cancellations, memRow[model.BookingCancellation]{tenantID: tenantID, value: *c})
+	s.syncBookingPayment(tenantID, &b.ID)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+)
+
+type MemoryCarModelRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCarModelRepository(s *MemoryStore) *MemoryCarModelRepository {
+	return &MemoryCarModelRepository{Store: s}
+}
+
+func (r *MemoryCarModelRepository) FindAll(ctx context.Context) ([]model.CarModel, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var list []model.CarModel
+	for _, row := range r.Store.carModels {
+		if row.tenantID == tenantID {
+			list = append(list, row.value)
+		}
+	}
+	return list, nil
+}
+
+func (r *MemoryCarModelRepository) GetByID(ctx context.Context, id int) (*model.CarModel, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.carModels {
+		if row.tenantID == tenantID && row.value.ID == id {
+			cm := row.value
+			return &cm, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryCarModelRepository) Create(ctx context.Context, cm *model.CarModel) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	cm.ID = r.Store.nextID("car_model")
+	r.Store.carModels = append(r.Store.carModels, memRow[model.CarModel]{tenantID: tenantID, value: *cm})
+	return nil
+}
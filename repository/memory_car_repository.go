@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"time"
+)
+
+type MemoryCarRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCarRepository(s *MemoryStore) *MemoryCarRepository {
+	return &MemoryCarRepository{Store: s}
+}
+
+func (r *MemoryCarRepository) find(tenantID int64, id int, deleted bool) *model.Car {
+	for i := range r.Store.cars {
+		row := &r.Store.cars[i]
+		if row.tenantID == tenantID && row.value.ID == id && (row.value.DeletedAt != nil) == deleted {
+			return &row.value
+		}
+	}
+	return nil
+}
+
+func (r *MemoryCarRepository) GetAll(ctx context.Context) ([]model.Car, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var cars []model.Car
+	for _, row := range r.Store.cars {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil {
+			cars = append(cars, row.value)
+		}
+	}
+	return cars, nil
+}
+
+func (r *MemoryCarRepository) GetByID(ctx context.Context, id int) (*model.Car, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	v := r.find(tenantID, id, false)
+	if v == nil {
+		return nil, nil
+	}
+	found := *v
+	return &found, nil
+}
+
+func (r *MemoryCarRepository) Create(ctx context.Context, v model.Car) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	r.Store.cars = append(r.Store.cars, memRow[model.Car]{tenantID: tenantID, value: model.Car{
+		ID:                  r.Store.nextID("vehicles"),
+		Brand:               v.Brand,
+		Model:               v.Model,
+		Year:                v.Year,
+		PlateNumber:         v.PlateNumber,
+		Capacity:            v.Capacity,
+		Color:               v.Color,
+		DriverID:            v.DriverID,
+		LastMaintenanceDate: v.LastMaintenanceDate,
+		CurrentKM:           v.CurrentKM,
+		Version:             1,
+	}})
+	return nil
+}
+
+func (r *MemoryCarRepository) Update(ctx context.Context, id int, v model.Car) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != v.Version {
+		return ErrVersionConflict
+	}
+
+	stored.Brand = v.Brand
+	stored.Model = v.Model
+	stored.Year = v.Year
+	stored.PlateNumber = v.PlateNumber
+	stored.Capacity = v.Capacity
+	stored.Color = v.Color
+	stored.DriverID = v.DriverID
+	stored.LastMaintenanceDate = v.LastMaintenanceDate
+	stored.CurrentKM = v.CurrentKM
+	stored.Version++
+	return nil
+}
+
+func (r *MemoryCarRepository) Delete(ctx context.Context, id int, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != version {
+		return ErrVersionConflict
+	}
+
+	now := time.Now()
+	stored.DeletedAt = &now
+	stored.Version++
+	return nil
+}
+
+func (r *MemoryCarRepository) GetDeleted(ctx context.Context) ([]model.Car, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var cars []model.Car
+	for _, row := range r.Store.cars {
+		if row.tenantID == tenantID && row.value.DeletedAt != nil {
+			cars = append(cars, row.value)
+		}
+	}
+	sortByDeletedAt(cars, func(v model.Car) *time.Time { return v.DeletedAt })
+	return cars, nil
+}
+
+func (r *MemoryCarRepository) Restore(ctx context.Context, id int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, true)
+	if stored == nil {
+		return ErrNotFound
+	}
+
+	stored.DeletedAt = nil
+	stored.Version++
+	return nil
+}
+
+// PurgeDeleted is run by the retention job and spans every tenant.
+func (r *MemoryCarRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	var n int64
+	r.Store.cars, n = purgeRows(r.Store.cars, func(v model.Car) *time.Time { return v.DeletedAt }, before)
+
+	// Mirrors ON DELETE SET NULL on trips.vehicle_id.
+	live := map[int]bool{}
+	for _, row := range r.Store.cars {
+		live[row.value.ID] = true
+	}
+	for i := range r.Store.trips {
+		if t := &r.Store.trips[i].value; t.VehicleID != 0 && !live[int(t.VehicleID)] {
+			t.VehicleID = 0
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"strconv"
+)
+
+type MemoryCarTypeRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCarTypeRepository(s *MemoryStore) *MemoryCarTypeRepository {
+	return &MemoryCarTypeRepository{Store: s}
+}
+
+func (r *MemoryCarTypeRepository) FindAll(ctx context.Context) ([]model.CarType, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var list []model.CarType
+	for _, row := range r.Store.carTypes {
+		if row.tenantID == tenantID {
+			list = append(list, row.value)
+		}
+	}
+	return list, nil
+}
+
+func (r *MemoryCarTypeRepository) GetByID(ctx context.Context, id int) (*model.CarType, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.carTypes {
+		if row.tenantID == tenantID && row.value.ID == strconv.Itoa(id) {
+			ct := row.value
+			return &ct, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryCarTypeRepository) Create(ctx context.Context, ct model.CarType) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	r.Store.carTypes = append(r.Store.carTypes, memRow[model.CarType]{tenantID: tenantID, value: model.CarType{
+		ID:       strconv.Itoa(r.Store.nextID("car_type")),
+		TypeName: ct.TypeName,
+	}})
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryPopularDestinationRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryPopularDestinationRepository(s *MemoryStore) *MemoryPopularDestinationRepository {
+	return &MemoryPopularDestinationRepository{Store: s}
+}
+
+func (r *MemoryPopularDestinationRepository) GetAll(ctx context.Context) ([]model.PopularDestination, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var result []model.PopularDestination
+	for _, row := range r.Store.popular {
+		if row.tenantID == tenantID {
+			result = append(result, row.value)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Bookings > result[j].Bookings
+	})
+	return result, nil
+}
+
+func (r *MemoryPopularDestinationRepository) Add(ctx context.Context, pd model.PopularDestination) (*model.PopularDestination, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	pd.ID = r.Store.nextID("popular_destinations")
+	pd.CreatedAt = time.Now()
+	r.Store.popular = append(r.Store.popular, memRow[model.PopularDestination]{tenantID: tenantID, value: pd})
+	return &pd, nil
+}
+
+func (r *MemoryPopularDestinationRepository) UpdateBookings(ctx context.Context, id int, bookings int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.popular {
+		row := &r.Store.popular[i]
+		if row.tenantID == tenantID && row.value.ID == id {
+			row.value.Bookings = bookings
+		}
+	}
+	return nil
+}
+
+func (r *MemoryPopularDestinationRepository) Delete(ctx context.Context, id int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	kept := r.Store.popular[:0]
+	for _, row := range r.Store.popular {
+		if row.tenantID == tenantID && row.value.ID == id {
+			continue
+		}
+		kept = append(kept, row)
+	}
+	r.Store.popular = kept
+	return nil
+}
@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryCorporateRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCorporateRepository(s *MemoryStore) *MemoryCorporateRepository {
+	return &MemoryCorporateRepository{Store: s}
+}
+
+func copyAccount(a model.CorporateAccount) model.CorporateAccount {
+	a.BookerIDs = append([]int{}, a.BookerIDs...)
+	a.CostCentres = append([]string{}, a.CostCentres...)
+	return a
+}
+
+func sortAccounts(accounts []model.CorporateAccount) {
+	sort.SliceStable(accounts, func(i, j int) bool {
+		if accounts[i].Name != accounts[j].Name {
+			return accounts[i].Name < accounts[j].Name
+		}
+		return accounts[i].ID < accounts[j].ID
+	})
+}
+
+func (r *MemoryCorporateRepository) GetAccounts(ctx context.Context) ([]model.CorporateAccount, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	accounts := []model.CorporateAccount{}
+	for _, row := range r.Store.accounts {
+		if row.tenantID == tenantID {
+			accounts = append(accounts, copyAccount(row.value))
+		}
+	}
+	sortAccounts(accounts)
+	return accounts, nil
+}
+
+// findAccount must be called with the lock held.
+func (s *MemoryStore) findAccount(tenantID int64, match func(a *model.CorporateAccount) bool) *model.CorporateAccount {
+	for i := range s.accounts {
+		if s.accounts[i].tenantID == tenantID && match(&s.accounts[i].value) {
+			return &s.accounts[i].value
+		}
+	}
+	return nil
+}
+
+func (r *MemoryCorporateRepository) GetAccount(ctx context.Context, id int) (*model.CorporateAccount, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	if a := r.Store.findAccount(tenantID, func(a *model.CorporateAccount) bool { return a.ID == id }); a != nil {
+		found := copyAccount(*a)
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *MemoryCorporateRepository) CreateAccount(ctx context.Context, a *model.CorporateAccount) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeAccount(a)
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if r.Store.findAccount(tenantID, func(existing *model.CorporateAccount) bool { return existing.Name == a.Name }) != nil {
+		return ErrDuplicateKey
+	}
+
+	a.ID = r.Store.nextID("corporate_accounts")
+	a.CreatedAt = time.Now()
+	a.UpdatedAt = a.CreatedAt
+	a.Version = 1
+	a.TenantID = tenantID
+	r.Store.accounts = append(r.Store.accounts, memRow[model.CorporateAccount]{tenantID: tenantID, value: copyAccount(*a)})
+	return nil
+}
+
+func (r *MemoryCorporateRepository) UpdateAccount(ctx context.Context, a *model.CorporateAccount) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeAccount(a)
+	a.UpdatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if r.Store.findAccount(tenantID, func(existing *model.CorporateAccount) bool {
+		return existing.Name == a.Name && existing.ID != a.ID
+	}) != nil {
+		return ErrDuplicateKey
+	}
+
+	stored := r.Store.findAccount(tenantID, func(existing *model.CorporateAccount) bool { return existing.ID == a.ID })
+	if stored == nil || stored.Version != a.Version {
+		return ErrVersionConflict
+	}
+
+	createdAt := stored.CreatedAt
+	*stored = copyAccount(*a)
+	stored.CreatedAt = createdAt
+	stored.TenantID = tenantID
+	stored.Version++
+
+	a.Version++
+	return nil
+}
+
+// invoicedBookings must be called with the lock held.
+func (s *MemoryStore) invoicedBookings() map[string]bool {
+	invoiced := map[string]bool{}
+	for _, row := range s.invoiceLines {
+		if row.value.BookingID != nil {
+			invoiced[*row.value.BookingID] = true
+		}
+	}
+	return invoiced
+}
+
+func (r *MemoryCorporateRepository) GetBalance(ctx context.Context, accountID int) (*model.AccountBalance, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	b := model.AccountBalance{AccountID: accountID}
+	invoiced := r.Store.invoicedBookings()
+	for _, row := range r.Store.bookings {
+		bk := row.value
+		if row.tenantID != tenantID || bk.CorporateAccountID == nil || *bk.CorporateAccountID != accountID || bk.DeletedAt != nil ||
+			bk.Status == model.BookingCancelled || bk.Status == model.BookingNoShow || invoiced[bk.ID] {
+			continue
+		}
+		if bk.Amount != nil {
+			b.Unbilled += *bk.Amount
+		}
+	}
+	for _, row := range r.Store.invoices {
+		if inv := row.value; row.tenantID == tenantID && inv.AccountID == accountID && inv.Status != model.InvoiceVoid {
+			b.Outstanding += inv.Total - inv.AmountPaid
+		}
+	}
+	return &b, nil
+}
+
+func (r *MemoryCorporateRepository) GetBillable(ctx context.Context) ([]model.CorporateAccount, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	accounts := []model.CorporateAccount{}
+	for _, row := range r.Store.accounts {
+		if row.value.Active {
+			accounts = append(accounts, copyAccount(row.value))
+		}
+	}
+	sortAccounts(accounts)
+	return accounts, nil
+}
+
+func (r *MemoryCorporateRepository) GetUninvoiced(ctx context.Context, accountID int, before time.Time) ([]model.InvoiceLine, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	// The last completed trip of each booking sets the fare and the date.
+	lastTrip := map[string]model.VehicleTrip{}
+	for _, row := range r.Store.trips {
+		t := row.value
+		if row.tenantID != tenantID || t.BookingID == nil || t.Status != model.TripCompleted {
+			continue
+		}
+		if prev, ok := lastTrip[*t.BookingID]; ok && prev.EndedAt != nil && (t.EndedAt == nil || t.EndedAt.Before(*prev.EndedAt)) {
+			continue
+		}
+		lastTrip[*t.BookingID] = t
+	}
+
+	invoiced := r.Store.invoicedBookings()
+	lines := []model.InvoiceLine{}
+	for _, row := range r.Store.bookings {
+		bk := row.value
+		if row.tenantID != tenantID || bk.CorporateAccountID == nil || *bk.CorporateAccountID != accountID ||
+			bk.Status != model.BookingCompleted || bk.DeletedAt != nil || invoiced[bk.ID] {
+			continue
+		}
+
+		id := bk.ID
+		l := model.InvoiceLine{BookingID: &id, Booker: bk.Customer, Description: invoiceDescription(bk.Place, bk.PickupLocation, bk.DropLocation), DistanceKM: bk.DistanceKM}
+		if bk.CostCentre != nil {
+			l.CostCentre = *bk.CostCentre
+		}
+		if bk.Amount != nil {
+			l.Amount = *bk.Amount
+		}
+		l.TripDate = bk.CreatedAt
+		if bk.StartAt != nil {
+			l.TripDate = *bk.StartAt
+		}
+		if t, ok := lastTrip[bk.ID]; ok {
+			if t.EndedAt != nil {
+				l.TripDate = *t.EndedAt
+			}
+			km := float64(t.DistanceKM)
+			l.DistanceKM = &km
+			l.Amount = t.Price
+		}
+		if !l.TripDate.Before(before) {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if !lines[i].TripDate.Equal(lines[j].TripDate) {
+			return lines[i].TripDate.Before(lines[j].TripDate)
+		}
+		return *lines[i].BookingID < *lines[j].BookingID
+	})
+	return lines, nil
+}
+
+func (r *MemoryCorporateRepository) CreateInvoice(ctx context.Context, inv *model.CorporateInvoice) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for _, row := range r.Store.invoices {
+		existing := row.value
+		if (existing.AccountID == inv.AccountID && existing.PeriodStart.Equal(inv.PeriodStart)) ||
+			(row.tenantID == tenantID && existing.Number == inv.Number) {
+			return ErrDuplicateKey
+		}
+	}
+	invoiced := r.Store.invoicedBookings()
+	for _, l := range inv.Lines {
+		if l.BookingID != nil && invoiced[*l.BookingID] {
+			return ErrDuplicateKey
+		}
+	}
+
+	inv.ID = r.Store.nextID("corporate_invoices")
+	inv.AmountPaid = 0
+	stored := *inv
+	stored.Lines, stored.Payments = nil, nil
+	r.Store.invoices = append(r.Store.invoices, memRow[model.CorporateInvoice]{tenantID: tenantID, value: stored})
+	for i := range inv.Lines {
+		inv.Lines[i].ID = r.Store.nextID("corporate_invoice_lines")
+		inv.Lines[i].InvoiceID = inv.ID
+		r.Store.invoiceLines = append(r.Store.invoiceLines, memRow[model.InvoiceLine]{tenantID: tenantID, value: inv.Lines[i]})
+	}
+	return nil
+}
+
+// withAccountName must be called with the lock held.
+func (s *MemoryStore) withAccountName(tenantID int64, inv model.CorporateInvoice) model.CorporateInvoice {
+	if a := s.findAccount(tenantID, func(a *model.CorporateAccount) bool { return a.ID == inv.AccountID }); a != nil {
+		inv.AccountName = a.Name
+	}
+	return inv
+}
+
+func (r *MemoryCorporateRepository) GetInvoices(ctx context.Context, accountID int) ([]model.CorporateInvoice, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	invoices := []model.CorporateInvoice{}
+	for _, row := range r.Store.invoices {
+		if row.tenantID == tenantID && row.value.AccountID == accountID {
+			invoices = append(invoices, r.Store.withAccountName(tenantID, row.value))
+		}
+	}
+	sort.SliceStable(invoices, func(i, j int) bool {
+		if !invoices[i].PeriodStart.Equal(invoices[j].PeriodStart) {
+			return invoices[i].PeriodStart.After(invoices[j].PeriodStart)
+		}
+		return invoices[i].ID > invoices[j].ID
+	})
+	return invoices, nil
+}
+
+func (r *MemoryCorporateRepository) GetInvoice(ctx context.Context, id int) (*model.CorporateInvoice, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.invoices {
+		if row.tenantID != tenantID || row.value.ID != id {
+			continue
+		}
+		inv := r.Store.withAccountName(tenantID, row.value)
+		inv.Lines = []model.InvoiceLine{}
+		for _, l := range r.Store.invoiceLines {
+			if l.value.InvoiceID == id {
+				inv.Lines = append(inv.Lines, l.value)
+			}
+		}
+		sort.SliceStable(inv.Lines, func(i, j int) bool { return inv.Lines[i].TripDate.Before(inv.Lines[j].TripDate) })
+		inv.Payments = []model.InvoicePayment{}
+		for _, p := range r.Store.remittances {
+			if p.value.InvoiceID == id {
+				inv.Payments = append(inv.Payments, p.value)
+			}
+		}
+		sort.SliceStable(inv.Payments, func(i, j int) bool { return inv.Payments[i].PaidAt.Before(inv.Payments[j].PaidAt) })
+		return &inv, nil
+	}
+	return nil, nil
+}
+
+func (r *MemoryCorporateRepository) AddInvoicePayment(ctx context.Context, p *model.InvoicePayment) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.invoices {
+		inv := &r.Store.invoices[i].value
+		if r.Store.invoices[i].tenantID != tenantID || inv.ID != p.InvoiceID {
+			continue
+		}
+		if inv.Status == model.InvoiceVoid || inv.AmountPaid+p.Amount > inv.Total {
+			return ErrInvoiceOverpaid
+		}
+		inv.AmountPaid += p.Amount
+		if inv.AmountPaid >= inv.Total {
+			inv.Status = model.InvoicePaid
+		} else {
+			inv.Status = model.InvoicePartiallyPaid
+		}
+		p.ID = r.Store.nextID("corporate_invoice_payments")
+		r.Store.remittances = append(r.Store.remittances, memRow[model.InvoicePayment]{tenantID: tenantID, value: *p})
+		return nil
+	}
+	return ErrInvoiceOverpaid
+}
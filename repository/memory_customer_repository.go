@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryCustomerRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryCustomerRepository(s *MemoryStore) *MemoryCustomerRepository {
+	return &MemoryCustomerRepository{Store: s}
+}
+
+func (r *MemoryCustomerRepository) find(tenantID int64, id int) *model.Customer {
+	for i := range r.Store.customers {
+		row := &r.Store.customers[i]
+		if row.tenantID == tenantID && row.value.ID == id {
+			return &row.value
+		}
+	}
+	return nil
+}
+
+func customerMatches(c model.Customer, query string) bool {
+	if query == "" {
+		return true
+	}
+	query = strings.ToLower(query)
+	fields := append([]string{c.Name}, c.Phones...)
+	fields = append(fields, c.Emails...)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryCustomerRepository) Search(ctx context.Context, query string) ([]model.Customer, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	customers := []model.Customer{}
+	for _, row := range r.Store.customers {
+		if row.tenantID == tenantID && customerMatches(row.value, query) {
+			customers = append(customers, row.value)
+		}
+	}
+	sort.SliceStable(customers, func(i, j int) bool { return customers[i].Name < customers[j].Name })
+	return customers, nil
+}
+
+func (r *MemoryCustomerRepository) GetByID(ctx context.Context, id int) (*model.Customer, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	c := r.find(tenantID, id)
+	if c == nil {
+		return nil, nil
+	}
+	found := *c
+	return &found, nil
+}
+
+func (r *MemoryCustomerRepository) Create(ctx context.Context, c *model.Customer) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeCustomer(c)
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	c.ID = r.Store.nextID("customers")
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+	c.Version = 1
+	r.Store.customers = append(r.Store.customers, memRow[model.Customer]{tenantID: tenantID, value: *c})
+	return nil
+}
+
+func (r *MemoryCustomerRepository) Update(ctx context.Context, c *model.Customer) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeCustomer(c)
+	c.UpdatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, c.ID)
+	if stored == nil || stored.Version != c.Version {
+		return ErrVersionConflict
+	}
+
+	stored.Name = c.Name
+	stored.Phones = c.Phones
+	stored.Emails = c.Emails
+	stored.Addresses = c.Addresses
+	stored.Notes = c.Notes
+	stored.Blacklisted = c.Blacklisted
+	stored.UpdatedAt = c.UpdatedAt
+	stored.Version++
+
+	c.Version++
+	return nil
+}
+
+func (r *MemoryCustomerRepository) Delete(ctx context.Context, id int, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i, row := range r.Store.customers {
+		if row.tenantID != tenantID || row.value.ID != id {
+			continue
+		}
+		if row.value.Version != version {
+			return ErrVersionConflict
+		}
+		r.Store.customers = append(r.Store.customers[:i], r.Store.customers[i+1:]...)
+
+		// Mirrors ON DELETE SET NULL on booking, payment, notifications and
+		// trip_feedback, and
+		// ON DELETE CASCADE on notification_preferences.
+		for j := range r.Store.bookings {
+			if b := &r.Store.bookings[j]; b.tenantID == tenantID && b.value.CustomerID != nil && *b.value.CustomerID == id {
+				b.value.CustomerID = nil
+			}
+		}
+		for j := range r.Store.payments {
+			if p := &r.Store.payments[j]; p.tenantID == tenantID && p.value.CustomerID != nil && *p.value.CustomerID == id {
+				p.value.CustomerID = nil
+			}
+		}
+		for j := range r.Store.notifications {
+			if n := &r.Store.notifications[j]; n.tenantID == tenantID && n.value.CustomerID != nil && *n.value.CustomerID == id {
+				n.value.CustomerID = nil
+			}
+		}
+		for j := range r.Store.feedback {
+			if f := &r.Store.feedback[j]; f.tenantID == tenantID && f.value.CustomerID != nil && *f.value.CustomerID == id {
+				f.value.CustomerID = nil
+			}
+		}
+		preferences := r.Store.preferences[:0]
+		for _, row := range r.Store.preferences {
+			if row.tenantID != tenantID || row.value.CustomerID != id {
+				preferences = append(preferences, row)
+			}
+		}
+		r.Store.preferences = preferences
+		return nil
+	}
+	return ErrVersionConflict
+}
+
+func (r *MemoryCustomerRepository) GetBookings(ctx context.Context, id int) ([]model.Booking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	bookings := []model.Booking{}
+	for _, row := range r.Store.bookings {
+		b := row.value
+		if row.tenantID == tenantID && b.DeletedAt == nil && b.CustomerID != nil && *b.CustomerID == id {
+			bookings = append(bookings, b)
+		}
+	}
+	sort.SliceStable(bookings, func(i, j int) bool { return bookings[i].CreatedAt.After(bookings[j].CreatedAt) })
+	return bookings, nil
+}
+
+func (r *MemoryCustomerRepository) GetPayments(ctx context.Context, id int) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	payments := []model.Payment{}
+	for _, row := range r.Store.payments {
+		p := row.value
+		if row.tenantID == tenantID && p.DeletedAt == nil && p.CustomerID != nil && *p.CustomerID == id {
+			payments = append(payments, p)
+		}
+	}
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].PaymentDate > payments[j].PaymentDate })
+	return payments, nil
+}
+
+func (r *MemoryCustomerRepository) GetTrips(ctx context.Context, id int) ([]model.TripHistory, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	codes := map[string]bool{}
+	for _, row := range r.Store.bookings {
+		b := row.value
+		if row.tenantID == tenantID && b.CustomerID != nil && *b.CustomerID == id {
+			codes[b.ID] = true
+		}
+	}
+
+	trips := []model.TripHistory{}
+	for _, row := range r.Store.trips {
+		if row.tenantID == tenantID && row.value.BookingID != nil && codes[*row.value.BookingID] {
+			trips = append(trips, tripHistory(row.value))
+		}
+	}
+	sort.SliceStable(trips, func(i, j int) bool { return trips[i].BookingDate > trips[j].BookingDate })
+	return trips, nil
+}
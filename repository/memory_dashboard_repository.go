@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+)
+
+type MemoryDashboardRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryDashboardRepository(s *MemoryStore) *MemoryDashboardRepository {
+	return &MemoryDashboardRepository{Store: s}
+}
+
+func (r *MemoryDashboardRepository) GetTotalBookings(ctx context.Context) (int, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var count int
+	for _, row := range r.Store.bookings {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryDashboardRepository) GetActiveDrivers(ctx context.Context) (int, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var count int
+	for _, row := range r.Store.drivers {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil && row.value.Status == "active" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *MemoryDashboardRepository) GetTotalRevenue(ctx context.Context) (float64, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var payments []model.Payment
+	for _, row := range r.Store.payments {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil {
+			payments = append(payments, row.value)
+		}
+	}
+	summary := model.SummarisePayments(0, payments)
+	return summary.Paid - summary.Refunded, nil
+}
+
+type MemoryDashboardTripRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryDashboardTripRepository(s *MemoryStore) *MemoryDashboardTripRepository {
+	return &MemoryDashboardTripRepository{Store: s}
+}
+
+func (r *MemoryDashboardTripRepository) GetDashboardSummary(ctx context.Context) (*model.DashboardSummary, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	summary := &model.DashboardSummary{}
+	for _, row := range r.Store.trips {
+		if row.tenantID == tenantID && row.value.Status == model.TripCompleted {
+			summary.TotalTrips++
+			summary.TotalDistance += row.value.DistanceKM
+			summary.TotalDuration += row.value.DurationMinutes
+		}
+	}
+	return summary, nil
+}
+
+type MemoryBookingTrendsRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryBookingTrendsRepository(s *MemoryStore) *MemoryBookingTrendsRepository {
+	return &MemoryBookingTrendsRepository{Store: s}
+}
+
+func (r *MemoryBookingTrendsRepository) GetTrends(ctx context.Context, year int) ([]model.BookingTrend, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var trends []model.BookingTrend
+	for _, row := range r.Store.trends {
+		if row.tenantID == tenantID && row.value.Year == year {
+			trends = append(trends, row.value)
+		}
+	}
+	return trends, nil
+}
+
+// Add stands in for the reporting job that fills booking_trends in Postgres.
+func (r *MemoryBookingTrendsRepository) Add(ctx context.Context, t model.BookingTrend) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	r.Store.trends = append(r.Store.trends, memRow[model.BookingTrend]{tenantID: tenantID, value: t})
+	return nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryDispatchRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryDispatchRepository(s *MemoryStore) *MemoryDispatchRepository {
+	return &MemoryDispatchRepository{Store: s}
+}
+
+func (r *MemoryDispatchRepository) ActiveDrivers(ctx context.Context, from, to time.Time) ([]model.DispatchCandidate, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	candidates := []model.DispatchCandidate{}
+	for _, row := range r.Store.drivers {
+		d := row.value
+		if row.tenantID != tenantID || d.DeletedAt != nil || d.Status != "active" {
+			continue
+		}
+
+		c := model.DispatchCandidate{DriverID: d.ID, Name: d.Name, CarTypeID: d.CarTypeID}
+		for _, loc := range r.Store.locations {
+			if loc.tenantID == tenantID && loc.value.DriverID == d.ID {
+				lat, lng := loc.value.Latitude, loc.value.Longitude
+				c.Latitude, c.Longitude = &lat, &lng
+			}
+		}
+
+		var total float64
+		var rated int
+		for _, trip := range r.Store.trips {
+			t := trip.value
+			if trip.tenantID != tenantID || t.Rating == 0 {
+				continue
+			}
+			if t.DriverID == uint(d.ID) || (t.DriverID == 0 && t.DriverName == d.Name) {
+				total += float64(t.Rating)
+				rated++
+			}
+		}
+		if rated > 0 {
+			c.Rating = total / float64(rated)
+		}
+
+		for _, b := range r.Store.bookings {
+			if b.tenantID == tenantID && holdsSchedule(b.value) && sameRef(b.value.DriverID, &d.ID) &&
+				overlapsWindow(*b.value.StartAt, *b.value.EndAt, from, to) {
+				c.Workload++
+			}
+		}
+
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].DriverID < candidates[j].DriverID })
+	return candidates, nil
+}
+
+func (r *MemoryDispatchRepository) SetLocation(ctx context.Context, l *model.DriverLocation) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	l.UpdatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.locations {
+		if r.Store.locations[i].value.DriverID == l.DriverID {
+			r.Store.locations[i].value = *l
+			return nil
+		}
+	}
+	r.Store.locations = append(r.Store.locations, memRow[model.DriverLocation]{tenantID: tenantID, value: *l})
+	return nil
+}
+
+func (r *MemoryDispatchRepository) CreateOffer(ctx context.Context, o *model.DispatchOffer) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	o.ID = r.Store.nextID("dispatch_offers")
+	o.TenantID = tenantID
+	r.Store.offers = append(r.Store.offers, memRow[model.DispatchOffer]{tenantID: tenantID, value: *o})
+	return nil
+}
+
+func (r *MemoryDispatchRepository) GetOffer(ctx context.Context, id int) (*model.DispatchOffer, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.offers {
+		if row.tenantID == tenantID && row.value.ID == id {
+			found := row.value
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryDispatchRepository) GetOffers(ctx context.Context, bookingID string) ([]model.DispatchOffer, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	offers := []model.DispatchOffer{}
+	for _, row := range r.Store.offers {
+		if row.tenantID == tenantID && row.value.BookingID == bookingID {
+			offers = append(offers, row.value)
+		}
+	}
+	sort.SliceStable(offers, func(i, j int) bool { return offers[i].OfferedAt.Before(offers[j].OfferedAt) })
+	return offers, nil
+}
+
+func (r *MemoryDispatchRepository) RespondOffer(ctx context.Context, o *model.DispatchOffer) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.Store.pendingOffer(tenantID, o.ID)
+	if stored == nil {
+		return ErrVersionConflict
+	}
+	r.Store.respondOffer(stored, o)
+	return nil
+}
+
+// pendingOffer returns the stored offer id while it is still pending, and
+// must be called with the lock held.
+func (s *MemoryStore) pendingOffer(tenantID int64, id int) *model.DispatchOffer {
+	for i := range s.offers {
+		stored := &s.offers[i]
+		if stored.tenantID == tenantID && stored.value.ID == id && stored.value.Status == model.OfferPending {
+			return &stored.value
+		}
+	}
+	return nil
+}
+
+// respondOffer records the answer o on stored and must be called with the
+// write lock held.
+func (s *MemoryStore) respondOffer(stored, o *model.DispatchOffer) {
+	now := time.Now()
+	stored.Status = o.Status
+	stored.RespondedAt = &now
+	o.RespondedAt = &now
+}
+
+// ExpireOffers is run by the dispatch sweep and spans every tenant.
+func (r *MemoryDispatchRepository) ExpireOffers(ctx context.Context, now time.Time) ([]model.DispatchOffer, error) {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	expired := []model.DispatchOffer{}
+	for i := range r.Store.offers {
+		o := &r.Store.offers[i].value
+		if o.Status == model.OfferPending && !o.ExpiresAt.After(now) {
+			respondedAt := now
+			o.Status = model.OfferExpired
+			o.RespondedAt = &respondedAt
+			expired = append(expired, *o)
+		}
+	}
+	return expired, nil
+}
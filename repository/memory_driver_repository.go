@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+)
+
+type MemoryDriverRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryDriverRepository(s *MemoryStore) *MemoryDriverRepository {
+	return &MemoryDriverRepository{Store: s}
+}
+
+func (r *MemoryDriverRepository) find(tenantID int64, id string, deleted bool) *model.Driver {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil
+	}
+	for i := range r.Store.drivers {
+		row := &r.Store.drivers[i]
+		if row.tenantID == tenantID && row.value.ID == n && (row.value.DeletedAt != nil) == deleted {
+			return &row.value
+		}
+	}
+	return nil
+}
+
+func (r *MemoryDriverRepository) GetAll(ctx context.Context) ([]model.Driver, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var drivers []model.Driver
+	for _, row := range r.Store.drivers {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil {
+			drivers = append(drivers, row.value)
+		}
+	}
+	return drivers, nil
+}
+
+func (r *MemoryDriverRepository) Create(ctx context.Context, d *model.Driver) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	now := time.Now()
+	d.ID = r.Store.nextID("drivers")
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	d.Version = 1
+	d.DeletedAt = nil
+	d.RatingAverage, d.RatingCount, d.RollingRating = 0, 0, 0
+	r.Store.drivers = append(r.Store.drivers, memRow[model.Driver]{tenantID: tenantID, value: *d})
+	return nil
+}
+
+func (r *MemoryDriverRepository) GetByID(ctx context.Context, id string) (*model.Driver, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	d := r.find(tenantID, id, false)
+	if d == nil {
+		return nil, sql.ErrNoRows
+	}
+	found := *d
+	return &found, nil
+}
+
+func (r *MemoryDriverRepository) Update(ctx context.Context, id string, d *model.Driver) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != d.Version {
+		return ErrVersionConflict
+	}
+
+	stored.Name = d.Name
+	stored.Email = d.Email
+	stored.Phone = d.Phone
+	stored.Address = d.Address
+	stored.DriverLicenseNumber = d.DriverLicenseNumber
+	stored.CarModelID = d.CarModelID
+	stored.CarTypeID = d.CarTypeID
+	stored.PlateNumber = d.PlateNumber
+	stored.Status = d.Status
+	stored.UpdatedAt = time.Now()
+	stored.Version++
+
+	d.Version++
+	return nil
+}
+
+func (r *MemoryDriverRepository) Delete(ctx context.Context, id string, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != version {
+		return ErrVersionConflict
+	}
+
+	now := time.Now()
+	stored.DeletedAt = &now
+	stored.Version++
+	return nil
+}
+
+func (r *MemoryDriverRepository) GetDeleted(ctx context.Context) ([]model.Driver, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var drivers []model.Driver
+	for _, row := range r.Store.drivers {
+		if row.tenantID == tenantID && row.value.DeletedAt != nil {
+			drivers = append(drivers, row.value)
+		}
+	}
+	sortByDeletedAt(drivers, func(d model.Driver) *time.Time { return d.DeletedAt })
+	return drivers, nil
+}
+
+func (r *MemoryDriverRepository) Restore(ctx context.Context, id string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, true)
+	if stored == nil {
+		return ErrNotFound
+	}
+
+	stored.DeletedAt = nil
+	stored.Version++
+	return nil
+}
+
+// PurgeDeleted is run by the retention job and spans every tenant.
+func (r *MemoryDriverRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	var n int64
+	r.Store.drivers, n = purgeRows(r.Store.drivers, func(d model.Driver) *time.Time { return d.DeletedAt }, before)
+
+	// Mirrors ON DELETE CASCADE on driver_leave, driver_locations,
+	// dispatch_offers and driver_rating_alerts, and ON DELETE SET NULL on
+	// booking.driver_id, trips.driver_id and trip_feedback.driver_id.
+	live := map[int]bool{}
+	for _, row := range r.Store.drivers {
+		live[row.value.ID] = true
+	}
+	leaves := r.Store.leaves[:0]
+	for _, row := range r.Store.leaves {
+		if live[row.value.DriverID] {
+			leaves = append(leaves, row)
+		}
+	}
+	r.Store.leaves = leaves
+	locations := r.Store.locations[:0]
+	for _, row := range r.Store.locations {
+		if live[row.value.DriverID] {
+			locations = append(locations, row)
+		}
+	}
+	r.Store.locations = locations
+	offers := r.Store.offers[:0]
+	for _, row := range r.Store.offers {
+		if live[row.value.DriverID] {
+			offers = append(offers, row)
+		}
+	}
+	r.Store.offers = offers
+	for i := range r.Store.bookings {
+		if b := &r.Store.bookings[i].value; b.DriverID != nil && !live[*b.DriverID] {
+			b.DriverID = nil
+		}
+	}
+	for i := range r.Store.trips {
+		if t := &r.Store.trips[i].value; t.DriverID != 0 && !live[int(t.DriverID)] {
+			t.DriverID = 0
+		}
+	}
+	for i := range r.Store.feedback {
+		if f := &r.Store.feedback[i].value; f.DriverID != nil && !live[*f.DriverID] {
+			f.DriverID = nil
+		}
+	}
+	alerts := r.Store.ratingAlerts[:0]
+	for _, row := range r.Store.ratingAlerts {
+		if live[row.value.DriverID] {
+			alerts = append(alerts, row)
+		}
+	}
+	r.Store.ratingAlerts = alerts
+	return n, nil
+}
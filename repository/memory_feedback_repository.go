@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+type MemoryFeedbackRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryFeedbackRepository(s *MemoryStore) *MemoryFeedbackRepository {
+	return &MemoryFeedbackRepository{Store: s}
+}
+
+func (r *MemoryFeedbackRepository) Submit(ctx context.Context, f *model.TripFeedback, window int) (*model.DriverRating, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for _, row := range r.Store.feedback {
+		if row.value.TripID == f.TripID {
+			return nil, ErrDuplicateKey
+		}
+	}
+
+	f.ID = r.Store.nextID("trip_feedback")
+	f.CreatedAt = time.Now()
+	stored := *f
+	stored.Tags = append([]string{}, f.Tags...)
+	r.Store.feedback = append(r.Store.feedback, memRow[model.TripFeedback]{tenantID: tenantID, value: stored})
+
+	if t := r.Store.trip(tenantID, f.TripID); t != nil {
+		t.Rating = float32(f.Rating)
+		t.Feedback = f.Comment
+	}
+	if f.DriverID == nil {
+		return nil, nil
+	}
+
+	var d *model.Driver
+	for i := range r.Store.drivers {
+		if row := &r.Store.drivers[i]; row.tenantID == tenantID && row.value.ID == *f.DriverID {
+			d = &row.value
+		}
+	}
+	if d == nil {
+		return nil, nil
+	}
+
+	// Feedback rows are appended in creation order, so walking them backwards
+	// visits the driver's latest ratings first.
+	var total, latest, count int
+	for i := len(r.Store.feedback) - 1; i >= 0; i-- {
+		row := r.Store.feedback[i]
+		if row.tenantID != tenantID || row.value.DriverID == nil || *row.value.DriverID != d.ID {
+			continue
+		}
+		total += row.value.Rating
+		if count < window {
+			latest += row.value.Rating
+		}
+		count++
+	}
+	d.RatingCount = count
+	d.RatingAverage = roundRating(float64(total) / float64(count))
+	d.RollingRating = roundRating(float64(latest) / float64(min(count, window)))
+	return &model.DriverRating{DriverID: d.ID, Average: d.RatingAverage, Count: d.RatingCount, RollingAverage: d.RollingRating}, nil
+}
+
+// roundRating matches the two decimals of the NUMERIC(3, 2) driver columns.
+func roundRating(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func (r *MemoryFeedbackRepository) GetByTrip(ctx context.Context, tripID uint) (*model.TripFeedback, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.feedback {
+		if row.tenantID == tenantID && row.value.TripID == tripID {
+			f := row.value
+			return &f, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryFeedbackRepository) GetByDriver(ctx context.Context, driverID int) ([]model.TripFeedback, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	feedback := []model.TripFeedback{}
+	for i := len(r.Store.feedback) - 1; i >= 0; i-- {
+		row := r.Store.feedback[i]
+		if row.tenantID == tenantID && row.value.DriverID != nil && *row.value.DriverID == driverID {
+			feedback = append(feedback, row.value)
+		}
+	}
+	return feedback, nil
+}
+
+func (r *MemoryFeedbackRepository) RaiseAlert(ctx context.Context, a *model.DriverRatingAlert, notifications []model.Notification) (bool, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for _, row := range r.Store.ratingAlerts {
+		if row.value.DriverID == a.DriverID && row.value.AcknowledgedAt == nil {
+			return false, nil
+		}
+	}
+
+	a.ID = r.Store.nextID("driver_rating_alerts")
+	a.CreatedAt = time.Now()
+	r.Store.ratingAlerts = append(r.Store.ratingAlerts, memRow[model.DriverRatingAlert]{tenantID: tenantID, value: *a})
+	for i := range notifications {
+		r.Store.enqueueNotification(tenantID, &notifications[i])
+	}
+	return true, nil
+}
+
+func (r *MemoryFeedbackRepository) GetAlerts(ctx context.Context, open bool) ([]model.DriverRatingAlert, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	names := map[int]string{}
+	for _, row := range r.Store.drivers {
+		if row.tenantID == tenantID {
+			names[row.value.ID] = row.value.Name
+		}
+	}
+
+	alerts := []model.DriverRatingAlert{}
+	for _, row := range r.Store.ratingAlerts {
+		if row.tenantID != tenantID || open && row.value.AcknowledgedAt != nil {
+			continue
+		}
+		a := row.value
+		a.DriverName = names[a.DriverID]
+		alerts = append(alerts, a)
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if alerts[i].CreatedAt.Equal(alerts[j].CreatedAt) {
+			return alerts[i].ID > alerts[j].ID
+		}
+		return alerts[i].CreatedAt.After(alerts[j].CreatedAt)
+	})
+	return alerts, nil
+}
+
+func (r *MemoryFeedbackRepository) AcknowledgeAlert(ctx context.Context, id int, by int64) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.ratingAlerts {
+		row := &r.Store.ratingAlerts[i]
+		if row.tenantID == tenantID && row.value.ID == id && row.value.AcknowledgedAt == nil {
+			now := time.Now()
+			row.value.AcknowledgedAt = &now
+			row.value.AcknowledgedBy = &by
+			return nil
+		}
+	}
+	return ErrNotFound
+}
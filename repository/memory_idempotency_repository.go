@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"time"
+)
+
+type MemoryIdempotencyRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryIdempotencyRepository(s *MemoryStore) *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{Store: s}
+}
+
+func (r *MemoryIdempotencyRepository) find(tenantID int64, key string) *model.IdempotencyKey {
+	for i := range r.Store.idempotency {
+		row := &r.Store.idempotency[i]
+		if row.tenantID == tenantID && row.value.Key == key {
+			return &row.value
+		}
+	}
+	return nil
+}
+
+func (r *MemoryIdempotencyRepository) Reserve(ctx context.Context, k *model.IdempotencyKey) (bool, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	reserved := model.IdempotencyKey{
+		Key:         k.Key,
+		Method:      k.Method,
+		Path:        k.Path,
+		Fingerprint: k.Fingerprint,
+		CreatedAt:   k.CreatedAt,
+		ExpiresAt:   k.ExpiresAt,
+	}
+
+	existing := r.find(tenantID, k.Key)
+	if existing == nil {
+		r.Store.idempotency = append(r.Store.idempotency, memRow[model.IdempotencyKey]{tenantID: tenantID, value: reserved})
+		return true, nil
+	}
+	if existing.ExpiresAt.After(k.CreatedAt) {
+		return false, nil
+	}
+
+	*existing = reserved
+	return true, nil
+}
+
+func (r *MemoryIdempotencyRepository) FindByKey(ctx context.Context, key string) (*model.IdempotencyKey, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	k := r.find(tenantID, key)
+	if k == nil {
+		return nil, nil
+	}
+	found := *k
+	return &found, nil
+}
+
+func (r *MemoryIdempotencyRepository) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if k := r.find(tenantID, key); k != nil {
+		k.StatusCode = statusCode
+		k.ResponseBody = append([]byte(nil), body...)
+	}
+	return nil
+}
+
+func (r *MemoryIdempotencyRepository) Release(ctx context.Context, key string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	kept := r.Store.idempotency[:0]
+	for _, row := range r.Store.idempotency {
+		if row.tenantID == tenantID && row.value.Key == key && row.value.StatusCode == 0 {
+			continue
+		}
+		kept = append(kept, row)
+	}
+	r.Store.idempotency = kept
+	return nil
+}
+
+// DeleteExpired is run by the retention job and spans every tenant.
+func (r *MemoryIdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	kept := r.Store.idempotency[:0]
+	var n int64
+	for _, row := range r.Store.idempotency {
+		if !row.value.ExpiresAt.After(before) {
+			n++
+			continue
+		}
+		kept = append(kept, row)
+	}
+	r.Store.idempotency = kept
+	return n, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+)
+
+type MemoryMaintenanceRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryMaintenanceRepository(s *MemoryStore) *MemoryMaintenanceRepository {
+	return &MemoryMaintenanceRepository{Store: s}
+}
+
+func (r *MemoryMaintenanceRepository) Create(ctx context.Context, m *model.VehicleMaintenance) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := *m
+	stored.ID = uint(r.Store.nextID("vehicle_maintenance"))
+	r.Store.maintenance = append(r.Store.maintenance, memRow[model.VehicleMaintenance]{tenantID: tenantID, value: stored})
+	return nil
+}
+
+func (r *MemoryMaintenanceRepository) FindByVehicle(ctx context.Context, vehicleID int) ([]model.VehicleMaintenance, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var list []model.VehicleMaintenance
+	for _, row := range r.Store.maintenance {
+		if row.tenantID == tenantID && int(row.value.VehicleID) == vehicleID {
+			list = append(list, row.value)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].ServiceDate.After(list[j].ServiceDate)
+	})
+	return list, nil
+}
+
+func (r *MemoryMaintenanceRepository) Update(ctx context.Context, m *model.VehicleMaintenance) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.maintenance {
+		row := &r.Store.maintenance[i]
+		if row.tenantID == tenantID && row.value.ID == m.ID {
+			row.value = *m
+		}
+	}
+	return nil
+}
+
+func (r *MemoryMaintenanceRepository) Delete(ctx context.Context, id uint) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	kept := r.Store.maintenance[:0]
+	for _, row := range r.Store.maintenance {
+		if row.tenantID == tenantID && row.value.ID == id {
+			continue
+		}
+		kept = append(kept, row)
+	}
+	r.Store.maintenance = kept
+	return nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryNotificationRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryNotificationRepository(s *MemoryStore) *MemoryNotificationRepository {
+	return &MemoryNotificationRepository{Store: s}
+}
+
+func (r *MemoryNotificationRepository) Enqueue(ctx context.Context, n *model.Notification) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	r.Store.enqueueNotification(tenantID, n)
+	return nil
+}
+
+// enqueueNotification mirrors the Postgres insert and must be called with the
+// write lock held.
+func (s *MemoryStore) enqueueNotification(tenantID int64, n *model.Notification) {
+	n.ID = s.nextID("notifications")
+	n.Status = model.NotificationPending
+	n.CreatedAt = time.Now()
+	n.NextAttemptAt = n.CreatedAt
+	n.TenantID = tenantID
+	s.notifications = append(s.notifications, memRow[model.Notification]{tenantID: tenantID, value: *n})
+}
+
+func (r *MemoryNotificationRepository) GetAll(ctx context.Context, status string) ([]model.Notification, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	notifications := []model.Notification{}
+	for _, row := range r.Store.notifications {
+		if row.tenantID == tenantID && (status == "" || row.value.Status == status) {
+			notifications = append(notifications, row.value)
+		}
+	}
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].ID > notifications[j].ID
+	})
+	return notifications, nil
+}
+
+func (r *MemoryNotificationRepository) GetPreference(ctx context.Context, customerID int) (*model.NotificationPreference, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.preferences {
+		if row.tenantID == tenantID && row.value.CustomerID == customerID {
+			p := row.value
+			p.Channels = append([]string{}, p.Channels...)
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryNotificationRepository) SavePreference(ctx context.Context, p *model.NotificationPreference) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	if p.Channels == nil {
+		p.Channels = []string{}
+	}
+	p.UpdatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := *p
+	stored.Channels = append([]string{}, p.Channels...)
+	for i := range r.Store.preferences {
+		if r.Store.preferences[i].tenantID == tenantID && r.Store.preferences[i].value.CustomerID == p.CustomerID {
+			r.Store.preferences[i].value = stored
+			return nil
+		}
+	}
+	r.Store.preferences = append(r.Store.preferences, memRow[model.NotificationPreference]{tenantID: tenantID, value: stored})
+	return nil
+}
+
+// ClaimDue is run by the notification dispatcher and spans every tenant.
+func (r *MemoryNotificationRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.Notification, error) {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	due := []*model.Notification{}
+	for i := range r.Store.notifications {
+		n := &r.Store.notifications[i].value
+		if n.Status == model.NotificationPending && !n.NextAttemptAt.After(now) {
+			due = append(due, n)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := []model.Notification{}
+	for _, n := range due {
+		n.Attempts++
+		n.NextAttemptAt = leaseUntil
+		claimed = append(claimed, *n)
+	}
+	return claimed, nil
+}
+
+// MarkSent is run by the notification dispatcher and spans every tenant.
+func (r *MemoryNotificationRepository) MarkSent(ctx context.Context, id int, at time.Time) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if n := r.Store.notification(id); n != nil {
+		n.Status = model.NotificationSent
+		n.SentAt = &at
+		n.LastError = ""
+	}
+	return nil
+}
+
+// MarkFailed is run by the notification dispatcher and spans every tenant.
+func (r *MemoryNotificationRepository) MarkFailed(ctx context.Context, id int, reason string, retryAt *time.Time) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if n := r.Store.notification(id); n != nil {
+		n.LastError = reason
+		if retryAt == nil {
+			n.Status = model.NotificationFailed
+		} else {
+			n.NextAttemptAt = *retryAt
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) notification(id int) *model.Notification {
+	for i := range s.notifications {
+		if s.notifications[i].value.ID == id {
+			return &s.notifications[i].value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,513 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"math"
+	"time"
+)
+
+type MemoryPaymentRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryPaymentRepository(s *MemoryStore) *MemoryPaymentRepository {
+	return &MemoryPaymentRepository{Store: s}
+}
+
+func (r *MemoryPaymentRepository) find(tenantID int64, id int, deleted bool) *model.Payment {
+	for i := range r.Store.payments {
+		row := &r.Store.payments[i]
+		if row.tenantID == tenantID && row.value.PaymentID == id && (row.value.DeletedAt != nil) == deleted {
+			return &row.value
+		}
+	}
+	return nil
+}
+
+func (r *MemoryPaymentRepository) active(tenantID int64) []model.Payment {
+	var payments []model.Payment
+	for _, row := range r.Store.payments {
+		if row.tenantID == tenantID && row.value.DeletedAt == nil {
+			payments = append(payments, row.value)
+		}
+	}
+	return payments
+}
+
+func (r *MemoryPaymentRepository) GetPaymentStats(ctx context.Context) (*model.PaymentStats, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var pending float64
+	stats := &model.PaymentStats{}
+	payments := r.active(tenantID)
+	for _, p := range payments {
+		if p.Status == model.PaymentPending {
+			pending += p.Amount
+		}
+		stats.TotalTransactions++
+	}
+	summary := model.SummarisePayments(0, payments)
+	stats.TotalPayment = int64(math.Round(summary.Paid))
+	stats.TotalRefunded = int64(math.Round(summary.Refunded))
+	stats.NetPayment = stats.TotalPayment - stats.TotalRefunded
+	stats.PendingPayment = int64(math.Round(pending))
+
+	return stats, nil
+}
+
+func (r *MemoryPaymentRepository) GetPayments(ctx context.Context, page, pageSize int) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	payments := r.active(tenantID)
+	offset := (page - 1) * pageSize
+	if offset >= len(payments) {
+		return nil, nil
+	}
+	end := offset + pageSize
+	if end > len(payments) {
+		end = len(payments)
+	}
+
+	return payments[offset:end], nil
+}
+
+func (r *MemoryPaymentRepository) GetAll(ctx context.Context) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	return r.active(tenantID), nil
+}
+
+func (r *MemoryPaymentRepository) GetByID(ctx context.Context, id int) (*model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	p := r.find(tenantID, id, false)
+	if p == nil {
+		return nil, nil
+	}
+	found := *p
+	return &found, nil
+}
+
+func (r *MemoryPaymentRepository) GetByBooking(ctx context.Context, bookingID string) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	return r.Store.bookingPayments(tenantID, bookingID), nil
+}
+
+func (r *MemoryPaymentRepository) Create(ctx context.Context, p *model.Payment) (int, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	id := r.Store.nextID("payment")
+	r.Store.payments = append(r.Store.payments, memRow[model.Payment]{tenantID: tenantID, value: model.Payment{
+		PaymentID:      id,
+		BookingID:      p.BookingID,
+		Customer:       p.Customer,
+		CustomerID:     p.CustomerID,
+		Driver:         p.Driver,
+		Amount:         p.Amount,
+		RefundedAmount: p.RefundedAmount,
+		Method:         p.Method,
+		Status:         p.Status,
+		PaymentDate:    time.Now().UTC().Format(time.RFC3339),
+		ExpiresAt:      p.ExpiresAt,
+		ProviderRef:    p.ProviderRef,
+		PayCode:        p.PayCode,
+		Version:        1,
+	}})
+	r.Store.syncBookingPayment(tenantID, p.BookingID)
+
+	p.Version = 1
+	return id, nil
+}
+
+func (r *MemoryPaymentRepository) Update(ctx context.Context, p *model.Payment) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, p.PaymentID, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != p.Version {
+		return ErrVersionConflict
+	}
+
+	previous := stored.BookingID
+	stored.BookingID = p.BookingID
+	stored.Customer = p.Customer
+	stored.CustomerID = p.CustomerID
+	stored.Driver = p.Driver
+	stored.Amount = p.Amount
+	stored.RefundedAmount = p.RefundedAmount
+	stored.Method = p.Method
+	stored.Status = p.Status
+	stored.ExpiresAt = p.ExpiresAt
+	stored.ProviderRef = p.ProviderRef
+	stored.PayCode = p.PayCode
+	stored.Version++
+	if previous != nil && (p.BookingID == nil || *previous != *p.BookingID) {
+		r.Store.syncBookingPayment(tenantID, previous)
+	}
+	r.Store.syncBookingPayment(tenantID, p.BookingID)
+
+	p.Version++
+	return nil
+}
+
+func (r *MemoryPaymentRepository) Delete(ctx context.Context, id int, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, false)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Version != version {
+		return ErrVersionConflict
+	}
+
+	now := time.Now()
+	stored.DeletedAt = &now
+	stored.Version++
+	r.Store.syncBookingPayment(tenantID, stored.BookingID)
+	return nil
+}
+
+func (r *MemoryPaymentRepository) GetDeleted(ctx context.Context) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var payments []model.Payment
+	for _, row := range r.Store.payments {
+		if row.tenantID == tenantID && row.value.DeletedAt != nil {
+			payments = append(payments, row.value)
+		}
+	}
+	sortByDeletedAt(payments, func(p model.Payment) *time.Time { return p.DeletedAt })
+	return payments, nil
+}
+
+func (r *MemoryPaymentRepository) Restore(ctx context.Context, id int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.find(tenantID, id, true)
+	if stored == nil {
+		return ErrNotFound
+	}
+
+	stored.DeletedAt = nil
+	stored.Version++
+	r.Store.syncBookingPayment(tenantID, stored.BookingID)
+	return nil
+}
+
+// PurgeDeleted is run by the retention job and spans every tenant.
+func (r *MemoryPaymentRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	var n int64
+	r.Store.payments, n = purgeRows(r.Store.payments, func(p model.Payment) *time.Time { return p.DeletedAt }, before)
+
+	// Mirrors ON DELETE SET NULL on the payment links of booking_cancellations
+	// and ON DELETE CASCADE on payment_refunds.
+	remaining := map[int]bool{}
+	for _, row := range r.Store.payments {
+		remaining[row.value.PaymentID] = true
+	}
+	refunds := r.Store.refunds[:0]
+	for _, row := range r.Store.refunds {
+		if remaining[row.value.PaymentID] {
+			refunds = append(refunds, row)
+		}
+	}
+	r.Store.refunds = refunds
+	for i := range r.Store.cancellations {
+		c := &r.Store.cancellations[i].value
+		if c.FeePaymentID != nil && !remaining[*c.FeePaymentID] {
+			c.FeePaymentID = nil
+		}
+	}
+	return n, nil
+}
+
+// ExpirePending expires pending and authorised payments whose time ran out,
+// in every tenant, and returns how many it expired.
+func (r *MemoryPaymentRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	var n int64
+	for i := range r.Store.payments {
+		row := &r.Store.payments[i]
+		p := &row.value
+		if p.DeletedAt != nil || p.ExpiresAt == nil || p.ExpiresAt.After(now) {
+			continue
+		}
+		if p.Status != model.PaymentPending && p.Status != model.PaymentAuthorised {
+			continue
+		}
+		p.Status = model.PaymentExpired
+		p.Version++
+		n++
+		r.Store.syncBookingPayment(row.tenantID, p.BookingID)
+	}
+	return n, nil
+}
+
+// FindByProviderRef looks a payment up by its gateway order id in every
+// tenant; gateway callbacks arrive without one.
+func (r *MemoryPaymentRepository) FindByProviderRef(ctx context.Context, ref string) (*model.Payment, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.payments {
+		p := row.value
+		if p.DeletedAt == nil && p.ProviderRef != nil && *p.ProviderRef == ref {
+			p.TenantID = row.tenantID
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryPaymentRepository) RecordCallback(ctx context.Context, cb *model.PaymentCallback) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for _, seen := range r.Store.callbacks {
+		if seen.EventID == cb.EventID {
+			return ErrDuplicateKey
+		}
+	}
+	r.Store.callbacks = append(r.Store.callbacks, *cb)
+	return nil
+}
+
+func (r *MemoryPaymentRepository) ForgetCallback(ctx context.Context, eventID string) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i, seen := range r.Store.callbacks {
+		if seen.EventID == eventID {
+			r.Store.callbacks = append(r.Store.callbacks[:i], r.Store.callbacks[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *MemoryPaymentRepository) AddRefund(ctx context.Context, rf *model.PaymentRefund) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	p, err := r.Store.addRefund(tenantID, rf)
+	if err != nil {
+		return err
+	}
+	r.Store.syncBookingPayment(tenantID, p.BookingID)
+	return nil
+}
+
+// refundable returns the captured payment rf refunds, or nil when rf would
+// take more than is left of it. It must be called with the lock held.
+func (s *MemoryStore) refundable(tenantID int64, rf *model.PaymentRefund) *model.Payment {
+	for i := range s.payments {
+		row := &s.payments[i]
+		if row.tenantID != tenantID || row.value.PaymentID != rf.PaymentID || row.value.DeletedAt != nil {
+			continue
+		}
+		p := &row.value
+		if (p.Status != model.PaymentPaid && p.Status != model.PaymentPartiallyRefunded) ||
+			math.Round((p.RefundedAmount+rf.Amount)*100) > math.Round(p.Amount*100) {
+			return nil
+		}
+		return p
+	}
+	return nil
+}
+
+// addRefund mirrors the guarded update and insert of AddRefund and must be
+// called with the write lock held.
+func (s *MemoryStore) addRefund(tenantID int64, rf *model.PaymentRefund) (*model.Payment, error) {
+	p := s.refundable(tenantID, rf)
+	if p == nil {
+		return nil, ErrRefundExceedsCaptured
+	}
+
+	p.RefundedAmount = math.Round((p.RefundedAmount+rf.Amount)*100) / 100
+	p.Status = model.PaymentPartiallyRefunded
+	if p.RefundedAmount >= p.Amount {
+		p.Status = model.PaymentStatusRefunded
+	}
+	p.Version++
+
+	rf.ID = s.nextID("payment_refunds")
+	rf.CreatedAt = time.Now()
+	s.refunds = append(s.refunds, memRow[model.PaymentRefund]{tenantID: tenantID, value: *rf})
+	return p, nil
+}
+
+func (r *MemoryPaymentRepository) SettleRefund(ctx context.Context, id int, status string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	var rf *model.PaymentRefund
+	for i := range r.Store.refunds {
+		if row := &r.Store.refunds[i]; row.tenantID == tenantID && row.value.ID == id && row.value.Status == model.RefundPending {
+			rf = &row.value
+		}
+	}
+	if rf == nil {
+		return ErrNotFound
+	}
+	rf.Status = status
+
+	if status == model.RefundFailed {
+		for i := range r.Store.payments {
+			row := &r.Store.payments[i]
+			if row.tenantID != tenantID || row.value.PaymentID != rf.PaymentID {
+				continue
+			}
+			p := &row.value
+			p.RefundedAmount = math.Round((p.RefundedAmount-rf.Amount)*100) / 100
+			p.Status = model.PaymentPartiallyRefunded
+			if p.RefundedAmount <= 0 {
+				p.Status = model.PaymentPaid
+			}
+			p.Version++
+			r.Store.syncBookingPayment(tenantID, p.BookingID)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryPaymentRepository) GetRefunds(ctx context.Context, paymentID int) ([]model.PaymentRefund, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	refunds := []model.PaymentRefund{}
+	for _, row := range r.Store.refunds {
+		if row.tenantID == tenantID && row.value.PaymentID == paymentID {
+			refunds = append(refunds, row.value)
+		}
+	}
+	return refunds, nil
+}
+
+// bookingPayments lists the live payments of a booking and must be called with
+// the lock held.
+func (s *MemoryStore) bookingPayments(tenantID int64, bookingID string) []model.Payment {
+	var payments []model.Payment
+	for _, row := range s.payments {
+		p := row.value
+		if row.tenantID == tenantID && p.DeletedAt == nil && p.BookingID != nil && *p.BookingID == bookingID {
+			payments = append(payments, p)
+		}
+	}
+	return payments
+}
+
+// syncBookingPayment mirrors the Postgres derivation of a booking's payment
+// status and must be called with the write lock held.
+func (s *MemoryStore) syncBookingPayment(tenantID int64, bookingID *string) {
+	if bookingID == nil {
+		return
+	}
+
+	var booking *model.Booking
+	for i := range s.bookings {
+		if row := &s.bookings[i]; row.tenantID == tenantID && row.value.ID == *bookingID {
+			booking = &row.value
+		}
+	}
+	if booking == nil {
+		return
+	}
+	payments := s.bookingPayments(tenantID, *bookingID)
+	if len(payments) == 0 {
+		return
+	}
+
+	var fee *float64
+	for _, row := range s.cancellations {
+		if row.tenantID == tenantID && row.value.BookingID == *bookingID {
+			f := row.value.Fee
+			fee = &f
+		}
+	}
+	booking.Payment = model.SummarisePayments(amountDue(booking.Amount, fee), payments).PaymentStatus
+}
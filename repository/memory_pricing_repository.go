@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryPricingRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryPricingRepository(s *MemoryStore) *MemoryPricingRepository {
+	return &MemoryPricingRepository{Store: s}
+}
+
+func (r *MemoryPricingRepository) GetTariffs(ctx context.Context) ([]model.Tariff, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	tariffs := []model.Tariff{}
+	for _, row := range r.Store.tariffs {
+		if row.tenantID == tenantID {
+			tariffs = append(tariffs, row.value)
+		}
+	}
+	sort.Slice(tariffs, func(i, j int) bool { return tariffs[i].CarTypeID < tariffs[j].CarTypeID })
+	return tariffs, nil
+}
+
+func (r *MemoryPricingRepository) GetTariff(ctx context.Context, carTypeID string) (*model.Tariff, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.tariffs {
+		if row.tenantID == tenantID && row.value.CarTypeID == carTypeID {
+			t := row.value
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryPricingRepository) SaveTariff(ctx context.Context, t *model.Tariff) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.UpdatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.tariffs {
+		if r.Store.tariffs[i].tenantID == tenantID && r.Store.tariffs[i].value.CarTypeID == t.CarTypeID {
+			r.Store.tariffs[i].value = *t
+			return nil
+		}
+	}
+	r.Store.tariffs = append(r.Store.tariffs, memRow[model.Tariff]{tenantID: tenantID, value: *t})
+	return nil
+}
+
+func (r *MemoryPricingRepository) CreateQuote(ctx context.Context, q *model.Quote) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	q.CreatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	q.ID = r.Store.nextID("quotes")
+	stored := *q
+	stored.Items = append([]model.QuoteItem(nil), q.Items...)
+	stored.Legs = append([]model.Route(nil), q.Legs...)
+	stored.QuotedStops = append([]model.QuoteStop(nil), q.QuotedStops...)
+	r.Store.quotes = append(r.Store.quotes, memRow[model.Quote]{tenantID: tenantID, value: stored})
+	return nil
+}
+
+func (r *MemoryPricingRepository) GetQuote(ctx context.Context, id int) (*model.Quote, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.quotes {
+		if row.tenantID == tenantID && row.value.ID == id {
+			q := row.value
+			q.Items = append([]model.QuoteItem(nil), row.value.Items...)
+			q.Legs = append([]model.Route(nil), row.value.Legs...)
+			q.QuotedStops = append([]model.QuoteStop(nil), row.value.QuotedStops...)
+			return &q, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryPromotionRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryPromotionRepository(s *MemoryStore) *MemoryPromotionRepository {
+	return &MemoryPromotionRepository{Store: s}
+}
+
+func copyPromotion(p model.Promotion) model.Promotion {
+	p.CarTypeIDs = append([]string{}, p.CarTypeIDs...)
+	return p
+}
+
+func (r *MemoryPromotionRepository) GetAll(ctx context.Context) ([]model.Promotion, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	promotions := []model.Promotion{}
+	for i := len(r.Store.promotions) - 1; i >= 0; i-- {
+		if row := r.Store.promotions[i]; row.tenantID == tenantID {
+			promotions = append(promotions, copyPromotion(row.value))
+		}
+	}
+	return promotions, nil
+}
+
+// findPromotion must be called with the lock held.
+func (s *MemoryStore) findPromotion(tenantID int64, match func(p *model.Promotion) bool) *model.Promotion {
+	for i := range s.promotions {
+		if s.promotions[i].tenantID == tenantID && match(&s.promotions[i].value) {
+			return &s.promotions[i].value
+		}
+	}
+	return nil
+}
+
+func (r *MemoryPromotionRepository) GetByID(ctx context.Context, id int) (*model.Promotion, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	if p := r.Store.findPromotion(tenantID, func(p *model.Promotion) bool { return p.ID == id }); p != nil {
+		found := copyPromotion(*p)
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *MemoryPromotionRepository) GetByCode(ctx context.Context, code string) (*model.Promotion, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	code = normalizePromoCode(code)
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	if p := r.Store.findPromotion(tenantID, func(p *model.Promotion) bool { return p.Code == code }); p != nil {
+		found := copyPromotion(*p)
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *MemoryPromotionRepository) Create(ctx context.Context, p *model.Promotion) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizePromotion(p)
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if r.Store.findPromotion(tenantID, func(existing *model.Promotion) bool { return existing.Code == p.Code }) != nil {
+		return ErrDuplicateKey
+	}
+
+	p.ID = r.Store.nextID("promotions")
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+	p.Version = 1
+	r.Store.promotions = append(r.Store.promotions, memRow[model.Promotion]{tenantID: tenantID, value: copyPromotion(*p)})
+	return nil
+}
+
+func (r *MemoryPromotionRepository) Update(ctx context.Context, p *model.Promotion) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizePromotion(p)
+	p.UpdatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	stored := r.Store.findPromotion(tenantID, func(existing *model.Promotion) bool { return existing.ID == p.ID })
+	if stored == nil || stored.Version != p.Version {
+		return ErrVersionConflict
+	}
+
+	code, createdAt := stored.Code, stored.CreatedAt
+	*stored = copyPromotion(*p)
+	stored.Code = code
+	stored.CreatedAt = createdAt
+	stored.Version++
+
+	p.Version++
+	return nil
+}
+
+// promotionUsage must be called with the lock held.
+func (s *MemoryStore) promotionUsage(tenantID int64, promotionID int, customerID *int) (int, int) {
+	var total, customer int
+	for _, row := range s.redemptions {
+		if row.tenantID != tenantID || row.value.PromotionID != promotionID {
+			continue
+		}
+		total++
+		if sameRef(row.value.CustomerID, customerID) {
+			customer++
+		}
+	}
+	return total, customer
+}
+
+func (r *MemoryPromotionRepository) Usage(ctx context.Context, promotionID int, customerID *int) (int, int, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	total, customer := r.Store.promotionUsage(tenantID, promotionID, customerID)
+	return total, customer, nil
+}
+
+// redeemPromotion mirrors the Postgres insert transaction and must be called
+// with the write lock held.
+func (s *MemoryStore) redeemPromotion(tenantID int64, b *model.Booking) error {
+	code := normalizePromoCode(*b.PromoCode)
+	p := s.findPromotion(tenantID, func(p *model.Promotion) bool { return p.Code == code })
+	if p == nil {
+		return ErrUnknownPromotion
+	}
+
+	total, customer := s.promotionUsage(tenantID, p.ID, b.CustomerID)
+	if p.MaxRedemptions != nil && total >= *p.MaxRedemptions {
+		return ErrPromotionExhausted
+	}
+	if p.MaxPerCustomer != nil && b.CustomerID != nil && customer >= *p.MaxPerCustomer {
+		return ErrPromotionExhausted
+	}
+
+	var discount float64
+	if b.Discount != nil {
+		discount = *b.Discount
+	}
+	s.redemptions = append(s.redemptions, memRow[model.PromotionRedemption]{tenantID: tenantID, value: model.PromotionRedemption{
+		ID:          s.nextID("promotion_redemptions"),
+		PromotionID: p.ID,
+		BookingID:   b.ID,
+		CustomerID:  b.CustomerID,
+		Discount:    discount,
+		RedeemedAt:  b.CreatedAt,
+	}})
+	return nil
+}
+
+func (r *MemoryPromotionRepository) GetRedemptions(ctx context.Context, promotionID int) ([]model.PromotionRedemption, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	redemptions := []model.PromotionRedemption{}
+	for _, row := range r.Store.redemptions {
+		if row.tenantID == tenantID && row.value.PromotionID == promotionID {
+			redemptions = append(redemptions, row.value)
+		}
+	}
+	sort.SliceStable(redemptions, func(i, j int) bool {
+		if !redemptions[i].RedeemedAt.Equal(redemptions[j].RedeemedAt) {
+			return redemptions[i].RedeemedAt.After(redemptions[j].RedeemedAt)
+		}
+		return redemptions[i].ID > redemptions[j].ID
+	})
+	return redemptions, nil
+}
+
+func (r *MemoryPromotionRepository) Report(ctx context.Context, from, to time.Time) ([]model.PromotionReport, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	amounts := map[string]float64{}
+	for _, row := range r.Store.bookings {
+		if row.tenantID == tenantID && row.value.Amount != nil {
+			amounts[row.value.ID] = *row.value.Amount
+		}
+	}
+
+	byPromotion := map[int]*model.PromotionReport{}
+	customers := map[int]map[int]bool{}
+	for _, row := range r.Store.redemptions {
+		rd := row.value
+		if row.tenantID != tenantID || rd.RedeemedAt.Before(from) || !rd.RedeemedAt.Before(to) {
+			continue
+		}
+		pr := byPromotion[rd.PromotionID]
+		if pr == nil {
+			p := r.Store.findPromotion(tenantID, func(p *model.Promotion) bool { return p.ID == rd.PromotionID })
+			if p == nil {
+				continue
+			}
+			pr = &model.PromotionReport{PromotionID: p.ID, Code: p.Code}
+			byPromotion[rd.PromotionID] = pr
+			customers[rd.PromotionID] = map[int]bool{}
+		}
+		pr.Redemptions++
+		pr.TotalDiscount += rd.Discount
+		pr.NetRevenue += amounts[rd.BookingID]
+		if rd.CustomerID != nil {
+			customers[rd.PromotionID][*rd.CustomerID] = true
+		}
+	}
+
+	report := []model.PromotionReport{}
+	for id, pr := range byPromotion {
+		pr.Customers = len(customers[id])
+		report = append(report, *pr)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Redemptions != report[j].Redemptions {
+			return report[i].Redemptions > report[j].Redemptions
+		}
+		return report[i].Code < report[j].Code
+	})
+	return report, nil
+}
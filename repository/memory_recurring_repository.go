@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"time"
+)
+
+type MemoryRecurringBookingRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryRecurringBookingRepository(s *MemoryStore) *MemoryRecurringBookingRepository {
+	return &MemoryRecurringBookingRepository{Store: s}
+}
+
+func copyRecurring(row memRow[model.RecurringBooking]) model.RecurringBooking {
+	r := row.value
+	r.Weekdays = append([]string{}, r.Weekdays...)
+	r.Exceptions = append([]string{}, r.Exceptions...)
+	r.TenantID = row.tenantID
+	return r
+}
+
+func (r *MemoryRecurringBookingRepository) GetAll(ctx context.Context) ([]model.RecurringBooking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	series := []model.RecurringBooking{}
+	for _, row := range r.Store.recurring {
+		if row.tenantID == tenantID {
+			series = append(series, copyRecurring(row))
+		}
+	}
+	return series, nil
+}
+
+// GetActive is run by the recurring scheduler and spans every tenant.
+func (r *MemoryRecurringBookingRepository) GetActive(ctx context.Context, day string) ([]model.RecurringBooking, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	series := []model.RecurringBooking{}
+	for _, row := range r.Store.recurring {
+		if row.value.Until == nil || *row.value.Until >= day {
+			series = append(series, copyRecurring(row))
+		}
+	}
+	return series, nil
+}
+
+func (r *MemoryRecurringBookingRepository) GetByID(ctx context.Context, id int) (*model.RecurringBooking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.recurring {
+		if row.tenantID == tenantID && row.value.ID == id {
+			found := copyRecurring(row)
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryRecurringBookingRepository) Create(ctx context.Context, s *model.RecurringBooking) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeRecurring(s)
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	s.ID = r.Store.nextID("recurring_bookings")
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+	s.Version = 1
+	s.TenantID = tenantID
+	row := memRow[model.RecurringBooking]{tenantID: tenantID, value: *s}
+	row.value = copyRecurring(row)
+	r.Store.recurring = append(r.Store.recurring, row)
+	return nil
+}
+
+func (r *MemoryRecurringBookingRepository) Update(ctx context.Context, s *model.RecurringBooking) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeRecurring(s)
+	s.UpdatedAt = time.Now()
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.recurring {
+		stored := &r.Store.recurring[i]
+		if stored.tenantID != tenantID || stored.value.ID != s.ID {
+			continue
+		}
+		if stored.value.Version != s.Version {
+			return ErrVersionConflict
+		}
+
+		s.CreatedAt = stored.value.CreatedAt
+		s.Version++
+		s.TenantID = tenantID
+		stored.value = copyRecurring(memRow[model.RecurringBooking]{tenantID: tenantID, value: *s})
+		return nil
+	}
+	return ErrVersionConflict
+}
+
+func (r *MemoryRecurringBookingRepository) GetOccurrences(ctx context.Context, recurringID int) ([]model.RecurringOccurrence, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	occurrences := []model.RecurringOccurrence{}
+	for _, row := range r.Store.occurrences {
+		if row.tenantID == tenantID && row.value.RecurringID == recurringID {
+			occurrences = append(occurrences, row.value)
+		}
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool { return occurrences[i].Date < occurrences[j].Date })
+	return occurrences, nil
+}
+
+func (r *MemoryRecurringBookingRepository) ClaimOccurrence(ctx context.Context, o *model.RecurringOccurrence) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for _, row := range r.Store.occurrences {
+		if row.value.RecurringID == o.RecurringID && row.value.Date == o.Date {
+			return ErrDuplicateKey
+		}
+	}
+
+	o.ID = r.Store.nextID("recurring_occurrences")
+	o.CreatedAt = time.Now()
+	r.Store.occurrences = append(r.Store.occurrences, memRow[model.RecurringOccurrence]{tenantID: tenantID, value: *o})
+	return nil
+}
+
+func (r *MemoryRecurringBookingRepository) SaveOccurrence(ctx context.Context, o *model.RecurringOccurrence) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	for i := range r.Store.occurrences {
+		stored := &r.Store.occurrences[i]
+		if stored.tenantID == tenantID && stored.value.ID == o.ID {
+			stored.value.BookingID = o.BookingID
+			stored.value.Detached = o.Detached
+			stored.value.Error = o.Error
+			return nil
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"auth-service/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore holds every table of the in-memory backend. Repositories built
+// on the same store see each other's writes, which the analytics queries rely on.
+type MemoryStore struct {
+	mu  sync.RWMutex
+	seq map[string]int
+
+	tenants       []model.Tenant
+	users         []model.User
+	customers     []memRow[model.Customer]
+	tokens        []model.RefreshToken
+	drivers       []memRow[model.Driver]
+	leaves        []memRow[model.DriverLeave]
+	locations     []memRow[model.DriverLocation]
+	offers        []memRow[model.DispatchOffer]
+	cars          []memRow[model.Car]
+	bookings      []memRow[model.Booking]
+	stops         []memRow[model.BookingStop]
+	statuses      []memRow[model.BookingStatusChange]
+	tariffs       []memRow[model.Tariff]
+	quotes        []memRow[model.Quote]
+	promotions    []memRow[model.Promotion]
+	redemptions   []memRow[model.PromotionRedemption]
+	recurring     []memRow[model.RecurringBooking]
+	occurrences   []memRow[model.RecurringOccurrence]
+	policies      []memRow[model.CancellationPolicy]
+	cancellations []memRow[model.BookingCancellation]
+	preferences   []memRow[model.NotificationPreference]
+	notifications []memRow[model.Notification]
+	payments      []memRow[model.Payment]
+	callbacks     []model.PaymentCallback
+	refunds       []memRow[model.PaymentRefund]
+	accounts      []memRow[model.CorporateAccount]
+	invoices      []memRow[model.CorporateInvoice]
+	invoiceLines  []memRow[model.InvoiceLine]
+	remittances   []memRow[model.InvoicePayment]
+	trips         []memRow[model.VehicleTrip]
+	feedback      []memRow[model.TripFeedback]
+	ratingAlerts  []memRow[model.DriverRatingAlert]
+	assignments   []memRow[model.DriverAssignment]
+	maintenance   []memRow[model.VehicleMaintenance]
+	carModels     []memRow[model.CarModel]
+	carTypes      []memRow[model.CarType]
+	popular       []memRow[model.PopularDestination]
+	trends        []memRow[model.BookingTrend]
+	idempotency   []memRow[model.IdempotencyKey]
+	calendarFeeds []model.CalendarFeed
+}
+
+type memRow[T any] struct {
+	tenantID int64
+	value    T
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{seq: map[string]int{}}
+	s.tenants = append(s.tenants, model.Tenant{ID: int64(s.nextID("tenants")), Name: "Default", Slug: "default", CreatedAt: time.Now()})
+	return s
+}
+
+// nextID must be called with the write lock held.
+func (s *MemoryStore) nextID(table string) int {
+	s.seq[table]++
+	return s.seq[table]
+}
+
+// sortByDeletedAt orders trash listings newest first, matching ORDER BY deleted_at DESC.
+func sortByDeletedAt[T any](items []T, deletedAt func(T) *time.Time) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return deletedAt(items[i]).After(*deletedAt(items[j]))
+	})
+}
+
+func purgeRows[T any](rows []memRow[T], deletedAt func(T) *time.Time, before time.Time) ([]memRow[T], int64) {
+	kept := rows[:0]
+	var purged int64
+	for _, row := range rows {
+		if at := deletedAt(row.value); at != nil && !at.After(before) {
+			purged++
+			continue
+		}
+		kept = append(kept, row)
+	}
+	return kept, purged
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"time"
+)
+
+type MemoryTenantRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryTenantRepository(s *MemoryStore) *MemoryTenantRepository {
+	return &MemoryTenantRepository{Store: s}
+}
+
+func (r *MemoryTenantRepository) GetAll(ctx context.Context) ([]model.Tenant, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var tenants []model.Tenant
+	tenants = append(tenants, r.Store.tenants...)
+	return tenants, nil
+}
+
+func (r *MemoryTenantRepository) GetByID(ctx context.Context, id int64) (*model.Tenant, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, t := range r.Store.tenants {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryTenantRepository) Create(ctx context.Context, t *model.Tenant) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	t.ID = int64(r.Store.nextID("tenants"))
+	t.CreatedAt = time.Now()
+	r.Store.tenants = append(r.Store.tenants, *t)
+	return nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type MemoryTripHistoryRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryTripHistoryRepository(s *MemoryStore) *MemoryTripHistoryRepository {
+	return &MemoryTripHistoryRepository{Store: s}
+}
+
+func (r *MemoryTripHistoryRepository) GetTripHistory(ctx context.Context) ([]model.TripHistory, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	trips := []model.TripHistory{}
+	for _, row := range r.Store.trips {
+		if row.tenantID == tenantID {
+			h := tripHistory(row.value)
+			h.Stops = r.Store.stopAddresses(tenantID, row.value.BookingID)
+			trips = append(trips, h)
+		}
+	}
+	return trips, nil
+}
+
+// tripHistory is a trip as the history and PDF queries read it back, with the
+// timestamp rendered the way database/sql renders it into a string.
+func tripHistory(t model.VehicleTrip) model.TripHistory {
+	h := model.TripHistory{
+		ID:              int(t.ID),
+		CustomerName:    t.PassengerName,
+		BookingDate:     t.TripDate.Format(time.RFC3339Nano),
+		DurationMinutes: t.DurationMinutes,
+		DistanceKM:      t.DistanceKM,
+		PickupLocation:  t.Origin,
+		Destination:     t.Destination,
+		DriverName:      t.DriverName,
+		VehicleName:     t.VehicleName,
+		Amount:          t.Price,
+		Rating:          t.Rating,
+		Feedback:        t.Feedback,
+	}
+	if t.BookingID != nil {
+		h.BookingCode = *t.BookingID
+	}
+	return h
+}
+
+// stopAddresses mirrors tripStopsColumn.
+func (s *MemoryStore) stopAddresses(tenantID int64, bookingID *string) []string {
+	var stops []model.BookingStop
+	for _, row := range s.stops {
+		if row.tenantID == tenantID && bookingID != nil && row.value.BookingID == *bookingID {
+			stops = append(stops, row.value)
+		}
+	}
+	sort.Slice(stops, func(i, j int) bool { return stops[i].Position < stops[j].Position })
+	addresses := []string{}
+	for _, st := range stops {
+		addresses = append(addresses, st.Address)
+	}
+	return addresses
+}
+
+type MemoryPDFRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryPDFRepository(s *MemoryStore) *MemoryPDFRepository {
+	return &MemoryPDFRepository{Store: s}
+}
+
+func (r *MemoryPDFRepository) GetTripByID(ctx context.Context, tripID string) (model.Pdf, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return model.Pdf{}, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, row := range r.Store.trips {
+		if row.tenantID != tenantID || strconv.Itoa(int(row.value.ID)) != tripID {
+			continue
+		}
+
+		t := tripHistory(row.value)
+		return model.Pdf{
+			ID:              tripID,
+			BookingCode:     t.BookingCode,
+			CustomerName:    t.CustomerName,
+			BookingDate:     row.value.TripDate,
+			DurationMinutes: t.DurationMinutes,
+			DistanceKM:      t.DistanceKM,
+			PickupLocation:  t.PickupLocation,
+			Destination:     t.Destination,
+			DriverName:      t.DriverName,
+			VehicleName:     t.VehicleName,
+			Amount:          t.Amount,
+			Rating:          t.Rating,
+			Feedback:        t.Feedback,
+			Stops:           r.Store.stopAddresses(tenantID, row.value.BookingID),
+		}, nil
+	}
+
+	return model.Pdf{}, nil
+}
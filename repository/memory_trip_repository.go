@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+)
+
+type MemoryTripsRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryTripRepo(s *MemoryStore) *MemoryTripsRepository {
+	return &MemoryTripsRepository{Store: s}
+}
+
+func (r *MemoryTripsRepository) Create(ctx context.Context, t *model.VehicleTrip) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if t.Status == "" {
+		t.Status = model.TripCompleted
+	}
+	r.Store.saveTrip(tenantID, t)
+	return nil
+}
+
+func (r *MemoryTripsRepository) Update(ctx context.Context, t *model.VehicleTrip) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	if t.Status == "" {
+		t.Status = model.TripCompleted
+	}
+	if r.Store.trip(tenantID, t.ID) != nil {
+		r.Store.saveTrip(tenantID, t)
+	}
+	return nil
+}
+
+// saveTrip mirrors the Postgres saveTrip and must be called with the write
+// lock held.
+func (s *MemoryStore) saveTrip(tenantID int64, t *model.VehicleTrip) {
+	if t.ID == 0 {
+		t.ID = uint(s.nextID("trips"))
+		s.trips = append(s.trips, memRow[model.VehicleTrip]{tenantID: tenantID, value: *t})
+		return
+	}
+	if stored := s.trip(tenantID, t.ID); stored != nil {
+		*stored = *t
+	}
+}
+
+func (s *MemoryStore) trip(tenantID int64, id uint) *model.VehicleTrip {
+	for i := range s.trips {
+		if s.trips[i].tenantID == tenantID && s.trips[i].value.ID == id {
+			return &s.trips[i].value
+		}
+	}
+	return nil
+}
+
+func (r *MemoryTripsRepository) Delete(ctx context.Context, id uint) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	kept := r.Store.trips[:0]
+	for _, row := range r.Store.trips {
+		if row.tenantID == tenantID && row.value.ID == id {
+			continue
+		}
+		kept = append(kept, row)
+	}
+	r.Store.trips = kept
+
+	// Mirrors ON DELETE CASCADE on trip_feedback.
+	feedback := r.Store.feedback[:0]
+	for _, row := range r.Store.feedback {
+		if row.tenantID != tenantID || row.value.TripID != id {
+			feedback = append(feedback, row)
+		}
+	}
+	r.Store.feedback = feedback
+	return nil
+}
+
+func (r *MemoryTripsRepository) FindByID(ctx context.Context, id uint) (*model.VehicleTrip, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	if stored := r.Store.trip(tenantID, id); stored != nil {
+		t := *stored
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (r *MemoryTripsRepository) FindByBooking(ctx context.Context, bookingID string) (*model.VehicleTrip, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for i := len(r.Store.trips) - 1; i >= 0; i-- {
+		row := r.Store.trips[i]
+		if row.tenantID == tenantID && row.value.BookingID != nil && *row.value.BookingID == bookingID {
+			t := row.value
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MemoryTripsRepository) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.VehicleTrip, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	var trips []model.VehicleTrip
+	for _, row := range r.Store.trips {
+		if row.tenantID == tenantID && row.value.VehicleID == vehicleID {
+			trips = append(trips, row.value)
+		}
+	}
+	return trips, nil
+}
+
+func (r *MemoryTripsRepository) GetTripTotal(ctx context.Context) (*model.TotalTrips, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	stats := &model.TotalTrips{}
+	var ratings float64
+	var rated int
+	for _, row := range r.Store.trips {
+		if row.tenantID != tenantID || row.value.Status != model.TripCompleted {
+			continue
+		}
+		stats.TotalTrips++
+		stats.TotalDistance += int64(row.value.DistanceKM)
+		stats.TotalRevenue += row.value.Price
+		if row.value.Rating > 0 {
+			ratings += float64(row.value.Rating)
+			rated++
+		}
+	}
+	if rated > 0 {
+		stats.AverageRating = ratings / float64(rated)
+	}
+
+	return stats, nil
+}
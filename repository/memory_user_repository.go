@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"auth-service/model"
+	"database/sql"
+)
+
+type MemoryUserRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryUserRepository(s *MemoryStore) *MemoryUserRepository {
+	return &MemoryUserRepository{Store: s}
+}
+
+func (r *MemoryUserRepository) Save(user *model.User) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	u := *user
+	u.ID = int64(r.Store.nextID("users"))
+	r.Store.users = append(r.Store.users, u)
+	return nil
+}
+
+func (r *MemoryUserRepository) FindByUsername(username string) (*model.User, error) {
+	r.Store.mu.RLock()
+	defer r.Store.mu.RUnlock()
+
+	for _, u := range r.Store.users {
+		if u.Username == username {
+			return &u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+type MemoryTokenRepository struct {
+	Store *MemoryStore
+}
+
+func NewMemoryTokenRepository(s *MemoryStore) *MemoryTokenRepository {
+	return &MemoryTokenRepository{Store: s}
+}
+
+func (r *MemoryTokenRepository) Save(token *model.RefreshToken) error {
+	r.Store.mu.Lock()
+	defer r.Store.mu.Unlock()
+
+	t := *token
+	t.ID = int64(r.Store.nextID("refresh_tokens"))
+	r.Store.tokens = append(r.Store.tokens, t)
+	return nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type NotificationRepositoryInterface interface {
+	Enqueue(ctx context.Context, n *model.Notification) error
+	GetAll(ctx context.Context, status string) ([]model.Notification, error)
+	GetPreference(ctx context.Context, customerID int) (*model.NotificationPreference, error)
+	SavePreference(ctx context.Context, p *model.NotificationPreference) error
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.Notification, error)
+	MarkSent(ctx context.Context, id int, at time.Time) error
+	MarkFailed(ctx context.Context, id int, reason string, retryAt *time.Time) error
+}
+
+type NotificationRepository struct {
+	DB *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{DB: db}
+}
+
+const notificationColumns = `id, event, channel, recipient, locale, subject, body, booking_id, customer_id, status, attempts, last_error, next_attempt_at, sent_at, created_at, tenant_id`
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (r *NotificationRepository) Enqueue(ctx context.Context, n *model.Notification) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+	return enqueueNotification(ctx, r.DB, tenantID, n)
+}
+
+// enqueueNotification is shared with the booking transition transaction so a
+// status change and its notifications are committed together.
+func enqueueNotification(ctx context.Context, q queryRower, tenantID int64, n *model.Notification) error {
+	n.Status = model.NotificationPending
+	n.CreatedAt = time.Now()
+	n.NextAttemptAt = n.CreatedAt
+	n.TenantID = tenantID
+
+	return q.QueryRowContext(ctx,
+		`INSERT INTO notifications (event, channel, recipient, locale, subject, body, booking_id, customer_id, status, next_attempt_at, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		n.Event, n.Channel, n.Recipient, n.Locale, n.Subject, n.Body, n.BookingID, n.CustomerID, n.Status, n.NextAttemptAt, n.CreatedAt, tenantID,
+	).Scan(&n.ID)
+}
+
+// GetAll lists the tenant's outbox newest first; an empty status lists everything.
+func (r *NotificationRepository) GetAll(ctx context.Context, status string) ([]model.Notification, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT `+notificationColumns+` FROM notifications WHERE tenant_id = $1 AND ($2 = '' OR status = $2) ORDER BY created_at DESC, id DESC`,
+		tenantID, status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotifications(rows)
+}
+
+// GetPreference returns nil when the customer has not saved a preference.
+func (r *NotificationRepository) GetPreference(ctx context.Context, customerID int) (*model.NotificationPreference, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p := model.NotificationPreference{CustomerID: customerID}
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT locale, channels, updated_at FROM notification_preferences WHERE customer_id = $1 AND tenant_id = $2`,
+		customerID, tenantID,
+	).Scan(&p.Locale, pq.Array(&p.Channels), &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SavePreference creates the customer's preference or replaces it.
+func (r *NotificationRepository) SavePreference(ctx context.Context, p *model.NotificationPreference) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	if p.Channels == nil {
+		p.Channels = []string{}
+	}
+	p.UpdatedAt = time.Now()
+
+	_, err = r.DB.ExecContext(ctx, `
+        INSERT INTO notification_preferences (customer_id, locale, channels, updated_at, tenant_id)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (customer_id) DO UPDATE SET
+            locale = EXCLUDED.locale,
+            channels = EXCLUDED.channels,
+            updated_at = EXCLUDED.updated_at
+    `,
+		p.CustomerID, p.Locale, pq.Array(p.Channels), p.UpdatedAt, tenantID,
+	)
+	return err
+}
+
+// ClaimDue is run by the notification dispatcher and spans every tenant. Each
+// claimed message counts an attempt and is hidden until leaseUntil, so a
+// crashed dispatcher's messages are picked up again later.
+func (r *NotificationRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]model.Notification, error) {
+	rows, err := r.DB.QueryContext(ctx,
+		`UPDATE notifications SET attempts = attempts + 1, next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM notifications WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at LIMIT $3 FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+notificationColumns,
+		now, leaseUntil, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanNotifications(rows)
+}
+
+// MarkSent is run by the notification dispatcher and spans every tenant.
+func (r *NotificationRepository) MarkSent(ctx context.Context, id int, at time.Time) error {
+	_, err := r.DB.ExecContext(ctx,
+		`UPDATE notifications SET status = 'sent', sent_at = $1, last_error = '' WHERE id = $2`,
+		at, id,
+	)
+	return err
+}
+
+// MarkFailed is run by the notification dispatcher and spans every tenant. A
+// nil retryAt gives up on the message.
+func (r *NotificationRepository) MarkFailed(ctx context.Context, id int, reason string, retryAt *time.Time) error {
+	_, err := r.DB.ExecContext(ctx,
+		`UPDATE notifications SET last_error = $1,
+			status = CASE WHEN $2::timestamp IS NULL THEN 'failed' ELSE status END,
+			next_attempt_at = COALESCE($2, next_attempt_at)
+		WHERE id = $3`,
+		reason, retryAt, id,
+	)
+	return err
+}
+
+func scanNotifications(rows *sql.Rows) ([]model.Notification, error) {
+	notifications := []model.Notification{}
+	for rows.Next() {
+		var n model.Notification
+		if err := rows.Scan(&n.ID, &n.Event, &n.Channel, &n.Recipient, &n.Locale, &n.Subject, &n.Body, &n.BookingID, &n.CustomerID,
+			&n.Status, &n.Attempts, &n.LastError, &n.NextAttemptAt, &n.SentAt, &n.CreatedAt, &n.TenantID); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var notificationColumns = []string{"id", "event", "channel", "recipient", "locale", "subject", "body", "booking_id", "customer_id", "status", "attempts", "last_error", "next_attempt_at", "sent_at", "created_at", "tenant_id"}
+
+func TestNotificationRepository_Preference(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewNotificationRepository(db)
+
+	mock.ExpectQuery(`FROM notification_preferences WHERE customer_id = \$1 AND tenant_id = \$2`).
+		WithArgs(4, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"locale", "channels", "updated_at"}))
+	missing, err := repo.GetPreference(tenantCtx(), 4)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	mock.ExpectExec(`INSERT INTO notification_preferences .* ON CONFLICT \(customer_id\) DO UPDATE`).
+		WithArgs(4, "en", sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	pref := &model.NotificationPreference{CustomerID: 4, Locale: "en"}
+	assert.NoError(t, repo.SavePreference(tenantCtx(), pref))
+	assert.Equal(t, []string{}, pref.Channels)
+
+	mock.ExpectQuery(`FROM notification_preferences`).
+		WithArgs(4, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"locale", "channels", "updated_at"}).AddRow("en", "{whatsapp,email}", time.Now()))
+	found, err := repo.GetPreference(tenantCtx(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"whatsapp", "email"}, found.Channels)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_Outbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewNotificationRepository(db)
+
+	mock.ExpectQuery(`INSERT INTO notifications`).
+		WithArgs(model.NotifyPaymentReceived, model.ChannelSMS, "+62811", "id", "", "Halo", nil, nil, model.NotificationPending, sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	n := &model.Notification{Event: model.NotifyPaymentReceived, Channel: model.ChannelSMS, Recipient: "+62811", Locale: "id", Body: "Halo"}
+	assert.NoError(t, repo.Enqueue(tenantCtx(), n))
+	assert.Equal(t, 3, n.ID)
+	assert.Equal(t, n.CreatedAt, n.NextAttemptAt)
+
+	now := time.Now()
+	lease := now.Add(5 * time.Minute)
+	mock.ExpectQuery(`UPDATE notifications SET attempts = attempts \+ 1, next_attempt_at = \$2 WHERE id IN \( SELECT id FROM notifications WHERE status = 'pending' AND next_attempt_at <= \$1 ORDER BY next_attempt_at LIMIT \$3 FOR UPDATE SKIP LOCKED \)`).
+		WithArgs(now, lease, 50).
+		WillReturnRows(sqlmock.NewRows(notificationColumns).
+			AddRow(3, model.NotifyPaymentReceived, model.ChannelSMS, "+62811", "id", "", "Halo", nil, nil, model.NotificationPending, 1, "", lease, nil, now, int64(2)))
+	claimed, err := repo.ClaimDue(context.Background(), now, lease, 50)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+	assert.Equal(t, int64(2), claimed[0].TenantID)
+
+	mock.ExpectExec(`UPDATE notifications SET last_error = \$1`).
+		WithArgs("gateway down", nil, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkFailed(context.Background(), 3, "gateway down", nil))
+
+	mock.ExpectExec(`UPDATE notifications SET status = 'sent'`).
+		WithArgs(now, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkSent(context.Background(), 3, now))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,16 +5,32 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+var ErrRefundExceedsCaptured = errors.New("refund exceeds what was captured and not yet refunded")
+
 type PaymentRepositoryInterface interface {
-	GetPayments(page, pageSize int) ([]model.Payment, error)
+	GetPayments(ctx context.Context, page, pageSize int) ([]model.Payment, error)
 	GetPaymentStats(ctx context.Context) (*model.PaymentStats, error)
 	GetAll(ctx context.Context) ([]model.Payment, error)
 	GetByID(ctx context.Context, id int) (*model.Payment, error)
+	GetByBooking(ctx context.Context, bookingID string) ([]model.Payment, error)
 	Create(ctx context.Context, p *model.Payment) (int, error)
 	Update(ctx context.Context, p *model.Payment) error
-	Delete(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int, version int) error
+	GetDeleted(ctx context.Context) ([]model.Payment, error)
+	Restore(ctx context.Context, id int) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+	FindByProviderRef(ctx context.Context, ref string) (*model.Payment, error)
+	RecordCallback(ctx context.Context, cb *model.PaymentCallback) error
+	ForgetCallback(ctx context.Context, eventID string) error
+	AddRefund(ctx context.Context, rf *model.PaymentRefund) error
+	SettleRefund(ctx context.Context, id int, status string) error
+	GetRefunds(ctx context.Context, paymentID int) ([]model.PaymentRefund, error)
 }
 
 type PaymentRepository struct {
@@ -26,25 +42,33 @@ func NewPaymentRepository(db *sql.DB) *PaymentRepository {
 }
 
 func (r *PaymentRepository) GetPaymentStats(ctx context.Context) (*model.PaymentStats, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	stats := &model.PaymentStats{}
 
-	err := r.DB.QueryRowContext(ctx, `
-	SELECT 
-		CAST(COALESCE(SUM(amount), 0) AS BIGINT) 
-		FROM payment 
-	WHERE status = 'paid';
-	`).Scan(&stats.TotalPayment)
+	// Refunds count the refunded part of captured payments.
+	err = r.DB.QueryRowContext(ctx, `
+	SELECT
+		CAST(COALESCE(SUM(amount) FILTER (WHERE status IN ('paid', 'partially_refunded', 'refunded')), 0) AS BIGINT),
+		CAST(COALESCE(SUM(refunded_amount), 0) AS BIGINT)
+		FROM payment
+	WHERE tenant_id = $1 AND deleted_at IS NULL;
+	`, tenantID).Scan(&stats.TotalPayment, &stats.TotalRefunded)
 	if err != nil {
 		return nil, err
 	}
+	stats.NetPayment = stats.TotalPayment - stats.TotalRefunded
 
 	err = r.DB.QueryRowContext(ctx, `
-        SELECT 
-			CAST(COALESCE(SUM(amount), 0) AS BIGINT) 
-			FROM payment 
-		WHERE status = 'pending';
+        SELECT
+			CAST(COALESCE(SUM(amount), 0) AS BIGINT)
+			FROM payment
+		WHERE status = 'pending' AND tenant_id = $1 AND deleted_at IS NULL;
 
-    `).Scan(&stats.PendingPayment)
+    `, tenantID).Scan(&stats.PendingPayment)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +76,8 @@ func (r *PaymentRepository) GetPaymentStats(ctx context.Context) (*model.Payment
 	err = r.DB.QueryRowContext(ctx, `
         SELECT COUNT(*)
         FROM payment
-    `).Scan(&stats.TotalTransactions)
+        WHERE tenant_id = $1 AND deleted_at IS NULL
+    `, tenantID).Scan(&stats.TotalTransactions)
 	if err != nil {
 		return nil, err
 	}
@@ -60,41 +85,279 @@ func (r *PaymentRepository) GetPaymentStats(ctx context.Context) (*model.Payment
 	return stats, nil
 }
 
-func (r *PaymentRepository) GetPayments(page, pageSize int) ([]model.Payment, error) {
-	offset := (page - 1) * pageSize
-	query := `SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date FROM payment LIMIT $1 OFFSET $2`
-	rows, err := r.DB.Query(query, pageSize, offset)
+const paymentColumns = `payment_id, booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, payment_date, expires_at, provider_ref, pay_code, version`
+
+func scanPayment(row rowScanner) (*model.Payment, error) {
+	var p model.Payment
+	err := row.Scan(
+		&p.PaymentID,
+		&p.BookingID,
+		&p.Customer,
+		&p.CustomerID,
+		&p.Driver,
+		&p.Amount,
+		&p.RefundedAmount,
+		&p.Method,
+		&p.Status,
+		&p.PaymentDate,
+		&p.ExpiresAt,
+		&p.ProviderRef,
+		&p.PayCode,
+		&p.Version,
+	)
 	if err != nil {
 		return nil, err
 	}
+	return &p, nil
+}
+
+func scanPayments(rows *sql.Rows) ([]model.Payment, error) {
 	defer rows.Close()
 
 	var payments []model.Payment
 	for rows.Next() {
-		var p model.Payment
-		if err := rows.Scan(
-			&p.PaymentID,
-			&p.BookingID,
-			&p.Customer,
-			&p.Driver,
-			&p.Amount,
-			&p.Method,
-			&p.Status,
-			&p.PaymentDate,
-		); err != nil {
+		p, err := scanPayment(rows)
+		if err != nil {
 			return nil, err
 		}
+		payments = append(payments, *p)
+	}
 
-		payments = append(payments, p)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return payments, nil
 }
 
+func (r *PaymentRepository) GetPayments(ctx context.Context, page, pageSize int) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE tenant_id = $3 AND deleted_at IS NULL LIMIT $1 OFFSET $2`
+	rows, err := r.DB.QueryContext(ctx, query, pageSize, offset, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPayments(rows)
+}
+
 func (r *PaymentRepository) GetAll(ctx context.Context) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT `+paymentColumns+`
+		 FROM payment
+		 WHERE tenant_id=$1 AND deleted_at IS NULL`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPayments(rows)
+}
+
+func (r *PaymentRepository) GetByID(ctx context.Context, id int) (*model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := scanPayment(r.DB.QueryRowContext(ctx,
+		`SELECT `+paymentColumns+`
+		 FROM payment
+		 WHERE payment_id=$1 AND tenant_id=$2 AND deleted_at IS NULL`,
+		id, tenantID,
+	))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// GetByBooking lists the payments of a booking in the order they were made.
+func (r *PaymentRepository) GetByBooking(ctx context.Context, bookingID string) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT `+paymentColumns+`
+		 FROM payment
+		 WHERE booking_id=$1 AND tenant_id=$2 AND deleted_at IS NULL
+		 ORDER BY payment_id`, bookingID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPayments(rows)
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, p *model.Payment) (int, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO payment (booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, expires_at, provider_ref, pay_code, tenant_id)
+         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+         RETURNING payment_id`,
+		p.BookingID,
+		p.Customer,
+		p.CustomerID,
+		p.Driver,
+		p.Amount,
+		p.RefundedAmount,
+		p.Method,
+		p.Status,
+		p.ExpiresAt,
+		p.ProviderRef,
+		p.PayCode,
+		tenantID,
+	).Scan(&id)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := syncBookingPayment(ctx, tx, tenantID, p.BookingID); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	p.Version = 1
+	return id, nil
+}
+
+func (r *PaymentRepository) Update(ctx context.Context, p *model.Payment) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A payment moved to another booking changes the status of both.
+	var previous *string
+	err = tx.QueryRowContext(ctx,
+		`SELECT booking_id FROM payment WHERE payment_id=$1 AND tenant_id=$2 AND deleted_at IS NULL FOR UPDATE`,
+		p.PaymentID, tenantID,
+	).Scan(&previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = versionedResult(tx.ExecContext(ctx,
+		`UPDATE payment
+		 SET booking_id=$1, customer=$2, customer_id=$3, driver=$4, amount=$5, refunded_amount=$6, method=$7, status=$8, expires_at=$9, provider_ref=$10, pay_code=$11, version=version+1
+		 WHERE payment_id=$12 AND version=$13 AND tenant_id=$14 AND deleted_at IS NULL`,
+		p.BookingID,
+		p.Customer,
+		p.CustomerID,
+		p.Driver,
+		p.Amount,
+		p.RefundedAmount,
+		p.Method,
+		p.Status,
+		p.ExpiresAt,
+		p.ProviderRef,
+		p.PayCode,
+		p.PaymentID,
+		p.Version,
+		tenantID,
+	))
+	if err != nil {
+		return err
+	}
+
+	if previous != nil && (p.BookingID == nil || *previous != *p.BookingID) {
+		if err := syncBookingPayment(ctx, tx, tenantID, previous); err != nil {
+			return err
+		}
+	}
+	if err := syncBookingPayment(ctx, tx, tenantID, p.BookingID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	p.Version++
+	return nil
+}
+
+func (r *PaymentRepository) Delete(ctx context.Context, id int, version int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var bookingID *string
+	err = tx.QueryRowContext(ctx,
+		`UPDATE payment SET deleted_at=NOW(), version=version+1
+		 WHERE payment_id=$1 AND version=$2 AND tenant_id=$3 AND deleted_at IS NULL
+		 RETURNING booking_id`, id, version, tenantID).Scan(&bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return missingOrConflict(ctx, tx, "payment", "payment_id", id, tenantID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := syncBookingPayment(ctx, tx, tenantID, bookingID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PaymentRepository) GetDeleted(ctx context.Context) ([]model.Payment, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := r.DB.QueryContext(ctx,
-		`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date 
-		 FROM payment`)
+		`SELECT `+paymentColumns+`, deleted_at
+		 FROM payment
+		 WHERE tenant_id=$1 AND deleted_at IS NOT NULL
+		 ORDER BY deleted_at DESC`, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -108,95 +371,362 @@ func (r *PaymentRepository) GetAll(ctx context.Context) ([]model.Payment, error)
 			&p.PaymentID,
 			&p.BookingID,
 			&p.Customer,
+			&p.CustomerID,
 			&p.Driver,
 			&p.Amount,
+			&p.RefundedAmount,
 			&p.Method,
 			&p.Status,
 			&p.PaymentDate,
+			&p.ExpiresAt,
+			&p.ProviderRef,
+			&p.PayCode,
+			&p.Version,
+			&p.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
 		payments = append(payments, p)
 	}
 
+	return payments, rows.Err()
+}
+
+func (r *PaymentRepository) Restore(ctx context.Context, id int) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var bookingID *string
+	err = tx.QueryRowContext(ctx,
+		`UPDATE payment SET deleted_at=NULL, version=version+1
+		 WHERE payment_id=$1 AND tenant_id=$2 AND deleted_at IS NOT NULL
+		 RETURNING booking_id`, id, tenantID).Scan(&bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := syncBookingPayment(ctx, tx, tenantID, bookingID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ExpirePending expires pending and authorised payments whose time ran out,
+// in every tenant, and returns how many it expired.
+func (r *PaymentRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`UPDATE payment SET status='expired', version=version+1
+		 WHERE status IN ('pending', 'authorised') AND expires_at <= $1 AND deleted_at IS NULL
+		 RETURNING tenant_id, booking_id`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	type bookingRef struct {
+		tenantID  int64
+		bookingID string
+	}
+	var n int64
+	var bookings []bookingRef
+	seen := map[bookingRef]bool{}
+	for rows.Next() {
+		var ref bookingRef
+		var bookingID *string
+		if err := rows.Scan(&ref.tenantID, &bookingID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		n++
+		if bookingID == nil {
+			continue
+		}
+		ref.bookingID = *bookingID
+		if !seen[ref] {
+			seen[ref] = true
+			bookings = append(bookings, ref)
+		}
+	}
+	rows.Close()
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	return payments, nil
+	for _, ref := range bookings {
+		if err := syncBookingPayment(ctx, tx, ref.tenantID, &ref.bookingID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return n, nil
 }
 
-func (r *PaymentRepository) GetByID(ctx context.Context, id int) (*model.Payment, error) {
+// FindByProviderRef looks a payment up by its gateway order id in every
+// tenant; gateway callbacks arrive without one.
+func (r *PaymentRepository) FindByProviderRef(ctx context.Context, ref string) (*model.Payment, error) {
 	var p model.Payment
-
 	err := r.DB.QueryRowContext(ctx,
-		`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date 
-		 FROM payment 
-		 WHERE payment_id=$1`,
-		id,
+		`SELECT `+paymentColumns+`, tenant_id
+		 FROM payment
+		 WHERE provider_ref=$1 AND deleted_at IS NULL`, ref,
 	).Scan(
 		&p.PaymentID,
 		&p.BookingID,
 		&p.Customer,
+		&p.CustomerID,
 		&p.Driver,
 		&p.Amount,
+		&p.RefundedAmount,
 		&p.Method,
 		&p.Status,
 		&p.PaymentDate,
+		&p.ExpiresAt,
+		&p.ProviderRef,
+		&p.PayCode,
+		&p.Version,
+		&p.TenantID,
 	)
-
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// RecordCallback remembers an accepted gateway callback. A callback that was
+// recorded before returns ErrDuplicateKey.
+func (r *PaymentRepository) RecordCallback(ctx context.Context, cb *model.PaymentCallback) error {
+	_, err := r.DB.ExecContext(ctx,
+		`INSERT INTO payment_callbacks (event_id, provider_ref, received_at) VALUES ($1, $2, $3)`,
+		cb.EventID, cb.ProviderRef, cb.ReceivedAt,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+// ForgetCallback drops a recorded callback that could not be applied, so the
+// gateway's retry of it is accepted.
+func (r *PaymentRepository) ForgetCallback(ctx context.Context, eventID string) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM payment_callbacks WHERE event_id = $1`, eventID)
+	return err
+}
+
+const refundColumns = `id, payment_id, amount, reason, status, gateway, approved_by, cancellation_id, created_at`
+
+// AddRefund records rf and adds it to the refunded amount of its payment,
+// which becomes partially refunded or, once nothing is left, refunded. Only
+// captured payments can be refunded, and never by more than is left of them;
+// otherwise it fails with ErrRefundExceedsCaptured.
+func (r *PaymentRepository) AddRefund(ctx context.Context, rf *model.PaymentRefund) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	bookingID, err := addRefund(ctx, tx, tenantID, rf)
+	if err != nil {
+		return err
+	}
+	if err := syncBookingPayment(ctx, tx, tenantID, bookingID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
+// addRefund is AddRefund inside tx, and returns the booking of the refunded
+// payment so the caller can bring its payment status up to date.
+func addRefund(ctx context.Context, tx *sql.Tx, tenantID int64, rf *model.PaymentRefund) (*string, error) {
+	var bookingID *string
+	err := tx.QueryRowContext(ctx,
+		`UPDATE payment SET refunded_amount = refunded_amount + $1,
+			status = CASE WHEN refunded_amount + $1 >= amount THEN 'refunded' ELSE 'partially_refunded' END,
+			version = version + 1
+		 WHERE payment_id = $2 AND tenant_id = $3 AND deleted_at IS NULL
+		   AND status IN ('paid', 'partially_refunded') AND refunded_amount + $1 <= amount
+		 RETURNING booking_id`, rf.Amount, rf.PaymentID, tenantID,
+	).Scan(&bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefundExceedsCaptured
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &p, nil
+	rf.CreatedAt = time.Now()
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO payment_refunds (payment_id, amount, reason, status, gateway, approved_by, cancellation_id, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		rf.PaymentID, rf.Amount, rf.Reason, rf.Status, rf.Gateway, rf.ApprovedBy, rf.CancellationID, rf.CreatedAt, tenantID,
+	).Scan(&rf.ID)
+	if err != nil {
+		return nil, err
+	}
+	return bookingID, nil
 }
 
-func (r *PaymentRepository) Create(ctx context.Context, p *model.Payment) (int, error) {
-	var id int
+// SettleRefund records how the gateway carried out a pending refund. A failed
+// refund gives its amount back to the payment.
+func (r *PaymentRepository) SettleRefund(ctx context.Context, id int, status string) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
 
-	err := r.DB.QueryRowContext(ctx,
-		`INSERT INTO payment (booking_id, customer, driver, amount, method, status) 
-         VALUES ($1,$2,$3,$4,$5,$6) 
-         RETURNING payment_id`,
-		p.BookingID,
-		p.Customer,
-		p.Driver,
-		p.Amount,
-		p.Method,
-		p.Status,
-	).Scan(&id)
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
+	var paymentID int
+	var amount float64
+	err = tx.QueryRowContext(ctx,
+		`UPDATE payment_refunds SET status = $1
+		 WHERE id = $2 AND tenant_id = $3 AND status = 'pending'
+		 RETURNING payment_id, amount`, status, id, tenantID,
+	).Scan(&paymentID, &amount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	return id, nil
+	if status == model.RefundFailed {
+		var bookingID *string
+		err = tx.QueryRowContext(ctx,
+			`UPDATE payment SET refunded_amount = refunded_amount - $1,
+				status = CASE WHEN refunded_amount - $1 <= 0 THEN 'paid' ELSE 'partially_refunded' END,
+				version = version + 1
+			 WHERE payment_id = $2 AND tenant_id = $3
+			 RETURNING booking_id`, amount, paymentID, tenantID,
+		).Scan(&bookingID)
+		if err != nil {
+			return err
+		}
+		if err := syncBookingPayment(ctx, tx, tenantID, bookingID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-func (r *PaymentRepository) Update(ctx context.Context, p *model.Payment) error {
-	_, err := r.DB.ExecContext(ctx,
-		`UPDATE payment 
-		 SET booking_id=$1, customer=$2, driver=$3, amount=$4, method=$5, status=$6
-		 WHERE payment_id=$7`,
-		p.BookingID,
-		p.Customer,
-		p.Driver,
-		p.Amount,
-		p.Method,
-		p.Status,
-		p.PaymentID,
-	)
+// GetRefunds lists the refunds of a payment in the order they were made.
+func (r *PaymentRepository) GetRefunds(ctx context.Context, paymentID int) ([]model.PaymentRefund, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	return queryRefunds(ctx, r.DB, `payment_id = $1 AND tenant_id = $2`, paymentID, tenantID)
 }
 
-func (r *PaymentRepository) Delete(ctx context.Context, id int) error {
-	_, err := r.DB.ExecContext(ctx,
-		`DELETE FROM payment WHERE payment_id=$1`, id)
+// queryRefunds lists the refunds matching where in the order they were made.
+func queryRefunds(ctx context.Context, db *sql.DB, where string, args ...any) ([]model.PaymentRefund, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+refundColumns+` FROM payment_refunds WHERE `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []model.PaymentRefund{}
+	for rows.Next() {
+		var rf model.PaymentRefund
+		if err := rows.Scan(&rf.ID, &rf.PaymentID, &rf.Amount, &rf.Reason, &rf.Status, &rf.Gateway, &rf.ApprovedBy, &rf.CancellationID, &rf.CreatedAt); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, rf)
+	}
+	return refunds, rows.Err()
+}
+
+// syncBookingPayment derives the payment status of a booking from its
+// payments. What a booking owes is its fare, or its fee once cancelled.
+// Bookings without payments keep the status they were given.
+func syncBookingPayment(ctx context.Context, tx *sql.Tx, tenantID int64, bookingID *string) error {
+	if bookingID == nil {
+		return nil
+	}
+
+	var amount, fee *float64
+	err := tx.QueryRowContext(ctx,
+		`SELECT b.amount, c.fee
+		 FROM booking b LEFT JOIN booking_cancellations c ON c.booking_id = b.id
+		 WHERE b.id=$1 AND b.tenant_id=$2
+		 FOR UPDATE OF b`, *bookingID, tenantID,
+	).Scan(&amount, &fee)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+paymentColumns+`
+		 FROM payment
+		 WHERE booking_id=$1 AND tenant_id=$2 AND deleted_at IS NULL`, *bookingID, tenantID)
+	if err != nil {
+		return err
+	}
+	payments, err := scanPayments(rows)
+	if err != nil || len(payments) == 0 {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE booking SET payment=$1 WHERE id=$2 AND tenant_id=$3`,
+		model.SummarisePayments(amountDue(amount, fee), payments).PaymentStatus, *bookingID, tenantID)
 	return err
 }
+
+// amountDue is what a booking owes: its cancellation fee when it has one,
+// otherwise its fare.
+func amountDue(amount, fee *float64) float64 {
+	switch {
+	case fee != nil:
+		return *fee
+	case amount != nil:
+		return *amount
+	}
+	return 0
+}
+
+// PurgeDeleted is run by the retention job and spans every tenant.
+func (r *PaymentRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.DB.ExecContext(ctx,
+		`DELETE FROM payment WHERE deleted_at IS NOT NULL AND deleted_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
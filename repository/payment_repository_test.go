@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,10 +21,10 @@ func TestPaymentRepository_GetPaymentStats_Success(t *testing.T) {
 
 	repo := repository.NewPaymentRepository(db)
 
-	rows := sqlmock.NewRows([]string{"total_payment"}).AddRow(1000)
-	mock.ExpectQuery(`SELECT CAST\(COALESCE\(SUM\(amount\), 0\) AS BIGINT\) FROM payment WHERE status = 'paid'`).
+	rows := sqlmock.NewRows([]string{"total_payment", "total_refunded"}).AddRow(1000, 250)
+	mock.ExpectQuery(`SELECT CAST\(COALESCE\(SUM\(amount\) FILTER \(WHERE status IN \('paid', 'partially_refunded', 'refunded'\)\), 0\) AS BIGINT\), CAST\(COALESCE\(SUM\(refunded_amount\), 0\) AS BIGINT\) FROM payment WHERE tenant_id = \$1`).
+		WithArgs(int64(1)).
 		WillReturnRows(rows)
-
 	rows2 := sqlmock.NewRows([]string{"pending_payment"}).AddRow(500)
 	mock.ExpectQuery(`SELECT CAST\(COALESCE\(SUM\(amount\), 0\) AS BIGINT\) FROM payment WHERE status = 'pending'`).
 		WillReturnRows(rows2)
@@ -32,10 +33,12 @@ func TestPaymentRepository_GetPaymentStats_Success(t *testing.T) {
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM payment`).
 		WillReturnRows(rows3)
 
-	stats, err := repo.GetPaymentStats(context.Background())
+	stats, err := repo.GetPaymentStats(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1000), stats.TotalPayment)
+	assert.Equal(t, int64(250), stats.TotalRefunded)
+	assert.Equal(t, int64(750), stats.NetPayment)
 	assert.Equal(t, int64(500), stats.PendingPayment)
 	assert.Equal(t, int64(10), stats.TotalTransactions)
 
@@ -50,10 +53,10 @@ func TestPaymentRepository_GetPaymentStats_Error(t *testing.T) {
 
 	repo := repository.NewPaymentRepository(db)
 
-	mock.ExpectQuery(`SELECT CAST\(COALESCE\(SUM\(amount\), 0\) AS BIGINT\) FROM payment WHERE status = 'paid'`).
+	mock.ExpectQuery(`SELECT CAST\(COALESCE\(SUM\(amount\) FILTER`).
 		WillReturnError(sql.ErrConnDone)
 
-	stats, err := repo.GetPaymentStats(context.Background())
+	stats, err := repo.GetPaymentStats(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, stats)
@@ -72,15 +75,15 @@ func TestPaymentRepository_GetPayments_Success(t *testing.T) {
 
 	page := 1
 	pageSize := 10
-	rows := sqlmock.NewRows([]string{"payment_id", "booking_id", "customer", "driver", "amount", "method", "status", "payment_date"}).
-		AddRow(1, 1, "Customer1", "Driver1", 100.0, "Credit", "paid", "2023-01-01").
-		AddRow(2, 2, "Customer2", "Driver2", 200.0, "Cash", "pending", "2023-01-02")
+	rows := sqlmock.NewRows([]string{"payment_id", "booking_id", "customer", "customer_id", "driver", "amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version"}).
+		AddRow(1, "BK1", "Customer1", nil, "Driver1", 100.0, 0.0, "Credit", "paid", "2023-01-01", nil, nil, "", 1).
+		AddRow(2, "BK2", "Customer2", nil, "Driver2", 200.0, 0.0, "Cash", "pending", "2023-01-02", nil, nil, "", 1)
 
-	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date FROM payment LIMIT \$1 OFFSET \$2`).
-		WithArgs(pageSize, 0).
+	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, payment_date, expires_at, provider_ref, pay_code, version FROM payment WHERE tenant_id = \$3 AND deleted_at IS NULL LIMIT \$1 OFFSET \$2`).
+		WithArgs(pageSize, 0, int64(1)).
 		WillReturnRows(rows)
 
-	payments, err := repo.GetPayments(page, pageSize)
+	payments, err := repo.GetPayments(tenantCtx(), page, pageSize)
 
 	assert.NoError(t, err)
 	assert.Len(t, payments, 2)
@@ -103,11 +106,11 @@ func TestPaymentRepository_GetPayments_Error(t *testing.T) {
 	page := 1
 	pageSize := 10
 
-	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date FROM payment LIMIT \$1 OFFSET \$2`).
-		WithArgs(pageSize, 0).
+	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, payment_date, expires_at, provider_ref, pay_code, version FROM payment WHERE tenant_id = \$3 AND deleted_at IS NULL LIMIT \$1 OFFSET \$2`).
+		WithArgs(pageSize, 0, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	payments, err := repo.GetPayments(page, pageSize)
+	payments, err := repo.GetPayments(tenantCtx(), page, pageSize)
 
 	assert.Error(t, err)
 	assert.Nil(t, payments)
@@ -124,14 +127,14 @@ func TestPaymentRepository_GetAll_Success(t *testing.T) {
 
 	repo := repository.NewPaymentRepository(db)
 
-	rows := sqlmock.NewRows([]string{"payment_id", "booking_id", "customer", "driver", "amount", "method", "status", "payment_date"}).
-		AddRow(1, 1, "Customer1", "Driver1", 100.0, "Credit", "paid", "2023-01-01").
-		AddRow(2, 2, "Customer2", "Driver2", 200.0, "Cash", "pending", "2023-01-02")
+	rows := sqlmock.NewRows([]string{"payment_id", "booking_id", "customer", "customer_id", "driver", "amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version"}).
+		AddRow(1, "BK1", "Customer1", nil, "Driver1", 100.0, 0.0, "Credit", "paid", "2023-01-01", nil, nil, "", 1).
+		AddRow(2, "BK2", "Customer2", nil, "Driver2", 200.0, 0.0, "Cash", "pending", "2023-01-02", nil, nil, "", 1)
 
-	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date FROM payment`).
+	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, payment_date, expires_at, provider_ref, pay_code, version FROM payment`).
 		WillReturnRows(rows)
 
-	payments, err := repo.GetAll(context.Background())
+	payments, err := repo.GetAll(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, payments, 2)
@@ -149,10 +152,10 @@ func TestPaymentRepository_GetAll_Error(t *testing.T) {
 
 	repo := repository.NewPaymentRepository(db)
 
-	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date FROM payment`).
+	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, payment_date, expires_at, provider_ref, pay_code, version FROM payment`).
 		WillReturnError(sql.ErrConnDone)
 
-	payments, err := repo.GetAll(context.Background())
+	payments, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, payments)
@@ -170,14 +173,14 @@ func TestPaymentRepository_GetByID_Success(t *testing.T) {
 	repo := repository.NewPaymentRepository(db)
 
 	id := 1
-	rows := sqlmock.NewRows([]string{"payment_id", "booking_id", "customer", "driver", "amount", "method", "status", "payment_date"}).
-		AddRow(1, 1, "Customer1", "Driver1", 100.0, "Credit", "paid", "2023-01-01")
+	rows := sqlmock.NewRows([]string{"payment_id", "booking_id", "customer", "customer_id", "driver", "amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version"}).
+		AddRow(1, "BK1", "Customer1", nil, "Driver1", 100.0, 0.0, "Credit", "paid", "2023-01-01", nil, nil, "", 1)
 
-	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date FROM payment WHERE payment_id=\$1`).
-		WithArgs(id).
+	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, payment_date, expires_at, provider_ref, pay_code, version FROM payment WHERE payment_id=\$1`).
+		WithArgs(id, int64(1)).
 		WillReturnRows(rows)
 
-	payment, err := repo.GetByID(context.Background(), id)
+	payment, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, payment)
@@ -197,11 +200,11 @@ func TestPaymentRepository_GetByID_NotFound(t *testing.T) {
 
 	id := 1
 
-	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date FROM payment WHERE payment_id=\$1`).
-		WithArgs(id).
+	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, payment_date, expires_at, provider_ref, pay_code, version FROM payment WHERE payment_id=\$1`).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrNoRows)
 
-	payment, err := repo.GetByID(context.Background(), id)
+	payment, err := repo.GetByID(tenantCtx(), id)
 
 	assert.NoError(t, err)
 	assert.Nil(t, payment)
@@ -219,11 +222,11 @@ func TestPaymentRepository_GetByID_Error(t *testing.T) {
 
 	id := 1
 
-	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, driver, amount, method, status, payment_date FROM payment WHERE payment_id=\$1`).
-		WithArgs(id).
+	mock.ExpectQuery(`SELECT payment_id, booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, payment_date, expires_at, provider_ref, pay_code, version FROM payment WHERE payment_id=\$1`).
+		WithArgs(id, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	payment, err := repo.GetByID(context.Background(), id)
+	payment, err := repo.GetByID(tenantCtx(), id)
 
 	assert.Error(t, err)
 	assert.Nil(t, payment)
@@ -241,7 +244,7 @@ func TestPaymentRepository_Create_Success(t *testing.T) {
 	repo := repository.NewPaymentRepository(db)
 
 	payment := &model.Payment{
-		BookingID: 1,
+		BookingID: stringPtr("BK1"),
 		Customer:  "Customer1",
 		Driver:    "Driver1",
 		Amount:    100.0,
@@ -249,11 +252,15 @@ func TestPaymentRepository_Create_Success(t *testing.T) {
 		Status:    "paid",
 	}
 
-	mock.ExpectQuery(`INSERT INTO payment \(booking_id, customer, driver, amount, method, status\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6\) RETURNING payment_id`).
-		WithArgs(payment.BookingID, payment.Customer, payment.Driver, payment.Amount, payment.Method, payment.Status).
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO payment \(booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, expires_at, provider_ref, pay_code, tenant_id\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10,\$11,\$12\) RETURNING payment_id`).
+		WithArgs(payment.BookingID, payment.Customer, payment.CustomerID, payment.Driver, payment.Amount, 0.0, payment.Method, payment.Status, nil, nil, "", int64(1)).
 		WillReturnRows(sqlmock.NewRows([]string{"payment_id"}).AddRow(1))
+	expectBookingPaymentSync(mock, "BK1", 150.0, model.BookingPartiallyPaid,
+		paymentRows().AddRow(1, "BK1", "Customer1", nil, "Driver1", 100.0, 0.0, "Credit", "paid", "2023-01-01", nil, nil, "", 1))
+	mock.ExpectCommit()
 
-	id, err := repo.Create(context.Background(), payment)
+	id, err := repo.Create(tenantCtx(), payment)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, id)
@@ -262,6 +269,25 @@ func TestPaymentRepository_Create_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// paymentRows returns the columns payments are read with.
+func paymentRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"payment_id", "booking_id", "customer", "customer_id", "driver", "amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version"})
+}
+
+// expectBookingPaymentSync expects the payment status of bookingID, which
+// costs amount, to be derived from payments as status.
+func expectBookingPaymentSync(mock sqlmock.Sqlmock, bookingID string, amount float64, status string, payments *sqlmock.Rows) {
+	mock.ExpectQuery(`SELECT b.amount, c.fee\s+FROM booking b LEFT JOIN booking_cancellations c`).
+		WithArgs(bookingID, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"amount", "fee"}).AddRow(amount, nil))
+	mock.ExpectQuery(`FROM payment\s+WHERE booking_id=\$1 AND tenant_id=\$2 AND deleted_at IS NULL`).
+		WithArgs(bookingID, int64(1)).
+		WillReturnRows(payments)
+	mock.ExpectExec(`UPDATE booking SET payment=\$1 WHERE id=\$2 AND tenant_id=\$3`).
+		WithArgs(status, bookingID, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
 func TestPaymentRepository_Create_Error(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -270,7 +296,7 @@ func TestPaymentRepository_Create_Error(t *testing.T) {
 	repo := repository.NewPaymentRepository(db)
 
 	payment := &model.Payment{
-		BookingID: 1,
+		BookingID: stringPtr("BK1"),
 		Customer:  "Customer1",
 		Driver:    "Driver1",
 		Amount:    100.0,
@@ -278,11 +304,13 @@ func TestPaymentRepository_Create_Error(t *testing.T) {
 		Status:    "paid",
 	}
 
-	mock.ExpectQuery(`INSERT INTO payment \(booking_id, customer, driver, amount, method, status\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6\) RETURNING payment_id`).
-		WithArgs(payment.BookingID, payment.Customer, payment.Driver, payment.Amount, payment.Method, payment.Status).
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO payment \(booking_id, customer, customer_id, driver, amount, refunded_amount, method, status, expires_at, provider_ref, pay_code, tenant_id\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10,\$11,\$12\) RETURNING payment_id`).
+		WithArgs(payment.BookingID, payment.Customer, payment.CustomerID, payment.Driver, payment.Amount, 0.0, payment.Method, payment.Status, nil, nil, "", int64(1)).
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	id, err := repo.Create(context.Background(), payment)
+	id, err := repo.Create(tenantCtx(), payment)
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, id)
@@ -301,21 +329,33 @@ func TestPaymentRepository_Update_Success(t *testing.T) {
 
 	payment := &model.Payment{
 		PaymentID: 1,
-		BookingID: 1,
+		BookingID: stringPtr("BK2"),
 		Customer:  "Customer1",
 		Driver:    "Driver1",
 		Amount:    100.0,
 		Method:    "Credit",
 		Status:    "paid",
+		Version:   2,
 	}
 
-	mock.ExpectExec(`UPDATE payment SET booking_id=\$1, customer=\$2, driver=\$3, amount=\$4, method=\$5, status=\$6 WHERE payment_id=\$7`).
-		WithArgs(payment.BookingID, payment.Customer, payment.Driver, payment.Amount, payment.Method, payment.Status, payment.PaymentID).
+	// Moving the payment to another booking updates both bookings.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT booking_id FROM payment WHERE payment_id=\$1 AND tenant_id=\$2 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow("BK1"))
+	mock.ExpectExec(`UPDATE payment SET booking_id=\$1, customer=\$2, customer_id=\$3, driver=\$4, amount=\$5, refunded_amount=\$6, method=\$7, status=\$8, expires_at=\$9, provider_ref=\$10, pay_code=\$11, version=version\+1 WHERE payment_id=\$12 AND version=\$13`).
+		WithArgs(payment.BookingID, payment.Customer, payment.CustomerID, payment.Driver, payment.Amount, 0.0, payment.Method, payment.Status, nil, nil, "", payment.PaymentID, 2, int64(1)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectBookingPaymentSync(mock, "BK1", 100.0, model.BookingPaymentPending,
+		paymentRows().AddRow(2, "BK1", "Customer1", nil, "Driver1", 100.0, 0.0, "Credit", "pending", "2023-01-01", nil, nil, "", 1))
+	expectBookingPaymentSync(mock, "BK2", 100.0, model.BookingPaid,
+		paymentRows().AddRow(1, "BK2", "Customer1", nil, "Driver1", 100.0, 0.0, "Credit", "paid", "2023-01-01", nil, nil, "", 3))
+	mock.ExpectCommit()
 
-	err = repo.Update(context.Background(), payment)
+	err = repo.Update(tenantCtx(), payment)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 3, payment.Version)
 
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
@@ -330,19 +370,25 @@ func TestPaymentRepository_Update_Error(t *testing.T) {
 
 	payment := &model.Payment{
 		PaymentID: 1,
-		BookingID: 1,
+		BookingID: stringPtr("BK1"),
 		Customer:  "Customer1",
 		Driver:    "Driver1",
 		Amount:    100.0,
 		Method:    "Credit",
 		Status:    "paid",
+		Version:   2,
 	}
 
-	mock.ExpectExec(`UPDATE payment SET booking_id=\$1, customer=\$2, driver=\$3, amount=\$4, method=\$5, status=\$6 WHERE payment_id=\$7`).
-		WithArgs(payment.BookingID, payment.Customer, payment.Driver, payment.Amount, payment.Method, payment.Status, payment.PaymentID).
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT booking_id FROM payment`).
+		WithArgs(1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow("BK1"))
+	mock.ExpectExec(`UPDATE payment SET booking_id=\$1, customer=\$2, customer_id=\$3, driver=\$4, amount=\$5, refunded_amount=\$6, method=\$7, status=\$8, expires_at=\$9, provider_ref=\$10, pay_code=\$11, version=version\+1 WHERE payment_id=\$12 AND version=\$13`).
+		WithArgs(payment.BookingID, payment.Customer, payment.CustomerID, payment.Driver, payment.Amount, 0.0, payment.Method, payment.Status, nil, nil, "", payment.PaymentID, 2, int64(1)).
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	err = repo.Update(context.Background(), payment)
+	err = repo.Update(tenantCtx(), payment)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -360,11 +406,13 @@ func TestPaymentRepository_Delete_Success(t *testing.T) {
 
 	id := 1
 
-	mock.ExpectExec(`DELETE FROM payment WHERE payment_id=\$1`).
-		WithArgs(id).
-		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment SET deleted_at=NOW\(\), version=version\+1 WHERE payment_id=\$1 AND version=\$2 AND tenant_id=\$3 AND deleted_at IS NULL RETURNING booking_id`).
+		WithArgs(id, 1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow(nil))
+	mock.ExpectCommit()
 
-	err = repo.Delete(context.Background(), id)
+	err = repo.Delete(tenantCtx(), id, 1)
 
 	assert.NoError(t, err)
 
@@ -381,11 +429,13 @@ func TestPaymentRepository_Delete_Error(t *testing.T) {
 
 	id := 1
 
-	mock.ExpectExec(`DELETE FROM payment WHERE payment_id=\$1`).
-		WithArgs(id).
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment SET deleted_at=NOW\(\), version=version\+1 WHERE payment_id=\$1 AND version=\$2 AND tenant_id=\$3 AND deleted_at IS NULL RETURNING booking_id`).
+		WithArgs(id, 1, int64(1)).
 		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
 
-	err = repo.Delete(context.Background(), id)
+	err = repo.Delete(tenantCtx(), id, 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -400,13 +450,13 @@ func TestPaymentRepository_GetPaymentStats_ScanError(t *testing.T) {
 
 	repo := repository.NewPaymentRepository(db)
 
-	mock.ExpectQuery(`SELECT\s+CAST\(COALESCE\(SUM\(amount\), 0\) AS BIGINT\)`).
+	mock.ExpectQuery(`SELECT\s+CAST\(COALESCE\(SUM\(amount\) FILTER`).
 		WillReturnRows(
-			sqlmock.NewRows([]string{"sum"}).
-				AddRow("INVALID"), // ❌ harus int64
+			sqlmock.NewRows([]string{"sum", "refunded"}).
+				AddRow("INVALID", 0), // ❌ harus int64
 		)
 
-	stats, err := repo.GetPaymentStats(context.Background())
+	stats, err := repo.GetPaymentStats(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, stats)
@@ -419,18 +469,18 @@ func TestPaymentRepository_GetPayments_ScanError(t *testing.T) {
 	repo := repository.NewPaymentRepository(db)
 
 	rows := sqlmock.NewRows([]string{
-		"payment_id", "booking_id", "customer", "driver",
-		"amount", "method", "status", "payment_date",
+		"payment_id", "booking_id", "customer", "customer_id", "driver",
+		"amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version",
 	}).AddRow(
-		1, 1, "Cust", "Driver",
-		"INVALID_AMOUNT", // ❌ string → number
-		"CASH", "paid", time.Now(),
+		1, "BK1", "Cust", nil, "Driver",
+		"INVALID_AMOUNT", 0.0, // ❌ string → number
+		"CASH", "paid", time.Now(), nil, nil, "", 1,
 	)
 
 	mock.ExpectQuery(`FROM payment`).
 		WillReturnRows(rows)
 
-	result, err := repo.GetPayments(1, 10)
+	result, err := repo.GetPayments(tenantCtx(), 1, 10)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -443,18 +493,18 @@ func TestPaymentRepository_GetAll_ScanError(t *testing.T) {
 	repo := repository.NewPaymentRepository(db)
 
 	rows := sqlmock.NewRows([]string{
-		"payment_id", "booking_id", "customer", "driver",
-		"amount", "method", "status", "payment_date",
+		"payment_id", "booking_id", "customer", "customer_id", "driver",
+		"amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version",
 	}).AddRow(
-		1, 1, "Cust", "Driver",
-		"INVALID",
-		"CASH", "paid", time.Now(),
+		1, "BK1", "Cust", nil, "Driver",
+		"INVALID", 0.0,
+		"CASH", "paid", time.Now(), nil, nil, "", 1,
 	)
 
 	mock.ExpectQuery(`FROM payment`).
 		WillReturnRows(rows)
 
-	result, err := repo.GetAll(context.Background())
+	result, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -467,16 +517,16 @@ func TestPaymentRepository_GetAll_RowsError(t *testing.T) {
 	repo := repository.NewPaymentRepository(db)
 
 	rows := sqlmock.NewRows([]string{
-		"payment_id", "booking_id", "customer", "driver",
-		"amount", "method", "status", "payment_date",
+		"payment_id", "booking_id", "customer", "customer_id", "driver",
+		"amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version",
 	}).
-		AddRow(1, 1, "Cust", "Driver", 1000, "CASH", "paid", time.Now()).
+		AddRow(1, "BK1", "Cust", nil, "Driver", 1000, 0.0, "CASH", "paid", time.Now(), nil, nil, "", 1).
 		RowError(0, errors.New("row error"))
 
 	mock.ExpectQuery(`FROM payment`).
 		WillReturnRows(rows)
 
-	result, err := repo.GetAll(context.Background())
+	result, err := repo.GetAll(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -489,10 +539,10 @@ func TestPaymentRepository_GetByID_ScanError(t *testing.T) {
 	repo := repository.NewPaymentRepository(db)
 
 	mock.ExpectQuery(`FROM payment WHERE payment_id=\$1`).
-		WithArgs(1).
+		WithArgs(1, int64(1)).
 		WillReturnError(errors.New("db error"))
 
-	result, err := repo.GetByID(context.Background(), 1)
+	result, err := repo.GetByID(tenantCtx(), 1)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -504,13 +554,15 @@ func TestPaymentRepository_Create_ScanError(t *testing.T) {
 
 	repo := repository.NewPaymentRepository(db)
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(`INSERT INTO payment`).
 		WillReturnRows(
 			sqlmock.NewRows([]string{"payment_id"}).
 				AddRow("INVALID"), // ❌ int expected
 		)
+	mock.ExpectRollback()
 
-	id, err := repo.Create(context.Background(), &model.Payment{})
+	id, err := repo.Create(tenantCtx(), &model.Payment{})
 
 	assert.Error(t, err)
 	assert.Equal(t, 0, id)
@@ -528,7 +580,7 @@ func TestGetPaymentStats_TotalPaymentScanError(t *testing.T) {
 				AddRow("INVALID"),
 		)
 
-	stats, err := repo.GetPaymentStats(context.Background())
+	stats, err := repo.GetPaymentStats(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, stats)
@@ -552,7 +604,7 @@ func TestGetPaymentStats_PendingPaymentScanError(t *testing.T) {
 				AddRow("INVALID"),
 		)
 
-	stats, err := repo.GetPaymentStats(context.Background())
+	stats, err := repo.GetPaymentStats(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, stats)
@@ -582,8 +634,260 @@ func TestGetPaymentStats_TotalTransactionsScanError(t *testing.T) {
 				AddRow("INVALID"),
 		)
 
-	stats, err := repo.GetPaymentStats(context.Background())
+	stats, err := repo.GetPaymentStats(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, stats)
 }
+
+func TestPaymentRepository_Update_VersionConflict(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT booking_id FROM payment`).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow(nil))
+	mock.ExpectExec(`UPDATE payment SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	payment := &model.Payment{PaymentID: 1, Version: 1}
+	err := repo.Update(tenantCtx(), payment)
+
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	assert.Equal(t, 1, payment.Version)
+}
+
+func TestPaymentRepository_Trash(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+	ctx := tenantCtx()
+	deletedAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"payment_id", "booking_id", "customer", "customer_id", "driver", "amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version", "deleted_at"}).
+		AddRow(1, "BK1", "Customer1", nil, "Driver1", 100.0, 0.0, "Credit", "paid", "2023-01-01", nil, nil, "", 2, deletedAt)
+
+	mock.ExpectQuery(`FROM payment WHERE tenant_id=\$1 AND deleted_at IS NOT NULL`).WillReturnRows(rows)
+
+	payments, err := repo.GetDeleted(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, payments, 1)
+	assert.Equal(t, deletedAt, *payments[0].DeletedAt)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment SET deleted_at=NULL`).WithArgs(1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow("BK1"))
+	expectBookingPaymentSync(mock, "BK1", 100.0, model.BookingPaid,
+		paymentRows().AddRow(1, "BK1", "Customer1", nil, "Driver1", 100.0, 0.0, "Credit", "paid", "2023-01-01", nil, nil, "", 3))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.Restore(ctx, 1))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment SET deleted_at=NULL`).WithArgs(2, int64(1)).WillReturnRows(sqlmock.NewRows([]string{"booking_id"}))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.Restore(ctx, 2), repository.ErrNotFound)
+
+	mock.ExpectExec(`DELETE FROM payment WHERE deleted_at IS NOT NULL AND deleted_at <= \$1`).
+		WithArgs(deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := repo.PurgeDeleted(ctx, deletedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_GetByBooking(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+
+	mock.ExpectQuery(`FROM payment\s+WHERE booking_id=\$1 AND tenant_id=\$2 AND deleted_at IS NULL\s+ORDER BY payment_id`).
+		WithArgs("BK1", int64(1)).
+		WillReturnRows(paymentRows().
+			AddRow(1, "BK1", "Sari", nil, "Budi", 100.0, 25.0, "qris", "partially_refunded", "2023-01-01", nil, nil, "", 3))
+
+	payments, err := repo.GetByBooking(tenantCtx(), "BK1")
+	assert.NoError(t, err)
+	assert.Len(t, payments, 1)
+	assert.Equal(t, "BK1", *payments[0].BookingID)
+	assert.Equal(t, 25.0, payments[0].RefundedAmount)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_ExpirePending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+	now := time.Now()
+
+	// Expiry spans tenants and updates each booking once.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment SET status='expired'`).
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "booking_id"}).
+			AddRow(int64(1), "BK1").
+			AddRow(int64(1), "BK1").
+			AddRow(int64(2), nil))
+	expectBookingPaymentSync(mock, "BK1", 100.0, model.BookingUnpaid,
+		paymentRows().AddRow(1, "BK1", "Sari", nil, "Budi", 100.0, 0.0, "qris", "expired", "2023-01-01", now, nil, "", 2))
+	mock.ExpectCommit()
+
+	n, err := repo.ExpirePending(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_FindByProviderRef(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+
+	columns := []string{"payment_id", "booking_id", "customer", "customer_id", "driver", "amount", "refunded_amount", "method", "status", "payment_date", "expires_at", "provider_ref", "pay_code", "version", "tenant_id"}
+	mock.ExpectQuery(`FROM payment\s+WHERE provider_ref=\$1 AND deleted_at IS NULL`).
+		WithArgs("PAY-1").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, "BK1", "Sari", nil, "Budi", 100.0, 0.0, "qris", "pending", "2023-01-01", nil, "PAY-1", "000201", 1, int64(4)))
+	mock.ExpectQuery(`FROM payment\s+WHERE provider_ref=\$1`).
+		WithArgs("PAY-2").
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	// Callbacks carry no tenant, so the lookup needs none.
+	p, err := repo.FindByProviderRef(context.Background(), "PAY-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), p.TenantID)
+	assert.Equal(t, "000201", p.PayCode)
+
+	p, err = repo.FindByProviderRef(context.Background(), "PAY-2")
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_RecordCallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+	cb := &model.PaymentCallback{EventID: "evt-1", ProviderRef: "PAY-1", ReceivedAt: time.Now()}
+
+	mock.ExpectExec(`INSERT INTO payment_callbacks`).
+		WithArgs("evt-1", "PAY-1", cb.ReceivedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO payment_callbacks`).
+		WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectExec(`DELETE FROM payment_callbacks WHERE event_id = \$1`).
+		WithArgs("evt-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RecordCallback(context.Background(), cb))
+	assert.ErrorIs(t, repo.RecordCallback(context.Background(), cb), repository.ErrDuplicateKey)
+	assert.NoError(t, repo.ForgetCallback(context.Background(), "evt-1"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_AddRefund(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+	approver := int64(7)
+	refund := &model.PaymentRefund{PaymentID: 1, Amount: 30.0, Reason: "double charge", Status: model.RefundSucceeded, ApprovedBy: &approver}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment SET refunded_amount = refunded_amount \+ \$1, .+ WHERE payment_id = \$2 AND tenant_id = \$3 .+ AND refunded_amount \+ \$1 <= amount RETURNING booking_id`).
+		WithArgs(30.0, 1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow("BK1"))
+	mock.ExpectQuery(`INSERT INTO payment_refunds \(payment_id, amount, reason, status, gateway, approved_by, cancellation_id, created_at, tenant_id\)`).
+		WithArgs(1, 30.0, "double charge", model.RefundSucceeded, false, &approver, nil, sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	expectBookingPaymentSync(mock, "BK1", 100.0, model.BookingPartiallyRefunded,
+		paymentRows().AddRow(1, "BK1", "Customer1", nil, "Driver1", 100.0, 30.0, "Credit", "partially_refunded", "2023-01-01", nil, nil, "", 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.AddRefund(tenantCtx(), refund))
+	assert.Equal(t, 4, refund.ID)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment SET refunded_amount`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.AddRefund(tenantCtx(), &model.PaymentRefund{PaymentID: 1, Amount: 80.0}), repository.ErrRefundExceedsCaptured)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_SettleRefund(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment_refunds SET status = \$1 WHERE id = \$2 AND tenant_id = \$3 AND status = 'pending' RETURNING payment_id, amount`).
+		WithArgs(model.RefundSucceeded, 4, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"payment_id", "amount"}).AddRow(1, 30.0))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SettleRefund(tenantCtx(), 4, model.RefundSucceeded))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment_refunds SET status = \$1`).
+		WithArgs(model.RefundFailed, 5, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"payment_id", "amount"}).AddRow(1, 30.0))
+	mock.ExpectQuery(`UPDATE payment SET refunded_amount = refunded_amount - \$1, .+ WHERE payment_id = \$2 AND tenant_id = \$3 RETURNING booking_id`).
+		WithArgs(30.0, 1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"booking_id"}).AddRow(nil))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SettleRefund(tenantCtx(), 5, model.RefundFailed))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE payment_refunds SET status = \$1`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.SettleRefund(tenantCtx(), 4, model.RefundFailed), repository.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_GetRefunds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPaymentRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT id, payment_id, amount, reason, status, gateway, approved_by, cancellation_id, created_at FROM payment_refunds WHERE payment_id = \$1 AND tenant_id = \$2 ORDER BY id`).
+		WithArgs(1, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payment_id", "amount", "reason", "status", "gateway", "approved_by", "cancellation_id", "created_at"}).
+			AddRow(4, 1, 30.0, "double charge", "succeeded", false, 7, nil, now).
+			AddRow(5, 1, 70.0, "refunded at the payment gateway", "succeeded", true, nil, nil, now))
+
+	refunds, err := repo.GetRefunds(tenantCtx(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, refunds, 2)
+	assert.Equal(t, int64(7), *refunds[0].ApprovedBy)
+	assert.Nil(t, refunds[1].ApprovedBy)
+	assert.True(t, refunds[1].Gateway)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
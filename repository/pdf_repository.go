@@ -2,11 +2,14 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 type PDFRepositoryInterface interface {
-	GetTripByID(tripID string) (model.Pdf, error)
+	GetTripByID(ctx context.Context, tripID string) (model.Pdf, error)
 }
 
 type PDFRepository struct {
@@ -17,22 +20,28 @@ func NewPDFRepository(db *sql.DB) *PDFRepository {
 	return &PDFRepository{DB: db}
 }
 
-func (r *PDFRepository) GetTripByID(tripID string) (model.Pdf, error) {
+func (r *PDFRepository) GetTripByID(ctx context.Context, tripID string) (model.Pdf, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return model.Pdf{}, err
+	}
+
 	var trip model.Pdf
 
 	query := `
         SELECT
-            id, customer_name, booking_date, duration_minutes, distance_km,
+            id, COALESCE(booking_id, ''), customer_name, booking_date, duration_minutes, distance_km,
             pickup_location, destination, driver_name, vehicle_name,
-            amount, rating, feedback
+            amount, COALESCE(rating, 0), COALESCE(feedback, ''), ` + tripStopsColumn + `
         FROM trips
-        WHERE id = $1
+        WHERE id = $1 AND tenant_id = $2
     `
 
-	row := r.DB.QueryRow(query, tripID)
+	row := r.DB.QueryRowContext(ctx, query, tripID, tenantID)
 
-	err := row.Scan(
+	err = row.Scan(
 		&trip.ID,
+		&trip.BookingCode,
 		&trip.CustomerName,
 		&trip.BookingDate,
 		&trip.DurationMinutes,
@@ -44,6 +53,7 @@ func (r *PDFRepository) GetTripByID(tripID string) (model.Pdf, error) {
 		&trip.Amount,
 		&trip.Rating,
 		&trip.Feedback,
+		pq.Array(&trip.Stops),
 	)
 
 	if err != nil {
@@ -20,18 +20,19 @@ func TestPDFRepository_GetTripByID_Success(t *testing.T) {
 
 	tripID := "123"
 	bookingDate := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "customer_name", "booking_date", "duration_minutes", "distance_km", "pickup_location", "destination", "driver_name", "vehicle_name", "amount", "rating", "feedback"}).
-		AddRow("123", "John Doe", bookingDate, 60, 50, "Location A", "Location B", "Driver X", "Car Y", 100, 4.5, "Good trip")
+	rows := sqlmock.NewRows([]string{"id", "booking_id", "customer_name", "booking_date", "duration_minutes", "distance_km", "pickup_location", "destination", "driver_name", "vehicle_name", "amount", "rating", "feedback", "stops"}).
+		AddRow("123", "BK00000ZB", "John Doe", bookingDate, 60, 50, "Location A", "Location B", "Driver X", "Car Y", 100, 4.5, "Good trip", "{Jl. Braga}")
 
-	mock.ExpectQuery(`SELECT id, customer_name, booking_date, duration_minutes, distance_km, pickup_location, destination, driver_name, vehicle_name, amount, rating, feedback FROM trips WHERE id = \$1`).
-		WithArgs(tripID).
+	mock.ExpectQuery(`SELECT id, COALESCE\(booking_id, ''\), customer_name, booking_date, duration_minutes, distance_km, pickup_location, destination, driver_name, vehicle_name, amount, COALESCE\(rating, 0\), COALESCE\(feedback, ''\), ARRAY\(SELECT s.address FROM booking_stops s WHERE s.booking_id = trips.booking_id ORDER BY s.position\) FROM trips WHERE id = \$1`).
+		WithArgs(tripID, int64(1)).
 		WillReturnRows(rows)
 
-	trip, err := repo.GetTripByID(tripID)
+	trip, err := repo.GetTripByID(tenantCtx(), tripID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, trip)
 	assert.Equal(t, "123", trip.ID)
+	assert.Equal(t, "BK00000ZB", trip.BookingCode)
 	assert.Equal(t, "John Doe", trip.CustomerName)
 	assert.Equal(t, bookingDate, trip.BookingDate)
 	assert.Equal(t, 60, trip.DurationMinutes)
@@ -40,9 +41,10 @@ func TestPDFRepository_GetTripByID_Success(t *testing.T) {
 	assert.Equal(t, "Location B", trip.Destination)
 	assert.Equal(t, "Driver X", trip.DriverName)
 	assert.Equal(t, "Car Y", trip.VehicleName)
-	assert.Equal(t, 100, trip.Amount)
+	assert.Equal(t, 100.0, trip.Amount)
 	assert.Equal(t, float32(4.5), trip.Rating)
 	assert.Equal(t, "Good trip", trip.Feedback)
+	assert.Equal(t, []string{"Jl. Braga"}, trip.Stops)
 
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
@@ -57,11 +59,11 @@ func TestPDFRepository_GetTripByID_NotFound(t *testing.T) {
 
 	tripID := "123"
 
-	mock.ExpectQuery(`SELECT id, customer_name, booking_date, duration_minutes, distance_km, pickup_location, destination, driver_name, vehicle_name, amount, rating, feedback FROM trips WHERE id = \$1`).
-		WithArgs(tripID).
+	mock.ExpectQuery(`SELECT id, COALESCE\(booking_id, ''\), customer_name, booking_date, duration_minutes, distance_km, pickup_location, destination, driver_name, vehicle_name, amount, COALESCE\(rating, 0\), COALESCE\(feedback, ''\), ARRAY\(SELECT s.address FROM booking_stops s WHERE s.booking_id = trips.booking_id ORDER BY s.position\) FROM trips WHERE id = \$1`).
+		WithArgs(tripID, int64(1)).
 		WillReturnError(sql.ErrNoRows)
 
-	trip, err := repo.GetTripByID(tripID)
+	trip, err := repo.GetTripByID(tenantCtx(), tripID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, model.Pdf{}, trip)
@@ -79,11 +81,11 @@ func TestPDFRepository_GetTripByID_Error(t *testing.T) {
 
 	tripID := "123"
 
-	mock.ExpectQuery(`SELECT id, customer_name, booking_date, duration_minutes, distance_km, pickup_location, destination, driver_name, vehicle_name, amount, rating, feedback FROM trips WHERE id = \$1`).
-		WithArgs(tripID).
+	mock.ExpectQuery(`SELECT id, COALESCE\(booking_id, ''\), customer_name, booking_date, duration_minutes, distance_km, pickup_location, destination, driver_name, vehicle_name, amount, COALESCE\(rating, 0\), COALESCE\(feedback, ''\), ARRAY\(SELECT s.address FROM booking_stops s WHERE s.booking_id = trips.booking_id ORDER BY s.position\) FROM trips WHERE id = \$1`).
+		WithArgs(tripID, int64(1)).
 		WillReturnError(sql.ErrConnDone)
 
-	trip, err := repo.GetTripByID(tripID)
+	trip, err := repo.GetTripByID(tenantCtx(), tripID)
 
 	assert.Error(t, err)
 	assert.Equal(t, model.Pdf{}, trip)
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type PricingRepositoryInterface interface {
+	GetTariffs(ctx context.Context) ([]model.Tariff, error)
+	GetTariff(ctx context.Context, carTypeID string) (*model.Tariff, error)
+	SaveTariff(ctx context.Context, t *model.Tariff) error
+	CreateQuote(ctx context.Context, q *model.Quote) error
+	GetQuote(ctx context.Context, id int) (*model.Quote, error)
+}
+
+type PricingRepository struct {
+	DB *sql.DB
+}
+
+func NewPricingRepository(db *sql.DB) *PricingRepository {
+	return &PricingRepository{DB: db}
+}
+
+const tariffColumns = `car_type_id, base_fare, per_km, per_minute, minimum_fare, night_surcharge_pct, night_start_hour, night_end_hour, airport_surcharge, per_stop, updated_at`
+
+func (r *PricingRepository) GetTariffs(ctx context.Context) ([]model.Tariff, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+tariffColumns+` FROM tariffs WHERE tenant_id = $1 ORDER BY car_type_id`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tariffs := []model.Tariff{}
+	for rows.Next() {
+		var t model.Tariff
+		if err := rows.Scan(&t.CarTypeID, &t.BaseFare, &t.PerKM, &t.PerMinute, &t.MinimumFare,
+			&t.NightSurchargePct, &t.NightStartHour, &t.NightEndHour, &t.AirportSurcharge, &t.PerStop, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		tariffs = append(tariffs, t)
+	}
+	return tariffs, rows.Err()
+}
+
+func (r *PricingRepository) GetTariff(ctx context.Context, carTypeID string) (*model.Tariff, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var t model.Tariff
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT `+tariffColumns+` FROM tariffs WHERE car_type_id = $1 AND tenant_id = $2`,
+		carTypeID, tenantID,
+	).Scan(&t.CarTypeID, &t.BaseFare, &t.PerKM, &t.PerMinute, &t.MinimumFare,
+		&t.NightSurchargePct, &t.NightStartHour, &t.NightEndHour, &t.AirportSurcharge, &t.PerStop, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// SaveTariff creates the tariff of t.CarTypeID or replaces it.
+func (r *PricingRepository) SaveTariff(ctx context.Context, t *model.Tariff) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.UpdatedAt = time.Now()
+
+	_, err = r.DB.ExecContext(ctx, `
+        INSERT INTO tariffs (`+tariffColumns+`, tenant_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        ON CONFLICT (tenant_id, car_type_id) DO UPDATE SET
+            base_fare = EXCLUDED.base_fare,
+            per_km = EXCLUDED.per_km,
+            per_minute = EXCLUDED.per_minute,
+            minimum_fare = EXCLUDED.minimum_fare,
+            night_surcharge_pct = EXCLUDED.night_surcharge_pct,
+            night_start_hour = EXCLUDED.night_start_hour,
+            night_end_hour = EXCLUDED.night_end_hour,
+            airport_surcharge = EXCLUDED.airport_surcharge,
+            per_stop = EXCLUDED.per_stop,
+            updated_at = EXCLUDED.updated_at
+    `,
+		t.CarTypeID, t.BaseFare, t.PerKM, t.PerMinute, t.MinimumFare,
+		t.NightSurchargePct, t.NightStartHour, t.NightEndHour, t.AirportSurcharge, t.PerStop, t.UpdatedAt, tenantID,
+	)
+	return err
+}
+
+func (r *PricingRepository) CreateQuote(ctx context.Context, q *model.Quote) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	items, err := json.Marshal(q.Items)
+	if err != nil {
+		return err
+	}
+	legs, err := json.Marshal(append([]model.Route{}, q.Legs...))
+	if err != nil {
+		return err
+	}
+	stops, err := json.Marshal(append([]model.QuoteStop{}, q.QuotedStops...))
+	if err != nil {
+		return err
+	}
+
+	q.CreatedAt = time.Now()
+
+	return r.DB.QueryRowContext(ctx,
+		`INSERT INTO quotes (car_type_id, distance_km, duration_minutes, pickup_at, airport, stops, wait_minutes, quoted_stops, legs, items, promo_code, discount, total, expires_at, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id`,
+		q.CarTypeID, q.DistanceKM, q.DurationMinutes, q.PickupAt, q.Airport, q.Stops, q.WaitMinutes, stops, legs, items, q.PromoCode, q.Discount, q.Total, q.ExpiresAt, q.CreatedAt, tenantID,
+	).Scan(&q.ID)
+}
+
+func (r *PricingRepository) GetQuote(ctx context.Context, id int) (*model.Quote, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var q model.Quote
+	var items, legs, stops []byte
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT id, car_type_id, distance_km, duration_minutes, pickup_at, airport, stops, wait_minutes, quoted_stops, legs, items, promo_code, discount, total, expires_at, created_at FROM quotes WHERE id = $1 AND tenant_id = $2`,
+		id, tenantID,
+	).Scan(&q.ID, &q.CarTypeID, &q.DistanceKM, &q.DurationMinutes, &q.PickupAt, &q.Airport, &q.Stops, &q.WaitMinutes, &stops, &legs, &items, &q.PromoCode, &q.Discount, &q.Total, &q.ExpiresAt, &q.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(items, &q.Items); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(legs, &q.Legs); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(stops, &q.QuotedStops); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
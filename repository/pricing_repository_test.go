@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var tariffColumns = []string{"car_type_id", "base_fare", "per_km", "per_minute", "minimum_fare", "night_surcharge_pct", "night_start_hour", "night_end_hour", "airport_surcharge", "per_stop", "updated_at"}
+
+func TestPricingRepository_Tariffs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPricingRepository(db)
+
+	mock.ExpectQuery(`FROM tariffs WHERE tenant_id = \$1 ORDER BY car_type_id`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(tariffColumns).
+			AddRow("mpv", 20000, 4000, 500, 50000, 25, 22, 5, 35000, 15000, time.Now()))
+	tariffs, err := repo.GetTariffs(tenantCtx())
+	assert.NoError(t, err)
+	assert.Len(t, tariffs, 1)
+	assert.Equal(t, 25.0, tariffs[0].NightSurchargePct)
+	assert.Equal(t, 15000.0, tariffs[0].PerStop)
+
+	mock.ExpectQuery(`FROM tariffs WHERE car_type_id = \$1 AND tenant_id = \$2`).
+		WithArgs("sedan", int64(1)).
+		WillReturnRows(sqlmock.NewRows(tariffColumns))
+	missing, err := repo.GetTariff(tenantCtx(), "sedan")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	tariff := &model.Tariff{CarTypeID: "mpv", BaseFare: 20000, PerKM: 4500, NightStartHour: 22, NightEndHour: 5}
+	mock.ExpectExec(`INSERT INTO tariffs .* ON CONFLICT \(tenant_id, car_type_id\) DO UPDATE`).
+		WithArgs("mpv", 20000.0, 4500.0, 0.0, 0.0, 0.0, 22, 5, 0.0, 0.0, sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SaveTariff(tenantCtx(), tariff))
+	assert.False(t, tariff.UpdatedAt.IsZero())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPricingRepository_Quotes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPricingRepository(db)
+	pickup := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	expires := time.Now().Add(time.Minute)
+	quote := &model.Quote{CarTypeID: "mpv", DistanceKM: 10, PickupAt: pickup, Total: 65000, ExpiresAt: expires, Stops: 1, WaitMinutes: 5,
+		QuotedStops: []model.QuoteStop{{Point: &model.GeoPoint{Lat: -6.9, Lng: 107.6}, WaitMinutes: 5}},
+		Legs:        []model.Route{{DistanceKM: 4, DurationMinutes: 6}, {DistanceKM: 6, DurationMinutes: 9}},
+		Items:       []model.QuoteItem{{Code: model.QuoteItemBaseFare, Description: "Base fare", Amount: 65000}}}
+	legs := []byte(`[{"distance_km":4,"duration_minutes":6},{"distance_km":6,"duration_minutes":9}]`)
+	stops := []byte(`[{"point":{"lat":-6.9,"lng":107.6},"wait_minutes":5}]`)
+
+	mock.ExpectQuery(`INSERT INTO quotes`).
+		WithArgs("mpv", 10.0, 0.0, pickup, false, 1, 5, stops, legs, []byte(`[{"code":"base_fare","description":"Base fare","amount":65000}]`), nil, 0.0, 65000.0, expires, sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+	assert.NoError(t, repo.CreateQuote(tenantCtx(), quote))
+	assert.Equal(t, 8, quote.ID)
+
+	mock.ExpectQuery(`FROM quotes WHERE id = \$1 AND tenant_id = \$2`).
+		WithArgs(8, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "car_type_id", "distance_km", "duration_minutes", "pickup_at", "airport", "stops", "wait_minutes", "quoted_stops", "legs", "items", "promo_code", "discount", "total", "expires_at", "created_at"}).
+			AddRow(8, "mpv", 10.0, 0.0, pickup, false, 1, 5, stops, legs, []byte(`[{"code":"base_fare","description":"Base fare","amount":65000}]`), nil, 0.0, 65000.0, expires, time.Now()))
+	found, err := repo.GetQuote(tenantCtx(), 8)
+	assert.NoError(t, err)
+	assert.Equal(t, quote.Items, found.Items)
+	assert.Equal(t, quote.Legs, found.Legs)
+	assert.Equal(t, quote.QuotedStops, found.QuotedStops)
+
+	mock.ExpectQuery(`FROM quotes WHERE id = \$1 AND tenant_id = \$2`).
+		WithArgs(9, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	missing, err := repo.GetQuote(tenantCtx(), 9)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Create_QuoteRedeemed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+	quoteID := 8
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT nextval`).WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO booking`).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_booking_quote"})
+	mock.ExpectRollback()
+
+	err = repo.Create(tenantCtx(), &model.Booking{Status: "pending", QuoteID: &quoteID})
+	assert.ErrorIs(t, err, repository.ErrQuoteRedeemed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
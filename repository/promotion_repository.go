@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUnknownPromotion   = errors.New("promo code not found")
+	ErrPromotionExhausted = errors.New("promo code has reached its usage limit")
+)
+
+type PromotionRepositoryInterface interface {
+	GetAll(ctx context.Context) ([]model.Promotion, error)
+	GetByID(ctx context.Context, id int) (*model.Promotion, error)
+	GetByCode(ctx context.Context, code string) (*model.Promotion, error)
+	Create(ctx context.Context, p *model.Promotion) error
+	Update(ctx context.Context, p *model.Promotion) error
+	Usage(ctx context.Context, promotionID int, customerID *int) (total int, customer int, err error)
+	GetRedemptions(ctx context.Context, promotionID int) ([]model.PromotionRedemption, error)
+	Report(ctx context.Context, from, to time.Time) ([]model.PromotionReport, error)
+}
+
+type PromotionRepository struct {
+	DB *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) *PromotionRepository {
+	return &PromotionRepository{DB: db}
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func normalizePromotion(p *model.Promotion) {
+	p.Code = normalizePromoCode(p.Code)
+	if p.CarTypeIDs == nil {
+		p.CarTypeIDs = []string{}
+	}
+}
+
+const promotionColumns = `id, code, description, discount_type, discount_value, max_discount, minimum_fare, car_type_ids, valid_from, valid_until, max_redemptions, max_per_customer, active, created_at, updated_at, version`
+
+func (r *PromotionRepository) GetAll(ctx context.Context) ([]model.Promotion, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+promotionColumns+` FROM promotions WHERE tenant_id = $1 ORDER BY created_at DESC, id DESC`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := []model.Promotion{}
+	for rows.Next() {
+		var p model.Promotion
+		if err := rows.Scan(&p.ID, &p.Code, &p.Description, &p.DiscountType, &p.DiscountValue, &p.MaxDiscount, &p.MinimumFare,
+			pq.Array(&p.CarTypeIDs), &p.ValidFrom, &p.ValidUntil, &p.MaxRedemptions, &p.MaxPerCustomer, &p.Active,
+			&p.CreatedAt, &p.UpdatedAt, &p.Version); err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+	return promotions, rows.Err()
+}
+
+func (r *PromotionRepository) get(ctx context.Context, where string, arg any) (*model.Promotion, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var p model.Promotion
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT `+promotionColumns+` FROM promotions WHERE `+where+` = $1 AND tenant_id = $2`,
+		arg, tenantID,
+	).Scan(&p.ID, &p.Code, &p.Description, &p.DiscountType, &p.DiscountValue, &p.MaxDiscount, &p.MinimumFare,
+		pq.Array(&p.CarTypeIDs), &p.ValidFrom, &p.ValidUntil, &p.MaxRedemptions, &p.MaxPerCustomer, &p.Active,
+		&p.CreatedAt, &p.UpdatedAt, &p.Version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (r *PromotionRepository) GetByID(ctx context.Context, id int) (*model.Promotion, error) {
+	return r.get(ctx, "id", id)
+}
+
+func (r *PromotionRepository) GetByCode(ctx context.Context, code string) (*model.Promotion, error) {
+	return r.get(ctx, "code", normalizePromoCode(code))
+}
+
+func (r *PromotionRepository) Create(ctx context.Context, p *model.Promotion) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizePromotion(p)
+
+	err = r.DB.QueryRowContext(ctx,
+		`INSERT INTO promotions (code, description, discount_type, discount_value, max_discount, minimum_fare, car_type_ids, valid_from, valid_until, max_redemptions, max_per_customer, active, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
+		RETURNING id, created_at, updated_at`,
+		p.Code, p.Description, p.DiscountType, p.DiscountValue, p.MaxDiscount, p.MinimumFare, pq.Array(p.CarTypeIDs),
+		p.ValidFrom, p.ValidUntil, p.MaxRedemptions, p.MaxPerCustomer, p.Active, tenantID,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicateKey
+	}
+	if err != nil {
+		return err
+	}
+
+	p.Version = 1
+	return nil
+}
+
+// Update edits the terms of a promotion. The code is fixed once created since
+// bookings record it.
+func (r *PromotionRepository) Update(ctx context.Context, p *model.Promotion) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizePromotion(p)
+	p.UpdatedAt = time.Now()
+
+	err = versionedResult(r.DB.ExecContext(ctx,
+		`UPDATE promotions SET description = $1, discount_type = $2, discount_value = $3, max_discount = $4, minimum_fare = $5, car_type_ids = $6,
+		valid_from = $7, valid_until = $8, max_redemptions = $9, max_per_customer = $10, active = $11, updated_at = $12, version = version + 1
+		WHERE id = $13 AND version = $14 AND tenant_id = $15`,
+		p.Description, p.DiscountType, p.DiscountValue, p.MaxDiscount, p.MinimumFare, pq.Array(p.CarTypeIDs),
+		p.ValidFrom, p.ValidUntil, p.MaxRedemptions, p.MaxPerCustomer, p.Active, p.UpdatedAt,
+		p.ID, p.Version, tenantID,
+	))
+	if err != nil {
+		return err
+	}
+
+	p.Version++
+	return nil
+}
+
+// Usage counts the redemptions of a promotion overall and by one customer.
+func (r *PromotionRepository) Usage(ctx context.Context, promotionID int, customerID *int) (int, int, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total, customer int
+	err = r.DB.QueryRowContext(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE customer_id = $2) FROM promotion_redemptions WHERE promotion_id = $1 AND tenant_id = $3`,
+		promotionID, customerID, tenantID,
+	).Scan(&total, &customer)
+	return total, customer, err
+}
+
+func (r *PromotionRepository) GetRedemptions(ctx context.Context, promotionID int) ([]model.PromotionRedemption, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT id, promotion_id, booking_id, customer_id, discount, redeemed_at FROM promotion_redemptions WHERE promotion_id = $1 AND tenant_id = $2 ORDER BY redeemed_at DESC, id DESC`,
+		promotionID, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redemptions := []model.PromotionRedemption{}
+	for rows.Next() {
+		var rd model.PromotionRedemption
+		if err := rows.Scan(&rd.ID, &rd.PromotionID, &rd.BookingID, &rd.CustomerID, &rd.Discount, &rd.RedeemedAt); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, rd)
+	}
+	return redemptions, rows.Err()
+}
+
+// Report sums redemptions made within [from, to) per promotion, leaving out
+// promotions that were not used in that period.
+func (r *PromotionRepository) Report(ctx context.Context, from, to time.Time) ([]model.PromotionReport, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT p.id, p.code, COUNT(*), COUNT(DISTINCT rd.customer_id), COALESCE(SUM(rd.discount), 0), COALESCE(SUM(b.amount), 0)
+        FROM promotion_redemptions rd
+        JOIN promotions p ON p.id = rd.promotion_id
+        JOIN booking b ON b.id = rd.booking_id
+        WHERE rd.tenant_id = $1 AND rd.redeemed_at >= $2 AND rd.redeemed_at < $3
+        GROUP BY p.id, p.code
+        ORDER BY COUNT(*) DESC, p.code
+    `, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := []model.PromotionReport{}
+	for rows.Next() {
+		var pr model.PromotionReport
+		if err := rows.Scan(&pr.PromotionID, &pr.Code, &pr.Redemptions, &pr.Customers, &pr.TotalDiscount, &pr.NetRevenue); err != nil {
+			return nil, err
+		}
+		report = append(report, pr)
+	}
+	return report, rows.Err()
+}
+
+// redeemPromotion records a booking's use of its promo code inside the
+// booking's insert transaction. The promotion row is locked first so that
+// concurrent bookings cannot overrun its usage limits.
+func redeemPromotion(ctx context.Context, tx *sql.Tx, tenantID int64, b *model.Booking) error {
+	var id int
+	var maxTotal, maxPerCustomer *int
+	err := tx.QueryRowContext(ctx,
+		`SELECT id, max_redemptions, max_per_customer FROM promotions WHERE code = $1 AND tenant_id = $2 FOR UPDATE`,
+		normalizePromoCode(*b.PromoCode), tenantID,
+	).Scan(&id, &maxTotal, &maxPerCustomer)
+	if err == sql.ErrNoRows {
+		return ErrUnknownPromotion
+	}
+	if err != nil {
+		return err
+	}
+
+	var total, customer int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE customer_id = $2) FROM promotion_redemptions WHERE promotion_id = $1`,
+		id, b.CustomerID,
+	).Scan(&total, &customer)
+	if err != nil {
+		return err
+	}
+	if maxTotal != nil && total >= *maxTotal {
+		return ErrPromotionExhausted
+	}
+	if maxPerCustomer != nil && b.CustomerID != nil && customer >= *maxPerCustomer {
+		return ErrPromotionExhausted
+	}
+
+	var discount float64
+	if b.Discount != nil {
+		discount = *b.Discount
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO promotion_redemptions (promotion_id, booking_id, customer_id, discount, redeemed_at, tenant_id) VALUES ($1, $2, $3, $4, $5, $6)`,
+		id, b.ID, b.CustomerID, discount, b.CreatedAt, tenantID,
+	)
+	return err
+}
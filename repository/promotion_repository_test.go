@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var promotionColumns = []string{"id", "code", "description", "discount_type", "discount_value", "max_discount", "minimum_fare", "car_type_ids", "valid_from", "valid_until", "max_redemptions", "max_per_customer", "active", "created_at", "updated_at", "version"}
+
+func TestPromotionRepository_CRUD(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewPromotionRepository(db)
+
+	promotion := &model.Promotion{Code: " hemat10 ", DiscountType: model.DiscountPercent, DiscountValue: 10, Active: true}
+	mock.ExpectQuery(`INSERT INTO promotions`).
+		WithArgs("HEMAT10", "", model.DiscountPercent, 10.0, nil, 0.0, sqlmock.AnyArg(), nil, nil, nil, nil, true, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(4, time.Now(), time.Now()))
+	assert.NoError(t, repo.Create(tenantCtx(), promotion))
+	assert.Equal(t, 4, promotion.ID)
+	assert.Equal(t, "HEMAT10", promotion.Code)
+	assert.Equal(t, 1, promotion.Version)
+
+	mock.ExpectQuery(`INSERT INTO promotions`).
+		WillReturnError(&pq.Error{Code: "23505"})
+	assert.ErrorIs(t, repo.Create(tenantCtx(), &model.Promotion{Code: "HEMAT10"}), repository.ErrDuplicateKey)
+
+	mock.ExpectQuery(`FROM promotions WHERE code = \$1 AND tenant_id = \$2`).
+		WithArgs("HEMAT10", int64(1)).
+		WillReturnRows(sqlmock.NewRows(promotionColumns).
+			AddRow(4, "HEMAT10", "", "percent", 10.0, nil, 0.0, "{mpv,sedan}", nil, nil, 100, nil, true, time.Now(), time.Now(), 1))
+	found, err := repo.GetByCode(tenantCtx(), "hemat10")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mpv", "sedan"}, found.CarTypeIDs)
+	assert.Equal(t, 100, *found.MaxRedemptions)
+
+	mock.ExpectQuery(`FROM promotions WHERE id = \$1 AND tenant_id = \$2`).
+		WithArgs(5, int64(1)).
+		WillReturnRows(sqlmock.NewRows(promotionColumns))
+	missing, err := repo.GetByID(tenantCtx(), 5)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	mock.ExpectExec(`UPDATE promotions SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Update(tenantCtx(), &model.Promotion{ID: 4, Version: 3}), repository.ErrVersionConflict)
+
+	mock.ExpectQuery(`FROM promotion_redemptions WHERE promotion_id = \$1 AND tenant_id = \$3`).
+		WithArgs(4, nil, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "customer"}).AddRow(7, 0))
+	total, customer, err := repo.Usage(tenantCtx(), 4, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, total)
+	assert.Equal(t, 0, customer)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Create_RedeemsPromotion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.BookingRepository{DB: db}
+	code := "hemat10"
+	discount := 5000.0
+	limit := 3
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT nextval`).WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(7))
+	mock.ExpectExec(`INSERT INTO booking`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM promotions WHERE code = \$1 AND tenant_id = \$2 FOR UPDATE`).
+		WithArgs("HEMAT10", int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "max_redemptions", "max_per_customer"}).AddRow(4, limit, nil))
+	mock.ExpectQuery(`FROM promotion_redemptions WHERE promotion_id = \$1`).
+		WithArgs(4, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "customer"}).AddRow(1, 0))
+	mock.ExpectExec(`INSERT INTO promotion_redemptions`).
+		WithArgs(4, sqlmock.AnyArg(), nil, discount, sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.Create(tenantCtx(), &model.Booking{Status: "pending", PromoCode: &code, Discount: &discount}))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT nextval`).WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(7))
+	mock.ExpectExec(`INSERT INTO booking`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "max_redemptions", "max_per_customer"}).AddRow(4, limit, nil))
+	mock.ExpectQuery(`FROM promotion_redemptions`).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "customer"}).AddRow(3, 0))
+	mock.ExpectRollback()
+	err = repo.Create(tenantCtx(), &model.Booking{Status: "pending", PromoCode: &code, Discount: &discount})
+	assert.ErrorIs(t, err, repository.ErrPromotionExhausted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"auth-service/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type RecurringBookingRepositoryInterface interface {
+	GetAll(ctx context.Context) ([]model.RecurringBooking, error)
+	GetByID(ctx context.Context, id int) (*model.RecurringBooking, error)
+	Create(ctx context.Context, r *model.RecurringBooking) error
+	Update(ctx context.Context, r *model.RecurringBooking) error
+	GetActive(ctx context.Context, day string) ([]model.RecurringBooking, error)
+	GetOccurrences(ctx context.Context, recurringID int) ([]model.RecurringOccurrence, error)
+	ClaimOccurrence(ctx context.Context, o *model.RecurringOccurrence) error
+	SaveOccurrence(ctx context.Context, o *model.RecurringOccurrence) error
+}
+
+type RecurringBookingRepository struct {
+	DB *sql.DB
+}
+
+func NewRecurringBookingRepository(db *sql.DB) *RecurringBookingRepository {
+	return &RecurringBookingRepository{DB: db}
+}
+
+func normalizeRecurring(r *model.RecurringBooking) {
+	if r.Weekdays == nil {
+		r.Weekdays = []string{}
+	}
+	if r.Exceptions == nil {
+		r.Exceptions = []string{}
+	}
+}
+
+const recurringColumns = `id, customer_id, customer, phone_number, place, pickup_location, pickup_lat, pickup_lng, drop_location, car_type_id, driver_id, vehicle_id, amount, payment, notes,
+	frequency, repeat_interval, weekdays, start_at, duration_minutes, until, exceptions, days_ahead, created_at, updated_at, version, tenant_id`
+
+// query reads recurringColumns. DATE columns come back as timestamps and are
+// turned back into calendar days.
+func (r *RecurringBookingRepository) query(ctx context.Context, where string, args ...any) ([]model.RecurringBooking, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+recurringColumns+` FROM recurring_bookings WHERE `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := []model.RecurringBooking{}
+	for rows.Next() {
+		var s model.RecurringBooking
+		var until *time.Time
+		if err := rows.Scan(&s.ID, &s.CustomerID, &s.Customer, &s.PhoneNumber, &s.Place, &s.PickupLocation, &s.PickupLat, &s.PickupLng, &s.DropLocation,
+			&s.CarTypeID, &s.DriverID, &s.VehicleID, &s.Amount, &s.Payment, &s.Notes,
+			&s.Frequency, &s.Interval, pq.Array(&s.Weekdays), &s.StartAt, &s.DurationMinutes, &until, pq.Array(&s.Exceptions), &s.DaysAhead,
+			&s.CreatedAt, &s.UpdatedAt, &s.Version, &s.TenantID); err != nil {
+			return nil, err
+		}
+		if until != nil {
+			day := until.Format(model.DateLayout)
+			s.Until = &day
+		}
+		normalizeRecurring(&s)
+		series = append(series, s)
+	}
+	return series, rows.Err()
+}
+
+func (r *RecurringBookingRepository) GetAll(ctx context.Context) ([]model.RecurringBooking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx, `tenant_id = $1`, tenantID)
+}
+
+// GetActive is run by the recurring scheduler and spans every tenant. It
+// returns the series that have not ended before day.
+func (r *RecurringBookingRepository) GetActive(ctx context.Context, day string) ([]model.RecurringBooking, error) {
+	return r.query(ctx, `until IS NULL OR until >= $1`, day)
+}
+
+func (r *RecurringBookingRepository) GetByID(ctx context.Context, id int) (*model.RecurringBooking, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := r.query(ctx, `id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil || len(series) == 0 {
+		return nil, err
+	}
+	return &series[0], nil
+}
+
+func (r *RecurringBookingRepository) Create(ctx context.Context, s *model.RecurringBooking) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeRecurring(s)
+
+	err = r.DB.QueryRowContext(ctx,
+		`INSERT INTO recurring_bookings (customer_id, customer, phone_number, place, pickup_location, pickup_lat, pickup_lng, drop_location, car_type_id, driver_id, vehicle_id, amount, payment, notes,
+		frequency, repeat_interval, weekdays, start_at, duration_minutes, until, exceptions, days_ahead, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, NOW(), NOW())
+		RETURNING id, created_at, updated_at`,
+		s.CustomerID, s.Customer, s.PhoneNumber, s.Place, s.PickupLocation, s.PickupLat, s.PickupLng, s.DropLocation, s.CarTypeID, s.DriverID, s.VehicleID, s.Amount, s.Payment, s.Notes,
+		s.Frequency, s.Interval, pq.Array(s.Weekdays), s.StartAt, s.DurationMinutes, s.Until, pq.Array(s.Exceptions), s.DaysAhead, tenantID,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	s.Version = 1
+	s.TenantID = tenantID
+	return nil
+}
+
+func (r *RecurringBookingRepository) Update(ctx context.Context, s *model.RecurringBooking) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	normalizeRecurring(s)
+	s.UpdatedAt = time.Now()
+
+	err = versionedResult(r.DB.ExecContext(ctx,
+		`UPDATE recurring_bookings SET customer_id = $1, customer = $2, phone_number = $3, place = $4, pickup_location = $5, pickup_lat = $6, pickup_lng = $7, drop_location = $8,
+		car_type_id = $9, driver_id = $10, vehicle_id = $11, amount = $12, payment = $13, notes = $14, frequency = $15, repeat_interval = $16, weekdays = $17, start_at = $18,
+		duration_minutes = $19, until = $20, exceptions = $21, days_ahead = $22, updated_at = $23, version = version + 1
+		WHERE id = $24 AND version = $25 AND tenant_id = $26`,
+		s.CustomerID, s.Customer, s.PhoneNumber, s.Place, s.PickupLocation, s.PickupLat, s.PickupLng, s.DropLocation,
+		s.CarTypeID, s.DriverID, s.VehicleID, s.Amount, s.Payment, s.Notes, s.Frequency, s.Interval, pq.Array(s.Weekdays), s.StartAt,
+		s.DurationMinutes, s.Until, pq.Array(s.Exceptions), s.DaysAhead, s.UpdatedAt,
+		s.ID, s.Version, tenantID,
+	))
+	if err != nil {
+		return err
+	}
+
+	s.Version++
+	s.TenantID = tenantID
+	return nil
+}
+
+func (r *RecurringBookingRepository) GetOccurrences(ctx context.Context, recurringID int) ([]model.RecurringOccurrence, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT id, recurring_id, occurrence_date, booking_id, detached, error, created_at FROM recurring_occurrences WHERE recurring_id = $1 AND tenant_id = $2 ORDER BY occurrence_date`,
+		recurringID, tenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	occurrences := []model.RecurringOccurrence{}
+	for rows.Next() {
+		var o model.RecurringOccurrence
+		var date time.Time
+		if err := rows.Scan(&o.ID, &o.RecurringID, &date, &o.BookingID, &o.Detached, &o.Error, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		o.Date = date.Format(model.DateLayout)
+		occurrences = append(occurrences, o)
+	}
+	return occurrences, rows.Err()
+}
+
+// ClaimOccurrence reserves one day of a series before its booking is created,
+// so two schedulers never book the same day twice. A day that is already
+// claimed returns ErrDuplicateKey.
+func (r *RecurringBookingRepository) ClaimOccurrence(ctx context.Context, o *model.RecurringOccurrence) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = r.DB.QueryRowContext(ctx,
+		`INSERT INTO recurring_occurrences (recurring_id, occurrence_date, tenant_id, created_at) VALUES ($1, $2, $3, NOW()) RETURNING id, created_at`,
+		o.RecurringID, o.Date, tenantID,
+	).Scan(&o.ID, &o.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+func (r *RecurringBookingRepository) SaveOccurrence(ctx context.Context, o *model.RecurringOccurrence) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return restoredResult(r.DB.ExecContext(ctx,
+		`UPDATE recurring_occurrences SET booking_id = $1, detached = $2, error = $3 WHERE id = $4 AND tenant_id = $5`,
+		o.BookingID, o.Detached, o.Error, o.ID, tenantID,
+	))
+}
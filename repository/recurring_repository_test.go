@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var recurringColumns = []string{"id", "customer_id", "customer", "phone_number", "place", "pickup_location", "pickup_lat", "pickup_lng", "drop_location", "car_type_id", "driver_id", "vehicle_id", "amount", "payment", "notes",
+	"frequency", "repeat_interval", "weekdays", "start_at", "duration_minutes", "until", "exceptions", "days_ahead", "created_at", "updated_at", "version", "tenant_id"}
+
+func TestRecurringBookingRepository_Series(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRecurringBookingRepository(db)
+	start := time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC)
+
+	series := &model.RecurringBooking{Customer: "PT Maju", Frequency: model.RecurWeekly, Interval: 1, StartAt: start, DurationMinutes: 60}
+	mock.ExpectQuery(`INSERT INTO recurring_bookings`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(3, time.Now(), time.Now()))
+	assert.NoError(t, repo.Create(tenantCtx(), series))
+	assert.Equal(t, 3, series.ID)
+	assert.Equal(t, 1, series.Version)
+	assert.Equal(t, []string{}, series.Weekdays)
+
+	until := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM recurring_bookings WHERE id = \$1 AND tenant_id = \$2`).
+		WithArgs(3, int64(1)).
+		WillReturnRows(sqlmock.NewRows(recurringColumns).
+			AddRow(3, nil, "PT Maju", nil, "", nil, nil, nil, nil, nil, nil, nil, nil, "", nil,
+				"weekly", 1, "{MO,WE}", start, 60, until, "{2024-05-13}", 0, time.Now(), time.Now(), 2, 1))
+	found, err := repo.GetByID(tenantCtx(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-06-30", *found.Until)
+	assert.Equal(t, []string{"MO", "WE"}, found.Weekdays)
+	assert.Equal(t, []string{"2024-05-13"}, found.Exceptions)
+
+	mock.ExpectQuery(`FROM recurring_bookings WHERE id = \$1 AND tenant_id = \$2`).
+		WithArgs(4, int64(1)).
+		WillReturnRows(sqlmock.NewRows(recurringColumns))
+	missing, err := repo.GetByID(tenantCtx(), 4)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	mock.ExpectExec(`UPDATE recurring_bookings SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Update(tenantCtx(), &model.RecurringBooking{ID: 3, Version: 1}), repository.ErrVersionConflict)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecurringBookingRepository_Occurrences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRecurringBookingRepository(db)
+
+	occurrence := &model.RecurringOccurrence{RecurringID: 3, Date: "2024-05-06"}
+	mock.ExpectQuery(`INSERT INTO recurring_occurrences`).
+		WithArgs(3, "2024-05-06", int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(9, time.Now()))
+	assert.NoError(t, repo.ClaimOccurrence(tenantCtx(), occurrence))
+	assert.Equal(t, 9, occurrence.ID)
+
+	mock.ExpectQuery(`INSERT INTO recurring_occurrences`).
+		WillReturnError(&pq.Error{Code: "23505"})
+	assert.ErrorIs(t, repo.ClaimOccurrence(tenantCtx(), &model.RecurringOccurrence{RecurringID: 3, Date: "2024-05-06"}), repository.ErrDuplicateKey)
+
+	bookingID := "BKR3-20240506"
+	occurrence.BookingID = &bookingID
+	mock.ExpectExec(`UPDATE recurring_occurrences SET booking_id = \$1, detached = \$2, error = \$3 WHERE id = \$4 AND tenant_id = \$5`).
+		WithArgs(&bookingID, false, nil, 9, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SaveOccurrence(tenantCtx(), occurrence))
+
+	mock.ExpectQuery(`FROM recurring_occurrences WHERE recurring_id = \$1 AND tenant_id = \$2`).
+		WithArgs(3, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "recurring_id", "occurrence_date", "booking_id", "detached", "error", "created_at"}).
+			AddRow(9, 3, time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), bookingID, false, nil, time.Now()))
+	occurrences, err := repo.GetOccurrences(tenantCtx(), 3)
+	assert.NoError(t, err)
+	assert.Len(t, occurrences, 1)
+	assert.Equal(t, "2024-05-06", occurrences[0].Date)
+	assert.Equal(t, bookingID, *occurrences[0].BookingID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
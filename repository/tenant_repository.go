@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"auth-service/model"
+	"auth-service/utils"
+	"context"
+	"database/sql"
+)
+
+type TenantRepositoryInterface interface {
+	GetAll(ctx context.Context) ([]model.Tenant, error)
+	GetByID(ctx context.Context, id int64) (*model.Tenant, error)
+	Create(ctx context.Context, t *model.Tenant) error
+}
+
+type TenantRepository struct {
+	DB *sql.DB
+}
+
+func NewTenantRepository(db *sql.DB) *TenantRepository {
+	return &TenantRepository{DB: db}
+}
+
+func currentTenant(ctx context.Context) (int64, error) {
+	tenantID, ok := utils.TenantFromContext(ctx)
+	if !ok {
+		return 0, ErrNoTenant
+	}
+	return tenantID, nil
+}
+
+func (r *TenantRepository) GetAll(ctx context.Context) ([]model.Tenant, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, name, slug, created_at FROM tenants ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []model.Tenant
+	for rows.Next() {
+		var t model.Tenant
+		if err := rows.Scan(&t.ID, &t.Name, &t.Slug, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+
+	return tenants, rows.Err()
+}
+
+func (r *TenantRepository) GetByID(ctx context.Context, id int64) (*model.Tenant, error) {
+	var t model.Tenant
+	err := r.DB.QueryRowContext(ctx, `SELECT id, name, slug, created_at FROM tenants WHERE id = $1`, id).
+		Scan(&t.ID, &t.Name, &t.Slug, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *TenantRepository) Create(ctx context.Context, t *model.Tenant) error {
+	return r.DB.QueryRowContext(ctx,
+		`INSERT INTO tenants (name, slug) VALUES ($1, $2) RETURNING id, created_at`,
+		t.Name, t.Slug,
+	).Scan(&t.ID, &t.CreatedAt)
+}
@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func tenantCtx() context.Context {
+	return utils.WithTenant(context.Background(), 1)
+}
+
+// expectLiveRow expects the check a versioned write that matched nothing
+// makes for the row id of table in the tenant of tenantCtx.
+func expectLiveRow(mock sqlmock.Sqlmock, table, key string, id any, exists bool) {
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM `+table+` WHERE `+key+` = \$1 AND tenant_id = \$2 AND deleted_at IS NULL\)`).
+		WithArgs(id, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(exists))
+}
+
+func TestTenantRepository_GetAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTenantRepository(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}).
+		AddRow(1, "Default", "default", now).
+		AddRow(2, "Acme", "acme", now)
+	mock.ExpectQuery("SELECT id, name, slug, created_at FROM tenants ORDER BY id").WillReturnRows(rows)
+
+	tenants, err := repo.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, tenants, 2)
+	assert.Equal(t, "acme", tenants[1].Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTenantRepository_GetByID_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTenantRepository(db)
+
+	mock.ExpectQuery("SELECT id, name, slug, created_at FROM tenants WHERE id = \\$1").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}))
+
+	tenant, err := repo.GetByID(context.Background(), 9)
+	assert.NoError(t, err)
+	assert.Nil(t, tenant)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTenantRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTenantRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO tenants").
+		WithArgs("Acme", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, now))
+
+	tenant := &model.Tenant{Name: "Acme", Slug: "acme"}
+	err = repo.Create(context.Background(), tenant)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), tenant.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScopedRepository_NoTenant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewCarRepository(db)
+
+	cars, err := repo.GetAll(context.Background())
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	assert.Nil(t, cars)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
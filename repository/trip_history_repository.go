@@ -2,13 +2,20 @@ package repository
 
 import (
 	"auth-service/model"
+	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 type TripHistoryRepositoryInterface interface {
-	GetTripHistory() ([]model.TripHistory, error)
+	GetTripHistory(ctx context.Context) ([]model.TripHistory, error)
 }
 
+// tripStopsColumn lists the addresses of the stops of a trip's booking, in
+// order, for the history and receipts.
+const tripStopsColumn = `ARRAY(SELECT s.address FROM booking_stops s WHERE s.booking_id = trips.booking_id ORDER BY s.position)`
+
 type TripHistoryRepository struct {
 	DB *sql.DB
 }
@@ -17,14 +24,21 @@ func NewTripHistoryRepository(db *sql.DB) *TripHistoryRepository {
 	return &TripHistoryRepository{DB: db}
 }
 
-func (r *TripHistoryRepository) GetTripHistory() ([]model.TripHistory, error) {
+func (r *TripHistoryRepository) GetTripHistory(ctx context.Context) ([]model.TripHistory, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-        SELECT id, booking_code, customer_name, booking_date,
+        SELECT id, COALESCE(booking_id, ''), customer_name, booking_date,
                duration_minutes, distance_km, pickup_location, destination,
-               driver_name, vehicle_name, amount, rating, feedback
+               driver_name, vehicle_name, amount, COALESCE(rating, 0), COALESCE(feedback, ''),
+               ` + tripStopsColumn + `
         FROM trips
+        WHERE tenant_id = $1
     `
-	rows, err := r.DB.Query(query)
+	rows, err := r.DB.QueryContext(ctx, query, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +52,7 @@ func (r *TripHistoryRepository) GetTripHistory() ([]model.TripHistory, error) {
 			&b.ID, &b.BookingCode, &b.CustomerName, &b.BookingDate,
 			&b.DurationMinutes, &b.DistanceKM, &b.PickupLocation,
 			&b.Destination, &b.DriverName, &b.VehicleName,
-			&b.Amount, &b.Rating, &b.Feedback,
+			&b.Amount, &b.Rating, &b.Feedback, pq.Array(&b.Stops),
 		)
 		if err != nil {
 			return nil, err
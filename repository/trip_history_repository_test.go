@@ -19,18 +19,19 @@ func TestTripHistoryRepository_GetTripHistory_Success(t *testing.T) {
 
 	repo := repository.NewTripHistoryRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "booking_code", "customer_name", "booking_date", "duration_minutes", "distance_km", "pickup_location", "destination", "driver_name", "vehicle_name", "amount", "rating", "feedback"}).
-		AddRow(1, "ABC123", "John Doe", "2023-01-01", 60, 50, "Location A", "Location B", "Driver X", "Car Y", 100, 4.5, "Good trip")
+	rows := sqlmock.NewRows([]string{"id", "booking_id", "customer_name", "booking_date", "duration_minutes", "distance_km", "pickup_location", "destination", "driver_name", "vehicle_name", "amount", "rating", "feedback", "stops"}).
+		AddRow(1, "ABC123", "John Doe", "2023-01-01", 60, 50, "Location A", "Location B", "Driver X", "Car Y", 100, 4.5, "Good trip", "{\"Stop 1\",\"Stop 2\"}")
 
 	mock.ExpectQuery(`
-        SELECT id, booking_code, customer_name, booking_date,
+        SELECT id, COALESCE\(booking_id, ''\), customer_name, booking_date,
                duration_minutes, distance_km, pickup_location, destination,
-               driver_name, vehicle_name, amount, rating, feedback
+               driver_name, vehicle_name, amount, COALESCE\(rating, 0\), COALESCE\(feedback, ''\),
+               ARRAY\(SELECT s.address FROM booking_stops s WHERE s.booking_id = trips.booking_id ORDER BY s.position\)
         FROM trips
     `).
 		WillReturnRows(rows)
 
-	tripHistories, err := repo.GetTripHistory()
+	tripHistories, err := repo.GetTripHistory(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.NotNil(t, tripHistories)
@@ -45,9 +46,10 @@ func TestTripHistoryRepository_GetTripHistory_Success(t *testing.T) {
 	assert.Equal(t, "Location B", tripHistories[0].Destination)
 	assert.Equal(t, "Driver X", tripHistories[0].DriverName)
 	assert.Equal(t, "Car Y", tripHistories[0].VehicleName)
-	assert.Equal(t, 100, tripHistories[0].Amount)
+	assert.Equal(t, 100.0, tripHistories[0].Amount)
 	assert.Equal(t, float32(4.5), tripHistories[0].Rating)
 	assert.Equal(t, "Good trip", tripHistories[0].Feedback)
+	assert.Equal(t, []string{"Stop 1", "Stop 2"}, tripHistories[0].Stops)
 
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
@@ -61,14 +63,15 @@ func TestTripHistoryRepository_GetTripHistory_NoRows(t *testing.T) {
 	repo := repository.NewTripHistoryRepository(db)
 
 	mock.ExpectQuery(`
-        SELECT id, booking_code, customer_name, booking_date,
+        SELECT id, COALESCE\(booking_id, ''\), customer_name, booking_date,
                duration_minutes, distance_km, pickup_location, destination,
-               driver_name, vehicle_name, amount, rating, feedback
+               driver_name, vehicle_name, amount, COALESCE\(rating, 0\), COALESCE\(feedback, ''\),
+               ARRAY\(SELECT s.address FROM booking_stops s WHERE s.booking_id = trips.booking_id ORDER BY s.position\)
         FROM trips
     `).
 		WillReturnError(sql.ErrNoRows)
 
-	tripHistories, err := repo.GetTripHistory()
+	tripHistories, err := repo.GetTripHistory(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, tripHistories)
@@ -85,17 +88,18 @@ func TestTripHistoryRepository_GetTripHistory_EmptyResult(t *testing.T) {
 
 	repo := repository.NewTripHistoryRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "booking_code", "customer_name", "booking_date", "duration_minutes", "distance_km", "pickup_location", "destination", "driver_name", "vehicle_name", "amount", "rating", "feedback"})
+	rows := sqlmock.NewRows([]string{"id", "booking_id", "customer_name", "booking_date", "duration_minutes", "distance_km", "pickup_location", "destination", "driver_name", "vehicle_name", "amount", "rating", "feedback", "stops"})
 
 	mock.ExpectQuery(`
-        SELECT id, booking_code, customer_name, booking_date,
+        SELECT id, COALESCE\(booking_id, ''\), customer_name, booking_date,
                duration_minutes, distance_km, pickup_location, destination,
-               driver_name, vehicle_name, amount, rating, feedback
+               driver_name, vehicle_name, amount, COALESCE\(rating, 0\), COALESCE\(feedback, ''\),
+               ARRAY\(SELECT s.address FROM booking_stops s WHERE s.booking_id = trips.booking_id ORDER BY s.position\)
         FROM trips
     `).
 		WillReturnRows(rows)
 
-	tripHistories, err := repo.GetTripHistory()
+	tripHistories, err := repo.GetTripHistory(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Equal(t, []model.TripHistory{}, tripHistories)
@@ -113,14 +117,15 @@ func TestTripHistoryRepository_GetTripHistory_Error(t *testing.T) {
 	repo := repository.NewTripHistoryRepository(db)
 
 	mock.ExpectQuery(`
-        SELECT id, booking_code, customer_name, booking_date,
+        SELECT id, COALESCE\(booking_id, ''\), customer_name, booking_date,
                duration_minutes, distance_km, pickup_location, destination,
-               driver_name, vehicle_name, amount, rating, feedback
+               driver_name, vehicle_name, amount, COALESCE\(rating, 0\), COALESCE\(feedback, ''\),
+               ARRAY\(SELECT s.address FROM booking_stops s WHERE s.booking_id = trips.booking_id ORDER BY s.position\)
         FROM trips
     `).
 		WillReturnError(sql.ErrConnDone)
 
-	tripHistories, err := repo.GetTripHistory()
+	tripHistories, err := repo.GetTripHistory(tenantCtx())
 
 	assert.Error(t, err)
 	assert.Nil(t, tripHistories)
@@ -139,7 +144,7 @@ func TestGetTripHistory_QueryError(t *testing.T) {
 	mock.ExpectQuery("FROM trips").
 		WillReturnError(errors.New("query error"))
 
-	trips, err := repo.GetTripHistory()
+	trips, err := repo.GetTripHistory(tenantCtx())
 
 	assert.Nil(t, trips)
 	assert.Error(t, err)
@@ -152,13 +157,13 @@ func TestGetTripHistory_ScanError(t *testing.T) {
 	repo := repository.NewTripHistoryRepository(db)
 
 	rows := sqlmock.NewRows([]string{
-		"id", "booking_code", "customer_name",
+		"id", "booking_id", "customer_name",
 	}).AddRow(1, "BC001", "John")
 
 	mock.ExpectQuery("FROM trips").
 		WillReturnRows(rows)
 
-	trips, err := repo.GetTripHistory()
+	trips, err := repo.GetTripHistory(tenantCtx())
 
 	assert.Nil(t, trips)
 	assert.Error(t, err)
@@ -171,23 +176,23 @@ func TestGetTripHistory_RowsErr(t *testing.T) {
 	repo := repository.NewTripHistoryRepository(db)
 
 	rows := sqlmock.NewRows([]string{
-		"id", "booking_code", "customer_name", "booking_date",
+		"id", "booking_id", "customer_name", "booking_date",
 		"duration_minutes", "distance_km", "pickup_location",
 		"destination", "driver_name", "vehicle_name",
-		"amount", "rating", "feedback",
+		"amount", "rating", "feedback", "stops",
 	}).
 		AddRow(
 			1, "BC001", "John", time.Now(),
 			30, 10.5, "A", "B",
 			"Driver A", "Car A",
-			50000, 4.5, "OK",
+			50000, 4.5, "OK", "{}",
 		).
 		RowError(0, errors.New("row error"))
 
 	mock.ExpectQuery("FROM trips").
 		WillReturnRows(rows)
 
-	trips, err := repo.GetTripHistory()
+	trips, err := repo.GetTripHistory(tenantCtx())
 
 	assert.Nil(t, trips)
 	assert.Error(t, err)
@@ -200,22 +205,22 @@ func TestGetTripHistory_Success(t *testing.T) {
 	repo := repository.NewTripHistoryRepository(db)
 
 	rows := sqlmock.NewRows([]string{
-		"id", "booking_code", "customer_name", "booking_date",
+		"id", "booking_id", "customer_name", "booking_date",
 		"duration_minutes", "distance_km", "pickup_location",
 		"destination", "driver_name", "vehicle_name",
-		"amount", "rating", "feedback",
+		"amount", "rating", "feedback", "stops",
 	}).
 		AddRow(
 			1, "BC001", "John", time.Now(),
 			30, 10, "A", "B",
 			"Driver A", "Car A",
-			50000, 4.5, "OK",
+			50000, 4.5, "OK", "{}",
 		)
 
 	mock.ExpectQuery("FROM trips").
 		WillReturnRows(rows)
 
-	trips, err := repo.GetTripHistory()
+	trips, err := repo.GetTripHistory(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Len(t, trips, 1)
@@ -4,13 +4,15 @@ import (
 	"auth-service/model"
 	"context"
 	"database/sql"
+	"errors"
 )
 
 type TripsRepositoryInterface interface {
-	Create(t *model.VehicleTrip) error
-	Update(t *model.VehicleTrip) error
-	Delete(id uint) error
-	FindByVehicle(vehicleID uint) ([]model.VehicleTrip, error)
+	Create(ctx context.Context, t *model.VehicleTrip) error
+	Update(ctx context.Context, t *model.VehicleTrip) error
+	Delete(ctx context.Context, id uint) error
+	FindByVehicle(ctx context.Context, vehicleID uint) ([]model.VehicleTrip, error)
+	FindByBooking(ctx context.Context, bookingID string) (*model.VehicleTrip, error)
 	GetTripTotal(ctx context.Context) (*model.TotalTrips, error)
 }
 
@@ -21,150 +23,196 @@ type TripsRepository struct {
 func NewTripRepo(db *sql.DB) *TripsRepository {
 	return &TripsRepository{DB: db}
 }
-func (r *TripsRepository) Create(t *model.VehicleTrip) error {
-	query := `
-		INSERT INTO vehicle_trips
-		(vehicle_id, trip_date, origin, destination, rating, price, passenger_name, distance_km)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`
-	_, err := r.DB.Exec(query,
-		t.VehicleID,
-		t.TripDate,
-		t.Origin,
-		t.Destination,
-		t.Rating,
-		t.Price,
-		t.PassengerName,
-		t.DistanceKM,
-	)
-	return err
+
+const tripColumns = `id, booking_id, COALESCE(vehicle_id, 0), COALESCE(driver_id, 0), booking_date, pickup_location, destination,
+		distance_km, duration_minutes, COALESCE(rating, 0), amount, customer_name, driver_name, vehicle_name,
+		COALESCE(feedback, ''), status, started_at, ended_at, pickup_lat, pickup_lng, destination_lat, destination_lng`
+
+// Create records a trip entered by hand, which is complete unless it says otherwise.
+func (r *TripsRepository) Create(ctx context.Context, t *model.VehicleTrip) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	if t.Status == "" {
+		t.Status = model.TripCompleted
+	}
+	return saveTrip(ctx, r.DB, tenantID, t)
 }
 
-func (r *TripsRepository) Update(t *model.VehicleTrip) error {
-	query := `
-		UPDATE vehicle_trips
-		SET vehicle_id = $1,
-			trip_date = $2,
-			origin = $3,
-			destination = $4,
-			rating = $5,
-			price = $6
-			passenger_name = $7
-			distance_km = $8
-		WHERE id = $9
-	`
-	_, err := r.DB.Exec(query,
-		t.VehicleID,
-		t.TripDate,
-		t.Origin,
-		t.Destination,
-		t.Rating,
-		t.Price,
-		t.PassengerName,
-		t.DistanceKM,
-		t.ID,
-	)
+func (r *TripsRepository) Update(ctx context.Context, t *model.VehicleTrip) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	if t.Status == "" {
+		t.Status = model.TripCompleted
+	}
+	err = saveTrip(ctx, r.DB, tenantID, t)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
 	return err
 }
 
-func (r *TripsRepository) Delete(id uint) error {
-	query := `DELETE FROM vehicle_trips WHERE id = $1`
-	_, err := r.DB.Exec(query, id)
+// saveTrip inserts a new trip or rewrites an existing one. It is shared with
+// the booking transition transaction, which opens and closes booking trips.
+func saveTrip(ctx context.Context, q queryRower, tenantID int64, t *model.VehicleTrip) error {
+	args := []any{
+		t.BookingID, t.VehicleID, t.DriverID, t.TripDate, t.Origin, t.Destination, t.DistanceKM, t.DurationMinutes,
+		t.Rating, t.Price, t.PassengerName, t.DriverName, t.VehicleName, t.Feedback, t.Status, t.StartedAt, t.EndedAt,
+		t.OriginLat, t.OriginLng, t.DestinationLat, t.DestinationLng,
+	}
+
+	if t.ID == 0 {
+		var id int64
+		err := q.QueryRowContext(ctx,
+			`INSERT INTO trips (booking_id, vehicle_id, driver_id, booking_date, pickup_location, destination, distance_km, duration_minutes,
+				rating, amount, customer_name, driver_name, vehicle_name, feedback, status, started_at, ended_at,
+				pickup_lat, pickup_lng, destination_lat, destination_lng, tenant_id)
+			VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4, $5, $6, $7, $8, NULLIF($9::numeric, 0), $10, $11, $12, $13, $14, $15, $16, $17,
+				$18, $19, $20, $21, $22)
+			RETURNING id`,
+			append(args, tenantID)...,
+		).Scan(&id)
+		t.ID = uint(id)
+		return err
+	}
+
+	return q.QueryRowContext(ctx,
+		`UPDATE trips SET booking_id = $1, vehicle_id = NULLIF($2, 0), driver_id = NULLIF($3, 0), booking_date = $4,
+			pickup_location = $5, destination = $6, distance_km = $7, duration_minutes = $8, rating = NULLIF($9::numeric, 0),
+			amount = $10, customer_name = $11, driver_name = $12, vehicle_name = $13, feedback = $14, status = $15,
+			started_at = $16, ended_at = $17, pickup_lat = $18, pickup_lng = $19, destination_lat = $20, destination_lng = $21
+		WHERE id = $22 AND tenant_id = $23 RETURNING id`,
+		append(args, t.ID, tenantID)...,
+	).Scan(new(int64))
+}
+
+func (r *TripsRepository) Delete(ctx context.Context, id uint) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM trips WHERE id = $1 AND tenant_id = $2`
+	_, err = r.DB.ExecContext(ctx, query, id, tenantID)
 	return err
 }
 
-func (r *TripsRepository) FindByID(id uint) (*model.VehicleTrip, error) {
-	query := `
-		SELECT id, vehicle_id, trip_date, origin, destination, rating, price, passenger_name, distance_km
-		FROM vehicle_trips
-		WHERE id = $1
-	`
-	row := r.DB.QueryRow(query, id)
-	var t model.VehicleTrip
-	err := row.Scan(
-		&t.ID,
-		&t.VehicleID,
-		&t.TripDate,
-		&t.Origin,
-		&t.Destination,
-		&t.Rating,
-		&t.Price,
-		&t.PassengerName,
-		&t.DistanceKM,
-	)
+func (r *TripsRepository) FindByID(ctx context.Context, id uint) (*model.VehicleTrip, error) {
+	tenantID, err := currentTenant(ctx)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
 		return nil, err
 	}
-	return &t, nil
+
+	row := r.DB.QueryRowContext(ctx, `SELECT `+tripColumns+` FROM trips WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	return scanTrip(row)
 }
 
-func (r *TripsRepository) FindByVehicle(vehicleID uint) ([]model.VehicleTrip, error) {
-	query := `
-		SELECT id, vehicle_id, trip_date, origin, destination, rating, price, passenger_name, distance_km
-		FROM vehicle_trips
-		WHERE vehicle_id = $1
-	`
-	rows, err := r.DB.Query(query, vehicleID)
+// FindByBooking returns the booking's latest trip, or nil if it has none.
+func (r *TripsRepository) FindByBooking(ctx context.Context, bookingID string) (*model.VehicleTrip, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := r.DB.QueryRowContext(ctx,
+		`SELECT `+tripColumns+` FROM trips WHERE booking_id = $1 AND tenant_id = $2 ORDER BY id DESC LIMIT 1`,
+		bookingID, tenantID,
+	)
+	return scanTrip(row)
+}
+
+func (r *TripsRepository) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.VehicleTrip, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT `+tripColumns+` FROM trips WHERE vehicle_id = $1 AND tenant_id = $2 ORDER BY id`,
+		vehicleID, tenantID,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+
 	var trips []model.VehicleTrip
 	for rows.Next() {
-		var t model.VehicleTrip
-		err := rows.Scan(
-			&t.ID,
-			&t.VehicleID,
-			&t.TripDate,
-			&t.Origin,
-			&t.Destination,
-			&t.Rating,
-			&t.Price,
-			&t.PassengerName,
-			&t.DistanceKM,
-		)
+		t, err := scanTrip(rows)
 		if err != nil {
 			return nil, err
 		}
-		trips = append(trips, t)
+		trips = append(trips, *t)
 	}
-	return trips, nil
+	return trips, rows.Err()
 }
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTrip(row rowScanner) (*model.VehicleTrip, error) {
+	var t model.VehicleTrip
+	err := row.Scan(
+		&t.ID, &t.BookingID, &t.VehicleID, &t.DriverID, &t.TripDate, &t.Origin, &t.Destination,
+		&t.DistanceKM, &t.DurationMinutes, &t.Rating, &t.Price, &t.PassengerName, &t.DriverName, &t.VehicleName,
+		&t.Feedback, &t.Status, &t.StartedAt, &t.EndedAt, &t.OriginLat, &t.OriginLng, &t.DestinationLat, &t.DestinationLng,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetTripTotal sums completed trips only; ongoing ones have no actuals yet.
 func (r *TripsRepository) GetTripTotal(ctx context.Context) (*model.TotalTrips, error) {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	stats := &model.TotalTrips{}
 
-	err := r.DB.QueryRowContext(ctx, `
-		SELECT COUNT(*) 
-		FROM vehicle_trips;
-	`).Scan(&stats.TotalTrips)
+	err = r.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM trips
+		WHERE tenant_id = $1 AND status = 'completed';
+	`, tenantID).Scan(&stats.TotalTrips)
 	if err != nil {
 		return nil, err
 	}
 
 	err = r.DB.QueryRowContext(ctx, `
 		SELECT COALESCE(SUM(distance_km), 0)
-		FROM vehicle_trips;
-	`).Scan(&stats.TotalDistance)
+		FROM trips
+		WHERE tenant_id = $1 AND status = 'completed';
+	`, tenantID).Scan(&stats.TotalDistance)
 	if err != nil {
 		return nil, err
 	}
 
 	err = r.DB.QueryRowContext(ctx, `
-		SELECT COALESCE(SUM(price), 0)
-		FROM vehicle_trips;
-	`).Scan(&stats.TotalRevenue)
+		SELECT COALESCE(SUM(amount), 0)
+		FROM trips
+		WHERE tenant_id = $1 AND status = 'completed';
+	`, tenantID).Scan(&stats.TotalRevenue)
 	if err != nil {
 		return nil, err
 	}
 
 	err = r.DB.QueryRowContext(ctx, `
 		SELECT COALESCE(AVG(rating), 0)
-		FROM vehicle_trips;
-	`).Scan(&stats.AverageRating)
+		FROM trips
+		WHERE tenant_id = $1 AND status = 'completed';
+	`, tenantID).Scan(&stats.AverageRating)
 	if err != nil {
 		return nil, err
 	}
@@ -3,7 +3,6 @@ package repository_test
 import (
 	"auth-service/model"
 	"auth-service/repository"
-	"context"
 	"database/sql"
 	"errors"
 	"regexp"
@@ -14,6 +13,10 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var tripColumns = []string{"id", "booking_id", "vehicle_id", "driver_id", "booking_date", "pickup_location", "destination",
+	"distance_km", "duration_minutes", "rating", "amount", "customer_name", "driver_name", "vehicle_name", "feedback", "status", "started_at", "ended_at",
+	"pickup_lat", "pickup_lng", "destination_lat", "destination_lng"}
+
 func TestTripsRepository_Create(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -32,16 +35,15 @@ func TestTripsRepository_Create(t *testing.T) {
 		DistanceKM:    150,
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`
-		INSERT INTO vehicle_trips
-		(vehicle_id, trip_date, origin, destination, rating, price, passenger_name, distance_km)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`)).
-		WithArgs(trip.VehicleID, trip.TripDate, trip.Origin, trip.Destination, trip.Rating, trip.Price, trip.PassengerName, trip.DistanceKM).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`INSERT INTO trips .* VALUES \(\$1, NULLIF\(\$2, 0\), NULLIF\(\$3, 0\), .* RETURNING id`).
+		WithArgs(nil, trip.VehicleID, trip.DriverID, trip.TripDate, trip.Origin, trip.Destination, trip.DistanceKM, 0,
+			trip.Rating, trip.Price, trip.PassengerName, "", "", "", model.TripCompleted, nil, nil, nil, nil, nil, nil, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
 
-	err = repo.Create(trip)
+	err = repo.Create(tenantCtx(), trip)
 	assert.NoError(t, err)
+	assert.Equal(t, uint(9), trip.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestTripsRepository_Update(t *testing.T) {
@@ -51,8 +53,10 @@ func TestTripsRepository_Update(t *testing.T) {
 
 	repo := repository.NewTripRepo(db)
 
+	bookingID := "BK1"
 	trip := &model.VehicleTrip{
 		ID:            1,
+		BookingID:     &bookingID,
 		VehicleID:     1,
 		TripDate:      time.Now(),
 		Origin:        "Jakarta",
@@ -61,25 +65,20 @@ func TestTripsRepository_Update(t *testing.T) {
 		Price:         120000,
 		PassengerName: "Jane Doe",
 		DistanceKM:    160,
+		Status:        model.TripOngoing,
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta(`
-		UPDATE vehicle_trips
-		SET vehicle_id = $1,
-			trip_date = $2,
-			origin = $3,
-			destination = $4,
-			rating = $5,
-			price = $6
-			passenger_name = $7
-			distance_km = $8
-		WHERE id = $9
-	`)).
-		WithArgs(trip.VehicleID, trip.TripDate, trip.Origin, trip.Destination, trip.Rating, trip.Price, trip.PassengerName, trip.DistanceKM, trip.ID).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`UPDATE trips SET booking_id = \$1, .* WHERE id = \$22 AND tenant_id = \$23 RETURNING id`).
+		WithArgs(trip.BookingID, trip.VehicleID, trip.DriverID, trip.TripDate, trip.Origin, trip.Destination, trip.DistanceKM, 0,
+			trip.Rating, trip.Price, trip.PassengerName, "", "", "", model.TripOngoing, nil, nil, nil, nil, nil, nil, trip.ID, int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
-	err = repo.Update(trip)
+	err = repo.Update(tenantCtx(), trip)
 	assert.NoError(t, err)
+
+	mock.ExpectQuery(`UPDATE trips`).WillReturnError(sql.ErrNoRows)
+	assert.NoError(t, repo.Update(tenantCtx(), trip))
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestTripsRepository_Delete(t *testing.T) {
@@ -89,11 +88,11 @@ func TestTripsRepository_Delete(t *testing.T) {
 
 	repo := repository.NewTripRepo(db)
 
-	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM vehicle_trips WHERE id = $1")).
-		WithArgs(1).
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM trips WHERE id = $1")).
+		WithArgs(1, int64(1)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Delete(1)
+	err = repo.Delete(tenantCtx(), 1)
 	assert.NoError(t, err)
 }
 
@@ -106,16 +105,12 @@ func TestTripsRepository_FindByVehicle(t *testing.T) {
 
 	tripDate := time.Now()
 
-	rows := sqlmock.NewRows([]string{"id", "vehicle_id", "trip_date", "origin", "destination", "rating", "price", "passenger_name", "distance_km"}).
-		AddRow(1, 1, tripDate, "Jakarta", "Bandung", 5, 100000, "John Doe", 150)
+	rows := sqlmock.NewRows(tripColumns).
+		AddRow(1, nil, 1, 0, tripDate, "Jakarta", "Bandung", 150, 0, 5, 100000, "John Doe", "", "", "", model.TripCompleted, tripDate, tripDate, nil, nil, nil, nil)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, vehicle_id, trip_date, origin, destination, rating, price, passenger_name, distance_km
-		FROM vehicle_trips
-		WHERE vehicle_id = $1
-	`)).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`FROM trips WHERE vehicle_id = \$1 AND tenant_id = \$2 ORDER BY id`).WithArgs(1, int64(1)).WillReturnRows(rows)
 
-	result, err := repo.FindByVehicle(1)
+	result, err := repo.FindByVehicle(tenantCtx(), 1)
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
 	assert.Equal(t, "John Doe", result[0].PassengerName)
@@ -131,16 +126,12 @@ func TestTripsRepository_FindByID_Success(t *testing.T) {
 	id := uint(1)
 	tripDate := time.Now()
 
-	rows := sqlmock.NewRows([]string{"id", "vehicle_id", "trip_date", "origin", "destination", "rating", "price", "passenger_name", "distance_km"}).
-		AddRow(1, 1, tripDate, "Jakarta", "Bandung", 5, 100000, "John Doe", 150)
+	rows := sqlmock.NewRows(tripColumns).
+		AddRow(1, nil, 1, 0, tripDate, "Jakarta", "Bandung", 150, 0, 5, 100000, "John Doe", "", "", "", model.TripCompleted, tripDate, tripDate, nil, nil, nil, nil)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, vehicle_id, trip_date, origin, destination, rating, price, passenger_name, distance_km
-		FROM vehicle_trips
-		WHERE id = $1
-	`)).WithArgs(id).WillReturnRows(rows)
+	mock.ExpectQuery(`FROM trips WHERE id = \$1 AND tenant_id = \$2`).WithArgs(id, int64(1)).WillReturnRows(rows)
 
-	result, err := repo.FindByID(id)
+	result, err := repo.FindByID(tenantCtx(), id)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, id, result.ID)
@@ -156,13 +147,9 @@ func TestTripsRepository_FindByID_NotFound(t *testing.T) {
 
 	id := uint(1)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, vehicle_id, trip_date, origin, destination, rating, price, passenger_name, distance_km
-		FROM vehicle_trips
-		WHERE id = $1
-	`)).WithArgs(id).WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`FROM trips WHERE id = \$1 AND tenant_id = \$2`).WithArgs(id, int64(1)).WillReturnError(sql.ErrNoRows)
 
-	result, err := repo.FindByID(id)
+	result, err := repo.FindByID(tenantCtx(), id)
 	assert.NoError(t, err)
 	assert.Nil(t, result)
 }
@@ -173,11 +160,11 @@ func TestFindByVehicle_QueryError(t *testing.T) {
 
 	repo := repository.NewTripRepo(db)
 
-	mock.ExpectQuery("FROM vehicle_trips").
-		WithArgs(uint(1)).
+	mock.ExpectQuery("FROM trips").
+		WithArgs(uint(1), int64(1)).
 		WillReturnError(errors.New("query failed"))
 
-	trips, err := repo.FindByVehicle(1)
+	trips, err := repo.FindByVehicle(tenantCtx(), 1)
 
 	assert.Nil(t, trips)
 	assert.Error(t, err)
@@ -193,10 +180,10 @@ func TestFindByVehicle_ScanError(t *testing.T) {
 		"id", "vehicle_id", "trip_date",
 	}).AddRow(1, 1, time.Now())
 
-	mock.ExpectQuery("FROM vehicle_trips").
+	mock.ExpectQuery("FROM trips").
 		WillReturnRows(rows)
 
-	trips, err := repo.FindByVehicle(1)
+	trips, err := repo.FindByVehicle(tenantCtx(), 1)
 
 	assert.Nil(t, trips)
 	assert.Error(t, err)
@@ -211,7 +198,7 @@ func TestGetTripTotal_CountError(t *testing.T) {
 	mock.ExpectQuery("SELECT COUNT").
 		WillReturnError(errors.New("count error"))
 
-	stats, err := repo.GetTripTotal(context.Background())
+	stats, err := repo.GetTripTotal(tenantCtx())
 
 	assert.Nil(t, stats)
 	assert.Error(t, err)
@@ -229,13 +216,13 @@ func TestGetTripTotal_Success(t *testing.T) {
 	mock.ExpectQuery("SUM\\(distance_km\\)").
 		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(50))
 
-	mock.ExpectQuery("SUM\\(price\\)").
+	mock.ExpectQuery("SUM\\(amount\\)").
 		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(5000))
 
 	mock.ExpectQuery("AVG\\(rating\\)").
 		WillReturnRows(sqlmock.NewRows([]string{"avg"}).AddRow(4.5))
 
-	stats, err := repo.GetTripTotal(context.Background())
+	stats, err := repo.GetTripTotal(tenantCtx())
 
 	assert.NoError(t, err)
 	assert.Equal(t, int64(2), stats.TotalTrips)
@@ -253,13 +240,13 @@ func TestGetTripTotal_AvgError(t *testing.T) {
 	mock.ExpectQuery("SUM\\(distance_km\\)").
 		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(10))
 
-	mock.ExpectQuery("SUM\\(price\\)").
+	mock.ExpectQuery("SUM\\(amount\\)").
 		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1000))
 
 	mock.ExpectQuery("AVG\\(rating\\)").
 		WillReturnError(errors.New("avg error"))
 
-	stats, err := repo.GetTripTotal(context.Background())
+	stats, err := repo.GetTripTotal(tenantCtx())
 
 	assert.Nil(t, stats)
 	assert.Error(t, err)
@@ -277,10 +264,10 @@ func TestGetTripTotal_RevenueError(t *testing.T) {
 	mock.ExpectQuery("SUM\\(distance_km\\)").
 		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(10))
 
-	mock.ExpectQuery("SUM\\(price\\)").
+	mock.ExpectQuery("SUM\\(amount\\)").
 		WillReturnError(errors.New("price error"))
 
-	stats, err := repo.GetTripTotal(context.Background())
+	stats, err := repo.GetTripTotal(tenantCtx())
 
 	assert.Nil(t, stats)
 	assert.Error(t, err)
@@ -298,7 +285,7 @@ func TestGetTripTotal_DistanceError(t *testing.T) {
 	mock.ExpectQuery("SUM\\(distance_km\\)").
 		WillReturnError(errors.New("distance error"))
 
-	stats, err := repo.GetTripTotal(context.Background())
+	stats, err := repo.GetTripTotal(tenantCtx())
 
 	assert.Nil(t, stats)
 	assert.Error(t, err)
@@ -313,13 +300,9 @@ func TestTripsRepository_FindByID_Error(t *testing.T) {
 
 	id := uint(1)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT id, vehicle_id, trip_date, origin, destination, rating, price, passenger_name, distance_km
-		FROM vehicle_trips
-		WHERE id = $1
-	`)).WithArgs(id).WillReturnError(sql.ErrConnDone)
+	mock.ExpectQuery(`FROM trips WHERE id = \$1 AND tenant_id = \$2`).WithArgs(id, int64(1)).WillReturnError(sql.ErrConnDone)
 
-	result, err := repo.FindByID(id)
+	result, err := repo.FindByID(tenantCtx(), id)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, sql.ErrConnDone, err)
@@ -332,26 +315,30 @@ func TestTripsRepository_GetTripTotal(t *testing.T) {
 
 	repo := repository.NewTripRepo(db)
 
-	ctx := context.Background()
+	ctx := tenantCtx()
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
 		SELECT COUNT(*)
-		FROM vehicle_trips;
+		FROM trips
+		WHERE tenant_id = $1 AND status = 'completed';
 	`)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
 		SELECT COALESCE(SUM(distance_km), 0)
-		FROM vehicle_trips;
+		FROM trips
+		WHERE tenant_id = $1 AND status = 'completed';
 	`)).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1500))
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-		SELECT COALESCE(SUM(price), 0)
-		FROM vehicle_trips;
+		SELECT COALESCE(SUM(amount), 0)
+		FROM trips
+		WHERE tenant_id = $1 AND status = 'completed';
 	`)).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1000000.0))
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
 		SELECT COALESCE(AVG(rating), 0)
-		FROM vehicle_trips;
+		FROM trips
+		WHERE tenant_id = $1 AND status = 'completed';
 	`)).WillReturnRows(sqlmock.NewRows([]string{"avg"}).AddRow(4.5))
 
 	result, err := repo.GetTripTotal(ctx)
@@ -361,3 +348,32 @@ func TestTripsRepository_GetTripTotal(t *testing.T) {
 	assert.Equal(t, 1000000.0, result.TotalRevenue)
 	assert.Equal(t, 4.5, result.AverageRating)
 }
+
+func TestTripsRepository_FindByBooking(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewTripRepo(db)
+
+	started := time.Now()
+	mock.ExpectQuery(`FROM trips WHERE booking_id = \$1 AND tenant_id = \$2 ORDER BY id DESC LIMIT 1`).
+		WithArgs("BK1", int64(1)).
+		WillReturnRows(sqlmock.NewRows(tripColumns).
+			AddRow(3, "BK1", 7, 2, started, "Gambir", "", 0, 0, 0, 0, "Sari", "Budi", "", "", model.TripOngoing, started, nil, -6.1767, 106.8306, nil, nil))
+
+	trip, err := repo.FindByBooking(tenantCtx(), "BK1")
+	assert.NoError(t, err)
+	assert.Equal(t, "BK1", *trip.BookingID)
+	assert.Equal(t, uint(2), trip.DriverID)
+	assert.Equal(t, model.TripOngoing, trip.Status)
+	assert.Nil(t, trip.EndedAt)
+
+	mock.ExpectQuery(`FROM trips WHERE booking_id`).
+		WithArgs("BK2", int64(1)).
+		WillReturnRows(sqlmock.NewRows(tripColumns))
+	missing, err := repo.FindByBooking(tenantCtx(), "BK2")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
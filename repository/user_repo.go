@@ -11,21 +11,21 @@ type UserRepositoryImpl struct {
 
 func (r *UserRepositoryImpl) Save(user *model.User) error {
 	_, err := r.DB.Exec(`
-		INSERT INTO users (username, password, role)
-		VALUES ($1, $2, $3)
-	`, user.Username, user.Password, user.Role)
+		INSERT INTO users (username, password, role, tenant_id, driver_id)
+		VALUES ($1, $2, $3, $4, $5)
+	`, user.Username, user.Password, user.Role, user.TenantID, user.DriverID)
 	return err
 }
 
 func (r *UserRepositoryImpl) FindByUsername(username string) (*model.User, error) {
 	row := r.DB.QueryRow(`
-		SELECT id, username, password, role
+		SELECT id, username, password, role, tenant_id, driver_id
 		FROM users
 		WHERE username = $1
 	`, username)
 
 	user := model.User{}
-	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.TenantID, &user.DriverID)
 	if err != nil {
 		return nil, err
 	}
@@ -27,11 +27,11 @@ func TestUserRepository_FindByUsername_Success(t *testing.T) {
 
 	repo := &UserRepositoryImpl{DB: db}
 
-	rows := sqlmock.NewRows([]string{"id", "username", "password", "role"}).
-		AddRow(1, "admin", "hashed-password", "ADMIN")
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "role", "tenant_id", "driver_id"}).
+		AddRow(1, "admin", "hashed-password", "ADMIN", 1, nil)
 
 	mock.ExpectQuery(`
-		SELECT id, username, password, role
+		SELECT id, username, password, role, tenant_id, driver_id
 		FROM users
 		WHERE username = \$1
 	`).WithArgs("admin").WillReturnRows(rows)
@@ -44,6 +44,8 @@ func TestUserRepository_FindByUsername_Success(t *testing.T) {
 	assert.Equal(t, "admin", user.Username)
 	assert.Equal(t, "hashed-password", user.Password)
 	assert.Equal(t, "ADMIN", user.Role)
+	assert.Equal(t, int64(1), user.TenantID)
+	assert.Nil(t, user.DriverID)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -55,7 +57,7 @@ func TestUserRepository_FindByUsername_NotFound(t *testing.T) {
 	repo := &UserRepositoryImpl{DB: db}
 
 	mock.ExpectQuery(`
-		SELECT id, username, password, role
+		SELECT id, username, password, role, tenant_id, driver_id
 		FROM users
 		WHERE username = \$1
 	`).WithArgs("unknown").WillReturnError(sql.ErrNoRows)
@@ -76,7 +78,7 @@ func TestUserRepository_FindByUsername_DBError(t *testing.T) {
 	repo := &UserRepositoryImpl{DB: db}
 
 	mock.ExpectQuery(`
-		SELECT id, username, password, role
+		SELECT id, username, password, role, tenant_id, driver_id
 		FROM users
 		WHERE username = \$1
 	`).WithArgs("admin").WillReturnError(errors.New("db error"))
@@ -97,15 +99,16 @@ func TestUserRepository_Save(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		mock.ExpectExec(`
-			INSERT INTO users \(username, password, role\)
-			VALUES \(\$1, \$2, \$3\)
-		`).WithArgs("admin", "hashed-password", "ADMIN").
+			INSERT INTO users \(username, password, role, tenant_id, driver_id\)
+			VALUES \(\$1, \$2, \$3, \$4, \$5\)
+		`).WithArgs("admin", "hashed-password", "ADMIN", int64(1), nil).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		user := &model.User{
 			Username: "admin",
 			Password: "hashed-password",
 			Role:     "ADMIN",
+			TenantID: 1,
 		}
 
 		err := repo.Save(user)
@@ -115,15 +118,16 @@ func TestUserRepository_Save(t *testing.T) {
 
 	t.Run("db_error", func(t *testing.T) {
 		mock.ExpectExec(`
-			INSERT INTO users \(username, password, role\)
-			VALUES \(\$1, \$2, \$3\)
-		`).WithArgs("admin", "hashed-password", "ADMIN").
+			INSERT INTO users \(username, password, role, tenant_id, driver_id\)
+			VALUES \(\$1, \$2, \$3, \$4, \$5\)
+		`).WithArgs("admin", "hashed-password", "ADMIN", int64(1), nil).
 			WillReturnError(errors.New("insert failed"))
 
 		user := &model.User{
 			Username: "admin",
 			Password: "hashed-password",
 			Role:     "ADMIN",
+			TenantID: 1,
 		}
 
 		err := repo.Save(user)
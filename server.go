@@ -2,31 +2,70 @@ package main
 
 import (
 	"auth-service/handler"
-	"auth-service/repository"
+	"auth-service/model"
 	"auth-service/service"
-	"database/sql"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(db *sql.DB) *gin.Engine {
+const (
+	idempotencyRetention = 24 * time.Hour
+	trashRetention       = 30 * 24 * time.Hour
+	dispatchOfferTimeout = 2 * time.Minute
+	quoteValidity        = 15 * time.Minute
+	recurringDaysAhead   = 14
+)
 
-	userRepo := &repository.UserRepositoryImpl{DB: db}
-	tokenRepo := &repository.TokenRepositoryImpl{DB: db}
-	driverRepo := &repository.DriverRepository{DB: db}
-	bookingRepo := &repository.BookingRepository{DB: db}
-	popularRepo := &repository.PopularDestinationRepository{DB: db}
-	carRepo := repository.NewCarRepository(db)
-	paymentRepo := repository.NewPaymentRepository(db)
+func newBookingService(repos *repositories) *service.BookingService {
+	return &service.BookingService{
+		Repo:          repos.Bookings,
+		Customers:     repos.Customers,
+		Drivers:       repos.Drivers,
+		Availability:  repos.Availability,
+		Pricing:       repos.Pricing,
+		Promotions:    repos.Promotions,
+		Cancellations: repos.Cancellations,
+		Notifications: repos.Notifications,
+		Trips:         repos.Trips,
+		Corporate:     repos.Corporate,
+		Payments:      repos.Payments,
+		Providers:     repos.PaymentProviders,
+		Geocoder:      repos.Geocoder,
+		Router:        repos.Router,
+		FeedbackURL:   repos.FeedbackURL,
+	}
+}
 
-	authService := service.NewAuthService(userRepo, tokenRepo)
-	driverService := &service.DriverService{Repo: driverRepo}
-	bookingService := &service.BookingService{Repo: bookingRepo}
-	popularService := &service.PopularDestinationService{Repo: popularRepo}
-	carService := service.NewCarService(carRepo)
-	paymentService := service.NewPaymentService(paymentRepo)
+func newPaymentService(repos *repositories) *service.PaymentService {
+	return &service.PaymentService{
+		Repo:           repos.Payments,
+		Customers:      repos.Customers,
+		Notifications:  repos.Notifications,
+		Bookings:       repos.Bookings,
+		Cancellations:  repos.Cancellations,
+		Providers:      repos.PaymentProviders,
+		CallbackSecret: repos.PaymentCallbackSecret,
+	}
+}
+
+func newDispatchService(repos *repositories) *service.DispatchService {
+	s := service.NewDispatchService(repos.Dispatch, repos.Bookings, repos.Drivers, repos.Availability, dispatchOfferTimeout)
+	s.Customers = repos.Customers
+	s.Notifications = repos.Notifications
+	s.Router = repos.Router
+	return s
+}
+
+func SetupRouter(repos *repositories) *gin.Engine {
+
+	authService := service.NewAuthService(repos.Users, repos.Tokens)
+	driverService := &service.DriverService{Repo: repos.Drivers}
+	bookingService := newBookingService(repos)
+	popularService := &service.PopularDestinationService{Repo: repos.Popular}
+	carService := service.NewCarService(repos.Cars)
+	paymentService := newPaymentService(repos)
 
 	authHandler := &handler.AuthHandler{AuthService: authService}
 	driverHandler := &handler.DriverHandler{Service: driverService}
@@ -35,115 +74,251 @@ func SetupRouter(db *sql.DB) *gin.Engine {
 	carHandler := handler.NewCarHandler(carService)
 	paymentHandler := handler.PaymentHandler{Service: paymentService}
 
-	maintenanceRepo := repository.NewMaintenanceRepository(db)
-	maintenanceService := service.NewMaintenanceService(maintenanceRepo)
+	maintenanceService := service.NewMaintenanceService(repos.Maintenance)
 	maintenanceHandler := handler.NewMaintenanceHandler(maintenanceService)
 
-	assignmentRepo := repository.NewAssignmentsRepository(db)
-	assignmentService := service.NewAssignmentsService(assignmentRepo)
+	assignmentService := service.NewAssignmentsService(repos.Assignments)
 	assignmentHandler := handler.NewAssignmentHandler(assignmentService)
 
-	tripRepo := repository.NewTripRepo(db)
-	tripService := service.NewTripService(tripRepo)
+	tripService := service.NewTripService(repos.Trips)
+	tripService.Router = repos.Router
 	tripHandler := handler.NewTripHandler(tripService)
 
-	tripHistoryRepo := repository.NewTripHistoryRepository(db)
-	tripHistoryService := service.NewTripHistoryService(tripHistoryRepo)
+	tripHistoryService := service.NewTripHistoryService(repos.TripHistory)
 
 	tripHistoryHandler := handler.NewTripHistoryHandler(tripHistoryService)
 
-	bookingTrendsRepo := repository.NewBookingTrendsRepository(db)
-	bookingTrendsService := service.NewBookingTrendsService(bookingTrendsRepo)
+	bookingTrendsService := service.NewBookingTrendsService(repos.BookingTrends)
 	bookingTrendsHandler := handler.NewBookingTrendsHandler(bookingTrendsService)
 
-	pdfRepo := repository.NewPDFRepository(db)
+	templates := &service.HTMLTemplateRenderer{Dir: "templates"}
 	pdfService := &service.PDFService{
-		TripRepo:     pdfRepo,
-		PDFGenerator: &service.DefaultPDFGenerator{},
+		TripRepo:         repos.PDF,
+		PDFGenerator:     &service.DefaultPDFGenerator{},
+		TemplateRenderer: templates,
+		InvoiceRenderer:  templates,
 	}
 	pdfHandler := handler.NewPDFHandler(pdfService)
 
-	dashboardRepo := repository.NewDashboardRepository(db)
-	dashboardService := service.NewDashboardService(dashboardRepo)
+	corporateService := service.NewCorporateService(repos.Corporate)
+	corporateService.PDF = pdfService
+	corporateHandler := handler.NewCorporateHandler(corporateService)
+
+	dashboardService := service.NewDashboardService(repos.Dashboard)
 	dashboardHandler := handler.NewDashboardHandler(dashboardService)
 
-	dashboardTripRepo := repository.NewDashboardTripRepository(db)
-	dashboardTripService := service.NewDashboardTripService(dashboardTripRepo)
+	dashboardTripService := service.NewDashboardTripService(repos.DashboardTrip)
 	dashboardTripHandler := handler.NewDashboardTripHandler(dashboardTripService)
 
-	carTypeRepo := repository.NewCarTypeRepository(db)
-	carModelRepo := repository.NewCarModelRepository(db)
-
-	carTypeService := service.NewCarTypeService(carTypeRepo)
-	carModelService := service.NewCarModelService(carModelRepo)
+	carTypeService := service.NewCarTypeService(repos.CarTypes)
+	carModelService := service.NewCarModelService(repos.CarModels)
 
 	carTypeHandler := handler.NewCarTypeHandler(carTypeService)
 	carModelHandler := handler.NewCarModelRepositoryHandler(carModelService)
 
+	idempotencyService := service.NewIdempotencyService(repos.Idempotency, idempotencyRetention)
+	idempotent := handler.IdempotencyMiddleware(idempotencyService)
+
+	trashService := service.NewTrashService(repos.Drivers, repos.Cars, repos.Bookings, repos.Payments, trashRetention)
+	trashHandler := handler.NewTrashHandler(trashService)
+
+	availabilityService := service.NewAvailabilityService(repos.Availability, repos.Drivers)
+	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
+
+	dispatchService := newDispatchService(repos)
+	dispatchHandler := handler.NewDispatchHandler(dispatchService)
+
+	pricingService := service.NewPricingService(repos.Pricing, repos.Promotions, quoteValidity)
+	pricingService.Router = repos.Router
+	pricingHandler := handler.NewPricingHandler(pricingService)
+
+	promotionService := service.NewPromotionService(repos.Promotions)
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+
+	cancellationService := service.NewCancellationService(repos.Cancellations)
+	cancellationHandler := handler.NewCancellationHandler(cancellationService)
+
+	recurringService := service.NewRecurringService(repos.Recurring, bookingService, recurringDaysAhead)
+	recurringHandler := handler.NewRecurringBookingHandler(recurringService)
+
+	notificationService := service.NewNotificationService(repos.Notifications, repos.Customers, repos.Drivers)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+
+	customerService := service.NewCustomerService(repos.Customers)
+	customerHandler := handler.NewCustomerHandler(customerService)
+
+	tenantService := service.NewTenantService(repos.Tenants)
+	tenantHandler := handler.NewTenantHandler(tenantService)
+
+	feedbackService := service.NewFeedbackService(repos.Feedback, repos.Bookings, repos.Trips, repos.Drivers)
+	feedbackService.AlertEmail = repos.RatingAlertEmail
+	feedbackHandler := handler.NewFeedbackHandler(feedbackService)
+
+	calendarHandler := handler.NewCalendarHandler(service.NewCalendarService(repos.Calendar))
+
+	geocodeHandler := handler.NewGeocodeHandler(repos.Geocoder)
+	routeHandler := handler.NewRouteHandler(repos.Router)
+
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match", handler.IdempotencyKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	r.POST("/login", authHandler.Login)
 	r.POST("/refresh", authHandler.Refresh)
+	r.GET("/feedback/:token", feedbackHandler.GetForm)
+	r.POST("/feedback/:token", feedbackHandler.SubmitForm)
+	r.GET("/calendar/:token", calendarHandler.Serve)
+	r.POST("/payments/callback", paymentHandler.Callback)
+
+	api := r.Group("", handler.AuthMiddleware())
+
+	api.GET("/drivers", driverHandler.GetAll)
+	api.POST("/drivers", driverHandler.Create)
+	api.GET("/drivers/:id", driverHandler.GetByID)
+	api.PUT("/drivers/:id", driverHandler.Update)
+	api.DELETE("/drivers/:id", driverHandler.Delete)
+	api.GET("/drivers/:id/availability", availabilityHandler.GetDriverAvailability)
+	api.POST("/drivers/:id/leave", availabilityHandler.AddLeave)
+	api.DELETE("/drivers/:id/leave/:leave_id", availabilityHandler.DeleteLeave)
+	api.PUT("/drivers/:id/location", dispatchHandler.SetLocation)
+	api.GET("/drivers/:id/feedback", feedbackHandler.GetByDriver)
+
+	api.POST("/booking", idempotent, bookingHandler.Create)
+	api.GET("/booking", bookingHandler.GetAll)
+	api.GET("/booking/:id", bookingHandler.GetByID)
+	api.GET("/booking/by-code/:code", bookingHandler.GetByCode)
+	api.GET("/booking/nearby", bookingHandler.Nearby)
+	api.GET("/booking/within", bookingHandler.Within)
+	api.GET("/geocode", geocodeHandler.Geocode)
+	api.GET("/route", routeHandler.Route)
+	api.PUT("/booking/:id", bookingHandler.Update)
+	api.DELETE("/booking/:id", bookingHandler.Delete)
+	api.POST("/booking/:id/transitions", bookingHandler.Transition)
+	api.GET("/booking/:id/history", bookingHandler.GetStatusHistory)
+	api.PUT("/booking/:id/stops/:position", bookingHandler.UpdateStop)
+	api.POST("/booking/:id/cancel", bookingHandler.Cancel)
+	api.GET("/booking/:id/cancellation", bookingHandler.GetCancellation)
+	api.GET("/booking/:id/payments", paymentHandler.GetByBooking)
+	api.GET("/booking/:id/trip", bookingHandler.GetTrip)
+	api.GET("/booking/:id/feedback", feedbackHandler.GetByBooking)
+	api.GET("/cancellation-policy", cancellationHandler.GetPolicy)
+
+	api.GET("/recurring-bookings", recurringHandler.GetAll)
+	api.POST("/recurring-bookings", idempotent, recurringHandler.Create)
+	api.GET("/recurring-bookings/:id", recurringHandler.GetByID)
+	api.PUT("/recurring-bookings/:id", recurringHandler.Update)
+	api.POST("/recurring-bookings/:id/cancel", recurringHandler.Cancel)
+	api.PUT("/recurring-bookings/:id/occurrences/:date", recurringHandler.UpdateOccurrence)
+	api.DELETE("/recurring-bookings/:id/occurrences/:date", recurringHandler.SkipOccurrence)
+
+	api.POST("/dispatch/offers/:id/accept", dispatchHandler.Accept)
+	api.POST("/dispatch/offers/:id/decline", dispatchHandler.Decline)
+
+	api.GET("/tariffs", pricingHandler.GetTariffs)
+	api.POST("/quotes", idempotent, pricingHandler.CreateQuote)
+	api.GET("/quotes/:id", pricingHandler.GetQuote)
+
+	api.GET("/customers", customerHandler.Search)
+	api.POST("/customers", customerHandler.Create)
+	api.GET("/customers/:id", customerHandler.GetDetail)
+	api.PUT("/customers/:id", customerHandler.Update)
+	api.DELETE("/customers/:id", customerHandler.Delete)
+	api.GET("/customers/:id/notification-preferences", notificationHandler.GetPreference)
+	api.PUT("/customers/:id/notification-preferences", notificationHandler.SavePreference)
+
+	api.GET("/car", carHandler.GetAll)
+	api.GET("/car/:id", carHandler.GetByID)
+	api.POST("/car", carHandler.Create)
+	api.PUT("/car/:id", carHandler.Update)
+	api.DELETE("/car/:id", carHandler.Delete)
+
+	api.GET("/payments", paymentHandler.GetPayments)
+	api.GET("/paymentsStats", paymentHandler.GetPaymentStats)
+	api.GET("/payments/:id", paymentHandler.GetPaymentByID)
+	api.POST("/payments", idempotent, paymentHandler.CreatePayment)
+	api.POST("/payments/:id/refresh", paymentHandler.RefreshPayment)
+	api.GET("/payments/:id/refunds", paymentHandler.GetRefunds)
+	api.PUT("/payments/:id", paymentHandler.UpdatePayment)
+	api.DELETE("/payments/:id", paymentHandler.DeletePayment)
+
+	api.GET("/assignments/:vehicle_id", assignmentHandler.FindByVehicle)
+	api.POST("/assignments", assignmentHandler.Create)
+	api.PUT("/assignments/:id", assignmentHandler.Update)
+	api.DELETE("/assignments/:id", assignmentHandler.Delete)
+
+	api.POST("/maintenance", maintenanceHandler.Create)
+	api.GET("/maintenance/vehicle/:vehicle_id", maintenanceHandler.FindByVehicle)
+	api.PUT("/maintenance/:id", maintenanceHandler.Update)
+	api.DELETE("/maintenance/:id", maintenanceHandler.Delete)
+
+	api.GET("/trips/vehicle/:vehicle_id", tripHandler.FindByVehicle)
+	api.POST("/trips", tripHandler.Create)
+	api.PUT("/trips/:id", tripHandler.Update)
+	api.DELETE("/trips/:id", tripHandler.Delete)
+
+	api.GET("/tripsStats", tripHandler.GetTripTotal)
+	api.GET("/booking-trends", bookingTrendsHandler.GetTrends)
+	api.GET("/trip-history", tripHistoryHandler.GetTripHistory)
+	api.GET("/chart", popularHandler.GetAll)
+
+	api.GET("/car-types", carTypeHandler.GetAll)
+	api.GET("/car-models", carModelHandler.GetAll)
+	api.GET("/car-models/:id", carModelHandler.GetByID)
+	api.GET("/car-types/:id", carTypeHandler.GetByID)
+
+	admin := api.Group("", handler.RequireAdmin())
+	admin.GET("/trash/:entity", trashHandler.List)
+	admin.POST("/drivers/:id/restore", trashHandler.Restore(service.TrashDrivers))
+	admin.POST("/car/:id/restore", trashHandler.Restore(service.TrashCars))
+	admin.POST("/booking/:id/restore", trashHandler.Restore(service.TrashBookings))
+	admin.POST("/payments/:id/restore", trashHandler.Restore(service.TrashPayments))
+	admin.GET("/booking/:id/dispatch/candidates", dispatchHandler.Candidates)
+	admin.POST("/booking/:id/dispatch", dispatchHandler.Dispatch)
+	admin.GET("/booking/:id/dispatch/offers", dispatchHandler.GetOffers)
+	admin.PUT("/tariffs/:car_type_id", pricingHandler.SaveTariff)
+	admin.PUT("/cancellation-policy", cancellationHandler.SavePolicy)
+	admin.GET("/promotions", promotionHandler.GetAll)
+	admin.POST("/promotions", promotionHandler.Create)
+	admin.GET("/promotions/:id", promotionHandler.GetByID)
+	admin.PUT("/promotions/:id", promotionHandler.Update)
+	admin.GET("/promotions/:id/redemptions", promotionHandler.GetRedemptions)
+	admin.GET("/promotion-report", promotionHandler.Report)
+	admin.GET("/notifications", notificationHandler.GetOutbox)
+	admin.POST("/booking/:id/feedback", feedbackHandler.Submit)
+	admin.GET("/driver-alerts", feedbackHandler.GetAlerts)
+	admin.POST("/driver-alerts/:id/ack", feedbackHandler.AcknowledgeAlert)
+	admin.GET("/corporate-accounts", corporateHandler.GetAccounts)
+	admin.POST("/corporate-accounts", corporateHandler.CreateAccount)
+	admin.GET("/corporate-accounts/:id", corporateHandler.GetAccount)
+	admin.PUT("/corporate-accounts/:id", corporateHandler.UpdateAccount)
+	admin.GET("/corporate-accounts/:id/balance", corporateHandler.GetBalance)
+	admin.GET("/corporate-accounts/:id/invoices", corporateHandler.GetInvoices)
+	admin.POST("/corporate-accounts/:id/invoices", corporateHandler.CreateInvoice)
+	admin.GET("/invoices/:id", corporateHandler.GetInvoice)
+	admin.GET("/invoices/:id/pdf", corporateHandler.InvoicePDF)
+	admin.POST("/invoices/:id/payments", idempotent, corporateHandler.RecordPayment)
+	admin.POST("/payments/:id/refunds", idempotent, paymentHandler.RefundPayment)
+	admin.GET("/drivers/:id/calendar-feed", calendarHandler.GetFeed(model.CalendarDriver))
+	admin.POST("/drivers/:id/calendar-feed", calendarHandler.IssueFeed(model.CalendarDriver))
+	admin.DELETE("/drivers/:id/calendar-feed", calendarHandler.RevokeFeed(model.CalendarDriver))
+	admin.GET("/car/:id/calendar-feed", calendarHandler.GetFeed(model.CalendarVehicle))
+	admin.POST("/car/:id/calendar-feed", calendarHandler.IssueFeed(model.CalendarVehicle))
+	admin.DELETE("/car/:id/calendar-feed", calendarHandler.RevokeFeed(model.CalendarVehicle))
+
+	superAdmin := api.Group("/tenants", handler.RequireSuperAdmin())
+	superAdmin.GET("", tenantHandler.GetAll)
+	superAdmin.POST("", tenantHandler.Create)
+	superAdmin.POST("/switch", tenantHandler.Switch)
 
-	r.GET("/drivers", driverHandler.GetAll)
-	r.POST("/drivers", driverHandler.Create)
-	r.GET("/drivers/:id", driverHandler.GetByID)
-	r.PUT("/drivers/:id", driverHandler.Update)
-	r.DELETE("/drivers/:id", driverHandler.Delete)
-
-	r.POST("/booking", bookingHandler.Create)
-	r.GET("/booking", bookingHandler.GetAll)
-	r.DELETE("/booking/:id", bookingHandler.Delete)
-
-	r.GET("/car", carHandler.GetAll)
-	r.GET("/car/:id", carHandler.GetByID)
-	r.POST("/car", carHandler.Create)
-	r.PUT("/car/:id", carHandler.Update)
-	r.DELETE("/car/:id", carHandler.Delete)
-
-	r.GET("/payments", paymentHandler.GetPayments)
-	r.GET("/paymentsStats", paymentHandler.GetPaymentStats)
-	r.GET("/payments/:id", paymentHandler.GetPaymentByID)
-	r.POST("/payments", paymentHandler.CreatePayment)
-	r.PUT("/payments/:id", paymentHandler.UpdatePayment)
-	r.DELETE("/payments/:id", paymentHandler.DeletePayment)
-
-	r.GET("/assignments/:vehicle_id", assignmentHandler.FindByVehicle)
-	r.POST("/assignments", assignmentHandler.Create)
-	r.PUT("/assignments/:id", assignmentHandler.Update)
-	r.DELETE("/assignments/:id", assignmentHandler.Delete)
-
-	r.POST("/maintenance", maintenanceHandler.Create)
-	r.GET("/maintenance/vehicle/:vehicle_id", maintenanceHandler.FindByVehicle)
-	r.PUT("/maintenance/:id", maintenanceHandler.Update)
-	r.DELETE("/maintenance/:id", maintenanceHandler.Delete)
-
-	r.GET("/trips/vehicle/:vehicle_id", tripHandler.FindByVehicle)
-	r.POST("/trips", tripHandler.Create)
-	r.PUT("/trips/:id", tripHandler.Update)
-	r.DELETE("/trips/:id", tripHandler.Delete)
-
-	r.GET("/tripsStats", tripHandler.GetTripTotal)
-	r.GET("/booking-trends", bookingTrendsHandler.GetTrends)
-	r.GET("/trip-history", tripHistoryHandler.GetTripHistory)
-	r.GET("/chart", popularHandler.GetAll)
-
-	r.GET("/car-types", carTypeHandler.GetAll)
-	r.GET("/car-models", carModelHandler.GetAll)
-	r.GET("/car-models/:id", carModelHandler.GetByID)
-	r.GET("/car-types/:id", carTypeHandler.GetByID)
-
-	r.GET("/receipt/:trip_id", pdfHandler.HandlePDFReceipt)
-	r.GET("/api/dashboard", dashboardHandler.GetDashboard)
-	r.GET("/api/dashboardtrip/:id", dashboardTripHandler.GetDashboard)
+	api.GET("/receipt/:trip_id", pdfHandler.HandlePDFReceipt)
+	api.GET("/api/dashboard", dashboardHandler.GetDashboard)
+	api.GET("/api/dashboardtrip/:id", dashboardTripHandler.GetDashboard)
 
 	return r
 }
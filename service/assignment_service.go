@@ -3,13 +3,14 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type AssignmentsServiceInterface interface {
-	Create(*model.DriverAssignment) error
-	Update(*model.DriverAssignment) error
-	Delete(uint) error
-	FindByVehicle(uint) ([]model.DriverAssignment, error)
+	Create(context.Context, *model.DriverAssignment) error
+	Update(context.Context, *model.DriverAssignment) error
+	Delete(context.Context, uint) error
+	FindByVehicle(context.Context, uint) ([]model.DriverAssignment, error)
 }
 
 type AssignmentsService struct {
@@ -20,18 +21,18 @@ func NewAssignmentsService(repo repository.AssignmentsRepositoryInterface) *Assi
 	return &AssignmentsService{Repo: repo}
 }
 
-func (s *AssignmentsService) Create(a *model.DriverAssignment) error {
-	return s.Repo.Create(a)
+func (s *AssignmentsService) Create(ctx context.Context, a *model.DriverAssignment) error {
+	return s.Repo.Create(ctx, a)
 }
 
-func (s *AssignmentsService) FindByVehicle(vehicleID uint) ([]model.DriverAssignment, error) {
-	return s.Repo.FindByVehicle(vehicleID)
+func (s *AssignmentsService) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.DriverAssignment, error) {
+	return s.Repo.FindByVehicle(ctx, vehicleID)
 }
 
-func (s *AssignmentsService) Update(a *model.DriverAssignment) error {
-	return s.Repo.Update(a)
+func (s *AssignmentsService) Update(ctx context.Context, a *model.DriverAssignment) error {
+	return s.Repo.Update(ctx, a)
 }
 
-func (s *AssignmentsService) Delete(id uint) error {
-	return s.Repo.Delete(id)
+func (s *AssignmentsService) Delete(ctx context.Context, id uint) error {
+	return s.Repo.Delete(ctx, id)
 }
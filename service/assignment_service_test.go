@@ -3,6 +3,7 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"database/sql"
 	"testing"
 
@@ -14,22 +15,22 @@ type MockAssignmentRepo struct {
 	mock.Mock
 }
 
-func (m *MockAssignmentRepo) Create(a *model.DriverAssignment) error {
+func (m *MockAssignmentRepo) Create(ctx context.Context, a *model.DriverAssignment) error {
 	args := m.Called(a)
 	return args.Error(0)
 }
 
-func (m *MockAssignmentRepo) FindByVehicle(vehicleID uint) ([]model.DriverAssignment, error) {
+func (m *MockAssignmentRepo) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.DriverAssignment, error) {
 	args := m.Called(vehicleID)
 	return args.Get(0).([]model.DriverAssignment), args.Error(1)
 }
 
-func (m *MockAssignmentRepo) Update(a *model.DriverAssignment) error {
+func (m *MockAssignmentRepo) Update(ctx context.Context, a *model.DriverAssignment) error {
 	args := m.Called(a)
 	return args.Error(0)
 }
 
-func (m *MockAssignmentRepo) Delete(id uint) error {
+func (m *MockAssignmentRepo) Delete(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -41,7 +42,7 @@ func TestAssignmentsService_Create(t *testing.T) {
 	assignment := &model.DriverAssignment{ID: 1, VehicleID: 1, DriverID: sql.NullInt64{Int64: 1, Valid: true}}
 	mockRepo.On("Create", assignment).Return(nil)
 
-	err := svc.Create(assignment)
+	err := svc.Create(context.Background(), assignment)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -51,7 +52,7 @@ func TestAssignmentsService_Update(t *testing.T) {
 	svc := service.NewAssignmentsService(mockRepo)
 	assignment := &model.DriverAssignment{ID: 1, VehicleID: 1, DriverID: sql.NullInt64{Int64: 1, Valid: true}}
 	mockRepo.On("Update", assignment).Return(nil)
-	err := svc.Update(assignment)
+	err := svc.Update(context.Background(), assignment)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -66,7 +67,7 @@ func TestAssignmentsService_FindByVehicle(t *testing.T) {
 
 	mockRepo.On("FindByVehicle", uint(1)).Return(expected, nil)
 
-	result, err := svc.FindByVehicle(1)
+	result, err := svc.FindByVehicle(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -78,7 +79,7 @@ func TestAssignmentsService_Delete(t *testing.T) {
 
 	mockRepo.On("Delete", uint(1)).Return(nil)
 
-	err := svc.Delete(1)
+	err := svc.Delete(context.Background(), 1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -4,6 +4,7 @@ import (
 	"auth-service/model"
 	"auth-service/repository"
 	"auth-service/utils"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -12,7 +13,7 @@ import (
 )
 
 type AuthServiceInterface interface {
-	Register(username, password, role string) error
+	Register(ctx context.Context, username, password, role string) error
 	Login(username, password string) (string, string, error)
 	RefreshToken(refreshToken string) (string, error)
 }
@@ -20,7 +21,7 @@ type AuthServiceInterface interface {
 type AuthService struct {
 	UserRepo            repository.UserRepository
 	TokenRepo           repository.TokenRepository
-	GenerateAccessToken func(userID int64, role string) (string, error)
+	GenerateAccessToken func(userID, tenantID int64, role string, driverID *int) (string, error)
 	HashPasswordFn      func(string) (string, error)
 }
 
@@ -36,13 +37,19 @@ func NewAuthService(
 	}
 }
 
-func (s *AuthService) Register(username, password, role string) error {
+func (s *AuthService) Register(ctx context.Context, username, password, role string) error {
+	tenantID, ok := utils.TenantFromContext(ctx)
+	if !ok {
+		return ErrNoTenant
+	}
+
 	hashedPassword, err := s.HashPasswordFn(password) // <- pakai yang di-inject
 	if err != nil {
 		return err
 	}
 
 	user := &model.User{
+		TenantID: tenantID,
 		Username: username,
 		Password: hashedPassword,
 		Role:     role,
@@ -66,7 +73,7 @@ func (s *AuthService) Login(username, password string) (string, string, error) {
 		return "", "", errors.New("invalid credentials password")
 	}
 
-	accessToken, err := s.GenerateAccessToken(user.ID, user.Role)
+	accessToken, err := s.GenerateAccessToken(user.ID, user.TenantID, user.Role, user.DriverID)
 	if err != nil {
 		return "", "", err
 	}
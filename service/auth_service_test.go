@@ -3,6 +3,7 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/utils"
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -84,6 +85,7 @@ func TestAuthService_Register_Success(t *testing.T) {
 			assert.Equal(t, "admin", user.Username)
 			assert.NotEmpty(t, user.Password)
 			assert.Equal(t, "ADMIN", user.Role)
+			assert.Equal(t, int64(1), user.TenantID)
 			return nil
 		},
 	}
@@ -93,7 +95,7 @@ func TestAuthService_Register_Success(t *testing.T) {
 		HashPasswordFn: utils.HashPassword,
 	}
 
-	err := service.Register("admin", "password123", "ADMIN")
+	err := service.Register(utils.WithTenant(context.Background(), 1), "admin", "password123", "ADMIN")
 	assert.NoError(t, err)
 }
 
@@ -107,7 +109,7 @@ func TestAuthService_Register_HashPasswordError(t *testing.T) {
 		},
 	}
 
-	err := service.Register("admin", "password123", "ADMIN")
+	err := service.Register(utils.WithTenant(context.Background(), 1), "admin", "password123", "ADMIN")
 	assert.EqualError(t, err, "hash error")
 }
 
@@ -123,7 +125,7 @@ func TestAuthService_Register_SaveUserError(t *testing.T) {
 		HashPasswordFn: utils.HashPassword,
 	}
 
-	err := service.Register("admin", "password123", "ADMIN")
+	err := service.Register(utils.WithTenant(context.Background(), 1), "admin", "password123", "ADMIN")
 	assert.EqualError(t, err, "db error")
 }
 
@@ -182,7 +184,7 @@ func TestAuthService_Login_GenerateAccessTokenError(t *testing.T) {
 	tokenRepo := &MockTokenRepo{}
 
 	service := NewAuthService(userRepo, tokenRepo)
-	service.GenerateAccessToken = func(userID int64, role string) (string, error) {
+	service.GenerateAccessToken = func(userID, tenantID int64, role string, driverID *int) (string, error) {
 		return "", errors.New("token generation error")
 	}
 
@@ -225,3 +227,13 @@ func TestAuthService_RefreshToken_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "NEW_ACCESS_TOKEN", access)
 }
+
+func TestAuthService_Register_NoTenant(t *testing.T) {
+	service := &AuthService{
+		UserRepo:       &MockUserRepo{},
+		HashPasswordFn: utils.HashPassword,
+	}
+
+	err := service.Register(context.Background(), "admin", "password123", "ADMIN")
+	assert.ErrorIs(t, err, ErrNoTenant)
+}
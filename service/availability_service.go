@@ -0,0 +1,147 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const maxAvailabilityRange = 31 * 24 * time.Hour
+
+var (
+	ErrMissingWindow      = errors.New("start_at and end_at are required when a driver or vehicle is assigned")
+	ErrInvalidWindow      = errors.New("end_at must be after start_at")
+	ErrAvailabilityRange  = errors.New("availability range may not exceed 31 days")
+	ErrUnknownDriver      = errors.New("driver not found")
+	ErrDriverUnavailable  = errors.New("driver is not available for the requested time")
+	ErrVehicleUnavailable = errors.New("vehicle is not available for the requested time")
+)
+
+type AvailabilityServiceInterface interface {
+	GetDriverAvailability(ctx context.Context, driverID int, from, to time.Time) (*model.DriverAvailability, error)
+	AddLeave(ctx context.Context, l *model.DriverLeave) error
+	DeleteLeave(ctx context.Context, driverID, id int) error
+}
+
+type AvailabilityService struct {
+	Repo    repository.AvailabilityRepositoryInterface
+	Drivers repository.DriverRepositoryInterface
+}
+
+func NewAvailabilityService(repo repository.AvailabilityRepositoryInterface, drivers repository.DriverRepositoryInterface) *AvailabilityService {
+	return &AvailabilityService{Repo: repo, Drivers: drivers}
+}
+
+// lookupDriver hides the driver repository's sql.ErrNoRows behind ErrUnknownDriver.
+func lookupDriver(ctx context.Context, repo repository.DriverRepositoryInterface, id int) (*model.Driver, error) {
+	d, err := repo.GetByID(ctx, strconv.Itoa(id))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && d == nil) {
+		return nil, ErrUnknownDriver
+	}
+	return d, err
+}
+
+// conflicting returns the first block that is not the booking itself, so a
+// booking being edited never collides with its own slot.
+func conflicting(blocks []model.BusyBlock, bookingID string) *model.BusyBlock {
+	for i := range blocks {
+		if blocks[i].Kind == model.BlockBooking && blocks[i].Ref == bookingID {
+			continue
+		}
+		return &blocks[i]
+	}
+	return nil
+}
+
+// freeSlots subtracts blocks from [from, to). Blocks may overlap each other.
+func freeSlots(from, to time.Time, blocks []model.BusyBlock) []model.TimeSlot {
+	sorted := append([]model.BusyBlock(nil), blocks...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartAt.Before(sorted[j].StartAt) })
+
+	slots := []model.TimeSlot{}
+	cursor := from
+	for _, b := range sorted {
+		if !cursor.Before(to) {
+			return slots
+		}
+		if b.StartAt.After(cursor) {
+			end := b.StartAt
+			if end.After(to) {
+				end = to
+			}
+			slots = append(slots, model.TimeSlot{StartAt: cursor, EndAt: end})
+		}
+		if b.EndAt.After(cursor) {
+			cursor = b.EndAt
+		}
+	}
+	if cursor.Before(to) {
+		slots = append(slots, model.TimeSlot{StartAt: cursor, EndAt: to})
+	}
+	return slots
+}
+
+func (s *AvailabilityService) GetDriverAvailability(ctx context.Context, driverID int, from, to time.Time) (*model.DriverAvailability, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidWindow
+	}
+	if to.Sub(from) > maxAvailabilityRange {
+		return nil, ErrAvailabilityRange
+	}
+
+	if _, err := lookupDriver(ctx, s.Drivers, driverID); err != nil {
+		if errors.Is(err, ErrUnknownDriver) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	blocks, err := s.Repo.DriverBlocks(ctx, driverID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DriverAvailability{
+		DriverID: driverID,
+		From:     from,
+		To:       to,
+		Free:     freeSlots(from, to, blocks),
+		Busy:     blocks,
+	}, nil
+}
+
+// AddLeave refuses leave that would strand an existing booking; the booking has
+// to be reassigned or cancelled first.
+func (s *AvailabilityService) AddLeave(ctx context.Context, l *model.DriverLeave) error {
+	if !l.EndAt.After(l.StartAt) {
+		return ErrInvalidWindow
+	}
+
+	if _, err := lookupDriver(ctx, s.Drivers, l.DriverID); err != nil {
+		if errors.Is(err, ErrUnknownDriver) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	blocks, err := s.Repo.DriverBlocks(ctx, l.DriverID, l.StartAt, l.EndAt)
+	if err != nil {
+		return err
+	}
+	for _, b := range blocks {
+		if b.Kind == model.BlockBooking {
+			return ErrDriverUnavailable
+		}
+	}
+
+	return s.Repo.CreateLeave(ctx, l)
+}
+
+func (s *AvailabilityService) DeleteLeave(ctx context.Context, driverID, id int) error {
+	return s.Repo.DeleteLeave(ctx, driverID, id)
+}
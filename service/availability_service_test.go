@@ -0,0 +1,155 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAvailabilityRepository struct {
+	mock.Mock
+}
+
+func (m *MockAvailabilityRepository) DriverBlocks(ctx context.Context, driverID int, from, to time.Time) ([]model.BusyBlock, error) {
+	args := m.Called(driverID, from, to)
+	return args.Get(0).([]model.BusyBlock), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) VehicleBlocks(ctx context.Context, vehicleID int, from, to time.Time) ([]model.BusyBlock, error) {
+	args := m.Called(vehicleID, from, to)
+	return args.Get(0).([]model.BusyBlock), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) CreateLeave(ctx context.Context, l *model.DriverLeave) error {
+	args := m.Called(l)
+	return args.Error(0)
+}
+
+func (m *MockAvailabilityRepository) DeleteLeave(ctx context.Context, driverID, id int) error {
+	args := m.Called(driverID, id)
+	return args.Error(0)
+}
+
+func at(hour int) time.Time {
+	return time.Date(2024, 5, 1, hour, 0, 0, 0, time.UTC)
+}
+
+func TestAvailabilityService_GetDriverAvailability(t *testing.T) {
+	repo := new(MockAvailabilityRepository)
+	drivers := new(MockDriverRepository)
+	svc := service.NewAvailabilityService(repo, drivers)
+
+	drivers.On("GetByID", "3").Return(&model.Driver{ID: 3, Name: "Budi"}, nil)
+	drivers.On("GetByID", "4").Return((*model.Driver)(nil), sql.ErrNoRows)
+	repo.On("DriverBlocks", 3, at(8), at(18)).Return([]model.BusyBlock{
+		{Kind: model.BlockBooking, Ref: "BK1", StartAt: at(7), EndAt: at(9)},
+		{Kind: model.BlockLeave, Ref: "1", StartAt: at(12), EndAt: at(14)},
+		{Kind: model.BlockBooking, Ref: "BK2", StartAt: at(13), EndAt: at(15)},
+		{Kind: model.BlockBooking, Ref: "BK3", StartAt: at(17), EndAt: at(20)},
+	}, nil)
+
+	availability, err := svc.GetDriverAvailability(context.Background(), 3, at(8), at(18))
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TimeSlot{
+		{StartAt: at(9), EndAt: at(12)},
+		{StartAt: at(15), EndAt: at(17)},
+	}, availability.Free)
+	assert.Len(t, availability.Busy, 4)
+
+	_, err = svc.GetDriverAvailability(context.Background(), 4, at(8), at(18))
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	_, err = svc.GetDriverAvailability(context.Background(), 3, at(18), at(8))
+	assert.ErrorIs(t, err, service.ErrInvalidWindow)
+
+	_, err = svc.GetDriverAvailability(context.Background(), 3, at(8), at(8).AddDate(0, 2, 0))
+	assert.ErrorIs(t, err, service.ErrAvailabilityRange)
+}
+
+func TestAvailabilityService_AddLeave(t *testing.T) {
+	repo := new(MockAvailabilityRepository)
+	drivers := new(MockDriverRepository)
+	svc := service.NewAvailabilityService(repo, drivers)
+
+	drivers.On("GetByID", "3").Return(&model.Driver{ID: 3}, nil)
+	repo.On("DriverBlocks", 3, at(8), at(10)).Return([]model.BusyBlock{
+		{Kind: model.BlockMaintenance, Ref: "2", StartAt: at(0), EndAt: at(24)},
+	}, nil)
+	repo.On("DriverBlocks", 3, at(12), at(14)).Return([]model.BusyBlock{
+		{Kind: model.BlockBooking, Ref: "BK1", StartAt: at(13), EndAt: at(15)},
+	}, nil)
+	repo.On("CreateLeave", mock.Anything).Return(nil)
+
+	assert.NoError(t, svc.AddLeave(context.Background(), &model.DriverLeave{DriverID: 3, StartAt: at(8), EndAt: at(10)}))
+
+	err := svc.AddLeave(context.Background(), &model.DriverLeave{DriverID: 3, StartAt: at(12), EndAt: at(14)})
+	assert.ErrorIs(t, err, service.ErrDriverUnavailable)
+
+	err = svc.AddLeave(context.Background(), &model.DriverLeave{DriverID: 3, StartAt: at(10), EndAt: at(10)})
+	assert.ErrorIs(t, err, service.ErrInvalidWindow)
+
+	repo.AssertNumberOfCalls(t, "CreateLeave", 1)
+}
+
+func TestBookingService_CreateChecksAvailability(t *testing.T) {
+	bookings := new(MockBookingRepository)
+	drivers := new(MockDriverRepository)
+	availability := new(MockAvailabilityRepository)
+	svc := &service.BookingService{Repo: bookings, Drivers: drivers, Availability: availability}
+
+	driverID, vehicleID := 3, 9
+	start, end := at(8), at(10)
+
+	drivers.On("GetByID", "3").Return(&model.Driver{ID: 3, Name: "Budi"}, nil)
+	availability.On("DriverBlocks", 3, start, end).Return([]model.BusyBlock{}, nil).Once()
+	availability.On("VehicleBlocks", 9, start, end).Return([]model.BusyBlock{}, nil).Once()
+	bookings.On("Create", mock.Anything).Return(nil)
+
+	b := &model.Booking{DriverID: &driverID, VehicleID: &vehicleID, StartAt: &start, EndAt: &end}
+	assert.NoError(t, svc.Create(context.Background(), b))
+	assert.Equal(t, "Budi", b.Driver)
+
+	availability.On("DriverBlocks", 3, start, end).Return([]model.BusyBlock{
+		{Kind: model.BlockLeave, Ref: "1", StartAt: at(9), EndAt: at(12)},
+	}, nil).Once()
+	err := svc.Create(context.Background(), &model.Booking{DriverID: &driverID, StartAt: &start, EndAt: &end})
+	assert.ErrorIs(t, err, service.ErrDriverUnavailable)
+
+	availability.On("VehicleBlocks", 9, start, end).Return([]model.BusyBlock{
+		{Kind: model.BlockMaintenance, Ref: "4", StartAt: at(0), EndAt: at(24)},
+	}, nil).Once()
+	err = svc.Create(context.Background(), &model.Booking{VehicleID: &vehicleID, StartAt: &start, EndAt: &end})
+	assert.ErrorIs(t, err, service.ErrVehicleUnavailable)
+
+	err = svc.Create(context.Background(), &model.Booking{DriverID: &driverID})
+	assert.ErrorIs(t, err, service.ErrMissingWindow)
+
+	err = svc.Create(context.Background(), &model.Booking{StartAt: &end, EndAt: &start})
+	assert.ErrorIs(t, err, service.ErrInvalidWindow)
+
+	bookings.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestBookingService_UpdateIgnoresOwnSlot(t *testing.T) {
+	bookings := new(MockBookingRepository)
+	availability := new(MockAvailabilityRepository)
+	svc := &service.BookingService{Repo: bookings, Availability: availability}
+
+	driverID := 3
+	start, end := at(8), at(11)
+
+	bookings.On("GetByID", "BK1").Return(&model.Booking{ID: "BK1", Status: model.BookingConfirmed}, nil)
+	availability.On("DriverBlocks", 3, start, end).Return([]model.BusyBlock{
+		{Kind: model.BlockBooking, Ref: "BK1", StartAt: at(8), EndAt: at(10)},
+	}, nil)
+	bookings.On("Update", mock.Anything).Return(nil)
+
+	err := svc.Update(context.Background(), &model.Booking{ID: "BK1", DriverID: &driverID, StartAt: &start, EndAt: &end})
+	assert.NoError(t, err)
+}
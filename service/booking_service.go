@@ -3,31 +3,824 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidStatus       = errors.New("invalid booking status")
+	ErrInvalidTransition   = errors.New("booking status transition not allowed")
+	ErrTransitionForbidden = errors.New("role may not perform this booking transition")
+	ErrStatusChange        = errors.New("booking status can only be changed through transitions")
+	ErrInvalidBookingCode  = errors.New("invalid booking code")
+	ErrInvalidRadius       = errors.New("radius must be greater than zero")
+	ErrInvalidStop         = errors.New("stop status change not allowed")
 )
 
 type BookingServiceInterface interface {
-	Create(*model.Booking) error
-	GetAll() ([]model.Booking, error)
-	Update(*model.Booking) error
-	Delete(id string) error
+	Create(ctx context.Context, b *model.Booking) error
+	GetAll(ctx context.Context) ([]model.Booking, error)
+	GetByID(ctx context.Context, id string) (*model.Booking, error)
+	GetByCode(ctx context.Context, code string) (*model.Booking, error)
+	Update(ctx context.Context, b *model.Booking) error
+	Delete(ctx context.Context, id string, version int) error
+	Transition(ctx context.Context, id string, user *model.User, t model.BookingTransition) (*model.Booking, error)
+	GetStatusHistory(ctx context.Context, id string) ([]model.BookingStatusChange, error)
+	Cancel(ctx context.Context, id string, user *model.User, reason string) (*model.Booking, *model.BookingCancellation, error)
+	GetCancellation(ctx context.Context, id string) (*model.BookingCancellation, error)
+	GetTrip(ctx context.Context, id string) (*model.VehicleTrip, error)
+	Nearby(ctx context.Context, lat, lng, radiusKM float64) ([]model.NearbyBooking, error)
+	Within(ctx context.Context, box model.GeoBox) ([]model.Booking, error)
+	UpdateStop(ctx context.Context, id string, position int, user *model.User, u model.StopUpdate) (*model.Booking, error)
 }
 
 type BookingService struct {
-	Repo repository.BookingRepositoryInterface
+	Repo          repository.BookingRepositoryInterface
+	Customers     repository.CustomerRepositoryInterface
+	Drivers       repository.DriverRepositoryInterface
+	Availability  repository.AvailabilityRepositoryInterface
+	Pricing       repository.PricingRepositoryInterface
+	Promotions    repository.PromotionRepositoryInterface
+	Cancellations repository.CancellationRepositoryInterface
+	Notifications repository.NotificationRepositoryInterface
+	Trips         repository.TripsRepositoryInterface
+	Corporate     repository.CorporateRepositoryInterface
+	Payments      repository.PaymentRepositoryInterface
+	// Providers refund, at their gateway, what a cancellation gives back of
+	// the payments made with the method they are keyed by.
+	Providers   map[string]PaymentProvider
+	Geocoder    Geocoder
+	Router      Router
+	FeedbackURL string
+}
+
+// bookingActor decides who may move b along one edge of the lifecycle.
+type bookingActor func(u *model.User, b *model.Booking) bool
+
+func adminOnly(u *model.User, b *model.Booking) bool { return u.IsAdmin() }
+
+// adminOrOwner lets admins and the user who made b move it.
+func adminOrOwner(u *model.User, b *model.Booking) bool {
+	return u.IsAdmin() || (b.CreatedBy != nil && *b.CreatedBy == u.ID)
+}
+
+// adminOrAssignedDriver lets admins and the account of b's driver move it.
+func adminOrAssignedDriver(u *model.User, b *model.Booking) bool {
+	return u.IsAdmin() || (b.DriverID != nil && u.ActsFor(*b.DriverID))
+}
+
+var bookingTransitions = map[string]map[string]bookingActor{
+	model.BookingPending: {
+		model.BookingConfirmed: adminOnly,
+		model.BookingCancelled: adminOrOwner,
+	},
+	model.BookingConfirmed: {
+		model.BookingDriverAssigned: adminOnly,
+		model.BookingCancelled:      adminOrOwner,
+	},
+	model.BookingDriverAssigned: {
+		model.BookingOnTrip:    adminOrAssignedDriver,
+		model.BookingNoShow:    adminOrAssignedDriver,
+		model.BookingCancelled: adminOnly,
+	},
+	model.BookingOnTrip: {
+		model.BookingCompleted: adminOrAssignedDriver,
+	},
+}
+
+func (s *BookingService) Create(ctx context.Context, b *model.Booking) error {
+	// Every booking code comes from booking_code_seq; one sent by the client
+	// is ignored.
+	b.ID = ""
+	if b.Status == "" {
+		b.Status = model.BookingPending
+	}
+	if status, ok := model.ParseBookingStatus(b.Status); !ok || status != model.BookingPending {
+		return ErrStatusChange
+	}
+	if err := s.applyQuote(ctx, b); err != nil {
+		return err
+	}
+	if err := s.applyCustomer(ctx, b); err != nil {
+		return err
+	}
+	if err := s.applyPromotion(ctx, b); err != nil {
+		return err
+	}
+	if err := s.applySchedule(ctx, b); err != nil {
+		return err
+	}
+	if err := chargeToAccount(ctx, s.Corporate, b, nil); err != nil {
+		return err
+	}
+	numberStops(b.Stops, nil)
+	if err := s.locate(ctx, b); err != nil {
+		return err
+	}
+	return s.Repo.Create(ctx, b)
+}
+
+// applyQuote prices a booking made from a quote at the quoted total. The
+// repository refuses a second booking on the same quote.
+func (s *BookingService) applyQuote(ctx context.Context, b *model.Booking) error {
+	if b.QuoteID == nil {
+		return nil
+	}
+
+	q, err := s.Pricing.GetQuote(ctx, *b.QuoteID)
+	if err != nil {
+		return err
+	}
+	if q == nil {
+		return ErrUnknownQuote
+	}
+	if time.Now().After(q.ExpiresAt) {
+		return ErrQuoteExpired
+	}
+	if b.PromoCode != nil && (q.PromoCode == nil || !strings.EqualFold(strings.TrimSpace(*b.PromoCode), *q.PromoCode)) {
+		return ErrPromoOnQuote
+	}
+	if err := quotedStops(q, b.Stops); err != nil {
+		return err
+	}
+
+	total := q.Total
+	carType := q.CarTypeID
+	b.Amount = &total
+	b.Price = formatMoney(total)
+	b.CarTypeID = &carType
+	b.PromoCode = q.PromoCode
+	b.Discount = nil
+	if q.PromoCode != nil {
+		discount := q.Discount
+		b.Discount = &discount
+	}
+	return nil
+}
+
+// applyPromotion discounts the amount of a booking made without a quote by
+// its promo code. Quoted bookings carry the discount worked out for the quote.
+func (s *BookingService) applyPromotion(ctx context.Context, b *model.Booking) error {
+	if b.QuoteID != nil {
+		return nil
+	}
+	b.Discount = nil
+	if b.PromoCode != nil && strings.TrimSpace(*b.PromoCode) == "" {
+		b.PromoCode = nil
+	}
+	if b.PromoCode == nil {
+		return nil
+	}
+
+	var fare float64
+	if b.Amount != nil {
+		fare = *b.Amount
+	}
+	var carType string
+	if b.CarTypeID != nil {
+		carType = *b.CarTypeID
+	}
+
+	p, discount, err := applyPromotion(ctx, s.Promotions, *b.PromoCode, fare, carType, b.CustomerID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	net := roundMoney(fare - discount)
+	b.PromoCode = &p.Code
+	b.Discount = &discount
+	b.Amount = &net
+	b.Price = formatMoney(net)
+	return nil
+}
+
+// quotedStops checks that stops are the ones q was priced for: as many, in the
+// same order, with the same waits and at the quoted point where it had one.
+func quotedStops(q *model.Quote, stops []model.BookingStop) error {
+	if len(stops) != len(q.QuotedStops) {
+		return ErrPriceLocked
+	}
+	for i, quoted := range q.QuotedStops {
+		st := stops[i]
+		if st.WaitMinutes != quoted.WaitMinutes {
+			return ErrPriceLocked
+		}
+		if p := quoted.Point; p != nil && (st.Lat == nil || st.Lng == nil || *st.Lat != p.Lat || *st.Lng != p.Lng) {
+			return ErrPriceLocked
+		}
+	}
+	return nil
 }
 
-func (s *BookingService) Create(b *model.Booking) error {
-	return s.Repo.Create(b)
+// lockPrice keeps the price and car type of a booking made from a quote or
+// with a promo code, and the stops of one made from a quote; a request that
+// tries to change them is rejected.
+func (s *BookingService) lockPrice(ctx context.Context, b, current *model.Booking) error {
+	b.QuoteID = current.QuoteID
+	b.PromoCode = current.PromoCode
+	b.Discount = current.Discount
+	if current.QuoteID == nil && current.PromoCode == nil {
+		return nil
+	}
+
+	if b.Amount != nil && (current.Amount == nil || *b.Amount != *current.Amount) {
+		return ErrPriceLocked
+	}
+	if b.CarTypeID != nil && (current.CarTypeID == nil || *b.CarTypeID != *current.CarTypeID) {
+		return ErrPriceLocked
+	}
+	if b.Stops != nil && current.QuoteID != nil {
+		q, err := s.Pricing.GetQuote(ctx, *current.QuoteID)
+		if err != nil {
+			return err
+		}
+		if q != nil {
+			if err := quotedStops(q, b.Stops); err != nil {
+				return err
+			}
+		}
+	}
+	b.Amount = current.Amount
+	b.Price = current.Price
+	b.CarTypeID = current.CarTypeID
+	return nil
+}
+
+// applyCustomer copies the linked customer's name, and phone when none was
+// given, onto the booking so existing readers of those columns keep working.
+func (s *BookingService) applyCustomer(ctx context.Context, b *model.Booking) error {
+	c, err := lookupCustomer(ctx, s.Customers, b.CustomerID)
+	if err != nil || c == nil {
+		return err
+	}
+	if c.Blacklisted {
+		return ErrCustomerBlacklisted
+	}
+
+	b.Customer = c.Name
+	if b.PhoneNumber == nil && len(c.Phones) > 0 {
+		phone := c.Phones[0]
+		b.PhoneNumber = &phone
+	}
+	return nil
+}
+
+// applySchedule validates the booking window, fills the driver's name and
+// rejects the booking when its driver or vehicle is already busy. Cancelled and
+// no-show bookings no longer hold their slot, so they are not checked.
+func (s *BookingService) applySchedule(ctx context.Context, b *model.Booking) error {
+	if b.StartAt == nil || b.EndAt == nil {
+		if b.StartAt != nil || b.EndAt != nil || b.DriverID != nil || b.VehicleID != nil {
+			return ErrMissingWindow
+		}
+		return nil
+	}
+	if !b.EndAt.After(*b.StartAt) {
+		return ErrInvalidWindow
+	}
+
+	if b.DriverID != nil && s.Drivers != nil {
+		d, err := lookupDriver(ctx, s.Drivers, *b.DriverID)
+		if err != nil {
+			return err
+		}
+		b.Driver = d.Name
+	}
+
+	if s.Availability == nil || b.Status == model.BookingCancelled || b.Status == model.BookingNoShow {
+		return nil
+	}
+
+	if b.DriverID != nil {
+		blocks, err := s.Availability.DriverBlocks(ctx, *b.DriverID, *b.StartAt, *b.EndAt)
+		if err != nil {
+			return err
+		}
+		if conflicting(blocks, b.ID) != nil {
+			return ErrDriverUnavailable
+		}
+	}
+	if b.VehicleID != nil {
+		blocks, err := s.Availability.VehicleBlocks(ctx, *b.VehicleID, *b.StartAt, *b.EndAt)
+		if err != nil {
+			return err
+		}
+		if conflicting(blocks, b.ID) != nil {
+			return ErrVehicleUnavailable
+		}
+	}
+	return nil
+}
+
+// numberStops numbers stops from 1 in the order they are listed. A stop left
+// at its position on the booking keeps its progress; a new or moved one starts
+// pending.
+func numberStops(stops, current []model.BookingStop) {
+	progress := map[int]model.BookingStop{}
+	for _, st := range current {
+		progress[st.Position] = st
+	}
+	for i := range stops {
+		st := &stops[i]
+		st.Position = i + 1
+		if st.Kind == "" {
+			st.Kind = model.StopWaypoint
+		}
+		st.Status, st.ArrivedAt, st.DepartedAt = model.StopPending, nil, nil
+		if prev, ok := progress[st.Position]; ok && prev.Address == st.Address {
+			st.Status, st.ArrivedAt, st.DepartedAt = prev.Status, prev.ArrivedAt, prev.DepartedAt
+		}
+	}
+}
+
+// locate geocodes whichever of the pickup, stop and drop-off points came
+// without coordinates and works out the straight-line length of each leg and
+// of the whole route through the stops. An address the geocoder does not know
+// is left without coordinates, and the legs either side of it and the total
+// distance unknown.
+func (s *BookingService) locate(ctx context.Context, b *model.Booking) error {
+	var err error
+	if b.PickupLat, b.PickupLng, err = s.geocode(ctx, b.PickupLocation, b.PickupLat, b.PickupLng); err != nil {
+		return err
+	}
+	for i := range b.Stops {
+		st := &b.Stops[i]
+		if st.Lat, st.Lng, err = s.geocode(ctx, &st.Address, st.Lat, st.Lng); err != nil {
+			return err
+		}
+	}
+	if b.DropLat, b.DropLng, err = s.geocode(ctx, b.DropLocation, b.DropLat, b.DropLng); err != nil {
+		return err
+	}
+
+	b.DistanceKM = nil
+	total, known := 0.0, b.PickupLat != nil && b.DropLat != nil
+	lat, lng := b.PickupLat, b.PickupLng
+	for i := range b.Stops {
+		st := &b.Stops[i]
+		st.LegKM = legKM(lat, lng, st.Lat, st.Lng)
+		if st.LegKM == nil {
+			known = false
+		} else {
+			total += *st.LegKM
+		}
+		lat, lng = st.Lat, st.Lng
+	}
+	if !known {
+		return nil
+	}
+	last := legKM(lat, lng, b.DropLat, b.DropLng)
+	if last == nil {
+		return nil
+	}
+	d := math.Round((total+*last)*10) / 10
+	b.DistanceKM = &d
+	return nil
+}
+
+func legKM(fromLat, fromLng, toLat, toLng *float64) *float64 {
+	if fromLat == nil || toLat == nil {
+		return nil
+	}
+	d := math.Round(utils.HaversineKM(*fromLat, *fromLng, *toLat, *toLng)*10) / 10
+	return &d
+}
+
+func (s *BookingService) geocode(ctx context.Context, address *string, lat, lng *float64) (*float64, *float64, error) {
+	if (lat == nil) != (lng == nil) {
+		return nil, nil, ErrInvalidLocation
+	}
+	if lat != nil {
+		if !utils.ValidCoordinates(*lat, *lng) {
+			return nil, nil, ErrInvalidLocation
+		}
+		return lat, lng, nil
+	}
+	if s.Geocoder == nil || address == nil {
+		return nil, nil, nil
+	}
+
+	p, err := s.Geocoder.Geocode(ctx, *address)
+	if errors.Is(err, ErrAddressNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &p.Lat, &p.Lng, nil
+}
+
+// Nearby lists bookings picking up within radiusKM of a point, nearest first.
+func (s *BookingService) Nearby(ctx context.Context, lat, lng, radiusKM float64) ([]model.NearbyBooking, error) {
+	if !utils.ValidCoordinates(lat, lng) {
+		return nil, ErrInvalidLocation
+	}
+	if radiusKM <= 0 {
+		return nil, ErrInvalidRadius
+	}
+
+	var box model.GeoBox
+	box.MinLat, box.MinLng, box.MaxLat, box.MaxLng = utils.BoundingBox(lat, lng, radiusKM)
+	bookings, err := s.Repo.GetByPickupArea(ctx, box)
+	if err != nil {
+		return nil, err
+	}
+
+	nearby := []model.NearbyBooking{}
+	for _, b := range bookings {
+		d := utils.HaversineKM(lat, lng, *b.PickupLat, *b.PickupLng)
+		if d <= radiusKM {
+			nearby = append(nearby, model.NearbyBooking{Booking: b, AwayKM: math.Round(d*100) / 100})
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool { return nearby[i].AwayKM < nearby[j].AwayKM })
+	return nearby, nil
+}
+
+// Within lists bookings picking up inside box.
+func (s *BookingService) Within(ctx context.Context, box model.GeoBox) ([]model.Booking, error) {
+	if !utils.ValidCoordinates(box.MinLat, box.MinLng) || !utils.ValidCoordinates(box.MaxLat, box.MaxLng) ||
+		box.MinLat > box.MaxLat || box.MinLng > box.MaxLng {
+		return nil, ErrInvalidLocation
+	}
+	return s.Repo.GetByPickupArea(ctx, box)
+}
+
+func (s *BookingService) GetAll(ctx context.Context) ([]model.Booking, error) {
+	return s.Repo.GetAll(ctx)
+}
+
+func (s *BookingService) GetByID(ctx context.Context, id string) (*model.Booking, error) {
+	return s.Repo.GetByID(ctx, id)
+}
+
+// GetByCode finds a booking by a code as a customer would read it out, see
+// utils.ParseBookingCode.
+func (s *BookingService) GetByCode(ctx context.Context, code string) (*model.Booking, error) {
+	id, ok := utils.ParseBookingCode(code)
+	if !ok {
+		return nil, ErrInvalidBookingCode
+	}
+
+	b, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
+
+// Update edits booking details only; the stored status is kept so that PUT
+// cannot bypass the lifecycle.
+func (s *BookingService) Update(ctx context.Context, b *model.Booking) error {
+	current, err := s.Repo.GetByID(ctx, b.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrNotFound
+	}
+
+	if b.Status != "" {
+		if status, ok := model.ParseBookingStatus(b.Status); !ok || status != current.Status {
+			return ErrStatusChange
+		}
+	}
+	b.Status = current.Status
+
+	if err := s.lockPrice(ctx, b, current); err != nil {
+		return err
+	}
+	if err := s.applyCustomer(ctx, b); err != nil {
+		return err
+	}
+	if err := s.applySchedule(ctx, b); err != nil {
+		return err
+	}
+	if err := chargeToAccount(ctx, s.Corporate, b, current); err != nil {
+		return err
+	}
+	if b.Stops == nil {
+		b.Stops = current.Stops
+	}
+	numberStops(b.Stops, current.Stops)
+	if err := s.locate(ctx, b); err != nil {
+		return err
+	}
+	if err := s.derivePayment(ctx, b); err != nil {
+		return err
+	}
+	return s.Repo.Update(ctx, b)
+}
+
+// derivePayment keeps the payment status of a booking that has payments in
+// line with them, so it cannot be edited by hand.
+func (s *BookingService) derivePayment(ctx context.Context, b *model.Booking) error {
+	if s.Payments == nil {
+		return nil
+	}
+	payments, err := s.Payments.GetByBooking(ctx, b.ID)
+	if err != nil || len(payments) == 0 {
+		return err
+	}
+	due, err := bookingDue(ctx, s.Cancellations, b)
+	if err != nil {
+		return err
+	}
+	b.Payment = model.SummarisePayments(due, payments).PaymentStatus
+	return nil
+}
+
+// stopMoves lists the progress a stop can make from each status: the driver
+// arrives and departs, or skips a stop not yet left.
+var stopMoves = map[string]map[string]bool{
+	model.StopPending: {model.StopArrived: true, model.StopSkipped: true},
+	model.StopArrived: {model.StopDeparted: true, model.StopSkipped: true},
+}
+
+// UpdateStop records the driver reaching, leaving or skipping one stop of a
+// booking under way.
+func (s *BookingService) UpdateStop(ctx context.Context, id string, position int, user *model.User, u model.StopUpdate) (*model.Booking, error) {
+	b, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	if user == nil || !adminOrAssignedDriver(user, b) {
+		return nil, ErrTransitionForbidden
+	}
+
+	var stop *model.BookingStop
+	for i := range b.Stops {
+		if b.Stops[i].Position == position {
+			stop = &b.Stops[i]
+		}
+	}
+	if stop == nil {
+		return nil, ErrNotFound
+	}
+	if b.Status != model.BookingDriverAssigned && b.Status != model.BookingOnTrip {
+		return nil, ErrInvalidStop
+	}
+	if !stopMoves[stop.Status][u.Status] {
+		return nil, ErrInvalidStop
+	}
+
+	from := stop.Status
+	now := time.Now()
+	switch u.Status {
+	case model.StopArrived:
+		stop.ArrivedAt = &now
+	case model.StopDeparted:
+		stop.DepartedAt = &now
+	}
+	stop.Status = u.Status
+	if err := s.Repo.UpdateStop(ctx, id, stop, from); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *BookingService) Delete(ctx context.Context, id string, version int) error {
+	return s.Repo.Delete(ctx, id, version)
+}
+
+func (s *BookingService) Transition(ctx context.Context, id string, user *model.User, t model.BookingTransition) (*model.Booking, error) {
+	b, _, err := s.transition(ctx, id, user, t)
+	return b, err
+}
+
+// Cancel cancels a booking under the tenant's cancellation policy and returns
+// how it was settled.
+func (s *BookingService) Cancel(ctx context.Context, id string, user *model.User, reason string) (*model.Booking, *model.BookingCancellation, error) {
+	b, change, err := s.transition(ctx, id, user, model.BookingTransition{To: model.BookingCancelled, Reason: reason})
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, change.Cancellation, nil
+}
+
+// transition moves a booking along the lifecycle. Cancelling a booking or
+// marking it a no-show also settles its fee and refund, confirming it or
+// assigning a driver queues notifications, and going on trip or completing
+// opens or closes its trip.
+func (s *BookingService) transition(ctx context.Context, id string, user *model.User, t model.BookingTransition) (*model.Booking, *model.BookingStatusChange, error) {
+	to, ok := model.ParseBookingStatus(t.To)
+	if !ok {
+		return nil, nil, ErrInvalidStatus
+	}
+
+	b, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b == nil {
+		return nil, nil, ErrNotFound
+	}
+
+	allowed, ok := bookingTransitions[b.Status][to]
+	if !ok {
+		return nil, nil, ErrInvalidTransition
+	}
+	if user == nil || !allowed(user, b) {
+		return nil, nil, ErrTransitionForbidden
+	}
+
+	change := &model.BookingStatusChange{
+		FromStatus: b.Status,
+		ToStatus:   to,
+		ChangedBy:  user.ID,
+		Reason:     t.Reason,
+	}
+
+	var payments []model.Payment
+	if (to == model.BookingCancelled || to == model.BookingNoShow) && s.Cancellations != nil {
+		policy, err := NewCancellationService(s.Cancellations).GetPolicy(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		var paid float64
+		paid, payments, err = amountPaid(ctx, s.Payments, b)
+		if err != nil {
+			return nil, nil, err
+		}
+		change.Cancellation = settleCancellation(*policy, b, to, paid, time.Now())
+		change.Cancellation.Reason = t.Reason
+		change.Cancellation.CancelledBy = user.ID
+		change.Cancellation.Refunds = cancellationRefunds(change.Cancellation, payments, s.Providers, "cancellation of booking "+b.ID)
+		for i := range change.Cancellation.Refunds {
+			change.Cancellation.Refunds[i].ApprovedBy = &user.ID
+		}
+	}
+
+	if s.Notifications != nil {
+		notifications := NewNotificationService(s.Notifications, s.Customers, s.Drivers)
+		notifications.FeedbackURL = s.FeedbackURL
+		change.Notifications, err = notifications.BookingNotifications(ctx, b, to)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if (to == model.BookingOnTrip || to == model.BookingCompleted) && s.Trips != nil {
+		change.Trip, err = s.bookingTrip(ctx, b, to, t, time.Now())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	b.Status = to
+	if err := s.Repo.Transition(ctx, b, change); err != nil {
+		return nil, nil, err
+	}
+	if change.Cancellation != nil {
+		s.refundAtGateway(ctx, change.Cancellation, payments)
+	}
+
+	return b, change, nil
+}
+
+// refundAtGateway carries out the pending refunds of c at the gateway of
+// their payment. The booking stays cancelled when the gateway refuses; the
+// refund is then recorded as failed and its money can be refunded again.
+func (s *BookingService) refundAtGateway(ctx context.Context, c *model.BookingCancellation, payments []model.Payment) {
+	refunds := &PaymentService{Repo: s.Payments, Providers: s.Providers}
+	for i := range c.Refunds {
+		rf := &c.Refunds[i]
+		if rf.Status != model.RefundPending {
+			continue
+		}
+		for j := range payments {
+			if payments[j].PaymentID != rf.PaymentID {
+				continue
+			}
+			if err := refunds.settleAtGateway(ctx, &payments[j], rf); err != nil {
+				log.Printf("Gagal mengembalikan dana pembayaran %d untuk pembatalan booking %s: %v\n", rf.PaymentID, c.BookingID, err)
+			}
+		}
+	}
+}
+
+func (s *BookingService) GetStatusHistory(ctx context.Context, id string) ([]model.BookingStatusChange, error) {
+	b, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNotFound
+	}
+
+	return s.Repo.GetStatusHistory(ctx, id)
+}
+
+// bookingTrip opens b's trip when it goes on trip and closes it with the
+// actuals when it completes. A booking completed without an open trip gets
+// one that starts and ends now. A reported distance is checked against the
+// road route through the stops; without one the route's, or else the
+// straight-line, distance is recorded.
+func (s *BookingService) bookingTrip(ctx context.Context, b *model.Booking, to string, t model.BookingTransition, now time.Time) (*model.VehicleTrip, error) {
+	trip, err := s.Trips.FindByBooking(ctx, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil || trip.Status != model.TripOngoing {
+		trip = &model.VehicleTrip{
+			BookingID:     &b.ID,
+			TripDate:      now,
+			PassengerName: b.Customer,
+			DriverName:    b.Driver,
+			Status:        model.TripOngoing,
+			StartedAt:     &now,
+		}
+		if b.VehicleID != nil {
+			trip.VehicleID = uint(*b.VehicleID)
+		}
+		if b.DriverID != nil {
+			trip.DriverID = uint(*b.DriverID)
+		}
+		if b.PickupLocation != nil {
+			trip.Origin = *b.PickupLocation
+		}
+		if b.DropLocation != nil {
+			trip.Destination = *b.DropLocation
+		}
+		trip.OriginLat, trip.OriginLng = b.PickupLat, b.PickupLng
+		trip.DestinationLat, trip.DestinationLng = b.DropLat, b.DropLng
+	}
+	if to == model.BookingOnTrip {
+		return trip, nil
+	}
+
+	trip.Status = model.TripCompleted
+	trip.EndedAt = &now
+	if t.DistanceKM != nil {
+		trip.DistanceKM = *t.DistanceKM
+	}
+	if err := routeTrip(ctx, s.Router, trip, stopPoints(b.Stops)); err != nil {
+		return nil, err
+	}
+	if trip.DistanceKM == 0 && b.DistanceKM != nil {
+		trip.DistanceKM = int(math.Round(*b.DistanceKM))
+	}
+	trip.DurationMinutes = int(now.Sub(*trip.StartedAt).Round(time.Minute).Minutes())
+	if t.DurationMinutes != nil {
+		trip.DurationMinutes = *t.DurationMinutes
+	}
+	if b.Amount != nil {
+		trip.Price = *b.Amount
+	}
+	if t.Fare != nil {
+		trip.Price = *t.Fare
+	}
+	return trip, nil
 }
 
-func (s *BookingService) GetAll() ([]model.Booking, error) {
-	return s.Repo.GetAll()
+// stopPoints lists the located stops a booking's route passes through,
+// leaving out skipped ones.
+func stopPoints(stops []model.BookingStop) []model.GeoPoint {
+	var points []model.GeoPoint
+	for _, st := range stops {
+		if st.Lat != nil && st.Lng != nil && st.Status != model.StopSkipped {
+			points = append(points, model.GeoPoint{Name: st.Address, Lat: *st.Lat, Lng: *st.Lng})
+		}
+	}
+	return points
 }
 
-func (s *BookingService) Update(b *model.Booking) error {
-	return s.Repo.Update(b)
+func (s *BookingService) GetTrip(ctx context.Context, id string) (*model.VehicleTrip, error) {
+	if s.Trips == nil {
+		return nil, ErrNotFound
+	}
+	trip, err := s.Trips.FindByBooking(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, ErrNotFound
+	}
+	return trip, nil
 }
 
-func (s *BookingService) Delete(id string) error {
-	return s.Repo.Delete(id)
+func (s *BookingService) GetCancellation(ctx context.Context, id string) (*model.BookingCancellation, error) {
+	if s.Cancellations == nil {
+		return nil, ErrNotFound
+	}
+	c, err := s.Cancellations.GetByBooking(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, ErrNotFound
+	}
+	return c, nil
 }
@@ -2,47 +2,97 @@ package service_test
 
 import (
 	"auth-service/model"
+	"auth-service/repository"
 	"auth-service/service"
+	"auth-service/utils"
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockBookingRepository struct {
 	mock.Mock
 }
 
-func (m *MockBookingRepository) Create(b *model.Booking) error {
+func (m *MockBookingRepository) Create(ctx context.Context, b *model.Booking) error {
 	args := m.Called(b)
 	return args.Error(0)
 }
 
-func (m *MockBookingRepository) GetAll() ([]model.Booking, error) {
+func (m *MockBookingRepository) GetAll(ctx context.Context) ([]model.Booking, error) {
 	args := m.Called()
 	return args.Get(0).([]model.Booking), args.Error(1)
 }
 
-func (m *MockBookingRepository) Update(b *model.Booking) error {
+func (m *MockBookingRepository) GetByPickupArea(ctx context.Context, box model.GeoBox) ([]model.Booking, error) {
+	args := m.Called(box)
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
+
+func (m *MockBookingRepository) Update(ctx context.Context, b *model.Booking) error {
 	args := m.Called(b)
 	return args.Error(0)
 }
 
-func (m *MockBookingRepository) Delete(id string) error {
+func (m *MockBookingRepository) GetByID(ctx context.Context, id string) (*model.Booking, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Booking), args.Error(1)
+}
+
+func (m *MockBookingRepository) Delete(ctx context.Context, id string, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockBookingRepository) GetDeleted(ctx context.Context) ([]model.Booking, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
+
+func (m *MockBookingRepository) Restore(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+func (m *MockBookingRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBookingRepository) Transition(ctx context.Context, b *model.Booking, change *model.BookingStatusChange) error {
+	args := m.Called(b, change)
+	return args.Error(0)
+}
+
+func (m *MockBookingRepository) GetStatusHistory(ctx context.Context, id string) ([]model.BookingStatusChange, error) {
+	args := m.Called(id)
+	return args.Get(0).([]model.BookingStatusChange), args.Error(1)
+}
+
+func (m *MockBookingRepository) UpdateStop(ctx context.Context, bookingID string, stop *model.BookingStop, from string) error {
+	args := m.Called(bookingID, stop, from)
+	return args.Error(0)
+}
+
 func TestBookingService_Create(t *testing.T) {
 	mockRepo := new(MockBookingRepository)
 	svc := &service.BookingService{Repo: mockRepo}
 
-	booking := &model.Booking{ID: "1"}
+	booking := &model.Booking{ID: "BK-MINE"}
 
 	mockRepo.On("Create", booking).Return(nil)
 
-	err := svc.Create(booking)
+	err := svc.Create(context.Background(), booking)
 	assert.NoError(t, err)
+	assert.Empty(t, booking.ID)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -57,7 +107,7 @@ func TestBookingService_GetAll(t *testing.T) {
 
 	mockRepo.On("GetAll").Return(expected, nil)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 
@@ -70,24 +120,420 @@ func TestBookingService_Update(t *testing.T) {
 
 	booking := &model.Booking{ID: "1"}
 
+	mockRepo.On("GetByID", "1").Return(&model.Booking{ID: "1", Status: model.BookingConfirmed}, nil)
 	mockRepo.On("Update", booking).Return(nil)
 
-	err := svc.Update(booking)
+	err := svc.Update(context.Background(), booking)
 	assert.NoError(t, err)
+	assert.Equal(t, model.BookingConfirmed, booking.Status)
 
 	mockRepo.AssertExpectations(t)
 }
 
+func TestBookingService_UpdateRejectsStatusChange(t *testing.T) {
+	mockRepo := new(MockBookingRepository)
+	svc := &service.BookingService{Repo: mockRepo}
+
+	mockRepo.On("GetByID", "1").Return(&model.Booking{ID: "1", Status: model.BookingCompleted}, nil)
+
+	err := svc.Update(context.Background(), &model.Booking{ID: "1", Status: "pending"})
+	assert.ErrorIs(t, err, service.ErrStatusChange)
+
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestBookingService_CreateStartsPending(t *testing.T) {
+	mockRepo := new(MockBookingRepository)
+	svc := &service.BookingService{Repo: mockRepo}
+
+	booking := &model.Booking{ID: "1"}
+	mockRepo.On("Create", booking).Return(nil)
+
+	assert.NoError(t, svc.Create(context.Background(), booking))
+	assert.Equal(t, model.BookingPending, booking.Status)
+
+	err := svc.Create(context.Background(), &model.Booking{ID: "2", Status: "completed"})
+	assert.ErrorIs(t, err, service.ErrStatusChange)
+}
+
+func TestBookingService_Transition(t *testing.T) {
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+	driverID, otherDriverID := 7, 8
+	driver := &model.User{ID: 2, Role: model.RoleDriver, DriverID: &driverID}
+	otherDriver := &model.User{ID: 4, Role: model.RoleDriver, DriverID: &otherDriverID}
+	customer := &model.User{ID: 3, Role: "user"}
+	stranger := &model.User{ID: 5, Role: "user"}
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		user *model.User
+		err  error
+	}{
+		{"admin confirms", model.BookingPending, "confirmed", admin, nil},
+		{"customer cancels", model.BookingPending, "Cancelled", customer, nil},
+		{"driver starts trip", model.BookingDriverAssigned, "on_trip", driver, nil},
+		{"driver completes", model.BookingOnTrip, "Completed", driver, nil},
+		{"customer cannot confirm", model.BookingPending, "Confirmed", customer, service.ErrTransitionForbidden},
+		{"driver cannot cancel assigned", model.BookingDriverAssigned, "Cancelled", driver, service.ErrTransitionForbidden},
+		{"only the owner cancels", model.BookingConfirmed, "Cancelled", stranger, service.ErrTransitionForbidden},
+		{"driver cannot cancel someone's booking", model.BookingPending, "Cancelled", driver, service.ErrTransitionForbidden},
+		{"other driver cannot start trip", model.BookingDriverAssigned, "OnTrip", otherDriver, service.ErrTransitionForbidden},
+		{"other driver cannot mark no-show", model.BookingDriverAssigned, "NoShow", otherDriver, service.ErrTransitionForbidden},
+		{"other driver cannot complete", model.BookingOnTrip, "Completed", otherDriver, service.ErrTransitionForbidden},
+		{"admin completes", model.BookingOnTrip, "Completed", admin, nil},
+		{"completed is final", model.BookingCompleted, "Pending", admin, service.ErrInvalidTransition},
+		{"no skipping", model.BookingPending, "OnTrip", admin, service.ErrInvalidTransition},
+		{"unknown status", model.BookingPending, "Lost", admin, service.ErrInvalidStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockBookingRepository)
+			svc := &service.BookingService{Repo: mockRepo}
+
+			mockRepo.On("GetByID", "1").Return(&model.Booking{ID: "1", Status: tt.from, DriverID: &driverID, CreatedBy: &customer.ID, Version: 2}, nil)
+			mockRepo.On("Transition", mock.Anything, mock.Anything).Return(nil)
+
+			b, err := svc.Transition(context.Background(), "1", tt.user, model.BookingTransition{To: tt.to, Reason: "test"})
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				mockRepo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			change := mockRepo.Calls[1].Arguments.Get(1).(*model.BookingStatusChange)
+			assert.Equal(t, tt.from, change.FromStatus)
+			assert.Equal(t, b.Status, change.ToStatus)
+			assert.Equal(t, tt.user.ID, change.ChangedBy)
+			assert.Equal(t, "test", change.Reason)
+		})
+	}
+}
+
+func TestBookingService_TransitionNotFound(t *testing.T) {
+	mockRepo := new(MockBookingRepository)
+	svc := &service.BookingService{Repo: mockRepo}
+
+	mockRepo.On("GetByID", "9").Return(nil, nil)
+
+	_, err := svc.Transition(context.Background(), "9", &model.User{Role: model.RoleAdmin}, model.BookingTransition{To: "Confirmed"})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
 func TestBookingService_Delete(t *testing.T) {
 	mockRepo := new(MockBookingRepository)
 	svc := &service.BookingService{Repo: mockRepo}
 
 	id := "1"
 
-	mockRepo.On("Delete", id).Return(nil)
+	mockRepo.On("Delete", id, 2).Return(nil)
+
+	err := svc.Delete(context.Background(), id, 2)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookingService_GetByID(t *testing.T) {
+	mockRepo := new(MockBookingRepository)
+	svc := &service.BookingService{Repo: mockRepo}
+
+	expected := &model.Booking{ID: "1", Version: 3}
+	mockRepo.On("GetByID", "1").Return(expected, nil)
 
-	err := svc.Delete(id)
+	result, err := svc.GetByID(context.Background(), "1")
 	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestBookingService_GetByCode(t *testing.T) {
+	mockRepo := new(MockBookingRepository)
+	svc := &service.BookingService{Repo: mockRepo}
+
+	code := utils.BookingCode(4242)
+	expected := &model.Booking{ID: code, Version: 1}
+	mockRepo.On("GetByID", code).Return(expected, nil)
+	missing := utils.BookingCode(4243)
+	mockRepo.On("GetByID", missing).Return(nil, nil)
+
+	result, err := svc.GetByCode(context.Background(), strings.ToLower(code[:5]+"-"+code[5:]))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	_, err = svc.GetByCode(context.Background(), missing)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	_, err = svc.GetByCode(context.Background(), code[:len(code)-1])
+	assert.ErrorIs(t, err, service.ErrInvalidBookingCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBookingService_TripLifecycle(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	trips := repository.NewMemoryTripRepo(store)
+	svc := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Trips: trips}
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+	driverID, vehicleID, amount := 3, 7, 250000.0
+	driver := &model.User{ID: 2, Role: model.RoleDriver, DriverID: &driverID}
+
+	pickup, drop := "Stasiun Gambir", "Bandara Soekarno-Hatta"
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	b := &model.Booking{Customer: "Sari", Driver: "Budi", DriverID: &driverID, VehicleID: &vehicleID, StartAt: &start, EndAt: &end,
+		PickupLocation: &pickup, DropLocation: &drop, Amount: &amount}
+	require.NoError(t, svc.Create(ctx, b))
+
+	for _, to := range []string{model.BookingConfirmed, model.BookingDriverAssigned} {
+		_, err := svc.Transition(ctx, b.ID, admin, model.BookingTransition{To: to})
+		require.NoError(t, err)
+	}
+	_, err := svc.GetTrip(ctx, b.ID)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	_, err = svc.Transition(ctx, b.ID, driver, model.BookingTransition{To: model.BookingOnTrip})
+	require.NoError(t, err)
+	trip, err := svc.GetTrip(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.TripOngoing, trip.Status)
+	assert.Equal(t, uint(3), trip.DriverID)
+	assert.Equal(t, uint(7), trip.VehicleID)
+	assert.Equal(t, "Stasiun Gambir", trip.Origin)
+	assert.Equal(t, "Sari", trip.PassengerName)
+	require.NotNil(t, trip.StartedAt)
+	assert.Nil(t, trip.EndedAt)
+
+	distance := 32
+	_, err = svc.Transition(ctx, b.ID, driver, model.BookingTransition{To: model.BookingCompleted, DistanceKM: &distance})
+	require.NoError(t, err)
+	closed, err := svc.GetTrip(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, trip.ID, closed.ID)
+	assert.Equal(t, model.TripCompleted, closed.Status)
+	assert.Equal(t, 32, closed.DistanceKM)
+	assert.Equal(t, 250000.0, closed.Price)
+	assert.Zero(t, closed.DurationMinutes)
+	require.NotNil(t, closed.EndedAt)
+
+	total, err := trips.GetTripTotal(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total.TotalTrips)
+	assert.Equal(t, int64(32), total.TotalDistance)
+}
+
+func TestBookingService_CompleteWithActuals(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	bookings := repository.NewMemoryBookingRepository(store)
+	svc := &service.BookingService{Repo: bookings, Trips: repository.NewMemoryTripRepo(store)}
+
+	b := &model.Booking{Customer: "Andi"}
+	require.NoError(t, svc.Create(ctx, b))
+	stored, err := bookings.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	stored.Status = model.BookingOnTrip
+	require.NoError(t, bookings.Transition(ctx, stored, &model.BookingStatusChange{FromStatus: model.BookingPending, ToStatus: model.BookingOnTrip}))
+
+	distance, minutes, fare := 18, 45, 175000.0
+	_, err = svc.Transition(ctx, b.ID, &model.User{ID: 1, Role: model.RoleAdmin}, model.BookingTransition{
+		To: model.BookingCompleted, DistanceKM: &distance, DurationMinutes: &minutes, Fare: &fare,
+	})
+	require.NoError(t, err)
+
+	trip, err := svc.GetTrip(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.TripCompleted, trip.Status)
+	assert.Equal(t, 18, trip.DistanceKM)
+	assert.Equal(t, 45, trip.DurationMinutes)
+	assert.Equal(t, 175000.0, trip.Price)
+	assert.Equal(t, trip.StartedAt, trip.EndedAt)
+}
+
+func TestBookingService_Locate(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	bookings := repository.NewMemoryBookingRepository(store)
+	svc := &service.BookingService{Repo: bookings, Geocoder: service.DefaultGazetteer()}
+
+	pickup, drop := "Jl. Medan Merdeka, Jakarta", "Gedung Sate, Bandung"
+	b := &model.Booking{Customer: "Sari", PickupLocation: &pickup, DropLocation: &drop}
+	require.NoError(t, svc.Create(ctx, b))
+	require.NotNil(t, b.PickupLat)
+	assert.Equal(t, -6.1754, *b.PickupLat)
+	assert.Equal(t, 107.6188, *b.DropLng)
+	require.NotNil(t, b.DistanceKM)
+	assert.InDelta(t, 119, *b.DistanceKM, 2)
+
+	stored, err := bookings.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *b.DistanceKM, *stored.DistanceKM)
+
+	lat, lng := -6.5971, 106.8060
+	unknown := "Somewhere unmapped"
+	b.DropLocation, b.DropLat, b.DropLng = &unknown, &lat, &lng
+	require.NoError(t, svc.Update(ctx, b))
+	assert.InDelta(t, 47, *b.DistanceKM, 2)
+
+	b.DropLat, b.DropLng = nil, nil
+	require.NoError(t, svc.Update(ctx, b))
+	assert.Nil(t, b.DropLat)
+	assert.Nil(t, b.DistanceKM)
+
+	bad := 95.0
+	b.DropLat, b.DropLng = &bad, &lng
+	assert.ErrorIs(t, svc.Update(ctx, b), service.ErrInvalidLocation)
+	b.DropLat, b.DropLng = &lat, nil
+	assert.ErrorIs(t, svc.Update(ctx, b), service.ErrInvalidLocation)
+}
+
+func TestBookingService_Stops(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	bookings := repository.NewMemoryBookingRepository(store)
+	svc := &service.BookingService{Repo: bookings, Geocoder: service.DefaultGazetteer()}
+	assigned, other := 3, 4
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+	driver := &model.User{ID: 7, Role: model.RoleDriver, DriverID: &assigned}
+
+	pickup, drop := "Jakarta", "Bandung"
+	b := &model.Booking{Customer: "Sari", PickupLocation: &pickup, DropLocation: &drop, Stops: []model.BookingStop{
+		{Kind: model.StopPickup, Address: "Bogor", ContactPhone: "0812"},
+		{Address: "Depok", ContactName: "Rina", WaitMinutes: 10},
+	}}
+	require.NoError(t, svc.Create(ctx, b))
+
+	stored, err := bookings.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Stops, 2)
+	assert.Equal(t, "Bogor", stored.Stops[0].Address)
+	assert.Equal(t, model.StopPickup, stored.Stops[0].Kind)
+	assert.Equal(t, "Depok", stored.Stops[1].Address)
+	assert.Equal(t, 2, stored.Stops[1].Position)
+	assert.Equal(t, model.StopWaypoint, stored.Stops[1].Kind)
+	assert.Equal(t, model.StopPending, stored.Stops[1].Status)
+	require.NotNil(t, stored.Stops[0].LegKM)
+	require.NotNil(t, stored.Stops[1].LegKM)
+	assert.InDelta(t, 47, *stored.Stops[0].LegKM, 2)
+	require.NotNil(t, b.DistanceKM)
+	assert.Greater(t, *b.DistanceKM, 119.0)
+
+	_, err = svc.UpdateStop(ctx, b.ID, 1, admin, model.StopUpdate{Status: model.StopArrived})
+	assert.ErrorIs(t, err, service.ErrInvalidStop)
+
+	start := time.Now().Add(time.Hour)
+	end := start.Add(3 * time.Hour)
+	stored.DriverID, stored.StartAt, stored.EndAt = &assigned, &start, &end
+	require.NoError(t, bookings.Update(ctx, stored))
+	stored.Status = model.BookingOnTrip
+	require.NoError(t, bookings.Transition(ctx, stored, &model.BookingStatusChange{ToStatus: model.BookingOnTrip}))
+
+	for _, u := range []*model.User{nil, {ID: 8, Role: "user"}, {ID: 9, Role: model.RoleDriver, DriverID: &other}} {
+		_, err = svc.UpdateStop(ctx, b.ID, 1, u, model.StopUpdate{Status: model.StopArrived})
+		assert.ErrorIs(t, err, service.ErrTransitionForbidden)
+	}
+
+	updated, err := svc.UpdateStop(ctx, b.ID, 1, driver, model.StopUpdate{Status: model.StopArrived})
+	require.NoError(t, err)
+	assert.Equal(t, model.StopArrived, updated.Stops[0].Status)
+	require.NotNil(t, updated.Stops[0].ArrivedAt)
+	_, err = svc.UpdateStop(ctx, b.ID, 1, driver, model.StopUpdate{Status: model.StopDeparted})
+	require.NoError(t, err)
+	_, err = svc.UpdateStop(ctx, b.ID, 1, driver, model.StopUpdate{Status: model.StopSkipped})
+	assert.ErrorIs(t, err, service.ErrInvalidStop)
+	_, err = svc.UpdateStop(ctx, b.ID, 2, driver, model.StopUpdate{Status: model.StopDeparted})
+	assert.ErrorIs(t, err, service.ErrInvalidStop)
+	_, err = svc.UpdateStop(ctx, b.ID, 3, driver, model.StopUpdate{Status: model.StopArrived})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	edit, err := svc.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	edit.Stops = nil
+	edit.Notes = &pickup
+	require.NoError(t, svc.Update(ctx, edit))
+	kept, err := bookings.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	require.Len(t, kept.Stops, 2)
+	assert.Equal(t, model.StopDeparted, kept.Stops[0].Status)
+
+	kept.Stops = []model.BookingStop{kept.Stops[1], {Address: "Bekasi"}}
+	require.NoError(t, svc.Update(ctx, kept))
+	replaced, err := bookings.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	require.Len(t, replaced.Stops, 2)
+	assert.Equal(t, "Depok", replaced.Stops[0].Address)
+	assert.Equal(t, 1, replaced.Stops[0].Position)
+	assert.Equal(t, model.StopPending, replaced.Stops[1].Status)
+}
+
+func TestBookingService_StopsKeepProgressByPosition(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	bookings := repository.NewMemoryBookingRepository(store)
+	svc := &service.BookingService{Repo: bookings}
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+
+	b := &model.Booking{Customer: "Sari", Stops: []model.BookingStop{{Address: "Depok"}, {Address: "Bogor"}, {Address: "Depok"}}}
+	require.NoError(t, svc.Create(ctx, b))
+	b.Status = model.BookingOnTrip
+	require.NoError(t, bookings.Transition(ctx, b, &model.BookingStatusChange{ToStatus: model.BookingOnTrip}))
+	_, err := svc.UpdateStop(ctx, b.ID, 1, admin, model.StopUpdate{Status: model.StopArrived})
+	require.NoError(t, err)
+
+	edit, err := svc.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Update(ctx, edit))
+	kept, err := bookings.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	require.Len(t, kept.Stops, 3)
+	assert.Equal(t, model.StopArrived, kept.Stops[0].Status)
+	assert.Equal(t, model.StopPending, kept.Stops[2].Status)
+
+	kept.Stops = []model.BookingStop{kept.Stops[1], kept.Stops[0]}
+	require.NoError(t, svc.Update(ctx, kept))
+	moved, err := bookings.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	require.Len(t, moved.Stops, 2)
+	assert.Equal(t, model.StopPending, moved.Stops[1].Status)
+}
+
+func TestBookingService_Nearby(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	svc := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Geocoder: service.DefaultGazetteer()}
+
+	for _, place := range []string{"Stasiun Gambir", "Jakarta", "Bandung", "Bandara Soekarno-Hatta"} {
+		place := place
+		require.NoError(t, svc.Create(ctx, &model.Booking{Customer: place, PickupLocation: &place}))
+	}
+	require.NoError(t, svc.Create(ctx, &model.Booking{Customer: "no pickup"}))
+
+	nearby, err := svc.Nearby(ctx, -6.1760, 106.8300, 3)
+	require.NoError(t, err)
+	require.Len(t, nearby, 2)
+	assert.Equal(t, "Stasiun Gambir", nearby[0].Customer)
+	assert.Equal(t, "Jakarta", nearby[1].Customer)
+	assert.Less(t, nearby[0].AwayKM, nearby[1].AwayKM)
+
+	nearby, err = svc.Nearby(ctx, -6.1760, 106.8300, 25)
+	require.NoError(t, err)
+	assert.Len(t, nearby, 3)
+
+	_, err = svc.Nearby(ctx, -6.1760, 106.8300, 0)
+	assert.ErrorIs(t, err, service.ErrInvalidRadius)
+	_, err = svc.Nearby(ctx, -91, 106.8300, 3)
+	assert.ErrorIs(t, err, service.ErrInvalidLocation)
+
+	within, err := svc.Within(ctx, model.GeoBox{MinLat: -7, MinLng: 107, MaxLat: -6.5, MaxLng: 108})
+	require.NoError(t, err)
+	require.Len(t, within, 1)
+	assert.Equal(t, "Bandung", within[0].Customer)
+
+	_, err = svc.Within(ctx, model.GeoBox{MinLat: -6, MinLng: 107, MaxLat: -7, MaxLng: 108})
+	assert.ErrorIs(t, err, service.ErrInvalidLocation)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type BookingTrendsService struct {
@@ -13,6 +14,6 @@ func NewBookingTrendsService(repo repository.BookingTrendsRepositoryInterface) *
 	return &BookingTrendsService{Repo: repo}
 }
 
-func (s *BookingTrendsService) GetTrends(year int) ([]model.BookingTrend, error) {
-	return s.Repo.GetTrends(year)
+func (s *BookingTrendsService) GetTrends(ctx context.Context, year int) ([]model.BookingTrend, error) {
+	return s.Repo.GetTrends(ctx, year)
 }
@@ -3,6 +3,7 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ type MockBookingTrendsRepository struct {
 	mock.Mock
 }
 
-func (m *MockBookingTrendsRepository) GetTrends(year int) ([]model.BookingTrend, error) {
+func (m *MockBookingTrendsRepository) GetTrends(ctx context.Context, year int) ([]model.BookingTrend, error) {
 	args := m.Called(year)
 	return args.Get(0).([]model.BookingTrend), args.Error(1)
 }
@@ -28,7 +29,7 @@ func TestBookingTrendsService_GetTrends(t *testing.T) {
 	}
 	mockRepo.On("GetTrends", 2023).Return(expected, nil)
 
-	result, err := svc.GetTrends(2023)
+	result, err := svc.GetTrends(context.Background(), 2023)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -40,7 +41,7 @@ func TestBookingTrendsService_GetTrends_Error(t *testing.T) {
 
 	mockRepo.On("GetTrends", 2023).Return([]model.BookingTrend{}, assert.AnError)
 
-	result, err := svc.GetTrends(2023)
+	result, err := svc.GetTrends(context.Background(), 2023)
 	assert.Error(t, err)
 	assert.Empty(t, result)
 	mockRepo.AssertExpectations(t)
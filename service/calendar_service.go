@@ -0,0 +1,230 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCalendar = errors.New("calendar feed must be for a driver or a vehicle")
+
+// defaultCalendarLookback keeps trips of the past week in feeds, so calendar
+// apps do not drop a job the moment it ends.
+const defaultCalendarLookback = 7 * 24 * time.Hour
+
+// calendarRefresh is how often subscribers are asked to fetch the feed again.
+const calendarRefresh = "PT15M"
+
+type CalendarServiceInterface interface {
+	GetFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error)
+	IssueFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error)
+	RevokeFeed(ctx context.Context, kind string, subjectID int) error
+	Render(ctx context.Context, token string) ([]byte, error)
+}
+
+// CalendarService publishes driver and vehicle schedules as iCalendar feeds
+// behind secret tokens. Feeds are rendered on every fetch, so they follow
+// booking changes at the subscriber's next refresh.
+type CalendarService struct {
+	Repo     repository.CalendarRepositoryInterface
+	Lookback time.Duration
+}
+
+func NewCalendarService(repo repository.CalendarRepositoryInterface) *CalendarService {
+	return &CalendarService{Repo: repo, Lookback: defaultCalendarLookback}
+}
+
+func (s *CalendarService) GetFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error) {
+	if kind != model.CalendarDriver && kind != model.CalendarVehicle {
+		return nil, ErrInvalidCalendar
+	}
+	f, err := s.Repo.GetFeed(ctx, kind, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, ErrNotFound
+	}
+	return f, nil
+}
+
+// IssueFeed gives the driver or vehicle a feed with a fresh token. Issuing
+// again rotates the token and stops the old URL from working.
+func (s *CalendarService) IssueFeed(ctx context.Context, kind string, subjectID int) (*model.CalendarFeed, error) {
+	schedule, err := s.schedule(ctx, kind, subjectID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, ErrNotFound
+	}
+
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	f := &model.CalendarFeed{Token: token, Kind: kind, SubjectID: subjectID}
+	if err := s.Repo.SaveFeed(ctx, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *CalendarService) RevokeFeed(ctx context.Context, kind string, subjectID int) error {
+	if kind != model.CalendarDriver && kind != model.CalendarVehicle {
+		return ErrInvalidCalendar
+	}
+	return s.Repo.DeleteFeed(ctx, kind, subjectID)
+}
+
+// Render returns the feed behind token as an iCalendar document. The token is
+// the only credential, so it is scoped to the tenant it was issued in.
+func (s *CalendarService) Render(ctx context.Context, token string) ([]byte, error) {
+	f, err := s.Repo.FindFeed(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, ErrNotFound
+	}
+
+	now := time.Now()
+	schedule, err := s.schedule(utils.WithTenant(ctx, f.TenantID), f.Kind, f.SubjectID, now.Add(-s.Lookback))
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, ErrNotFound
+	}
+	return renderCalendar(f, schedule, now), nil
+}
+
+func (s *CalendarService) schedule(ctx context.Context, kind string, subjectID int, from time.Time) (*model.CalendarSchedule, error) {
+	switch kind {
+	case model.CalendarDriver:
+		return s.Repo.DriverSchedule(ctx, subjectID, from)
+	case model.CalendarVehicle:
+		return s.Repo.VehicleSchedule(ctx, subjectID, from)
+	}
+	return nil, ErrInvalidCalendar
+}
+
+func newFeedToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// renderCalendar writes the schedule as an RFC 5545 calendar. Event UIDs are
+// stable across fetches and bookings carry their version as SEQUENCE, so
+// calendar apps update events in place instead of duplicating them.
+func renderCalendar(f *model.CalendarFeed, s *model.CalendarSchedule, now time.Time) []byte {
+	w := &icalWriter{}
+	w.raw("BEGIN", "VCALENDAR")
+	w.raw("VERSION", "2.0")
+	w.raw("PRODID", "-//auth-service//Fleet Schedule//EN")
+	w.raw("CALSCALE", "GREGORIAN")
+	w.raw("METHOD", "PUBLISH")
+	w.text("X-WR-CALNAME", s.Name)
+	w.raw("REFRESH-INTERVAL;VALUE=DURATION", calendarRefresh)
+	w.raw("X-PUBLISHED-TTL", calendarRefresh)
+
+	uid := func(kind string, id any) string {
+		return fmt.Sprintf("%s-%v@tenant-%d.auth-service", kind, id, f.TenantID)
+	}
+
+	for _, b := range s.Bookings {
+		w.raw("BEGIN", "VEVENT")
+		w.raw("UID", uid("booking", b.ID))
+		w.dateTime("DTSTAMP", now)
+		w.dateTime("DTSTART", *b.StartAt)
+		w.dateTime("DTEND", *b.EndAt)
+		w.raw("SEQUENCE", fmt.Sprint(b.Version))
+		if !b.UpdatedAt.IsZero() {
+			w.dateTime("LAST-MODIFIED", b.UpdatedAt)
+		}
+		summary := b.Customer
+		if b.Place != "" {
+			summary += " - " + b.Place
+		}
+		w.text("SUMMARY", summary)
+		if b.PickupLocation != nil && *b.PickupLocation != "" {
+			w.text("LOCATION", *b.PickupLocation)
+		}
+		w.text("DESCRIPTION", bookingDescription(b))
+		if b.Status == model.BookingPending {
+			w.raw("STATUS", "TENTATIVE")
+		} else {
+			w.raw("STATUS", "CONFIRMED")
+		}
+		w.raw("END", "VEVENT")
+	}
+
+	for _, a := range s.Assignments {
+		w.raw("BEGIN", "VEVENT")
+		w.raw("UID", uid("assignment", a.ID))
+		w.dateTime("DTSTAMP", now)
+		w.date("DTSTART", a.StartDate)
+		w.date("DTEND", a.EndDate.AddDate(0, 0, 1))
+		if f.Kind == model.CalendarDriver {
+			w.text("SUMMARY", fmt.Sprintf("Assigned to vehicle %d", a.VehicleID))
+		} else {
+			w.text("SUMMARY", "Assigned to "+a.DriverName)
+		}
+		if a.Status != "" {
+			w.text("DESCRIPTION", "Status: "+a.Status)
+		}
+		w.raw("TRANSP", "TRANSPARENT")
+		w.raw("END", "VEVENT")
+	}
+
+	for _, m := range s.Maintenance {
+		w.raw("BEGIN", "VEVENT")
+		w.raw("UID", uid("maintenance", m.ID))
+		w.dateTime("DTSTAMP", now)
+		w.date("DTSTART", m.ServiceDate)
+		w.date("DTEND", m.ServiceDate.AddDate(0, 0, 1))
+		summary := "Maintenance"
+		if m.ServiceType != "" {
+			summary += ": " + m.ServiceType
+		}
+		if f.Kind == model.CalendarDriver {
+			summary += fmt.Sprintf(" (vehicle %d)", m.VehicleID)
+		}
+		w.text("SUMMARY", summary)
+		if m.Description != "" {
+			w.text("DESCRIPTION", m.Description)
+		}
+		w.raw("END", "VEVENT")
+	}
+
+	w.raw("END", "VCALENDAR")
+	return w.Bytes()
+}
+
+func bookingDescription(b model.Booking) string {
+	lines := []string{"Booking " + b.ID, "Customer: " + b.Customer}
+	if b.PhoneNumber != nil && *b.PhoneNumber != "" {
+		lines = append(lines, "Phone: "+*b.PhoneNumber)
+	}
+	if b.PickupLocation != nil && *b.PickupLocation != "" {
+		lines = append(lines, "Pickup: "+*b.PickupLocation)
+	}
+	if b.DropLocation != nil && *b.DropLocation != "" {
+		lines = append(lines, "Drop-off: "+*b.DropLocation)
+	}
+	lines = append(lines, "Status: "+b.Status)
+	if b.Notes != nil && *b.Notes != "" {
+		lines = append(lines, "Notes: "+*b.Notes)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarService_Feeds(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	drivers := repository.NewMemoryDriverRepository(store)
+	bookings := repository.NewMemoryBookingRepository(store)
+	svc := service.NewCalendarService(repository.NewMemoryCalendarRepository(store))
+
+	_, err := svc.IssueFeed(ctx, model.CalendarDriver, 99)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = svc.IssueFeed(ctx, "customer", 1)
+	assert.ErrorIs(t, err, service.ErrInvalidCalendar)
+
+	d := &model.Driver{Name: "Budi", Status: "active"}
+	require.NoError(t, drivers.Create(ctx, d))
+	_, err = svc.GetFeed(ctx, model.CalendarDriver, d.ID)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Minute)
+	end := start.Add(3 * time.Hour)
+	pickup := "Jl. Asia Afrika No. 8, Bandung; lobby"
+	b := &model.Booking{ID: "BK1", Customer: "Sari", Place: "Jakarta", DriverID: &d.ID, StartAt: &start, EndAt: &end,
+		Status: model.BookingConfirmed, PickupLocation: &pickup}
+	require.NoError(t, bookings.Create(ctx, b))
+
+	feed, err := svc.IssueFeed(ctx, model.CalendarDriver, d.ID)
+	require.NoError(t, err)
+	assert.Len(t, feed.Token, 32)
+
+	// Feeds are fetched without a tenant in the context.
+	body, err := svc.Render(context.Background(), feed.Token)
+	require.NoError(t, err)
+	ics := string(body)
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Contains(t, ics, "X-WR-CALNAME:Budi\r\n")
+	assert.Contains(t, ics, "UID:booking-BK1@tenant-1.auth-service\r\n")
+	assert.Contains(t, ics, "DTSTART:"+start.UTC().Format("20060102T150405Z")+"\r\n")
+	assert.Contains(t, ics, "LOCATION:Jl. Asia Afrika No. 8\\, Bandung\\; lobby\r\n")
+	assert.Contains(t, ics, "SEQUENCE:1\r\n")
+	assert.Contains(t, ics, "STATUS:CONFIRMED\r\n")
+
+	// Edits show up at the next fetch as a new revision of the same event.
+	later := end.Add(time.Hour)
+	b.EndAt = &later
+	require.NoError(t, bookings.Update(ctx, b))
+	body, err = svc.Render(context.Background(), feed.Token)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "SEQUENCE:2\r\n")
+	assert.Equal(t, 1, strings.Count(string(body), "BEGIN:VEVENT"))
+
+	rotated, err := svc.IssueFeed(ctx, model.CalendarDriver, d.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, feed.Token, rotated.Token)
+	_, err = svc.Render(context.Background(), feed.Token)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	current, err := svc.GetFeed(ctx, model.CalendarDriver, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.Token, current.Token)
+
+	require.NoError(t, svc.RevokeFeed(ctx, model.CalendarDriver, d.ID))
+	_, err = svc.Render(context.Background(), rotated.Token)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.ErrorIs(t, svc.RevokeFeed(ctx, model.CalendarDriver, d.ID), service.ErrNotFound)
+}
+
+func TestCalendarService_VehicleFeed(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	cars := repository.NewMemoryCarRepository(store)
+	svc := service.NewCalendarService(repository.NewMemoryCalendarRepository(store))
+
+	require.NoError(t, cars.Create(ctx, model.Car{Brand: "Toyota", Model: "Innova", PlateNumber: "D 1234 AB", Status: "available"}))
+	list, err := cars.GetAll(ctx)
+	require.NoError(t, err)
+	vehicleID := list[0].ID
+
+	day := time.Now().AddDate(0, 0, 3)
+	note := strings.Repeat("Ganti oli mesin dan filter udara — ", 4)
+	require.NoError(t, repository.NewMemoryMaintenanceRepository(store).Create(ctx,
+		&model.VehicleMaintenance{VehicleID: uint(vehicleID), ServiceDate: day, ServiceType: "oil", Description: note}))
+	require.NoError(t, repository.NewMemoryAssignmentsRepository(store).Create(ctx,
+		&model.DriverAssignment{VehicleID: uint(vehicleID), DriverName: "Budi", StartDate: day, EndDate: day.AddDate(0, 0, 6), Status: "active"}))
+
+	feed, err := svc.IssueFeed(ctx, model.CalendarVehicle, vehicleID)
+	require.NoError(t, err)
+	body, err := svc.Render(context.Background(), feed.Token)
+	require.NoError(t, err)
+	ics := string(body)
+
+	assert.Contains(t, ics, "X-WR-CALNAME:Toyota Innova (D 1234 AB)\r\n")
+	assert.Contains(t, ics, "DTSTART;VALUE=DATE:"+day.Format("20060102")+"\r\n")
+	assert.Contains(t, ics, "DTEND;VALUE=DATE:"+day.AddDate(0, 0, 7).Format("20060102")+"\r\n")
+	assert.Contains(t, ics, "SUMMARY:Assigned to Budi\r\n")
+	assert.Contains(t, ics, "SUMMARY:Maintenance: oil\r\n")
+
+	// Long lines are folded within 75 octets without splitting characters,
+	// and unfold back to the original text.
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line), line)
+	}
+	assert.Contains(t, strings.ReplaceAll(ics, "\r\n ", ""), "DESCRIPTION:"+note+"\r\n")
+}
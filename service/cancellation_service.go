@@ -0,0 +1,110 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"math"
+	"strings"
+	"time"
+)
+
+type CancellationServiceInterface interface {
+	GetPolicy(ctx context.Context) (*model.CancellationPolicy, error)
+	SavePolicy(ctx context.Context, p *model.CancellationPolicy) error
+}
+
+type CancellationService struct {
+	Repo repository.CancellationRepositoryInterface
+}
+
+func NewCancellationService(repo repository.CancellationRepositoryInterface) *CancellationService {
+	return &CancellationService{Repo: repo}
+}
+
+// GetPolicy returns the default policy to tenants that have not saved one.
+func (s *CancellationService) GetPolicy(ctx context.Context) (*model.CancellationPolicy, error) {
+	p, err := s.Repo.GetPolicy(ctx)
+	if err != nil || p != nil {
+		return p, err
+	}
+	def := model.DefaultCancellationPolicy()
+	return &def, nil
+}
+
+func (s *CancellationService) SavePolicy(ctx context.Context, p *model.CancellationPolicy) error {
+	return s.Repo.SavePolicy(ctx, p)
+}
+
+// settleCancellation prices cancelling b, or marking it a no-show, under p,
+// when the customer has paid paid towards it.
+func settleCancellation(p model.CancellationPolicy, b *model.Booking, to string, paid float64, now time.Time) *model.BookingCancellation {
+	c := &model.BookingCancellation{Status: to, Paid: roundMoney(paid)}
+	if b.Amount != nil {
+		c.Fare = *b.Amount
+	}
+
+	if b.StartAt != nil {
+		hours := math.Round(b.StartAt.Sub(now).Hours()*100) / 100
+		c.HoursBefore = &hours
+	}
+
+	switch {
+	case to == model.BookingNoShow:
+		c.Fee = c.Fare * p.NoShowFeePercent / 100
+	case c.HoursBefore != nil && *c.HoursBefore < float64(p.FreeHours):
+		c.Fee = math.Max(c.Fare*p.LateFeePercent/100, p.MinimumLateFee)
+	}
+	c.Fee = roundMoney(math.Min(c.Fee, c.Fare))
+	c.Refund = roundMoney(math.Max(c.Paid-c.Fee, 0))
+	return c
+}
+
+// amountPaid is what the customer has paid towards b, net of refunds, and
+// the payments it was paid with. A booking without payments counts as paid in
+// full when its payment is "paid".
+func amountPaid(ctx context.Context, payments repository.PaymentRepositoryInterface, b *model.Booking) (float64, []model.Payment, error) {
+	if payments != nil {
+		list, err := payments.GetByBooking(ctx, b.ID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(list) > 0 {
+			summary := model.SummarisePayments(0, list)
+			return summary.Paid - summary.Refunded, list, nil
+		}
+	}
+	if b.Amount != nil && strings.EqualFold(strings.TrimSpace(b.Payment), model.PaymentPaid) {
+		return *b.Amount, nil, nil
+	}
+	return 0, nil, nil
+}
+
+// cancellationRefunds spreads the refund of c over the captured payments,
+// oldest first, never taking more than is left of one. A payment made through
+// a gateway is refunded there, so its refund stays pending until the gateway
+// has carried it out.
+func cancellationRefunds(c *model.BookingCancellation, payments []model.Payment, providers map[string]PaymentProvider, reason string) []model.PaymentRefund {
+	refunds := []model.PaymentRefund{}
+	left := c.Refund
+	for _, p := range payments {
+		if left <= 0 {
+			break
+		}
+		if p.Status != model.PaymentPaid && p.Status != model.PaymentPartiallyRefunded {
+			continue
+		}
+		amount := roundMoney(math.Min(left, p.Amount-p.RefundedAmount))
+		if amount <= 0 {
+			continue
+		}
+		rf := model.PaymentRefund{PaymentID: p.PaymentID, Amount: amount, Reason: reason, Status: model.RefundSucceeded}
+		if p.ProviderRef != nil && providers[p.Method] != nil {
+			rf.Status = model.RefundPending
+			rf.Gateway = true
+		}
+		refunds = append(refunds, rf)
+		left = roundMoney(left - amount)
+	}
+	return refunds
+}
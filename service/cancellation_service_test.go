@@ -0,0 +1,225 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancellationService_DefaultPolicy(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	svc := service.NewCancellationService(repository.NewMemoryCancellationRepository(store))
+
+	policy, err := svc.GetPolicy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 24, policy.FreeHours)
+	assert.Equal(t, 100.0, policy.NoShowFeePercent)
+
+	require.NoError(t, svc.SavePolicy(ctx, &model.CancellationPolicy{FreeHours: 6, LateFeePercent: 25}))
+	policy, err = svc.GetPolicy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 6, policy.FreeHours)
+	assert.Zero(t, policy.NoShowFeePercent)
+}
+
+func TestBookingService_Cancel(t *testing.T) {
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+
+	tests := []struct {
+		name     string
+		pickupIn time.Duration
+		payment  string
+		amount   float64
+		noShow   bool
+		fee      float64
+		refund   float64
+		charged  float64
+	}{
+		{"free cancellation refunds everything", 48 * time.Hour, "paid", 100000, false, 0, 100000, 0},
+		{"late cancellation keeps the fee", 2 * time.Hour, "paid", 100000, false, 50000, 50000, 0},
+		{"late cancellation charges an unpaid booking", 2 * time.Hour, "unpaid", 100000, false, 50000, 0, 50000},
+		{"minimum fee", 2 * time.Hour, "unpaid", 40000, false, 30000, 0, 30000},
+		{"minimum fee never exceeds the fare", 2 * time.Hour, "unpaid", 20000, false, 20000, 0, 20000},
+		{"no-show", 48 * time.Hour, "paid", 100000, true, 100000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := repository.NewMemoryStore()
+			ctx := utils.WithTenant(context.Background(), 1)
+			cancellations := repository.NewMemoryCancellationRepository(store)
+			require.NoError(t, cancellations.SavePolicy(ctx, &model.CancellationPolicy{FreeHours: 24, LateFeePercent: 50, MinimumLateFee: 30000, NoShowFeePercent: 100}))
+			svc := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Cancellations: cancellations}
+
+			start := time.Now().Add(tt.pickupIn)
+			end := start.Add(time.Hour)
+			amount := tt.amount
+			b := &model.Booking{ID: "BK-cancel", Customer: "Sari", StartAt: &start, EndAt: &end, Amount: &amount, Payment: tt.payment}
+			require.NoError(t, svc.Create(ctx, b))
+
+			var c *model.BookingCancellation
+			if tt.noShow {
+				for _, to := range []string{model.BookingConfirmed, model.BookingDriverAssigned, model.BookingNoShow} {
+					_, err := svc.Transition(ctx, b.ID, admin, model.BookingTransition{To: to})
+					require.NoError(t, err)
+				}
+				var err error
+				c, err = svc.GetCancellation(ctx, b.ID)
+				require.NoError(t, err)
+			} else {
+				cancelled, settled, err := svc.Cancel(ctx, b.ID, admin, "plans changed")
+				require.NoError(t, err)
+				assert.Equal(t, model.BookingCancelled, cancelled.Status)
+				c = settled
+			}
+
+			assert.Equal(t, tt.fee, c.Fee)
+			assert.Equal(t, tt.refund, c.Refund)
+			payments, err := repository.NewMemoryPaymentRepository(store).GetAll(ctx)
+			require.NoError(t, err)
+
+			var charged float64
+			for _, p := range payments {
+				if p.Method == model.PaymentMethodCancellationFee {
+					charged += p.Amount
+					assert.Equal(t, *c.FeePaymentID, p.PaymentID)
+					assert.Equal(t, "pending", p.Status)
+				}
+			}
+			assert.Equal(t, tt.charged, charged)
+			// Without recorded payments there is nothing to refund against.
+			assert.Empty(t, c.Refunds)
+		})
+	}
+}
+
+func TestBookingService_CancelTwice(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	svc := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Cancellations: repository.NewMemoryCancellationRepository(store)}
+	user := &model.User{ID: 2, Role: "user"}
+
+	b := &model.Booking{Customer: "Andi", CreatedBy: &user.ID}
+	require.NoError(t, svc.Create(ctx, b))
+
+	_, err := svc.GetCancellation(ctx, b.ID)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	_, c, err := svc.Cancel(ctx, b.ID, user, "")
+	require.NoError(t, err)
+	assert.Nil(t, c.HoursBefore)
+	assert.Zero(t, c.Fee)
+	assert.Nil(t, c.FeePaymentID)
+	assert.Empty(t, c.Refunds)
+	assert.Equal(t, int64(2), c.CancelledBy)
+
+	_, _, err = svc.Cancel(ctx, b.ID, user, "")
+	assert.ErrorIs(t, err, service.ErrInvalidTransition)
+}
+
+func TestBookingService_CancelRefundsRecordedPayments(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	payments := repository.NewMemoryPaymentRepository(store)
+	svc := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Cancellations: repository.NewMemoryCancellationRepository(store), Payments: payments}
+	paymentService := &service.PaymentService{Repo: payments, Bookings: svc.Repo, Cancellations: svc.Cancellations}
+
+	start := time.Now().Add(2 * time.Hour)
+	end := start.Add(time.Hour)
+	amount := 100000.0
+	b := &model.Booking{ID: "BK-deposit", Customer: "Sari", StartAt: &start, EndAt: &end, Amount: &amount, Payment: "unpaid"}
+	require.NoError(t, svc.Create(ctx, b))
+
+	// A deposit of 70% was paid; the late fee is 50% of the fare.
+	depositID, err := paymentService.CreatePayment(ctx, &model.Payment{BookingID: &b.ID, Amount: 70000, Method: "qris", Status: model.PaymentPaid})
+	require.NoError(t, err)
+
+	// The payment status follows the payments, whatever the edit says.
+	b.Payment = "paid"
+	require.NoError(t, svc.Update(ctx, b))
+	assert.Equal(t, model.BookingPartiallyPaid, b.Payment)
+
+	_, c, err := svc.Cancel(ctx, b.ID, &model.User{ID: 1, Role: model.RoleAdmin}, "")
+	require.NoError(t, err)
+	assert.Equal(t, 70000.0, c.Paid)
+	assert.Equal(t, 50000.0, c.Fee)
+	assert.Equal(t, 20000.0, c.Refund)
+
+	summary, err := paymentService.GetBookingPayments(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.BookingPartiallyRefunded, summary.PaymentStatus)
+	assert.Equal(t, 50000.0, summary.AmountDue)
+	assert.Zero(t, summary.Outstanding)
+	require.Len(t, summary.Payments, 1)
+	assert.Equal(t, 20000.0, summary.Payments[0].RefundedAmount)
+
+	require.Len(t, c.Refunds, 1)
+	assert.Equal(t, depositID, c.Refunds[0].PaymentID)
+	assert.Equal(t, model.RefundSucceeded, c.Refunds[0].Status)
+	assert.Equal(t, "cancellation of booking "+b.ID, c.Refunds[0].Reason)
+	refunds, err := paymentService.GetRefunds(ctx, depositID)
+	require.NoError(t, err)
+	require.Len(t, refunds, 1)
+	assert.Equal(t, c.ID, *refunds[0].CancellationID)
+
+	// The money given back on cancelling cannot be refunded a second time.
+	_, err = paymentService.RefundPayment(ctx, depositID, model.RefundRequest{Amount: 70000, Reason: "refund again"}, nil)
+	assert.ErrorIs(t, err, service.ErrRefundExceedsCaptured)
+	again, err := paymentService.RefundPayment(ctx, depositID, model.RefundRequest{Reason: "refund the rest"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 50000.0, again.Amount)
+	_, err = paymentService.RefundPayment(ctx, depositID, model.RefundRequest{Reason: "refund again"}, nil)
+	assert.ErrorIs(t, err, service.ErrPaymentNotCaptured)
+}
+
+func TestBookingService_CancelRefundsAtGateway(t *testing.T) {
+	gatewayServer := httptest.NewServer(service.NewMockGateway("server-key", "", ""))
+	defer gatewayServer.Close()
+	gateway := &service.Gateway{URL: gatewayServer.URL, ServerKey: "server-key"}
+
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	payments := repository.NewMemoryPaymentRepository(store)
+	providers := map[string]service.PaymentProvider{model.PaymentMethodQRIS: &service.QRISProvider{Gateway: gateway}}
+	svc := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Cancellations: repository.NewMemoryCancellationRepository(store), Payments: payments, Providers: providers}
+	paymentService := &service.PaymentService{Repo: payments, Bookings: svc.Repo, Providers: providers}
+
+	amount := 100000.0
+	b := &model.Booking{Customer: "Sari", Amount: &amount, Payment: "unpaid"}
+	require.NoError(t, svc.Create(ctx, b))
+	p := &model.Payment{BookingID: &b.ID, Amount: 100000, Method: model.PaymentMethodQRIS}
+	var err error
+	p.PaymentID, err = paymentService.CreatePayment(ctx, p)
+	require.NoError(t, err)
+	resp, err := http.Post(gatewayServer.URL+"/"+*p.ProviderRef+"/pay", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	_, err = paymentService.RefreshPayment(ctx, p.PaymentID)
+	require.NoError(t, err)
+
+	// Without a pickup time the booking is cancelled free of charge.
+	_, c, err := svc.Cancel(ctx, b.ID, &model.User{ID: 1, Role: model.RoleAdmin}, "")
+	require.NoError(t, err)
+	assert.Equal(t, 100000.0, c.Refund)
+	require.Len(t, c.Refunds, 1)
+	assert.True(t, c.Refunds[0].Gateway)
+	assert.Equal(t, model.RefundSucceeded, c.Refunds[0].Status)
+
+	charge, err := gateway.Status(context.Background(), *p.ProviderRef)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentStatusRefunded, charge.Status)
+	assert.Equal(t, 100000.0, charge.RefundedAmount)
+
+	_, err = paymentService.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Reason: "refund again"}, nil)
+	assert.ErrorIs(t, err, service.ErrPaymentNotCaptured)
+}
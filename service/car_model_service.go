@@ -3,6 +3,7 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type CarModelService struct {
@@ -13,14 +14,14 @@ func NewCarModelService(repo repository.CarModelRepositoryInterface) *CarModelSe
 	return &CarModelService{repo: repo}
 }
 
-func (s *CarModelService) GetAll() ([]model.CarModel, error) {
-	return s.repo.FindAll()
+func (s *CarModelService) GetAll(ctx context.Context) ([]model.CarModel, error) {
+	return s.repo.FindAll(ctx)
 }
 
-func (s *CarModelService) GetByID(id int) (*model.CarModel, error) {
-	return s.repo.GetByID(id)
+func (s *CarModelService) GetByID(ctx context.Context, id int) (*model.CarModel, error) {
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *CarModelService) Create(cm model.CarModel) error {
-	return s.repo.Create(&cm)
+func (s *CarModelService) Create(ctx context.Context, cm model.CarModel) error {
+	return s.repo.Create(ctx, &cm)
 }
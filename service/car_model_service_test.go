@@ -1,10 +1,11 @@
 package service_test
 
 import (
+	"auth-service/handler"
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"testing"
-	"auth-service/handler" 
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -17,9 +18,11 @@ type MockCarModelRepository struct {
 
 type MockCarModelService struct{}
 
-func (m *MockCarModelService) GetAll() ([]model.CarModel, error) { return nil, nil }
-func (m *MockCarModelService) GetByID(id int) (*model.CarModel, error) { return nil, nil }
-func (m *MockCarModelService) Create(cm model.CarModel) error { return nil }
+func (m *MockCarModelService) GetAll(ctx context.Context) ([]model.CarModel, error) { return nil, nil }
+func (m *MockCarModelService) GetByID(ctx context.Context, id int) (*model.CarModel, error) {
+	return nil, nil
+}
+func (m *MockCarModelService) Create(ctx context.Context, cm model.CarModel) error { return nil }
 
 func TestNewCarModelRepositoryHandler(t *testing.T) {
 	mockSvc := &MockCarModelService{}
@@ -28,17 +31,17 @@ func TestNewCarModelRepositoryHandler(t *testing.T) {
 	assert.NotNil(t, h)
 }
 
-func (m *MockCarModelRepository) FindAll() ([]model.CarModel, error) {
+func (m *MockCarModelRepository) FindAll(ctx context.Context) ([]model.CarModel, error) {
 	args := m.Called()
 	return args.Get(0).([]model.CarModel), args.Error(1)
 }
 
-func (m *MockCarModelRepository) GetByID(id int) (*model.CarModel, error) {
+func (m *MockCarModelRepository) GetByID(ctx context.Context, id int) (*model.CarModel, error) {
 	args := m.Called(id)
 	return args.Get(0).(*model.CarModel), args.Error(1)
 }
 
-func (m *MockCarModelRepository) Create(cm *model.CarModel) error {
+func (m *MockCarModelRepository) Create(ctx context.Context, cm *model.CarModel) error {
 	args := m.Called(cm)
 	return args.Error(0)
 }
@@ -53,7 +56,7 @@ func TestCarModelService_GetAll(t *testing.T) {
 	}
 	mockRepo.On("FindAll").Return(expected, nil)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -65,7 +68,7 @@ func TestCarModelService_GetAll_Error(t *testing.T) {
 
 	mockRepo.On("FindAll").Return([]model.CarModel{}, assert.AnError)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(context.Background())
 	assert.Error(t, err)
 	assert.Empty(t, result)
 	mockRepo.AssertExpectations(t)
@@ -78,7 +81,7 @@ func TestCarModelService_GetByID(t *testing.T) {
 	expected := &model.CarModel{ID: 1, ModelName: "Model A", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	mockRepo.On("GetByID", 1).Return(expected, nil)
 
-	result, err := svc.GetByID(1)
+	result, err := svc.GetByID(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -90,7 +93,7 @@ func TestCarModelService_GetByID_Error(t *testing.T) {
 
 	mockRepo.On("GetByID", 1).Return((*model.CarModel)(nil), assert.AnError)
 
-	result, err := svc.GetByID(1)
+	result, err := svc.GetByID(context.Background(), 1)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	mockRepo.AssertExpectations(t)
@@ -103,7 +106,7 @@ func TestCarModelService_Create(t *testing.T) {
 	cm := model.CarModel{ModelName: "Model A", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	mockRepo.On("Create", &cm).Return(nil)
 
-	err := svc.Create(cm)
+	err := svc.Create(context.Background(), cm)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -115,7 +118,7 @@ func TestCarModelService_Create_Error(t *testing.T) {
 	cm := model.CarModel{ModelName: "Model A", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 	mockRepo.On("Create", &cm).Return(assert.AnError)
 
-	err := svc.Create(cm)
+	err := svc.Create(context.Background(), cm)
 	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type CarService struct {
@@ -13,22 +14,22 @@ func NewCarService(repo repository.CarRepositoryInterface) *CarService {
 	return &CarService{Repo: repo}
 }
 
-func (s *CarService) GetAll() ([]model.Car, error) {
-	return s.Repo.GetAll()
+func (s *CarService) GetAll(ctx context.Context) ([]model.Car, error) {
+	return s.Repo.GetAll(ctx)
 }
 
-func (s *CarService) GetByID(id int) (*model.Car, error) {
-	return s.Repo.GetByID(id)
+func (s *CarService) GetByID(ctx context.Context, id int) (*model.Car, error) {
+	return s.Repo.GetByID(ctx, id)
 }
 
-func (s *CarService) Create(v model.Car) error {
-	return s.Repo.Create(v)
+func (s *CarService) Create(ctx context.Context, v model.Car) error {
+	return s.Repo.Create(ctx, v)
 }
 
-func (s *CarService) Update(id int, v model.Car) error {
-	return s.Repo.Update(id, v)
+func (s *CarService) Update(ctx context.Context, id int, v model.Car) error {
+	return s.Repo.Update(ctx, id, v)
 }
 
-func (s *CarService) Delete(id int) error {
-	return s.Repo.Delete(id)
+func (s *CarService) Delete(ctx context.Context, id int, version int) error {
+	return s.Repo.Delete(ctx, id, version)
 }
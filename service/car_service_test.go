@@ -3,7 +3,9 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -13,31 +15,46 @@ type MockCarRepository struct {
 	mock.Mock
 }
 
-func (m *MockCarRepository) GetAll() ([]model.Car, error) {
+func (m *MockCarRepository) GetAll(ctx context.Context) ([]model.Car, error) {
 	args := m.Called()
 	return args.Get(0).([]model.Car), args.Error(1)
 }
 
-func (m *MockCarRepository) GetByID(id int) (*model.Car, error) {
+func (m *MockCarRepository) GetByID(ctx context.Context, id int) (*model.Car, error) {
 	args := m.Called(id)
 	return args.Get(0).(*model.Car), args.Error(1)
 }
 
-func (m *MockCarRepository) Create(v model.Car) error {
+func (m *MockCarRepository) Create(ctx context.Context, v model.Car) error {
 	args := m.Called(v)
 	return args.Error(0)
 }
 
-func (m *MockCarRepository) Update(id int, v model.Car) error {
+func (m *MockCarRepository) Update(ctx context.Context, id int, v model.Car) error {
 	args := m.Called(id, v)
 	return args.Error(0)
 }
 
-func (m *MockCarRepository) Delete(id int) error {
+func (m *MockCarRepository) Delete(ctx context.Context, id int, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockCarRepository) GetDeleted(ctx context.Context) ([]model.Car, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Car), args.Error(1)
+}
+
+func (m *MockCarRepository) Restore(ctx context.Context, id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+func (m *MockCarRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestCarService_GetAll(t *testing.T) {
 	mockRepo := new(MockCarRepository)
 	svc := service.NewCarService(mockRepo)
@@ -48,7 +65,7 @@ func TestCarService_GetAll(t *testing.T) {
 	}
 	mockRepo.On("GetAll").Return(expected, nil)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -61,7 +78,7 @@ func TestCarService_GetByID(t *testing.T) {
 	expected := &model.Car{ID: 1, Model: "Model A"}
 	mockRepo.On("GetByID", 1).Return(expected, nil)
 
-	result, err := svc.GetByID(1)
+	result, err := svc.GetByID(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -74,7 +91,7 @@ func TestCarService_Create(t *testing.T) {
 	car := model.Car{Model: "Model A"}
 	mockRepo.On("Create", car).Return(nil)
 
-	err := svc.Create(car)
+	err := svc.Create(context.Background(), car)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -86,7 +103,7 @@ func TestCarService_Update(t *testing.T) {
 	car := model.Car{ID: 1, Model: "Updated Model"}
 	mockRepo.On("Update", 1, car).Return(nil)
 
-	err := svc.Update(1, car)
+	err := svc.Update(context.Background(), 1, car)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -95,9 +112,9 @@ func TestCarService_Delete(t *testing.T) {
 	mockRepo := new(MockCarRepository)
 	svc := service.NewCarService(mockRepo)
 
-	mockRepo.On("Delete", 1).Return(nil)
+	mockRepo.On("Delete", 1, 1).Return(nil)
 
-	err := svc.Delete(1)
+	err := svc.Delete(context.Background(), 1, 1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type CarTypeService struct {
@@ -13,14 +14,14 @@ func NewCarTypeService(repo repository.CarTypeRepositoryInterface) *CarTypeServi
 	return &CarTypeService{Repo: repo}
 }
 
-func (s *CarTypeService) GetAll() ([]model.CarType, error) {
-	return s.Repo.FindAll()
+func (s *CarTypeService) GetAll(ctx context.Context) ([]model.CarType, error) {
+	return s.Repo.FindAll(ctx)
 }
 
-func (s *CarTypeService) GetByID(id int) (*model.CarType, error) {
-	return s.Repo.GetByID(id)
+func (s *CarTypeService) GetByID(ctx context.Context, id int) (*model.CarType, error) {
+	return s.Repo.GetByID(ctx, id)
 }
 
-func (s *CarTypeService) Create(ct model.CarType) error {
-	return s.Repo.Create(ct)
+func (s *CarTypeService) Create(ctx context.Context, ct model.CarType) error {
+	return s.Repo.Create(ctx, ct)
 }
@@ -3,6 +3,7 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,17 +14,17 @@ type MockCarTypeRepository struct {
 	mock.Mock
 }
 
-func (m *MockCarTypeRepository) FindAll() ([]model.CarType, error) {
+func (m *MockCarTypeRepository) FindAll(ctx context.Context) ([]model.CarType, error) {
 	args := m.Called()
 	return args.Get(0).([]model.CarType), args.Error(1)
 }
 
-func (m *MockCarTypeRepository) GetByID(id int) (*model.CarType, error) {
+func (m *MockCarTypeRepository) GetByID(ctx context.Context, id int) (*model.CarType, error) {
 	args := m.Called(id)
 	return args.Get(0).(*model.CarType), args.Error(1)
 }
 
-func (m *MockCarTypeRepository) Create(ct model.CarType) error {
+func (m *MockCarTypeRepository) Create(ctx context.Context, ct model.CarType) error {
 	args := m.Called(ct)
 	return args.Error(0)
 }
@@ -38,7 +39,7 @@ func TestCarTypeService_GetAll(t *testing.T) {
 	}
 	mockRepo.On("FindAll").Return(expected, nil)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -51,7 +52,7 @@ func TestCarTypeService_GetByID(t *testing.T) {
 	expected := &model.CarType{ID: "1", TypeName: "Sedan"}
 	mockRepo.On("GetByID", 1).Return(expected, nil)
 
-	result, err := svc.GetByID(1)
+	result, err := svc.GetByID(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -64,7 +65,7 @@ func TestCarTypeService_Create(t *testing.T) {
 	ct := model.CarType{TypeName: "Sedan"}
 	mockRepo.On("Create", ct).Return(nil)
 
-	err := svc.Create(ct)
+	err := svc.Create(context.Background(), ct)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
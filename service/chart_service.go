@@ -3,6 +3,7 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type PopularDestinationService struct {
@@ -10,25 +11,25 @@ type PopularDestinationService struct {
 }
 
 type PopularDestinationServiceInterface interface {
-	GetAll() ([]model.PopularDestination, error)
+	GetAll(ctx context.Context) ([]model.PopularDestination, error)
 }
 
-func (s *PopularDestinationService) GetAll() ([]model.PopularDestination, error) {
-	return s.Repo.GetAll()
+func (s *PopularDestinationService) GetAll(ctx context.Context) ([]model.PopularDestination, error) {
+	return s.Repo.GetAll(ctx)
 }
 
-func (s *PopularDestinationService) Add(destination string, bookings int) (*model.PopularDestination, error) {
+func (s *PopularDestinationService) Add(ctx context.Context, destination string, bookings int) (*model.PopularDestination, error) {
 	pd := model.PopularDestination{
 		Destination: destination,
 		Bookings:    bookings,
 	}
-	return s.Repo.Add(pd)
+	return s.Repo.Add(ctx, pd)
 }
 
-func (s *PopularDestinationService) UpdateBookings(id int, bookings int) error {
-	return s.Repo.UpdateBookings(id, bookings)
+func (s *PopularDestinationService) UpdateBookings(ctx context.Context, id int, bookings int) error {
+	return s.Repo.UpdateBookings(ctx, id, bookings)
 }
 
-func (s *PopularDestinationService) Delete(id int) error {
-	return s.Repo.Delete(id)
+func (s *PopularDestinationService) Delete(ctx context.Context, id int) error {
+	return s.Repo.Delete(ctx, id)
 }
@@ -3,6 +3,7 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"testing"
 	"time"
 
@@ -14,22 +15,22 @@ type MockPopularDestinationRepository struct {
 	mock.Mock
 }
 
-func (m *MockPopularDestinationRepository) GetAll() ([]model.PopularDestination, error) {
+func (m *MockPopularDestinationRepository) GetAll(ctx context.Context) ([]model.PopularDestination, error) {
 	args := m.Called()
 	return args.Get(0).([]model.PopularDestination), args.Error(1)
 }
 
-func (m *MockPopularDestinationRepository) Add(pd model.PopularDestination) (*model.PopularDestination, error) {
+func (m *MockPopularDestinationRepository) Add(ctx context.Context, pd model.PopularDestination) (*model.PopularDestination, error) {
 	args := m.Called(pd)
 	return args.Get(0).(*model.PopularDestination), args.Error(1)
 }
 
-func (m *MockPopularDestinationRepository) UpdateBookings(id int, bookings int) error {
+func (m *MockPopularDestinationRepository) UpdateBookings(ctx context.Context, id int, bookings int) error {
 	args := m.Called(id, bookings)
 	return args.Error(0)
 }
 
-func (m *MockPopularDestinationRepository) Delete(id int) error {
+func (m *MockPopularDestinationRepository) Delete(ctx context.Context, id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -44,7 +45,7 @@ func TestPopularDestinationService_GetAll(t *testing.T) {
 	}
 	mockRepo.On("GetAll").Return(expected, nil)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -56,7 +57,7 @@ func TestPopularDestinationService_GetAll_Error(t *testing.T) {
 
 	mockRepo.On("GetAll").Return([]model.PopularDestination{}, assert.AnError)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(context.Background())
 	assert.Error(t, err)
 	assert.Empty(t, result)
 	mockRepo.AssertExpectations(t)
@@ -70,7 +71,7 @@ func TestPopularDestinationService_Add(t *testing.T) {
 	expected := &model.PopularDestination{ID: 1, Destination: "Jakarta", Bookings: 100, CreatedAt: time.Now()}
 	mockRepo.On("Add", input).Return(expected, nil)
 
-	result, err := svc.Add("Jakarta", 100)
+	result, err := svc.Add(context.Background(), "Jakarta", 100)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -83,7 +84,7 @@ func TestPopularDestinationService_Add_Error(t *testing.T) {
 	input := model.PopularDestination{Destination: "Jakarta", Bookings: 100}
 	mockRepo.On("Add", input).Return((*model.PopularDestination)(nil), assert.AnError)
 
-	result, err := svc.Add("Jakarta", 100)
+	result, err := svc.Add(context.Background(), "Jakarta", 100)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	mockRepo.AssertExpectations(t)
@@ -95,7 +96,7 @@ func TestPopularDestinationService_UpdateBookings(t *testing.T) {
 
 	mockRepo.On("UpdateBookings", 1, 150).Return(nil)
 
-	err := svc.UpdateBookings(1, 150)
+	err := svc.UpdateBookings(context.Background(), 1, 150)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -106,7 +107,7 @@ func TestPopularDestinationService_UpdateBookings_Error(t *testing.T) {
 
 	mockRepo.On("UpdateBookings", 1, 150).Return(assert.AnError)
 
-	err := svc.UpdateBookings(1, 150)
+	err := svc.UpdateBookings(context.Background(), 1, 150)
 	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -117,7 +118,7 @@ func TestPopularDestinationService_Delete(t *testing.T) {
 
 	mockRepo.On("Delete", 1).Return(nil)
 
-	err := svc.Delete(1)
+	err := svc.Delete(context.Background(), 1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -128,7 +129,7 @@ func TestPopularDestinationService_Delete_Error(t *testing.T) {
 
 	mockRepo.On("Delete", 1).Return(assert.AnError)
 
-	err := svc.Delete(1)
+	err := svc.Delete(context.Background(), 1)
 	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownAccount      = errors.New("corporate account not found")
+	ErrAccountInactive     = errors.New("corporate account is not active")
+	ErrBookerNotAuthorised = errors.New("customer is not an authorised booker for this corporate account")
+	ErrInvalidCostCentre   = errors.New("cost centre is not one of the corporate account's cost centres")
+	ErrCreditLimitExceeded = errors.New("booking would take the corporate account over its credit limit")
+	ErrAccountNameTaken    = errors.New("corporate account name already exists")
+	ErrInvoiceExists       = errors.New("account has already been invoiced for this period")
+	ErrNothingToInvoice    = errors.New("account has no uninvoiced trips in this period")
+	ErrInvoiceOverpaid     = repository.ErrInvoiceOverpaid
+)
+
+type CorporateServiceInterface interface {
+	GetAccounts(ctx context.Context) ([]model.CorporateAccount, error)
+	GetAccount(ctx context.Context, id int) (*model.CorporateAccount, error)
+	CreateAccount(ctx context.Context, a *model.CorporateAccount) error
+	UpdateAccount(ctx context.Context, a *model.CorporateAccount) error
+	GetBalance(ctx context.Context, id int) (*model.AccountBalance, error)
+	GetInvoices(ctx context.Context, accountID int) ([]model.CorporateInvoice, error)
+	GetInvoice(ctx context.Context, id int) (*model.CorporateInvoice, error)
+	Invoice(ctx context.Context, accountID int, month time.Time) (*model.CorporateInvoice, error)
+	RecordPayment(ctx context.Context, invoiceID int, p *model.InvoicePayment) (*model.CorporateInvoice, error)
+	InvoicePDF(ctx context.Context, id int) ([]byte, string, error)
+}
+
+type CorporateService struct {
+	Repo repository.CorporateRepositoryInterface
+	PDF  *PDFService
+}
+
+func NewCorporateService(repo repository.CorporateRepositoryInterface) *CorporateService {
+	return &CorporateService{Repo: repo}
+}
+
+func (s *CorporateService) GetAccounts(ctx context.Context) ([]model.CorporateAccount, error) {
+	return s.Repo.GetAccounts(ctx)
+}
+
+func (s *CorporateService) GetAccount(ctx context.Context, id int) (*model.CorporateAccount, error) {
+	return s.Repo.GetAccount(ctx, id)
+}
+
+// normalizeCostCentres trims the account's cost centres and drops blanks and
+// repeats.
+func normalizeCostCentres(a *model.CorporateAccount) {
+	seen := map[string]bool{}
+	centres := []string{}
+	for _, c := range a.CostCentres {
+		c = strings.TrimSpace(c)
+		if c != "" && !seen[c] {
+			seen[c] = true
+			centres = append(centres, c)
+		}
+	}
+	a.CostCentres = centres
+}
+
+func (s *CorporateService) CreateAccount(ctx context.Context, a *model.CorporateAccount) error {
+	normalizeCostCentres(a)
+	if a.PaymentTermsDays == 0 {
+		a.PaymentTermsDays = 30
+	}
+	err := s.Repo.CreateAccount(ctx, a)
+	if errors.Is(err, repository.ErrDuplicateKey) {
+		return ErrAccountNameTaken
+	}
+	return err
+}
+
+func (s *CorporateService) UpdateAccount(ctx context.Context, a *model.CorporateAccount) error {
+	normalizeCostCentres(a)
+	err := s.Repo.UpdateAccount(ctx, a)
+	if errors.Is(err, repository.ErrDuplicateKey) {
+		return ErrAccountNameTaken
+	}
+	return err
+}
+
+// GetBalance reports what the account owes and, for accounts with a credit
+// limit, how much credit is left.
+func (s *CorporateService) GetBalance(ctx context.Context, id int) (*model.AccountBalance, error) {
+	a, err := s.Repo.GetAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrNotFound
+	}
+
+	b, err := s.Repo.GetBalance(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b.CreditLimit = a.CreditLimit
+	if a.CreditLimit != nil {
+		available := math.Max(0, *a.CreditLimit-b.Unbilled-b.Outstanding)
+		b.Available = &available
+	}
+	return b, nil
+}
+
+func (s *CorporateService) GetInvoices(ctx context.Context, accountID int) ([]model.CorporateInvoice, error) {
+	return s.Repo.GetInvoices(ctx, accountID)
+}
+
+func (s *CorporateService) GetInvoice(ctx context.Context, id int) (*model.CorporateInvoice, error) {
+	return s.Repo.GetInvoice(ctx, id)
+}
+
+// invoicePeriod returns the first day of the calendar month holding t and the
+// first day of the month after.
+func invoicePeriod(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+// Invoice bills the account for the calendar month holding month. Trips
+// completed earlier that were never invoiced are carried into it.
+func (s *CorporateService) Invoice(ctx context.Context, accountID int, month time.Time) (*model.CorporateInvoice, error) {
+	a, err := s.Repo.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrNotFound
+	}
+	return s.invoice(ctx, a, month, time.Now())
+}
+
+func (s *CorporateService) invoice(ctx context.Context, a *model.CorporateAccount, month, now time.Time) (*model.CorporateInvoice, error) {
+	start, end := invoicePeriod(month)
+
+	lines, err := s.Repo.GetUninvoiced(ctx, a.ID, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, ErrNothingToInvoice
+	}
+
+	inv := &model.CorporateInvoice{
+		AccountID:   a.ID,
+		AccountName: a.Name,
+		Number:      fmt.Sprintf("INV-%s-%04d", start.Format("200601"), a.ID),
+		PeriodStart: start,
+		PeriodEnd:   end.AddDate(0, 0, -1),
+		Status:      model.InvoiceIssued,
+		IssuedAt:    now,
+		DueAt:       now.AddDate(0, 0, a.PaymentTermsDays),
+		Lines:       lines,
+	}
+	for _, l := range lines {
+		inv.Total += l.Amount
+	}
+	inv.Total = math.Round(inv.Total*100) / 100
+
+	err = s.Repo.CreateInvoice(ctx, inv)
+	if errors.Is(err, repository.ErrDuplicateKey) {
+		return nil, ErrInvoiceExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// RunMonthly invoices every active account in every tenant for the month
+// before now and returns how many invoices it issued. Accounts already
+// invoiced for that month or without trips are skipped, so the job can run
+// more often than monthly.
+func (s *CorporateService) RunMonthly(ctx context.Context, now time.Time) (int, error) {
+	accounts, err := s.Repo.GetBillable(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	current, _ := invoicePeriod(now)
+	month := current.AddDate(0, -1, 0)
+
+	n := 0
+	for i := range accounts {
+		_, err := s.invoice(utils.WithTenant(ctx, accounts[i].TenantID), &accounts[i], month, now)
+		if errors.Is(err, ErrInvoiceExists) || errors.Is(err, ErrNothingToInvoice) {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// RecordPayment books a payment received against an invoice and returns the
+// invoice with its new status.
+func (s *CorporateService) RecordPayment(ctx context.Context, invoiceID int, p *model.InvoicePayment) (*model.CorporateInvoice, error) {
+	inv, err := s.Repo.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrNotFound
+	}
+
+	p.InvoiceID = invoiceID
+	if p.Method == "" {
+		p.Method = "transfer"
+	}
+	if p.PaidAt.IsZero() {
+		p.PaidAt = time.Now()
+	}
+	if err := s.Repo.AddInvoicePayment(ctx, p); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetInvoice(ctx, invoiceID)
+}
+
+func (s *CorporateService) InvoicePDF(ctx context.Context, id int) ([]byte, string, error) {
+	inv, err := s.Repo.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if inv == nil {
+		return nil, "", ErrNotFound
+	}
+	a, err := s.Repo.GetAccount(ctx, inv.AccountID)
+	if err != nil {
+		return nil, "", err
+	}
+	if a == nil {
+		return nil, "", ErrNotFound
+	}
+	return s.PDF.GenerateInvoicePDF(inv, a)
+}
+
+// chargeToAccount checks a booking charged to a corporate account: the account
+// must be active, the customer one of its bookers, the cost centre one of its
+// own, and the booking must fit in what is left of its credit. current is the
+// saved booking on update, whose amount is already part of the balance.
+func chargeToAccount(ctx context.Context, repo repository.CorporateRepositoryInterface, b, current *model.Booking) error {
+	if b.CostCentre != nil && strings.TrimSpace(*b.CostCentre) == "" {
+		b.CostCentre = nil
+	}
+	if b.CorporateAccountID == nil {
+		b.CostCentre = nil
+		return nil
+	}
+	if repo == nil {
+		return ErrUnknownAccount
+	}
+
+	a, err := repo.GetAccount(ctx, *b.CorporateAccountID)
+	if err != nil {
+		return err
+	}
+	if a == nil {
+		return ErrUnknownAccount
+	}
+	if !a.Active {
+		return ErrAccountInactive
+	}
+
+	authorised := false
+	for _, id := range a.BookerIDs {
+		authorised = authorised || (b.CustomerID != nil && id == *b.CustomerID)
+	}
+	if !authorised {
+		return ErrBookerNotAuthorised
+	}
+
+	if b.CostCentre != nil {
+		centre := strings.TrimSpace(*b.CostCentre)
+		b.CostCentre = &centre
+	}
+	if len(a.CostCentres) > 0 {
+		valid := false
+		for _, c := range a.CostCentres {
+			valid = valid || (b.CostCentre != nil && c == *b.CostCentre)
+		}
+		if !valid {
+			return ErrInvalidCostCentre
+		}
+	}
+
+	if a.CreditLimit == nil || b.Amount == nil {
+		return nil
+	}
+	balance, err := repo.GetBalance(ctx, a.ID)
+	if err != nil {
+		return err
+	}
+	used := balance.Unbilled + balance.Outstanding
+	if current != nil && current.CorporateAccountID != nil && *current.CorporateAccountID == a.ID && current.Amount != nil {
+		used -= *current.Amount
+	}
+	if used+*b.Amount > *a.CreditLimit {
+		return ErrCreditLimitExceeded
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorporateService_BookOnAccount(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	corporate := repository.NewMemoryCorporateRepository(store)
+	svc := service.NewCorporateService(corporate)
+	bookings := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Corporate: corporate}
+
+	limit := 500000.0
+	account := &model.CorporateAccount{Name: "PT Maju", BookerIDs: []int{4}, CostCentres: []string{" SALES ", "OPS", "SALES"}, CreditLimit: &limit, Active: true}
+	require.NoError(t, svc.CreateAccount(ctx, account))
+	assert.Equal(t, []string{"SALES", "OPS"}, account.CostCentres)
+	assert.Equal(t, 30, account.PaymentTermsDays)
+	assert.ErrorIs(t, svc.CreateAccount(ctx, &model.CorporateAccount{Name: "PT Maju"}), service.ErrAccountNameTaken)
+
+	booker, stranger, unknown := 4, 5, 99
+	sales, marketing := "SALES", "MARKETING"
+	book := func(customerID, accountID *int, centre *string, amount float64) (*model.Booking, error) {
+		b := &model.Booking{Customer: "Sari", CustomerID: customerID, CorporateAccountID: accountID, CostCentre: centre, Amount: &amount}
+		return b, bookings.Create(ctx, b)
+	}
+
+	_, err := book(&booker, &unknown, &sales, 100000)
+	assert.ErrorIs(t, err, service.ErrUnknownAccount)
+	_, err = book(&stranger, &account.ID, &sales, 100000)
+	assert.ErrorIs(t, err, service.ErrBookerNotAuthorised)
+	_, err = book(nil, &account.ID, &sales, 100000)
+	assert.ErrorIs(t, err, service.ErrBookerNotAuthorised)
+	_, err = book(&booker, &account.ID, &marketing, 100000)
+	assert.ErrorIs(t, err, service.ErrInvalidCostCentre)
+	_, err = book(&booker, &account.ID, nil, 100000)
+	assert.ErrorIs(t, err, service.ErrInvalidCostCentre)
+
+	first, err := book(&booker, &account.ID, &sales, 300000)
+	require.NoError(t, err)
+	_, err = book(&booker, &account.ID, &sales, 250000)
+	assert.ErrorIs(t, err, service.ErrCreditLimitExceeded)
+
+	balance, err := svc.GetBalance(ctx, account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 300000.0, balance.Unbilled)
+	require.NotNil(t, balance.Available)
+	assert.Equal(t, 200000.0, *balance.Available)
+
+	// The booking's own amount is already on the balance when it is edited.
+	raised := 450000.0
+	first.Amount = &raised
+	require.NoError(t, bookings.Update(ctx, first))
+
+	// Bookings not charged to an account drop a stray cost centre.
+	private, err := book(&stranger, nil, &sales, 100000)
+	require.NoError(t, err)
+	assert.Nil(t, private.CostCentre)
+
+	account.Active = false
+	require.NoError(t, svc.UpdateAccount(ctx, account))
+	_, err = book(&booker, &account.ID, &sales, 10000)
+	assert.ErrorIs(t, err, service.ErrAccountInactive)
+}
+
+func TestCorporateService_MonthlyInvoices(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tenants := repository.NewMemoryTenantRepository(store)
+	second := &model.Tenant{Name: "Second", Slug: "second"}
+	require.NoError(t, tenants.Create(context.Background(), second))
+
+	corporate := repository.NewMemoryCorporateRepository(store)
+	bookingRepo := repository.NewMemoryBookingRepository(store)
+	svc := service.NewCorporateService(corporate)
+
+	september := time.Date(2026, 9, 14, 9, 0, 0, 0, time.Local)
+	october := time.Date(2026, 10, 3, 9, 0, 0, 0, time.Local)
+	now := time.Date(2026, 10, 5, 1, 0, 0, 0, time.Local)
+
+	seed := func(ctx context.Context, name string, amounts ...float64) *model.CorporateAccount {
+		account := &model.CorporateAccount{Name: name, PaymentTermsDays: 14, Active: true}
+		require.NoError(t, svc.CreateAccount(ctx, account))
+		for i, amount := range amounts {
+			at := september
+			if i == len(amounts)-1 {
+				at = october
+			}
+			end := at.Add(time.Hour)
+			require.NoError(t, bookingRepo.Create(ctx, &model.Booking{Customer: "Sari", Place: "Bandung", Status: model.BookingCompleted,
+				Amount: &amount, StartAt: &at, EndAt: &end, CorporateAccountID: &account.ID}))
+		}
+		return account
+	}
+	first := utils.WithTenant(context.Background(), 1)
+	other := utils.WithTenant(context.Background(), second.ID)
+	maju := seed(first, "PT Maju", 300000, 125000.5, 80000)
+	jaya := seed(other, "CV Jaya", 210000, 60000)
+	seed(first, "PT Kosong")
+
+	n, err := svc.RunMonthly(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	invoices, err := svc.GetInvoices(first, maju.ID)
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+	inv, err := svc.GetInvoice(first, invoices[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "INV-202609-0001", inv.Number)
+	assert.Equal(t, 425000.5, inv.Total)
+	assert.Len(t, inv.Lines, 2)
+	assert.Equal(t, model.InvoiceIssued, inv.Status)
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local), inv.PeriodStart)
+	assert.Equal(t, time.Date(2026, 9, 30, 0, 0, 0, 0, time.Local), inv.PeriodEnd)
+	assert.Equal(t, now.AddDate(0, 0, 14), inv.DueAt)
+
+	invoices, err = svc.GetInvoices(other, jaya.ID)
+	require.NoError(t, err)
+	require.Len(t, invoices, 1)
+	assert.Equal(t, 210000.0, invoices[0].Total)
+
+	// A second run in the same month finds nothing left to bill.
+	n, err = svc.RunMonthly(context.Background(), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	_, err = svc.Invoice(first, maju.ID, september)
+	assert.ErrorIs(t, err, service.ErrNothingToInvoice)
+
+	next, err := svc.Invoice(first, maju.ID, october)
+	require.NoError(t, err)
+	assert.Len(t, next.Lines, 1)
+
+	_, err = svc.RecordPayment(first, inv.ID, &model.InvoicePayment{Amount: 500000})
+	assert.ErrorIs(t, err, service.ErrInvoiceOverpaid)
+	paid, err := svc.RecordPayment(first, inv.ID, &model.InvoicePayment{Amount: 25000.5, Reference: "TRF-9"})
+	require.NoError(t, err)
+	assert.Equal(t, model.InvoicePartiallyPaid, paid.Status)
+	require.Len(t, paid.Payments, 1)
+	assert.Equal(t, "transfer", paid.Payments[0].Method)
+	paid, err = svc.RecordPayment(first, inv.ID, &model.InvoicePayment{Amount: 400000})
+	require.NoError(t, err)
+	assert.Equal(t, model.InvoicePaid, paid.Status)
+
+	_, err = svc.RecordPayment(other, inv.ID, &model.InvoicePayment{Amount: 1})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+type MockInvoiceRenderer struct{ mock.Mock }
+
+func (m *MockInvoiceRenderer) RenderInvoice(data model.InvoiceTemplateData) (string, error) {
+	args := m.Called(data)
+	return args.String(0), args.Error(1)
+}
+
+func TestCorporateService_InvoicePDF(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	corporate := repository.NewMemoryCorporateRepository(store)
+	gen, tpl := new(MockPDFGenerator), new(MockInvoiceRenderer)
+	svc := service.NewCorporateService(corporate)
+	svc.PDF = &service.PDFService{PDFGenerator: gen, InvoiceRenderer: tpl}
+
+	account := &model.CorporateAccount{Name: "PT Maju", TaxID: "01.234.567.8-901.000", Active: true}
+	require.NoError(t, svc.CreateAccount(ctx, account))
+	inv := &model.CorporateInvoice{AccountID: account.ID, Number: "INV-202609-0001", Total: 300000, Status: model.InvoiceIssued}
+	require.NoError(t, corporate.CreateInvoice(ctx, inv))
+
+	tpl.On("RenderInvoice", mock.MatchedBy(func(d model.InvoiceTemplateData) bool {
+		return d.Number == inv.Number && d.TaxID == account.TaxID && d.Outstanding == 300000
+	})).Return("<html>invoice</html>", nil)
+	gen.On("GeneratePDF", "<html>invoice</html>").Return([]byte("pdf"), nil)
+
+	pdf, filename, err := svc.InvoicePDF(ctx, inv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pdf"), pdf)
+	assert.Equal(t, "invoice_INV-202609-0001.pdf", filename)
+
+	_, _, err = svc.InvoicePDF(ctx, 99)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
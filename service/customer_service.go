@@ -0,0 +1,90 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"errors"
+)
+
+var (
+	ErrUnknownCustomer     = errors.New("customer not found")
+	ErrCustomerBlacklisted = errors.New("customer is blacklisted")
+)
+
+type CustomerServiceInterface interface {
+	Search(ctx context.Context, query string) ([]model.Customer, error)
+	GetByID(ctx context.Context, id int) (*model.Customer, error)
+	GetDetail(ctx context.Context, id int) (*model.CustomerDetail, error)
+	Create(ctx context.Context, c *model.Customer) error
+	Update(ctx context.Context, c *model.Customer) error
+	Delete(ctx context.Context, id int, version int) error
+}
+
+type CustomerService struct {
+	Repo repository.CustomerRepositoryInterface
+}
+
+func NewCustomerService(repo repository.CustomerRepositoryInterface) *CustomerService {
+	return &CustomerService{Repo: repo}
+}
+
+func (s *CustomerService) Search(ctx context.Context, query string) ([]model.Customer, error) {
+	return s.Repo.Search(ctx, query)
+}
+
+func (s *CustomerService) GetByID(ctx context.Context, id int) (*model.Customer, error) {
+	return s.Repo.GetByID(ctx, id)
+}
+
+func (s *CustomerService) GetDetail(ctx context.Context, id int) (*model.CustomerDetail, error) {
+	c, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, ErrNotFound
+	}
+
+	detail := &model.CustomerDetail{Customer: *c}
+	if detail.Bookings, err = s.Repo.GetBookings(ctx, id); err != nil {
+		return nil, err
+	}
+	if detail.Payments, err = s.Repo.GetPayments(ctx, id); err != nil {
+		return nil, err
+	}
+	if detail.Trips, err = s.Repo.GetTrips(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return detail, nil
+}
+
+func (s *CustomerService) Create(ctx context.Context, c *model.Customer) error {
+	return s.Repo.Create(ctx, c)
+}
+
+func (s *CustomerService) Update(ctx context.Context, c *model.Customer) error {
+	return s.Repo.Update(ctx, c)
+}
+
+func (s *CustomerService) Delete(ctx context.Context, id int, version int) error {
+	return s.Repo.Delete(ctx, id, version)
+}
+
+// lookupCustomer resolves a customer_id sent with a booking or payment. A nil
+// id, or a service built without a customer repository, resolves to nil.
+func lookupCustomer(ctx context.Context, repo repository.CustomerRepositoryInterface, id *int) (*model.Customer, error) {
+	if id == nil || repo == nil {
+		return nil, nil
+	}
+
+	c, err := repo.GetByID(ctx, *id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, ErrUnknownCustomer
+	}
+	return c, nil
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCustomerRepository struct {
+	mock.Mock
+}
+
+func (m *MockCustomerRepository) Search(ctx context.Context, query string) ([]model.Customer, error) {
+	args := m.Called(query)
+	return args.Get(0).([]model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) GetByID(ctx context.Context, id int) (*model.Customer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) Create(ctx context.Context, c *model.Customer) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) Update(ctx context.Context, c *model.Customer) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) Delete(ctx context.Context, id int, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) GetBookings(ctx context.Context, id int) ([]model.Booking, error) {
+	args := m.Called(id)
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
+
+func (m *MockCustomerRepository) GetPayments(ctx context.Context, id int) ([]model.Payment, error) {
+	args := m.Called(id)
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
+
+func (m *MockCustomerRepository) GetTrips(ctx context.Context, id int) ([]model.TripHistory, error) {
+	args := m.Called(id)
+	return args.Get(0).([]model.TripHistory), args.Error(1)
+}
+
+func TestCustomerService_GetDetail(t *testing.T) {
+	repo := new(MockCustomerRepository)
+	svc := service.NewCustomerService(repo)
+
+	repo.On("GetByID", 4).Return(&model.Customer{ID: 4, Name: "Sari"}, nil)
+	repo.On("GetBookings", 4).Return([]model.Booking{{ID: "BK1"}}, nil)
+	repo.On("GetPayments", 4).Return([]model.Payment{{PaymentID: 9}}, nil)
+	repo.On("GetTrips", 4).Return([]model.TripHistory{{BookingCode: "BK1"}}, nil)
+	repo.On("GetByID", 5).Return(nil, nil)
+
+	detail, err := svc.GetDetail(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sari", detail.Name)
+	assert.Len(t, detail.Bookings, 1)
+	assert.Len(t, detail.Payments, 1)
+	assert.Len(t, detail.Trips, 1)
+
+	_, err = svc.GetDetail(context.Background(), 5)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestBookingService_CreateWithCustomer(t *testing.T) {
+	bookings := new(MockBookingRepository)
+	customers := new(MockCustomerRepository)
+	svc := &service.BookingService{Repo: bookings, Customers: customers}
+
+	customers.On("GetByID", 4).Return(&model.Customer{ID: 4, Name: "Sari", Phones: []string{"0812"}}, nil)
+	customers.On("GetByID", 5).Return(&model.Customer{ID: 5, Name: "Andi", Blacklisted: true}, nil)
+	customers.On("GetByID", 6).Return(nil, nil)
+	bookings.On("Create", mock.Anything).Return(nil)
+
+	id := 4
+	b := &model.Booking{CustomerID: &id}
+	assert.NoError(t, svc.Create(context.Background(), b))
+	assert.Equal(t, "Sari", b.Customer)
+	assert.Equal(t, "0812", *b.PhoneNumber)
+
+	blacklisted := 5
+	err := svc.Create(context.Background(), &model.Booking{CustomerID: &blacklisted})
+	assert.ErrorIs(t, err, service.ErrCustomerBlacklisted)
+
+	unknown := 6
+	err = svc.Create(context.Background(), &model.Booking{CustomerID: &unknown})
+	assert.ErrorIs(t, err, service.ErrUnknownCustomer)
+
+	bookings.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestPaymentService_CreateWithCustomer(t *testing.T) {
+	payments := new(MockPaymentRepository)
+	customers := new(MockCustomerRepository)
+	svc := &service.PaymentService{Repo: payments, Customers: customers}
+
+	id := 4
+	customers.On("GetByID", 4).Return(&model.Customer{ID: 4, Name: "Sari"}, nil)
+	payments.On("Create", mock.Anything, mock.Anything).Return(7, nil)
+
+	p := &model.Payment{CustomerID: &id, Amount: 1000}
+	paymentID, err := svc.CreatePayment(context.Background(), p)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, paymentID)
+	assert.Equal(t, "Sari", p.Customer)
+}
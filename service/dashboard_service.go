@@ -1,9 +1,12 @@
 package service
 
-import "auth-service/repository"
+import (
+	"auth-service/repository"
+	"context"
+)
 
 type DashboardServiceInterface interface {
-	GetDashboardData() (map[string]interface{}, error)
+	GetDashboardData(ctx context.Context) (map[string]interface{}, error)
 }
 
 type DashboardService struct {
@@ -14,18 +17,18 @@ func NewDashboardService(repo repository.DashboardRepositoryInterface) *Dashboar
 	return &DashboardService{repo: repo}
 }
 
-func (s *DashboardService) GetDashboardData() (map[string]interface{}, error) {
-	bookings, err := s.repo.GetTotalBookings()
+func (s *DashboardService) GetDashboardData(ctx context.Context) (map[string]interface{}, error) {
+	bookings, err := s.repo.GetTotalBookings(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	drivers, err := s.repo.GetActiveDrivers()
+	drivers, err := s.repo.GetActiveDrivers(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	revenue, err := s.repo.GetTotalRevenue()
+	revenue, err := s.repo.GetTotalRevenue(ctx)
 	if err != nil {
 		return nil, err
 	}
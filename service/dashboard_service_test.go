@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"auth-service/service"
+	"context"
 	"errors"
 	"testing"
 
@@ -13,17 +14,17 @@ type MockDashboardRepository struct {
 	mock.Mock
 }
 
-func (m *MockDashboardRepository) GetTotalBookings() (int, error) {
+func (m *MockDashboardRepository) GetTotalBookings(ctx context.Context) (int, error) {
 	args := m.Called()
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockDashboardRepository) GetActiveDrivers() (int, error) {
+func (m *MockDashboardRepository) GetActiveDrivers(ctx context.Context) (int, error) {
 	args := m.Called()
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockDashboardRepository) GetTotalRevenue() (float64, error) {
+func (m *MockDashboardRepository) GetTotalRevenue(ctx context.Context) (float64, error) {
 	args := m.Called()
 	return args.Get(0).(float64), args.Error(1)
 }
@@ -36,7 +37,7 @@ func TestDashboardService_GetDashboardData(t *testing.T) {
 	mockRepo.On("GetActiveDrivers").Return(50, nil)
 	mockRepo.On("GetTotalRevenue").Return(1000000.0, nil)
 
-	result, err := svc.GetDashboardData()
+	result, err := svc.GetDashboardData(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, 100, result["totalBookings"])
 	assert.Equal(t, 50, result["activeDrivers"])
@@ -50,7 +51,7 @@ func TestDashboardService_GetDashboardData_GetTotalBookingsError(t *testing.T) {
 
 	mockRepo.On("GetTotalBookings").Return(0, errors.New("test error"))
 
-	result, err := svc.GetDashboardData()
+	result, err := svc.GetDashboardData(context.Background())
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	mockRepo.AssertExpectations(t)
@@ -63,7 +64,7 @@ func TestDashboardService_GetDashboardData_GetActiveDriversError(t *testing.T) {
 	mockRepo.On("GetTotalBookings").Return(100, nil)
 	mockRepo.On("GetActiveDrivers").Return(0, errors.New("test error"))
 
-	result, err := svc.GetDashboardData()
+	result, err := svc.GetDashboardData(context.Background())
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	mockRepo.AssertExpectations(t)
@@ -77,7 +78,7 @@ func TestDashboardService_GetDashboardData_GetTotalRevenueError(t *testing.T) {
 	mockRepo.On("GetActiveDrivers").Return(50, nil)
 	mockRepo.On("GetTotalRevenue").Return(0.0, errors.New("test error"))
 
-	result, err := svc.GetDashboardData()
+	result, err := svc.GetDashboardData(context.Background())
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	mockRepo.AssertExpectations(t)
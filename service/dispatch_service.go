@@ -0,0 +1,405 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+)
+
+// Candidates are scored in points: each star of rating adds ratingWeight, each
+// kilometre from the pickup and each booking already held that day subtract.
+const (
+	ratingWeight      = 5.0
+	distanceWeight    = 1.0
+	workloadWeight    = 10.0
+	neutralRating     = 3.0
+	unknownDistanceKM = 50.0
+)
+
+var (
+	ErrNotDispatchable = errors.New("only confirmed bookings can be dispatched")
+	ErrNoCandidates    = errors.New("no available driver matches this booking")
+	ErrOfferPending    = errors.New("booking already has a pending offer")
+	ErrOfferClosed     = errors.New("offer is no longer pending")
+	ErrOfferExpired    = errors.New("offer has expired")
+	ErrInvalidLocation = errors.New("latitude must be within -90..90 and longitude within -180..180")
+)
+
+type DispatchServiceInterface interface {
+	Candidates(ctx context.Context, bookingID string) ([]model.DispatchCandidate, error)
+	Dispatch(ctx context.Context, bookingID string, user *model.User, auto bool) (*model.DispatchResult, error)
+	GetOffers(ctx context.Context, bookingID string) ([]model.DispatchOffer, error)
+	Accept(ctx context.Context, offerID int, user *model.User) (*model.Booking, error)
+	Decline(ctx context.Context, offerID int, user *model.User) (*model.DispatchOffer, error)
+	SetLocation(ctx context.Context, l *model.DriverLocation, user *model.User) error
+}
+
+type DispatchService struct {
+	Repo         repository.DispatchRepositoryInterface
+	Bookings     repository.BookingRepositoryInterface
+	Drivers      repository.DriverRepositoryInterface
+	Availability repository.AvailabilityRepositoryInterface
+	OfferTimeout time.Duration
+
+	// Customers and Notifications are optional; when both are set, assigning
+	// a driver notifies the customer and the driver.
+	Customers     repository.CustomerRepositoryInterface
+	Notifications repository.NotificationRepositoryInterface
+
+	// Router is optional; without it drivers are ranked by straight-line
+	// distance.
+	Router Router
+}
+
+func NewDispatchService(repo repository.DispatchRepositoryInterface, bookings repository.BookingRepositoryInterface,
+	drivers repository.DriverRepositoryInterface, availability repository.AvailabilityRepositoryInterface, offerTimeout time.Duration) *DispatchService {
+	return &DispatchService{
+		Repo:         repo,
+		Bookings:     bookings,
+		Drivers:      drivers,
+		Availability: availability,
+		OfferTimeout: offerTimeout,
+	}
+}
+
+func (s *DispatchService) dispatchable(ctx context.Context, bookingID string) (*model.Booking, error) {
+	b, err := s.Bookings.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	if b.Status != model.BookingConfirmed {
+		return nil, ErrNotDispatchable
+	}
+	if b.StartAt == nil || b.EndAt == nil {
+		return nil, ErrMissingWindow
+	}
+	return b, nil
+}
+
+// locateCandidate works out how far a driver is from the pickup, by road
+// when the route can be found and in a straight line otherwise.
+func (s *DispatchService) locateCandidate(ctx context.Context, c *model.DispatchCandidate, b *model.Booking, now time.Time) error {
+	if c.Latitude == nil || c.Longitude == nil || b.PickupLat == nil || b.PickupLng == nil {
+		return nil
+	}
+
+	if s.Router != nil {
+		route, err := s.Router.Route(ctx, model.GeoPoint{Lat: *c.Latitude, Lng: *c.Longitude},
+			model.GeoPoint{Lat: *b.PickupLat, Lng: *b.PickupLng}, now)
+		if err == nil {
+			c.DistanceKM, c.ETAMinutes = &route.DistanceKM, &route.DurationMinutes
+			return nil
+		}
+		if !errors.Is(err, ErrNoRoute) {
+			return err
+		}
+	}
+
+	d := utils.HaversineKM(*b.PickupLat, *b.PickupLng, *c.Latitude, *c.Longitude)
+	c.DistanceKM = &d
+	return nil
+}
+
+func scoreCandidate(c *model.DispatchCandidate) {
+	distance := unknownDistanceKM
+	if c.DistanceKM != nil {
+		distance = *c.DistanceKM
+	}
+
+	rating := c.Rating
+	if rating == 0 {
+		rating = neutralRating
+	}
+
+	c.Score = ratingWeight*rating - distanceWeight*distance - workloadWeight*float64(c.Workload)
+}
+
+// rank returns the drivers free for the whole booking window with a matching
+// car type, best first. Drivers in exclude have already been offered the job.
+func (s *DispatchService) rank(ctx context.Context, b *model.Booking, exclude map[int]bool) ([]model.DispatchCandidate, error) {
+	start := *b.StartAt
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	drivers, err := s.Repo.ActiveDrivers(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ranked := []model.DispatchCandidate{}
+	for _, c := range drivers {
+		if exclude[c.DriverID] {
+			continue
+		}
+		if b.CarTypeID != nil && *b.CarTypeID != "" && c.CarTypeID != *b.CarTypeID {
+			continue
+		}
+
+		blocks, err := s.Availability.DriverBlocks(ctx, c.DriverID, *b.StartAt, *b.EndAt)
+		if err != nil {
+			return nil, err
+		}
+		if conflicting(blocks, b.ID) != nil {
+			continue
+		}
+
+		if err := s.locateCandidate(ctx, &c, b, now); err != nil {
+			return nil, err
+		}
+		scoreCandidate(&c)
+		ranked = append(ranked, c)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	return ranked, nil
+}
+
+func (s *DispatchService) Candidates(ctx context.Context, bookingID string) ([]model.DispatchCandidate, error) {
+	b, err := s.dispatchable(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	return s.rank(ctx, b, nil)
+}
+
+// Dispatch assigns the best candidate straight away when auto is set, and
+// otherwise offers the booking to them for OfferTimeout.
+func (s *DispatchService) Dispatch(ctx context.Context, bookingID string, user *model.User, auto bool) (*model.DispatchResult, error) {
+	b, err := s.dispatchable(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := s.Repo.GetOffers(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	offered := map[int]bool{}
+	for _, o := range offers {
+		if o.Status == model.OfferPending {
+			return nil, ErrOfferPending
+		}
+		offered[o.DriverID] = true
+	}
+
+	if auto {
+		ranked, err := s.rank(ctx, b, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(ranked) == 0 {
+			return nil, ErrNoCandidates
+		}
+		if err := s.assign(ctx, b, ranked[0].DriverID, user, nil); err != nil {
+			return nil, err
+		}
+		return &model.DispatchResult{Booking: b}, nil
+	}
+
+	offer, err := s.offerNext(ctx, b, offered)
+	if err != nil {
+		return nil, err
+	}
+	if offer == nil {
+		return nil, ErrNoCandidates
+	}
+	return &model.DispatchResult{Offer: offer}, nil
+}
+
+func (s *DispatchService) offerNext(ctx context.Context, b *model.Booking, offered map[int]bool) (*model.DispatchOffer, error) {
+	ranked, err := s.rank(ctx, b, offered)
+	if err != nil || len(ranked) == 0 {
+		return nil, err
+	}
+
+	now := time.Now()
+	offer := &model.DispatchOffer{
+		BookingID: b.ID,
+		DriverID:  ranked[0].DriverID,
+		Score:     ranked[0].Score,
+		Status:    model.OfferPending,
+		OfferedAt: now,
+		ExpiresAt: now.Add(s.OfferTimeout),
+	}
+	if err := s.Repo.CreateOffer(ctx, offer); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// assign records the driver on the booking and moves it to DriverAssigned,
+// answering offer in the same write when it is given.
+func (s *DispatchService) assign(ctx context.Context, b *model.Booking, driverID int, user *model.User, offer *model.DispatchOffer) error {
+	d, err := lookupDriver(ctx, s.Drivers, driverID)
+	if err != nil {
+		return err
+	}
+
+	// Transition writes the driver with the status, so a failed assignment
+	// leaves neither behind.
+	b.DriverID = &d.ID
+	b.Driver = d.Name
+
+	change := &model.BookingStatusChange{
+		FromStatus: b.Status,
+		ToStatus:   model.BookingDriverAssigned,
+		ChangedBy:  user.ID,
+		Reason:     "dispatch",
+		Offer:      offer,
+	}
+	if s.Notifications != nil {
+		change.Notifications, err = NewNotificationService(s.Notifications, s.Customers, s.Drivers).BookingNotifications(ctx, b, model.BookingDriverAssigned)
+		if err != nil {
+			return err
+		}
+	}
+	b.Status = model.BookingDriverAssigned
+	return s.Bookings.Transition(ctx, b, change)
+}
+
+func (s *DispatchService) GetOffers(ctx context.Context, bookingID string) ([]model.DispatchOffer, error) {
+	b, err := s.Bookings.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNotFound
+	}
+	return s.Repo.GetOffers(ctx, bookingID)
+}
+
+func (s *DispatchService) openOffer(ctx context.Context, offerID int, user *model.User) (*model.DispatchOffer, error) {
+	if user == nil || !(user.IsAdmin() || user.IsDriver()) {
+		return nil, ErrTransitionForbidden
+	}
+
+	offer, err := s.Repo.GetOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer == nil {
+		return nil, ErrNotFound
+	}
+	if !user.ActsFor(offer.DriverID) {
+		return nil, ErrTransitionForbidden
+	}
+	if offer.Status != model.OfferPending {
+		return nil, ErrOfferClosed
+	}
+	return offer, nil
+}
+
+func (s *DispatchService) respond(ctx context.Context, offer *model.DispatchOffer, status string) error {
+	offer.Status = status
+	err := s.Repo.RespondOffer(ctx, offer)
+	if errors.Is(err, ErrVersionConflict) {
+		return ErrOfferClosed
+	}
+	return err
+}
+
+func (s *DispatchService) Accept(ctx context.Context, offerID int, user *model.User) (*model.Booking, error) {
+	offer, err := s.openOffer(ctx, offerID, user)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(offer.ExpiresAt) {
+		return nil, ErrOfferExpired
+	}
+
+	b, err := s.dispatchable(ctx, offer.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := s.Availability.DriverBlocks(ctx, offer.DriverID, *b.StartAt, *b.EndAt)
+	if err != nil {
+		return nil, err
+	}
+	if conflicting(blocks, b.ID) != nil {
+		return nil, ErrDriverUnavailable
+	}
+
+	// The offer is accepted in the assignment's transaction, so a failed
+	// assignment leaves it open.
+	offer.Status = model.OfferAccepted
+	if err := s.assign(ctx, b, offer.DriverID, user, offer); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Decline closes the offer and passes the booking to the next candidate, whose
+// offer is returned. It is nil when nobody is left to ask.
+func (s *DispatchService) Decline(ctx context.Context, offerID int, user *model.User) (*model.DispatchOffer, error) {
+	offer, err := s.openOffer(ctx, offerID, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.respond(ctx, offer, model.OfferDeclined); err != nil {
+		return nil, err
+	}
+	return s.redispatch(ctx, offer.BookingID)
+}
+
+func (s *DispatchService) redispatch(ctx context.Context, bookingID string) (*model.DispatchOffer, error) {
+	b, err := s.dispatchable(ctx, bookingID)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotDispatchable) || errors.Is(err, ErrMissingWindow) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := s.Repo.GetOffers(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	offered := map[int]bool{}
+	for _, o := range offers {
+		offered[o.DriverID] = true
+	}
+	return s.offerNext(ctx, b, offered)
+}
+
+// ExpireOffers closes offers past their deadline in every tenant and moves each
+// booking on to its next candidate. It returns how many offers expired. A
+// booking that cannot be moved on is logged and left for the others to go on.
+func (s *DispatchService) ExpireOffers(ctx context.Context) (int, error) {
+	expired, err := s.Repo.ExpireOffers(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, o := range expired {
+		if _, err := s.redispatch(utils.WithTenant(ctx, o.TenantID), o.BookingID); err != nil {
+			log.Printf("Gagal menawarkan ulang booking %s setelah tawaran %d kedaluwarsa: %v\n", o.BookingID, o.ID, err)
+		}
+	}
+	return len(expired), nil
+}
+
+// SetLocation records where a driver is. Only the driver's own account, or an
+// admin, may report it.
+func (s *DispatchService) SetLocation(ctx context.Context, l *model.DriverLocation, user *model.User) error {
+	if user == nil || !user.ActsFor(l.DriverID) {
+		return ErrTransitionForbidden
+	}
+	if !utils.ValidCoordinates(l.Latitude, l.Longitude) {
+		return ErrInvalidLocation
+	}
+	if _, err := lookupDriver(ctx, s.Drivers, l.DriverID); err != nil {
+		if errors.Is(err, ErrUnknownDriver) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return s.Repo.SetLocation(ctx, l)
+}
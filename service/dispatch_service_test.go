@@ -0,0 +1,221 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDispatchRepository struct {
+	mock.Mock
+}
+
+func (m *MockDispatchRepository) ActiveDrivers(ctx context.Context, from, to time.Time) ([]model.DispatchCandidate, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]model.DispatchCandidate), args.Error(1)
+}
+
+func (m *MockDispatchRepository) SetLocation(ctx context.Context, l *model.DriverLocation) error {
+	args := m.Called(l)
+	return args.Error(0)
+}
+
+func (m *MockDispatchRepository) CreateOffer(ctx context.Context, o *model.DispatchOffer) error {
+	args := m.Called(o)
+	return args.Error(0)
+}
+
+func (m *MockDispatchRepository) GetOffer(ctx context.Context, id int) (*model.DispatchOffer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DispatchOffer), args.Error(1)
+}
+
+func (m *MockDispatchRepository) GetOffers(ctx context.Context, bookingID string) ([]model.DispatchOffer, error) {
+	args := m.Called(bookingID)
+	return args.Get(0).([]model.DispatchOffer), args.Error(1)
+}
+
+func (m *MockDispatchRepository) RespondOffer(ctx context.Context, o *model.DispatchOffer) error {
+	args := m.Called(o)
+	return args.Error(0)
+}
+
+func (m *MockDispatchRepository) ExpireOffers(ctx context.Context, now time.Time) ([]model.DispatchOffer, error) {
+	args := m.Called(now)
+	return args.Get(0).([]model.DispatchOffer), args.Error(1)
+}
+
+type dispatchFixture struct {
+	repo         *MockDispatchRepository
+	bookings     *MockBookingRepository
+	drivers      *MockDriverRepository
+	availability *MockAvailabilityRepository
+	svc          *service.DispatchService
+	booking      *model.Booking
+}
+
+func newDispatchFixture() *dispatchFixture {
+	f := &dispatchFixture{
+		repo:         new(MockDispatchRepository),
+		bookings:     new(MockBookingRepository),
+		drivers:      new(MockDriverRepository),
+		availability: new(MockAvailabilityRepository),
+	}
+	f.svc = service.NewDispatchService(f.repo, f.bookings, f.drivers, f.availability, time.Minute)
+
+	start, end := at(8), at(10)
+	lat, lng := -6.2, 106.8
+	mpv := "mpv"
+	f.booking = &model.Booking{ID: "BK1", Status: model.BookingConfirmed, StartAt: &start, EndAt: &end, PickupLat: &lat, PickupLng: &lng, CarTypeID: &mpv, Version: 2}
+
+	near, far := -6.21, -6.5
+	f.repo.On("ActiveDrivers", at(0), at(24)).Return([]model.DispatchCandidate{
+		{DriverID: 1, Name: "Far", CarTypeID: "mpv", Latitude: &far, Longitude: &lng, Rating: 5},
+		{DriverID: 2, Name: "Near", CarTypeID: "mpv", Latitude: &near, Longitude: &lng, Rating: 4},
+		{DriverID: 3, Name: "Sedan", CarTypeID: "sedan", Latitude: &near, Longitude: &lng, Rating: 5},
+		{DriverID: 4, Name: "Busy", CarTypeID: "mpv", Latitude: &near, Longitude: &lng, Rating: 5},
+		{DriverID: 5, Name: "Unplaced", CarTypeID: "mpv", Workload: 2},
+	}, nil)
+	for _, id := range []int{1, 2, 3, 5} {
+		f.availability.On("DriverBlocks", id, start, end).Return([]model.BusyBlock{}, nil)
+	}
+	f.availability.On("DriverBlocks", 4, start, end).Return([]model.BusyBlock{{Kind: model.BlockLeave, Ref: "1", StartAt: at(7), EndAt: at(9)}}, nil)
+
+	return f
+}
+
+func TestDispatchService_Candidates(t *testing.T) {
+	f := newDispatchFixture()
+	f.bookings.On("GetByID", "BK1").Return(f.booking, nil)
+
+	candidates, err := f.svc.Candidates(context.Background(), "BK1")
+	assert.NoError(t, err)
+
+	var order []int
+	for _, c := range candidates {
+		order = append(order, c.DriverID)
+	}
+	assert.Equal(t, []int{2, 1, 5}, order)
+	assert.InDelta(t, 1.1, *candidates[0].DistanceKM, 0.1)
+	assert.Nil(t, candidates[2].DistanceKM)
+
+	f.booking.Status = model.BookingPending
+	_, err = f.svc.Candidates(context.Background(), "BK1")
+	assert.ErrorIs(t, err, service.ErrNotDispatchable)
+}
+
+func TestDispatchService_AutoAssign(t *testing.T) {
+	f := newDispatchFixture()
+	admin := &model.User{ID: 9, Role: model.RoleAdmin}
+
+	f.bookings.On("GetByID", "BK1").Return(f.booking, nil)
+	f.repo.On("GetOffers", "BK1").Return([]model.DispatchOffer{}, nil)
+	f.drivers.On("GetByID", "2").Return(&model.Driver{ID: 2, Name: "Near"}, nil)
+	f.bookings.On("Transition", f.booking, mock.MatchedBy(func(c *model.BookingStatusChange) bool {
+		return c.FromStatus == model.BookingConfirmed && c.ToStatus == model.BookingDriverAssigned && c.ChangedBy == 9
+	})).Return(nil)
+
+	result, err := f.svc.Dispatch(context.Background(), "BK1", admin, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "Near", result.Booking.Driver)
+	assert.Equal(t, 2, *result.Booking.DriverID)
+	assert.Equal(t, model.BookingDriverAssigned, result.Booking.Status)
+	f.bookings.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestDispatchService_OfferDeclineAccept(t *testing.T) {
+	f := newDispatchFixture()
+	near, far := 2, 1
+	nearDriver := &model.User{ID: 7, Role: model.RoleDriver, DriverID: &near}
+	farDriver := &model.User{ID: 8, Role: model.RoleDriver, DriverID: &far}
+
+	f.bookings.On("GetByID", "BK1").Return(f.booking, nil)
+	f.repo.On("GetOffers", "BK1").Return([]model.DispatchOffer{}, nil).Once()
+	f.repo.On("CreateOffer", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.DispatchOffer).ID = 1
+	}).Return(nil).Once()
+
+	result, err := f.svc.Dispatch(context.Background(), "BK1", &model.User{ID: 9, Role: model.RoleAdmin}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Offer.DriverID)
+	assert.Equal(t, model.OfferPending, result.Offer.Status)
+
+	first := *result.Offer
+	f.repo.On("GetOffer", 1).Return(&first, nil)
+	f.repo.On("RespondOffer", mock.Anything).Return(nil)
+	f.repo.On("GetOffers", "BK1").Return([]model.DispatchOffer{{ID: 1, DriverID: 2, Status: model.OfferDeclined}}, nil)
+	f.repo.On("CreateOffer", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.DispatchOffer).ID = 2
+	}).Return(nil).Once()
+
+	_, err = f.svc.Decline(context.Background(), 1, farDriver)
+	assert.ErrorIs(t, err, service.ErrTransitionForbidden)
+
+	next, err := f.svc.Decline(context.Background(), 1, nearDriver)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, next.DriverID)
+
+	second := *next
+	f.repo.On("GetOffer", 2).Return(&second, nil)
+	f.drivers.On("GetByID", "1").Return(&model.Driver{ID: 1, Name: "Far"}, nil)
+	f.bookings.On("Transition", f.booking, mock.MatchedBy(func(c *model.BookingStatusChange) bool {
+		return c.Offer == &second && c.Offer.Status == model.OfferAccepted
+	})).Return(nil)
+
+	_, err = f.svc.Accept(context.Background(), 2, &model.User{ID: 9, Role: "user"})
+	assert.ErrorIs(t, err, service.ErrTransitionForbidden)
+	_, err = f.svc.Accept(context.Background(), 2, nearDriver)
+	assert.ErrorIs(t, err, service.ErrTransitionForbidden)
+	assert.Equal(t, model.OfferPending, second.Status)
+
+	booking, err := f.svc.Accept(context.Background(), 2, farDriver)
+	assert.NoError(t, err)
+	assert.Equal(t, "Far", booking.Driver)
+	assert.Equal(t, model.OfferAccepted, second.Status)
+	// Only the decline answered an offer on its own; the acceptance went with
+	// the assignment.
+	f.repo.AssertNumberOfCalls(t, "RespondOffer", 1)
+}
+
+func TestDispatchService_ExpireOffers(t *testing.T) {
+	f := newDispatchFixture()
+	expired := model.DispatchOffer{ID: 1, BookingID: "BK1", DriverID: 2, Status: model.OfferExpired, TenantID: 4}
+	failing := model.DispatchOffer{ID: 2, BookingID: "BK0", DriverID: 2, Status: model.OfferExpired, TenantID: 4}
+
+	f.repo.On("ExpireOffers", mock.Anything).Return([]model.DispatchOffer{failing, expired}, nil)
+	f.bookings.On("GetByID", "BK0").Return(nil, assert.AnError)
+	f.bookings.On("GetByID", "BK1").Return(f.booking, nil)
+	f.repo.On("GetOffers", "BK1").Return([]model.DispatchOffer{expired}, nil)
+	f.repo.On("CreateOffer", mock.MatchedBy(func(o *model.DispatchOffer) bool { return o.DriverID == 1 })).Return(nil)
+
+	// The booking that failed to move on does not hold up the rest.
+	n, err := f.svc.ExpireOffers(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	f.repo.AssertNumberOfCalls(t, "CreateOffer", 1)
+}
+
+func TestDispatchService_SetLocation(t *testing.T) {
+	f := newDispatchFixture()
+	f.drivers.On("GetByID", "2").Return(&model.Driver{ID: 2}, nil)
+	f.repo.On("SetLocation", mock.Anything).Return(nil)
+
+	driverID, otherID := 2, 3
+	driver := &model.User{ID: 7, Role: model.RoleDriver, DriverID: &driverID}
+	other := &model.User{ID: 8, Role: model.RoleDriver, DriverID: &otherID}
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+
+	assert.NoError(t, f.svc.SetLocation(context.Background(), &model.DriverLocation{DriverID: 2, Latitude: -6.2, Longitude: 106.8}, driver))
+	assert.NoError(t, f.svc.SetLocation(context.Background(), &model.DriverLocation{DriverID: 2, Latitude: -6.2, Longitude: 106.8}, admin))
+	assert.ErrorIs(t, f.svc.SetLocation(context.Background(), &model.DriverLocation{DriverID: 2, Latitude: -6.2, Longitude: 106.8}, other), service.ErrTransitionForbidden)
+	assert.ErrorIs(t, f.svc.SetLocation(context.Background(), &model.DriverLocation{DriverID: 2, Latitude: 120}, driver), service.ErrInvalidLocation)
+	f.repo.AssertNumberOfCalls(t, "SetLocation", 2)
+}
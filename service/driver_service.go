@@ -3,28 +3,29 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type DriverService struct {
 	Repo repository.DriverRepositoryInterface
 }
 
-func (s *DriverService) GetAll() ([]model.Driver, error) {
-	return s.Repo.GetAll()
+func (s *DriverService) GetAll(ctx context.Context) ([]model.Driver, error) {
+	return s.Repo.GetAll(ctx)
 }
 
-func (s *DriverService) Create(driver *model.Driver) error {
-	return s.Repo.Create(driver)
+func (s *DriverService) Create(ctx context.Context, driver *model.Driver) error {
+	return s.Repo.Create(ctx, driver)
 }
 
-func (s *DriverService) GetByID(id string) (*model.Driver, error) {
-	return s.Repo.GetByID(id)
+func (s *DriverService) GetByID(ctx context.Context, id string) (*model.Driver, error) {
+	return s.Repo.GetByID(ctx, id)
 }
 
-func (s *DriverService) Update(id string, driver *model.Driver) error {
-	return s.Repo.Update(id, driver)
+func (s *DriverService) Update(ctx context.Context, id string, driver *model.Driver) error {
+	return s.Repo.Update(ctx, id, driver)
 }
 
-func (s *DriverService) Delete(id string) error {
-	return s.Repo.Delete(id)
+func (s *DriverService) Delete(ctx context.Context, id string, version int) error {
+	return s.Repo.Delete(ctx, id, version)
 }
@@ -3,7 +3,9 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -13,31 +15,46 @@ type MockDriverRepository struct {
 	mock.Mock
 }
 
-func (m *MockDriverRepository) GetAll() ([]model.Driver, error) {
+func (m *MockDriverRepository) GetAll(ctx context.Context) ([]model.Driver, error) {
 	args := m.Called()
 	return args.Get(0).([]model.Driver), args.Error(1)
 }
 
-func (m *MockDriverRepository) Create(d *model.Driver) error {
+func (m *MockDriverRepository) Create(ctx context.Context, d *model.Driver) error {
 	args := m.Called(d)
 	return args.Error(0)
 }
 
-func (m *MockDriverRepository) GetByID(id string) (*model.Driver, error) {
+func (m *MockDriverRepository) GetByID(ctx context.Context, id string) (*model.Driver, error) {
 	args := m.Called(id)
 	return args.Get(0).(*model.Driver), args.Error(1)
 }
 
-func (m *MockDriverRepository) Update(id string, d *model.Driver) error {
+func (m *MockDriverRepository) Update(ctx context.Context, id string, d *model.Driver) error {
 	args := m.Called(id, d)
 	return args.Error(0)
 }
 
-func (m *MockDriverRepository) Delete(id string) error {
+func (m *MockDriverRepository) Delete(ctx context.Context, id string, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockDriverRepository) GetDeleted(ctx context.Context) ([]model.Driver, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Driver), args.Error(1)
+}
+
+func (m *MockDriverRepository) Restore(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+func (m *MockDriverRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestDriverService_GetAll(t *testing.T) {
 	mockRepo := new(MockDriverRepository)
 	svc := &service.DriverService{Repo: mockRepo}
@@ -48,7 +65,7 @@ func TestDriverService_GetAll(t *testing.T) {
 	}
 	mockRepo.On("GetAll").Return(expected, nil)
 
-	result, err := svc.GetAll()
+	result, err := svc.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -61,7 +78,7 @@ func TestDriverService_Create(t *testing.T) {
 	driver := &model.Driver{Name: "John Doe", Email: "john@example.com"}
 	mockRepo.On("Create", driver).Return(nil)
 
-	err := svc.Create(driver)
+	err := svc.Create(context.Background(), driver)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -73,7 +90,7 @@ func TestDriverService_GetByID(t *testing.T) {
 	expected := &model.Driver{ID: 1, Name: "John Doe", Email: "john@example.com"}
 	mockRepo.On("GetByID", "1").Return(expected, nil)
 
-	result, err := svc.GetByID("1")
+	result, err := svc.GetByID(context.Background(), "1")
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -86,7 +103,7 @@ func TestDriverService_Update(t *testing.T) {
 	driver := &model.Driver{ID: 1, Name: "Updated Name", Email: "updated@example.com"}
 	mockRepo.On("Update", "1", driver).Return(nil)
 
-	err := svc.Update("1", driver)
+	err := svc.Update(context.Background(), "1", driver)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -95,9 +112,9 @@ func TestDriverService_Delete(t *testing.T) {
 	mockRepo := new(MockDriverRepository)
 	svc := &service.DriverService{Repo: mockRepo}
 
-	mockRepo.On("Delete", "1").Return(nil)
+	mockRepo.On("Delete", "1", 1).Return(nil)
 
-	err := svc.Delete("1")
+	err := svc.Delete(context.Background(), "1", 1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"auth-service/repository"
+	"errors"
+)
+
+var (
+	ErrVersionConflict  = repository.ErrVersionConflict
+	ErrNotFound         = repository.ErrNotFound
+	ErrNoTenant         = repository.ErrNoTenant
+	ErrScheduleConflict = repository.ErrScheduleConflict
+	ErrInvalidID        = errors.New("invalid id")
+)
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+)
+
+var (
+	ErrTripNotCompleted = errors.New("booking has no completed trip to rate")
+	ErrFeedbackExists   = errors.New("feedback has already been submitted for this trip")
+	ErrInvalidTag       = errors.New("unknown feedback tag")
+	ErrInvalidLink      = errors.New("feedback link is invalid or has expired")
+)
+
+const (
+	defaultRatingThreshold = 3.5
+	defaultRatingMinimum   = 5
+	defaultRatingWindow    = 20
+)
+
+type FeedbackServiceInterface interface {
+	Submit(ctx context.Context, bookingID string, req model.FeedbackRequest) (*model.TripFeedback, error)
+	GetByBooking(ctx context.Context, bookingID string) (*model.TripFeedback, error)
+	GetForm(ctx context.Context, token string) (*model.FeedbackForm, error)
+	SubmitWithToken(ctx context.Context, token string, req model.FeedbackRequest) (*model.TripFeedback, error)
+	GetByDriver(ctx context.Context, driverID int) ([]model.TripFeedback, error)
+	GetAlerts(ctx context.Context, open bool) ([]model.DriverRatingAlert, error)
+	AcknowledgeAlert(ctx context.Context, id int, by int64) error
+}
+
+// FeedbackService takes one rating per completed trip, either from staff or
+// from the customer through a signed link. Once a driver has MinRatings
+// ratings, a rolling average over their latest Window ratings below Threshold
+// opens an alert, which is emailed to AlertEmail when set.
+type FeedbackService struct {
+	Repo       repository.FeedbackRepositoryInterface
+	Bookings   repository.BookingRepositoryInterface
+	Trips      repository.TripsRepositoryInterface
+	Drivers    repository.DriverRepositoryInterface
+	Threshold  float64
+	MinRatings int
+	Window     int
+	AlertEmail string
+}
+
+func NewFeedbackService(repo repository.FeedbackRepositoryInterface, bookings repository.BookingRepositoryInterface,
+	trips repository.TripsRepositoryInterface, drivers repository.DriverRepositoryInterface) *FeedbackService {
+	return &FeedbackService{
+		Repo:       repo,
+		Bookings:   bookings,
+		Trips:      trips,
+		Drivers:    drivers,
+		Threshold:  defaultRatingThreshold,
+		MinRatings: defaultRatingMinimum,
+		Window:     defaultRatingWindow,
+	}
+}
+
+// Submit records feedback taken by staff for the booking's trip.
+func (s *FeedbackService) Submit(ctx context.Context, bookingID string, req model.FeedbackRequest) (*model.TripFeedback, error) {
+	return s.submit(ctx, bookingID, req, model.FeedbackFromStaff)
+}
+
+func (s *FeedbackService) GetByBooking(ctx context.Context, bookingID string) (*model.TripFeedback, error) {
+	_, trip, err := s.bookingTrip(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, ErrNotFound
+	}
+
+	f, err := s.Repo.GetByTrip(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, ErrNotFound
+	}
+	return f, nil
+}
+
+// GetForm returns the trip a feedback link was sent for.
+func (s *FeedbackService) GetForm(ctx context.Context, token string) (*model.FeedbackForm, error) {
+	ctx, bookingID, err := feedbackLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	b, trip, err := s.bookingTrip(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil || trip.Status != model.TripCompleted {
+		return nil, ErrTripNotCompleted
+	}
+
+	form := &model.FeedbackForm{
+		BookingID:   b.ID,
+		TripDate:    trip.TripDate,
+		Origin:      trip.Origin,
+		Destination: trip.Destination,
+		Driver:      trip.DriverName,
+		Tags:        model.FeedbackTags,
+	}
+	form.Feedback, err = s.Repo.GetByTrip(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+// SubmitWithToken records the customer's own feedback from a feedback link.
+func (s *FeedbackService) SubmitWithToken(ctx context.Context, token string, req model.FeedbackRequest) (*model.TripFeedback, error) {
+	ctx, bookingID, err := feedbackLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.submit(ctx, bookingID, req, model.FeedbackFromLink)
+}
+
+// feedbackLink checks a feedback link token and scopes ctx to the tenant it
+// was issued in, since its holder is not logged in.
+func feedbackLink(ctx context.Context, token string) (context.Context, string, error) {
+	claims, err := utils.ParseFeedbackToken(token)
+	if err != nil {
+		return ctx, "", ErrInvalidLink
+	}
+	return utils.WithTenant(ctx, claims.TenantID), claims.BookingID, nil
+}
+
+func (s *FeedbackService) submit(ctx context.Context, bookingID string, req model.FeedbackRequest, source string) (*model.TripFeedback, error) {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, tag := range req.Tags {
+		if !model.ValidFeedbackTag(tag) {
+			return nil, ErrInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	b, trip, err := s.bookingTrip(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil || trip.Status != model.TripCompleted {
+		return nil, ErrTripNotCompleted
+	}
+
+	f := &model.TripFeedback{
+		TripID:     trip.ID,
+		BookingID:  &b.ID,
+		DriverID:   b.DriverID,
+		CustomerID: b.CustomerID,
+		Rating:     req.Rating,
+		Tags:       tags,
+		Comment:    req.Comment,
+		Source:     source,
+	}
+	if trip.DriverID != 0 {
+		driverID := int(trip.DriverID)
+		f.DriverID = &driverID
+	}
+
+	rating, err := s.Repo.Submit(ctx, f, s.Window)
+	if errors.Is(err, repository.ErrDuplicateKey) {
+		return nil, ErrFeedbackExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rating != nil && rating.Count >= s.MinRatings && rating.RollingAverage < s.Threshold {
+		if err := s.raiseAlert(ctx, rating); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// bookingTrip returns the booking and its latest trip, which is nil until
+// the booking has gone on trip.
+func (s *FeedbackService) bookingTrip(ctx context.Context, bookingID string) (*model.Booking, *model.VehicleTrip, error) {
+	b, err := s.Bookings.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b == nil {
+		return nil, nil, ErrNotFound
+	}
+
+	trip, err := s.Trips.FindByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, trip, nil
+}
+
+// raiseAlert opens an alert for the driver unless one is already open.
+func (s *FeedbackService) raiseAlert(ctx context.Context, rating *model.DriverRating) error {
+	a := &model.DriverRatingAlert{
+		DriverID:       rating.DriverID,
+		RollingAverage: rating.RollingAverage,
+		Threshold:      s.Threshold,
+	}
+	if s.Drivers != nil {
+		d, err := lookupDriver(ctx, s.Drivers, rating.DriverID)
+		if err != nil && !errors.Is(err, ErrUnknownDriver) {
+			return err
+		}
+		if d != nil {
+			a.DriverName = d.Name
+		}
+	}
+
+	notifications, err := driverRatingAlert(a, s.AlertEmail)
+	if err != nil {
+		return err
+	}
+	_, err = s.Repo.RaiseAlert(ctx, a, notifications)
+	return err
+}
+
+func (s *FeedbackService) GetByDriver(ctx context.Context, driverID int) ([]model.TripFeedback, error) {
+	if _, err := lookupDriver(ctx, s.Drivers, driverID); err != nil {
+		if errors.Is(err, ErrUnknownDriver) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.Repo.GetByDriver(ctx, driverID)
+}
+
+func (s *FeedbackService) GetAlerts(ctx context.Context, open bool) ([]model.DriverRatingAlert, error) {
+	return s.Repo.GetAlerts(ctx, open)
+}
+
+func (s *FeedbackService) AcknowledgeAlert(ctx context.Context, id int, by int64) error {
+	return s.Repo.AcknowledgeAlert(ctx, id, by)
+}
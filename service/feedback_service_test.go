@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type feedbackFixture struct {
+	ctx           context.Context
+	bookings      *service.BookingService
+	feedback      *service.FeedbackService
+	notifications *service.NotificationService
+	driver        *model.Driver
+	customer      *model.Customer
+	rides         int
+}
+
+func newFeedbackFixture(t *testing.T) *feedbackFixture {
+	store := repository.NewMemoryStore()
+	customers := repository.NewMemoryCustomerRepository(store)
+	drivers := repository.NewMemoryDriverRepository(store)
+	notifications := repository.NewMemoryNotificationRepository(store)
+	trips := repository.NewMemoryTripRepo(store)
+	bookingRepo := repository.NewMemoryBookingRepository(store)
+
+	f := &feedbackFixture{
+		ctx: utils.WithTenant(context.Background(), 1),
+		bookings: &service.BookingService{Repo: bookingRepo, Customers: customers, Drivers: drivers, Notifications: notifications,
+			Trips: trips, FeedbackURL: "https://rides.example.com/feedback/"},
+		feedback:      service.NewFeedbackService(repository.NewMemoryFeedbackRepository(store), bookingRepo, trips, drivers),
+		notifications: service.NewNotificationService(notifications, customers, drivers),
+		driver:        &model.Driver{Name: "Budi", Phone: "+628222"},
+		customer:      &model.Customer{Name: "Sari", Emails: []string{"sari@example.com"}},
+	}
+	f.feedback.AlertEmail = "ops@example.com"
+	require.NoError(t, drivers.Create(f.ctx, f.driver))
+	require.NoError(t, customers.Create(f.ctx, f.customer))
+	return f
+}
+
+// ride books a trip with the fixture's driver and takes it as far as status.
+func (f *feedbackFixture) ride(t *testing.T, status string) *model.Booking {
+	start := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC).AddDate(0, 0, f.rides)
+	end := start.Add(time.Hour)
+	f.rides++
+	b := &model.Booking{Customer: f.customer.Name, CustomerID: &f.customer.ID, DriverID: &f.driver.ID, StartAt: &start, EndAt: &end}
+	require.NoError(t, f.bookings.Create(f.ctx, b))
+
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+	for _, to := range []string{model.BookingConfirmed, model.BookingDriverAssigned, model.BookingOnTrip, model.BookingCompleted} {
+		_, err := f.bookings.Transition(f.ctx, b.ID, admin, model.BookingTransition{To: to})
+		require.NoError(t, err)
+		if to == status {
+			break
+		}
+	}
+	return b
+}
+
+func TestFeedbackService_SubmitThroughLink(t *testing.T) {
+	f := newFeedbackFixture(t)
+	b := f.ride(t, model.BookingCompleted)
+
+	outbox, err := f.notifications.GetOutbox(f.ctx, "")
+	require.NoError(t, err)
+	var request *model.Notification
+	for i := range outbox {
+		if outbox[i].Event == model.NotifyFeedbackRequest {
+			request = &outbox[i]
+		}
+	}
+	require.NotNil(t, request)
+	assert.Equal(t, "sari@example.com", request.Recipient)
+	i := strings.Index(request.Body, "https://rides.example.com/feedback/")
+	require.GreaterOrEqual(t, i, 0)
+	token := strings.TrimPrefix(request.Body[i:], "https://rides.example.com/feedback/")
+
+	// The link carries its own tenant, so it works without one in the context.
+	form, err := f.feedback.GetForm(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, b.ID, form.BookingID)
+	assert.Equal(t, "Budi", form.Driver)
+	assert.Nil(t, form.Feedback)
+
+	_, err = f.feedback.SubmitWithToken(context.Background(), token, model.FeedbackRequest{Rating: 4, Tags: []string{"teleported"}})
+	assert.ErrorIs(t, err, service.ErrInvalidTag)
+	_, err = f.feedback.SubmitWithToken(context.Background(), token+"x", model.FeedbackRequest{Rating: 4})
+	assert.ErrorIs(t, err, service.ErrInvalidLink)
+
+	submitted, err := f.feedback.SubmitWithToken(context.Background(), token,
+		model.FeedbackRequest{Rating: 4, Tags: []string{model.TagPunctual, model.TagFriendly, model.TagPunctual}, Comment: "Terima kasih"})
+	require.NoError(t, err)
+	assert.Equal(t, model.FeedbackFromLink, submitted.Source)
+	assert.Equal(t, []string{model.TagPunctual, model.TagFriendly}, submitted.Tags)
+	assert.Equal(t, f.driver.ID, *submitted.DriverID)
+	assert.Equal(t, f.customer.ID, *submitted.CustomerID)
+
+	_, err = f.feedback.SubmitWithToken(context.Background(), token, model.FeedbackRequest{Rating: 1})
+	assert.ErrorIs(t, err, service.ErrFeedbackExists)
+	_, err = f.feedback.Submit(f.ctx, b.ID, model.FeedbackRequest{Rating: 1})
+	assert.ErrorIs(t, err, service.ErrFeedbackExists)
+
+	found, err := f.feedback.GetByBooking(f.ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, found.Rating)
+	trip, err := f.bookings.GetTrip(f.ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, float32(4), trip.Rating)
+	assert.Equal(t, "Terima kasih", trip.Feedback)
+
+	driver, err := f.bookings.Drivers.GetByID(f.ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, driver.RatingCount)
+	assert.Equal(t, 4.0, driver.RatingAverage)
+}
+
+func TestFeedbackService_RequiresCompletedTrip(t *testing.T) {
+	f := newFeedbackFixture(t)
+
+	_, err := f.feedback.Submit(f.ctx, "missing", model.FeedbackRequest{Rating: 5})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	confirmed := f.ride(t, model.BookingConfirmed)
+	_, err = f.feedback.Submit(f.ctx, confirmed.ID, model.FeedbackRequest{Rating: 5})
+	assert.ErrorIs(t, err, service.ErrTripNotCompleted)
+
+	onTrip := f.ride(t, model.BookingOnTrip)
+	_, err = f.feedback.Submit(f.ctx, onTrip.ID, model.FeedbackRequest{Rating: 5})
+	assert.ErrorIs(t, err, service.ErrTripNotCompleted)
+	_, err = f.feedback.GetByBooking(f.ctx, onTrip.ID)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestFeedbackService_AlertsOnLowRollingAverage(t *testing.T) {
+	f := newFeedbackFixture(t)
+	f.feedback.MinRatings, f.feedback.Window = 3, 3
+
+	// Two poor ratings are not enough to judge a driver by.
+	for _, stars := range []int{5, 2, 2} {
+		if stars == 2 {
+			alerts, err := f.feedback.GetAlerts(f.ctx, true)
+			require.NoError(t, err)
+			assert.Empty(t, alerts)
+		}
+		b := f.ride(t, model.BookingCompleted)
+		_, err := f.feedback.Submit(f.ctx, b.ID, model.FeedbackRequest{Rating: stars})
+		require.NoError(t, err)
+	}
+
+	alerts, err := f.feedback.GetAlerts(f.ctx, true)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "Budi", alerts[0].DriverName)
+	assert.Equal(t, 3.0, alerts[0].RollingAverage)
+	assert.Equal(t, 3.5, alerts[0].Threshold)
+
+	outbox, err := f.notifications.GetOutbox(f.ctx, "")
+	require.NoError(t, err)
+	var sent []model.Notification
+	for _, n := range outbox {
+		if n.Event == model.NotifyDriverRatingLow {
+			sent = append(sent, n)
+		}
+	}
+	require.Len(t, sent, 1)
+	assert.Equal(t, "ops@example.com", sent[0].Recipient)
+	assert.Equal(t, "Rata-rata rating terbaru Budi turun menjadi 3.00, di bawah batas 3.50. Mohon ditindaklanjuti.", sent[0].Body)
+
+	// Further poor ratings keep the one open alert until it is acknowledged.
+	b := f.ride(t, model.BookingCompleted)
+	_, err = f.feedback.Submit(f.ctx, b.ID, model.FeedbackRequest{Rating: 1})
+	require.NoError(t, err)
+	alerts, err = f.feedback.GetAlerts(f.ctx, false)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+
+	require.NoError(t, f.feedback.AcknowledgeAlert(f.ctx, alerts[0].ID, 7))
+	assert.ErrorIs(t, f.feedback.AcknowledgeAlert(f.ctx, alerts[0].ID, 7), service.ErrNotFound)
+
+	b = f.ride(t, model.BookingCompleted)
+	_, err = f.feedback.Submit(f.ctx, b.ID, model.FeedbackRequest{Rating: 2})
+	require.NoError(t, err)
+	alerts, err = f.feedback.GetAlerts(f.ctx, true)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+
+	feedback, err := f.feedback.GetByDriver(f.ctx, f.driver.ID)
+	require.NoError(t, err)
+	assert.Len(t, feedback, 5)
+	_, err = f.feedback.GetByDriver(f.ctx, 99)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
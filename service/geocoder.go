@@ -0,0 +1,183 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/utils"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrAddressNotFound = errors.New("address could not be geocoded")
+
+// Geocoder resolves a free-text address to a coordinate.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (*model.GeoPoint, error)
+}
+
+// Gazetteer is an offline geocoder over a fixed list of named places. An
+// address matches a place when it is the place's name or contains it as a
+// whole word, so "Jl. Merdeka, Bandung" resolves to Bandung; the longest
+// matching name wins.
+type Gazetteer struct {
+	places map[string]model.GeoPoint
+}
+
+func NewGazetteer(places []model.GeoPoint) *Gazetteer {
+	g := &Gazetteer{places: make(map[string]model.GeoPoint, len(places))}
+	for _, p := range places {
+		g.places[normalizePlace(p.Name)] = p
+	}
+	return g
+}
+
+// DefaultGazetteer knows the cities and transport hubs most bookings start or
+// end at.
+func DefaultGazetteer() *Gazetteer {
+	return NewGazetteer([]model.GeoPoint{
+		{Name: "Jakarta", Lat: -6.1754, Lng: 106.8272},
+		{Name: "Bandung", Lat: -6.9025, Lng: 107.6188},
+		{Name: "Bogor", Lat: -6.5971, Lng: 106.8060},
+		{Name: "Depok", Lat: -6.4025, Lng: 106.7942},
+		{Name: "Tangerang", Lat: -6.1783, Lng: 106.6319},
+		{Name: "Bekasi", Lat: -6.2383, Lng: 106.9756},
+		{Name: "Surabaya", Lat: -7.2575, Lng: 112.7521},
+		{Name: "Yogyakarta", Lat: -7.7956, Lng: 110.3695},
+		{Name: "Semarang", Lat: -6.9667, Lng: 110.4167},
+		{Name: "Denpasar", Lat: -8.6705, Lng: 115.2126},
+		{Name: "Stasiun Gambir", Lat: -6.1767, Lng: 106.8306},
+		{Name: "Bandara Soekarno-Hatta", Lat: -6.1256, Lng: 106.6559},
+		{Name: "Bandara Halim Perdanakusuma", Lat: -6.2666, Lng: 106.8911},
+	})
+}
+
+// LoadGazetteer reads places from CSV rows of name,lat,lng. A header row is
+// skipped.
+func LoadGazetteer(r io.Reader) (*Gazetteer, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	cr.TrimLeadingSpace = true
+
+	var places []model.GeoPoint
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		lat, latErr := strconv.ParseFloat(rec[1], 64)
+		lng, lngErr := strconv.ParseFloat(rec[2], 64)
+		if latErr != nil || lngErr != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("gazetteer line %d: invalid coordinates", line)
+		}
+		if !utils.ValidCoordinates(lat, lng) {
+			return nil, fmt.Errorf("gazetteer line %d: %w", line, ErrInvalidLocation)
+		}
+		places = append(places, model.GeoPoint{Name: rec[0], Lat: lat, Lng: lng})
+	}
+	return NewGazetteer(places), nil
+}
+
+func (g *Gazetteer) Geocode(ctx context.Context, address string) (*model.GeoPoint, error) {
+	addr := normalizePlace(address)
+	if addr == "" {
+		return nil, ErrAddressNotFound
+	}
+	if p, ok := g.places[addr]; ok {
+		return &p, nil
+	}
+
+	var best *model.GeoPoint
+	var bestLen int
+	padded := " " + addr + " "
+	for name, p := range g.places {
+		if len(name) > bestLen && strings.Contains(padded, " "+name+" ") {
+			p := p
+			best, bestLen = &p, len(name)
+		}
+	}
+	if best == nil {
+		return nil, ErrAddressNotFound
+	}
+	return best, nil
+}
+
+// normalizePlace lower-cases a name and reduces it to words separated by
+// single spaces.
+func normalizePlace(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), " ")
+}
+
+// NominatimGeocoder looks addresses up on an OpenStreetMap Nominatim server.
+// Public servers require an identifying UserAgent.
+type NominatimGeocoder struct {
+	URL       string
+	UserAgent string
+	Client    *http.Client
+}
+
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address string) (*model.GeoPoint, error) {
+	if strings.TrimSpace(address) == "" {
+		return nil, ErrAddressNotFound
+	}
+
+	q := url.Values{"q": {address}, "format": {"json"}, "limit": {"1"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(g.URL, "/")+"/search?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if g.UserAgent != "" {
+		req.Header.Set("User-Agent", g.UserAgent)
+	}
+
+	client := g.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("geocoder returned %s", resp.Status)
+	}
+
+	var results []struct {
+		DisplayName string `json:"display_name"`
+		Lat         string `json:"lat"`
+		Lon         string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrAddressNotFound
+	}
+
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return nil, err
+	}
+	lng, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &model.GeoPoint{Name: results[0].DisplayName, Lat: lat, Lng: lng}, nil
+}
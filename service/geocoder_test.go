@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"auth-service/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGazetteer_Geocode(t *testing.T) {
+	g := service.DefaultGazetteer()
+	ctx := context.Background()
+
+	p, err := g.Geocode(ctx, "  stasiun GAMBIR ")
+	require.NoError(t, err)
+	assert.Equal(t, "Stasiun Gambir", p.Name)
+
+	p, err = g.Geocode(ctx, "Terminal 3, Bandara Soekarno-Hatta, Tangerang")
+	require.NoError(t, err)
+	assert.Equal(t, "Bandara Soekarno-Hatta", p.Name)
+
+	_, err = g.Geocode(ctx, "Bandungan")
+	assert.ErrorIs(t, err, service.ErrAddressNotFound)
+	_, err = g.Geocode(ctx, "")
+	assert.ErrorIs(t, err, service.ErrAddressNotFound)
+}
+
+func TestLoadGazetteer(t *testing.T) {
+	g, err := service.LoadGazetteer(strings.NewReader("name,lat,lng\nKantor Pusat,-6.2,106.8\n\"Pool, Cibubur\",-6.37,106.89\n"))
+	require.NoError(t, err)
+
+	p, err := g.Geocode(context.Background(), "pool cibubur")
+	require.NoError(t, err)
+	assert.Equal(t, -6.37, p.Lat)
+
+	_, err = service.LoadGazetteer(strings.NewReader("Kantor,-6.2,106.8\nGudang,x,106.8\n"))
+	assert.ErrorContains(t, err, "line 2")
+	_, err = service.LoadGazetteer(strings.NewReader("Kutub,95,0\n"))
+	assert.ErrorIs(t, err, service.ErrInvalidLocation)
+}
+
+func TestNominatimGeocoder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search", r.URL.Path)
+		assert.Equal(t, "test-agent", r.UserAgent())
+		if r.URL.Query().Get("q") == "Monas" {
+			w.Write([]byte(`[{"display_name":"Monumen Nasional, Jakarta","lat":"-6.1754","lon":"106.8272"}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	g := &service.NominatimGeocoder{URL: srv.URL, UserAgent: "test-agent"}
+	p, err := g.Geocode(context.Background(), "Monas")
+	require.NoError(t, err)
+	assert.Equal(t, "Monumen Nasional, Jakarta", p.Name)
+	assert.Equal(t, 106.8272, p.Lng)
+
+	_, err = g.Geocode(context.Background(), "Atlantis")
+	assert.ErrorIs(t, err, service.ErrAddressNotFound)
+}
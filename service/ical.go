@@ -0,0 +1,61 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icalLineLimit is the longest content line RFC 5545 allows, in octets,
+// before it has to be folded.
+const icalLineLimit = 75
+
+const (
+	icalDateTime = "20060102T150405Z"
+	icalDate     = "20060102"
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icalWriter writes iCalendar content lines, folding long ones and ending
+// every line with CRLF.
+type icalWriter struct {
+	buf bytes.Buffer
+}
+
+// raw writes a property whose value is already in iCalendar form.
+func (w *icalWriter) raw(name, value string) {
+	line := name + ":" + value
+	for len(line) > icalLineLimit {
+		// Fold on a rune boundary. Continuation lines start with a space,
+		// which counts against their own limit.
+		cut := icalLineLimit
+		for cut > 1 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut])
+		w.buf.WriteString("\r\n")
+		line = " " + line[cut:]
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
+
+// text writes a TEXT property, escaping the characters RFC 5545 reserves.
+func (w *icalWriter) text(name, value string) {
+	w.raw(name, icalEscaper.Replace(value))
+}
+
+func (w *icalWriter) dateTime(name string, t time.Time) {
+	w.raw(name, t.UTC().Format(icalDateTime))
+}
+
+// date writes an all-day DATE value, taking the day as t's calendar day.
+func (w *icalWriter) date(name string, t time.Time) {
+	w.raw(name+";VALUE=DATE", t.Format(icalDate))
+}
+
+func (w *icalWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
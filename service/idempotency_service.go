@@ -0,0 +1,95 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still being processed")
+	ErrIdempotencyMismatch   = errors.New("idempotency key was already used with a different request")
+)
+
+type IdempotencyServiceInterface interface {
+	Begin(ctx context.Context, key, method, path string, body []byte) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, key string, statusCode int, body []byte) error
+	Release(ctx context.Context, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type IdempotencyService struct {
+	Repo      repository.IdempotencyRepositoryInterface
+	Retention time.Duration
+	Now       func() time.Time
+}
+
+func NewIdempotencyService(repo repository.IdempotencyRepositoryInterface, retention time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		Repo:      repo,
+		Retention: retention,
+		Now:       time.Now,
+	}
+}
+
+func RequestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *IdempotencyService) Begin(ctx context.Context, key, method, path string, body []byte) (*model.IdempotencyKey, error) {
+	now := s.Now()
+	record := &model.IdempotencyKey{
+		Key:         key,
+		Method:      method,
+		Path:        path,
+		Fingerprint: RequestFingerprint(method, path, body),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.Retention),
+	}
+
+	reserved, err := s.Repo.Reserve(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	existing, err := s.Repo.FindByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrIdempotencyInProgress
+	}
+	if existing.Fingerprint != record.Fingerprint {
+		return nil, ErrIdempotencyMismatch
+	}
+	if existing.StatusCode == 0 {
+		return nil, ErrIdempotencyInProgress
+	}
+
+	return existing, nil
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	return s.Repo.Complete(ctx, key, statusCode, body)
+}
+
+func (s *IdempotencyService) Release(ctx context.Context, key string) error {
+	return s.Repo.Release(ctx, key)
+}
+
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.Repo.DeleteExpired(ctx, s.Now())
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, k *model.IdempotencyKey) (bool, error) {
+	args := m.Called(k)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) FindByKey(ctx context.Context, key string) (*model.IdempotencyKey, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, key string, statusCode int, body []byte) error {
+	args := m.Called(key, statusCode, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Release(ctx context.Context, key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"amount":100}`)
+	fp := service.RequestFingerprint("POST", "/payments", body)
+
+	t.Run("first request reserves key", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		svc := service.NewIdempotencyService(repo, time.Hour)
+		svc.Now = func() time.Time { return now }
+
+		repo.On("Reserve", mock.MatchedBy(func(k *model.IdempotencyKey) bool {
+			return k.Key == "k1" && k.Fingerprint == fp && k.ExpiresAt.Equal(now.Add(time.Hour))
+		})).Return(true, nil)
+
+		stored, err := svc.Begin(context.Background(), "k1", "POST", "/payments", body)
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("replay returns stored response", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		svc := service.NewIdempotencyService(repo, time.Hour)
+
+		existing := &model.IdempotencyKey{Key: "k1", Fingerprint: fp, StatusCode: 201, ResponseBody: []byte("{}")}
+		repo.On("Reserve", mock.Anything).Return(false, nil)
+		repo.On("FindByKey", "k1").Return(existing, nil)
+
+		stored, err := svc.Begin(context.Background(), "k1", "POST", "/payments", body)
+		assert.NoError(t, err)
+		assert.Equal(t, existing, stored)
+	})
+
+	t.Run("different body is rejected", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		svc := service.NewIdempotencyService(repo, time.Hour)
+
+		repo.On("Reserve", mock.Anything).Return(false, nil)
+		repo.On("FindByKey", "k1").Return(&model.IdempotencyKey{Key: "k1", Fingerprint: "other", StatusCode: 201}, nil)
+
+		_, err := svc.Begin(context.Background(), "k1", "POST", "/payments", body)
+		assert.ErrorIs(t, err, service.ErrIdempotencyMismatch)
+	})
+
+	t.Run("pending request is reported in progress", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		svc := service.NewIdempotencyService(repo, time.Hour)
+
+		repo.On("Reserve", mock.Anything).Return(false, nil)
+		repo.On("FindByKey", "k1").Return(&model.IdempotencyKey{Key: "k1", Fingerprint: fp}, nil)
+
+		_, err := svc.Begin(context.Background(), "k1", "POST", "/payments", body)
+		assert.ErrorIs(t, err, service.ErrIdempotencyInProgress)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := new(MockIdempotencyRepository)
+		svc := service.NewIdempotencyService(repo, time.Hour)
+
+		repo.On("Reserve", mock.Anything).Return(false, errors.New("db down"))
+
+		_, err := svc.Begin(context.Background(), "k1", "POST", "/payments", body)
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestIdempotencyService_PurgeExpired(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	svc := service.NewIdempotencyService(repo, time.Hour)
+	svc.Now = func() time.Time { return now }
+
+	repo.On("DeleteExpired", now).Return(int64(2), nil)
+
+	n, err := svc.PurgeExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
@@ -3,13 +3,14 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type MaintenanceServiceInterface interface {
-	Create(*model.VehicleMaintenance) error
-	FindByVehicle(vehicleID int) ([]model.VehicleMaintenance, error)
-	Update(*model.VehicleMaintenance) error
-	Delete(id uint) error
+	Create(ctx context.Context, m *model.VehicleMaintenance) error
+	FindByVehicle(ctx context.Context, vehicleID int) ([]model.VehicleMaintenance, error)
+	Update(ctx context.Context, m *model.VehicleMaintenance) error
+	Delete(ctx context.Context, id uint) error
 }
 
 type MaintenanceService struct {
@@ -20,18 +21,18 @@ func NewMaintenanceService(repo repository.MaintenanceRepositoryInterface) *Main
 	return &MaintenanceService{Repo: repo}
 }
 
-func (s *MaintenanceService) Create(m *model.VehicleMaintenance) error {
-	return s.Repo.Create(m)
+func (s *MaintenanceService) Create(ctx context.Context, m *model.VehicleMaintenance) error {
+	return s.Repo.Create(ctx, m)
 }
 
-func (s *MaintenanceService) FindByVehicle(vehicleID int) ([]model.VehicleMaintenance, error) {
-	return s.Repo.FindByVehicle(vehicleID)
+func (s *MaintenanceService) FindByVehicle(ctx context.Context, vehicleID int) ([]model.VehicleMaintenance, error) {
+	return s.Repo.FindByVehicle(ctx, vehicleID)
 }
 
-func (s *MaintenanceService) Update(m *model.VehicleMaintenance) error {
-	return s.Repo.Update(m)
+func (s *MaintenanceService) Update(ctx context.Context, m *model.VehicleMaintenance) error {
+	return s.Repo.Update(ctx, m)
 }
 
-func (s *MaintenanceService) Delete(id uint) error {
-	return s.Repo.Delete(id)
+func (s *MaintenanceService) Delete(ctx context.Context, id uint) error {
+	return s.Repo.Delete(ctx, id)
 }
@@ -3,6 +3,7 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,22 +14,22 @@ type MockMaintenanceRepository struct {
 	mock.Mock
 }
 
-func (m *MockMaintenanceRepository) Create(maint *model.VehicleMaintenance) error {
+func (m *MockMaintenanceRepository) Create(ctx context.Context, maint *model.VehicleMaintenance) error {
 	args := m.Called(maint)
 	return args.Error(0)
 }
 
-func (m *MockMaintenanceRepository) FindByVehicle(vehicleID int) ([]model.VehicleMaintenance, error) {
+func (m *MockMaintenanceRepository) FindByVehicle(ctx context.Context, vehicleID int) ([]model.VehicleMaintenance, error) {
 	args := m.Called(vehicleID)
 	return args.Get(0).([]model.VehicleMaintenance), args.Error(1)
 }
 
-func (m *MockMaintenanceRepository) Update(maint *model.VehicleMaintenance) error {
+func (m *MockMaintenanceRepository) Update(ctx context.Context, maint *model.VehicleMaintenance) error {
 	args := m.Called(maint)
 	return args.Error(0)
 }
 
-func (m *MockMaintenanceRepository) Delete(id uint) error {
+func (m *MockMaintenanceRepository) Delete(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -44,7 +45,7 @@ func TestMaintenanceService_Create(t *testing.T) {
 	}
 	mockRepo.On("Create", maint).Return(nil)
 
-	err := svc.Create(maint)
+	err := svc.Create(context.Background(), maint)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -59,7 +60,7 @@ func TestMaintenanceService_FindByVehicle(t *testing.T) {
 	}
 	mockRepo.On("FindByVehicle", 1).Return(expected, nil)
 
-	result, err := svc.FindByVehicle(1)
+	result, err := svc.FindByVehicle(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -77,7 +78,7 @@ func TestMaintenanceService_Update(t *testing.T) {
 	}
 	mockRepo.On("Update", maint).Return(nil)
 
-	err := svc.Update(maint)
+	err := svc.Update(context.Background(), maint)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -88,7 +89,7 @@ func TestMaintenanceService_Delete(t *testing.T) {
 
 	mockRepo.On("Delete", uint(1)).Return(nil)
 
-	err := svc.Delete(1)
+	err := svc.Delete(context.Background(), 1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
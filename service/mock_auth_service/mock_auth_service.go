@@ -1,14 +1,16 @@
 package mock_auth_service
 
+import "context"
+
 type MockAuthService struct {
-	RegisterFn     func(username, password, role string) error
+	RegisterFn     func(ctx context.Context, username, password, role string) error
 	LoginFn        func(username, password string) (string, string, error)
 	RefreshTokenFn func(refreshToken string) (string, error)
 }
 
-func (m *MockAuthService) Register(username, password, role string) error {
+func (m *MockAuthService) Register(ctx context.Context, username, password, role string) error {
 	if m.RegisterFn != nil {
-		return m.RegisterFn(username, password, role)
+		return m.RegisterFn(ctx, username, password, role)
 	}
 	return nil
 }
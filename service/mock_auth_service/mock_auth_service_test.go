@@ -1,6 +1,7 @@
 package mock_auth_service
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -62,7 +63,7 @@ func TestMockAuthService_RefreshToken_WithError(t *testing.T) {
 
 func TestMockAuthService_Register_WithFn(t *testing.T) {
 	mock := &MockAuthService{
-		RegisterFn: func(username, password, role string) error {
+		RegisterFn: func(ctx context.Context, username, password, role string) error {
 			assert.Equal(t, "user1", username)
 			assert.Equal(t, "pass123", password)
 			assert.Equal(t, "admin", role)
@@ -70,18 +71,18 @@ func TestMockAuthService_Register_WithFn(t *testing.T) {
 		},
 	}
 
-	err := mock.Register("user1", "pass123", "admin")
+	err := mock.Register(context.Background(), "user1", "pass123", "admin")
 	assert.NoError(t, err)
 }
 
 func TestMockAuthService_Register_WithError(t *testing.T) {
 	mock := &MockAuthService{
-		RegisterFn: func(username, password, role string) error {
+		RegisterFn: func(ctx context.Context, username, password, role string) error {
 			return errors.New("username already exists")
 		},
 	}
 
-	err := mock.Register("user1", "pass123", "admin")
+	err := mock.Register(context.Background(), "user1", "pass123", "admin")
 	assert.Error(t, err)
 	assert.Equal(t, "username already exists", err.Error())
 }
@@ -89,7 +90,7 @@ func TestMockAuthService_Register_WithError(t *testing.T) {
 func TestMockAuthService_Register_WithoutFn(t *testing.T) {
 	mock := &MockAuthService{}
 
-	err := mock.Register("user1", "pass123", "admin")
+	err := mock.Register(context.Background(), "user1", "pass123", "admin")
 	assert.NoError(t, err) // default behavior returns nil
 }
 
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockGateway is a local stand-in for a payment gateway. It serves the API
+// Gateway talks to, keeps its charges in memory and pays nothing out.
+//
+// Customers are simulated with POST /{order_id}/pay, /authorize and /expire,
+// which move the charge along and send the signed callback a real gateway
+// would to CallbackURL. Captures and refunds are answered directly and send
+// no callback.
+type MockGateway struct {
+	ServerKey      string
+	CallbackURL    string
+	CallbackSecret string
+	Client         *http.Client
+
+	mu      sync.Mutex
+	seq     int
+	charges map[string]*gatewayTransaction
+	mux     *http.ServeMux
+}
+
+func NewMockGateway(serverKey, callbackURL, callbackSecret string) *MockGateway {
+	g := &MockGateway{
+		ServerKey:      serverKey,
+		CallbackURL:    callbackURL,
+		CallbackSecret: callbackSecret,
+		charges:        map[string]*gatewayTransaction{},
+		mux:            http.NewServeMux(),
+	}
+	g.mux.HandleFunc("POST /charge", g.createCharge)
+	g.mux.HandleFunc("GET /{ref}/status", g.status)
+	g.mux.HandleFunc("POST /{ref}/capture", g.capture)
+	g.mux.HandleFunc("POST /{ref}/refund", g.refund)
+	g.mux.HandleFunc("POST /{ref}/pay", g.customerAction("settlement", "pending", "authorize"))
+	g.mux.HandleFunc("POST /{ref}/authorize", g.customerAction("authorize", "pending"))
+	g.mux.HandleFunc("POST /{ref}/expire", g.customerAction("expire", "pending", "authorize"))
+	return g
+}
+
+func (g *MockGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if key, _, ok := r.BasicAuth(); !ok || key != g.ServerKey {
+		if r.Method != http.MethodPost || !isCustomerAction(r.URL.Path) {
+			mockError(w, http.StatusUnauthorized, "invalid server key")
+			return
+		}
+	}
+	g.mux.ServeHTTP(w, r)
+}
+
+// isCustomerAction reports whether path simulates the customer, which needs
+// no server key.
+func isCustomerAction(path string) bool {
+	for _, action := range []string{"/pay", "/authorize", "/expire"} {
+		if strings.HasSuffix(path, action) {
+			return true
+		}
+	}
+	return false
+}
+
+type mockChargeRequest struct {
+	PaymentType        string `json:"payment_type"`
+	TransactionDetails struct {
+		OrderID     string `json:"order_id"`
+		GrossAmount string `json:"gross_amount"`
+	} `json:"transaction_details"`
+	BankTransfer struct {
+		Bank string `json:"bank"`
+	} `json:"bank_transfer"`
+	CustomExpiry struct {
+		ExpiryDuration int `json:"expiry_duration"`
+	} `json:"custom_expiry"`
+}
+
+func (g *MockGateway) createCharge(w http.ResponseWriter, r *http.Request) {
+	var req mockChargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mockError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	amount, err := parseGatewayAmount(req.TransactionDetails.GrossAmount)
+	if err != nil || amount <= 0 || req.TransactionDetails.OrderID == "" || req.PaymentType == "" {
+		mockError(w, http.StatusBadRequest, "order_id, payment_type and a positive gross_amount are required")
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ref := req.TransactionDetails.OrderID
+	if _, ok := g.charges[ref]; ok {
+		mockError(w, http.StatusConflict, "order_id has already been used")
+		return
+	}
+
+	expiry := 24 * time.Hour
+	if req.CustomExpiry.ExpiryDuration > 0 {
+		expiry = time.Duration(req.CustomExpiry.ExpiryDuration) * time.Minute
+	}
+	g.seq++
+	t := &gatewayTransaction{
+		TransactionID:     fmt.Sprintf("mock-%06d", g.seq),
+		OrderID:           ref,
+		PaymentType:       req.PaymentType,
+		GrossAmount:       formatGatewayAmount(amount),
+		TransactionStatus: "pending",
+		ExpiryTime:        time.Now().Add(expiry).In(wib).Format(gatewayTimeLayout),
+	}
+	switch req.PaymentType {
+	case "bank_transfer":
+		t.VANumbers = []gatewayVANumber{{Bank: req.BankTransfer.Bank, VANumber: fmt.Sprintf("8808%012d", g.seq)}}
+	case "qris":
+		t.QRString = "00020101021226570014ID.MOCKPAY.WWW0215" + ref + "5204411153033605802ID6304MOCK"
+	default:
+		t.Actions = []gatewayAction{{Name: "deeplink-redirect", URL: "mockpay://" + req.PaymentType + "/pay?order_id=" + ref}}
+	}
+	g.charges[ref] = t
+
+	writeMockJSON(w, http.StatusCreated, t)
+}
+
+func (g *MockGateway) status(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	t, ok := g.charges[r.PathValue("ref")]
+	if !ok {
+		mockError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	writeMockJSON(w, http.StatusOK, t)
+}
+
+func (g *MockGateway) capture(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	t, ok := g.charges[r.PathValue("ref")]
+	if !ok {
+		mockError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if t.TransactionStatus != "authorize" {
+		mockError(w, http.StatusConflict, "only authorized transactions can be captured")
+		return
+	}
+	t.TransactionStatus = "capture"
+	writeMockJSON(w, http.StatusOK, t)
+}
+
+func (g *MockGateway) refund(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount string `json:"amount"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mockError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	t, ok := g.charges[r.PathValue("ref")]
+	if !ok {
+		mockError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	switch t.TransactionStatus {
+	case "capture", "settlement", "partial_refund":
+	default:
+		mockError(w, http.StatusConflict, "transaction cannot be refunded in status "+t.TransactionStatus)
+		return
+	}
+
+	gross, _ := parseGatewayAmount(t.GrossAmount)
+	refunded, _ := parseGatewayAmount(t.RefundAmount)
+	amount, err := parseGatewayAmount(req.Amount)
+	refunded = math.Round((refunded+amount)*100) / 100
+	if err != nil || amount <= 0 || refunded > gross {
+		mockError(w, http.StatusBadRequest, "refund amount must be positive and within the captured amount")
+		return
+	}
+	t.RefundAmount = formatGatewayAmount(refunded)
+	t.TransactionStatus = "partial_refund"
+	if refunded == gross {
+		t.TransactionStatus = "refund"
+	}
+	writeMockJSON(w, http.StatusOK, t)
+}
+
+// customerAction moves a charge in one of the from statuses to status, as the
+// customer paying or letting the charge lapse would, and sends the callback.
+func (g *MockGateway) customerAction(status string, from ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g.mu.Lock()
+		t, ok := g.charges[r.PathValue("ref")]
+		if !ok {
+			g.mu.Unlock()
+			mockError(w, http.StatusNotFound, "transaction not found")
+			return
+		}
+		allowed := false
+		for _, s := range from {
+			allowed = allowed || t.TransactionStatus == s
+		}
+		if !allowed {
+			current := t.TransactionStatus
+			g.mu.Unlock()
+			mockError(w, http.StatusConflict, "transaction is already "+current)
+			return
+		}
+		t.TransactionStatus = status
+		snapshot := *t
+		g.mu.Unlock()
+
+		if err := g.notify(r.Context(), snapshot); err != nil {
+			log.Printf("Gagal mengirim callback pembayaran %s: %v\n", snapshot.OrderID, err)
+		}
+		writeMockJSON(w, http.StatusOK, snapshot)
+	}
+}
+
+// notify sends the signed callback for t.
+func (g *MockGateway) notify(ctx context.Context, t gatewayTransaction) error {
+	if g.CallbackURL == "" {
+		return nil
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	body, err := json.Marshal(gatewayNotification{EventID: hex.EncodeToString(id), gatewayTransaction: t})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(CallbackTimestampHeader, timestamp)
+	req.Header.Set(CallbackSignatureHeader, SignCallback(g.CallbackSecret, timestamp, body))
+
+	client := g.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return nil
+}
+
+func writeMockJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func mockError(w http.ResponseWriter, status int, message string) {
+	writeMockJSON(w, status, map[string]string{"status_message": message})
+}
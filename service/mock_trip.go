@@ -11,22 +11,22 @@ type MockTripsRepository struct {
 	mock.Mock
 }
 
-func (m *MockTripsRepository) Create(t *model.VehicleTrip) error {
+func (m *MockTripsRepository) Create(ctx context.Context, t *model.VehicleTrip) error {
 	args := m.Called(t)
 	return args.Error(0)
 }
 
-func (m *MockTripsRepository) Update(t *model.VehicleTrip) error {
+func (m *MockTripsRepository) Update(ctx context.Context, t *model.VehicleTrip) error {
 	args := m.Called(t)
 	return args.Error(0)
 }
 
-func (m *MockTripsRepository) Delete(id uint) error {
+func (m *MockTripsRepository) Delete(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockTripsRepository) FindByVehicle(vehicleID uint) ([]model.VehicleTrip, error) {
+func (m *MockTripsRepository) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.VehicleTrip, error) {
 	args := m.Called(vehicleID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -34,6 +34,14 @@ func (m *MockTripsRepository) FindByVehicle(vehicleID uint) ([]model.VehicleTrip
 	return args.Get(0).([]model.VehicleTrip), args.Error(1)
 }
 
+func (m *MockTripsRepository) FindByBooking(ctx context.Context, bookingID string) (*model.VehicleTrip, error) {
+	args := m.Called(bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.VehicleTrip), args.Error(1)
+}
+
 func (m *MockTripsRepository) GetTripTotal(ctx context.Context) (*model.TotalTrips, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"auth-service/model"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// NotificationSender delivers one rendered message over a single channel.
+type NotificationSender interface {
+	Send(ctx context.Context, n *model.Notification) error
+}
+
+// SMTPSender sends email notifications through an SMTP relay.
+type SMTPSender struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+// NewSMTPSender authenticates with PLAIN when a username is given.
+func NewSMTPSender(addr, from, username, password string) *SMTPSender {
+	s := &SMTPSender{Addr: addr, From: from}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		s.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return s
+}
+
+func (s *SMTPSender) Send(ctx context.Context, n *model.Notification) error {
+	return smtp.SendMail(s.Addr, s.Auth, s.From, []string{n.Recipient}, emailMessage(s.From, n))
+}
+
+func emailMessage(from string, n *model.Notification) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", n.Recipient)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(n.Body)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+// SMSSender posts messages to an HTTP SMS gateway as {"from","to","message"}
+// with a bearer token.
+type SMSSender struct {
+	URL    string
+	Token  string
+	From   string
+	Client *http.Client
+}
+
+func (s *SMSSender) Send(ctx context.Context, n *model.Notification) error {
+	return postJSON(ctx, s.Client, s.URL, s.Token, map[string]string{
+		"from":    s.From,
+		"to":      n.Recipient,
+		"message": n.Body,
+	})
+}
+
+// WhatsAppSender sends text messages through the WhatsApp Cloud API messages
+// endpoint of a business phone number.
+type WhatsAppSender struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+func (s *WhatsAppSender) Send(ctx context.Context, n *model.Notification) error {
+	return postJSON(ctx, s.Client, s.URL, s.Token, map[string]any{
+		"messaging_product": "whatsapp",
+		"to":                strings.TrimPrefix(n.Recipient, "+"),
+		"type":              "text",
+		"text":              map[string]string{"body": n.Body},
+	})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url, token string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("provider returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// LogSender stands in for a real provider by writing each message to a log,
+// so notifications can be followed locally without any credentials.
+type LogSender struct {
+	Logger *log.Logger
+}
+
+// NewFileSender appends messages to the file at path.
+func NewFileSender(path string) (*LogSender, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &LogSender{Logger: log.New(f, "", log.LstdFlags)}, nil
+}
+
+func (s *LogSender) Send(ctx context.Context, n *model.Notification) error {
+	s.Logger.Printf("[%s] to=%s event=%s subject=%q body=%q", n.Channel, n.Recipient, n.Event, n.Subject, n.Body)
+	return nil
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPNotificationSenders(t *testing.T) {
+	var got map[string]any
+	var auth string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		got = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":"quota exceeded"}`))
+	}))
+	defer server.Close()
+
+	n := &model.Notification{Channel: model.ChannelSMS, Recipient: "+628111", Body: "Halo"}
+
+	sms := &service.SMSSender{URL: server.URL, Token: "sms-token", From: "RENTAL"}
+	require.NoError(t, sms.Send(context.Background(), n))
+	assert.Equal(t, "Bearer sms-token", auth)
+	assert.Equal(t, map[string]any{"from": "RENTAL", "to": "+628111", "message": "Halo"}, got)
+
+	wa := &service.WhatsAppSender{URL: server.URL, Token: "wa-token"}
+	require.NoError(t, wa.Send(context.Background(), n))
+	assert.Equal(t, "Bearer wa-token", auth)
+	assert.Equal(t, "628111", got["to"])
+	assert.Equal(t, map[string]any{"body": "Halo"}, got["text"])
+
+	status = http.StatusTooManyRequests
+	err := sms.Send(context.Background(), n)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+	assert.Contains(t, err.Error(), "quota exceeded")
+}
+
+func TestLogSender(t *testing.T) {
+	var buf bytes.Buffer
+	sender := &service.LogSender{Logger: log.New(&buf, "", 0)}
+
+	require.NoError(t, sender.Send(context.Background(), &model.Notification{
+		Event: model.NotifyBookingConfirmed, Channel: model.ChannelEmail, Recipient: "sari@example.com", Subject: "Booking BK1", Body: "Halo Sari",
+	}))
+	assert.Equal(t, "[email] to=sari@example.com event=booking_confirmed subject=\"Booking BK1\" body=\"Halo Sari\"\n", buf.String())
+}
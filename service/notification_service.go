@@ -0,0 +1,401 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidChannel = errors.New("unknown notification channel")
+	ErrInvalidLocale  = errors.New("unknown notification locale")
+)
+
+const (
+	defaultNotificationAttempts = 5
+	notificationBatch           = 50
+	notificationLease           = 5 * time.Minute
+	maxNotificationBackoff      = time.Hour
+	feedbackLinkValidity        = 14 * 24 * time.Hour
+)
+
+type NotificationServiceInterface interface {
+	GetOutbox(ctx context.Context, status string) ([]model.Notification, error)
+	GetPreference(ctx context.Context, customerID int) (*model.NotificationPreference, error)
+	SavePreference(ctx context.Context, p *model.NotificationPreference) error
+}
+
+// NotificationService renders messages into the outbox and delivers them
+// through Senders, keyed by channel. Feedback links sent after a trip point
+// at FeedbackURL; without one no feedback request is sent.
+type NotificationService struct {
+	Repo        repository.NotificationRepositoryInterface
+	Customers   repository.CustomerRepositoryInterface
+	Drivers     repository.DriverRepositoryInterface
+	Senders     map[string]NotificationSender
+	MaxAttempts int
+	FeedbackURL string
+	Now         func() time.Time
+}
+
+func NewNotificationService(repo repository.NotificationRepositoryInterface, customers repository.CustomerRepositoryInterface,
+	drivers repository.DriverRepositoryInterface) *NotificationService {
+	return &NotificationService{
+		Repo:        repo,
+		Customers:   customers,
+		Drivers:     drivers,
+		MaxAttempts: defaultNotificationAttempts,
+		Now:         time.Now,
+	}
+}
+
+func (s *NotificationService) GetOutbox(ctx context.Context, status string) ([]model.Notification, error) {
+	return s.Repo.GetAll(ctx, status)
+}
+
+// GetPreference returns the customer's saved preference, or the default one.
+func (s *NotificationService) GetPreference(ctx context.Context, customerID int) (*model.NotificationPreference, error) {
+	if err := s.customerExists(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	p, err := s.Repo.GetPreference(ctx, customerID)
+	if err != nil || p != nil {
+		return p, err
+	}
+	return &model.NotificationPreference{CustomerID: customerID, Locale: model.LocaleID, Channels: []string{}}, nil
+}
+
+func (s *NotificationService) SavePreference(ctx context.Context, p *model.NotificationPreference) error {
+	if p.Locale == "" {
+		p.Locale = model.LocaleID
+	}
+	if p.Locale != model.LocaleID && p.Locale != model.LocaleEN {
+		return ErrInvalidLocale
+	}
+
+	channels := []string{}
+	seen := map[string]bool{}
+	for _, ch := range p.Channels {
+		if !validChannel(ch) {
+			return ErrInvalidChannel
+		}
+		if !seen[ch] {
+			seen[ch] = true
+			channels = append(channels, ch)
+		}
+	}
+	p.Channels = channels
+
+	if err := s.customerExists(ctx, p.CustomerID); err != nil {
+		return err
+	}
+	return s.Repo.SavePreference(ctx, p)
+}
+
+func (s *NotificationService) customerExists(ctx context.Context, id int) error {
+	_, err := lookupCustomer(ctx, s.Customers, &id)
+	if errors.Is(err, ErrUnknownCustomer) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func validChannel(ch string) bool {
+	return ch == model.ChannelEmail || ch == model.ChannelSMS || ch == model.ChannelWhatsApp
+}
+
+// BookingNotifications renders the messages for b entering status. Confirming
+// a booking tells the customer; assigning a driver tells both the customer and
+// the driver; completing it asks the customer for feedback. Other statuses
+// notify nobody.
+func (s *NotificationService) BookingNotifications(ctx context.Context, b *model.Booking, status string) ([]model.Notification, error) {
+	switch status {
+	case model.BookingConfirmed, model.BookingDriverAssigned:
+	case model.BookingCompleted:
+		return s.feedbackRequest(ctx, b)
+	default:
+		return nil, nil
+	}
+
+	data := notificationData{
+		BookingID: b.ID,
+		When:      bookingWhen(b),
+		Customer:  b.Customer,
+		Driver:    b.Driver,
+	}
+	if b.PickupLocation != nil {
+		data.Pickup = *b.PickupLocation
+	}
+	if b.PhoneNumber != nil {
+		data.CustomerPhone = *b.PhoneNumber
+	}
+
+	bookingID := b.ID
+	customer, err := s.customerRecipient(ctx, b.CustomerID, b.Customer, b.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if status == model.BookingConfirmed {
+		return customer.render(model.NotifyBookingConfirmed, data, &bookingID)
+	}
+
+	var driver *notificationRecipient
+	if b.DriverID != nil && s.Drivers != nil {
+		d, err := lookupDriver(ctx, s.Drivers, *b.DriverID)
+		if err != nil && !errors.Is(err, ErrUnknownDriver) {
+			return nil, err
+		}
+		if d != nil {
+			data.Driver, data.DriverPhone, data.Plate = d.Name, d.Phone, d.PlateNumber
+			driver = driverRecipient(d)
+		}
+	}
+
+	notifications, err := customer.render(model.NotifyDriverAssigned, data, &bookingID)
+	if err != nil || driver == nil {
+		return notifications, err
+	}
+	forDriver, err := driver.render(model.NotifyTripAssigned, data, &bookingID)
+	if err != nil {
+		return nil, err
+	}
+	return append(notifications, forDriver...), nil
+}
+
+// feedbackRequest sends the customer a signed link to rate the trip of b.
+func (s *NotificationService) feedbackRequest(ctx context.Context, b *model.Booking) ([]model.Notification, error) {
+	tenantID, ok := utils.TenantFromContext(ctx)
+	if s.FeedbackURL == "" || !ok {
+		return nil, nil
+	}
+
+	token, err := utils.GenerateFeedbackToken(b.ID, tenantID, feedbackLinkValidity)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := s.customerRecipient(ctx, b.CustomerID, b.Customer, b.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	bookingID := b.ID
+	return customer.render(model.NotifyFeedbackRequest, notificationData{
+		BookingID: b.ID,
+		When:      bookingWhen(b),
+		Customer:  b.Customer,
+		Driver:    b.Driver,
+		Link:      strings.TrimRight(s.FeedbackURL, "/") + "/" + token,
+	}, &bookingID)
+}
+
+// driverRatingAlert renders the message telling the operations team at email
+// that a driver's rolling rating has dropped below the threshold.
+func driverRatingAlert(a *model.DriverRatingAlert, email string) ([]model.Notification, error) {
+	if email == "" {
+		return nil, nil
+	}
+
+	ops := &notificationRecipient{
+		locale:    model.LocaleID,
+		channels:  []string{model.ChannelEmail},
+		addresses: map[string]string{model.ChannelEmail: email},
+	}
+	return ops.render(model.NotifyDriverRatingLow, notificationData{
+		Driver:    a.DriverName,
+		Rating:    fmt.Sprintf("%.2f", a.RollingAverage),
+		Threshold: fmt.Sprintf("%.2f", a.Threshold),
+	}, nil)
+}
+
+// PaymentReceived queues a receipt for a paid payment of a known customer.
+func (s *NotificationService) PaymentReceived(ctx context.Context, p *model.Payment) error {
+	if p.Status != "paid" || p.CustomerID == nil {
+		return nil
+	}
+
+	customer, err := s.customerRecipient(ctx, p.CustomerID, p.Customer, nil)
+	if err != nil {
+		return err
+	}
+	notifications, err := customer.render(model.NotifyPaymentReceived, notificationData{
+		Customer: p.Customer,
+		Driver:   p.Driver,
+		Amount:   fmt.Sprintf("%.0f", p.Amount),
+		Method:   p.Method,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	for i := range notifications {
+		if err := s.Repo.Enqueue(ctx, &notifications[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliver sends the messages that are due in every tenant. A failed message is
+// retried with a growing delay until MaxAttempts, then marked failed. It
+// returns how many messages were sent.
+func (s *NotificationService) Deliver(ctx context.Context) (int, error) {
+	now := s.Now()
+	due, err := s.Repo.ClaimDue(ctx, now, now.Add(notificationLease), notificationBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range due {
+		n := &due[i]
+		if sendErr := s.send(ctx, n); sendErr != nil {
+			var retryAt *time.Time
+			if n.Attempts < s.MaxAttempts {
+				at := now.Add(notificationBackoff(n.Attempts))
+				retryAt = &at
+			}
+			if err := s.Repo.MarkFailed(ctx, n.ID, sendErr.Error(), retryAt); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := s.Repo.MarkSent(ctx, n.ID, s.Now()); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *NotificationService) send(ctx context.Context, n *model.Notification) error {
+	sender, ok := s.Senders[n.Channel]
+	if !ok {
+		return fmt.Errorf("no sender configured for %s", n.Channel)
+	}
+	return sender.Send(ctx, n)
+}
+
+// notificationBackoff waits one minute after the first failure and doubles
+// the wait after each further one, up to an hour.
+func notificationBackoff(attempts int) time.Duration {
+	d := time.Minute
+	for i := 1; i < attempts && d < maxNotificationBackoff; i++ {
+		d *= 2
+	}
+	if d > maxNotificationBackoff {
+		d = maxNotificationBackoff
+	}
+	return d
+}
+
+// notificationRecipient is one person to notify with the address to use on
+// each of their channels.
+type notificationRecipient struct {
+	name       string
+	locale     string
+	customerID *int
+	channels   []string
+	addresses  map[string]string
+}
+
+// customerRecipient resolves how to reach a booking's customer. Without a
+// customer record the booking's name and phone number are used over SMS.
+func (s *NotificationService) customerRecipient(ctx context.Context, id *int, name string, phone *string) (*notificationRecipient, error) {
+	r := &notificationRecipient{name: name, locale: model.LocaleID, addresses: map[string]string{}}
+	if phone != nil {
+		r.addresses[model.ChannelSMS] = *phone
+		r.addresses[model.ChannelWhatsApp] = *phone
+	}
+
+	c, err := lookupCustomer(ctx, s.Customers, id)
+	if err != nil && !errors.Is(err, ErrUnknownCustomer) {
+		return nil, err
+	}
+	if c != nil {
+		r.name, r.customerID = c.Name, &c.ID
+		if len(c.Emails) > 0 {
+			r.addresses[model.ChannelEmail] = c.Emails[0]
+		}
+		if len(c.Phones) > 0 {
+			r.addresses[model.ChannelSMS] = c.Phones[0]
+			r.addresses[model.ChannelWhatsApp] = c.Phones[0]
+		}
+
+		p, err := s.Repo.GetPreference(ctx, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			r.locale, r.channels = p.Locale, p.Channels
+		}
+	}
+
+	if len(r.channels) == 0 {
+		if r.addresses[model.ChannelEmail] != "" {
+			r.channels = []string{model.ChannelEmail}
+		} else {
+			r.channels = []string{model.ChannelSMS}
+		}
+	}
+	return r, nil
+}
+
+// driverRecipient reaches drivers on WhatsApp, or by email when they have no
+// phone number on file.
+func driverRecipient(d *model.Driver) *notificationRecipient {
+	r := &notificationRecipient{name: d.Name, locale: model.LocaleID, addresses: map[string]string{}}
+	switch {
+	case d.Phone != "":
+		r.channels = []string{model.ChannelWhatsApp}
+		r.addresses[model.ChannelWhatsApp] = d.Phone
+	case d.Email != "":
+		r.channels = []string{model.ChannelEmail}
+		r.addresses[model.ChannelEmail] = d.Email
+	}
+	return r
+}
+
+// render builds one message per channel the recipient has an address for.
+func (r *notificationRecipient) render(event string, data notificationData, bookingID *string) ([]model.Notification, error) {
+	data.Name = r.name
+
+	notifications := []model.Notification{}
+	for _, ch := range r.channels {
+		to := r.addresses[ch]
+		if to == "" {
+			continue
+		}
+		subject, body, err := renderNotification(event, r.locale, data)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, model.Notification{
+			Event:      event,
+			Channel:    ch,
+			Recipient:  to,
+			Locale:     r.locale,
+			Subject:    subject,
+			Body:       body,
+			BookingID:  bookingID,
+			CustomerID: r.customerID,
+		})
+	}
+	return notifications, nil
+}
+
+// bookingWhen is the pickup time as shown in messages.
+func bookingWhen(b *model.Booking) string {
+	if b.StartAt != nil {
+		return b.StartAt.Format("02/01/2006 15:04")
+	}
+	if b.PickupTime != nil && *b.PickupTime != "" {
+		return b.Date + " " + *b.PickupTime
+	}
+	return b.Date
+}
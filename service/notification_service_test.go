@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	sent []model.Notification
+	err  error
+}
+
+func (s *recordingSender) Send(ctx context.Context, n *model.Notification) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, *n)
+	return nil
+}
+
+func TestNotificationService_BookingLifecycle(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	customers := repository.NewMemoryCustomerRepository(store)
+	drivers := repository.NewMemoryDriverRepository(store)
+	notifications := repository.NewMemoryNotificationRepository(store)
+	svc := service.NewNotificationService(notifications, customers, drivers)
+	bookings := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Customers: customers, Drivers: drivers, Notifications: notifications}
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+
+	sari := &model.Customer{Name: "Sari", Phones: []string{"+628111"}, Emails: []string{"sari@example.com"}}
+	require.NoError(t, customers.Create(ctx, sari))
+	budi := &model.Driver{Name: "Budi", Phone: "+628222", PlateNumber: "B 1234 XY"}
+	require.NoError(t, drivers.Create(ctx, budi))
+
+	_, err := svc.GetPreference(ctx, 99)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	pref, err := svc.GetPreference(ctx, sari.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.LocaleID, pref.Locale)
+	assert.ErrorIs(t, svc.SavePreference(ctx, &model.NotificationPreference{CustomerID: sari.ID, Channels: []string{"pigeon"}}), service.ErrInvalidChannel)
+	require.NoError(t, svc.SavePreference(ctx, &model.NotificationPreference{CustomerID: sari.ID, Locale: model.LocaleEN, Channels: []string{"whatsapp", "email", "whatsapp"}}))
+
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	pickup := "Stasiun Gambir"
+	b := &model.Booking{CustomerID: &sari.ID, DriverID: &budi.ID, StartAt: &start, EndAt: &end, PickupLocation: &pickup}
+	require.NoError(t, bookings.Create(ctx, b))
+	assert.Empty(t, mustOutbox(t, svc, ctx))
+
+	_, err = bookings.Transition(ctx, b.ID, admin, model.BookingTransition{To: model.BookingConfirmed})
+	require.NoError(t, err)
+	outbox := mustOutbox(t, svc, ctx)
+	require.Len(t, outbox, 2)
+	assert.Equal(t, model.ChannelEmail, outbox[0].Channel)
+	assert.Equal(t, "sari@example.com", outbox[0].Recipient)
+	assert.Equal(t, model.ChannelWhatsApp, outbox[1].Channel)
+	assert.Equal(t, "+628111", outbox[1].Recipient)
+	assert.Equal(t, "Booking "+b.ID+" confirmed", outbox[0].Subject)
+	assert.Equal(t, "Hi Sari, your booking "+b.ID+" for 04/03/2030 09:00 has been confirmed. Pickup at Stasiun Gambir. Thank you.", outbox[0].Body)
+	assert.Equal(t, b.ID, *outbox[0].BookingID)
+	assert.Equal(t, sari.ID, *outbox[0].CustomerID)
+
+	_, err = bookings.Transition(ctx, b.ID, admin, model.BookingTransition{To: model.BookingDriverAssigned})
+	require.NoError(t, err)
+	outbox = mustOutbox(t, svc, ctx)
+	require.Len(t, outbox, 5)
+	trip := outbox[0]
+	assert.Equal(t, model.NotifyTripAssigned, trip.Event)
+	assert.Equal(t, "+628222", trip.Recipient)
+	assert.Equal(t, model.LocaleID, trip.Locale)
+	assert.Contains(t, trip.Body, "Halo Budi, Anda ditugaskan untuk booking "+b.ID+" atas nama Sari")
+	assert.Nil(t, trip.CustomerID)
+	assert.Contains(t, outbox[2].Body, "Budi (B 1234 XY) will pick you up")
+
+	_, err = bookings.Transition(ctx, b.ID, admin, model.BookingTransition{To: model.BookingOnTrip})
+	require.NoError(t, err)
+	assert.Len(t, mustOutbox(t, svc, ctx), 5)
+}
+
+func TestNotificationService_PaymentReceived(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	customers := repository.NewMemoryCustomerRepository(store)
+	notifications := repository.NewMemoryNotificationRepository(store)
+	payments := &service.PaymentService{Repo: repository.NewMemoryPaymentRepository(store), Customers: customers, Notifications: notifications}
+
+	andi := &model.Customer{Name: "Andi", Phones: []string{"+62813"}}
+	require.NoError(t, customers.Create(ctx, andi))
+
+	p := &model.Payment{CustomerID: &andi.ID, Amount: 150000, Method: "transfer", Status: "pending"}
+	id, err := payments.CreatePayment(ctx, p)
+	require.NoError(t, err)
+	outbox, err := notifications.GetAll(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, outbox)
+
+	p.PaymentID, p.Status, p.Version = id, "paid", 1
+	require.NoError(t, payments.UpdatePayment(ctx, p))
+	p.Method = "cash"
+	require.NoError(t, payments.UpdatePayment(ctx, p))
+
+	outbox, err = notifications.GetAll(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, outbox, 1)
+	assert.Equal(t, model.ChannelSMS, outbox[0].Channel)
+	assert.Equal(t, "+62813", outbox[0].Recipient)
+	assert.Equal(t, "Halo Andi, pembayaran sebesar Rp150000 via transfer telah kami terima. Terima kasih.", outbox[0].Body)
+}
+
+func TestPaymentService_CreatePaymentKeepsPaymentWhenReceiptFails(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	customers := new(MockCustomerRepository)
+	repo := repository.NewMemoryPaymentRepository(store)
+	payments := &service.PaymentService{Repo: repo, Customers: customers, Notifications: repository.NewMemoryNotificationRepository(store)}
+
+	customerID := 4
+	customers.On("GetByID", customerID).Return(&model.Customer{ID: customerID, Name: "Andi"}, nil).Once()
+	customers.On("GetByID", customerID).Return(nil, errors.New("customers down"))
+
+	id, err := payments.CreatePayment(ctx, &model.Payment{CustomerID: &customerID, Amount: 150000, Method: "cash", Status: "paid"})
+	require.NoError(t, err)
+	stored, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, model.PaymentPaid, stored.Status)
+	customers.AssertNumberOfCalls(t, "GetByID", 2)
+}
+
+func TestNotificationService_Deliver(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	repo := repository.NewMemoryNotificationRepository(store)
+	email := &recordingSender{}
+	sms := &recordingSender{err: errors.New("gateway down")}
+	svc := service.NewNotificationService(repo, nil, nil)
+	svc.Senders = map[string]service.NotificationSender{model.ChannelEmail: email, model.ChannelSMS: sms}
+	svc.MaxAttempts = 2
+
+	for _, n := range []model.Notification{
+		{Event: model.NotifyPaymentReceived, Channel: model.ChannelEmail, Recipient: "a@example.com", Body: "a"},
+		{Event: model.NotifyPaymentReceived, Channel: model.ChannelSMS, Recipient: "+62811", Body: "b"},
+		{Event: model.NotifyPaymentReceived, Channel: model.ChannelWhatsApp, Recipient: "+62812", Body: "c"},
+	} {
+		require.NoError(t, repo.Enqueue(ctx, &n))
+	}
+	now := time.Now()
+	svc.Now = func() time.Time { return now }
+
+	sent, err := svc.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, email.sent, 1)
+	assert.Equal(t, "a@example.com", email.sent[0].Recipient)
+
+	retrying, err := repo.GetAll(ctx, model.NotificationPending)
+	require.NoError(t, err)
+	require.Len(t, retrying, 2)
+	assert.Equal(t, 1, retrying[1].Attempts)
+	assert.Equal(t, "gateway down", retrying[1].LastError)
+	assert.Equal(t, now.Add(time.Minute), retrying[1].NextAttemptAt)
+	assert.Contains(t, retrying[0].LastError, "no sender configured for whatsapp")
+
+	sent, err = svc.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	now = now.Add(time.Minute)
+	sent, err = svc.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	failed, err := repo.GetAll(ctx, model.NotificationFailed)
+	require.NoError(t, err)
+	require.Len(t, failed, 2)
+	assert.Equal(t, 2, failed[0].Attempts)
+}
+
+func mustOutbox(t *testing.T, svc *service.NotificationService, ctx context.Context) []model.Notification {
+	outbox, err := svc.GetOutbox(ctx, "")
+	require.NoError(t, err)
+	return outbox
+}
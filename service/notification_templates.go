@@ -0,0 +1,107 @@
+package service
+
+import (
+	"auth-service/model"
+	"strings"
+	"text/template"
+)
+
+// notificationData is what the message templates can refer to. Name is the
+// recipient's name, Customer and Driver the two parties of the booking.
+type notificationData struct {
+	Name          string
+	BookingID     string
+	When          string
+	Pickup        string
+	Customer      string
+	CustomerPhone string
+	Driver        string
+	DriverPhone   string
+	Plate         string
+	Amount        string
+	Method        string
+	Link          string
+	Rating        string
+	Threshold     string
+}
+
+type notificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func newNotificationTemplate(subject, body string) notificationTemplate {
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+var notificationTemplates = map[string]map[string]notificationTemplate{
+	model.NotifyBookingConfirmed: {
+		model.LocaleID: newNotificationTemplate(
+			"Booking {{.BookingID}} dikonfirmasi",
+			"Halo {{.Name}}, booking {{.BookingID}} untuk {{.When}} telah dikonfirmasi.{{if .Pickup}} Penjemputan di {{.Pickup}}.{{end}} Terima kasih."),
+		model.LocaleEN: newNotificationTemplate(
+			"Booking {{.BookingID}} confirmed",
+			"Hi {{.Name}}, your booking {{.BookingID}} for {{.When}} has been confirmed.{{if .Pickup}} Pickup at {{.Pickup}}.{{end}} Thank you."),
+	},
+	model.NotifyDriverAssigned: {
+		model.LocaleID: newNotificationTemplate(
+			"Driver untuk booking {{.BookingID}}",
+			"Halo {{.Name}}, {{.Driver}}{{if .Plate}} ({{.Plate}}){{end}} akan menjemput Anda untuk booking {{.BookingID}} pada {{.When}}.{{if .DriverPhone}} Hubungi driver di {{.DriverPhone}}.{{end}}"),
+		model.LocaleEN: newNotificationTemplate(
+			"Your driver for booking {{.BookingID}}",
+			"Hi {{.Name}}, {{.Driver}}{{if .Plate}} ({{.Plate}}){{end}} will pick you up for booking {{.BookingID}} on {{.When}}.{{if .DriverPhone}} You can reach your driver at {{.DriverPhone}}.{{end}}"),
+	},
+	model.NotifyTripAssigned: {
+		model.LocaleID: newNotificationTemplate(
+			"Tugas baru: booking {{.BookingID}}",
+			"Halo {{.Name}}, Anda ditugaskan untuk booking {{.BookingID}} atas nama {{.Customer}} pada {{.When}}.{{if .Pickup}} Jemput di {{.Pickup}}.{{end}}{{if .CustomerPhone}} Telepon pelanggan: {{.CustomerPhone}}.{{end}}"),
+		model.LocaleEN: newNotificationTemplate(
+			"New trip: booking {{.BookingID}}",
+			"Hi {{.Name}}, you have been assigned booking {{.BookingID}} for {{.Customer}} on {{.When}}.{{if .Pickup}} Pick up at {{.Pickup}}.{{end}}{{if .CustomerPhone}} Customer phone: {{.CustomerPhone}}.{{end}}"),
+	},
+	model.NotifyPaymentReceived: {
+		model.LocaleID: newNotificationTemplate(
+			"Pembayaran diterima",
+			"Halo {{.Name}}, pembayaran sebesar Rp{{.Amount}}{{if .Method}} via {{.Method}}{{end}} telah kami terima. Terima kasih."),
+		model.LocaleEN: newNotificationTemplate(
+			"Payment received",
+			"Hi {{.Name}}, we have received your payment of Rp{{.Amount}}{{if .Method}} by {{.Method}}{{end}}. Thank you."),
+	},
+	model.NotifyFeedbackRequest: {
+		model.LocaleID: newNotificationTemplate(
+			"Bagaimana perjalanan Anda?",
+			"Halo {{.Name}}, terima kasih telah bepergian bersama kami{{if .Driver}} dan {{.Driver}}{{end}}. Beri penilaian untuk booking {{.BookingID}} di {{.Link}}"),
+		model.LocaleEN: newNotificationTemplate(
+			"How was your trip?",
+			"Hi {{.Name}}, thank you for travelling with us{{if .Driver}} and {{.Driver}}{{end}}. Rate booking {{.BookingID}} at {{.Link}}"),
+	},
+	model.NotifyDriverRatingLow: {
+		model.LocaleID: newNotificationTemplate(
+			"Rating driver {{.Driver}} turun",
+			"Rata-rata rating terbaru {{.Driver}} turun menjadi {{.Rating}}, di bawah batas {{.Threshold}}. Mohon ditindaklanjuti."),
+		model.LocaleEN: newNotificationTemplate(
+			"Driver {{.Driver}} rating has dropped",
+			"The recent average rating of {{.Driver}} has dropped to {{.Rating}}, below the threshold of {{.Threshold}}. Please follow up."),
+	},
+}
+
+// renderNotification fills in the template for event, falling back to
+// Indonesian when there is no translation for locale.
+func renderNotification(event, locale string, data notificationData) (subject, body string, err error) {
+	tmpl, ok := notificationTemplates[event][locale]
+	if !ok {
+		tmpl = notificationTemplates[event][model.LocaleID]
+	}
+
+	var sb, bb strings.Builder
+	if err := tmpl.subject.Execute(&sb, data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.body.Execute(&bb, data); err != nil {
+		return "", "", err
+	}
+	return sb.String(), bb.String(), nil
+}
@@ -0,0 +1,238 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentProviders(t *testing.T) {
+	server := httptest.NewServer(service.NewMockGateway("server-key", "", ""))
+	defer server.Close()
+
+	ctx := context.Background()
+	gateway := &service.Gateway{URL: server.URL, ServerKey: "server-key"}
+	expires := time.Now().Add(30 * time.Minute)
+
+	va, err := (&service.VirtualAccountProvider{Gateway: gateway, Bank: "bca"}).CreateCharge(ctx, model.ChargeRequest{Ref: "PAY-VA", Amount: 150000, ExpiresAt: &expires})
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentPending, va.Status)
+	assert.Equal(t, 150000.0, va.Amount)
+	assert.Regexp(t, `^8808\d{12}$`, va.PayCode)
+	require.NotNil(t, va.ExpiresAt)
+	assert.WithinDuration(t, expires, *va.ExpiresAt, time.Minute)
+
+	qris, err := (&service.QRISProvider{Gateway: gateway}).CreateCharge(ctx, model.ChargeRequest{Ref: "PAY-QR", Amount: 50000})
+	require.NoError(t, err)
+	assert.Contains(t, qris.PayCode, "PAY-QR")
+
+	wallet, err := (&service.EWalletProvider{Gateway: gateway, Wallet: "gopay"}).CreateCharge(ctx, model.ChargeRequest{Ref: "PAY-EW", Amount: 50000})
+	require.NoError(t, err)
+	assert.Equal(t, "mockpay://gopay/pay?order_id=PAY-EW", wallet.PayCode)
+
+	_, err = (&service.QRISProvider{Gateway: gateway}).CreateCharge(ctx, model.ChargeRequest{Ref: "PAY-QR", Amount: 50000})
+	assert.ErrorContains(t, err, "409")
+
+	_, err = gateway.Capture(ctx, "PAY-VA", 150000)
+	assert.ErrorContains(t, err, "only authorized transactions can be captured")
+	_, err = http.Post(server.URL+"/PAY-VA/authorize", "application/json", nil)
+	require.NoError(t, err)
+	captured, err := gateway.Capture(ctx, "PAY-VA", 150000)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentPaid, captured.Status)
+
+	refund, err := gateway.Refund(ctx, "PAY-VA", 50000, "customer request")
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentPartiallyRefunded, refund.Status)
+	assert.Equal(t, 50000.0, refund.RefundedAmount)
+	_, err = gateway.Refund(ctx, "PAY-VA", 150000, "")
+	assert.ErrorContains(t, err, "within the captured amount")
+	refund, err = gateway.Refund(ctx, "PAY-VA", 100000, "")
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentStatusRefunded, refund.Status)
+
+	status, err := gateway.Status(ctx, "PAY-VA")
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentStatusRefunded, status.Status)
+	assert.Equal(t, 150000.0, status.RefundedAmount)
+
+	_, err = (&service.Gateway{URL: server.URL, ServerKey: "wrong"}).Status(ctx, "PAY-VA")
+	assert.ErrorContains(t, err, "401")
+	_, err = gateway.Status(ctx, "PAY-MISSING")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestPaymentService_GatewayPayments(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	bookings := repository.NewMemoryBookingRepository(store)
+	svc := &service.PaymentService{Repo: repository.NewMemoryPaymentRepository(store), Bookings: bookings, CallbackSecret: "callback-secret"}
+
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := svc.HandleCallback(r.Context(), body, r.Header.Get(service.CallbackTimestampHeader), r.Header.Get(service.CallbackSignatureHeader))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer app.Close()
+	gatewayServer := httptest.NewServer(service.NewMockGateway("server-key", app.URL, "callback-secret"))
+	defer gatewayServer.Close()
+
+	gateway := &service.Gateway{URL: gatewayServer.URL, ServerKey: "server-key"}
+	svc.Providers = map[string]service.PaymentProvider{model.PaymentMethodQRIS: &service.QRISProvider{Gateway: gateway}}
+
+	amount := 100000.0
+	require.NoError(t, bookings.Create(ctx, &model.Booking{ID: "BK-gw", Customer: "Sari", Amount: &amount, Payment: "unpaid"}))
+	id := "BK-gw"
+	p := &model.Payment{BookingID: &id, Amount: 100000, Method: model.PaymentMethodQRIS}
+	var err error
+	p.PaymentID, err = svc.CreatePayment(ctx, p)
+	require.NoError(t, err)
+	require.NotNil(t, p.ProviderRef)
+	assert.Equal(t, model.PaymentPending, p.Status)
+	assert.Contains(t, p.PayCode, *p.ProviderRef)
+
+	_, err = svc.RefreshPayment(ctx, p.PaymentID)
+	require.NoError(t, err)
+
+	resp, err := http.Post(gatewayServer.URL+"/"+*p.ProviderRef+"/pay", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	paid, err := svc.GetPaymentByID(ctx, p.PaymentID)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentPaid, paid.Status)
+	b, err := bookings.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, model.BookingPaid, b.Payment)
+
+	paid.Amount = 1
+	require.NoError(t, svc.UpdatePayment(ctx, paid))
+	assert.Equal(t, 100000.0, paid.Amount)
+
+	approver := int64(3)
+	refund, err := svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Amount: 25000, Reason: "double charge"}, &approver)
+	require.NoError(t, err)
+	assert.Equal(t, model.RefundSucceeded, refund.Status)
+	assert.True(t, refund.Gateway)
+	partial, err := svc.GetPaymentByID(ctx, p.PaymentID)
+	require.NoError(t, err)
+	refreshed, err := svc.RefreshPayment(ctx, p.PaymentID)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentPartiallyRefunded, refreshed.Status)
+	assert.Equal(t, 25000.0, refreshed.RefundedAmount)
+	assert.Equal(t, partial.Version, refreshed.Version)
+
+	callback := func(event, status string) []byte {
+		return []byte(`{"event_id":"` + event + `","order_id":"` + *p.ProviderRef + `","gross_amount":"100000.00","refund_amount":"100000.00","transaction_status":"` + status + `"}`)
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	body := callback("evt-refund", "refund")
+	require.NoError(t, svc.HandleCallback(context.Background(), body, now, service.SignCallback("callback-secret", now, body)))
+	refunded, err := svc.GetPaymentByID(ctx, p.PaymentID)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentStatusRefunded, refunded.Status)
+	assert.Equal(t, 100000.0, refunded.RefundedAmount)
+	refunds, err := svc.GetRefunds(ctx, p.PaymentID)
+	require.NoError(t, err)
+	require.Len(t, refunds, 2)
+	assert.Equal(t, 75000.0, refunds[1].Amount)
+	assert.Equal(t, "refunded at the payment gateway", refunds[1].Reason)
+	assert.Nil(t, refunds[1].ApprovedBy)
+
+	assert.ErrorIs(t, svc.HandleCallback(context.Background(), body, now, service.SignCallback("callback-secret", now, body)), service.ErrCallbackReplayed)
+	assert.ErrorIs(t, svc.HandleCallback(context.Background(), body, now, service.SignCallback("other-secret", now, body)), service.ErrCallbackSignature)
+
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	body = callback("evt-stale", "refund")
+	assert.ErrorIs(t, svc.HandleCallback(context.Background(), body, stale, service.SignCallback("callback-secret", stale, body)), service.ErrCallbackExpired)
+
+	// A settlement arriving after the refund is out of order and changes nothing.
+	body = callback("evt-late", "settlement")
+	require.NoError(t, svc.HandleCallback(context.Background(), body, now, service.SignCallback("callback-secret", now, body)))
+	late, err := svc.GetPaymentByID(ctx, p.PaymentID)
+	require.NoError(t, err)
+	assert.Equal(t, refunded.Version, late.Version)
+
+	body = []byte(`{"event_id":"evt-unknown","order_id":"PAY-UNKNOWN","transaction_status":"settlement"}`)
+	assert.ErrorIs(t, svc.HandleCallback(context.Background(), body, now, service.SignCallback("callback-secret", now, body)), service.ErrNotFound)
+	body = []byte(`{"event_id":"evt-bad","order_id":"PAY-X","transaction_status":"lunas"}`)
+	assert.ErrorIs(t, svc.HandleCallback(context.Background(), body, now, service.SignCallback("callback-secret", now, body)), service.ErrInvalidCallback)
+
+	cash := &model.Payment{BookingID: &id, Amount: 1000, Method: "cash"}
+	cash.PaymentID, err = svc.CreatePayment(ctx, cash)
+	require.NoError(t, err)
+	assert.Nil(t, cash.ProviderRef)
+	_, err = svc.RefreshPayment(ctx, cash.PaymentID)
+	assert.ErrorIs(t, err, service.ErrNoPaymentProvider)
+}
+
+func TestPaymentService_GatewayFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	svc := &service.PaymentService{
+		Repo:      repository.NewMemoryPaymentRepository(store),
+		Providers: map[string]service.PaymentProvider{model.PaymentMethodVirtualAccount: &service.VirtualAccountProvider{Gateway: &service.Gateway{URL: server.URL}, Bank: "bni"}},
+	}
+
+	_, err := svc.CreatePayment(ctx, &model.Payment{Amount: 1000, Method: model.PaymentMethodVirtualAccount})
+	assert.ErrorIs(t, err, service.ErrPaymentGateway)
+	assert.ErrorContains(t, err, "maintenance")
+
+	payments, err := svc.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, payments)
+}
+
+func TestPaymentService_GatewayRefundRefused(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	gatewayServer := httptest.NewServer(service.NewMockGateway("server-key", "", ""))
+	defer gatewayServer.Close()
+	svc := &service.PaymentService{
+		Repo:      repository.NewMemoryPaymentRepository(store),
+		Providers: map[string]service.PaymentProvider{model.PaymentMethodVirtualAccount: &service.VirtualAccountProvider{Gateway: &service.Gateway{URL: gatewayServer.URL, ServerKey: "server-key"}, Bank: "bca"}},
+	}
+
+	p := &model.Payment{Amount: 50000, Method: model.PaymentMethodVirtualAccount}
+	var err error
+	p.PaymentID, err = svc.CreatePayment(ctx, p)
+	require.NoError(t, err)
+
+	// The gateway refuses to refund a charge that was never paid, so the
+	// refund fails and the payment keeps its money.
+	p.Status = model.PaymentPaid
+	require.NoError(t, svc.UpdatePayment(ctx, p))
+	_, err = svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Amount: 20000, Reason: "customer request"}, nil)
+	assert.ErrorIs(t, err, service.ErrPaymentGateway)
+
+	kept, err := svc.GetPaymentByID(ctx, p.PaymentID)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentPaid, kept.Status)
+	assert.Equal(t, 0.0, kept.RefundedAmount)
+	refunds, err := svc.GetRefunds(ctx, p.PaymentID)
+	require.NoError(t, err)
+	require.Len(t, refunds, 1)
+	assert.Equal(t, model.RefundFailed, refunds[0].Status)
+
+	stats, err := svc.GetPaymentStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50000), stats.TotalPayment)
+	assert.Equal(t, int64(0), stats.TotalRefunded)
+}
@@ -3,20 +3,88 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"auth-service/utils"
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
 )
 
+var (
+	ErrUnknownBooking        = errors.New("payment must reference an existing booking")
+	ErrInvalidPaymentStatus  = errors.New("invalid payment status")
+	ErrPaymentTransition     = errors.New("payment status transition not allowed")
+	ErrInvalidPaymentAmount  = errors.New("payment amount must be more than zero")
+	ErrCapturedAmount        = errors.New("the amount of a captured payment cannot change")
+	ErrInvalidRefund         = errors.New("refund amount must be more than zero")
+	ErrRefundReason          = errors.New("refund reason is required")
+	ErrPaymentNotCaptured    = errors.New("only paid or partially refunded payments can be refunded")
+	ErrRefundRequired        = errors.New("payments are refunded by recording a refund")
+	ErrRefundExceedsCaptured = repository.ErrRefundExceedsCaptured
+	ErrPaymentGateway        = errors.New("payment gateway request failed")
+	ErrNoPaymentProvider     = errors.New("payment was not made through a payment gateway")
+	ErrCallbackSignature     = errors.New("payment callback signature is invalid")
+	ErrCallbackExpired       = errors.New("payment callback timestamp is outside the allowed window")
+	ErrCallbackReplayed      = errors.New("payment callback has already been processed")
+	ErrInvalidCallback       = errors.New("payment callback is malformed")
+)
+
+// defaultPaymentExpiry is how long a payment may stay pending or authorised
+// before it expires, when it is created without an expiry.
+const defaultPaymentExpiry = 24 * time.Hour
+
+// callbackTolerance is how far the timestamp of a gateway callback may be
+// from now. Callbacks are remembered, so one replayed within it is refused.
+const callbackTolerance = 5 * time.Minute
+
+// paymentTransitions lists the statuses a payment can move to from each
+// status. Payments are created pending, authorised, paid or failed, and only
+// refunds move them on from paid.
+var paymentTransitions = map[string]map[string]bool{
+	model.PaymentPending: {
+		model.PaymentAuthorised: true,
+		model.PaymentPaid:       true,
+		model.PaymentFailed:     true,
+		model.PaymentExpired:    true,
+	},
+	model.PaymentAuthorised: {
+		model.PaymentPaid:    true,
+		model.PaymentFailed:  true,
+		model.PaymentExpired: true,
+	},
+}
+
 type PaymentServiceInterface interface {
-	GetPayments(page, pageSize int) ([]model.Payment, error)
+	GetPayments(ctx context.Context, page, pageSize int) ([]model.Payment, error)
 	GetPaymentByID(ctx context.Context, id int) (*model.Payment, error)
 	CreatePayment(ctx context.Context, p *model.Payment) (int, error)
 	UpdatePayment(ctx context.Context, p *model.Payment) error
-	DeletePayment(ctx context.Context, id int) error
+	DeletePayment(ctx context.Context, id int, version int) error
 	GetPaymentStats(ctx context.Context) (*model.PaymentStats, error)
+	GetBookingPayments(ctx context.Context, bookingID string) (*model.BookingPayments, error)
+	RefreshPayment(ctx context.Context, id int) (*model.Payment, error)
+	HandleCallback(ctx context.Context, body []byte, timestamp, signature string) error
+	RefundPayment(ctx context.Context, paymentID int, req model.RefundRequest, approvedBy *int64) (*model.PaymentRefund, error)
+	GetRefunds(ctx context.Context, paymentID int) ([]model.PaymentRefund, error)
 }
 
 type PaymentService struct {
-	Repo repository.PaymentRepositoryInterface
+	Repo          repository.PaymentRepositoryInterface
+	Customers     repository.CustomerRepositoryInterface
+	Notifications repository.NotificationRepositoryInterface
+	Bookings      repository.BookingRepositoryInterface
+	Cancellations repository.CancellationRepositoryInterface
+	// Providers collect the payments made with the method they are keyed by,
+	// and CallbackSecret verifies the callbacks their gateway sends.
+	Providers      map[string]PaymentProvider
+	CallbackSecret string
 }
 
 func NewPaymentService(repo repository.PaymentRepositoryInterface) *PaymentService {
@@ -25,8 +93,8 @@ func NewPaymentService(repo repository.PaymentRepositoryInterface) *PaymentServi
 	}
 }
 
-func (s *PaymentService) GetPayments(page, pageSize int) ([]model.Payment, error) {
-	return s.Repo.GetPayments(page, pageSize)
+func (s *PaymentService) GetPayments(ctx context.Context, page, pageSize int) ([]model.Payment, error) {
+	return s.Repo.GetPayments(ctx, page, pageSize)
 }
 
 func (s *PaymentService) GetPaymentStats(ctx context.Context) (*model.PaymentStats, error) {
@@ -42,13 +110,519 @@ func (s *PaymentService) GetPaymentByID(ctx context.Context, id int) (*model.Pay
 }
 
 func (s *PaymentService) CreatePayment(ctx context.Context, payment *model.Payment) (int, error) {
-	return s.Repo.Create(ctx, payment)
+	if payment.Amount <= 0 {
+		return 0, ErrInvalidPaymentAmount
+	}
+	status := model.PaymentPending
+	if payment.Status != "" {
+		var ok bool
+		if status, ok = model.ParsePaymentStatus(payment.Status); !ok {
+			return 0, ErrInvalidPaymentStatus
+		}
+	}
+	switch status {
+	case model.PaymentPending, model.PaymentAuthorised:
+		if payment.ExpiresAt == nil {
+			expires := time.Now().Add(defaultPaymentExpiry)
+			payment.ExpiresAt = &expires
+		}
+	case model.PaymentPaid, model.PaymentFailed:
+	default:
+		return 0, ErrInvalidPaymentStatus
+	}
+	payment.Status = status
+	payment.RefundedAmount = 0
+
+	if err := s.applyBooking(ctx, payment); err != nil {
+		return 0, err
+	}
+	if err := s.applyCustomer(ctx, payment); err != nil {
+		return 0, err
+	}
+	if provider := s.Providers[payment.Method]; provider != nil && status == model.PaymentPending {
+		if err := s.charge(ctx, provider, payment); err != nil {
+			return 0, err
+		}
+	}
+	id, err := s.Repo.Create(ctx, payment)
+	if err != nil {
+		return 0, err
+	}
+	payment.PaymentID = id
+	s.notifyPaid(ctx, payment)
+	return id, nil
 }
 
+// UpdatePayment moves a payment along its lifecycle. What has been refunded
+// of it only changes through RefundPayment, and once captured its amount is
+// fixed. Payments made through a gateway are captured there, and keep their
+// method and amount.
 func (s *PaymentService) UpdatePayment(ctx context.Context, p *model.Payment) error {
-	return s.Repo.Update(ctx, p)
+	old, err := s.Repo.GetByID(ctx, p.PaymentID)
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		return ErrNotFound
+	}
+
+	p.ProviderRef = old.ProviderRef
+	p.PayCode = old.PayCode
+	provider := s.provider(old)
+	if provider != nil {
+		p.Method = old.Method
+		p.Amount = old.Amount
+	}
+	if err := transitionPayment(old, p); err != nil {
+		return err
+	}
+	if p.ExpiresAt == nil {
+		p.ExpiresAt = old.ExpiresAt
+	}
+
+	if err := s.applyBooking(ctx, p); err != nil {
+		return err
+	}
+	if err := s.applyCustomer(ctx, p); err != nil {
+		return err
+	}
+	if provider != nil {
+		if err := moveMoney(ctx, provider, old, p); err != nil {
+			return err
+		}
+	}
+	return s.save(ctx, old, p)
+}
+
+// transitionPayment checks that p may follow old. Refunds are recorded on
+// their own, so p keeps what old had refunded.
+func transitionPayment(old, p *model.Payment) error {
+	status := old.Status
+	if p.Status != "" {
+		var ok bool
+		if status, ok = model.ParsePaymentStatus(p.Status); !ok {
+			return ErrInvalidPaymentStatus
+		}
+	}
+	if status != old.Status {
+		if status == model.PaymentPartiallyRefunded || status == model.PaymentStatusRefunded {
+			return ErrRefundRequired
+		}
+		if !paymentTransitions[old.Status][status] {
+			return ErrPaymentTransition
+		}
+	}
+	if p.Amount <= 0 {
+		return ErrInvalidPaymentAmount
+	}
+	if old.Captured() && roundMoney(p.Amount) != roundMoney(old.Amount) {
+		return ErrCapturedAmount
+	}
+	p.Status = status
+	p.RefundedAmount = old.RefundedAmount
+	return nil
+}
+
+func (s *PaymentService) save(ctx context.Context, old, p *model.Payment) error {
+	if err := s.Repo.Update(ctx, p); err != nil {
+		return err
+	}
+	if old.Status != model.PaymentPaid {
+		s.notifyPaid(ctx, p)
+	}
+	return nil
+}
+
+// GetBookingPayments returns the payments of a booking with what is still
+// owed on it. A booking without payments keeps the payment status it was
+// given, and one marked paid counts as paid in full.
+func (s *PaymentService) GetBookingPayments(ctx context.Context, bookingID string) (*model.BookingPayments, error) {
+	b, err := s.Bookings.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrNotFound
+	}
+
+	payments, err := s.Repo.GetByBooking(ctx, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	due, err := bookingDue(ctx, s.Cancellations, b)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := model.SummarisePayments(due, payments)
+	summary.BookingID = b.ID
+	if len(payments) == 0 {
+		summary.Payments = []model.Payment{}
+		summary.PaymentStatus = b.Payment
+		if strings.EqualFold(strings.TrimSpace(b.Payment), model.PaymentPaid) {
+			summary.Paid = due
+			summary.Outstanding = 0
+		}
+	}
+	return &summary, nil
+}
+
+// bookingDue is what a booking owes: its cancellation fee once cancelled,
+// otherwise its fare.
+func bookingDue(ctx context.Context, cancellations repository.CancellationRepositoryInterface, b *model.Booking) (float64, error) {
+	if cancellations != nil {
+		c, err := cancellations.GetByBooking(ctx, b.ID)
+		if err != nil {
+			return 0, err
+		}
+		if c != nil {
+			return c.Fee, nil
+		}
+	}
+	if b.Amount != nil {
+		return *b.Amount, nil
+	}
+	return 0, nil
+}
+
+// notifyPaid sends the customer a receipt once a payment is paid. The payment
+// is saved by then, so a receipt that cannot be queued is logged rather than
+// failing the request.
+func (s *PaymentService) notifyPaid(ctx context.Context, p *model.Payment) {
+	if s.Notifications == nil {
+		return
+	}
+	if err := NewNotificationService(s.Notifications, s.Customers, nil).PaymentReceived(ctx, p); err != nil {
+		log.Printf("Gagal mengirim kuitansi pembayaran %d: %v\n", p.PaymentID, err)
+	}
+}
+
+// applyBooking checks that the payment is for an existing booking, and takes
+// the customer and driver from it when the payment leaves them out.
+func (s *PaymentService) applyBooking(ctx context.Context, p *model.Payment) error {
+	if s.Bookings == nil {
+		return nil
+	}
+	if p.BookingID == nil {
+		return ErrUnknownBooking
+	}
+
+	b, err := s.Bookings.GetByID(ctx, *p.BookingID)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return ErrUnknownBooking
+	}
+
+	p.BookingID = &b.ID
+	if p.CustomerID == nil {
+		p.CustomerID = b.CustomerID
+	}
+	if p.Customer == "" {
+		p.Customer = b.Customer
+	}
+	if p.Driver == "" {
+		p.Driver = b.Driver
+	}
+	return nil
+}
+
+func (s *PaymentService) applyCustomer(ctx context.Context, p *model.Payment) error {
+	c, err := lookupCustomer(ctx, s.Customers, p.CustomerID)
+	if err != nil || c == nil {
+		return err
+	}
+
+	p.Customer = c.Name
+	return nil
+}
+
+// charge opens a charge for payment at the gateway of provider under a new
+// order id, and takes what the customer pays with from it.
+func (s *PaymentService) charge(ctx context.Context, provider PaymentProvider, payment *model.Payment) error {
+	ref, err := newProviderRef()
+	if err != nil {
+		return err
+	}
+
+	c, err := provider.CreateCharge(ctx, model.ChargeRequest{
+		Ref:       ref,
+		Amount:    payment.Amount,
+		Customer:  payment.Customer,
+		ExpiresAt: payment.ExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPaymentGateway, err)
+	}
+
+	payment.ProviderRef = &ref
+	payment.PayCode = c.PayCode
+	if c.ExpiresAt != nil {
+		payment.ExpiresAt = c.ExpiresAt
+	}
+	if c.Status == model.PaymentAuthorised || c.Status == model.PaymentPaid || c.Status == model.PaymentFailed {
+		payment.Status = c.Status
+	}
+	return nil
+}
+
+// moveMoney asks the gateway to capture an authorised payment that is being
+// marked paid.
+func moveMoney(ctx context.Context, provider PaymentProvider, old, p *model.Payment) error {
+	if old.Status != model.PaymentAuthorised || p.Status != model.PaymentPaid {
+		return nil
+	}
+	if _, err := provider.Capture(ctx, *old.ProviderRef, p.Amount); err != nil {
+		return fmt.Errorf("%w: %v", ErrPaymentGateway, err)
+	}
+	return nil
+}
+
+// RefundPayment gives back part or all of a captured payment, never more than
+// is left of it. Without an amount, all that is left is refunded. A payment
+// made through a gateway is refunded there; when the gateway refuses, the
+// refund is recorded as failed and the payment keeps its money.
+func (s *PaymentService) RefundPayment(ctx context.Context, paymentID int, req model.RefundRequest, approvedBy *int64) (*model.PaymentRefund, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrRefundReason
+	}
+	if req.Amount < 0 {
+		return nil, ErrInvalidRefund
+	}
+
+	p, err := s.Repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrNotFound
+	}
+	if p.Status != model.PaymentPaid && p.Status != model.PaymentPartiallyRefunded {
+		return nil, ErrPaymentNotCaptured
+	}
+
+	remaining := roundMoney(p.Amount - p.RefundedAmount)
+	amount := roundMoney(req.Amount)
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return nil, ErrRefundExceedsCaptured
+	}
+
+	rf := &model.PaymentRefund{
+		PaymentID:  p.PaymentID,
+		Amount:     amount,
+		Reason:     reason,
+		Status:     model.RefundSucceeded,
+		ApprovedBy: approvedBy,
+	}
+	provider := s.provider(p)
+	if provider != nil {
+		rf.Status = model.RefundPending
+		rf.Gateway = true
+	}
+	if err := s.Repo.AddRefund(ctx, rf); err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return rf, nil
+	}
+	if err := s.settleAtGateway(ctx, p, rf); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// settleAtGateway carries out the pending refund rf of p at its gateway and
+// records whether it succeeded. The refund holds its amount while the gateway
+// carries it out, so a second refund cannot take the same money.
+func (s *PaymentService) settleAtGateway(ctx context.Context, p *model.Payment, rf *model.PaymentRefund) error {
+	_, refundErr := s.provider(p).Refund(ctx, *p.ProviderRef, rf.Amount, rf.Reason)
+	rf.Status = model.RefundSucceeded
+	if refundErr != nil {
+		rf.Status = model.RefundFailed
+	}
+	if err := s.Repo.SettleRefund(ctx, rf.ID, rf.Status); err != nil {
+		return err
+	}
+	if refundErr != nil {
+		return fmt.Errorf("%w: %v", ErrPaymentGateway, refundErr)
+	}
+	return nil
+}
+
+func (s *PaymentService) GetRefunds(ctx context.Context, paymentID int) ([]model.PaymentRefund, error) {
+	p, err := s.Repo.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrNotFound
+	}
+	return s.Repo.GetRefunds(ctx, paymentID)
+}
+
+// RefreshPayment asks the gateway how a payment stands and applies it.
+func (s *PaymentService) RefreshPayment(ctx context.Context, id int) (*model.Payment, error) {
+	p, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrNotFound
+	}
+	provider := s.provider(p)
+	if provider == nil {
+		return nil, ErrNoPaymentProvider
+	}
+
+	c, err := provider.Status(ctx, *p.ProviderRef)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPaymentGateway, err)
+	}
+	return s.applyCharge(ctx, p, c)
+}
+
+// HandleCallback applies a signed callback from the payment gateway. Each
+// callback is applied once; a replay returns ErrCallbackReplayed.
+func (s *PaymentService) HandleCallback(ctx context.Context, body []byte, timestamp, signature string) error {
+	if err := s.verifyCallback(body, timestamp, signature, time.Now()); err != nil {
+		return err
+	}
+
+	var n gatewayNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+	if n.EventID == "" || n.OrderID == "" {
+		return fmt.Errorf("%w: event_id and order_id are required", ErrInvalidCallback)
+	}
+	c, err := n.charge()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+
+	p, err := s.Repo.FindByProviderRef(ctx, n.OrderID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrNotFound
+	}
+
+	err = s.Repo.RecordCallback(ctx, &model.PaymentCallback{EventID: n.EventID, ProviderRef: n.OrderID, ReceivedAt: time.Now()})
+	if errors.Is(err, repository.ErrDuplicateKey) {
+		return ErrCallbackReplayed
+	}
+	if err != nil {
+		return err
+	}
+
+	// A callback that could not be applied is forgotten, so the gateway's
+	// retry of it is not refused as a replay.
+	if _, err := s.applyCharge(utils.WithTenant(ctx, p.TenantID), p, c); err != nil {
+		if forgetErr := s.Repo.ForgetCallback(ctx, n.EventID); forgetErr != nil {
+			return forgetErr
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PaymentService) verifyCallback(body []byte, timestamp, signature string, now time.Time) error {
+	if s.CallbackSecret == "" {
+		return ErrCallbackSignature
+	}
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrCallbackSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(SignCallback(s.CallbackSecret, timestamp, body))) {
+		return ErrCallbackSignature
+	}
+	if d := now.Sub(time.Unix(sent, 0)); d > callbackTolerance || d < -callbackTolerance {
+		return ErrCallbackExpired
+	}
+	return nil
+}
+
+// applyCharge moves p to the status the gateway reports for it. A status the
+// payment has already moved past, as when callbacks arrive out of order, is
+// ignored.
+func (s *PaymentService) applyCharge(ctx context.Context, old *model.Payment, c *model.Charge) (*model.Payment, error) {
+	if c.Status == model.PaymentPartiallyRefunded || c.Status == model.PaymentStatusRefunded {
+		return s.applyGatewayRefund(ctx, old, c)
+	}
+
+	p := *old
+	p.Status = c.Status
+	if p.Status == old.Status {
+		return old, nil
+	}
+
+	err := transitionPayment(old, &p)
+	if errors.Is(err, ErrPaymentTransition) {
+		return old, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, old, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// applyGatewayRefund records what the gateway reports refunded beyond what
+// the payment has refunded, as when a refund is made from the gateway's own
+// dashboard. Refunds made here are already recorded and change nothing.
+func (s *PaymentService) applyGatewayRefund(ctx context.Context, old *model.Payment, c *model.Charge) (*model.Payment, error) {
+	refunded := c.RefundedAmount
+	if c.Status == model.PaymentStatusRefunded && refunded == 0 {
+		refunded = old.Amount
+	}
+	amount := roundMoney(refunded - old.RefundedAmount)
+	if amount <= 0 || (old.Status != model.PaymentPaid && old.Status != model.PaymentPartiallyRefunded) {
+		return old, nil
+	}
+
+	err := s.Repo.AddRefund(ctx, &model.PaymentRefund{
+		PaymentID: old.PaymentID,
+		Amount:    amount,
+		Reason:    "refunded at the payment gateway",
+		Status:    model.RefundSucceeded,
+		Gateway:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Repo.GetByID(ctx, old.PaymentID)
+}
+
+// provider returns the gateway provider p was made through, if any.
+func (s *PaymentService) provider(p *model.Payment) PaymentProvider {
+	if p.ProviderRef == nil {
+		return nil
+	}
+	return s.Providers[p.Method]
+}
+
+func newProviderRef() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "PAY-" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// ExpirePending is run by the expiry job and spans every tenant.
+func (s *PaymentService) ExpirePending(ctx context.Context) (int64, error) {
+	return s.Repo.ExpirePending(ctx, time.Now())
 }
 
-func (s *PaymentService) DeletePayment(ctx context.Context, id int) error {
-	return s.Repo.Delete(ctx, id)
+func (s *PaymentService) DeletePayment(ctx context.Context, id int, version int) error {
+	return s.Repo.Delete(ctx, id, version)
 }
@@ -2,19 +2,25 @@ package service_test
 
 import (
 	"auth-service/model"
+	"auth-service/repository"
 	"auth-service/service"
+	"auth-service/utils"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+var paymentBooking = "BK1"
+
 type MockPaymentRepository struct {
 	mock.Mock
 }
 
-func (m *MockPaymentRepository) GetPayments(page, pageSize int) ([]model.Payment, error) {
+func (m *MockPaymentRepository) GetPayments(ctx context.Context, page, pageSize int) ([]model.Payment, error) {
 	args := m.Called(page, pageSize)
 	return args.Get(0).([]model.Payment), args.Error(1)
 }
@@ -44,22 +50,80 @@ func (m *MockPaymentRepository) Update(ctx context.Context, p *model.Payment) er
 	return args.Error(0)
 }
 
-func (m *MockPaymentRepository) Delete(ctx context.Context, id int) error {
+func (m *MockPaymentRepository) Delete(ctx context.Context, id int, version int) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetDeleted(ctx context.Context) ([]model.Payment, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) Restore(ctx context.Context, id int) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockPaymentRepository) GetByBooking(ctx context.Context, bookingID string) ([]model.Payment, error) {
+	args := m.Called(ctx, bookingID)
+	return args.Get(0).([]model.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPaymentRepository) FindByProviderRef(ctx context.Context, ref string) (*model.Payment, error) {
+	args := m.Called(ctx, ref)
+	return args.Get(0).(*model.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) RecordCallback(ctx context.Context, cb *model.PaymentCallback) error {
+	args := m.Called(ctx, cb)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) ForgetCallback(ctx context.Context, eventID string) error {
+	args := m.Called(ctx, eventID)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) AddRefund(ctx context.Context, rf *model.PaymentRefund) error {
+	args := m.Called(ctx, rf)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) SettleRefund(ctx context.Context, id int, status string) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetRefunds(ctx context.Context, paymentID int) ([]model.PaymentRefund, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PaymentRefund), args.Error(1)
+}
+
+func (m *MockPaymentRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestPaymentService_GetPayments(t *testing.T) {
 	mockRepo := new(MockPaymentRepository)
 	svc := service.NewPaymentService(mockRepo)
 
 	expected := []model.Payment{
-		{PaymentID: 1, BookingID: 1, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid", PaymentDate: "2023-01-01"},
-		{PaymentID: 2, BookingID: 2, Customer: "Alice", Driver: "Bob", Amount: 200.0, Method: "debit", Status: "pending", PaymentDate: "2023-01-02"},
+		{PaymentID: 1, BookingID: &paymentBooking, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid", PaymentDate: "2023-01-01"},
+		{PaymentID: 2, BookingID: &paymentBooking, Customer: "Alice", Driver: "Bob", Amount: 200.0, Method: "debit", Status: "pending", PaymentDate: "2023-01-02"},
 	}
 	mockRepo.On("GetPayments", 1, 10).Return(expected, nil)
 
-	result, err := svc.GetPayments(1, 10)
+	result, err := svc.GetPayments(context.Background(), 1, 10)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
@@ -71,7 +135,7 @@ func TestPaymentService_GetPayments_Error(t *testing.T) {
 
 	mockRepo.On("GetPayments", 1, 10).Return([]model.Payment{}, assert.AnError)
 
-	result, err := svc.GetPayments(1, 10)
+	result, err := svc.GetPayments(context.Background(), 1, 10)
 	assert.Error(t, err)
 	assert.Empty(t, result)
 	mockRepo.AssertExpectations(t)
@@ -82,7 +146,7 @@ func TestPaymentService_GetPaymentByID(t *testing.T) {
 	svc := service.NewPaymentService(mockRepo)
 	ctx := context.Background()
 
-	expected := &model.Payment{PaymentID: 1, BookingID: 1, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid", PaymentDate: "2023-01-01"}
+	expected := &model.Payment{PaymentID: 1, BookingID: &paymentBooking, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid", PaymentDate: "2023-01-01"}
 	mockRepo.On("GetByID", ctx, 1).Return(expected, nil)
 
 	result, err := svc.GetPaymentByID(ctx, 1)
@@ -122,7 +186,7 @@ func TestPaymentService_CreatePayment(t *testing.T) {
 	svc := service.NewPaymentService(mockRepo)
 	ctx := context.Background()
 
-	payment := &model.Payment{BookingID: 1, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid"}
+	payment := &model.Payment{BookingID: &paymentBooking, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid"}
 	mockRepo.On("Create", ctx, payment).Return(1, nil)
 
 	id, err := svc.CreatePayment(ctx, payment)
@@ -136,7 +200,7 @@ func TestPaymentService_CreatePayment_Error(t *testing.T) {
 	svc := service.NewPaymentService(mockRepo)
 	ctx := context.Background()
 
-	payment := &model.Payment{BookingID: 1, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid"}
+	payment := &model.Payment{BookingID: &paymentBooking, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid"}
 	mockRepo.On("Create", ctx, payment).Return(0, assert.AnError)
 
 	id, err := svc.CreatePayment(ctx, payment)
@@ -150,7 +214,8 @@ func TestPaymentService_UpdatePayment(t *testing.T) {
 	svc := service.NewPaymentService(mockRepo)
 	ctx := context.Background()
 
-	payment := &model.Payment{PaymentID: 1, BookingID: 1, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid"}
+	payment := &model.Payment{PaymentID: 1, BookingID: &paymentBooking, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid"}
+	mockRepo.On("GetByID", ctx, 1).Return(&model.Payment{PaymentID: 1, Status: "pending"}, nil)
 	mockRepo.On("Update", ctx, payment).Return(nil)
 
 	err := svc.UpdatePayment(ctx, payment)
@@ -163,7 +228,8 @@ func TestPaymentService_UpdatePayment_Error(t *testing.T) {
 	svc := service.NewPaymentService(mockRepo)
 	ctx := context.Background()
 
-	payment := &model.Payment{PaymentID: 1, BookingID: 1, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid"}
+	payment := &model.Payment{PaymentID: 1, BookingID: &paymentBooking, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid"}
+	mockRepo.On("GetByID", ctx, 1).Return(&model.Payment{PaymentID: 1, Status: "pending"}, nil)
 	mockRepo.On("Update", ctx, payment).Return(assert.AnError)
 
 	err := svc.UpdatePayment(ctx, payment)
@@ -176,9 +242,9 @@ func TestPaymentService_DeletePayment(t *testing.T) {
 	svc := service.NewPaymentService(mockRepo)
 	ctx := context.Background()
 
-	mockRepo.On("Delete", ctx, 1).Return(nil)
+	mockRepo.On("Delete", ctx, 1, 1).Return(nil)
 
-	err := svc.DeletePayment(ctx, 1)
+	err := svc.DeletePayment(ctx, 1, 1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -188,9 +254,9 @@ func TestPaymentService_DeletePayment_Error(t *testing.T) {
 	svc := service.NewPaymentService(mockRepo)
 	ctx := context.Background()
 
-	mockRepo.On("Delete", ctx, 1).Return(assert.AnError)
+	mockRepo.On("Delete", ctx, 1, 1).Return(assert.AnError)
 
-	err := svc.DeletePayment(ctx, 1)
+	err := svc.DeletePayment(ctx, 1, 1)
 	assert.Error(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -228,8 +294,8 @@ func TestPaymentService_GetAllPayments(t *testing.T) {
 	ctx := context.Background()
 
 	expected := []model.Payment{
-		{PaymentID: 1, BookingID: 1, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid", PaymentDate: "2023-01-01"},
-		{PaymentID: 2, BookingID: 2, Customer: "Alice", Driver: "Bob", Amount: 200.0, Method: "debit", Status: "pending", PaymentDate: "2023-01-02"},
+		{PaymentID: 1, BookingID: &paymentBooking, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid", PaymentDate: "2023-01-01"},
+		{PaymentID: 2, BookingID: &paymentBooking, Customer: "Alice", Driver: "Bob", Amount: 200.0, Method: "debit", Status: "pending", PaymentDate: "2023-01-02"},
 	}
 	mockRepo.On("GetAll", ctx).Return(expected, nil)
 
@@ -258,7 +324,7 @@ func TestPaymentService_GetAll(t *testing.T) {
 	ctx := context.Background()
 
 	expected := []model.Payment{
-		{PaymentID: 1, BookingID: 1, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid", PaymentDate: "2023-01-01"},
+		{PaymentID: 1, BookingID: &paymentBooking, Customer: "John Doe", Driver: "Jane Doe", Amount: 100.0, Method: "credit", Status: "paid", PaymentDate: "2023-01-01"},
 	}
 	mockRepo.On("GetAll", ctx).Return(expected, nil)
 
@@ -267,3 +333,130 @@ func TestPaymentService_GetAll(t *testing.T) {
 	assert.Equal(t, expected, result)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestPaymentService_Lifecycle(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	bookings := repository.NewMemoryBookingRepository(store)
+	svc := &service.PaymentService{Repo: repository.NewMemoryPaymentRepository(store), Bookings: bookings}
+
+	amount := 100000.0
+	require.NoError(t, bookings.Create(ctx, &model.Booking{ID: "BK-pay", Customer: "Sari", Driver: "Budi", Amount: &amount, Payment: "unpaid"}))
+
+	missing := "BK-missing"
+	_, err := svc.CreatePayment(ctx, &model.Payment{Amount: 1000})
+	assert.ErrorIs(t, err, service.ErrUnknownBooking)
+	_, err = svc.CreatePayment(ctx, &model.Payment{BookingID: &missing, Amount: 1000})
+	assert.ErrorIs(t, err, service.ErrUnknownBooking)
+	_, err = svc.CreatePayment(ctx, &model.Payment{BookingID: &paymentBooking, Amount: 1000, Status: "lunas"})
+	assert.ErrorIs(t, err, service.ErrInvalidPaymentStatus)
+	_, err = svc.CreatePayment(ctx, &model.Payment{BookingID: &paymentBooking, Amount: 1000, Status: "refunded"})
+	assert.ErrorIs(t, err, service.ErrInvalidPaymentStatus)
+	_, err = svc.CreatePayment(ctx, &model.Payment{BookingID: &paymentBooking, Amount: 0})
+	assert.ErrorIs(t, err, service.ErrInvalidPaymentAmount)
+
+	id := "BK-pay"
+	p := &model.Payment{BookingID: &id, Amount: 100000, Method: "qris"}
+	p.PaymentID, err = svc.CreatePayment(ctx, p)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentPending, p.Status)
+	assert.Equal(t, "Sari", p.Customer)
+	assert.Equal(t, "Budi", p.Driver)
+	require.NotNil(t, p.ExpiresAt)
+
+	summary, err := svc.GetBookingPayments(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, model.BookingPaymentPending, summary.PaymentStatus)
+	assert.Equal(t, 100000.0, summary.Outstanding)
+
+	p.Status = "AUTHORIZED"
+	require.NoError(t, svc.UpdatePayment(ctx, p))
+	assert.Equal(t, model.PaymentAuthorised, p.Status)
+	p.Status = model.PaymentPaid
+	require.NoError(t, svc.UpdatePayment(ctx, p))
+
+	p.Status = model.PaymentPending
+	assert.ErrorIs(t, svc.UpdatePayment(ctx, p), service.ErrPaymentTransition)
+	p.Status = model.PaymentPartiallyRefunded
+	p.RefundedAmount = 30000
+	assert.ErrorIs(t, svc.UpdatePayment(ctx, p), service.ErrRefundRequired)
+	p.Status = model.PaymentPaid
+	p.Amount = 20000
+	assert.ErrorIs(t, svc.UpdatePayment(ctx, p), service.ErrCapturedAmount)
+	p.Amount = 100000
+
+	approver := int64(7)
+	_, err = svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Amount: 100000.01, Reason: "double charge"}, &approver)
+	assert.ErrorIs(t, err, service.ErrRefundExceedsCaptured)
+	_, err = svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Amount: 30000, Reason: " "}, &approver)
+	assert.ErrorIs(t, err, service.ErrRefundReason)
+	_, err = svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Amount: -1, Reason: "double charge"}, &approver)
+	assert.ErrorIs(t, err, service.ErrInvalidRefund)
+	_, err = svc.RefundPayment(ctx, 999, model.RefundRequest{Reason: "double charge"}, &approver)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	refund, err := svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Amount: 30000, Reason: "double charge"}, &approver)
+	require.NoError(t, err)
+	assert.Equal(t, model.RefundSucceeded, refund.Status)
+	assert.False(t, refund.Gateway)
+	assert.Equal(t, &approver, refund.ApprovedBy)
+	summary, err = svc.GetBookingPayments(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, model.BookingPartiallyRefunded, summary.PaymentStatus)
+	assert.Equal(t, 100000.0, summary.Paid)
+	assert.Equal(t, 30000.0, summary.Refunded)
+	assert.Equal(t, 30000.0, summary.Outstanding)
+	require.Len(t, summary.Payments, 1)
+	assert.Equal(t, model.PaymentPartiallyRefunded, summary.Payments[0].Status)
+
+	_, err = svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Amount: 70000.01, Reason: "trip cancelled"}, &approver)
+	assert.ErrorIs(t, err, service.ErrRefundExceedsCaptured)
+	refund, err = svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Reason: "trip cancelled"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 70000.0, refund.Amount)
+	_, err = svc.RefundPayment(ctx, p.PaymentID, model.RefundRequest{Amount: 1, Reason: "trip cancelled"}, nil)
+	assert.ErrorIs(t, err, service.ErrPaymentNotCaptured)
+
+	refunds, err := svc.GetRefunds(ctx, p.PaymentID)
+	require.NoError(t, err)
+	require.Len(t, refunds, 2)
+	assert.Equal(t, []float64{30000, 70000}, []float64{refunds[0].Amount, refunds[1].Amount})
+
+	p, err = svc.GetPaymentByID(ctx, p.PaymentID)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentStatusRefunded, p.Status)
+	assert.Equal(t, 100000.0, p.RefundedAmount)
+	p.Status = model.PaymentPaid
+	assert.ErrorIs(t, svc.UpdatePayment(ctx, p), service.ErrPaymentTransition)
+
+	b, err := bookings.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, model.BookingRefunded, b.Payment)
+
+	_, err = svc.GetBookingPayments(ctx, missing)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestPaymentService_ExpirePending(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	bookings := repository.NewMemoryBookingRepository(store)
+	svc := &service.PaymentService{Repo: repository.NewMemoryPaymentRepository(store), Bookings: bookings}
+
+	require.NoError(t, bookings.Create(ctx, &model.Booking{ID: "BK-expire", Customer: "Sari", Payment: "unpaid"}))
+	id := "BK-expire"
+	past := time.Now().Add(-time.Minute)
+	_, err := svc.CreatePayment(ctx, &model.Payment{BookingID: &id, Amount: 1000, Status: model.PaymentAuthorised, ExpiresAt: &past})
+	require.NoError(t, err)
+	_, err = svc.CreatePayment(ctx, &model.Payment{BookingID: &id, Amount: 1000})
+	require.NoError(t, err)
+
+	n, err := svc.ExpirePending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	summary, err := svc.GetBookingPayments(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, model.PaymentExpired, summary.Payments[0].Status)
+	assert.Equal(t, model.BookingPaymentPending, summary.PaymentStatus)
+}
@@ -5,7 +5,10 @@ import (
 	"auth-service/repository"
 	"context"
 	"fmt"
+	"html/template"
 	"net/url"
+	"path/filepath"
+	"strings"
 
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
@@ -39,14 +42,45 @@ type TemplateRenderer interface {
 	RenderPDFReceipt(data model.PDFTemplateData) (string, error)
 }
 
+type InvoiceRenderer interface {
+	RenderInvoice(data model.InvoiceTemplateData) (string, error)
+}
+
+// HTMLTemplateRenderer renders the receipt and invoice templates found in Dir.
+// Templates are read on every call so they can be edited without a restart.
+type HTMLTemplateRenderer struct {
+	Dir string
+}
+
+func (r *HTMLTemplateRenderer) render(name string, data any) (string, error) {
+	tmpl, err := template.ParseFiles(filepath.Join(r.Dir, name))
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func (r *HTMLTemplateRenderer) RenderPDFReceipt(data model.PDFTemplateData) (string, error) {
+	return r.render("receipt_template.html", data)
+}
+
+func (r *HTMLTemplateRenderer) RenderInvoice(data model.InvoiceTemplateData) (string, error) {
+	return r.render("invoice_template.html", data)
+}
+
 type PDFService struct {
 	TripRepo         repository.PDFRepositoryInterface
 	PDFGenerator     PDFGenerator
 	TemplateRenderer TemplateRenderer
+	InvoiceRenderer  InvoiceRenderer
 }
 
-func (s *PDFService) GenerateTripReceiptPDF(tripID string) ([]byte, string, error) {
-	tripData, err := s.TripRepo.GetTripByID(tripID)
+func (s *PDFService) GenerateTripReceiptPDF(ctx context.Context, tripID string) ([]byte, string, error) {
+	tripData, err := s.TripRepo.GetTripByID(ctx, tripID)
 	if err != nil {
 		return nil, "", fmt.Errorf("gagal mendapatkan data trip: %w", err)
 	}
@@ -69,3 +103,23 @@ func (s *PDFService) GenerateTripReceiptPDF(tripID string) ([]byte, string, erro
 	filename := fmt.Sprintf("receipt_%s.pdf", tripID)
 	return pdfBytes, filename, nil
 }
+
+func (s *PDFService) GenerateInvoicePDF(inv *model.CorporateInvoice, account *model.CorporateAccount) ([]byte, string, error) {
+	html, err := s.InvoiceRenderer.RenderInvoice(model.InvoiceTemplateData{
+		CorporateInvoice: *inv,
+		BillingAddress:   account.BillingAddress,
+		TaxID:            account.TaxID,
+		Outstanding:      inv.Total - inv.AmountPaid,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("gagal render template invoice: %w", err)
+	}
+
+	pdfBytes, err := s.PDFGenerator.GeneratePDF(html)
+	if err != nil {
+		return nil, "", fmt.Errorf("gagal generate PDF: %w", err)
+	}
+
+	filename := fmt.Sprintf("invoice_%s.pdf", inv.Number)
+	return pdfBytes, filename, nil
+}
@@ -1,5 +1,7 @@
 package service
 
+import "context"
+
 type PDFServiceInterface interface {
-	GenerateTripReceiptPDF(tripID string) ([]byte, string, error)
+	GenerateTripReceiptPDF(ctx context.Context, tripID string) ([]byte, string, error)
 }
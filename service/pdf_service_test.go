@@ -3,6 +3,7 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"errors"
 	"testing"
 
@@ -14,7 +15,7 @@ type MockPDFRepository struct{ mock.Mock }
 type MockPDFGenerator struct{ mock.Mock }
 type MockTemplateRenderer struct{ mock.Mock }
 
-func (m *MockPDFRepository) GetTripByID(tripID string) (model.Pdf, error) {
+func (m *MockPDFRepository) GetTripByID(ctx context.Context, tripID string) (model.Pdf, error) {
 	args := m.Called(tripID)
 	return args.Get(0).(model.Pdf), args.Error(1)
 }
@@ -54,7 +55,7 @@ func TestPDFService_GenerateTripReceiptPDF(t *testing.T) {
 	pdfBytesDummy := []byte("dummy pdf content")
 	mockGen.On("GeneratePDF", "<html>dummy</html>").Return(pdfBytesDummy, nil)
 
-	pdfBytes, filename, err := svc.GenerateTripReceiptPDF("trip123")
+	pdfBytes, filename, err := svc.GenerateTripReceiptPDF(context.Background(), "trip123")
 
 	assert.NoError(t, err)
 	assert.Equal(t, pdfBytesDummy, pdfBytes)
@@ -79,7 +80,7 @@ func TestPDFService_GenerateTripReceiptPDF_GetTrip_Error(t *testing.T) {
 	mockRepo.On("GetTripByID", "trip123").
 		Return(model.Pdf{}, assert.AnError)
 
-	pdfBytes, filename, err := svc.GenerateTripReceiptPDF("trip123")
+	pdfBytes, filename, err := svc.GenerateTripReceiptPDF(context.Background(), "trip123")
 
 	assert.Error(t, err)
 	assert.Nil(t, pdfBytes)
@@ -105,7 +106,7 @@ func TestPDFService_GenerateTripReceiptPDF_Template_Error(t *testing.T) {
 	mockTpl.On("RenderPDFReceipt", mock.AnythingOfType("model.PDFTemplateData")).
 		Return("", assert.AnError)
 
-	pdfBytes, filename, err := svc.GenerateTripReceiptPDF("trip123")
+	pdfBytes, filename, err := svc.GenerateTripReceiptPDF(context.Background(), "trip123")
 
 	assert.Error(t, err)
 	assert.Nil(t, pdfBytes)
@@ -134,7 +135,7 @@ func TestPDFService_GenerateTripReceiptPDF_GeneratePDF_Error(t *testing.T) {
 
 	mockGen.On("GeneratePDF", "<html>dummy</html>").Return([]byte(nil), assert.AnError)
 
-	pdfBytes, filename, err := svc.GenerateTripReceiptPDF("trip123")
+	pdfBytes, filename, err := svc.GenerateTripReceiptPDF(context.Background(), "trip123")
 
 	assert.Error(t, err)
 	assert.Nil(t, pdfBytes)
@@ -169,7 +170,7 @@ func TestPDFService_RepoError(t *testing.T) {
 
 	mockRepo.On("GetTripByID", "trip123").Return(model.Pdf{}, errors.New("repo error"))
 
-	_, _, err := svc.GenerateTripReceiptPDF("trip123")
+	_, _, err := svc.GenerateTripReceiptPDF(context.Background(), "trip123")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "gagal mendapatkan data trip")
@@ -190,7 +191,7 @@ func TestPDFService_TemplateError(t *testing.T) {
 	mockRepo.On("GetTripByID", "trip123").Return(tripPdf, nil)
 	mockTpl.On("RenderPDFReceipt", mock.Anything).Return("", errors.New("template error"))
 
-	_, _, err := svc.GenerateTripReceiptPDF("trip123")
+	_, _, err := svc.GenerateTripReceiptPDF(context.Background(), "trip123")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "gagal render template")
@@ -212,8 +213,33 @@ func TestPDFService_PDFGeneratorError(t *testing.T) {
 	mockTpl.On("RenderPDFReceipt", mock.Anything).Return("<html>dummy</html>", nil)
 	mockGen.On("GeneratePDF", "<html>dummy</html>").Return([]byte(nil), errors.New("pdf gen error"))
 
-	_, _, err := svc.GenerateTripReceiptPDF("trip123")
+	_, _, err := svc.GenerateTripReceiptPDF(context.Background(), "trip123")
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "gagal generate PDF")
 }
+
+func TestHTMLTemplateRenderer(t *testing.T) {
+	r := &service.HTMLTemplateRenderer{Dir: "../templates"}
+
+	receipt, err := r.RenderPDFReceipt(model.PDFTemplateData{Pdf: model.Pdf{ID: "7", BookingCode: "BK-0001", CustomerName: "Sari"}, AmountFormatted: "150000"})
+	assert.NoError(t, err)
+	assert.Contains(t, receipt, "BK-0001")
+	assert.Contains(t, receipt, "150000")
+
+	bookingID, km := "BK-0002", 12.5
+	invoice, err := r.RenderInvoice(model.InvoiceTemplateData{
+		CorporateInvoice: model.CorporateInvoice{AccountName: "PT Maju", Number: "INV-202609-0001", Total: 300000, AmountPaid: 100000,
+			Lines: []model.InvoiceLine{{BookingID: &bookingID, Description: "Jakarta - Bandung", Booker: "Sari", CostCentre: "SALES", DistanceKM: &km, Amount: 300000}}},
+		TaxID:       "01.234.567.8-901.000",
+		Outstanding: 200000,
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, invoice, "INV-202609-0001")
+	assert.Contains(t, invoice, "BK-0002")
+	assert.Contains(t, invoice, "12.5")
+	assert.Contains(t, invoice, "200000")
+
+	_, err = (&service.HTMLTemplateRenderer{Dir: "missing"}).RenderInvoice(model.InvoiceTemplateData{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrNoTariff      = errors.New("no tariff configured for this car type")
+	ErrUnknownQuote  = errors.New("quote not found")
+	ErrQuoteExpired  = errors.New("quote has expired")
+	ErrQuoteRedeemed = repository.ErrQuoteRedeemed
+	ErrPriceLocked   = errors.New("price and car type of a quoted or discounted booking cannot be changed")
+	ErrNoDistance    = errors.New("distance_km is required unless pickup and drop can be routed")
+)
+
+type PricingServiceInterface interface {
+	GetTariffs(ctx context.Context) ([]model.Tariff, error)
+	SaveTariff(ctx context.Context, t *model.Tariff) error
+	Quote(ctx context.Context, req model.QuoteRequest) (*model.Quote, error)
+	GetQuote(ctx context.Context, id int) (*model.Quote, error)
+}
+
+type PricingService struct {
+	Repo       repository.PricingRepositoryInterface
+	Promotions repository.PromotionRepositoryInterface
+	QuoteTTL   time.Duration
+
+	// Router is optional; without it quotes need an explicit distance.
+	Router Router
+}
+
+func NewPricingService(repo repository.PricingRepositoryInterface, promotions repository.PromotionRepositoryInterface, quoteTTL time.Duration) *PricingService {
+	return &PricingService{Repo: repo, Promotions: promotions, QuoteTTL: quoteTTL}
+}
+
+func (s *PricingService) GetTariffs(ctx context.Context) ([]model.Tariff, error) {
+	return s.Repo.GetTariffs(ctx)
+}
+
+func (s *PricingService) SaveTariff(ctx context.Context, t *model.Tariff) error {
+	return s.Repo.SaveTariff(ctx, t)
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func formatMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// nightPickup reads the pickup hour in WIB, whatever offset the client sent.
+func nightPickup(t *model.Tariff, at time.Time) bool {
+	start, end, hour := t.NightStartHour, t.NightEndHour, at.In(wib).Hour()
+	if start == end {
+		return false
+	}
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// priceItems itemises a fare: base, distance and time, a fee per stop and
+// waiting at stops, topped up to the minimum fare, then the night surcharge on
+// that fare, the airport surcharge and tolls, which are passed through at cost.
+func priceItems(t *model.Tariff, req model.QuoteRequest) ([]model.QuoteItem, float64) {
+	items := []model.QuoteItem{
+		{Code: model.QuoteItemBaseFare, Description: "Base fare", Amount: roundMoney(t.BaseFare)},
+		{Code: model.QuoteItemDistance, Description: fmt.Sprintf("%.1f km x %s", req.DistanceKM, formatMoney(t.PerKM)), Amount: roundMoney(req.DistanceKM * t.PerKM)},
+		{Code: model.QuoteItemTime, Description: fmt.Sprintf("%.0f min x %s", req.DurationMinutes, formatMoney(t.PerMinute)), Amount: roundMoney(req.DurationMinutes * t.PerMinute)},
+	}
+	if n := len(req.Stops); n > 0 && t.PerStop > 0 {
+		items = append(items, model.QuoteItem{Code: model.QuoteItemStops, Description: fmt.Sprintf("%d stops x %s", n, formatMoney(t.PerStop)), Amount: roundMoney(float64(n) * t.PerStop)})
+	}
+	if wait := waitMinutes(req.Stops); wait > 0 && t.PerMinute > 0 {
+		items = append(items, model.QuoteItem{Code: model.QuoteItemWaiting, Description: fmt.Sprintf("%d min waiting x %s", wait, formatMoney(t.PerMinute)), Amount: roundMoney(float64(wait) * t.PerMinute)})
+	}
+
+	var fare float64
+	for _, item := range items {
+		fare += item.Amount
+	}
+	if fare < t.MinimumFare {
+		items = append(items, model.QuoteItem{Code: model.QuoteItemMinimumFare, Description: "Minimum fare adjustment", Amount: roundMoney(t.MinimumFare - fare)})
+		fare = t.MinimumFare
+	}
+
+	if t.NightSurchargePct > 0 && nightPickup(t, req.PickupAt) {
+		items = append(items, model.QuoteItem{Code: model.QuoteItemNight, Description: fmt.Sprintf("Night surcharge %g%%", t.NightSurchargePct), Amount: roundMoney(fare * t.NightSurchargePct / 100)})
+	}
+	if req.Airport && t.AirportSurcharge > 0 {
+		items = append(items, model.QuoteItem{Code: model.QuoteItemAirport, Description: "Airport surcharge", Amount: roundMoney(t.AirportSurcharge)})
+	}
+	if req.Tolls > 0 {
+		items = append(items, model.QuoteItem{Code: model.QuoteItemTolls, Description: "Tolls", Amount: roundMoney(req.Tolls)})
+	}
+
+	var total float64
+	for _, item := range items {
+		total += item.Amount
+	}
+	return items, roundMoney(total)
+}
+
+func (s *PricingService) Quote(ctx context.Context, req model.QuoteRequest) (*model.Quote, error) {
+	t, err := s.Repo.GetTariff(ctx, req.CarTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrNoTariff
+	}
+
+	legs, err := s.routeQuote(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	items, total := priceItems(t, req)
+
+	var promoCode *string
+	var discount float64
+	if req.PromoCode != "" {
+		p, d, err := applyPromotion(ctx, s.Promotions, req.PromoCode, total, req.CarTypeID, req.CustomerID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		promoCode, discount = &p.Code, d
+		items = append(items, model.QuoteItem{Code: model.QuoteItemDiscount, Description: "Promo " + p.Code, Amount: -d})
+		total = roundMoney(total - d)
+	}
+
+	q := &model.Quote{
+		CarTypeID:       req.CarTypeID,
+		DistanceKM:      req.DistanceKM,
+		DurationMinutes: req.DurationMinutes,
+		PickupAt:        req.PickupAt,
+		Airport:         req.Airport,
+		Stops:           len(req.Stops),
+		WaitMinutes:     waitMinutes(req.Stops),
+		QuotedStops:     req.Stops,
+		Legs:            legs,
+		Items:           items,
+		PromoCode:       promoCode,
+		Discount:        discount,
+		Total:           total,
+		ExpiresAt:       time.Now().Add(s.QuoteTTL),
+	}
+	if err := s.Repo.CreateQuote(ctx, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func waitMinutes(stops []model.QuoteStop) int {
+	var wait int
+	for _, st := range stops {
+		wait += st.WaitMinutes
+	}
+	return wait
+}
+
+// routeQuote fills in the distance and duration a quote request left out
+// from the road route between its pickup and drop-off, leg by leg through its
+// stops, and returns the legs it routed. A stop without a point cannot be
+// routed, so its quote needs an explicit distance.
+func (s *PricingService) routeQuote(ctx context.Context, req *model.QuoteRequest) ([]model.Route, error) {
+	if req.DistanceKM > 0 && req.DurationMinutes > 0 {
+		return nil, nil
+	}
+	points := quotePoints(req)
+	if s.Router == nil || points == nil {
+		if req.DistanceKM == 0 {
+			return nil, ErrNoDistance
+		}
+		return nil, nil
+	}
+
+	legs, err := routeLegs(ctx, s.Router, points, req.PickupAt)
+	if err != nil {
+		return nil, err
+	}
+	var km, minutes float64
+	for _, leg := range legs {
+		km += leg.DistanceKM
+		minutes += leg.DurationMinutes
+	}
+	if req.DistanceKM == 0 {
+		req.DistanceKM = math.Round(km*10) / 10
+	}
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = math.Round(minutes*10) / 10
+	}
+	return legs, nil
+}
+
+// quotePoints lists the pickup, stops and drop-off of req, or nil when any of
+// them has no point.
+func quotePoints(req *model.QuoteRequest) []model.GeoPoint {
+	if req.Pickup == nil || req.Drop == nil {
+		return nil
+	}
+	points := []model.GeoPoint{*req.Pickup}
+	for _, st := range req.Stops {
+		if st.Point == nil {
+			return nil
+		}
+		points = append(points, *st.Point)
+	}
+	return append(points, *req.Drop)
+}
+
+func (s *PricingService) GetQuote(ctx context.Context, id int) (*model.Quote, error) {
+	q, err := s.Repo.GetQuote(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if q == nil {
+		return nil, ErrNotFound
+	}
+	return q, nil
+}
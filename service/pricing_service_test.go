@@ -0,0 +1,206 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPricingRepository struct {
+	mock.Mock
+}
+
+func (m *MockPricingRepository) GetTariffs(ctx context.Context) ([]model.Tariff, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Tariff), args.Error(1)
+}
+
+func (m *MockPricingRepository) GetTariff(ctx context.Context, carTypeID string) (*model.Tariff, error) {
+	args := m.Called(carTypeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tariff), args.Error(1)
+}
+
+func (m *MockPricingRepository) SaveTariff(ctx context.Context, t *model.Tariff) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) CreateQuote(ctx context.Context, q *model.Quote) error {
+	args := m.Called(q)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) GetQuote(ctx context.Context, id int) (*model.Quote, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Quote), args.Error(1)
+}
+
+func quoteCodes(q *model.Quote) map[string]float64 {
+	codes := map[string]float64{}
+	for _, item := range q.Items {
+		codes[item.Code] = item.Amount
+	}
+	return codes
+}
+
+// wibAt is at in Western Indonesian Time, where tariffs read the hour.
+func wibAt(hour int) time.Time {
+	return time.Date(2024, 5, 1, hour, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+}
+
+func TestPricingService_Quote(t *testing.T) {
+	repo := new(MockPricingRepository)
+	svc := service.NewPricingService(repo, nil, 15*time.Minute)
+
+	repo.On("GetTariff", "mpv").Return(&model.Tariff{
+		CarTypeID: "mpv", BaseFare: 20000, PerKM: 4000, PerMinute: 500, MinimumFare: 50000,
+		NightSurchargePct: 20, NightStartHour: 22, NightEndHour: 5, AirportSurcharge: 35000,
+	}, nil)
+	repo.On("GetTariff", "bus").Return(nil, nil)
+	repo.On("CreateQuote", mock.Anything).Return(nil)
+
+	tests := []struct {
+		name  string
+		req   model.QuoteRequest
+		total float64
+		items map[string]float64
+	}{
+		{
+			name:  "day trip",
+			req:   model.QuoteRequest{CarTypeID: "mpv", DistanceKM: 12.5, DurationMinutes: 30, PickupAt: at(9)},
+			total: 85000,
+			items: map[string]float64{model.QuoteItemBaseFare: 20000, model.QuoteItemDistance: 50000, model.QuoteItemTime: 15000},
+		},
+		{
+			name:  "minimum fare",
+			req:   model.QuoteRequest{CarTypeID: "mpv", DistanceKM: 2, DurationMinutes: 10, PickupAt: at(9)},
+			total: 50000,
+			items: map[string]float64{model.QuoteItemBaseFare: 20000, model.QuoteItemDistance: 8000, model.QuoteItemTime: 5000, model.QuoteItemMinimumFare: 17000},
+		},
+		{
+			name:  "night airport run with tolls",
+			req:   model.QuoteRequest{CarTypeID: "mpv", DistanceKM: 2, PickupAt: wibAt(23), Airport: true, Tolls: 12500},
+			total: 107500,
+			items: map[string]float64{model.QuoteItemBaseFare: 20000, model.QuoteItemDistance: 8000, model.QuoteItemTime: 0, model.QuoteItemMinimumFare: 22000,
+				model.QuoteItemNight: 10000, model.QuoteItemAirport: 35000, model.QuoteItemTolls: 12500},
+		},
+		{
+			name:  "night in WIB whatever the client offset",
+			req:   model.QuoteRequest{CarTypeID: "mpv", DistanceKM: 20, PickupAt: at(16)},
+			total: 120000,
+			items: map[string]float64{model.QuoteItemBaseFare: 20000, model.QuoteItemDistance: 80000, model.QuoteItemTime: 0, model.QuoteItemNight: 20000},
+		},
+		{
+			name:  "late UTC evening is morning in WIB",
+			req:   model.QuoteRequest{CarTypeID: "mpv", DistanceKM: 20, PickupAt: at(23)},
+			total: 100000,
+			items: map[string]float64{model.QuoteItemBaseFare: 20000, model.QuoteItemDistance: 80000, model.QuoteItemTime: 0},
+		},
+		{
+			name:  "early morning is still night",
+			req:   model.QuoteRequest{CarTypeID: "mpv", DistanceKM: 20, PickupAt: wibAt(4)},
+			total: 120000,
+			items: map[string]float64{model.QuoteItemBaseFare: 20000, model.QuoteItemDistance: 80000, model.QuoteItemTime: 0, model.QuoteItemNight: 20000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := svc.Quote(context.Background(), tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.total, q.Total)
+			assert.Equal(t, tt.items, quoteCodes(q))
+			assert.WithinDuration(t, time.Now().Add(15*time.Minute), q.ExpiresAt, time.Second)
+		})
+	}
+
+	_, err := svc.Quote(context.Background(), model.QuoteRequest{CarTypeID: "bus", DistanceKM: 5, PickupAt: at(9)})
+	assert.ErrorIs(t, err, service.ErrNoTariff)
+}
+
+func TestBookingService_CreateFromQuote(t *testing.T) {
+	repo := new(MockBookingRepository)
+	pricing := new(MockPricingRepository)
+	svc := &service.BookingService{Repo: repo, Pricing: pricing}
+
+	live, stale := 3, 4
+	pricing.On("GetQuote", live).Return(&model.Quote{ID: live, CarTypeID: "mpv", Total: 85000, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	pricing.On("GetQuote", stale).Return(&model.Quote{ID: stale, CarTypeID: "mpv", Total: 85000, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+	pricing.On("GetQuote", 5).Return(nil, nil)
+
+	amount := 1.0
+	b := &model.Booking{Customer: "Sari", QuoteID: &live, Amount: &amount, Price: "1"}
+	repo.On("Create", b).Return(nil)
+
+	assert.NoError(t, svc.Create(context.Background(), b))
+	assert.Equal(t, 85000.0, *b.Amount)
+	assert.Equal(t, "85000.00", b.Price)
+	assert.Equal(t, "mpv", *b.CarTypeID)
+
+	assert.ErrorIs(t, svc.Create(context.Background(), &model.Booking{QuoteID: &stale}), service.ErrQuoteExpired)
+	missing := 5
+	assert.ErrorIs(t, svc.Create(context.Background(), &model.Booking{QuoteID: &missing}), service.ErrUnknownQuote)
+}
+
+func TestBookingService_UpdateKeepsQuotedPrice(t *testing.T) {
+	repo := new(MockBookingRepository)
+	svc := &service.BookingService{Repo: repo}
+
+	quoteID, amount, carType := 3, 85000.0, "mpv"
+	current := &model.Booking{ID: "BK1", Status: model.BookingPending, QuoteID: &quoteID, Amount: &amount, Price: "85000.00", CarTypeID: &carType, Version: 1}
+	repo.On("GetByID", "BK1").Return(current, nil)
+
+	cheaper := 1000.0
+	err := svc.Update(context.Background(), &model.Booking{ID: "BK1", Amount: &cheaper, Version: 1})
+	assert.ErrorIs(t, err, service.ErrPriceLocked)
+
+	b := &model.Booking{ID: "BK1", Place: "Bandung", Version: 1}
+	repo.On("Update", b).Return(nil)
+	assert.NoError(t, svc.Update(context.Background(), b))
+	assert.Equal(t, 85000.0, *b.Amount)
+	assert.Equal(t, "85000.00", b.Price)
+	assert.Equal(t, quoteID, *b.QuoteID)
+}
+
+func TestBookingService_QuotedStopsAreLocked(t *testing.T) {
+	repo := new(MockBookingRepository)
+	pricing := new(MockPricingRepository)
+	svc := &service.BookingService{Repo: repo, Pricing: pricing}
+
+	quoteID, amount, carType := 3, 85000.0, "mpv"
+	lat, lng, elsewhere := -6.9, 107.6, 107.7
+	pricing.On("GetQuote", quoteID).Return(&model.Quote{ID: quoteID, CarTypeID: carType, Total: amount, ExpiresAt: time.Now().Add(time.Minute),
+		Stops: 1, WaitMinutes: 10, QuotedStops: []model.QuoteStop{{Point: &model.GeoPoint{Lat: lat, Lng: lng}, WaitMinutes: 10}}}, nil)
+
+	for _, stops := range [][]model.BookingStop{
+		nil,
+		{{Address: "Dago", Lat: &lat, Lng: &lng, WaitMinutes: 10}, {Address: "Braga", WaitMinutes: 5}},
+		{{Address: "Dago", Lat: &lat, Lng: &lng, WaitMinutes: 30}},
+		{{Address: "Dago", Lat: &lat, Lng: &elsewhere, WaitMinutes: 10}},
+	} {
+		err := svc.Create(context.Background(), &model.Booking{Customer: "Sari", QuoteID: &quoteID, Stops: stops})
+		assert.ErrorIs(t, err, service.ErrPriceLocked)
+	}
+
+	b := &model.Booking{Customer: "Sari", QuoteID: &quoteID, Stops: []model.BookingStop{{Address: "Dago", Lat: &lat, Lng: &lng, WaitMinutes: 10}}}
+	repo.On("Create", b).Return(nil)
+	assert.NoError(t, svc.Create(context.Background(), b))
+	repo.AssertNumberOfCalls(t, "Create", 1)
+
+	current := &model.Booking{ID: "BK1", Status: model.BookingPending, QuoteID: &quoteID, Amount: &amount, Price: "85000.00", CarTypeID: &carType, Stops: b.Stops, Version: 1}
+	repo.On("GetByID", "BK1").Return(current, nil)
+	err := svc.Update(context.Background(), &model.Booking{ID: "BK1", Stops: []model.BookingStop{{Address: "Braga", WaitMinutes: 10}}, Version: 1})
+	assert.ErrorIs(t, err, service.ErrPriceLocked)
+	repo.AssertNotCalled(t, "Update", mock.Anything)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrUnknownPromo       = repository.ErrUnknownPromotion
+	ErrPromoExhausted     = repository.ErrPromotionExhausted
+	ErrPromoInactive      = errors.New("promo code is not active at this time")
+	ErrPromoNotApplicable = errors.New("promo code does not apply to this fare or car type")
+	ErrPromoNeedsCustomer = errors.New("promo code is limited per customer and needs a customer_id")
+	ErrPromoOnQuote       = errors.New("promo code must be applied when requesting the quote")
+	ErrPromoCodeTaken     = errors.New("promo code already exists")
+	ErrInvalidPromo       = errors.New("percentage discount must not exceed 100 and valid_until must be after valid_from")
+)
+
+type PromotionServiceInterface interface {
+	GetAll(ctx context.Context) ([]model.Promotion, error)
+	GetByID(ctx context.Context, id int) (*model.Promotion, error)
+	Create(ctx context.Context, p *model.Promotion) error
+	Update(ctx context.Context, p *model.Promotion) error
+	GetRedemptions(ctx context.Context, id int) ([]model.PromotionRedemption, error)
+	Report(ctx context.Context, from, to time.Time) ([]model.PromotionReport, error)
+}
+
+type PromotionService struct {
+	Repo repository.PromotionRepositoryInterface
+}
+
+func NewPromotionService(repo repository.PromotionRepositoryInterface) *PromotionService {
+	return &PromotionService{Repo: repo}
+}
+
+func validatePromotion(p *model.Promotion) error {
+	if p.DiscountType == model.DiscountPercent && p.DiscountValue > 100 {
+		return ErrInvalidPromo
+	}
+	if p.ValidFrom != nil && p.ValidUntil != nil && !p.ValidUntil.After(*p.ValidFrom) {
+		return ErrInvalidPromo
+	}
+	return nil
+}
+
+// applyPromotion looks up code and works out its discount on fare at time at.
+// Usage limits are checked here for a clear error and again, atomically, when
+// the booking repository records the redemption.
+func applyPromotion(ctx context.Context, repo repository.PromotionRepositoryInterface, code string, fare float64, carTypeID string, customerID *int, at time.Time) (*model.Promotion, float64, error) {
+	p, err := repo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, 0, err
+	}
+	if p == nil {
+		return nil, 0, ErrUnknownPromo
+	}
+
+	if !p.Active || (p.ValidFrom != nil && at.Before(*p.ValidFrom)) || (p.ValidUntil != nil && !at.Before(*p.ValidUntil)) {
+		return nil, 0, ErrPromoInactive
+	}
+	if fare <= 0 || fare < p.MinimumFare {
+		return nil, 0, ErrPromoNotApplicable
+	}
+	if len(p.CarTypeIDs) > 0 {
+		allowed := false
+		for _, id := range p.CarTypeIDs {
+			allowed = allowed || id == carTypeID
+		}
+		if !allowed {
+			return nil, 0, ErrPromoNotApplicable
+		}
+	}
+	if p.MaxPerCustomer != nil && customerID == nil {
+		return nil, 0, ErrPromoNeedsCustomer
+	}
+
+	total, customer, err := repo.Usage(ctx, p.ID, customerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if (p.MaxRedemptions != nil && total >= *p.MaxRedemptions) || (p.MaxPerCustomer != nil && customer >= *p.MaxPerCustomer) {
+		return nil, 0, ErrPromoExhausted
+	}
+
+	discount := p.DiscountValue
+	if p.DiscountType == model.DiscountPercent {
+		discount = fare * p.DiscountValue / 100
+		if p.MaxDiscount != nil {
+			discount = math.Min(discount, *p.MaxDiscount)
+		}
+	}
+	return p, roundMoney(math.Min(discount, fare)), nil
+}
+
+func (s *PromotionService) GetAll(ctx context.Context) ([]model.Promotion, error) {
+	return s.Repo.GetAll(ctx)
+}
+
+func (s *PromotionService) GetByID(ctx context.Context, id int) (*model.Promotion, error) {
+	p, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrNotFound
+	}
+	return p, nil
+}
+
+func (s *PromotionService) Create(ctx context.Context, p *model.Promotion) error {
+	if err := validatePromotion(p); err != nil {
+		return err
+	}
+	err := s.Repo.Create(ctx, p)
+	if errors.Is(err, repository.ErrDuplicateKey) {
+		return ErrPromoCodeTaken
+	}
+	return err
+}
+
+// Update changes the terms of a promotion; its code stays as created.
+func (s *PromotionService) Update(ctx context.Context, p *model.Promotion) error {
+	current, err := s.GetByID(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	p.Code = current.Code
+
+	if err := validatePromotion(p); err != nil {
+		return err
+	}
+	return s.Repo.Update(ctx, p)
+}
+
+func (s *PromotionService) GetRedemptions(ctx context.Context, id int) ([]model.PromotionRedemption, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetRedemptions(ctx, id)
+}
+
+// Report sums redemptions within [from, to). Remaining counts against the
+// overall limit across all time, not just the reported period.
+func (s *PromotionService) Report(ctx context.Context, from, to time.Time) ([]model.PromotionReport, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidWindow
+	}
+
+	report, err := s.Repo.Report(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report {
+		p, err := s.Repo.GetByID(ctx, report[i].PromotionID)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil || p.MaxRedemptions == nil {
+			continue
+		}
+		total, _, err := s.Repo.Usage(ctx, p.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+		remaining := *p.MaxRedemptions - total
+		if remaining < 0 {
+			remaining = 0
+		}
+		report[i].Remaining = &remaining
+	}
+	return report, nil
+}
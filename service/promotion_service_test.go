@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPromotionRepository struct {
+	mock.Mock
+}
+
+func (m *MockPromotionRepository) GetAll(ctx context.Context) ([]model.Promotion, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionRepository) GetByID(ctx context.Context, id int) (*model.Promotion, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionRepository) GetByCode(ctx context.Context, code string) (*model.Promotion, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionRepository) Create(ctx context.Context, p *model.Promotion) error {
+	args := m.Called(p)
+	return args.Error(0)
+}
+
+func (m *MockPromotionRepository) Update(ctx context.Context, p *model.Promotion) error {
+	args := m.Called(p)
+	return args.Error(0)
+}
+
+func (m *MockPromotionRepository) Usage(ctx context.Context, promotionID int, customerID *int) (int, int, error) {
+	args := m.Called(promotionID, customerID)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockPromotionRepository) GetRedemptions(ctx context.Context, promotionID int) ([]model.PromotionRedemption, error) {
+	args := m.Called(promotionID)
+	return args.Get(0).([]model.PromotionRedemption), args.Error(1)
+}
+
+func (m *MockPromotionRepository) Report(ctx context.Context, from, to time.Time) ([]model.PromotionReport, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]model.PromotionReport), args.Error(1)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestPricingService_QuoteWithPromotion(t *testing.T) {
+	repo := new(MockPricingRepository)
+	promotions := new(MockPromotionRepository)
+	svc := service.NewPricingService(repo, promotions, 15*time.Minute)
+
+	maxDiscount := 10000.0
+	expired := time.Now().Add(-time.Hour)
+	repo.On("GetTariff", "mpv").Return(&model.Tariff{CarTypeID: "mpv", BaseFare: 20000, PerKM: 4000}, nil)
+	repo.On("CreateQuote", mock.Anything).Return(nil)
+	promotions.On("GetByCode", "hemat20").Return(&model.Promotion{ID: 1, Code: "HEMAT20", DiscountType: model.DiscountPercent, DiscountValue: 20, MaxDiscount: &maxDiscount, Active: true}, nil)
+	promotions.On("GetByCode", "potong").Return(&model.Promotion{ID: 2, Code: "POTONG", DiscountType: model.DiscountFixed, DiscountValue: 5000, Active: true}, nil)
+	promotions.On("GetByCode", "lama").Return(&model.Promotion{ID: 3, Code: "LAMA", DiscountType: model.DiscountFixed, DiscountValue: 5000, ValidUntil: &expired, Active: true}, nil)
+	promotions.On("GetByCode", "sedan").Return(&model.Promotion{ID: 4, Code: "SEDAN", DiscountType: model.DiscountFixed, DiscountValue: 5000, CarTypeIDs: []string{"sedan"}, Active: true}, nil)
+	promotions.On("GetByCode", "besar").Return(&model.Promotion{ID: 5, Code: "BESAR", DiscountType: model.DiscountFixed, DiscountValue: 5000, MinimumFare: 500000, Active: true}, nil)
+	promotions.On("GetByCode", "sekali").Return(&model.Promotion{ID: 6, Code: "SEKALI", DiscountType: model.DiscountFixed, DiscountValue: 5000, MaxRedemptions: intPtr(10), MaxPerCustomer: intPtr(1), Active: true}, nil)
+	promotions.On("GetByCode", "nope").Return(nil, nil)
+	promotions.On("Usage", 1, (*int)(nil)).Return(0, 0, nil)
+	promotions.On("Usage", 2, (*int)(nil)).Return(0, 0, nil)
+	promotions.On("Usage", 6, intPtr(7)).Return(3, 1, nil)
+
+	tests := []struct {
+		name     string
+		code     string
+		customer *int
+		discount float64
+		total    float64
+		err      error
+	}{
+		{name: "percentage capped", code: "hemat20", discount: 10000, total: 50000},
+		{name: "fixed", code: "potong", discount: 5000, total: 55000},
+		{name: "expired", code: "lama", err: service.ErrPromoInactive},
+		{name: "other car type", code: "sedan", err: service.ErrPromoNotApplicable},
+		{name: "below minimum fare", code: "besar", err: service.ErrPromoNotApplicable},
+		{name: "per customer without customer", code: "sekali", err: service.ErrPromoNeedsCustomer},
+		{name: "customer limit reached", code: "sekali", customer: intPtr(7), err: service.ErrPromoExhausted},
+		{name: "unknown", code: "nope", err: service.ErrUnknownPromo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := svc.Quote(context.Background(), model.QuoteRequest{CarTypeID: "mpv", DistanceKM: 10, PickupAt: at(9), PromoCode: tt.code, CustomerID: tt.customer})
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.total, q.Total)
+			assert.Equal(t, tt.discount, q.Discount)
+			assert.Equal(t, -tt.discount, quoteCodes(q)[model.QuoteItemDiscount])
+		})
+	}
+}
+
+func TestBookingService_CreateWithPromotion(t *testing.T) {
+	repo := new(MockBookingRepository)
+	pricing := new(MockPricingRepository)
+	promotions := new(MockPromotionRepository)
+	svc := &service.BookingService{Repo: repo, Pricing: pricing, Promotions: promotions}
+
+	promotions.On("GetByCode", "potong").Return(&model.Promotion{ID: 2, Code: "POTONG", DiscountType: model.DiscountFixed, DiscountValue: 5000, Active: true}, nil)
+	promotions.On("Usage", 2, (*int)(nil)).Return(0, 0, nil)
+
+	code, amount := "potong", 80000.0
+	b := &model.Booking{Customer: "Sari", PromoCode: &code, Amount: &amount}
+	repo.On("Create", b).Return(nil)
+	assert.NoError(t, svc.Create(context.Background(), b))
+	assert.Equal(t, 75000.0, *b.Amount)
+	assert.Equal(t, "75000.00", b.Price)
+	assert.Equal(t, 5000.0, *b.Discount)
+	assert.Equal(t, "POTONG", *b.PromoCode)
+
+	quoted := 3
+	quoteCode := "HEMAT20"
+	pricing.On("GetQuote", quoted).Return(&model.Quote{ID: quoted, CarTypeID: "mpv", PromoCode: &quoteCode, Discount: 10000, Total: 50000, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+
+	fromQuote := &model.Booking{Customer: "Sari", QuoteID: &quoted}
+	repo.On("Create", fromQuote).Return(nil)
+	assert.NoError(t, svc.Create(context.Background(), fromQuote))
+	assert.Equal(t, 50000.0, *fromQuote.Amount)
+	assert.Equal(t, 10000.0, *fromQuote.Discount)
+	assert.Equal(t, "HEMAT20", *fromQuote.PromoCode)
+
+	other := "potong"
+	err := svc.Create(context.Background(), &model.Booking{QuoteID: &quoted, PromoCode: &other})
+	assert.ErrorIs(t, err, service.ErrPromoOnQuote)
+}
+
+func TestPromotionService_CreateAndReport(t *testing.T) {
+	repo := new(MockPromotionRepository)
+	svc := service.NewPromotionService(repo)
+
+	assert.ErrorIs(t, svc.Create(context.Background(), &model.Promotion{Code: "X", DiscountType: model.DiscountPercent, DiscountValue: 150}), service.ErrInvalidPromo)
+
+	taken := &model.Promotion{Code: "HEMAT", DiscountType: model.DiscountFixed, DiscountValue: 5000}
+	repo.On("Create", taken).Return(repository.ErrDuplicateKey)
+	assert.ErrorIs(t, svc.Create(context.Background(), taken), service.ErrPromoCodeTaken)
+
+	from, to := time.Now().Add(-time.Hour), time.Now()
+	_, err := svc.Report(context.Background(), to, from)
+	assert.ErrorIs(t, err, service.ErrInvalidWindow)
+
+	repo.On("Report", from, to).Return([]model.PromotionReport{{PromotionID: 1, Code: "HEMAT", Redemptions: 4}, {PromotionID: 2, Code: "BEBAS", Redemptions: 1}}, nil)
+	repo.On("GetByID", 1).Return(&model.Promotion{ID: 1, MaxRedemptions: intPtr(10)}, nil)
+	repo.On("GetByID", 2).Return(&model.Promotion{ID: 2}, nil)
+	repo.On("Usage", 1, (*int)(nil)).Return(6, 0, nil)
+
+	report, err := svc.Report(context.Background(), from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, *report[0].Remaining)
+	assert.Nil(t, report[1].Remaining)
+}
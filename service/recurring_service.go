@@ -0,0 +1,448 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidRecurrence = errors.New("recurring booking must end on or after its first pickup")
+	ErrNoOccurrence      = errors.New("recurring booking has no occurrence on this date")
+)
+
+type RecurringServiceInterface interface {
+	GetAll(ctx context.Context) ([]model.RecurringBooking, error)
+	GetByID(ctx context.Context, id int) (*model.RecurringBookingDetail, error)
+	Create(ctx context.Context, r *model.RecurringBooking) error
+	Update(ctx context.Context, r *model.RecurringBooking, user *model.User) error
+	UpdateOccurrence(ctx context.Context, id int, date string, b *model.Booking) error
+	SkipOccurrence(ctx context.Context, id int, date string, user *model.User) error
+	Cancel(ctx context.Context, id int, user *model.User, c model.RecurringCancel) (*model.RecurringBooking, error)
+}
+
+// RecurringService books the occurrences of recurring series through the
+// booking service, so each one is checked like a booking made by hand.
+type RecurringService struct {
+	Repo      repository.RecurringBookingRepositoryInterface
+	Bookings  BookingServiceInterface
+	DaysAhead int
+}
+
+func NewRecurringService(repo repository.RecurringBookingRepositoryInterface, bookings BookingServiceInterface, daysAhead int) *RecurringService {
+	return &RecurringService{Repo: repo, Bookings: bookings, DaysAhead: daysAhead}
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// civilDays counts calendar days from a to b, ignoring the clock.
+func civilDays(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return int(time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC).Sub(time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// occursOn reports whether the series has an occurrence on the day of at,
+// which must be in the location of r.StartAt. Weekly intervals count weeks
+// starting on Monday, as RRULE does by default.
+func occursOn(r *model.RecurringBooking, at time.Time) bool {
+	date := at.Format(model.DateLayout)
+	n := civilDays(r.StartAt, at)
+	if n < 0 || (r.Until != nil && date > *r.Until) {
+		return false
+	}
+	for _, skipped := range r.Exceptions {
+		if skipped == date {
+			return false
+		}
+	}
+
+	// Without weekdays a daily series runs every day and a weekly one on the
+	// weekday of its first pickup.
+	matched := len(r.Weekdays) == 0 && (r.Frequency == model.RecurDaily || at.Weekday() == r.StartAt.Weekday())
+	for _, code := range r.Weekdays {
+		matched = matched || weekdayCodes[code] == at.Weekday()
+	}
+	if !matched {
+		return false
+	}
+
+	if r.Frequency == model.RecurWeekly {
+		n = (n + (int(r.StartAt.Weekday())+6)%7) / 7
+	}
+	return n%max(r.Interval, 1) == 0
+}
+
+// occurrenceAt is the pickup time of the series on date, at the clock time of
+// its first pickup.
+func occurrenceAt(r *model.RecurringBooking, date string) (time.Time, bool) {
+	day, err := time.ParseInLocation(model.DateLayout, date, r.StartAt.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	at := time.Date(day.Year(), day.Month(), day.Day(), r.StartAt.Hour(), r.StartAt.Minute(), r.StartAt.Second(), 0, r.StartAt.Location())
+	return at, occursOn(r, at)
+}
+
+// occurrences lists the pickups of the series after from, up to and including
+// the day of to.
+func occurrences(r *model.RecurringBooking, from, to time.Time) []time.Time {
+	loc := r.StartAt.Location()
+	from, to = from.In(loc), to.In(loc)
+
+	pickups := []time.Time{}
+	for day := from; civilDays(day, to) >= 0; day = day.AddDate(0, 0, 1) {
+		at, ok := occurrenceAt(r, day.Format(model.DateLayout))
+		if ok && at.After(from) {
+			pickups = append(pickups, at)
+		}
+	}
+	return pickups
+}
+
+// bookingFor builds the booking of one occurrence.
+func bookingFor(r *model.RecurringBooking, at time.Time) *model.Booking {
+	end := at.Add(time.Duration(r.DurationMinutes) * time.Minute)
+	pickupTime := at.Format("15:04")
+	b := &model.Booking{
+		CustomerID:     r.CustomerID,
+		Customer:       r.Customer,
+		PhoneNumber:    r.PhoneNumber,
+		Place:          r.Place,
+		Date:           at.Format(model.DateLayout),
+		StartAt:        &at,
+		EndAt:          &end,
+		PickupTime:     &pickupTime,
+		PickupLocation: r.PickupLocation,
+		PickupLat:      r.PickupLat,
+		PickupLng:      r.PickupLng,
+		DropLocation:   r.DropLocation,
+		CarTypeID:      r.CarTypeID,
+		DriverID:       r.DriverID,
+		VehicleID:      r.VehicleID,
+		Payment:        r.Payment,
+		Notes:          r.Notes,
+		Status:         model.BookingPending,
+	}
+	if r.Amount != nil {
+		amount := *r.Amount
+		b.Amount = &amount
+		b.Price = formatMoney(amount)
+	}
+	return b
+}
+
+func validateRecurring(r *model.RecurringBooking) error {
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	if r.Until == nil {
+		return nil
+	}
+	until, err := time.Parse(model.DateLayout, *r.Until)
+	if err != nil || civilDays(r.StartAt, until) < 0 {
+		return ErrInvalidRecurrence
+	}
+	return nil
+}
+
+func (s *RecurringService) GetAll(ctx context.Context) ([]model.RecurringBooking, error) {
+	return s.Repo.GetAll(ctx)
+}
+
+func (s *RecurringService) get(ctx context.Context, id int) (*model.RecurringBooking, error) {
+	r, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ErrNotFound
+	}
+	return r, nil
+}
+
+func (s *RecurringService) GetByID(ctx context.Context, id int) (*model.RecurringBookingDetail, error) {
+	r, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := s.Repo.GetOccurrences(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RecurringBookingDetail{RecurringBooking: *r, Occurrences: scheduled}, nil
+}
+
+// Create saves the series and books its occurrences within the scheduling
+// horizon straight away.
+func (s *RecurringService) Create(ctx context.Context, r *model.RecurringBooking) error {
+	if err := validateRecurring(r); err != nil {
+		return err
+	}
+	if err := s.Repo.Create(ctx, r); err != nil {
+		return err
+	}
+	_, err := s.materialise(ctx, r, time.Now())
+	return err
+}
+
+// Update edits the whole series. Upcoming occurrences that are still pending
+// and were not edited on their own are rebooked from the new template, or
+// cancelled when the new pattern no longer includes their day.
+func (s *RecurringService) Update(ctx context.Context, r *model.RecurringBooking, user *model.User) error {
+	if _, err := s.get(ctx, r.ID); err != nil {
+		return err
+	}
+	if err := validateRecurring(r); err != nil {
+		return err
+	}
+	if err := s.Repo.Update(ctx, r); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	scheduled, err := s.Repo.GetOccurrences(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+	for i := range scheduled {
+		o := &scheduled[i]
+		if o.Detached {
+			continue
+		}
+		at, ok := occurrenceAt(r, o.Date)
+
+		if o.BookingID == nil {
+			if ok && at.After(now) {
+				if err := s.book(ctx, r, o, at); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		b, err := s.Bookings.GetByID(ctx, *o.BookingID)
+		if err != nil {
+			return err
+		}
+		if b == nil || b.Status != model.BookingPending || b.StartAt == nil || !b.StartAt.After(now) {
+			continue
+		}
+		if !ok {
+			if _, err := s.Bookings.Transition(ctx, b.ID, user, model.BookingTransition{To: model.BookingCancelled, Reason: "removed from recurring series"}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		next := bookingFor(r, at)
+		next.ID, next.Version = b.ID, b.Version
+		o.Error = nil
+		if err := s.Bookings.Update(ctx, next); err != nil {
+			msg := err.Error()
+			o.Error = &msg
+		}
+		if err := s.Repo.SaveOccurrence(ctx, o); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.materialise(ctx, r, now)
+	return err
+}
+
+func (s *RecurringService) occurrence(ctx context.Context, id int, date string) (*model.RecurringOccurrence, error) {
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+	scheduled, err := s.Repo.GetOccurrences(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range scheduled {
+		if scheduled[i].Date == date {
+			return &scheduled[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateOccurrence edits the booking of one occurrence and detaches it, so
+// later edits to the series leave it as it is.
+func (s *RecurringService) UpdateOccurrence(ctx context.Context, id int, date string, b *model.Booking) error {
+	o, err := s.occurrence(ctx, id, date)
+	if err != nil {
+		return err
+	}
+	if o == nil || o.BookingID == nil {
+		return ErrNotFound
+	}
+
+	b.ID = *o.BookingID
+	if err := s.Bookings.Update(ctx, b); err != nil {
+		return err
+	}
+	o.Detached = true
+	return s.Repo.SaveOccurrence(ctx, o)
+}
+
+// SkipOccurrence adds date to the exceptions of the series and cancels its
+// booking if one was already made.
+func (s *RecurringService) SkipOccurrence(ctx context.Context, id int, date string, user *model.User) error {
+	r, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, ok := occurrenceAt(r, date); !ok {
+		return ErrNoOccurrence
+	}
+
+	o, err := s.occurrence(ctx, id, date)
+	if err != nil {
+		return err
+	}
+	if o != nil && o.BookingID != nil {
+		if err := s.cancelBooking(ctx, *o.BookingID, user, "occurrence skipped"); err != nil {
+			return err
+		}
+	}
+
+	r.Exceptions = append(r.Exceptions, date)
+	return s.Repo.Update(ctx, r)
+}
+
+// Cancel ends the series and cancels its bookings from c.From on. Bookings
+// that are already under way or finished are left alone.
+func (s *RecurringService) Cancel(ctx context.Context, id int, user *model.User, c model.RecurringCancel) (*model.RecurringBooking, error) {
+	r, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	from := c.From
+	if from == "" {
+		from = time.Now().In(r.StartAt.Location()).Format(model.DateLayout)
+	}
+	fromDay, err := time.Parse(model.DateLayout, from)
+	if err != nil {
+		return nil, ErrInvalidRecurrence
+	}
+	reason := c.Reason
+	if reason == "" {
+		reason = "recurring series cancelled"
+	}
+
+	scheduled, err := s.Repo.GetOccurrences(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range scheduled {
+		if o.Date < from || o.BookingID == nil {
+			continue
+		}
+		if err := s.cancelBooking(ctx, *o.BookingID, user, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	until := fromDay.AddDate(0, 0, -1).Format(model.DateLayout)
+	if r.Until == nil || until < *r.Until {
+		r.Until = &until
+	}
+	if err := s.Repo.Update(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// cancelBooking cancels a scheduled booking unless it is already cancelled or
+// past the point where it can be.
+func (s *RecurringService) cancelBooking(ctx context.Context, id string, user *model.User, reason string) error {
+	b, err := s.Bookings.GetByID(ctx, id)
+	if err != nil || b == nil {
+		return err
+	}
+	if _, ok := bookingTransitions[b.Status][model.BookingCancelled]; !ok {
+		return nil
+	}
+	_, err = s.Bookings.Transition(ctx, id, user, model.BookingTransition{To: model.BookingCancelled, Reason: reason})
+	return err
+}
+
+// book creates the booking of a claimed occurrence. A booking the booking
+// service refuses, say because the driver is on leave, is recorded on the
+// occurrence rather than failing the whole run.
+func (s *RecurringService) book(ctx context.Context, r *model.RecurringBooking, o *model.RecurringOccurrence, at time.Time) error {
+	b := bookingFor(r, at)
+	o.BookingID, o.Error = nil, nil
+	if err := s.Bookings.Create(ctx, b); err != nil {
+		msg := err.Error()
+		o.Error = &msg
+	} else {
+		o.BookingID = &b.ID
+	}
+	return s.Repo.SaveOccurrence(ctx, o)
+}
+
+func (s *RecurringService) materialise(ctx context.Context, r *model.RecurringBooking, now time.Time) (int, error) {
+	days := r.DaysAhead
+	if days == 0 {
+		days = s.DaysAhead
+	}
+
+	existing, err := s.Repo.GetOccurrences(ctx, r.ID)
+	if err != nil {
+		return 0, err
+	}
+	claimed := map[string]bool{}
+	for _, o := range existing {
+		claimed[o.Date] = true
+	}
+
+	n := 0
+	for _, at := range occurrences(r, now, now.AddDate(0, 0, days)) {
+		o := &model.RecurringOccurrence{RecurringID: r.ID, Date: at.Format(model.DateLayout)}
+		if claimed[o.Date] {
+			continue
+		}
+		err := s.Repo.ClaimOccurrence(ctx, o)
+		if errors.Is(err, repository.ErrDuplicateKey) {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := s.book(ctx, r, o, at); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Materialise books the upcoming occurrences of every active series in every
+// tenant and returns how many it scheduled.
+func (s *RecurringService) Materialise(ctx context.Context) (int, error) {
+	now := time.Now()
+	series, err := s.Repo.GetActive(ctx, now.AddDate(0, 0, -1).Format(model.DateLayout))
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for i := range series {
+		n, err := s.materialise(utils.WithTenant(ctx, series[i].TenantID), &series[i], now)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
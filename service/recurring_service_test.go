@@ -0,0 +1,139 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOccursOn(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	monday := time.Date(2024, 5, 6, 7, 30, 0, 0, jakarta)
+	wednesday := monday.AddDate(0, 0, 2)
+	until := "2024-05-10"
+
+	tests := []struct {
+		name   string
+		series model.RecurringBooking
+		days   map[string]bool
+	}{
+		{
+			name:   "every day",
+			series: model.RecurringBooking{Frequency: model.RecurDaily, StartAt: monday},
+			days:   map[string]bool{"2024-05-05": false, "2024-05-06": true, "2024-05-07": true, "2024-05-12": true},
+		},
+		{
+			name:   "every other day",
+			series: model.RecurringBooking{Frequency: model.RecurDaily, Interval: 2, StartAt: monday},
+			days:   map[string]bool{"2024-05-07": false, "2024-05-08": true},
+		},
+		{
+			name:   "weekdays until friday with an exception",
+			series: model.RecurringBooking{Frequency: model.RecurDaily, Weekdays: []string{"MO", "TU", "WE", "TH", "FR"}, StartAt: monday, Until: &until, Exceptions: []string{"2024-05-09"}},
+			days:   map[string]bool{"2024-05-08": true, "2024-05-09": false, "2024-05-10": true, "2024-05-11": false, "2024-05-13": false},
+		},
+		{
+			name:   "weekly on the start weekday",
+			series: model.RecurringBooking{Frequency: model.RecurWeekly, StartAt: monday},
+			days:   map[string]bool{"2024-05-13": true, "2024-05-14": false},
+		},
+		{
+			name:   "fortnightly on monday and wednesday from a wednesday",
+			series: model.RecurringBooking{Frequency: model.RecurWeekly, Interval: 2, Weekdays: []string{"MO", "WE"}, StartAt: wednesday},
+			days:   map[string]bool{"2024-05-06": false, "2024-05-08": true, "2024-05-13": false, "2024-05-15": false, "2024-05-20": true, "2024-05-22": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for day, want := range tt.days {
+				at, ok := occurrenceAt(&tt.series, day)
+				assert.Equal(t, want, ok, day)
+				assert.Equal(t, "07:30", at.Format("15:04"))
+			}
+		})
+	}
+}
+
+func TestRecurringService_Series(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	bookings := &BookingService{Repo: repository.NewMemoryBookingRepository(store)}
+	svc := NewRecurringService(repository.NewMemoryRecurringBookingRepository(store), bookings, 14)
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+
+	now := time.Now()
+	first := time.Date(now.Year(), now.Month(), now.Day()+1, 7, 0, 0, 0, now.Location())
+	day := func(n int) string { return first.AddDate(0, 0, n).Format(model.DateLayout) }
+	amount := 80000.0
+
+	series := &model.RecurringBooking{Customer: "PT Maju", Frequency: model.RecurDaily, StartAt: first, DurationMinutes: 45, Amount: &amount, DaysAhead: 3}
+	require.NoError(t, svc.Create(ctx, series))
+
+	detail, err := svc.GetByID(ctx, series.ID)
+	require.NoError(t, err)
+	require.Len(t, detail.Occurrences, 3)
+	assert.Equal(t, day(0), detail.Occurrences[0].Date)
+
+	booked, err := bookings.GetByID(ctx, *detail.Occurrences[0].BookingID)
+	require.NoError(t, err)
+	assert.Equal(t, "PT Maju", booked.Customer)
+	assert.Equal(t, "80000.00", booked.Price)
+	assert.True(t, first.Equal(*booked.StartAt))
+	assert.Equal(t, 45*time.Minute, booked.EndAt.Sub(*booked.StartAt))
+
+	n, err := svc.Materialise(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	notes := "gate B"
+	edited := *booked
+	edited.ID, edited.Notes = "", &notes
+	require.NoError(t, svc.UpdateOccurrence(ctx, series.ID, day(1), &edited))
+
+	cheaper := 60000.0
+	series.Amount = &cheaper
+	series.Exceptions = []string{day(2)}
+	require.NoError(t, svc.Update(ctx, series, admin))
+
+	status := func(date string) *model.Booking {
+		for _, o := range mustDetail(t, svc, ctx, series.ID).Occurrences {
+			if o.Date == date {
+				b, err := bookings.GetByID(ctx, *o.BookingID)
+				require.NoError(t, err)
+				return b
+			}
+		}
+		t.Fatalf("no occurrence on %s", date)
+		return nil
+	}
+	assert.Equal(t, 60000.0, *status(day(0)).Amount)
+	assert.Equal(t, 80000.0, *status(day(1)).Amount)
+	assert.Equal(t, "gate B", *status(day(1)).Notes)
+	assert.Equal(t, model.BookingCancelled, status(day(2)).Status)
+
+	require.NoError(t, svc.SkipOccurrence(ctx, series.ID, day(0), admin))
+	assert.Equal(t, model.BookingCancelled, status(day(0)).Status)
+	assert.ErrorIs(t, svc.SkipOccurrence(ctx, series.ID, day(0), admin), ErrNoOccurrence)
+
+	cancelled, err := svc.Cancel(ctx, series.ID, admin, model.RecurringCancel{From: day(1)})
+	require.NoError(t, err)
+	assert.Equal(t, day(0), *cancelled.Until)
+	assert.Equal(t, model.BookingCancelled, status(day(1)).Status)
+
+	series.Until = &detail.Occurrences[0].Date
+	series.StartAt = first.AddDate(0, 0, 1)
+	assert.ErrorIs(t, svc.Update(ctx, series, admin), ErrInvalidRecurrence)
+}
+
+func mustDetail(t *testing.T, svc *RecurringService, ctx context.Context, id int) *model.RecurringBookingDetail {
+	detail, err := svc.GetByID(ctx, id)
+	require.NoError(t, err)
+	return detail
+}
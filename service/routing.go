@@ -0,0 +1,387 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/utils"
+	"bufio"
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoRoute      = errors.New("no road route between these points")
+	ErrTripTooShort = errors.New("trip distance is shorter than the road route between its ends")
+)
+
+// Router estimates the road distance and driving time between two points for
+// a departure time.
+type Router interface {
+	Route(ctx context.Context, from, to model.GeoPoint, departAt time.Time) (*model.Route, error)
+}
+
+// SpeedProfile gives the free-flow speed in km/h of each road class and, for
+// each hour of the day, the share of it that traffic allows.
+type SpeedProfile struct {
+	Speeds  map[string]float64
+	Traffic [24]float64
+}
+
+const (
+	defaultRoadSpeed = 25.0
+	accessRoadClass  = "service"
+	defaultMaxSnapKM = 2.0
+	graphCellDeg     = 0.01
+
+	// A reported trip may come in under its road route by this share to
+	// allow for roads missing from the graph.
+	minTripRouteShare = 0.8
+)
+
+// DefaultSpeedProfile uses OpenStreetMap highway classes, with traffic
+// halving speeds in the morning and evening peaks and free-flowing at night.
+func DefaultSpeedProfile() SpeedProfile {
+	p := SpeedProfile{Speeds: map[string]float64{
+		"motorway":    80,
+		"trunk":       60,
+		"primary":     45,
+		"secondary":   35,
+		"tertiary":    30,
+		"residential": 20,
+		"service":     15,
+	}}
+	for h := range p.Traffic {
+		switch {
+		case h >= 6 && h < 10, h >= 16 && h < 20:
+			p.Traffic[h] = 0.5
+		case h >= 22 || h < 5:
+			p.Traffic[h] = 1
+		default:
+			p.Traffic[h] = 0.75
+		}
+	}
+	return p
+}
+
+// Speed is the expected speed in km/h on a road of class at the hour of at.
+func (p SpeedProfile) Speed(class string, at time.Time) float64 {
+	speed, ok := p.Speeds[class]
+	if !ok {
+		speed = defaultRoadSpeed
+	}
+	if f := p.Traffic[at.Hour()]; f > 0 {
+		speed *= f
+	}
+	return speed
+}
+
+type roadEdge struct {
+	to     int32
+	meters float64
+	class  uint8
+}
+
+// RoadGraph routes over a local road network, picking the fastest path for
+// the time of day. Points are joined to the network at their nearest node no
+// further than MaxSnapKM away. Hours are read in Location, or in
+// Western Indonesian Time when it is nil.
+type RoadGraph struct {
+	Profile   SpeedProfile
+	MaxSnapKM float64
+	Location  *time.Location
+
+	lat, lng []float64
+	edges    [][]roadEdge
+	classes  []string
+	cells    map[[2]int32][]int32
+}
+
+// LoadRoadGraph reads a road network converted from an OpenStreetMap
+// extract. Each line is one record, fields separated by spaces:
+//
+//	n <node id> <lat> <lng>
+//	w <highway class> <oneway 0|1> <node id> <node id> ...
+//	s <highway class> <km/h>
+//	t <hour> <share of free-flow speed>
+//
+// Nodes must come before the ways that use them. s and t lines override the
+// default speed profile; blank lines and lines starting with # are skipped.
+func LoadRoadGraph(r io.Reader) (*RoadGraph, error) {
+	g := &RoadGraph{Profile: DefaultSpeedProfile(), MaxSnapKM: defaultMaxSnapKM, cells: map[[2]int32][]int32{}}
+	nodes := map[int64]int32{}
+	classes := map[string]uint8{}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		f := strings.Fields(sc.Text())
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		if err := g.record(f, nodes, classes); err != nil {
+			return nil, fmt.Errorf("road graph line %d: %w", line, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *RoadGraph) record(f []string, nodes map[int64]int32, classes map[string]uint8) error {
+	switch {
+	case f[0] == "n" && len(f) == 4:
+		id, err := strconv.ParseInt(f[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid node id %q", f[1])
+		}
+		lat, latErr := strconv.ParseFloat(f[2], 64)
+		lng, lngErr := strconv.ParseFloat(f[3], 64)
+		if latErr != nil || lngErr != nil || !utils.ValidCoordinates(lat, lng) {
+			return ErrInvalidLocation
+		}
+		if _, ok := nodes[id]; ok {
+			return fmt.Errorf("duplicate node %d", id)
+		}
+
+		n := int32(len(g.lat))
+		nodes[id] = n
+		g.lat, g.lng = append(g.lat, lat), append(g.lng, lng)
+		g.edges = append(g.edges, nil)
+		cell := graphCell(lat, lng)
+		g.cells[cell] = append(g.cells[cell], n)
+
+	case f[0] == "w" && len(f) >= 5:
+		class, ok := classes[f[1]]
+		if !ok {
+			if len(g.classes) == math.MaxUint8+1 {
+				return errors.New("too many road classes")
+			}
+			class = uint8(len(g.classes))
+			classes[f[1]] = class
+			g.classes = append(g.classes, f[1])
+		}
+		oneway := f[2] == "1"
+
+		prev := int32(-1)
+		for _, raw := range f[3:] {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid node id %q", raw)
+			}
+			n, ok := nodes[id]
+			if !ok {
+				return fmt.Errorf("unknown node %d", id)
+			}
+			if prev >= 0 {
+				meters := utils.HaversineKM(g.lat[prev], g.lng[prev], g.lat[n], g.lng[n]) * 1000
+				g.edges[prev] = append(g.edges[prev], roadEdge{to: n, meters: meters, class: class})
+				if !oneway {
+					g.edges[n] = append(g.edges[n], roadEdge{to: prev, meters: meters, class: class})
+				}
+			}
+			prev = n
+		}
+
+	case f[0] == "s" && len(f) == 3:
+		speed, err := strconv.ParseFloat(f[2], 64)
+		if err != nil || speed <= 0 {
+			return fmt.Errorf("invalid speed %q", f[2])
+		}
+		g.Profile.Speeds[f[1]] = speed
+
+	case f[0] == "t" && len(f) == 3:
+		hour, err := strconv.Atoi(f[1])
+		if err != nil || hour < 0 || hour > 23 {
+			return fmt.Errorf("invalid hour %q", f[1])
+		}
+		share, err := strconv.ParseFloat(f[2], 64)
+		if err != nil || share <= 0 {
+			return fmt.Errorf("invalid traffic share %q", f[2])
+		}
+		g.Profile.Traffic[hour] = share
+
+	default:
+		return fmt.Errorf("unrecognised record %q", f[0])
+	}
+	return nil
+}
+
+func graphCell(lat, lng float64) [2]int32 {
+	return [2]int32{int32(math.Floor(lat / graphCellDeg)), int32(math.Floor(lng / graphCellDeg))}
+}
+
+// snap finds the node nearest p within MaxSnapKM and how far away it is.
+func (g *RoadGraph) snap(p model.GeoPoint) (int32, float64, bool) {
+	minLat, minLng, maxLat, maxLng := utils.BoundingBox(p.Lat, p.Lng, g.MaxSnapKM)
+	lo, hi := graphCell(minLat, minLng), graphCell(maxLat, maxLng)
+
+	best, bestKM := int32(-1), math.Inf(1)
+	for i := lo[0]; i <= hi[0]; i++ {
+		for j := lo[1]; j <= hi[1]; j++ {
+			for _, n := range g.cells[[2]int32{i, j}] {
+				if d := utils.HaversineKM(p.Lat, p.Lng, g.lat[n], g.lng[n]); d < bestKM {
+					best, bestKM = n, d
+				}
+			}
+		}
+	}
+	return best, bestKM, best >= 0 && bestKM <= g.MaxSnapKM
+}
+
+func (g *RoadGraph) hourOf(t time.Time) time.Time {
+	if g.Location != nil {
+		return t.In(g.Location)
+	}
+	return t.In(wib)
+}
+
+// Route finds the fastest path, timing each road at the hour the vehicle
+// reaches it. The legs between the points and the network are driven at
+// service road speed.
+func (g *RoadGraph) Route(ctx context.Context, from, to model.GeoPoint, departAt time.Time) (*model.Route, error) {
+	if !utils.ValidCoordinates(from.Lat, from.Lng) || !utils.ValidCoordinates(to.Lat, to.Lng) {
+		return nil, ErrInvalidLocation
+	}
+	start, startKM, ok := g.snap(from)
+	if !ok {
+		return nil, ErrNoRoute
+	}
+	end, endKM, ok := g.snap(to)
+	if !ok {
+		return nil, ErrNoRoute
+	}
+
+	accessMinutes := func(km float64, at time.Time) float64 {
+		return km / g.Profile.Speed(accessRoadClass, g.hourOf(at)) * 60
+	}
+
+	n := len(g.lat)
+	minutes := make([]float64, n)
+	meters := make([]float64, n)
+	prev := make([]int32, n)
+	for i := range minutes {
+		minutes[i] = math.Inf(1)
+		prev[i] = -1
+	}
+	minutes[start] = accessMinutes(startKM, departAt)
+
+	queue := &routeQueue{{node: start, minutes: minutes[start]}}
+	for pops := 0; queue.Len() > 0; pops++ {
+		item := heap.Pop(queue).(routeItem)
+		if item.minutes > minutes[item.node] {
+			continue
+		}
+		if item.node == end {
+			break
+		}
+		if pops%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		at := g.hourOf(departAt.Add(time.Duration(item.minutes * float64(time.Minute))))
+		for _, e := range g.edges[item.node] {
+			m := item.minutes + e.meters/1000/g.Profile.Speed(g.classes[e.class], at)*60
+			if m < minutes[e.to] {
+				minutes[e.to], meters[e.to], prev[e.to] = m, meters[item.node]+e.meters, item.node
+				heap.Push(queue, routeItem{node: e.to, minutes: m})
+			}
+		}
+	}
+	if math.IsInf(minutes[end], 1) {
+		return nil, ErrNoRoute
+	}
+
+	var path []model.GeoPoint
+	for n := end; n >= 0; n = prev[n] {
+		path = append(path, model.GeoPoint{Lat: g.lat[n], Lng: g.lng[n]})
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	total := minutes[end] + accessMinutes(endKM, departAt.Add(time.Duration(minutes[end]*float64(time.Minute))))
+	return &model.Route{
+		DistanceKM:      math.Round((meters[end]/1000+startKM+endKM)*10) / 10,
+		DurationMinutes: math.Round(total*10) / 10,
+		Path:            path,
+	}, nil
+}
+
+type routeItem struct {
+	node    int32
+	minutes float64
+}
+
+type routeQueue []routeItem
+
+func (q routeQueue) Len() int           { return len(q) }
+func (q routeQueue) Less(i, j int) bool { return q[i].minutes < q[j].minutes }
+func (q routeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x any)        { *q = append(*q, x.(routeItem)) }
+func (q *routeQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// routeLegs routes each leg between consecutive points, departing on each as
+// the previous one arrives.
+func routeLegs(ctx context.Context, r Router, points []model.GeoPoint, departAt time.Time) ([]model.Route, error) {
+	legs := make([]model.Route, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		leg, err := r.Route(ctx, points[i-1], points[i], departAt)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, model.Route{DistanceKM: leg.DistanceKM, DurationMinutes: leg.DurationMinutes})
+		departAt = departAt.Add(time.Duration(leg.DurationMinutes * float64(time.Minute)))
+	}
+	return legs, nil
+}
+
+// routeTrip checks a trip against the road route between its ends, through
+// any via points in order: a trip without a distance gets the route's, and one
+// reported much shorter than the road allows is rejected. Trips missing either
+// end, or between points the router cannot connect, are left as they are.
+func routeTrip(ctx context.Context, r Router, t *model.VehicleTrip, via []model.GeoPoint) error {
+	if r == nil || t.OriginLat == nil || t.DestinationLat == nil {
+		return nil
+	}
+	depart := t.TripDate
+	if t.StartedAt != nil {
+		depart = *t.StartedAt
+	}
+
+	points := append([]model.GeoPoint{{Lat: *t.OriginLat, Lng: *t.OriginLng}}, via...)
+	points = append(points, model.GeoPoint{Lat: *t.DestinationLat, Lng: *t.DestinationLng})
+	legs, err := routeLegs(ctx, r, points, depart)
+	if errors.Is(err, ErrNoRoute) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var routeKM float64
+	for _, leg := range legs {
+		routeKM += leg.DistanceKM
+	}
+
+	if t.DistanceKM == 0 {
+		t.DistanceKM = int(math.Round(routeKM))
+		return nil
+	}
+	if float64(t.DistanceKM) < math.Floor(routeKM*minTripRouteShare) {
+		return ErrTripTooShort
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testRoads has a residential street from 1 to 3 with a faster but longer
+// motorway around it, a side road from 6 joining at 2 and a one-way road
+// from 8 to 9 on its own.
+const testRoads = `# test network
+n 1 -6.200 106.800
+n 2 -6.200 106.810
+n 3 -6.200 106.820
+n 4 -6.190 106.800
+n 5 -6.190 106.820
+n 6 -6.210 106.800
+n 7 -6.210 106.810
+n 8 -6.300 106.900
+n 9 -6.300 106.910
+
+w residential 0 1 2 3
+w motorway 0 1 4 5 3
+w secondary 0 6 7 2
+w primary 1 8 9
+`
+
+func testRoadGraph(t *testing.T, extra string) *service.RoadGraph {
+	g, err := service.LoadRoadGraph(strings.NewReader(testRoads + extra))
+	require.NoError(t, err)
+	return g
+}
+
+func TestRoadGraph_Route(t *testing.T) {
+	g := testRoadGraph(t, "")
+	ctx := context.Background()
+	from, to := model.GeoPoint{Lat: -6.2, Lng: 106.8}, model.GeoPoint{Lat: -6.2, Lng: 106.82}
+
+	night, err := g.Route(ctx, from, to, wibAt(23))
+	require.NoError(t, err)
+	assert.Equal(t, 4.4, night.DistanceKM)
+	assert.InDelta(t, 3.3, night.DurationMinutes, 0.1)
+	assert.Len(t, night.Path, 4)
+
+	rush, err := g.Route(ctx, from, to, wibAt(8))
+	require.NoError(t, err)
+	assert.Equal(t, 4.4, rush.DistanceKM)
+	assert.InDelta(t, 6.65, rush.DurationMinutes, 0.1)
+
+	utc, err := g.Route(ctx, from, to, at(1))
+	require.NoError(t, err)
+	assert.Equal(t, rush.DurationMinutes, utc.DurationMinutes)
+
+	near := model.GeoPoint{Lat: -6.2009, Lng: 106.8}
+	access, err := g.Route(ctx, near, to, wibAt(23))
+	require.NoError(t, err)
+	assert.Equal(t, 4.5, access.DistanceKM)
+	assert.Greater(t, access.DurationMinutes, night.DurationMinutes)
+
+	_, err = g.Route(ctx, model.GeoPoint{Lat: -6.3, Lng: 106.9}, model.GeoPoint{Lat: -6.3, Lng: 106.91}, wibAt(9))
+	assert.NoError(t, err)
+	_, err = g.Route(ctx, model.GeoPoint{Lat: -6.3, Lng: 106.91}, model.GeoPoint{Lat: -6.3, Lng: 106.9}, wibAt(9))
+	assert.ErrorIs(t, err, service.ErrNoRoute)
+	_, err = g.Route(ctx, from, model.GeoPoint{Lat: -7, Lng: 107}, wibAt(9))
+	assert.ErrorIs(t, err, service.ErrNoRoute)
+	_, err = g.Route(ctx, from, model.GeoPoint{Lat: -95, Lng: 107}, wibAt(9))
+	assert.ErrorIs(t, err, service.ErrInvalidLocation)
+}
+
+func TestRoadGraph_SpeedProfile(t *testing.T) {
+	g := testRoadGraph(t, "s residential 90\nt 23 0.5\n")
+
+	route, err := g.Route(context.Background(), model.GeoPoint{Lat: -6.2, Lng: 106.8}, model.GeoPoint{Lat: -6.2, Lng: 106.82}, wibAt(23))
+	require.NoError(t, err)
+	assert.Equal(t, 2.2, route.DistanceKM)
+	assert.InDelta(t, 2.95, route.DurationMinutes, 0.1)
+}
+
+func TestLoadRoadGraph_Errors(t *testing.T) {
+	for input, want := range map[string]string{
+		"n 1 -6.2 106.8\nw residential 0 1 2\n": "line 2: unknown node 2",
+		"n 1 -6.2\n":                            "line 1: unrecognised record",
+		"n 1 -6.2 106.8\nn 1 -6.3 106.8\n":      "line 2: duplicate node 1",
+		"t 24 0.5\n":                            "line 1: invalid hour",
+		"s motorway fast\n":                     "line 1: invalid speed",
+	} {
+		_, err := service.LoadRoadGraph(strings.NewReader(input))
+		assert.ErrorContains(t, err, want, input)
+	}
+}
+
+func TestPricingService_QuoteByRoute(t *testing.T) {
+	repo := new(MockPricingRepository)
+	svc := service.NewPricingService(repo, nil, 15*time.Minute)
+	repo.On("GetTariff", "mpv").Return(&model.Tariff{CarTypeID: "mpv", BaseFare: 20000, PerKM: 4000, PerMinute: 500}, nil)
+	repo.On("CreateQuote", mock.Anything).Return(nil)
+
+	req := model.QuoteRequest{CarTypeID: "mpv", PickupAt: wibAt(8),
+		Pickup: &model.GeoPoint{Lat: -6.2, Lng: 106.8}, Drop: &model.GeoPoint{Lat: -6.2, Lng: 106.82}}
+	_, err := svc.Quote(context.Background(), req)
+	assert.ErrorIs(t, err, service.ErrNoDistance)
+
+	svc.Router = testRoadGraph(t, "")
+	q, err := svc.Quote(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 4.4, q.DistanceKM)
+	assert.InDelta(t, 6.65, q.DurationMinutes, 0.1)
+
+	req.DistanceKM = 5
+	q, err = svc.Quote(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, q.DistanceKM)
+	assert.InDelta(t, 6.65, q.DurationMinutes, 0.1)
+
+	req.Drop = &model.GeoPoint{Lat: -7, Lng: 107}
+	req.DistanceKM = 0
+	_, err = svc.Quote(context.Background(), req)
+	assert.ErrorIs(t, err, service.ErrNoRoute)
+}
+
+func TestPricingService_QuoteThroughStops(t *testing.T) {
+	repo := new(MockPricingRepository)
+	svc := service.NewPricingService(repo, nil, 15*time.Minute)
+	svc.Router = testRoadGraph(t, "")
+	repo.On("GetTariff", "mpv").Return(&model.Tariff{CarTypeID: "mpv", BaseFare: 20000, PerKM: 4000, PerMinute: 500, PerStop: 10000}, nil)
+	repo.On("CreateQuote", mock.Anything).Return(nil)
+
+	req := model.QuoteRequest{CarTypeID: "mpv", PickupAt: wibAt(23),
+		Pickup: &model.GeoPoint{Lat: -6.2, Lng: 106.8}, Drop: &model.GeoPoint{Lat: -6.2, Lng: 106.82},
+		Stops: []model.QuoteStop{{Point: &model.GeoPoint{Lat: -6.21, Lng: 106.8}, WaitMinutes: 15}}}
+	q, err := svc.Quote(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, q.Legs, 2)
+	assert.InDelta(t, q.Legs[0].DistanceKM+q.Legs[1].DistanceKM, q.DistanceKM, 0.1)
+	assert.Greater(t, q.DistanceKM, 4.4)
+	assert.Equal(t, 1, q.Stops)
+	assert.Equal(t, 15, q.WaitMinutes)
+	items := quoteCodes(q)
+	assert.Equal(t, 10000.0, items[model.QuoteItemStops])
+	assert.Equal(t, 7500.0, items[model.QuoteItemWaiting])
+
+	req.Stops[0].Point = nil
+	_, err = svc.Quote(context.Background(), req)
+	assert.ErrorIs(t, err, service.ErrNoDistance)
+}
+
+func TestDispatchService_CandidatesByRoad(t *testing.T) {
+	f := newDispatchFixture()
+	f.svc.Router = testRoadGraph(t, "")
+	f.bookings.On("GetByID", "BK1").Return(f.booking, nil)
+
+	candidates, err := f.svc.Candidates(context.Background(), "BK1")
+	require.NoError(t, err)
+	require.Len(t, candidates, 3)
+	assert.Equal(t, 2, candidates[0].DriverID)
+	assert.Equal(t, 3.3, *candidates[0].DistanceKM)
+	require.NotNil(t, candidates[0].ETAMinutes)
+
+	assert.Equal(t, 1, candidates[1].DriverID)
+	assert.InDelta(t, 33, *candidates[1].DistanceKM, 1)
+	assert.Nil(t, candidates[1].ETAMinutes)
+}
+
+func TestTripService_ValidatesRoute(t *testing.T) {
+	ctx := utils.WithTenant(context.Background(), 1)
+	svc := service.NewTripService(repository.NewMemoryTripRepo(repository.NewMemoryStore()))
+	svc.Router = testRoadGraph(t, "")
+
+	lat, lng, destLng := -6.2, 106.8, 106.82
+	newTrip := func(distance int) *model.VehicleTrip {
+		return &model.VehicleTrip{TripDate: wibAt(23), Origin: "A", Destination: "B", DistanceKM: distance,
+			OriginLat: &lat, OriginLng: &lng, DestinationLat: &lat, DestinationLng: &destLng}
+	}
+
+	trip := newTrip(0)
+	require.NoError(t, svc.Create(ctx, trip))
+	assert.Equal(t, 4, trip.DistanceKM)
+
+	assert.ErrorIs(t, svc.Create(ctx, newTrip(1)), service.ErrTripTooShort)
+	assert.NoError(t, svc.Create(ctx, newTrip(9)))
+
+	farLat, farLng := -7.0, 107.0
+	unroutable := newTrip(0)
+	unroutable.DestinationLat, unroutable.DestinationLng = &farLat, &farLng
+	require.NoError(t, svc.Create(ctx, unroutable))
+	assert.InDelta(t, 95, unroutable.DistanceKM, 10)
+}
+
+func TestBookingService_CompleteChecksRoute(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := utils.WithTenant(context.Background(), 1)
+	svc := &service.BookingService{Repo: repository.NewMemoryBookingRepository(store), Trips: repository.NewMemoryTripRepo(store),
+		Router: testRoadGraph(t, "")}
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+
+	lat, lng, dropLng := -6.2, 106.8, 106.82
+	b := &model.Booking{Customer: "Sari", PickupLat: &lat, PickupLng: &lng, DropLat: &lat, DropLng: &dropLng}
+	require.NoError(t, svc.Create(ctx, b))
+	assert.Equal(t, 2.2, *b.DistanceKM)
+	for _, to := range []string{model.BookingConfirmed, model.BookingOnTrip} {
+		stored, err := svc.GetByID(ctx, b.ID)
+		require.NoError(t, err)
+		stored.Status = to
+		require.NoError(t, svc.Repo.Transition(ctx, stored, &model.BookingStatusChange{ToStatus: to}))
+	}
+
+	short := 1
+	_, err := svc.Transition(ctx, b.ID, admin, model.BookingTransition{To: model.BookingCompleted, DistanceKM: &short})
+	assert.ErrorIs(t, err, service.ErrTripTooShort)
+
+	_, err = svc.Transition(ctx, b.ID, admin, model.BookingTransition{To: model.BookingCompleted})
+	require.NoError(t, err)
+	trip, err := svc.GetTrip(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, trip.DistanceKM)
+}
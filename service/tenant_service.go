@@ -0,0 +1,56 @@
+package service
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/utils"
+	"context"
+	"errors"
+)
+
+var (
+	ErrTenantNotFound     = errors.New("tenant not found")
+	ErrSuperAdminRequired = errors.New("super admin role required")
+)
+
+type TenantServiceInterface interface {
+	GetAll(ctx context.Context) ([]model.Tenant, error)
+	Create(ctx context.Context, t *model.Tenant) error
+	SwitchTenant(ctx context.Context, user *model.User, tenantID int64) (string, error)
+}
+
+type TenantService struct {
+	Repo                repository.TenantRepositoryInterface
+	GenerateAccessToken func(userID, tenantID int64, role string, driverID *int) (string, error)
+}
+
+func NewTenantService(repo repository.TenantRepositoryInterface) *TenantService {
+	return &TenantService{
+		Repo:                repo,
+		GenerateAccessToken: utils.GenerateAccessToken,
+	}
+}
+
+func (s *TenantService) GetAll(ctx context.Context) ([]model.Tenant, error) {
+	return s.Repo.GetAll(ctx)
+}
+
+func (s *TenantService) Create(ctx context.Context, t *model.Tenant) error {
+	return s.Repo.Create(ctx, t)
+}
+
+func (s *TenantService) SwitchTenant(ctx context.Context, user *model.User, tenantID int64) (string, error) {
+	if user == nil || !user.IsSuperAdmin() {
+		return "", ErrSuperAdminRequired
+	}
+
+	tenant, err := s.Repo.GetByID(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	if tenant == nil {
+		return "", ErrTenantNotFound
+	}
+
+	return s.GenerateAccessToken(user.ID, tenant.ID, user.Role, user.DriverID)
+}
@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/service"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTenantRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantRepository) GetAll(ctx context.Context) ([]model.Tenant, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) GetByID(ctx context.Context, id int64) (*model.Tenant, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) Create(ctx context.Context, t *model.Tenant) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func TestTenantService_SwitchTenant(t *testing.T) {
+	superAdmin := &model.User{ID: 1, Role: model.RoleSuperAdmin, TenantID: 1}
+
+	t.Run("requires super admin", func(t *testing.T) {
+		repo := new(MockTenantRepository)
+		svc := service.NewTenantService(repo)
+
+		_, err := svc.SwitchTenant(context.Background(), &model.User{ID: 2, Role: model.RoleAdmin}, 2)
+		assert.ErrorIs(t, err, service.ErrSuperAdminRequired)
+		repo.AssertNotCalled(t, "GetByID", int64(2))
+	})
+
+	t.Run("tenant not found", func(t *testing.T) {
+		repo := new(MockTenantRepository)
+		svc := service.NewTenantService(repo)
+		repo.On("GetByID", int64(9)).Return(nil, nil)
+
+		_, err := svc.SwitchTenant(context.Background(), superAdmin, 9)
+		assert.ErrorIs(t, err, service.ErrTenantNotFound)
+	})
+
+	t.Run("issues token for target tenant", func(t *testing.T) {
+		repo := new(MockTenantRepository)
+		svc := service.NewTenantService(repo)
+		repo.On("GetByID", int64(2)).Return(&model.Tenant{ID: 2, Name: "Acme", Slug: "acme"}, nil)
+
+		var gotTenant int64
+		svc.GenerateAccessToken = func(userID, tenantID int64, role string, driverID *int) (string, error) {
+			gotTenant = tenantID
+			return "TOKEN", nil
+		}
+
+		token, err := svc.SwitchTenant(context.Background(), superAdmin, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "TOKEN", token)
+		assert.Equal(t, int64(2), gotTenant)
+	})
+}
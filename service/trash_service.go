@@ -0,0 +1,114 @@
+package service
+
+import (
+	"auth-service/repository"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	TrashDrivers  = "drivers"
+	TrashCars     = "car"
+	TrashBookings = "booking"
+	TrashPayments = "payments"
+)
+
+var ErrUnknownEntity = errors.New("unknown entity")
+
+type TrashServiceInterface interface {
+	List(ctx context.Context, entity string) (interface{}, error)
+	Restore(ctx context.Context, entity, id string) error
+	Purge(ctx context.Context) (map[string]int64, error)
+}
+
+type TrashService struct {
+	Drivers   repository.DriverRepositoryInterface
+	Cars      repository.CarRepositoryInterface
+	Bookings  repository.BookingRepositoryInterface
+	Payments  repository.PaymentRepositoryInterface
+	Retention time.Duration
+	Now       func() time.Time
+}
+
+func NewTrashService(
+	drivers repository.DriverRepositoryInterface,
+	cars repository.CarRepositoryInterface,
+	bookings repository.BookingRepositoryInterface,
+	payments repository.PaymentRepositoryInterface,
+	retention time.Duration,
+) *TrashService {
+	return &TrashService{
+		Drivers:   drivers,
+		Cars:      cars,
+		Bookings:  bookings,
+		Payments:  payments,
+		Retention: retention,
+		Now:       time.Now,
+	}
+}
+
+func (s *TrashService) List(ctx context.Context, entity string) (interface{}, error) {
+	switch entity {
+	case TrashDrivers:
+		return s.Drivers.GetDeleted(ctx)
+	case TrashCars:
+		return s.Cars.GetDeleted(ctx)
+	case TrashBookings:
+		return s.Bookings.GetDeleted(ctx)
+	case TrashPayments:
+		return s.Payments.GetDeleted(ctx)
+	}
+	return nil, ErrUnknownEntity
+}
+
+func (s *TrashService) Restore(ctx context.Context, entity, id string) error {
+	switch entity {
+	case TrashDrivers:
+		return s.Drivers.Restore(ctx, id)
+	case TrashBookings:
+		return s.Bookings.Restore(ctx, id)
+	case TrashCars, TrashPayments:
+		numericID, err := strconv.Atoi(id)
+		if err != nil {
+			return ErrInvalidID
+		}
+		if entity == TrashCars {
+			return s.Cars.Restore(ctx, numericID)
+		}
+		return s.Payments.Restore(ctx, numericID)
+	}
+	return ErrUnknownEntity
+}
+
+func (s *TrashService) Purge(ctx context.Context) (map[string]int64, error) {
+	before := s.Now().Add(-s.Retention)
+	purged := map[string]int64{}
+
+	n, err := s.Payments.PurgeDeleted(ctx, before)
+	if err != nil {
+		return purged, err
+	}
+	purged[TrashPayments] = n
+
+	n, err = s.Bookings.PurgeDeleted(ctx, before)
+	if err != nil {
+		return purged, err
+	}
+	purged[TrashBookings] = n
+
+	n, err = s.Cars.PurgeDeleted(ctx, before)
+	if err != nil {
+		return purged, err
+	}
+	purged[TrashCars] = n
+
+	n, err = s.Drivers.PurgeDeleted(ctx, before)
+	if err != nil {
+		return purged, err
+	}
+	purged[TrashDrivers] = n
+
+	return purged, nil
+}
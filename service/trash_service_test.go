@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTrashService() (*service.TrashService, *MockDriverRepository, *MockCarRepository, *MockBookingRepository, *MockPaymentRepository) {
+	drivers := new(MockDriverRepository)
+	cars := new(MockCarRepository)
+	bookings := new(MockBookingRepository)
+	payments := new(MockPaymentRepository)
+	svc := service.NewTrashService(drivers, cars, bookings, payments, 24*time.Hour)
+	return svc, drivers, cars, bookings, payments
+}
+
+func TestTrashService_List(t *testing.T) {
+	svc, _, cars, _, _ := newTrashService()
+
+	expected := []model.Car{{ID: 1, Model: "Model A"}}
+	cars.On("GetDeleted").Return(expected, nil)
+
+	result, err := svc.List(context.Background(), service.TrashCars)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	_, err = svc.List(context.Background(), "users")
+	assert.ErrorIs(t, err, service.ErrUnknownEntity)
+
+	cars.AssertExpectations(t)
+}
+
+func TestTrashService_Restore(t *testing.T) {
+	svc, drivers, _, _, payments := newTrashService()
+	ctx := context.Background()
+
+	drivers.On("Restore", "7").Return(nil)
+	payments.On("Restore", ctx, 3).Return(repository.ErrNotFound)
+
+	assert.NoError(t, svc.Restore(ctx, service.TrashDrivers, "7"))
+	assert.ErrorIs(t, svc.Restore(ctx, service.TrashPayments, "3"), service.ErrNotFound)
+	assert.ErrorIs(t, svc.Restore(ctx, service.TrashCars, "abc"), service.ErrInvalidID)
+	assert.ErrorIs(t, svc.Restore(ctx, "users", "1"), service.ErrUnknownEntity)
+
+	drivers.AssertExpectations(t)
+	payments.AssertExpectations(t)
+}
+
+func TestTrashService_Purge(t *testing.T) {
+	svc, drivers, cars, bookings, payments := newTrashService()
+	now := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	svc.Now = func() time.Time { return now }
+	before := now.Add(-24 * time.Hour)
+	ctx := context.Background()
+
+	payments.On("PurgeDeleted", ctx, before).Return(int64(1), nil)
+	bookings.On("PurgeDeleted", before).Return(int64(2), nil)
+	cars.On("PurgeDeleted", before).Return(int64(0), nil)
+	drivers.On("PurgeDeleted", before).Return(int64(3), nil)
+
+	purged, err := svc.Purge(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		service.TrashPayments: 1,
+		service.TrashBookings: 2,
+		service.TrashCars:     0,
+		service.TrashDrivers:  3,
+	}, purged)
+}
@@ -3,39 +3,69 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"auth-service/utils"
 	"context"
+	"math"
 )
 
 type TripServiceInterface interface {
-	Create(*model.VehicleTrip) error
-	FindByVehicle(uint) ([]model.VehicleTrip, error)
-	Update(*model.VehicleTrip) error
-	Delete(uint) error
+	Create(context.Context, *model.VehicleTrip) error
+	FindByVehicle(context.Context, uint) ([]model.VehicleTrip, error)
+	Update(context.Context, *model.VehicleTrip) error
+	Delete(context.Context, uint) error
 	GetTripTotals(context.Context) (*model.TotalTrips, error)
 }
 
 type TripService struct {
 	repo repository.TripsRepositoryInterface
+
+	// Router is optional; when set, trip distances are checked against and
+	// filled in from the road route.
+	Router Router
 }
 
 func NewTripService(repo repository.TripsRepositoryInterface) *TripService {
 	return &TripService{repo: repo}
 }
 
-func (s *TripService) Create(t *model.VehicleTrip) error {
-	return s.repo.Create(t)
+func (s *TripService) Create(ctx context.Context, t *model.VehicleTrip) error {
+	if err := s.locate(ctx, t); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, t)
+}
+
+func (s *TripService) Update(ctx context.Context, t *model.VehicleTrip) error {
+	if err := s.locate(ctx, t); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, t)
 }
 
-func (s *TripService) Update(t *model.VehicleTrip) error {
-	return s.repo.Update(t)
+// locate validates a trip's coordinates and its distance against the road
+// route. When no distance was recorded it fills in the route's, or else the
+// straight-line distance between its ends.
+func (s *TripService) locate(ctx context.Context, t *model.VehicleTrip) error {
+	for _, p := range [][2]*float64{{t.OriginLat, t.OriginLng}, {t.DestinationLat, t.DestinationLng}} {
+		if (p[0] == nil) != (p[1] == nil) || p[0] != nil && !utils.ValidCoordinates(*p[0], *p[1]) {
+			return ErrInvalidLocation
+		}
+	}
+	if err := routeTrip(ctx, s.Router, t, nil); err != nil {
+		return err
+	}
+	if t.DistanceKM == 0 && t.OriginLat != nil && t.DestinationLat != nil {
+		t.DistanceKM = int(math.Round(utils.HaversineKM(*t.OriginLat, *t.OriginLng, *t.DestinationLat, *t.DestinationLng)))
+	}
+	return nil
 }
 
-func (s *TripService) Delete(id uint) error {
-	return s.repo.Delete(id)
+func (s *TripService) Delete(ctx context.Context, id uint) error {
+	return s.repo.Delete(ctx, id)
 }
 
-func (s *TripService) FindByVehicle(vehicleID uint) ([]model.VehicleTrip, error) {
-	return s.repo.FindByVehicle(vehicleID)
+func (s *TripService) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.VehicleTrip, error) {
+	return s.repo.FindByVehicle(ctx, vehicleID)
 }
 
 func (s *TripService) GetTripTotals(ctx context.Context) (*model.TotalTrips, error) {
@@ -14,22 +14,22 @@ type MockTripsRepositoryImpl struct {
 	mock.Mock
 }
 
-func (m *MockTripsRepositoryImpl) Create(t *model.VehicleTrip) error {
+func (m *MockTripsRepositoryImpl) Create(ctx context.Context, t *model.VehicleTrip) error {
 	args := m.Called(t)
 	return args.Error(0)
 }
 
-func (m *MockTripsRepositoryImpl) Update(t *model.VehicleTrip) error {
+func (m *MockTripsRepositoryImpl) Update(ctx context.Context, t *model.VehicleTrip) error {
 	args := m.Called(t)
 	return args.Error(0)
 }
 
-func (m *MockTripsRepositoryImpl) Delete(id uint) error {
+func (m *MockTripsRepositoryImpl) Delete(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockTripsRepositoryImpl) FindByVehicle(vehicleID uint) ([]model.VehicleTrip, error) {
+func (m *MockTripsRepositoryImpl) FindByVehicle(ctx context.Context, vehicleID uint) ([]model.VehicleTrip, error) {
 	args := m.Called(vehicleID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,6 +37,14 @@ func (m *MockTripsRepositoryImpl) FindByVehicle(vehicleID uint) ([]model.Vehicle
 	return args.Get(0).([]model.VehicleTrip), args.Error(1)
 }
 
+func (m *MockTripsRepositoryImpl) FindByBooking(ctx context.Context, bookingID string) (*model.VehicleTrip, error) {
+	args := m.Called(bookingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.VehicleTrip), args.Error(1)
+}
+
 func (m *MockTripsRepositoryImpl) GetTripTotal(ctx context.Context) (*model.TotalTrips, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -54,7 +62,7 @@ func TestTripService(t *testing.T) {
 
 		repo.On("Create", trip).Return(nil)
 
-		err := svc.Create(trip)
+		err := svc.Create(context.Background(), trip)
 		assert.NoError(t, err)
 	})
 
@@ -64,7 +72,7 @@ func TestTripService(t *testing.T) {
 
 		repo.On("Create", mock.Anything).Return(errors.New("db error"))
 
-		err := svc.Create(trip)
+		err := svc.Create(context.Background(), trip)
 		assert.Error(t, err)
 	})
 
@@ -74,7 +82,7 @@ func TestTripService(t *testing.T) {
 
 		repo.On("Update", trip).Return(nil)
 
-		err := svc.Update(trip)
+		err := svc.Update(context.Background(), trip)
 		assert.NoError(t, err)
 	})
 
@@ -84,7 +92,7 @@ func TestTripService(t *testing.T) {
 
 		repo.On("Update", mock.Anything).Return(errors.New("db error"))
 
-		err := svc.Update(trip)
+		err := svc.Update(context.Background(), trip)
 		assert.Error(t, err)
 	})
 
@@ -94,7 +102,7 @@ func TestTripService(t *testing.T) {
 
 		repo.On("Delete", uint(1)).Return(nil)
 
-		err := svc.Delete(1)
+		err := svc.Delete(context.Background(), 1)
 		assert.NoError(t, err)
 	})
 
@@ -104,7 +112,7 @@ func TestTripService(t *testing.T) {
 
 		repo.On("Delete", uint(1)).Return(errors.New("db error"))
 
-		err := svc.Delete(1)
+		err := svc.Delete(context.Background(), 1)
 		assert.Error(t, err)
 	})
 
@@ -115,7 +123,7 @@ func TestTripService(t *testing.T) {
 		repo.On("FindByVehicle", uint(1)).
 			Return([]model.VehicleTrip{*trip}, nil)
 
-		result, err := svc.FindByVehicle(1)
+		result, err := svc.FindByVehicle(context.Background(), 1)
 		assert.NoError(t, err)
 		assert.Len(t, result, 1)
 	})
@@ -127,7 +135,7 @@ func TestTripService(t *testing.T) {
 		repo.On("FindByVehicle", uint(1)).
 			Return(nil, errors.New("db error"))
 
-		result, err := svc.FindByVehicle(1)
+		result, err := svc.FindByVehicle(context.Background(), 1)
 		assert.Error(t, err)
 		assert.Nil(t, result)
 	})
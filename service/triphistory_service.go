@@ -3,21 +3,22 @@ package service
 import (
 	"auth-service/model"
 	"auth-service/repository"
+	"context"
 )
 
 type TripHistoryServiceInterface interface {
-	GetTripHistory() ([]model.TripHistory, error)
+	GetTripHistory(ctx context.Context) ([]model.TripHistory, error)
 }
 
 type TripHistoryMockService interface {
-	GetTripHistory() ([]model.TripHistory, error)
+	GetTripHistory(ctx context.Context) ([]model.TripHistory, error)
 }
 
 type MockTripHistoryService struct {
 	ReturnError bool
 }
 
-func (m *MockTripHistoryService) GetTripHistory() ([]model.TripHistory, error) {
+func (m *MockTripHistoryService) GetTripHistory(ctx context.Context) ([]model.TripHistory, error) {
 	return []model.TripHistory{}, nil
 }
 
@@ -29,6 +30,6 @@ func NewTripHistoryService(repo repository.TripHistoryRepositoryInterface) *Trip
 	return &TripHistoryService{Repo: repo}
 }
 
-func (s *TripHistoryService) GetTripHistory() ([]model.TripHistory, error) {
-	return s.Repo.GetTripHistory()
+func (s *TripHistoryService) GetTripHistory(ctx context.Context) ([]model.TripHistory, error) {
+	return s.Repo.GetTripHistory(ctx)
 }
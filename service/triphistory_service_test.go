@@ -3,6 +3,7 @@ package service_test
 import (
 	"auth-service/model"
 	"auth-service/service"
+	"context"
 	"errors"
 	"testing"
 
@@ -14,12 +15,12 @@ type MockTripHistoryRepository struct {
 	mock.Mock
 }
 
-func (m *MockTripHistoryRepository) GetTripHistory() ([]model.TripHistory, error) {
+func (m *MockTripHistoryRepository) GetTripHistory(ctx context.Context) ([]model.TripHistory, error) {
 	args := m.Called()
 	return args.Get(0).([]model.TripHistory), args.Error(1)
 }
 
-func (m *MockTripHistoryRepository) GetBookingByCode() (*model.TripHistory, error) {
+func (m *MockTripHistoryRepository) GetBookingByCode(ctx context.Context) (*model.TripHistory, error) {
 	args := m.Called()
 	return args.Get(0).(*model.TripHistory), args.Error(1)
 }
@@ -27,7 +28,7 @@ func (m *MockTripHistoryRepository) GetBookingByCode() (*model.TripHistory, erro
 func TestMockTripHistoryService_GetTripHistory(t *testing.T) {
 	mockService := &service.MockTripHistoryService{}
 
-	result, err := mockService.GetTripHistory()
+	result, err := mockService.GetTripHistory(context.Background())
 
 	assert.NoError(t, err)
 	assert.Empty(t, result)
@@ -40,7 +41,7 @@ func TestTripHistoryService_GetTripHistory(t *testing.T) {
 	expected := []model.TripHistory{{ID: 1, CustomerName: "Trip A"}}
 	mockRepo.On("GetTripHistory").Return(expected, nil)
 
-	result, err := svc.GetTripHistory()
+	result, err := svc.GetTripHistory(context.Background())
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 
@@ -54,7 +55,7 @@ func TestTripHistoryService_GetTripHistory_Error(t *testing.T) {
 	expectedErr := errors.New("db error")
 	mockRepo.On("GetTripHistory").Return([]model.TripHistory(nil), expectedErr)
 
-	result, err := svc.GetTripHistory()
+	result, err := svc.GetTripHistory(context.Background())
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, expectedErr, err)
@@ -68,7 +69,7 @@ func TestTripHistoryService_GetTripHistory_Empty(t *testing.T) {
 
 	mockRepo.On("GetTripHistory").Return(([]model.TripHistory)(nil), nil)
 
-	result, err := svc.GetTripHistory()
+	result, err := svc.GetTripHistory(context.Background())
 	assert.NoError(t, err)
 	assert.Empty(t, result)
 	mockRepo.AssertExpectations(t)
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"auth-service/model"
+	"auth-service/repository"
+	"auth-service/service"
+	"auth-service/utils"
+	"database/sql"
+	"fmt"
+)
+
+const (
+	storagePostgres = "postgres"
+	storageMemory   = "memory"
+)
+
+type repositories struct {
+	Users         repository.UserRepository
+	Tokens        repository.TokenRepository
+	Tenants       repository.TenantRepositoryInterface
+	Customers     repository.CustomerRepositoryInterface
+	Drivers       repository.DriverRepositoryInterface
+	Availability  repository.AvailabilityRepositoryInterface
+	Dispatch      repository.DispatchRepositoryInterface
+	Pricing       repository.PricingRepositoryInterface
+	Promotions    repository.PromotionRepositoryInterface
+	Recurring     repository.RecurringBookingRepositoryInterface
+	Cancellations repository.CancellationRepositoryInterface
+	Notifications repository.NotificationRepositoryInterface
+	Cars          repository.CarRepositoryInterface
+	Bookings      repository.BookingRepositoryInterface
+	Payments      repository.PaymentRepositoryInterface
+	Trips         repository.TripsRepositoryInterface
+	TripHistory   repository.TripHistoryRepositoryInterface
+	Assignments   repository.AssignmentsRepositoryInterface
+	Maintenance   repository.MaintenanceRepositoryInterface
+	CarModels     repository.CarModelRepositoryInterface
+	CarTypes      repository.CarTypeRepositoryInterface
+	Popular       repository.PopularDestinationRepositoryInterface
+	BookingTrends repository.BookingTrendsRepositoryInterface
+	Dashboard     repository.DashboardRepositoryInterface
+	DashboardTrip repository.DashboardTripRepositoryInterface
+	PDF           repository.PDFRepositoryInterface
+	Idempotency   repository.IdempotencyRepositoryInterface
+	Feedback      repository.FeedbackRepositoryInterface
+	Corporate     repository.CorporateRepositoryInterface
+	Calendar      repository.CalendarRepositoryInterface
+	Geocoder      service.Geocoder
+	Router        service.Router
+	// FeedbackURL is where the feedback links sent after a trip point;
+	// RatingAlertEmail receives driver rating alerts.
+	FeedbackURL      string
+	RatingAlertEmail string
+	// PaymentProviders collect payments by method through the payment
+	// gateway, whose callbacks are signed with PaymentCallbackSecret.
+	PaymentProviders      map[string]service.PaymentProvider
+	PaymentCallbackSecret string
+}
+
+func postgresRepositories(db *sql.DB) *repositories {
+	return &repositories{
+		Users:         &repository.UserRepositoryImpl{DB: db},
+		Tokens:        &repository.TokenRepositoryImpl{DB: db},
+		Tenants:       repository.NewTenantRepository(db),
+		Customers:     repository.NewCustomerRepository(db),
+		Drivers:       &repository.DriverRepository{DB: db},
+		Availability:  repository.NewAvailabilityRepository(db),
+		Dispatch:      repository.NewDispatchRepository(db),
+		Pricing:       repository.NewPricingRepository(db),
+		Promotions:    repository.NewPromotionRepository(db),
+		Recurring:     repository.NewRecurringBookingRepository(db),
+		Cancellations: repository.NewCancellationRepository(db),
+		Notifications: repository.NewNotificationRepository(db),
+		Cars:          repository.NewCarRepository(db),
+		Bookings:      &repository.BookingRepository{DB: db},
+		Payments:      repository.NewPaymentRepository(db),
+		Trips:         repository.NewTripRepo(db),
+		TripHistory:   repository.NewTripHistoryRepository(db),
+		Assignments:   repository.NewAssignmentsRepository(db),
+		Maintenance:   repository.NewMaintenanceRepository(db),
+		CarModels:     repository.NewCarModelRepository(db),
+		CarTypes:      repository.NewCarTypeRepository(db),
+		Popular:       &repository.PopularDestinationRepository{DB: db},
+		BookingTrends: repository.NewBookingTrendsRepository(db),
+		Dashboard:     repository.NewDashboardRepository(db),
+		DashboardTrip: repository.NewDashboardTripRepository(db),
+		PDF:           repository.NewPDFRepository(db),
+		Idempotency:   repository.NewIdempotencyRepository(db),
+		Feedback:      repository.NewFeedbackRepository(db),
+		Corporate:     repository.NewCorporateRepository(db),
+		Calendar:      repository.NewCalendarRepository(db),
+		Geocoder:      service.DefaultGazetteer(),
+	}
+}
+
+func memoryRepositories(store *repository.MemoryStore) *repositories {
+	return &repositories{
+		Users:         repository.NewMemoryUserRepository(store),
+		Tokens:        repository.NewMemoryTokenRepository(store),
+		Tenants:       repository.NewMemoryTenantRepository(store),
+		Customers:     repository.NewMemoryCustomerRepository(store),
+		Drivers:       repository.NewMemoryDriverRepository(store),
+		Availability:  repository.NewMemoryAvailabilityRepository(store),
+		Dispatch:      repository.NewMemoryDispatchRepository(store),
+		Pricing:       repository.NewMemoryPricingRepository(store),
+		Promotions:    repository.NewMemoryPromotionRepository(store),
+		Recurring:     repository.NewMemoryRecurringBookingRepository(store),
+		Cancellations: repository.NewMemoryCancellationRepository(store),
+		Notifications: repository.NewMemoryNotificationRepository(store),
+		Cars:          repository.NewMemoryCarRepository(store),
+		Bookings:      repository.NewMemoryBookingRepository(store),
+		Payments:      repository.NewMemoryPaymentRepository(store),
+		Trips:         repository.NewMemoryTripRepo(store),
+		TripHistory:   repository.NewMemoryTripHistoryRepository(store),
+		Assignments:   repository.NewMemoryAssignmentsRepository(store),
+		Maintenance:   repository.NewMemoryMaintenanceRepository(store),
+		CarModels:     repository.NewMemoryCarModelRepository(store),
+		CarTypes:      repository.NewMemoryCarTypeRepository(store),
+		Popular:       repository.NewMemoryPopularDestinationRepository(store),
+		BookingTrends: repository.NewMemoryBookingTrendsRepository(store),
+		Dashboard:     repository.NewMemoryDashboardRepository(store),
+		DashboardTrip: repository.NewMemoryDashboardTripRepository(store),
+		PDF:           repository.NewMemoryPDFRepository(store),
+		Idempotency:   repository.NewMemoryIdempotencyRepository(store),
+		Feedback:      repository.NewMemoryFeedbackRepository(store),
+		Corporate:     repository.NewMemoryCorporateRepository(store),
+		Calendar:      repository.NewMemoryCalendarRepository(store),
+		Geocoder:      service.DefaultGazetteer(),
+	}
+}
+
+// seedMemoryAdmin gives a fresh in-memory backend a super admin on the default
+// tenant, since there is no existing users table to log in with.
+func seedMemoryAdmin(repos *repositories, username, password string) error {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return repos.Users.Save(&model.User{
+		TenantID: 1,
+		Username: username,
+		Password: hash,
+		Role:     model.RoleSuperAdmin,
+	})
+}
+
+func openStorage(kind, dsn string) (*repositories, *sql.DB, error) {
+	switch kind {
+	case storageMemory:
+		return memoryRepositories(repository.NewMemoryStore()), nil, nil
+	case storagePostgres:
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return postgresRepositories(db), db, nil
+	}
+	return nil, nil, fmt.Errorf("unknown storage %q, expected %s or %s", kind, storagePostgres, storageMemory)
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Booking codes are "BK", at least six Crockford base32 digits and a Luhn
+// mod 32 check digit. The alphabet leaves out I, L, O and U, so codes read
+// over the phone are hard to get wrong and typos are caught by the check.
+const (
+	bookingCodePrefix   = "BK"
+	bookingCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	bookingCodeDigits   = 6
+)
+
+// BookingCode encodes a sequence number as a booking code.
+func BookingCode(n int64) string {
+	var digits []byte
+	for n > 0 || len(digits) < bookingCodeDigits {
+		digits = append([]byte{bookingCodeAlphabet[n%32]}, digits...)
+		n /= 32
+	}
+	body := string(digits)
+	return bookingCodePrefix + body + string(bookingCodeCheck(body))
+}
+
+// ParseBookingCode returns the canonical form of a booking code typed by a
+// person: case, spaces and dashes are ignored, and O, I and L are read as 0
+// and 1. It reports false when the code is malformed or its check digit is
+// wrong.
+func ParseBookingCode(s string) (string, bool) {
+	code := strings.Map(func(r rune) rune {
+		switch r = unicode.ToUpper(r); r {
+		case ' ', '-':
+			return -1
+		case 'O':
+			return '0'
+		case 'I', 'L':
+			return '1'
+		}
+		return r
+	}, strings.TrimSpace(s))
+
+	body, ok := strings.CutPrefix(code, bookingCodePrefix)
+	if !ok || len(body) < bookingCodeDigits+1 {
+		return "", false
+	}
+	for i := 0; i < len(body); i++ {
+		if strings.IndexByte(bookingCodeAlphabet, body[i]) < 0 {
+			return "", false
+		}
+	}
+
+	last := len(body) - 1
+	if bookingCodeCheck(body[:last]) != body[last] {
+		return "", false
+	}
+	return code, true
+}
+
+func bookingCodeCheck(body string) byte {
+	factor, sum := 2, 0
+	for i := len(body) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(bookingCodeAlphabet, body[i])
+		sum += addend/32 + addend%32
+		factor = 3 - factor
+	}
+	return bookingCodeAlphabet[(32-sum%32)%32]
+}
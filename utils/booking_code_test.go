@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookingCode(t *testing.T) {
+	seen := map[string]bool{}
+	for _, n := range []int64{1, 2, 31, 32, 1000, 1<<30 - 1, 1 << 30} {
+		code := BookingCode(n)
+		assert.False(t, seen[code], code)
+		seen[code] = true
+
+		parsed, ok := ParseBookingCode(code)
+		assert.True(t, ok, code)
+		assert.Equal(t, code, parsed)
+	}
+
+	assert.Len(t, BookingCode(1), 9)
+	assert.Len(t, BookingCode(1<<30), 10)
+}
+
+func TestParseBookingCode(t *testing.T) {
+	code := BookingCode(123456)
+
+	tests := []struct {
+		name  string
+		input string
+		ok    bool
+	}{
+		{"canonical", code, true},
+		{"lower case with dashes", "bk-" + code[2:5] + "-" + code[5:], true},
+		{"too short", "BK12", false},
+		{"missing prefix", code[2:], false},
+		{"letter outside the alphabet", code[:len(code)-1] + "U", false},
+		{"wrong check digit", code[:len(code)-1] + string(bookingCodeAlphabet[(strings.IndexByte(bookingCodeAlphabet, code[len(code)-1])+1)%32]), false},
+		{"swapped digits", code[:2] + string(code[3]) + string(code[2]) + code[4:], code[2] == code[3]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, ok := ParseBookingCode(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, code, parsed)
+			}
+		})
+	}
+
+	zero, ok := ParseBookingCode("BK000001" + string(bookingCodeCheck("000001")))
+	assert.True(t, ok)
+	lookalike, ok := ParseBookingCode("bk ooooo1" + string(bookingCodeCheck("000001")))
+	assert.True(t, ok)
+	assert.Equal(t, zero, lookalike)
+}
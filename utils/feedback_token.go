@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const feedbackAudience = "feedback"
+
+// feedbackKey signs feedback links apart from access tokens, so a link can
+// never be used to call the API.
+var feedbackKey = append([]byte("feedback:"), jwtKey...)
+
+// FeedbackClaims identify the booking a feedback link was sent for.
+type FeedbackClaims struct {
+	BookingID string `json:"booking_id"`
+	TenantID  int64  `json:"tenant_id"`
+	jwt.RegisteredClaims
+}
+
+func GenerateFeedbackToken(bookingID string, tenantID int64, ttl time.Duration) (string, error) {
+	claims := &FeedbackClaims{
+		BookingID: bookingID,
+		TenantID:  tenantID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{feedbackAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(feedbackKey)
+}
+
+func ParseFeedbackToken(tokenString string) (*FeedbackClaims, error) {
+	claims := &FeedbackClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return feedbackKey, nil
+	}, jwt.WithAudience(feedbackAudience))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.BookingID == "" || claims.TenantID <= 0 {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedbackToken_RoundTrip(t *testing.T) {
+	token, err := GenerateFeedbackToken("BK-001", 3, time.Hour)
+	assert.NoError(t, err)
+
+	claims, err := ParseFeedbackToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "BK-001", claims.BookingID)
+	assert.Equal(t, int64(3), claims.TenantID)
+}
+
+func TestFeedbackToken_Rejected(t *testing.T) {
+	expired, _ := GenerateFeedbackToken("BK-001", 3, -time.Minute)
+	access, _ := GenerateAccessToken(1, 3, "admin", nil)
+	valid, _ := GenerateFeedbackToken("BK-001", 3, time.Hour)
+
+	for name, token := range map[string]string{
+		"expired":  expired,
+		"access":   access,
+		"tampered": valid[:len(valid)-2] + "xx",
+		"garbage":  "not-a-token",
+	} {
+		_, err := ParseFeedbackToken(token)
+		assert.Error(t, err, name)
+	}
+
+	_, err := ParseAccessToken(valid)
+	assert.Error(t, err, "a feedback link is not an access token")
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "math"
+
+const earthRadiusKM = 6371.0
+
+// HaversineKM returns the great-circle distance between two points in kilometres.
+func HaversineKM(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKM * math.Asin(math.Sqrt(a))
+}
+
+func ValidCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// BoundingBox returns the corners of the smallest latitude/longitude box that
+// contains every point within radiusKM of the centre.
+func BoundingBox(lat, lng, radiusKM float64) (minLat, minLng, maxLat, maxLng float64) {
+	dLat := radiusKM / earthRadiusKM * 180 / math.Pi
+	minLat, maxLat = math.Max(lat-dLat, -90), math.Min(lat+dLat, 90)
+
+	cos := math.Cos(lat * math.Pi / 180)
+	if cos < 1e-9 || minLat == -90 || maxLat == 90 {
+		return minLat, -180, maxLat, 180
+	}
+	dLng := dLat / cos
+	return minLat, math.Max(lng-dLng, -180), maxLat, math.Min(lng+dLng, 180)
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaversineKM(t *testing.T) {
+	// Jakarta (Monas) to Bandung (Gedung Sate).
+	assert.InDelta(t, 119, HaversineKM(-6.1754, 106.8272, -6.9025, 107.6188), 2)
+	assert.Zero(t, HaversineKM(-6.2, 106.8, -6.2, 106.8))
+}
+
+func TestValidCoordinates(t *testing.T) {
+	assert.True(t, ValidCoordinates(-6.2, 106.8))
+	assert.False(t, ValidCoordinates(91, 0))
+	assert.False(t, ValidCoordinates(0, -181))
+}
+
+func TestBoundingBox(t *testing.T) {
+	minLat, minLng, maxLat, maxLng := BoundingBox(-6.2, 106.8, 3)
+
+	assert.InDelta(t, 3, HaversineKM(-6.2, 106.8, maxLat, 106.8), 0.01)
+	assert.InDelta(t, 3, HaversineKM(-6.2, 106.8, minLat, 106.8), 0.01)
+	assert.InDelta(t, 3, HaversineKM(-6.2, 106.8, -6.2, maxLng), 0.05)
+	assert.Less(t, minLng, 106.8)
+
+	_, minLng, _, maxLng = BoundingBox(89.99, 0, 5)
+	assert.Equal(t, -180.0, minLng)
+	assert.Equal(t, 180.0, maxLng)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,15 +10,21 @@ import (
 var jwtKey = []byte("secret_key")
 
 type JWTclaims struct {
-	UserID int64  `json:"user_id"`
-	Role   string `json:"role"`
+	UserID   int64  `json:"user_id"`
+	TenantID int64  `json:"tenant_id"`
+	Role     string `json:"role"`
+	DriverID *int   `json:"driver_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(userID int64, role string) (string, error) {
+// GenerateAccessToken issues a token for the user userID in tenantID; driverID
+// is the driver a driver account is linked to, if any.
+func GenerateAccessToken(userID, tenantID int64, role string, driverID *int) (string, error) {
 	claims := &JWTclaims{
-		UserID: userID,
-		Role:   role,
+		UserID:   userID,
+		TenantID: tenantID,
+		Role:     role,
+		DriverID: driverID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -26,3 +33,20 @@ func GenerateAccessToken(userID int64, role string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtKey)
 }
+
+func ParseAccessToken(tokenString string) (*JWTclaims, error) {
+	claims := &JWTclaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return jwtKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
@@ -12,7 +12,7 @@ func TestGenerateAccessToken_Success(t *testing.T) {
 	userID := int64(1)
 	role := "admin"
 
-	tokenString, err := GenerateAccessToken(userID, role)
+	tokenString, err := GenerateAccessToken(userID, 3, role, nil)
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tokenString)
@@ -32,6 +32,7 @@ func TestGenerateAccessToken_Success(t *testing.T) {
 	assert.True(t, ok)
 
 	assert.Equal(t, userID, claims.UserID)
+	assert.Equal(t, int64(3), claims.TenantID)
 	assert.Equal(t, role, claims.Role)
 
 	assert.NotNil(t, claims.ExpiresAt)
@@ -41,7 +42,7 @@ func TestGenerateAccessToken_Success(t *testing.T) {
 func TestGenerateAccessToken_ExpiryTime(t *testing.T) {
 	start := time.Now()
 
-	tokenString, err := GenerateAccessToken(99, "user")
+	tokenString, err := GenerateAccessToken(99, 1, "user", nil)
 	assert.NoError(t, err)
 
 	token, err := jwt.ParseWithClaims(
@@ -59,3 +60,20 @@ func TestGenerateAccessToken_ExpiryTime(t *testing.T) {
 	diff := claims.ExpiresAt.Time.Sub(start)
 	assert.True(t, diff > 14*time.Minute && diff < 16*time.Minute)
 }
+
+func TestParseAccessToken(t *testing.T) {
+	tokenString, err := GenerateAccessToken(7, 2, "admin", nil)
+	assert.NoError(t, err)
+
+	claims, err := ParseAccessToken(tokenString)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), claims.UserID)
+	assert.Equal(t, int64(2), claims.TenantID)
+	assert.Equal(t, "admin", claims.Role)
+
+	_, err = ParseAccessToken(tokenString + "x")
+	assert.Error(t, err)
+
+	_, err = ParseAccessToken("not-a-token")
+	assert.Error(t, err)
+}
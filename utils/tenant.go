@@ -0,0 +1,17 @@
+package utils
+
+import "context"
+
+type tenantContextKey struct{}
+
+func WithTenant(ctx context.Context, tenantID int64) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+func TenantFromContext(ctx context.Context) (int64, bool) {
+	tenantID, ok := ctx.Value(tenantContextKey{}).(int64)
+	if !ok || tenantID <= 0 {
+		return 0, false
+	}
+	return tenantID, true
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantFromContext(t *testing.T) {
+	_, ok := TenantFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = TenantFromContext(WithTenant(context.Background(), 0))
+	assert.False(t, ok)
+
+	tenantID, ok := TenantFromContext(WithTenant(context.Background(), 4))
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), tenantID)
+}